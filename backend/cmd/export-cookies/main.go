@@ -2,15 +2,25 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/chromedp/chromedp"
 	"github.com/chromedp/cdproto/network"
+	"github.com/jgoulah/streamtime/internal/scraper"
 )
 
 func main() {
+	service := flag.String("service", "youtube_tv", "Service to export cookies for ("+strings.Join(scraper.ProviderKeys(), ", ")+")")
+	flag.Parse()
+
+	site, ok := scraper.CookieSiteFor(*service)
+	if !ok {
+		log.Fatalf("Unknown service %q, expected one of: %s", *service, strings.Join(scraper.ProviderKeys(), ", "))
+	}
+
 	// Create a context with a non-headless browser
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
 		chromedp.Flag("headless", false),
@@ -23,20 +33,20 @@ func main() {
 	ctx, cancel := chromedp.NewContext(allocCtx)
 	defer cancel()
 
-	// Navigate to myactivity.google.com
+	// Navigate to the page the scraper reads, which sends the user through login
 	log.Println("Opening browser...")
-	log.Println("Please log in and navigate to: https://myactivity.google.com/product/youtube")
+	log.Printf("Please log in and navigate to: %s", site.URL)
 	log.Println("Once you're on the page, come back here and press Enter to export cookies...")
 
 	err := chromedp.Run(ctx,
-		chromedp.Navigate("https://myactivity.google.com/product/youtube"),
+		chromedp.Navigate(site.URL),
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Wait for user to log in
-	fmt.Println("\nPress Enter when you're logged in and on the My Activity page...")
+	fmt.Println("\nPress Enter when you're logged in and on the history page...")
 	fmt.Scanln()
 
 	// Get all cookies
@@ -51,23 +61,34 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// Filter for Google cookies only
-	var googleCookies []*network.Cookie
+	// Filter for the service's cookies only, dropping those of login and ad domains
+	var serviceCookies []*network.Cookie
 	for _, cookie := range cookies {
-		// Only include cookies from google.com domains
-		if strings.Contains(cookie.Domain, "google.com") {
-			googleCookies = append(googleCookies, cookie)
+		domain := strings.TrimPrefix(cookie.Domain, ".")
+		if domain == site.Domain || strings.HasSuffix(domain, "."+site.Domain) {
+			serviceCookies = append(serviceCookies, cookie)
 		}
 	}
 
-	log.Printf("\nFound %d Google cookies (including HTTPOnly)", len(googleCookies))
-	fmt.Println("\n# Copy the output below into your config.yaml under youtube_tv.cookies:")
+	log.Printf("\nFound %d %s cookies (including HTTPOnly)", len(serviceCookies), site.Domain)
+	fmt.Printf("\n# Copy the output below into your config.yaml under services.%s:\n", *service)
 	fmt.Println("    cookies:")
 
-	// Output in YAML format
-	for _, cookie := range googleCookies {
-		fmt.Printf("      - name: \"%s\"\n", cookie.Name)
-		fmt.Printf("        value: \"%s\"\n", cookie.Value)
+	// Output in YAML format, keeping each cookie's attributes so it is set on the right subdomain
+	for _, cookie := range serviceCookies {
+		fmt.Printf("      - name: %q\n", cookie.Name)
+		fmt.Printf("        value: %q\n", cookie.Value)
+		fmt.Printf("        domain: %q\n", cookie.Domain)
+		fmt.Printf("        path: %q\n", cookie.Path)
+		if cookie.Expires > 0 {
+			// Session cookies report -1
+			fmt.Printf("        expires: %d\n", int64(cookie.Expires))
+		}
+		if cookie.SameSite != "" {
+			fmt.Printf("        same_site: \"%s\"\n", cookie.SameSite)
+		}
+		fmt.Printf("        secure: %t\n", cookie.Secure)
+		fmt.Printf("        http_only: %t\n", cookie.HTTPOnly)
 	}
 
 	fmt.Println("\n✅ Cookie export complete!")
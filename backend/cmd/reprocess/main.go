@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// reprocess re-parses the raw payloads stored with scraped entries, so a
+// parsing fix can be applied to existing history without re-scraping:
+//
+//	CONFIG_PATH=./config.yaml go run ./cmd/reprocess -service "Netflix" -dry-run
+func main() {
+	service := flag.String("service", "", "service to reprocess, e.g. \"Netflix\" (default: every enabled service)")
+	dryRun := flag.Bool("dry-run", false, "only report how many entries would change")
+	flag.Parse()
+
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "./config.yaml"
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.Open(cfg.Database.Driver, cfg.Database.DataSource())
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	manager := scraper.NewManager(db, cfg)
+	scrapers, err := scraper.NewScrapersFromConfig(cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to create scrapers: %v", err)
+	}
+
+	var names []string
+	for _, s := range scrapers {
+		manager.Register(s)
+		if *service == "" || s.Name() == *service {
+			names = append(names, s.Name())
+		}
+	}
+	if len(names) == 0 {
+		log.Fatalf("No enabled service named %q", *service)
+	}
+
+	for _, name := range names {
+		result, err := manager.Reprocess(context.Background(), name, *dryRun)
+		if err == scraper.ErrReprocessUnsupported {
+			log.Printf("Skipping %s: %v", name, err)
+			continue
+		}
+		if err != nil {
+			log.Fatalf("Failed to reprocess %s: %v", name, err)
+		}
+
+		verb := "updated"
+		if *dryRun {
+			verb = "would update"
+		}
+		log.Printf("%s: checked %d entries, %s %d, %d failed", name, result.Checked, verb, result.Updated, result.Failed)
+	}
+}
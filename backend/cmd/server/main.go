@@ -1,20 +1,34 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/jgoulah/streamtime/internal/api"
 	"github.com/jgoulah/streamtime/internal/config"
 	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/influx"
+	"github.com/jgoulah/streamtime/internal/mqtt"
+	"github.com/jgoulah/streamtime/internal/notify"
+	"github.com/jgoulah/streamtime/internal/reconcile"
+	"github.com/jgoulah/streamtime/internal/scheduler"
 	"github.com/jgoulah/streamtime/internal/scraper"
+	"github.com/jgoulah/streamtime/internal/storage"
+	"github.com/jgoulah/streamtime/internal/tmdb"
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply pending database migrations and exit")
+	migrateDown := flag.Int("migrate-down", -1, "revert database migrations newer than this schema version and exit")
+	flag.Parse()
+
 	// Load configuration
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -29,28 +43,184 @@ func main() {
 	log.Printf("Loaded configuration from %s", configPath)
 
 	// Initialize database
-	db, err := database.New(cfg.Database.Path)
+	db, err := database.Open(cfg.Database.Driver, cfg.Database.DataSource())
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
-	log.Printf("Database initialized at %s", cfg.Database.Path)
+	if cfg.Database.Driver == database.DriverPostgres {
+		log.Printf("Database initialized on Postgres")
+	} else {
+		log.Printf("Database initialized at %s", cfg.Database.Path)
+	}
+
+	if *migrateDown >= 0 {
+		reverted, err := db.MigrateDown(*migrateDown)
+		if err != nil {
+			log.Fatalf("Failed to revert migrations: %v", err)
+		}
+		log.Printf("Reverted migrations %v", reverted)
+		return
+	}
+	version, err := db.SchemaVersion()
+	if err != nil {
+		log.Fatalf("Failed to read schema version: %v", err)
+	}
+	log.Printf("Database schema at version %d", version)
+	if *migrateOnly {
+		return
+	}
 
 	// Initialize scraper manager
 	scraperMgr := scraper.NewManager(db, cfg)
 
-	// Register scrapers
-	netflixScraper := scraper.NewNetflixScraper(cfg, db)
-	scraperMgr.Register(netflixScraper)
+	// Register one scraper per enabled service instance
+	scrapers, err := scraper.NewScrapersFromConfig(cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to create scrapers: %v", err)
+	}
+	for _, s := range scrapers {
+		scraperMgr.Register(s)
+	}
+
+	log.Printf("Scraper manager initialized with %d scrapers", len(scrapers))
 
-	youtubeTVScraper := scraper.NewYouTubeTVScraper(cfg, db)
-	scraperMgr.Register(youtubeTVScraper)
+	// Record when services were acknowledged in config, for the consent report
+	if err := scraperMgr.RecordConfigConsent(); err != nil {
+		log.Fatalf("Failed to record consent: %v", err)
+	}
+	if cfg.Scraper.RequireConsent {
+		log.Printf("Consent required: only scraping services whose automation risk was acknowledged")
+	}
 
-	amazonScraper := scraper.NewAmazonScraper(cfg, db)
-	scraperMgr.Register(amazonScraper)
+	// Keep failed runs' page snapshots on disk or in a bucket instead of the database
+	artifacts, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize artifact storage: %v", err)
+	}
+	if artifacts != nil {
+		scraperMgr.SetArtifactStore(artifacts)
+		log.Printf("Storing run artifacts in %s storage", cfg.Storage.Type)
+	}
 
-	log.Println("Scraper manager initialized with Netflix, YouTube TV, and Amazon Video scrapers")
+	// Merge inferred sessions into scraped history that now covers them
+	scraperMgr.OnRunComplete(func(result *scraper.Result) {
+		if !result.Success {
+			return
+		}
+		if merged, err := reconcile.Run(db, time.Time{}, false); err != nil {
+			log.Printf("Failed to reconcile inferred sessions: %v", err)
+		} else if merged.Removed > 0 {
+			log.Printf("Merged %d inferred sessions into better evidence", merged.Removed)
+		}
+	})
+
+	// Push stats and scraper status to MQTT after every run
+	if cfg.MQTT.BrokerURL != "" {
+		client := mqtt.NewClient(cfg.MQTT.BrokerURL, cfg.MQTT.Username, cfg.MQTT.Password, cfg.MQTT.ClientID)
+		publisher := mqtt.NewPublisher(client, db, cfg.MQTT.TopicPrefix)
+		scraperMgr.OnRunComplete(publisher.HandleResult)
+		log.Printf("Publishing MQTT topics under %s/ to %s", cfg.MQTT.TopicPrefix, cfg.MQTT.BrokerURL)
+	}
+
+	// Write daily watch time to InfluxDB after every successful run
+	if cfg.Influx.URL != "" {
+		exporter := influx.NewExporter(cfg.Influx, db)
+		scraperMgr.OnRunComplete(exporter.HandleResult)
+		log.Printf("Exporting daily watch time to InfluxDB at %s", cfg.Influx.URL)
+	}
+
+	// Roll up history past the detail window at startup and after every
+	// successful run, keeping the database small on constrained devices
+	if cfg.Database.DetailDays > 0 {
+		compact := func() {
+			now := time.Now()
+			cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -cfg.Database.DetailDays)
+			result, err := db.CompactHistory(cutoff)
+			if err != nil {
+				log.Printf("Failed to compact history: %v", err)
+				return
+			}
+			if result.EntriesCompacted > 0 {
+				log.Printf("Compacted %d entries before %s into daily rollups", result.EntriesCompacted, cutoff.Format("2006-01-02"))
+			}
+		}
+		compact()
+		scraperMgr.OnRunComplete(func(result *scraper.Result) {
+			if result.Success {
+				compact()
+			}
+		})
+		log.Printf("Keeping full history detail for %d days", cfg.Database.DetailDays)
+	}
+
+	// Roll old scraper runs up into daily summaries at startup and after every run
+	pruneRuns := func() {
+		result, err := scraperMgr.PruneRuns(context.Background())
+		if err != nil {
+			log.Printf("Failed to prune scraper runs: %v", err)
+			return
+		}
+		if result.RunsDeleted > 0 {
+			log.Printf("Rolled %d old scraper runs up into daily summaries", result.RunsDeleted)
+		}
+	}
+	pruneRuns()
+	scraperMgr.OnRunComplete(func(*scraper.Result) { pruneRuns() })
+
+	// Run scrapers on scraper.schedule and per-service schedules
+	sched, err := scheduler.New(cfg, scraperMgr)
+	if err != nil {
+		log.Fatalf("Failed to create scheduler: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sched.Run(ctx)
+
+	// TMDB lookups share a client, and scheduled checks deliver through the notifier
+	notifier := notify.New(cfg, db)
+	client := tmdb.NewClient(cfg.TMDB.APIKey)
+	client.SetCache(db)
+
+	// Fill in durations, genres and thumbnails scrapers couldn't read
+	if cfg.TMDB.APIKey != "" {
+		scraperMgr.StartEnrichment(ctx, client)
+	}
+
+	// Check for new seasons of watched shows on notifications.new_seasons.schedule
+	if cfg.Notifications.NewSeasons.Enabled {
+		schedule, err := scheduler.Parse(cfg.Notifications.NewSeasons.Schedule)
+		if err != nil {
+			log.Fatalf("Invalid notifications.new_seasons.schedule: %v", err)
+		}
+		go scheduler.RunTask(ctx, schedule, func(ctx context.Context) {
+			check, err := notify.CheckNewSeasons(ctx, db, client, notifier)
+			if err != nil {
+				log.Printf("New season check failed: %v", err)
+				return
+			}
+			log.Printf("Checked %d shows for new seasons (%d looked up), sent %d notifications", check.Shows, check.LookedUp, check.Notified)
+		})
+		log.Printf("Checking for new seasons on %q", cfg.Notifications.NewSeasons.Schedule)
+	}
+
+	// Refresh availability of the most rewatched titles on notifications.leaving.schedule
+	if leaving := cfg.Notifications.Leaving; leaving.Enabled {
+		schedule, err := scheduler.Parse(leaving.Schedule)
+		if err != nil {
+			log.Fatalf("Invalid notifications.leaving.schedule: %v", err)
+		}
+		go scheduler.RunTask(ctx, schedule, func(ctx context.Context) {
+			check, err := notify.CheckLeaving(ctx, db, client, notifier, leaving.Region, leaving.Titles)
+			if err != nil {
+				log.Printf("Availability check failed: %v", err)
+				return
+			}
+			log.Printf("Checked availability of %d titles in %s, %d gone (%d new)", check.Titles, leaving.Region, check.Gone, check.Notified)
+		})
+		log.Printf("Checking availability of rewatched titles in %s on %q", leaving.Region, leaving.Schedule)
+	}
 
 	// Create API handler
 	handler := api.NewHandler(db, scraperMgr, cfg)
@@ -70,6 +240,7 @@ func main() {
 		<-sigChan
 
 		log.Println("Shutting down server...")
+		cancel()
 		if err := server.Close(); err != nil {
 			log.Printf("Error closing server: %v", err)
 		}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+)
+
+// serviceAdLoad returns the minutes of commercials per hour of content for a
+// service by its database name, or false if it isn't on an ad-supported tier
+func (h *Handler) serviceAdLoad(serviceName string) (float64, bool) {
+	for key, svc := range h.config.Services {
+		if h.serviceNameFor(key) != serviceName || !svc.AdSupported {
+			continue
+		}
+		if svc.AdMinutesPerHour > 0 {
+			return svc.AdMinutesPerHour, true
+		}
+		return h.config.Insights.Ads.MinutesPerHour, true
+	}
+	return 0, false
+}
+
+// addAdMinutes sets AdMinutes on the stats of ad-supported services and
+// returns their total, or nil when no service is marked ad_supported
+func (h *Handler) addAdMinutes(stats []database.ServiceStats) *int {
+	var total *int
+	for i := range stats {
+		rate, ok := h.serviceAdLoad(stats[i].ServiceName)
+		if !ok {
+			continue
+		}
+		minutes := insights.EstimateAdMinutes(stats[i].TotalMinutes, rate)
+		stats[i].AdMinutes = &minutes
+		if total == nil {
+			total = new(int)
+		}
+		*total += minutes
+	}
+	return total
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// badgeCacheTTL is how long rendered badges are reused before stats are re-queried
+const badgeCacheTTL = 10 * time.Minute
+
+// badgeCache holds rendered badges keyed by period and service. Expired
+// badges are dropped whenever one is stored, so the cache stays small.
+type badgeCache struct {
+	mu      sync.Mutex
+	entries map[string]badgeCacheEntry
+}
+
+type badgeCacheEntry struct {
+	svg     string
+	expires time.Time
+}
+
+func newBadgeCache() *badgeCache {
+	return &badgeCache{entries: make(map[string]badgeCacheEntry)}
+}
+
+func (c *badgeCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.svg, true
+}
+
+func (c *badgeCache) set(key, svg string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = badgeCacheEntry{svg: svg, expires: now.Add(badgeCacheTTL)}
+}
+
+// getHoursBadge renders an embeddable SVG badge like "123 hours watched this month".
+// Supports ?period=month|year|all (default month) and optional ?service_id= and ?profile=.
+func (h *Handler) getHoursBadge(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	period := query.Get("period")
+	if period == "" {
+		period = "month"
+	}
+
+	now := time.Now().UTC()
+	var startDate, endDate time.Time
+	var periodLabel string
+	switch period {
+	case "month":
+		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		endDate = startDate.AddDate(0, 1, 0)
+		periodLabel = "this month"
+	case "year":
+		startDate = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		endDate = startDate.AddDate(1, 0, 0)
+		periodLabel = "this year"
+	case "all":
+		startDate = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		endDate = now.AddDate(1, 0, 0)
+		periodLabel = "all time"
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid period parameter", fmt.Errorf("period must be month, year, or all"))
+		return
+	}
+
+	var serviceID int64
+	if serviceIDStr := query.Get("service_id"); serviceIDStr != "" {
+		var err error
+		serviceID, err = strconv.ParseInt(serviceIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid service_id parameter", err)
+			return
+		}
+	}
+
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%s|%d|%d", period, serviceID, db.ProfileID())
+	svg, ok := h.badges.get(cacheKey)
+	if !ok {
+		stats, err := db.GetServiceStats(startDate, endDate)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch stats", err)
+			return
+		}
+
+		label := "watched"
+		totalMinutes := 0
+		for _, stat := range stats {
+			if serviceID != 0 && stat.ServiceID != serviceID {
+				continue
+			}
+			if serviceID != 0 {
+				label = stat.ServiceName
+			}
+			totalMinutes += stat.TotalMinutes
+		}
+
+		value := fmt.Sprintf("%d hours %s", totalMinutes/60, periodLabel)
+		svg = renderBadge(label, value)
+		h.badges.set(cacheKey, svg)
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(badgeCacheTTL.Seconds())))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(svg))
+}
+
+// renderBadge renders a two-part flat badge in the style of shields.io
+func renderBadge(label, value string) string {
+	// Approximate text width for an 11px sans-serif font
+	labelWidth := len(label)*7 + 10
+	valueWidth := len(value)*7 + 10
+	totalWidth := labelWidth + valueWidth
+
+	label = html.EscapeString(label)
+	value = html.EscapeString(value)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+  <title>%s: %s</title>
+  <rect width="%d" height="20" rx="3" fill="#555"/>
+  <rect x="%d" width="%d" height="20" rx="3" fill="#E50914"/>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%d" y="14">%s</text>
+    <text x="%d" y="14">%s</text>
+  </g>
+</svg>
+`, totalWidth, label, value, label, value,
+		totalWidth, labelWidth, valueWidth,
+		labelWidth/2, label, labelWidth+valueWidth/2, value)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetHoursBadge(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       service.ID,
+		Title:           "Test Movie",
+		DurationMinutes: 185,
+		WatchedAt:       time.Now(),
+	})
+
+	req, err := http.NewRequest("GET", "/api/badges/hours.svg?period=month", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getHoursBadge(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("Expected SVG content type, got %q", ct)
+	}
+	if !strings.Contains(rr.Body.String(), "3 hours this month") {
+		t.Errorf("Expected badge to show 3 hours this month, got %s", rr.Body.String())
+	}
+
+	// Cached badges are served even after new history arrives
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       service.ID,
+		Title:           "Another Movie",
+		DurationMinutes: 600,
+		WatchedAt:       time.Now(),
+	})
+	rr = httptest.NewRecorder()
+	handler.getHoursBadge(rr, req)
+	if !strings.Contains(rr.Body.String(), "3 hours this month") {
+		t.Error("Expected cached badge to be served")
+	}
+}
+
+func TestGetHoursBadgeFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	watchAs(t, db, "Alex", "Severance", 120, time.Now())
+	watchAs(t, db, "Sam", "Love Is Blind", 240, time.Now())
+
+	// Each profile's badge is cached separately from the household's
+	for query, expected := range map[string]string{
+		"?period=month&profile=alex": "2 hours this month",
+		"?period=month":              "6 hours this month",
+	} {
+		req, _ := http.NewRequest("GET", "/api/badges/hours.svg"+query, nil)
+		rr := httptest.NewRecorder()
+		handler.getHoursBadge(rr, req)
+		if !strings.Contains(rr.Body.String(), expected) {
+			t.Errorf("%q: expected badge to show %s, got %s", query, expected, rr.Body.String())
+		}
+	}
+}
+
+func TestGetHoursBadgeCacheKey(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	for _, query := range []string{"period=month&x=1", "x=2&period=month", "period=month"} {
+		req, err := http.NewRequest("GET", "/api/badges/hours.svg?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler.getHoursBadge(httptest.NewRecorder(), req)
+	}
+	if n := len(handler.badges.entries); n != 1 {
+		t.Errorf("Expected equivalent queries to share one cache entry, got %d", n)
+	}
+
+	handler.badges.entries["stale"] = badgeCacheEntry{svg: "<svg/>", expires: time.Now().Add(-time.Minute)}
+	handler.badges.set("year|0|0", "<svg/>")
+	if _, ok := handler.badges.entries["stale"]; ok {
+		t.Error("Expected expired badges to be dropped when storing one")
+	}
+}
+
+func TestGetHoursBadgeInvalidPeriod(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("GET", "/api/badges/hours.svg?period=decade", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getHoursBadge(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestRenderBadgeEscapesText(t *testing.T) {
+	svg := renderBadge("<script>", "1 hours")
+	if strings.Contains(svg, "<script>") {
+		t.Error("Expected label to be escaped")
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/bundle"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// downloadRunBundle packages a scraper run's logs, selector hit counts, last DOM
+// snapshot and version info into a zip to attach to a bug report. Cookie
+// values, credentials and email addresses are scrubbed first.
+func (h *Handler) downloadRunBundle(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid scraper run ID", err)
+		return
+	}
+
+	run, err := h.db.GetScraperRun(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch scraper run", err)
+		return
+	}
+	if run == nil {
+		respondError(w, http.StatusNotFound, "Scraper run not found", fmt.Errorf("scraper run with ID %d not found", id))
+		return
+	}
+
+	// Cookies from outside the config are scrubbed too
+	serviceName := ""
+	extra := h.importedCookies(run.ServiceID)
+	if service, err := h.db.GetServiceByID(run.ServiceID); err == nil && service != nil {
+		serviceName = service.Name
+		extra = append(extra, scraper.BrowserProfileCookies(h.config, service.Key)...)
+	}
+
+	// Snapshots may be kept in the artifact store rather than the database
+	if run.DOMSnapshot, err = h.scraperManager.DOMSnapshot(r.Context(), run); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch page snapshot", err)
+		return
+	}
+
+	// Build the zip in memory so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, run, serviceName, bundle.NewScrubber(h.config, extra...)); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to build bundle", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="streamtime-run-%d.zip"`, run.ID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestDownloadRunBundle(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	run := &database.ScraperRun{
+		ServiceID:    service.ID,
+		RanAt:        time.Now(),
+		Status:       "failed",
+		ErrorMessage: "no data found",
+		Logs:         "Navigating to viewing activity page...\n",
+		DOMSnapshot:  "<html></html>",
+	}
+	if err := db.InsertScraperRun(run); err != nil {
+		t.Fatalf("Failed to insert scraper run: %v", err)
+	}
+
+	req, _ := http.NewRequest("POST", "/api/scraper/runs/1/bundle", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+	handler.downloadRunBundle(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Expected application/zip, got %q", ct)
+	}
+	if rr.Body.Len() == 0 {
+		t.Error("Expected a zip body")
+	}
+
+	req, _ = http.NewRequest("POST", "/api/scraper/runs/99/bundle", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "99"})
+	rr = httptest.NewRecorder()
+	handler.downloadRunBundle(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a missing run, got %d", http.StatusNotFound, status)
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// getChanges returns history and services changed since a timestamp, plus the
+// IDs of deleted history, so offline-capable frontends can sync incrementally.
+// Clients pass the returned synced_at as the next since; rows changed in that
+// same second may be sent twice, so applying changes must be idempotent.
+func (h *Handler) getChanges(w http.ResponseWriter, r *http.Request) {
+	sinceStr := r.URL.Query().Get("since")
+	if sinceStr == "" {
+		respondError(w, http.StatusBadRequest, "Invalid since parameter", fmt.Errorf("since is required (RFC 3339 or Unix seconds)"))
+		return
+	}
+	since, err := parseSince(sinceStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid since parameter", err)
+		return
+	}
+
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	// Taken before querying so changes made during the sync are picked up next time
+	syncedAt := time.Now().UTC().Truncate(time.Second)
+
+	history, err := db.GetHistoryChangedSince(since)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch history changes", err)
+		return
+	}
+
+	deleted, err := h.db.GetHistoryDeletedSince(since)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch deleted history", err)
+		return
+	}
+
+	services, err := h.db.GetServicesChangedSince(since)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service changes", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"since":               since.UTC().Format(time.RFC3339),
+		"synced_at":           syncedAt.Format(time.RFC3339),
+		"history":             history,
+		"deleted_history_ids": deleted,
+		"services":            services,
+	})
+}
+
+// parseSince parses an RFC 3339 timestamp or Unix seconds
+func parseSince(value string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
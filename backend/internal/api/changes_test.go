@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetChanges(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: time.Now()})
+
+	since := time.Now().Add(-time.Hour).Unix()
+	req, err := http.NewRequest("GET", "/api/changes?since="+strconv.FormatInt(since, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getChanges(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var resp struct {
+		SyncedAt string                  `json:"synced_at"`
+		History  []database.WatchHistory `json:"history"`
+		Deleted  []int64                 `json:"deleted_history_ids"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.History) != 1 || resp.History[0].Title != "Severance" {
+		t.Errorf("Expected the new history row, got %+v", resp.History)
+	}
+	if resp.Deleted == nil || resp.SyncedAt == "" {
+		t.Errorf("Expected deletions and synced_at, got %+v", resp)
+	}
+
+	// Nothing changed after the sync
+	req, _ = http.NewRequest("GET", "/api/changes?since="+resp.SyncedAt, nil)
+	rr = httptest.NewRecorder()
+	handler.getChanges(rr, req)
+	resp.History = nil
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.History) > 1 {
+		t.Errorf("Expected at most the row changed in the synced second, got %+v", resp.History)
+	}
+}
+
+func TestGetChangesFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	watchAs(t, db, "Alex", "Severance", 50, time.Now())
+	watchAs(t, db, "Sam", "Love Is Blind", 60, time.Now())
+
+	since := time.Now().Add(-time.Hour).Unix()
+	req, _ := http.NewRequest("GET", "/api/changes?profile=alex&since="+strconv.FormatInt(since, 10), nil)
+	rr := httptest.NewRecorder()
+	handler.getChanges(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		History []database.WatchHistory `json:"history"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.History) != 1 || resp.History[0].Title != "Severance" {
+		t.Errorf("Expected only Alex's history, got %+v", resp.History)
+	}
+}
+
+func TestGetChangesRequiresSince(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	for _, url := range []string{"/api/changes", "/api/changes?since=yesterday"} {
+		req, _ := http.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+		handler.getChanges(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, url, status)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// getScraperConsent reports, for every configured scraper, whether its
+// service's automation risk has been acknowledged and whether it may run
+func (h *Handler) getScraperConsent(w http.ResponseWriter, r *http.Request) {
+	states, err := h.scraperManager.ConsentStates()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch consent", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, states)
+}
+
+// getServiceConsent reports whether a service's automation risk has been
+// acknowledged, where and when
+func (h *Handler) getServiceConsent(w http.ResponseWriter, r *http.Request) {
+	service, ok := h.serviceFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := h.scraperManager.Consent(service)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch consent", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, state)
+}
+
+// setServiceConsent acknowledges a service's automation risk, letting it be
+// scraped when scraper.require_consent is on, or withdraws that consent.
+// The body is {"accepted": true}.
+func (h *Handler) setServiceConsent(w http.ResponseWriter, r *http.Request) {
+	service, ok := h.serviceFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Accepted *bool `json:"accepted"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.Accepted == nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", errors.New("accepted is required"))
+		return
+	}
+
+	state, err := h.scraperManager.SetConsent(service, *req.Accepted)
+	if errors.Is(err, scraper.ErrConsentInConfig) {
+		respondError(w, http.StatusConflict, "Consent is set in config", err)
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to record consent", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, state)
+}
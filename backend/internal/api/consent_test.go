@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+func TestServiceConsent(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Scraper.RequireConsent = true
+
+	service, _ := db.GetServiceByName("Netflix")
+	id := strconv.FormatInt(service.ID, 10)
+
+	// Scrapes are refused until the risk is acknowledged
+	req, _ := http.NewRequest("POST", "/api/scrape/netflix", nil)
+	req = mux.SetURLVars(req, map[string]string{"service": "netflix"})
+	rr := httptest.NewRecorder()
+	handler.triggerScrape(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d before consent, got %d", http.StatusForbidden, rr.Code)
+	}
+
+	req, _ = http.NewRequest("PUT", "/api/services/"+id+"/consent", strings.NewReader(`{"accepted": true}`))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr = httptest.NewRecorder()
+	handler.setServiceConsent(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/services/"+id+"/consent", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr = httptest.NewRecorder()
+	handler.getServiceConsent(rr, req)
+
+	var state scraper.ConsentState
+	if err := json.NewDecoder(rr.Body).Decode(&state); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !state.Required || !state.Accepted || !state.Allowed || state.Source != scraper.ConsentSourceAPI || state.Updated == nil {
+		t.Errorf("Expected consent recorded through the API, got %+v", state)
+	}
+}
+
+func TestServiceConsentInvalid(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	id := strconv.FormatInt(service.ID, 10)
+
+	req, _ := http.NewRequest("PUT", "/api/services/"+id+"/consent", strings.NewReader(`{}`))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr := httptest.NewRecorder()
+	handler.setServiceConsent(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without accepted, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	// Consent given in config can't be withdrawn through the API
+	svc := handler.config.Services["netflix"]
+	svc.AcceptAutomationRisk = true
+	handler.config.Services["netflix"] = svc
+
+	req, _ = http.NewRequest("PUT", "/api/services/"+id+"/consent", strings.NewReader(`{"accepted": false}`))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr = httptest.NewRecorder()
+	handler.setServiceConsent(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, rr.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/cookiefile"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// importServiceCookies stores cookies for a service from a cookies.txt file or
+// an EditThisCookie/Cookie-Editor JSON export, uploaded as a multipart "file"
+// field or the raw body. They replace the service's config cookies on the next
+// scrape. Pass ?domain=netflix.com to keep only that site's cookies from an
+// export of every site.
+func (h *Handler) importServiceCookies(w http.ResponseWriter, r *http.Request) {
+	service, ok := h.serviceFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	data, _, err := readImportFile(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to read upload", err)
+		return
+	}
+
+	cookies, format, err := cookiefile.Parse(data)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to parse cookie file", err)
+		return
+	}
+	if domain := r.URL.Query().Get("domain"); domain != "" {
+		cookies = cookiefile.FilterDomain(cookies, domain)
+		if len(cookies) == 0 {
+			respondError(w, http.StatusBadRequest, "No cookies for domain", fmt.Errorf("no cookies match domain %q", domain))
+			return
+		}
+	}
+
+	encoded, err := json.Marshal(cookies)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to encode cookies", err)
+		return
+	}
+	if err := h.db.SaveServiceCookies(service.ID, string(encoded)); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save cookies", err)
+		return
+	}
+
+	// Report names only, so cookie values don't end up in logs or browser history
+	names := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"service_id": service.ID,
+		"format":     format,
+		"imported":   len(cookies),
+		"names":      names,
+	})
+}
+
+// deleteServiceCookies removes a service's imported cookies, so scrapes go
+// back to the cookies in config
+func (h *Handler) deleteServiceCookies(w http.ResponseWriter, r *http.Request) {
+	service, ok := h.serviceFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.db.DeleteServiceCookies(service.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete cookies", err)
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "No imported cookies", fmt.Errorf("service %d has no imported cookies", service.ID))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deleted": service.ID,
+	})
+}
+
+// serviceFromPath looks up the service named by the {id} path variable,
+// responding with an error and returning false if it can't
+func (h *Handler) serviceFromPath(w http.ResponseWriter, r *http.Request) (*database.Service, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service ID", err)
+		return nil, false
+	}
+
+	service, err := h.db.GetServiceByID(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+		return nil, false
+	}
+	if service == nil {
+		respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service with ID %d not found", id))
+		return nil, false
+	}
+
+	return service, true
+}
+
+// importedCookies returns a service's imported cookies, if any
+func (h *Handler) importedCookies(serviceID int64) []config.Cookie {
+	stored, err := h.db.GetServiceCookies(serviceID)
+	if err != nil || stored == "" {
+		return nil
+	}
+	var cookies []config.Cookie
+	json.Unmarshal([]byte(stored), &cookies)
+	return cookies
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+func TestImportServiceCookies(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	id := strconv.FormatInt(service.ID, 10)
+	body := ".netflix.com\tTRUE\t/\tTRUE\t1767225600\tNetflixId\tsecret-value\n" +
+		".google.com\tTRUE\t/\tTRUE\t1767225600\tNID\tother\n"
+
+	req, _ := http.NewRequest("POST", "/api/services/"+id+"/cookies?domain=netflix.com", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr := httptest.NewRecorder()
+	handler.importServiceCookies(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String(), "secret-value") {
+		t.Error("Expected cookie values to be left out of the response")
+	}
+
+	var resp struct {
+		Format   string   `json:"format"`
+		Imported int      `json:"imported"`
+		Names    []string `json:"names"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Format != "netscape" || resp.Imported != 1 || resp.Names[0] != "NetflixId" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	stored, _ := db.GetServiceCookies(service.ID)
+	var cookies []config.Cookie
+	if err := json.Unmarshal([]byte(stored), &cookies); err != nil || len(cookies) != 1 || cookies[0].Value != "secret-value" {
+		t.Errorf("Expected the Netflix cookie to be stored, got %q", stored)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/services/"+id+"/cookies", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr = httptest.NewRecorder()
+	handler.deleteServiceCookies(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	if stored, _ := db.GetServiceCookies(service.ID); stored != "" {
+		t.Errorf("Expected imported cookies to be deleted, got %q", stored)
+	}
+}
+
+func TestImportServiceCookiesInvalid(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	id := strconv.FormatInt(service.ID, 10)
+
+	req, _ := http.NewRequest("POST", "/api/services/"+id+"/cookies", strings.NewReader(`[{"value": "no name"}]`))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr := httptest.NewRecorder()
+	handler.importServiceCookies(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// timelineEvent is a single scraper run or watch history entry on the debug timeline
+type timelineEvent struct {
+	Time   time.Time              `json:"time"`
+	Type   string                 `json:"type"` // "scrape" or "watch"
+	Scrape *database.ScraperRun   `json:"scrape,omitempty"`
+	Watch  *database.WatchHistory `json:"watch,omitempty"`
+}
+
+// timelineScrapeDays is how many days of scraper runs after the requested day
+// are included, since a day's viewing is usually picked up by a later scrape
+const timelineScrapeDays = 3
+
+// getDebugTimeline interleaves scraper runs with the watch history for one day,
+// so a missing show can be traced to either a scrape failure or a parse failure.
+// Each scrape counts the items it rejected, skipped or ignored instead of storing.
+// ?service= accepts a service ID or config key (e.g., "netflix"); ?day= is YYYY-MM-DD.
+func (h *Handler) getDebugTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	serviceParam := query.Get("service")
+	if serviceParam == "" {
+		respondError(w, http.StatusBadRequest, "Missing service parameter", fmt.Errorf("service is required"))
+		return
+	}
+
+	var service *database.Service
+	var err error
+	if id, parseErr := strconv.ParseInt(serviceParam, 10, 64); parseErr == nil {
+		service, err = h.db.GetServiceByID(id)
+	} else {
+		service, err = h.db.GetServiceByName(h.serviceNameFor(serviceParam))
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+		return
+	}
+	if service == nil {
+		respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service %q not found", serviceParam))
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := today
+	if dayStr := query.Get("day"); dayStr != "" {
+		day, err = time.Parse("2006-01-02", dayStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid day parameter", err)
+			return
+		}
+	}
+	dayEnd := day.AddDate(0, 0, 1)
+
+	history, err := h.db.GetWatchHistory(service.ID, day, dayEnd, 1000, 0)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch history", err)
+		return
+	}
+
+	runs, err := h.db.GetScraperRuns(service.ID, day, day.AddDate(0, 0, timelineScrapeDays))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch scraper runs", err)
+		return
+	}
+
+	events := []timelineEvent{}
+	for i := range history {
+		events = append(events, timelineEvent{Time: history[i].WatchedAt, Type: "watch", Watch: &history[i]})
+	}
+	for i := range runs {
+		events = append(events, timelineEvent{Time: runs[i].RanAt, Type: "scrape", Scrape: &runs[i]})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	response := map[string]interface{}{
+		"service":       service,
+		"day":           day.Format("2006-01-02"),
+		"events":        events,
+		"watch_count":   len(history),
+		"scrape_count":  len(runs),
+		"scrapes_until": day.AddDate(0, 0, timelineScrapeDays).Format("2006-01-02"),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
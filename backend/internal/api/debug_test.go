@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetDebugTimeline(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Evening Show", DurationMinutes: 40, WatchedAt: day.Add(20 * time.Hour)})
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: day.Add(3 * time.Hour), Status: "success", ItemsScraped: 5, ItemsSkipped: 2, ItemsIgnored: 1})
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: day.Add(27 * time.Hour), Status: "failed", ErrorMessage: "navigation failed"})
+
+	req, err := http.NewRequest("GET", "/api/debug/timeline?service=netflix&day=2025-03-01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getDebugTimeline(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Events []timelineEvent `json:"events"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(response.Events))
+	}
+	wantTypes := []string{"scrape", "watch", "scrape"}
+	for i, want := range wantTypes {
+		if response.Events[i].Type != want {
+			t.Errorf("Expected event %d to be %s, got %s", i, want, response.Events[i].Type)
+		}
+	}
+	if scrape := response.Events[0].Scrape; scrape.ItemsSkipped != 2 || scrape.ItemsIgnored != 1 {
+		t.Errorf("Expected the run's skipped and ignored items, got %+v", scrape)
+	}
+	if response.Events[2].Scrape.Status != "failed" {
+		t.Errorf("Expected last scrape to have failed, got %s", response.Events[2].Scrape.Status)
+	}
+}
+
+func TestGetDebugTimelineRequiresService(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("GET", "/api/debug/timeline", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getDebugTimeline(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
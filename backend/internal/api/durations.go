@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/units"
+)
+
+// formattedSuffix is appended to a minutes field's name for its human-readable copy
+const formattedSuffix = "_formatted"
+
+// formatDurations adds a human-readable copy of every minutes field to JSON
+// responses, e.g. "total_minutes": 155 gains "total_minutes_formatted": "2h 35m",
+// so clients don't each reimplement it. The format comes from
+// display.duration_format and can be overridden per request with
+// ?duration_format=short|long|none.
+func (h *Handler) formatDurations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := h.config.Display.DurationFormat
+		if override := r.URL.Query().Get("duration_format"); units.ValidFormat(override) {
+			format = override
+		}
+		if format == "" || format == units.FormatNone {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		body := rec.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			if formatted, ok := addFormattedDurations(body, format); ok {
+				body = formatted
+			}
+		}
+		w.WriteHeader(rec.status)
+		w.Write(body)
+	})
+}
+
+// bufferedResponse holds a handler's response so it can be rewritten
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// addFormattedDurations rewrites a JSON document with formatted copies of its
+// minutes fields. It reports false if the body isn't valid JSON.
+func addFormattedDurations(body []byte, format string) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, false
+	}
+
+	addFormatted(doc, format)
+
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(doc); err != nil {
+		return nil, false
+	}
+	return out.Bytes(), true
+}
+
+// addFormatted walks a decoded JSON value, adding a formatted field next to
+// each numeric field whose name ends in "minutes"
+func addFormatted(v interface{}, format string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if n, ok := value.(json.Number); ok && strings.HasSuffix(key, "minutes") {
+				if _, exists := v[key+formattedSuffix]; !exists {
+					if minutes, err := n.Float64(); err == nil {
+						v[key+formattedSuffix] = units.FormatMinutes(int(math.Round(minutes)), format)
+					}
+				}
+				continue
+			}
+			addFormatted(value, format)
+		}
+	case []interface{}:
+		for _, item := range v {
+			addFormatted(item, format)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormatDurations(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Display.DurationFormat = "short"
+
+	wrapped := handler.formatDurations(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"total_minutes": 155,
+			"services": []map[string]interface{}{
+				{"name": "Netflix", "total_minutes": 60},
+			},
+		})
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/stats", nil)
+	rr := httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, req)
+
+	var body struct {
+		TotalMinutes   int    `json:"total_minutes"`
+		TotalFormatted string `json:"total_minutes_formatted"`
+		Services       []struct {
+			TotalFormatted string `json:"total_minutes_formatted"`
+		} `json:"services"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.TotalMinutes != 155 || body.TotalFormatted != "2h 35m" {
+		t.Errorf("Expected 155 minutes formatted as 2h 35m, got %d and %q", body.TotalMinutes, body.TotalFormatted)
+	}
+	if len(body.Services) != 1 || body.Services[0].TotalFormatted != "1h" {
+		t.Errorf("Expected nested minutes to be formatted, got %+v", body.Services)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/stats?duration_format=none", nil)
+	rr = httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, req)
+
+	var raw map[string]interface{}
+	json.NewDecoder(rr.Body).Decode(&raw)
+	if _, ok := raw["total_minutes_formatted"]; ok {
+		t.Error("Expected no formatted fields with duration_format=none")
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+)
+
+// effectiveTime returns the configured intro and credits estimate
+func (h *Handler) effectiveTime() insights.EffectiveTime {
+	cfg := h.config.Insights.EffectiveTime
+	perEpisode := make(map[string]float64, len(cfg.Shows))
+	for title, show := range cfg.Shows {
+		perEpisode[title] = show.IntroMinutes + show.CreditsMinutes
+	}
+	return insights.NewEffectiveTime(cfg.IntroPercent+cfg.CreditsPercent, perEpisode)
+}
+
+// addEffectiveMinutes sets EffectiveMinutes on each service's stats and
+// returns their total, or nil when no intro or credits overhead is configured.
+// Only video is adjusted; listening time counts in full.
+func (h *Handler) addEffectiveMinutes(db *database.DB, stats []database.ServiceStats, startDate, endDate time.Time) (*int, error) {
+	effective := h.effectiveTime()
+	if !effective.Enabled() {
+		return nil, nil
+	}
+
+	titles, err := db.GetVideoTitleStats(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	overhead := make(map[int64]int)
+	for _, ts := range titles {
+		overhead[ts.ServiceID] += ts.TotalMinutes - effective.Minutes(ts.Title, ts.TotalMinutes, ts.WatchCount)
+	}
+
+	total := 0
+	for i := range stats {
+		minutes := stats[i].TotalMinutes - overhead[stats[i].ServiceID]
+		stats[i].EffectiveMinutes = &minutes
+		total += minutes
+	}
+	return &total, nil
+}
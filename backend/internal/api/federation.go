@@ -0,0 +1,83 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/federation"
+)
+
+// requireFederationToken rejects peer requests that don't carry the
+// configured federation token as a bearer token
+func (h *Handler) requireFederationToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expected := h.config.Federation.Token
+		if expected == "" {
+			respondError(w, http.StatusServiceUnavailable, "Federation not configured", fmt.Errorf("federation.token is required"))
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respondError(w, http.StatusUnauthorized, "Invalid token", fmt.Errorf("missing or invalid federation token"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// localSummary builds this instance's summary for a period
+func (h *Handler) localSummary(startDate, endDate time.Time) (federation.Summary, error) {
+	stats, err := h.db.GetServiceStats(startDate, endDate)
+	if err != nil {
+		return federation.Summary{}, err
+	}
+	return federation.NewSummary(h.config.Federation.Name, stats), nil
+}
+
+// getFederationSummary returns this instance's per-service totals for peers
+// building a household view
+func (h *Handler) getFederationSummary(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	summary, err := h.localSummary(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to build summary", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, summary)
+}
+
+// getHousehold combines this instance's totals with those of its configured
+// peers for the period in the request
+func (h *Handler) getHousehold(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	local, err := h.localSummary(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to build summary", err)
+		return
+	}
+
+	query := url.Values{}
+	for _, key := range []string{"year", "month"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+	respondJSON(w, http.StatusOK, h.federation.Household(r.Context(), local, query))
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/federation"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+func TestGetHousehold(t *testing.T) {
+	// A peer instance with an hour of Netflix, in its own database since
+	// in-memory databases are shared
+	peerDB, err := database.New(filepath.Join(t.TempDir(), "peer.db"))
+	if err != nil {
+		t.Fatalf("Failed to create peer database: %v", err)
+	}
+	defer peerDB.Close()
+	peerCfg := &config.Config{Federation: config.FederationConfig{Name: "alex", Token: "peer-secret"}}
+	peer := NewHandler(peerDB, scraper.NewManager(peerDB, peerCfg), peerCfg)
+	netflix, _ := peerDB.GetServiceByName("Netflix")
+	peerDB.UpdateServiceEnabled(netflix.ID, true)
+	peerDB.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       netflix.ID,
+		Title:           "Severance",
+		DurationMinutes: 60,
+		WatchedAt:       time.Now(),
+	})
+	server := httptest.NewServer(NewRouter(peer))
+	defer server.Close()
+
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Federation.Name = "me"
+	netflix, _ = db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       netflix.ID,
+		Title:           "The Bear",
+		DurationMinutes: 30,
+		WatchedAt:       time.Now(),
+	})
+	handler.federation = federation.NewClient([]config.PeerConfig{
+		{Name: "Alex", URL: server.URL, Token: "peer-secret"},
+		{Name: "Sam", URL: server.URL, Token: "wrong"},
+	})
+
+	req, _ := http.NewRequest("GET", "/api/household", nil)
+	rr := httptest.NewRecorder()
+	handler.getHousehold(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var household federation.Household
+	if err := json.NewDecoder(rr.Body).Decode(&household); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if household.TotalMinutes != 90 {
+		t.Errorf("Expected 90 household minutes, got %d", household.TotalMinutes)
+	}
+	if len(household.Members) != 3 {
+		t.Fatalf("Expected 3 members, got %d", len(household.Members))
+	}
+	if household.Members[1].Name != "Alex" || household.Members[1].TotalMinutes != 60 {
+		t.Errorf("Expected Alex with 60 minutes, got %+v", household.Members[1])
+	}
+	if household.Members[2].Error == "" {
+		t.Error("Expected an error for the peer with a wrong token")
+	}
+	if len(household.Services) != 1 || household.Services[0].TotalMinutes != 90 {
+		t.Errorf("Expected Netflix summed to 90 minutes, got %+v", household.Services)
+	}
+}
+
+func TestGetFederationSummaryRequiresToken(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	protected := handler.requireFederationToken(handler.getFederationSummary)
+
+	// Disabled when no token is configured
+	req, _ := http.NewRequest("GET", "/api/federation/summary", nil)
+	rr := httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+
+	handler.config.Federation.Token = "secret"
+	req, _ = http.NewRequest("GET", "/api/federation/summary", nil)
+	req.Header.Set("Authorization", "Bearer nope")
+	rr = httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/gaming"
+)
+
+// getGamingSessions returns gaming sessions for a month or year
+func (h *Handler) getGamingSessions(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	sessions, err := h.db.GetGamingSessions(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch gaming sessions", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, sessions)
+}
+
+// addGamingSession records a manually entered session, e.g. for consoles
+// that have no playtime API
+func (h *Handler) addGamingSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Platform string    `json:"platform"`
+		Game     string    `json:"game"`
+		Minutes  int       `json:"minutes"`
+		PlayedAt time.Time `json:"played_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Platform = strings.ToLower(strings.TrimSpace(req.Platform))
+	req.Game = strings.TrimSpace(req.Game)
+	if req.Platform == "" || req.Game == "" {
+		respondError(w, http.StatusBadRequest, "Invalid session", fmt.Errorf("platform and game are required"))
+		return
+	}
+	if req.Minutes <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid session", fmt.Errorf("minutes must be positive"))
+		return
+	}
+	if req.PlayedAt.IsZero() {
+		req.PlayedAt = time.Now()
+	}
+
+	session := &database.GamingSession{
+		Platform: req.Platform,
+		Game:     req.Game,
+		Minutes:  req.Minutes,
+		PlayedAt: req.PlayedAt,
+		Source:   "manual",
+	}
+	if err := h.db.InsertGamingSession(session); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save gaming session", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, session)
+}
+
+// deleteGamingSession removes a gaming session
+func (h *Handler) deleteGamingSession(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid session ID", err)
+		return
+	}
+
+	deleted, err := h.db.DeleteGamingSession(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete gaming session", err)
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Gaming session not found", fmt.Errorf("gaming session with ID %d not found", id))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// syncSteam pulls playtime added since the last sync from the Steam Web API
+func (h *Handler) syncSteam(w http.ResponseWriter, r *http.Request) {
+	steamCfg := h.config.Gaming.Steam
+	if steamCfg.APIKey == "" || steamCfg.SteamID == "" {
+		respondError(w, http.StatusBadRequest, "Steam not configured", fmt.Errorf("gaming.steam.api_key and gaming.steam.steam_id are required"))
+		return
+	}
+
+	client := gaming.NewSteamClient(steamCfg.APIKey, steamCfg.SteamID)
+	result, err := gaming.SyncSteam(r.Context(), h.db, client, time.Now())
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Steam sync failed", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// getScreenTime combines streaming and gaming time into one total
+func (h *Handler) getScreenTime(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	serviceStats, err := db.GetServiceStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+	streamingMinutes := 0
+	for _, stat := range serviceStats {
+		streamingMinutes += stat.TotalMinutes
+	}
+
+	platforms, err := db.GetPlatformStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch gaming stats", err)
+		return
+	}
+	gamingMinutes := 0
+	for _, stat := range platforms {
+		gamingMinutes += stat.TotalMinutes
+	}
+
+	response := map[string]interface{}{
+		"streaming_minutes": streamingMinutes,
+		"gaming_minutes":    gamingMinutes,
+		"total_minutes":     streamingMinutes + gamingMinutes,
+		"services":          serviceStats,
+		"platforms":         platforms,
+		"start_date":        startDate.Format("2006-01-02"),
+		"end_date":          endDate.Format("2006-01-02"),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
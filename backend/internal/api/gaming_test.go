@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestAddAndDeleteGamingSession(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	body := `{"platform": "Switch", "game": "Zelda", "minutes": 95}`
+	req, err := http.NewRequest("POST", "/api/gaming/sessions", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.addGamingSession(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, rr.Body.String())
+	}
+
+	var session database.GamingSession
+	if err := json.NewDecoder(rr.Body).Decode(&session); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if session.Platform != "switch" || session.Source != "manual" {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/gaming/sessions/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr = httptest.NewRecorder()
+	handler.deleteGamingSession(rr, req)
+
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+}
+
+func TestAddGamingSessionInvalid(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("POST", "/api/gaming/sessions", strings.NewReader(`{"platform": "switch", "game": "Zelda"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.addGamingSession(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestSyncSteamNotConfigured(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("POST", "/api/gaming/steam/sync", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.syncSteam(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestGetScreenTime(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	now := time.Now()
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Test Movie", DurationMinutes: 120, WatchedAt: now})
+	db.InsertGamingSession(&database.GamingSession{Platform: "steam", Game: "Portal 2", Minutes: 60, PlayedAt: now})
+
+	req, err := http.NewRequest("GET", "/api/stats/screen-time", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getScreenTime(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var response struct {
+		StreamingMinutes int `json:"streaming_minutes"`
+		GamingMinutes    int `json:"gaming_minutes"`
+		TotalMinutes     int `json:"total_minutes"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.StreamingMinutes != 120 || response.GamingMinutes != 60 || response.TotalMinutes != 180 {
+		t.Errorf("Unexpected screen time: %+v", response)
+	}
+}
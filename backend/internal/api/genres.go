@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// getGenreMappings returns all genre mappings
+func (h *Handler) getGenreMappings(w http.ResponseWriter, r *http.Request) {
+	mappings, err := h.db.GetGenreMappings()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch genre mappings", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mappings)
+}
+
+// addGenreMapping maps a provider's genre name to a normalized genre. Stored
+// history keeps its original genre; stats resolve it through the mapping.
+func (h *Handler) addGenreMapping(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Source string `json:"source"`
+		Genre  string `json:"genre"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Source = strings.TrimSpace(req.Source)
+	req.Genre = strings.TrimSpace(req.Genre)
+	if req.Source == "" || req.Genre == "" {
+		respondError(w, http.StatusBadRequest, "Invalid genre mapping", fmt.Errorf("source and genre are required"))
+		return
+	}
+	if strings.EqualFold(req.Source, req.Genre) {
+		respondError(w, http.StatusBadRequest, "Invalid genre mapping", fmt.Errorf("source and genre must differ"))
+		return
+	}
+
+	gm := &database.GenreMapping{
+		Source: req.Source,
+		Genre:  req.Genre,
+	}
+	if err := h.db.InsertGenreMapping(gm); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to add genre mapping", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, gm)
+}
+
+// deleteGenreMapping removes a genre mapping
+func (h *Handler) deleteGenreMapping(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid genre mapping ID", err)
+		return
+	}
+
+	deleted, err := h.db.DeleteGenreMapping(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete genre mapping", err)
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Genre mapping not found", fmt.Errorf("genre mapping with ID %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deleted": id,
+	})
+}
+
+// getGenreStats returns time per normalized genre
+func (h *Handler) getGenreStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	stats, err := db.GetGenreStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch genre stats", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestAddAndDeleteGenreMapping(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("POST", "/api/genre-mappings", strings.NewReader(`{"source": "Anime Series", "genre": "Anime"}`))
+	rr := httptest.NewRecorder()
+	handler.addGenreMapping(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, status)
+	}
+	var gm database.GenreMapping
+	if err := json.NewDecoder(rr.Body).Decode(&gm); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if gm.ID == 0 || gm.Genre != "Anime" {
+		t.Errorf("Unexpected genre mapping: %+v", gm)
+	}
+
+	id := strconv.FormatInt(gm.ID, 10)
+	req, _ = http.NewRequest("DELETE", "/api/genre-mappings/"+id, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr = httptest.NewRecorder()
+	handler.deleteGenreMapping(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+}
+
+func TestAddGenreMappingRequiresFields(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("POST", "/api/genre-mappings", strings.NewReader(`{"source": "Anime Series"}`))
+	rr := httptest.NewRecorder()
+	handler.addGenreMapping(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/goals"
+)
+
+// getGoals reports screen time streaks and adherence to planned screen-free
+// days over the last N days (?days=90&threshold_minutes=60)
+func (h *Handler) getGoals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	days := parseIntParam(query.Get("days"), 90)
+	threshold := parseIntParam(query.Get("threshold_minutes"), h.config.Goals.StreakThresholdMinutes)
+	if days <= 0 || threshold <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid parameters", fmt.Errorf("days and threshold_minutes must be positive"))
+		return
+	}
+
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	startDate := today.AddDate(0, 0, 1-days)
+	endDate := today.AddDate(0, 0, 1)
+
+	daily, err := db.GetDailyScreenTime(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch screen time", err)
+		return
+	}
+
+	// Include upcoming planned days so users can see what's scheduled
+	planned, err := h.db.GetScreenFreeDays(startDate.Format("2006-01-02"), "9999-12-31")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch screen-free days", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"streaks":     goals.Streaks(daily, startDate, endDate, threshold),
+		"screen_free": goals.EvaluateScreenFree(planned, daily, today, h.config.Goals.ScreenFreeMaxMinutes),
+		"start_date":  startDate.Format("2006-01-02"),
+		"end_date":    today.Format("2006-01-02"),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// getScreenFreeDays lists planned screen-free days (?from=YYYY-MM-DD&to=YYYY-MM-DD)
+func (h *Handler) getScreenFreeDays(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from := query.Get("from")
+	to := query.Get("to")
+	if to == "" {
+		to = "9999-12-31"
+	}
+
+	days, err := h.db.GetScreenFreeDays(from, to)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch screen-free days", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, days)
+}
+
+// addScreenFreeDay plans a screen-free day
+func (h *Handler) addScreenFreeDay(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Date string `json:"date"`
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date", fmt.Errorf("date must be YYYY-MM-DD"))
+		return
+	}
+
+	day := &database.ScreenFreeDay{
+		Date: req.Date,
+		Note: strings.TrimSpace(req.Note),
+	}
+	if err := h.db.UpsertScreenFreeDay(day); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save screen-free day", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, day)
+}
+
+// deleteScreenFreeDay removes a planned screen-free day
+func (h *Handler) deleteScreenFreeDay(w http.ResponseWriter, r *http.Request) {
+	date := mux.Vars(r)["date"]
+
+	deleted, err := h.db.DeleteScreenFreeDay(date)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete screen-free day", err)
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Screen-free day not found", fmt.Errorf("no screen-free day planned on %s", date))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deleted": date,
+	})
+}
+
+// syncScreenFreeDays imports screen-free days for the next year from the
+// configured CalDAV calendar
+func (h *Handler) syncScreenFreeDays(w http.ResponseWriter, r *http.Request) {
+	cfg := h.config.Goals.CalDAV
+	if cfg.URL == "" {
+		respondError(w, http.StatusServiceUnavailable, "CalDAV not configured", fmt.Errorf("goals.caldav.url is required"))
+		return
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -30)
+	client := goals.NewCalDAVClient(cfg.URL, cfg.Username, cfg.Password)
+	days, err := client.FetchDays(r.Context(), start, start.AddDate(1, 0, 30), cfg.Match)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to fetch calendar", err)
+		return
+	}
+
+	for _, day := range days {
+		if err := h.db.UpsertScreenFreeDay(&database.ScreenFreeDay{Date: day.Date, Note: day.Summary, Source: "caldav"}); err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to save screen-free day", err)
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"synced": len(days),
+	})
+}
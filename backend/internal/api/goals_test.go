@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/goals"
+)
+
+func TestGetGoals(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Goals.StreakThresholdMinutes = 60
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       service.ID,
+		Title:           "Show",
+		DurationMinutes: 120,
+		WatchedAt:       yesterday.Add(20 * time.Hour),
+	})
+	db.UpsertScreenFreeDay(&database.ScreenFreeDay{Date: yesterday.Format("2006-01-02")})
+	db.UpsertScreenFreeDay(&database.ScreenFreeDay{Date: today.AddDate(0, 0, -2).Format("2006-01-02")})
+
+	req, err := http.NewRequest("GET", "/api/goals?days=10", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getGoals(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Streaks    goals.StreakStats `json:"streaks"`
+		ScreenFree goals.Adherence   `json:"screen_free"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// Yesterday was over the threshold, so only today counts toward the current streak
+	if response.Streaks.Current.Days != 1 || response.Streaks.Longest.Days != 8 {
+		t.Errorf("Unexpected streaks: %+v", response.Streaks)
+	}
+	if response.ScreenFree.Kept != 1 || response.ScreenFree.Missed != 1 {
+		t.Errorf("Unexpected adherence: %+v", response.ScreenFree)
+	}
+}
+
+func TestGetGoalsFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Goals.StreakThresholdMinutes = 60
+
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	watchAs(t, db, "Alex", "Severance", 30, yesterday.Add(20*time.Hour))
+	watchAs(t, db, "Sam", "Love Is Blind", 120, yesterday.Add(21*time.Hour))
+
+	req, _ := http.NewRequest("GET", "/api/goals?days=10&profile=alex", nil)
+	rr := httptest.NewRecorder()
+	handler.getGoals(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Streaks goals.StreakStats `json:"streaks"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// Sam's two hours yesterday don't break Alex's streak
+	if response.Streaks.Current.Days != 10 {
+		t.Errorf("Expected a 10 day streak, got %+v", response.Streaks)
+	}
+}
+
+func TestAddAndDeleteScreenFreeDay(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("POST", "/api/goals/screen-free-days", strings.NewReader(`{"date": "2025-03-15", "note": "Hiking"}`))
+	rr := httptest.NewRecorder()
+	handler.addScreenFreeDay(rr, req)
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/api/goals/screen-free-days", strings.NewReader(`{"date": "next tuesday"}`))
+	rr = httptest.NewRecorder()
+	handler.addScreenFreeDay(rr, req)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid date, got %d", http.StatusBadRequest, status)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/goals/screen-free-days/2025-03-15", nil)
+	req = mux.SetURLVars(req, map[string]string{"date": "2025-03-15"})
+	rr = httptest.NewRecorder()
+	handler.deleteScreenFreeDay(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.deleteScreenFreeDay(rr, req)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Expected status code %d for missing day, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestSyncScreenFreeDaysNotConfigured(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("POST", "/api/goals/screen-free-days/sync", nil)
+	rr := httptest.NewRecorder()
+	handler.syncScreenFreeDays(rr, req)
+
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, status)
+	}
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// Metric names offered to Grafana. Per-service series are named
+// grafanaServicePrefix followed by the service name, e.g. "watch_time:Netflix".
+const (
+	grafanaWatchTime     = "watch_time"
+	grafanaServicePrefix = "watch_time:"
+	grafanaServices      = "services"
+	grafanaTopTitles     = "top_titles"
+)
+
+// grafanaMaxDays caps the days a single query can span, so a panel zoomed
+// out to years doesn't return an unbounded series
+const grafanaMaxDays = 3660
+
+// grafanaQueryRequest is the body Grafana's JSON datasource posts to /query
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+// grafanaSeries is a time series response: datapoints are [value, unix ms]
+type grafanaSeries struct {
+	Target     string     `json:"target"`
+	Datapoints [][2]int64 `json:"datapoints"`
+}
+
+// grafanaTable is a table response
+type grafanaTable struct {
+	Type    string          `json:"type"`
+	Columns []grafanaColumn `json:"columns"`
+	Rows    [][]any         `json:"rows"`
+}
+
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// grafanaHealth answers the datasource's "Save & test" connection check
+func (h *Handler) grafanaHealth(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// grafanaSearch lists the metrics panels can query: total daily watch time,
+// daily watch time per enabled service, and the services and top titles
+// tables
+func (h *Handler) grafanaSearch(w http.ResponseWriter, r *http.Request) {
+	services, err := h.db.GetAllServices()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+
+	metrics := []string{grafanaWatchTime}
+	for _, svc := range services {
+		if svc.Enabled {
+			metrics = append(metrics, grafanaServicePrefix+svc.Name)
+		}
+	}
+	metrics = append(metrics, grafanaServices, grafanaTopTitles)
+
+	respondJSON(w, http.StatusOK, metrics)
+}
+
+// grafanaQuery answers the JSON datasource's /query over the requested time
+// range. Watch time series have one point per day (UTC), in minutes; the
+// services and top_titles targets return tables whatever type was asked for.
+func (h *Handler) grafanaQuery(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	startDate := req.Range.From.UTC().Truncate(24 * time.Hour)
+	endDate := req.Range.To.UTC()
+	if !endDate.After(startDate) {
+		respondError(w, http.StatusBadRequest, "Invalid range", fmt.Errorf("range.to must be after range.from"))
+		return
+	}
+	if endDate.Sub(startDate) > grafanaMaxDays*24*time.Hour {
+		startDate = endDate.Truncate(24*time.Hour).AddDate(0, 0, -grafanaMaxDays)
+	}
+
+	results := []any{}
+	for _, target := range req.Targets {
+		var result any
+		var err error
+		switch {
+		case target.Target == grafanaWatchTime:
+			result, err = h.grafanaTotalSeries(db, startDate, endDate)
+		case strings.HasPrefix(target.Target, grafanaServicePrefix):
+			result, err = h.grafanaServiceSeries(db, strings.TrimPrefix(target.Target, grafanaServicePrefix), startDate, endDate)
+		case target.Target == grafanaServices:
+			result, err = h.grafanaServicesTable(db, startDate, endDate)
+		case target.Target == grafanaTopTitles:
+			result, err = h.grafanaTopTitlesTable(db, startDate, endDate)
+		case target.Target == "":
+			continue // A panel whose query hasn't been picked yet
+		default:
+			respondError(w, http.StatusBadRequest, "Unknown target", fmt.Errorf("no metric named %q", target.Target))
+			return
+		}
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to query "+target.Target, err)
+			return
+		}
+		results = append(results, result)
+	}
+
+	respondJSON(w, http.StatusOK, results)
+}
+
+func (h *Handler) grafanaTotalSeries(db *database.DB, startDate, endDate time.Time) (any, error) {
+	daily, err := db.GetDailyTotals(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaDailySeries(grafanaWatchTime, daily, startDate, endDate), nil
+}
+
+func (h *Handler) grafanaServiceSeries(db *database.DB, name string, startDate, endDate time.Time) (any, error) {
+	service, err := h.db.GetServiceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, fmt.Errorf("service %q not found", name)
+	}
+	daily, err := db.GetDailyStats(service.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return grafanaDailySeries(grafanaServicePrefix+service.Name, daily, startDate, endDate), nil
+}
+
+func (h *Handler) grafanaServicesTable(db *database.DB, startDate, endDate time.Time) (any, error) {
+	stats, err := db.GetServiceStats(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	table := grafanaTable{
+		Type: "table",
+		Columns: []grafanaColumn{
+			{Text: "Service", Type: "string"},
+			{Text: "Minutes", Type: "number"},
+			{Text: "Items", Type: "number"},
+		},
+		Rows: [][]any{},
+	}
+	for _, stat := range stats {
+		table.Rows = append(table.Rows, []any{stat.ServiceName, stat.TotalMinutes, stat.TotalShows})
+	}
+	return table, nil
+}
+
+func (h *Handler) grafanaTopTitlesTable(db *database.DB, startDate, endDate time.Time) (any, error) {
+	titles, err := db.GetTopTitles(startDate, endDate, overviewTopTitles)
+	if err != nil {
+		return nil, err
+	}
+	table := grafanaTable{
+		Type: "table",
+		Columns: []grafanaColumn{
+			{Text: "Title", Type: "string"},
+			{Text: "Minutes", Type: "number"},
+			{Text: "Plays", Type: "number"},
+		},
+		Rows: [][]any{},
+	}
+	for _, title := range titles {
+		table.Rows = append(table.Rows, []any{title.Title, title.TotalMinutes, title.WatchCount})
+	}
+	return table, nil
+}
+
+// grafanaDailySeries turns daily minutes (keyed YYYY-MM-DD) into a series
+// with a point for every day in the range, so days without viewing plot as
+// zero rather than being interpolated over
+func grafanaDailySeries(target string, daily map[string]int, startDate, endDate time.Time) grafanaSeries {
+	series := grafanaSeries{Target: target, Datapoints: [][2]int64{}}
+	for day := startDate; day.Before(endDate); day = day.AddDate(0, 0, 1) {
+		minutes := daily[day.Format("2006-01-02")]
+		series.Datapoints = append(series.Datapoints, [2]int64{int64(minutes), day.UnixMilli()})
+	}
+	return series
+}
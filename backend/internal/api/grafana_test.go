@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGrafanaSearch(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+
+	req, _ := http.NewRequest("POST", "/api/grafana/search", strings.NewReader(`{"target":""}`))
+	rr := httptest.NewRecorder()
+	handler.grafanaSearch(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var metrics []string
+	if err := json.NewDecoder(rr.Body).Decode(&metrics); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	for _, want := range []string{"watch_time", "watch_time:Netflix", "services", "top_titles"} {
+		if !contains(metrics, want) {
+			t.Errorf("Expected %q among the metrics, got %v", want, metrics)
+		}
+	}
+}
+
+func TestGrafanaQuery(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	day := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: day.Add(20 * time.Hour)})
+
+	body := `{
+		"range": {"from": "2025-03-01T00:00:00Z", "to": "2025-03-03T23:59:59Z"},
+		"targets": [
+			{"target": "watch_time", "refId": "A", "type": "timeserie"},
+			{"target": "watch_time:Netflix", "refId": "B", "type": "timeserie"},
+			{"target": "services", "refId": "C", "type": "table"}
+		]
+	}`
+	req, _ := http.NewRequest("POST", "/api/grafana/query", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.grafanaQuery(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var results []json.RawMessage
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected a result per target, got %d", len(results))
+	}
+
+	for i, target := range []string{"watch_time", "watch_time:Netflix"} {
+		var series grafanaSeries
+		json.Unmarshal(results[i], &series)
+		if series.Target != target || len(series.Datapoints) != 3 {
+			t.Fatalf("Expected three daily points for %s, got %+v", target, series)
+		}
+		if point := series.Datapoints[1]; point[0] != 50 || point[1] != day.UnixMilli() {
+			t.Errorf("%s: expected 50 minutes on March 2, got %v", target, point)
+		}
+		if series.Datapoints[0][0] != 0 {
+			t.Errorf("%s: expected days without viewing to be zero, got %v", target, series.Datapoints[0])
+		}
+	}
+
+	var table grafanaTable
+	json.Unmarshal(results[2], &table)
+	if table.Type != "table" || len(table.Rows) == 0 || table.Rows[0][0] != "Netflix" {
+		t.Errorf("Expected a services table led by Netflix, got %+v", table)
+	}
+}
+
+func TestGrafanaQueryFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	day := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	watchAs(t, db, "Alex", "Severance", 50, day.Add(20*time.Hour))
+	watchAs(t, db, "Sam", "Love Is Blind", 60, day.Add(21*time.Hour))
+
+	body := `{
+		"range": {"from": "2025-03-02T00:00:00Z", "to": "2025-03-02T23:59:59Z"},
+		"targets": [
+			{"target": "watch_time", "refId": "A", "type": "timeserie"},
+			{"target": "top_titles", "refId": "B", "type": "table"}
+		]
+	}`
+	req, _ := http.NewRequest("POST", "/api/grafana/query?profile=alex", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.grafanaQuery(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var results []json.RawMessage
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil || len(results) != 2 {
+		t.Fatalf("Expected a result per target, got %s (%v)", rr.Body.String(), err)
+	}
+	var series grafanaSeries
+	json.Unmarshal(results[0], &series)
+	if len(series.Datapoints) != 1 || series.Datapoints[0][0] != 50 {
+		t.Errorf("Expected only Alex's 50 minutes, got %+v", series)
+	}
+	var table grafanaTable
+	json.Unmarshal(results[1], &table)
+	if len(table.Rows) != 1 || table.Rows[0][0] != "Severance" {
+		t.Errorf("Expected only Alex's titles, got %+v", table.Rows)
+	}
+}
+
+func TestGrafanaQueryUnknownTarget(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	body := `{"range": {"from": "2025-03-01T00:00:00Z", "to": "2025-03-02T00:00:00Z"}, "targets": [{"target": "bogus"}]}`
+	req, _ := http.NewRequest("POST", "/api/grafana/query", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.grafanaQuery(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
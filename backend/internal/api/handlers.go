@@ -1,17 +1,21 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jgoulah/streamtime/internal/config"
 	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/federation"
+	"github.com/jgoulah/streamtime/internal/integrations/trakt"
 	"github.com/jgoulah/streamtime/internal/scraper"
+	"github.com/jgoulah/streamtime/internal/tmdb"
 )
 
 // Handler holds dependencies for API handlers
@@ -19,15 +23,32 @@ type Handler struct {
 	db             *database.DB
 	scraperManager *scraper.Manager
 	config         *config.Config
+	badges         *badgeCache
+	tmdb           *tmdb.Client // nil when no TMDB API key is configured
+	federation     *federation.Client
+	trakt          *trakt.Syncer // nil when no Trakt API app is configured
 }
 
 // NewHandler creates a new API handler
 func NewHandler(db *database.DB, scraperMgr *scraper.Manager, cfg *config.Config) *Handler {
-	return &Handler{
+	h := &Handler{
 		db:             db,
 		scraperManager: scraperMgr,
 		config:         cfg,
+		badges:         newBadgeCache(),
+		federation:     federation.NewClient(cfg.Federation.Peers),
 	}
+	if cfg.TMDB.APIKey != "" {
+		h.tmdb = tmdb.NewClient(cfg.TMDB.APIKey)
+		h.tmdb.SetCache(db)
+	}
+	if cfg.Trakt.ClientID != "" {
+		h.trakt = trakt.NewSyncer(cfg.Trakt, db)
+		if cfg.Trakt.SyncAfterScrape && scraperMgr != nil {
+			scraperMgr.OnRunComplete(h.trakt.HandleResult)
+		}
+	}
+	return h
 }
 
 // healthCheck returns the health status of the API
@@ -40,54 +61,41 @@ func (h *Handler) healthCheck(w http.ResponseWriter, r *http.Request) {
 
 // getServices returns all services with their statistics
 func (h *Handler) getServices(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters for date range
-	query := r.URL.Query()
-
-	var startDate, endDate time.Time
-
-	// Check if specific year or month is requested
-	yearStr := query.Get("year")
-	monthStr := query.Get("month")
-
-	if yearStr != "" {
-		// Specific year requested
-		year, err := strconv.Atoi(yearStr)
-		if err != nil || year < 2000 || year > 2100 {
-			respondError(w, http.StatusBadRequest, "Invalid year parameter", fmt.Errorf("year must be between 2000 and 2100"))
-			return
-		}
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
 
-		if monthStr != "" {
-			// Specific month within year
-			month, err := strconv.Atoi(monthStr)
-			if err != nil || month < 1 || month > 12 {
-				respondError(w, http.StatusBadRequest, "Invalid month parameter", fmt.Errorf("month must be between 1 and 12"))
-				return
-			}
-			startDate = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-			endDate = startDate.AddDate(0, 1, 0)
-		} else {
-			// Entire year
-			startDate = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-			endDate = time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
-		}
-	} else {
-		// All-time stats (default)
-		startDate = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
-		endDate = time.Now().AddDate(1, 0, 0) // One year from now
+	// Parse query parameters for date range
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
 	}
 
-	stats, err := h.db.GetServiceStats(startDate, endDate)
+	stats, err := db.GetServiceStats(startDate, endDate)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
 		return
 	}
+	if _, err := h.addEffectiveMinutes(db, stats, startDate, endDate); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to estimate effective minutes", err)
+		return
+	}
+	h.addAdMinutes(stats)
 
 	respondJSON(w, http.StatusOK, stats)
 }
 
 // getServiceHistory returns watch history for a specific service
 func (h *Handler) getServiceHistory(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
 	vars := mux.Vars(r)
 	serviceID, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
@@ -138,7 +146,7 @@ func (h *Handler) getServiceHistory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get service info
-	service, err := h.db.GetServiceByID(serviceID)
+	service, err := db.GetServiceByID(serviceID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
 		return
@@ -152,14 +160,14 @@ func (h *Handler) getServiceHistory(w http.ResponseWriter, r *http.Request) {
 	limit := parseIntParam(query.Get("limit"), 100)
 	offset := parseIntParam(query.Get("offset"), 0)
 
-	history, err := h.db.GetWatchHistory(serviceID, startDate, endDate, limit, offset)
+	history, err := db.GetWatchHistory(serviceID, startDate, endDate, limit, offset)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to fetch history", err)
 		return
 	}
 
 	// Get daily stats for charting
-	dailyStats, err := h.db.GetDailyStats(serviceID, startDate, endDate)
+	dailyStats, err := db.GetDailyStats(serviceID, startDate, endDate)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to fetch daily stats", err)
 		return
@@ -176,58 +184,119 @@ func (h *Handler) getServiceHistory(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, response)
 }
 
-// triggerScrape manually triggers a scraper for a specific service
+// triggerScrape manually triggers a scraper for a specific service. Passing
+// ?since=2025-06-01 or ?days=30 re-scrapes that window, upserting corrections to
+// history that was already stored, and ?limit=10 stops after that many items
+// for a quick verification run.
 func (h *Handler) triggerScrape(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	serviceName := vars["service"]
 
-	// Capitalize service name to match database format (e.g., "netflix" -> "Netflix")
-	serviceNameCapitalized := capitalizeServiceName(serviceName)
-
-	// Run scraper in background (with timeout)
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
-
-		result, err := h.scraperManager.Run(ctx, serviceNameCapitalized)
+	// Manual triggers override the failure backoff circuit
+	opts := scraper.RunOptions{Force: true}
+	query := r.URL.Query()
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		since, err := time.Parse("2006-01-02", sinceStr)
 		if err != nil {
-			// Error is already logged in scraper manager
+			respondError(w, http.StatusBadRequest, "Invalid since parameter", err)
+			return
+		}
+		opts.Since = since
+	} else if daysStr := query.Get("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			respondError(w, http.StatusBadRequest, "Invalid days parameter", fmt.Errorf("days must be a positive number"))
+			return
+		}
+		opts.Since = time.Now().AddDate(0, 0, -days)
+	}
+	if opts.Since.After(time.Now()) {
+		respondError(w, http.StatusBadRequest, "Invalid since parameter", fmt.Errorf("since must be in the past"))
+		return
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			respondError(w, http.StatusBadRequest, "Invalid limit parameter", fmt.Errorf("limit must be a positive number"))
 			return
 		}
+		opts.Limit = limit
+	}
+	opts.Review = query.Get("review") == "true"
+
+	// Resolve the service key to its database name (e.g., "netflix" -> "Netflix"),
+	// including configured instances like "netflix_kids"
+	serviceNameCapitalized := h.serviceNameFor(serviceName)
 
-		// Log result
-		if result.Success {
-			// Successfully scraped
+	// Refuse up front rather than failing the job when the service can't be
+	// scraped
+	if service, err := h.db.GetServiceByName(serviceNameCapitalized); err == nil && service != nil {
+		if !service.Enabled || service.Archived {
+			respondError(w, http.StatusConflict, "Service is disabled or archived", scraper.ErrServiceInactive)
+			return
+		}
+		if consent, err := h.scraperManager.Consent(service); err == nil && !consent.Allowed {
+			respondError(w, http.StatusForbidden, "Automation risk not acknowledged", scraper.ErrNoConsent)
 			return
 		}
-	}()
+	}
+
+	// Run scraper in background (with timeout), tracked as a job the frontend can poll
+	job := h.scraperManager.StartJob(serviceNameCapitalized, opts, 10*time.Minute)
 
 	// Return immediate response
-	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+	response := map[string]interface{}{
 		"message": "Scraper triggered",
 		"service": serviceName,
-		"status":  "running",
-	})
+		"status":  job.State,
+		"job_id":  job.ID,
+	}
+	if !opts.Since.IsZero() {
+		response["since"] = opts.Since.Format("2006-01-02")
+	}
+	if opts.Limit > 0 {
+		response["limit"] = opts.Limit
+	}
+	if opts.Review {
+		response["review"] = true
+	}
+	respondJSON(w, http.StatusAccepted, response)
 }
 
-// capitalizeServiceName converts service names to database format
-func capitalizeServiceName(name string) string {
-	switch name {
-	case "netflix":
-		return "Netflix"
-	case "youtube_tv":
-		return "YouTube TV"
-	case "amazon_video":
-		return "Amazon Video"
-	case "hbo_max":
-		return "HBO Max"
-	case "apple_tv":
-		return "Apple TV+"
-	case "peacock":
-		return "Peacock"
-	default:
+// serviceNameFor maps a service key to its database service name: the
+// service the key routes to, or for configured instances without a service
+// row yet the name their scraper would create. Anything else is taken as a
+// service name, following renames.
+func (h *Handler) serviceNameFor(key string) string {
+	if svc, err := h.db.GetServiceByKey(key); err == nil && svc != nil {
+		return svc.Name
+	}
+	if name := scraper.ServiceNameFor(h.config, key); name != "" {
 		return name
 	}
+	return database.CurrentServiceName(key)
+}
+
+// getScrapeJobs returns recently triggered scrape jobs, newest first
+func (h *Handler) getScrapeJobs(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.scraperManager.Jobs())
+}
+
+// getScrapeJob returns the progress of a triggered scrape
+func (h *Handler) getScrapeJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+
+	job, ok := h.scraperManager.GetJob(id)
+	if !ok {
+		respondError(w, http.StatusNotFound, "Job not found", fmt.Errorf("job %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
 }
 
 // getScraperStatus returns the status of recent scraper runs
@@ -241,6 +310,73 @@ func (h *Handler) getScraperStatus(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, runs)
 }
 
+// getScraperCircuits returns the failure backoff state of each scraper
+func (h *Handler) getScraperCircuits(w http.ResponseWriter, r *http.Request) {
+	states, err := h.scraperManager.CircuitStates()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch scraper circuits", err)
+		return
+	}
+	if states == nil {
+		states = []scraper.CircuitState{}
+	}
+
+	respondJSON(w, http.StatusOK, states)
+}
+
+// getScraperReliability returns each service's run success rate, counting
+// runs since ?days=N ago (default all time) including pruned ones
+func (h *Handler) getScraperReliability(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if days := parseIntParam(r.URL.Query().Get("days"), 0); days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
+
+	reliability, err := h.db.GetRunReliability(since)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch scraper reliability", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, reliability)
+}
+
+// checkServiceAuth loads a service's account page with its cookies to report
+// whether they're still signed in, which takes seconds instead of a full scrape
+func (h *Handler) checkServiceAuth(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service ID", err)
+		return
+	}
+
+	service, err := h.db.GetServiceByID(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+		return
+	}
+	if service == nil {
+		respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service with ID %d not found", id))
+		return
+	}
+
+	status, err := h.scraperManager.CheckAuth(r.Context(), service.Name)
+	switch {
+	case errors.Is(err, scraper.ErrScraperNotFound):
+		respondError(w, http.StatusNotFound, "No scraper configured for service", err)
+		return
+	case errors.Is(err, scraper.ErrAuthCheckUnsupported):
+		respondError(w, http.StatusNotImplemented, "Service does not support auth checks", err)
+		return
+	case err != nil:
+		respondError(w, http.StatusBadGateway, "Failed to check authentication", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
 // Helper functions
 
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -257,6 +393,68 @@ func respondError(w http.ResponseWriter, status int, message string, err error)
 	respondJSON(w, status, response)
 }
 
+// parseYearMonthRange returns the date range selected by optional year and
+// month query parameters, defaulting to all-time
+func parseYearMonthRange(query url.Values) (time.Time, time.Time, error) {
+	var startDate, endDate time.Time
+
+	// Check if specific year or month is requested
+	yearStr := query.Get("year")
+	monthStr := query.Get("month")
+
+	if yearStr != "" {
+		// Specific year requested
+		year, err := strconv.Atoi(yearStr)
+		if err != nil || year < 2000 || year > 2100 {
+			return startDate, endDate, fmt.Errorf("year must be between 2000 and 2100")
+		}
+
+		if monthStr != "" {
+			// Specific month within year
+			month, err := strconv.Atoi(monthStr)
+			if err != nil || month < 1 || month > 12 {
+				return startDate, endDate, fmt.Errorf("month must be between 1 and 12")
+			}
+			startDate = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+			endDate = startDate.AddDate(0, 1, 0)
+		} else {
+			// Entire year
+			startDate = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+			endDate = time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+	} else {
+		// All-time stats (default)
+		startDate = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		endDate = time.Now().AddDate(1, 0, 0) // One year from now
+	}
+
+	return startDate, endDate, nil
+}
+
+// parseDateRange returns the range selected by inclusive YYYY-MM-DD start and
+// end query parameters as [start, end), using the defaults for missing ones
+func parseDateRange(query url.Values, startKey, endKey string, defaultStart, defaultEnd time.Time) (time.Time, time.Time, error) {
+	startDate, endDate := defaultStart, defaultEnd
+	if startStr := query.Get(startKey); startStr != "" {
+		start, err := time.Parse("2006-01-02", startStr)
+		if err != nil {
+			return startDate, endDate, fmt.Errorf("%s must be YYYY-MM-DD", startKey)
+		}
+		startDate = start
+	}
+	if endStr := query.Get(endKey); endStr != "" {
+		end, err := time.Parse("2006-01-02", endStr)
+		if err != nil {
+			return startDate, endDate, fmt.Errorf("%s must be YYYY-MM-DD", endKey)
+		}
+		endDate = end.AddDate(0, 0, 1)
+	}
+	if !endDate.After(startDate) {
+		return startDate, endDate, fmt.Errorf("%s must not be before %s", endKey, startKey)
+	}
+	return startDate, endDate, nil
+}
+
 func parseDate(dateStr string, defaultDate time.Time) time.Time {
 	if dateStr == "" {
 		return defaultDate
@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +34,11 @@ func setupTestAPI(t *testing.T) (*Handler, *database.DB) {
 		},
 	}
 
+	// Netflix is configured, so it's enabled like the scraper factory would
+	if err := db.EnableService("Netflix"); err != nil {
+		t.Fatalf("Failed to enable Netflix: %v", err)
+	}
+
 	scraperMgr := scraper.NewManager(db, cfg)
 	handler := NewHandler(db, scraperMgr, cfg)
 	return handler, db
@@ -124,6 +132,53 @@ func TestGetServices(t *testing.T) {
 	}
 }
 
+func TestGetServicesEffectiveMinutes(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Insights.EffectiveTime = config.EffectiveTimeConfig{
+		IntroPercent:   5,
+		CreditsPercent: 5,
+		Shows:          map[string]config.ShowOverheadConfig{"The Office": {IntroMinutes: 0.5, CreditsMinutes: 0.5}},
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	now := time.Now()
+	for i, wh := range []*database.WatchHistory{
+		{Title: "Severance", DurationMinutes: 100},
+		{Title: "The Office", DurationMinutes: 22},
+		{Title: "The Office", DurationMinutes: 22},
+		{Title: "Dune", DurationMinutes: 60, MediaKind: database.MediaKindAudio},
+	} {
+		wh.ServiceID = service.ID
+		wh.WatchedAt = now.Add(-time.Duration(i) * time.Minute)
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/services", nil)
+	rr := httptest.NewRecorder()
+	handler.getServices(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var stats []database.ServiceStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	for _, stat := range stats {
+		if stat.ServiceName != "Netflix" {
+			continue
+		}
+		// 90% of Severance, The Office less a minute per episode, and the audiobook in full
+		if stat.TotalMinutes != 204 || stat.EffectiveMinutes == nil || *stat.EffectiveMinutes != 192 {
+			t.Errorf("Expected 192 of 204 minutes to be content, got %+v", stat)
+		}
+	}
+}
+
 func TestGetServiceHistory(t *testing.T) {
 	handler, db := setupTestAPI(t)
 	defer db.Close()
@@ -289,6 +344,39 @@ func TestTriggerScrape(t *testing.T) {
 	}
 }
 
+func TestTriggerScrapeWindow(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{"?since=2025-06-01", http.StatusAccepted},
+		{"?days=30", http.StatusAccepted},
+		{"?since=June", http.StatusBadRequest},
+		{"?days=-5", http.StatusBadRequest},
+		{"?since=2999-01-01", http.StatusBadRequest},
+		{"?limit=10", http.StatusAccepted},
+		{"?limit=0", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", "/api/scrape/netflix"+tt.query, nil)
+		req = mux.SetURLVars(req, map[string]string{"service": "netflix"})
+
+		rr := httptest.NewRecorder()
+		handler.triggerScrape(rr, req)
+
+		if status := rr.Code; status != tt.wantStatus {
+			t.Errorf("Expected status code %d for %s, got %d", tt.wantStatus, tt.query, status)
+		}
+		if tt.query == "?since=2025-06-01" && !strings.Contains(rr.Body.String(), `"since":"2025-06-01"`) {
+			t.Errorf("Expected since in response, got %s", rr.Body.String())
+		}
+	}
+}
+
 func TestGetScraperStatus(t *testing.T) {
 	handler, db := setupTestAPI(t)
 	defer db.Close()
@@ -386,3 +474,89 @@ func TestParseIntParamHelper(t *testing.T) {
 		t.Errorf("Expected default 10 for invalid input, got %d", result)
 	}
 }
+
+func TestCheckServiceAuth(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	// No scrapers are registered in tests
+	service, _ := db.GetServiceByName("Netflix")
+	id := strconv.FormatInt(service.ID, 10)
+	req, _ := http.NewRequest("POST", "/api/services/"+id+"/check-auth", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rr := httptest.NewRecorder()
+	handler.checkServiceAuth(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Expected status code %d without a scraper, got %d", http.StatusNotFound, status)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/services/999/check-auth", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "999"})
+	rr = httptest.NewRecorder()
+	handler.checkServiceAuth(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a missing service, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestGetScrapeJob(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	job := handler.scraperManager.StartJob("Netflix", scraper.RunOptions{}, time.Minute)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/api/scrape/jobs/%d", job.ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": strconv.FormatInt(job.ID, 10)})
+	rr := httptest.NewRecorder()
+	handler.getScrapeJob(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var got scraper.Job
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got.ID != job.ID || got.ServiceName != "Netflix" {
+		t.Errorf("Unexpected job: %+v", got)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/scrape/jobs/999", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "999"})
+	rr = httptest.NewRecorder()
+	handler.getScrapeJob(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestGetScraperReliability(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: time.Now(), Status: "success"})
+
+	req, _ := http.NewRequest("GET", "/api/scraper/reliability?days=7", nil)
+	rr := httptest.NewRecorder()
+	handler.getScraperReliability(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var reliability []database.RunReliability
+	if err := json.NewDecoder(rr.Body).Decode(&reliability); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	var found bool
+	for _, r := range reliability {
+		if r.ServiceName == "Netflix" {
+			found = r.Runs > 0 && r.SuccessRate > 0
+		}
+	}
+	if !found {
+		t.Errorf("Expected Netflix's recent run counted, got %+v", reliability)
+	}
+}
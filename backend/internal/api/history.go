@@ -0,0 +1,255 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// bulkEditRequest is the body of PATCH /api/history/bulk
+type bulkEditRequest struct {
+	Filter struct {
+		TitlePattern    string `json:"title_pattern"`
+		ServiceID       int64  `json:"service_id"`
+		StartDate       string `json:"start_date"` // YYYY-MM-DD, inclusive
+		EndDate         string `json:"end_date"`   // YYYY-MM-DD, inclusive
+		DurationMinutes *int   `json:"duration_minutes"`
+	} `json:"filter"`
+	Update struct {
+		ServiceID       int64   `json:"service_id"`
+		DurationMinutes *int    `json:"duration_minutes"`
+		Genre           *string `json:"genre"`
+	} `json:"update"`
+	DryRun *bool `json:"dry_run"` // Defaults to true so the match count can be checked first
+}
+
+// bulkEditHistory fixes systematic scrape errors by updating every history row
+// matching a filter, e.g. setting real durations on 45-minute placeholders.
+// Requests are dry runs unless "dry_run": false is sent.
+func (h *Handler) bulkEditHistory(w http.ResponseWriter, r *http.Request) {
+	var req bulkEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	filter := database.HistoryFilter{
+		TitlePattern:    req.Filter.TitlePattern,
+		ServiceID:       req.Filter.ServiceID,
+		DurationMinutes: req.Filter.DurationMinutes,
+	}
+	if req.Filter.StartDate != "" {
+		start, err := time.Parse("2006-01-02", req.Filter.StartDate)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid start_date", err)
+			return
+		}
+		filter.StartDate = start
+	}
+	if req.Filter.EndDate != "" {
+		end, err := time.Parse("2006-01-02", req.Filter.EndDate)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid end_date", err)
+			return
+		}
+		filter.EndDate = end.AddDate(0, 0, 1)
+	}
+
+	update := database.HistoryUpdate{
+		ServiceID:       req.Update.ServiceID,
+		DurationMinutes: req.Update.DurationMinutes,
+		Genre:           req.Update.Genre,
+	}
+	if update.DurationMinutes != nil && *update.DurationMinutes < 0 {
+		respondError(w, http.StatusBadRequest, "Invalid duration_minutes", fmt.Errorf("duration must not be negative"))
+		return
+	}
+	if update.ServiceID != 0 {
+		service, err := h.db.GetServiceByID(update.ServiceID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+			return
+		}
+		if service == nil {
+			respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service with ID %d not found", update.ServiceID))
+			return
+		}
+	}
+
+	dryRun := req.DryRun == nil || *req.DryRun
+	if filter.IsEmpty() || update.IsEmpty() {
+		respondError(w, http.StatusBadRequest, "Invalid bulk edit", fmt.Errorf("both a filter and at least one update field are required"))
+		return
+	}
+
+	result, err := h.db.BulkEditHistory(filter, update, dryRun)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to edit history", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// deleteServiceHistory deletes a service's history between ?start= and ?end=
+// (inclusive YYYY-MM-DD), e.g. entries a broken scrape stored with wrong
+// dates, so the window can be scraped again with POST /api/scrape/{service}
+// and ?since=. Pass ?dry_run=true to only count what would be deleted.
+func (h *Handler) deleteServiceHistory(w http.ResponseWriter, r *http.Request) {
+	serviceID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service ID", err)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("start") == "" || query.Get("end") == "" {
+		respondError(w, http.StatusBadRequest, "Invalid date range", fmt.Errorf("start and end are required"))
+		return
+	}
+	startDate, endDate, err := parseDateRange(query, "start", "end", time.Time{}, time.Time{})
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date range", err)
+		return
+	}
+	dryRun, _ := strconv.ParseBool(query.Get("dry_run"))
+
+	service, err := h.db.GetServiceByID(serviceID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+		return
+	}
+	if service == nil {
+		respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service with ID %d not found", serviceID))
+		return
+	}
+
+	filter := database.HistoryFilter{ServiceID: serviceID, StartDate: startDate, EndDate: endDate}
+	result, err := h.db.BulkDeleteHistory(filter, dryRun)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete history", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
+// maxNotesLength caps history notes so they stay short annotations
+const maxNotesLength = 1000
+
+// updateHistoryNotes sets the notes on a history entry, e.g. "fell asleep
+// halfway". An empty string clears them.
+func (h *Handler) updateHistoryNotes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid history ID", err)
+		return
+	}
+
+	var req struct {
+		Notes string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Notes = strings.TrimSpace(req.Notes)
+	if len(req.Notes) > maxNotesLength {
+		respondError(w, http.StatusBadRequest, "Invalid notes", fmt.Errorf("notes must be at most %d characters", maxNotesLength))
+		return
+	}
+
+	found, err := h.db.UpdateWatchHistoryNotes(id, req.Notes)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update notes", err)
+		return
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "History entry not found", fmt.Errorf("history entry with ID %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"id":    id,
+		"notes": req.Notes,
+	})
+}
+
+// updateHistoryRating sets a personal 1-5 rating on a history entry. A rating
+// of 0 clears it.
+func (h *Handler) updateHistoryRating(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid history ID", err)
+		return
+	}
+
+	var req struct {
+		Rating int `json:"rating"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.Rating < 0 || req.Rating > 5 {
+		respondError(w, http.StatusBadRequest, "Invalid rating", fmt.Errorf("rating must be between 1 and 5, or 0 to clear"))
+		return
+	}
+
+	found, err := h.db.UpdateWatchHistoryRating(id, req.Rating)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update rating", err)
+		return
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "History entry not found", fmt.Errorf("history entry with ID %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"id":     id,
+		"rating": req.Rating,
+	})
+}
+
+// searchHistory finds history entries across services whose title, episode or
+// notes contain the query
+func (h *Handler) searchHistory(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		respondError(w, http.StatusBadRequest, "Invalid search", fmt.Errorf("q is required"))
+		return
+	}
+
+	limit := parseIntParam(query.Get("limit"), 50)
+	if limit <= 0 {
+		limit = 50
+	}
+
+	results, err := db.SearchWatchHistory(q, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to search history", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"query":   q,
+		"results": results,
+	})
+}
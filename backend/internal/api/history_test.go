@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestBulkEditHistory(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("YouTube TV")
+	watchedAt := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Evening News", DurationMinutes: 45, WatchedAt: watchedAt})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Morning News", DurationMinutes: 45, WatchedAt: watchedAt.AddDate(0, 1, 0)})
+
+	body := `{
+		"filter": {"title_pattern": "*News", "start_date": "2025-03-01", "end_date": "2025-03-31"},
+		"update": {"duration_minutes": 30, "genre": "News"}
+	}`
+	req, err := http.NewRequest("PATCH", "/api/history/bulk", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.bulkEditHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var result database.BulkEditResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	// Dry run by default
+	if !result.DryRun || result.Matched != 1 || result.Updated != 0 {
+		t.Errorf("Unexpected dry run result: %+v", result)
+	}
+
+	body = strings.Replace(body, `"update"`, `"dry_run": false, "update"`, 1)
+	req, _ = http.NewRequest("PATCH", "/api/history/bulk", strings.NewReader(body))
+	rr = httptest.NewRecorder()
+	handler.bulkEditHistory(rr, req)
+
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.DryRun || result.Updated != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	var genre string
+	var minutes int
+	db.QueryRow(`SELECT genre, duration_minutes FROM watch_history WHERE title = 'Evening News'`).Scan(&genre, &minutes)
+	if genre != "News" || minutes != 30 {
+		t.Errorf("Expected edited row, got genre '%s' and %d minutes", genre, minutes)
+	}
+}
+
+func TestDeleteServiceHistory(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	hulu, _ := db.GetServiceByName("Hulu")
+	netflix, _ := db.GetServiceByName("Netflix")
+	day := time.Date(2025, 5, 10, 20, 0, 0, 0, time.UTC)
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: hulu.ID, Title: "Shogun", DurationMinutes: 60, WatchedAt: day},
+		{ServiceID: hulu.ID, Title: "Abbott Elementary", DurationMinutes: 22, WatchedAt: day.AddDate(0, 0, 1)},
+		{ServiceID: hulu.ID, Title: "The Bear", DurationMinutes: 30, WatchedAt: day.AddDate(0, 0, 2)},
+		{ServiceID: netflix.ID, Title: "Ripley", DurationMinutes: 55, WatchedAt: day},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	deleteHistory := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/api/services/"+strconv.FormatInt(hulu.ID, 10)+"/history?"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": strconv.FormatInt(hulu.ID, 10)})
+		rr := httptest.NewRecorder()
+		handler.deleteServiceHistory(rr, req)
+		return rr
+	}
+
+	var result database.BulkDeleteResult
+	rr := deleteHistory("start=2025-05-10&end=2025-05-11&dry_run=true")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	json.NewDecoder(rr.Body).Decode(&result)
+	if !result.DryRun || result.Matched != 2 || result.Deleted != 0 || len(result.Sample) != 2 {
+		t.Errorf("Unexpected dry run result: %+v", result)
+	}
+
+	rr = deleteHistory("start=2025-05-10&end=2025-05-11")
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.DryRun || result.Deleted != 2 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Only the window on that service is gone
+	var remaining []string
+	rows, _ := db.Query(`SELECT title FROM watch_history ORDER BY title`)
+	for rows.Next() {
+		var title string
+		rows.Scan(&title)
+		remaining = append(remaining, title)
+	}
+	rows.Close()
+	if strings.Join(remaining, ",") != "Ripley,The Bear" {
+		t.Errorf("Expected Ripley and The Bear to remain, got %v", remaining)
+	}
+
+	for _, query := range []string{"start=2025-05-10", "start=2025-05-12&end=2025-05-10", "start=May&end=June"} {
+		if rr := deleteHistory(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestBulkEditHistoryInvalid(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"filter": {}, "update": {"genre": "News"}}`, http.StatusBadRequest},
+		{`{"filter": {"title_pattern": "*"}, "update": {}}`, http.StatusBadRequest},
+		{`{"filter": {"start_date": "March"}, "update": {"genre": "News"}}`, http.StatusBadRequest},
+		{`{"filter": {"title_pattern": "*"}, "update": {"service_id": 999}}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("PATCH", "/api/history/bulk", strings.NewReader(tt.body))
+		rr := httptest.NewRecorder()
+		handler.bulkEditHistory(rr, req)
+		if rr.Code != tt.status {
+			t.Errorf("Expected status code %d for %s, got %d", tt.status, tt.body, rr.Code)
+		}
+	}
+}
+
+func TestUpdateHistoryNotesAndSearch(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	wh := &database.WatchHistory{ServiceID: service.ID, Title: "The Crown", DurationMinutes: 55, WatchedAt: time.Now()}
+	db.InsertWatchHistory(wh)
+
+	req, err := http.NewRequest("PUT", "/api/history/1/notes", strings.NewReader(`{"notes": "Fell asleep halfway"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	rr := httptest.NewRecorder()
+	handler.updateHistoryNotes(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/history/search?q=asleep", nil)
+	rr = httptest.NewRecorder()
+	handler.searchHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var resp struct {
+		Results []database.WatchHistory `json:"results"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Notes != "Fell asleep halfway" {
+		t.Errorf("Unexpected search results: %+v", resp.Results)
+	}
+}
+
+func TestUpdateHistoryNotesNotFound(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("PUT", "/api/history/42/notes", strings.NewReader(`{"notes": "x"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "42"})
+
+	rr := httptest.NewRecorder()
+	handler.updateHistoryNotes(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestUpdateHistoryRating(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: time.Now()})
+
+	for body, want := range map[string]int{
+		`{"rating": 5}`: http.StatusOK,
+		`{"rating": 6}`: http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("PUT", "/api/history/1/rating", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+		rr := httptest.NewRecorder()
+		handler.updateHistoryRating(rr, req)
+
+		if status := rr.Code; status != want {
+			t.Errorf("Expected status code %d for %s, got %d", want, body, status)
+		}
+	}
+
+	history, _ := db.GetWatchHistory(service.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 10, 0)
+	if len(history) != 1 || history[0].Rating != 5 {
+		t.Errorf("Expected rating 5 to be stored, got %+v", history)
+	}
+}
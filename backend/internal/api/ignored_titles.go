@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// getIgnoredTitles returns the title ignore list
+func (h *Handler) getIgnoredTitles(w http.ResponseWriter, r *http.Request) {
+	titles, err := h.db.GetIgnoredTitles()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch ignored titles", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, titles)
+}
+
+// addIgnoredTitle adds a title to the ignore list. Scrapers and importers skip
+// matching items, and existing matching rows are excluded from stats.
+func (h *Handler) addIgnoredTitle(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title     string `json:"title"`
+		ServiceID int64  `json:"service_id"` // 0 ignores the title on every service
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		respondError(w, http.StatusBadRequest, "Invalid title", fmt.Errorf("title is required"))
+		return
+	}
+
+	if req.ServiceID != 0 {
+		service, err := h.db.GetServiceByID(req.ServiceID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+			return
+		}
+		if service == nil {
+			respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service with ID %d not found", req.ServiceID))
+			return
+		}
+	}
+
+	it := &database.IgnoredTitle{
+		ServiceID: req.ServiceID,
+		Title:     req.Title,
+	}
+	if err := h.db.InsertIgnoredTitle(it); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to ignore title", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, it)
+}
+
+// deleteIgnoredTitle removes a title from the ignore list
+func (h *Handler) deleteIgnoredTitle(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ignored title ID", err)
+		return
+	}
+
+	deleted, err := h.db.DeleteIgnoredTitle(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete ignored title", err)
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Ignored title not found", fmt.Errorf("ignored title with ID %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deleted": id,
+	})
+}
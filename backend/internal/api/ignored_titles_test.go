@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestAddAndDeleteIgnoredTitle(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("POST", "/api/ignored-titles", strings.NewReader(`{"title": "White Noise"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.addIgnoredTitle(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, status)
+	}
+
+	var it database.IgnoredTitle
+	if err := json.NewDecoder(rr.Body).Decode(&it); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if it.ID == 0 || it.Title != "White Noise" {
+		t.Errorf("Unexpected ignored title: %+v", it)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/ignored-titles/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr = httptest.NewRecorder()
+	handler.deleteIgnoredTitle(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+}
+
+func TestAddIgnoredTitleRequiresTitle(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("POST", "/api/ignored-titles", strings.NewReader(`{"title": "  "}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.addIgnoredTitle(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/importer"
+)
+
+// maxImportSize limits the size of uploaded import files
+const maxImportSize = 20 << 20 // 20 MB
+
+// importHistory imports an uploaded watch history export for a service.
+// With ?dry_run=true nothing is written; the response previews how the first
+// rows were interpreted, including their TMDB matches when TMDB is configured,
+// and how many rows would be duplicates. Files that were already imported are
+// skipped unless ?force=true is given. The file is read as the service's own
+// export format unless ?format= names another one, such as a trakt, serializd
+// or generic export to import into the service. Generic CSVs can name their
+// columns with a configured ?mapping= or per-field parameters like
+// title_column, given in the query or as multipart fields; rows of a mapped
+// service column are imported into the service they name.
+func (h *Handler) importHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serviceName := vars["service"]
+
+	data, filename, err := readImportFile(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to read upload", err)
+		return
+	}
+
+	parse, err := h.importParser(r, serviceName)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Unsupported import format", err)
+		return
+	}
+
+	service, err := h.db.GetServiceByName(h.serviceNameFor(serviceName))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+		return
+	}
+	if service == nil {
+		respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service %q not found", serviceName))
+		return
+	}
+
+	h.applyImport(w, r, service, data, filename, parse)
+}
+
+// applyImport parses an uploaded export and imports it into a service,
+// honoring the dry_run, force and preview_rows parameters
+func (h *Handler) applyImport(w http.ResponseWriter, r *http.Request, service *database.Service, data []byte, filename string, parse importer.Parser) {
+	query := r.URL.Query()
+	dryRun := query.Get("dry_run") == "true"
+
+	// Skip files that were already imported unless explicitly forced, so
+	// re-uploading the same export doesn't redo thousands of duplicate checks
+	hash := importer.Fingerprint(data)
+	previous, err := h.db.GetImportByHash(service.ID, hash)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to check previous imports", err)
+		return
+	}
+	if previous != nil && query.Get("force") != "true" {
+		respondJSON(w, http.StatusOK, &importer.Summary{
+			DryRun:          dryRun,
+			AlreadyImported: true,
+			PreviousImport:  previous,
+			Errors:          []importer.RowError{},
+			Preview:         []importer.PreviewRow{},
+		})
+		return
+	}
+
+	parsed, err := parse(bytes.NewReader(data))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to parse import file", err)
+		return
+	}
+
+	services, err := h.importServices()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+	opts := importer.Options{
+		DryRun:      dryRun,
+		PreviewRows: parseIntParam(query.Get("preview_rows"), 20),
+		Services:    services,
+	}
+	// Only dry runs look titles up, so real imports don't wait on TMDB
+	if opts.DryRun {
+		opts.TMDB = h.tmdb
+	}
+
+	summary, err := importer.Apply(h.db, service.ID, parsed, opts)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import history", err)
+		return
+	}
+
+	if !dryRun {
+		err := h.db.InsertImport(&database.Import{
+			ServiceID:   service.ID,
+			ContentHash: hash,
+			Filename:    filename,
+			TotalRows:   summary.TotalRows,
+			Imported:    summary.Imported,
+		})
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to record import", err)
+			return
+		}
+	}
+	summary.AlreadyImported = previous != nil
+	summary.PreviousImport = previous
+
+	respondJSON(w, http.StatusOK, summary)
+}
+
+// readImportFile returns the contents and filename of the uploaded file from a
+// multipart "file" field, or the raw request body for non-multipart uploads
+func readImportFile(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("missing file field: %w", err)
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		return data, header.Filename, err
+	}
+
+	data, err := io.ReadAll(r.Body)
+	return data, "", err
+}
+
+// importColumns are the request parameters naming a generic CSV's columns
+var importColumns = []string{"title_column", "date_column", "duration_column", "episode_column", "service_column"}
+
+// importParser returns the parser for an upload: the ?format= named, or the
+// service's own export format. A column mapping implies the generic format.
+func (h *Handler) importParser(r *http.Request, serviceName string) (importer.Parser, error) {
+	mapping, mapped, err := h.importMapping(r)
+	if err != nil {
+		return nil, err
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" && mapped {
+		format = "generic"
+	}
+	if format == "" {
+		format = serviceName
+	}
+	if mapped {
+		if format != "generic" {
+			return nil, fmt.Errorf("column mappings only apply to the generic format, not %q", format)
+		}
+		return importer.MappedCSV(mapping), nil
+	}
+
+	parse, ok := importer.Formats[format]
+	if !ok {
+		return nil, fmt.Errorf("no importer for format %q", format)
+	}
+	return parse, nil
+}
+
+// importMapping returns the column mapping an upload asks for: the configured
+// mapping named by the mapping parameter, with any *_column parameters
+// overriding its fields. It reports false when no mapping was given.
+func (h *Handler) importMapping(r *http.Request) (importer.ColumnMapping, bool, error) {
+	var mapping importer.ColumnMapping
+	mapped := false
+
+	if name := r.FormValue("mapping"); name != "" {
+		cfg, ok := h.config.Importer.Mappings[name]
+		if !ok {
+			return mapping, false, fmt.Errorf("no import mapping named %q in config", name)
+		}
+		mapping = importer.ColumnMapping{
+			Title:    cfg.Title,
+			Date:     cfg.Date,
+			Duration: cfg.Duration,
+			Episode:  cfg.Episode,
+			Service:  cfg.Service,
+		}
+		mapped = true
+	}
+
+	fields := []*string{&mapping.Title, &mapping.Date, &mapping.Duration, &mapping.Episode, &mapping.Service}
+	for i, param := range importColumns {
+		if col := strings.TrimSpace(r.FormValue(param)); col != "" {
+			*fields[i] = col
+			mapped = true
+		}
+	}
+	return mapping, mapped, nil
+}
+
+// importServices maps the lowercase names and keys of services, and the
+// config keys of configured ones, to their IDs for imports whose rows name a service
+func (h *Handler) importServices() (map[string]int64, error) {
+	all, err := h.db.GetAllServices()
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]int64)
+	for _, svc := range all {
+		services[strings.ToLower(svc.Name)] = svc.ID
+	}
+	for _, svc := range all {
+		if svc.Key != "" {
+			services[strings.ToLower(svc.Key)] = svc.ID
+		}
+	}
+	for key := range h.config.Services {
+		if id, ok := services[strings.ToLower(h.serviceNameFor(key))]; ok {
+			services[strings.ToLower(key)] = id
+		}
+	}
+	return services, nil
+}
+
+// importYouTubeTakeout imports the watch-history.json of a Google Takeout
+// export into YouTube and YouTube TV, skipping rows already in the history.
+// ?skip_ads=true and ?skip_shorts=true leave out ads and Shorts; dry_run,
+// force and preview_rows work as for other imports.
+func (h *Handler) importYouTubeTakeout(w http.ResponseWriter, r *http.Request) {
+	data, filename, err := readImportFile(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to read upload", err)
+		return
+	}
+
+	service, err := h.db.GetServiceByName(importer.YouTubeService)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+		return
+	}
+	if service == nil {
+		respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service %q not found", importer.YouTubeService))
+		return
+	}
+
+	query := r.URL.Query()
+	opts := importer.TakeoutOptions{
+		SkipAds:    query.Get("skip_ads") == "true",
+		SkipShorts: query.Get("skip_shorts") == "true",
+	}
+	h.applyImport(w, r, service, data, filename, func(r io.Reader) (*importer.ParseResult, error) {
+		return importer.ParseYouTubeTakeout(r, opts)
+	})
+}
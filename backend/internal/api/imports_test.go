@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/importer"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestImportHistoryDryRun(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": [{"id": 398978, "media_type": "movie", "title": "The Irishman"}]}`))
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	csvData := "Title,Date\n\"The Irishman\",\"1/14/25\"\n"
+	req, err := http.NewRequest("POST", "/api/import/netflix?dry_run=true", strings.NewReader(csvData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"service": "netflix"})
+
+	rr := httptest.NewRecorder()
+	handler.importHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var summary importer.Summary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !summary.DryRun {
+		t.Error("Expected dry_run to be true")
+	}
+	if len(summary.Preview) != 1 || summary.Preview[0].Title != "The Irishman" {
+		t.Fatalf("Unexpected preview: %+v", summary.Preview)
+	}
+	if match := summary.Preview[0].TMDB; match == nil || match.ID != 398978 || match.Kind != "movie" {
+		t.Errorf("Expected the preview row's TMDB match, got %+v", match)
+	}
+}
+
+func TestImportHistoryUnsupportedService(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("POST", "/api/import/peacock", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"service": "peacock"})
+
+	rr := httptest.NewRecorder()
+	handler.importHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestImportHistorySkipsRepeatedFile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	csvData := "Title,Date\n\"The Irishman\",\"1/14/25\"\n"
+	upload := func(query string) importer.Summary {
+		req, err := http.NewRequest("POST", "/api/import/netflix"+query, strings.NewReader(csvData))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"service": "netflix"})
+
+		rr := httptest.NewRecorder()
+		handler.importHistory(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		var summary importer.Summary
+		if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return summary
+	}
+
+	first := upload("")
+	if first.AlreadyImported || first.Imported != 1 {
+		t.Fatalf("Expected first upload to import 1 row, got %+v", first)
+	}
+
+	second := upload("")
+	if !second.AlreadyImported || second.PreviousImport == nil {
+		t.Errorf("Expected second upload to be detected as already imported, got %+v", second)
+	}
+	if second.Parsed != 0 {
+		t.Errorf("Expected repeated file to be skipped without parsing, got %d parsed", second.Parsed)
+	}
+
+	forced := upload("?force=true")
+	if forced.Duplicates != 1 {
+		t.Errorf("Expected forced re-import to report 1 duplicate, got %d", forced.Duplicates)
+	}
+}
+
+func TestImportAudibleLibrary(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	tsv := "title\truntime_length_min\tis_finished\tdate_added\nBorn a Crime\t527\ttrue\t2023-11-20\n"
+	req, err := http.NewRequest("POST", "/api/import/audible", strings.NewReader(tsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"service": "audible"})
+
+	rr := httptest.NewRecorder()
+	handler.importHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var summary importer.Summary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Imported != 1 {
+		t.Errorf("Expected 1 imported title, got %d", summary.Imported)
+	}
+}
+
+func TestImportHistoryWithFormat(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.tmdb = nil // Keep the dry run's preview from calling TMDB
+
+	csvData := "title,date,minutes\nThe Irishman,2025-01-14,209\n"
+	req, err := http.NewRequest("POST", "/api/import/netflix?format=generic&dry_run=true", strings.NewReader(csvData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"service": "netflix"})
+
+	rr := httptest.NewRecorder()
+	handler.importHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var summary importer.Summary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(summary.Preview) != 1 || summary.Preview[0].DurationMinutes != 209 {
+		t.Errorf("Unexpected preview: %+v", summary.Preview)
+	}
+}
+
+func TestImportHistoryIntoKeyedService(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	custom, err := db.EnsureService("Criterion Collection", "#1A1A1A", "")
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if err := db.SetServiceKey(custom.Name, "criterion_discs"); err != nil {
+		t.Fatalf("Failed to set service key: %v", err)
+	}
+
+	csvData := "title,date,minutes\nSeven Samurai,2025-01-14,207\n"
+	req, err := http.NewRequest("POST", "/api/import/criterion_discs?format=generic", strings.NewReader(csvData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"service": "criterion_discs"})
+
+	rr := httptest.NewRecorder()
+	handler.importHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+	if has, _ := db.HasWatchHistory(custom.ID); !has {
+		t.Error("Expected history imported into the service the key routes to")
+	}
+}
+
+func TestImportHistoryWithColumnMapping(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Importer.Mappings = map[string]config.ColumnMappingConfig{
+		"justwatch": {Title: "Name", Date: "Watched", Service: "Provider"},
+	}
+
+	csvData := "Name,Watched,Length,Provider\n" +
+		"The Irishman,2025-01-14,3:29,\n" +
+		"The Bear,2025-01-15,0:30,hulu\n" +
+		"Slow Horses,2025-01-16,0:45,Betamax+\n"
+	req, err := http.NewRequest("POST", "/api/import/netflix?mapping=justwatch&duration_column=Length", strings.NewReader(csvData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"service": "netflix"})
+
+	rr := httptest.NewRecorder()
+	handler.importHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var summary importer.Summary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Imported != 2 || len(summary.Errors) != 1 {
+		t.Fatalf("Expected 2 imported rows and 1 unknown service, got %+v", summary)
+	}
+
+	hulu, _ := db.GetServiceByName("Hulu")
+	exists, err := db.WatchHistoryExists(hulu.ID, "The Bear", "", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil || !exists {
+		t.Errorf("Expected The Bear to be imported into Hulu (%v)", err)
+	}
+}
+
+func TestImportHistoryUnknownMapping(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("POST", "/api/import/netflix?mapping=missing", strings.NewReader("title,date\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"service": "netflix"})
+
+	rr := httptest.NewRecorder()
+	handler.importHistory(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestImportYouTubeTakeout(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	takeout := `[
+		{"header": "YouTube", "title": "Watched How Tides Work", "titleUrl": "https://www.youtube.com/watch?v=abc", "time": "2024-03-01T20:15:30Z"},
+		{"header": "YouTube TV", "title": "Watched NBA Basketball", "titleUrl": "https://tv.youtube.com/watch/xyz", "time": "2024-03-02T01:00:00Z"},
+		{"header": "YouTube", "title": "Watched Big Sale Today", "titleUrl": "https://www.youtube.com/watch?v=ghi", "time": "2024-03-02T09:05:00Z", "details": [{"name": "From Google Ads"}]}
+	]`
+	youtubeTV, _ := db.GetServiceByName("YouTube TV")
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: youtubeTV.ID, Title: "NBA Basketball", DurationMinutes: 60, WatchedAt: time.Date(2024, 3, 2, 1, 0, 0, 0, time.UTC)})
+
+	req, err := http.NewRequest("POST", "/api/upload/youtube-takeout?skip_ads=true", strings.NewReader(takeout))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.importYouTubeTakeout(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var summary importer.Summary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Imported != 1 || summary.Duplicates != 1 {
+		t.Errorf("Expected 1 imported video and 1 duplicate, got %+v", summary)
+	}
+
+	youtube, _ := db.GetServiceByName("YouTube")
+	if hasHistory, _ := db.HasWatchHistory(youtube.ID); !hasHistory {
+		t.Error("Expected the video to be imported into YouTube")
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/importer"
+)
+
+// maxIngestSize limits the size of a posted batch of device events
+const maxIngestSize = 1 << 20 // 1 MB
+
+// ingestDeviceEvents stores app foreground events posted by companion scripts
+// on Android TV and Fire TV devices as estimated sessions for the apps'
+// services. With ?dry_run=true nothing is written. When device_ingest.token
+// is set it must be sent as a bearer token or ?token=.
+func (h *Handler) ingestDeviceEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if expected := h.config.DeviceIngest.Token; expected != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = query.Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respondError(w, http.StatusUnauthorized, "Invalid token", fmt.Errorf("missing or invalid device ingest token"))
+			return
+		}
+	}
+
+	batch, err := importer.ParseDeviceEvents(http.MaxBytesReader(w, r.Body, maxIngestSize))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	opts := importer.Options{
+		DryRun:      query.Get("dry_run") == "true",
+		PreviewRows: parseIntParam(query.Get("preview_rows"), 20),
+	}
+	summary, err := importer.ApplyDeviceEvents(h.db, batch, h.config.DeviceIngest.Packages, opts)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to store device events", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, summary)
+}
+
+// ingestNetworkObservations stores streaming traffic seen in router-level
+// DNS or flow logs as low-confidence sessions that fill gaps in scraped
+// history. With ?dry_run=true nothing is written. When network_ingest.token
+// is set it must be sent as a bearer token or ?token=.
+func (h *Handler) ingestNetworkObservations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if expected := h.config.NetworkIngest.Token; expected != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = query.Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respondError(w, http.StatusUnauthorized, "Invalid token", fmt.Errorf("missing or invalid network ingest token"))
+			return
+		}
+	}
+
+	batch, err := importer.ParseNetworkObservations(http.MaxBytesReader(w, r.Body, maxIngestSize))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	opts := importer.Options{
+		DryRun:      query.Get("dry_run") == "true",
+		PreviewRows: parseIntParam(query.Get("preview_rows"), 20),
+	}
+	summary, err := importer.ApplyNetworkObservations(h.db, batch, h.config.NetworkIngest.Domains, opts)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to store network observations", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/importer"
+)
+
+func TestIngestDeviceEvents(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.DeviceIngest.Token = "secret"
+
+	body := `{"device": "Fire TV", "events": [{"package": "com.amazon.avod", "started_at": "2025-01-14T20:00:00Z", "seconds": 3600}]}`
+
+	req, _ := http.NewRequest("POST", "/api/ingest/device", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ingestDeviceEvents(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d without a token, got %d", http.StatusUnauthorized, rr.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/ingest/device", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	handler.ingestDeviceEvents(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var summary importer.UsageSummary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Imported != 1 {
+		t.Errorf("Expected 1 session stored, got %+v", summary)
+	}
+}
+
+func TestIngestDeviceEventsInvalidBody(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("POST", "/api/ingest/device", strings.NewReader("not json"))
+	rr := httptest.NewRecorder()
+	handler.ingestDeviceEvents(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestIngestNetworkObservations(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	body := `{"source": "pihole", "observations": [{"domain": "nflxvideo.net", "client": "tv", "first_seen": "2025-01-14T20:00:00Z", "last_seen": "2025-01-14T22:30:00Z"}]}`
+
+	req, _ := http.NewRequest("POST", "/api/ingest/network?dry_run=true", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ingestNetworkObservations(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var summary importer.UsageSummary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !summary.DryRun || summary.Imported != 0 || len(summary.Preview) != 1 || summary.Preview[0].DurationMinutes != 150 {
+		t.Errorf("Expected a 150 minute session previewed without storing it, got %+v", summary)
+	}
+}
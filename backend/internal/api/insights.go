@@ -0,0 +1,280 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+)
+
+// serviceGaps groups detected gaps for a single service
+type serviceGaps struct {
+	ServiceID   int64          `json:"service_id"`
+	ServiceName string         `json:"service_name"`
+	Gaps        []insights.Gap `json:"gaps"`
+}
+
+// getHistoryGaps reports suspicious gaps in watch history, likely caused by
+// expired cookies, so users know which periods to backfill via CSV import
+func (h *Handler) getHistoryGaps(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	opts := insights.DefaultGapOptions()
+	opts.MinGapDays = parseIntParam(query.Get("min_gap_days"), opts.MinGapDays)
+	opts.WindowDays = parseIntParam(query.Get("window_days"), opts.WindowDays)
+
+	// Look back over the last N days (default 1 year)
+	days := parseIntParam(query.Get("days"), 365)
+	if days <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid days parameter", fmt.Errorf("days must be positive"))
+		return
+	}
+	now := time.Now().UTC()
+	endDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	startDate := endDate.AddDate(0, 0, -days)
+
+	services, err := db.GetAllServices()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+
+	// Optionally restrict to a single service
+	if serviceIDStr := query.Get("service_id"); serviceIDStr != "" {
+		serviceID, err := strconv.ParseInt(serviceIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid service_id parameter", err)
+			return
+		}
+		var filtered []database.Service
+		for _, svc := range services {
+			if svc.ID == serviceID {
+				filtered = append(filtered, svc)
+			}
+		}
+		if len(filtered) == 0 {
+			respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service with ID %d not found", serviceID))
+			return
+		}
+		services = filtered
+	}
+
+	results := []serviceGaps{}
+	for _, svc := range services {
+		dailyStats, err := db.GetDailyStats(svc.ID, startDate, endDate)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch daily stats", err)
+			return
+		}
+
+		gaps := insights.DetectGaps(dailyStats, startDate, endDate, opts)
+		if len(gaps) == 0 {
+			continue
+		}
+
+		results = append(results, serviceGaps{
+			ServiceID:   svc.ID,
+			ServiceName: svc.Name,
+			Gaps:        gaps,
+		})
+	}
+
+	response := map[string]interface{}{
+		"services":   results,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+		"suggestion": "Gaps usually mean the scraper's cookies expired. Refresh cookies and backfill the missing period via CSV import.",
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// serviceFootprint is the estimated footprint for a single service
+type serviceFootprint struct {
+	ServiceID    int64  `json:"service_id"`
+	ServiceName  string `json:"service_name"`
+	TotalMinutes int    `json:"total_minutes"`
+	insights.Footprint
+}
+
+// getFootprint estimates data usage (GB) and energy (kWh) from watch time,
+// using each service's configured streaming resolution
+func (h *Handler) getFootprint(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	stats, err := db.GetServiceStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+
+	footprintCfg := h.config.Insights.Footprint
+
+	results := []serviceFootprint{}
+	var totalGB, totalKWh float64
+	totalMinutes := 0
+	for _, stat := range stats {
+		resolution := footprintCfg.DefaultResolution
+		if res := h.serviceResolution(stat.ServiceName); res != "" {
+			resolution = res
+		}
+
+		fp := insights.EstimateFootprint(stat.TotalMinutes, resolution, footprintCfg.KWhPerHour)
+		results = append(results, serviceFootprint{
+			ServiceID:    stat.ServiceID,
+			ServiceName:  stat.ServiceName,
+			TotalMinutes: stat.TotalMinutes,
+			Footprint:    fp,
+		})
+
+		totalMinutes += stat.TotalMinutes
+		totalGB += fp.DataGB
+		totalKWh += fp.EnergyKWh
+	}
+
+	response := map[string]interface{}{
+		"services":      results,
+		"total_minutes": totalMinutes,
+		"data_gb":       math.Round(totalGB*100) / 100,
+		"energy_kwh":    math.Round(totalKWh*100) / 100,
+		"start_date":    startDate.Format("2006-01-02"),
+		"end_date":      endDate.Format("2006-01-02"),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// serviceResolution returns the configured resolution for a service by its database name
+func (h *Handler) serviceResolution(serviceName string) string {
+	for key, svc := range h.config.Services {
+		if h.serviceNameFor(key) == serviceName {
+			return svc.Resolution
+		}
+	}
+	return ""
+}
+
+// trendingListSize is how many trending titles are compared, like a weekly top 10
+const trendingListSize = 10
+
+// getTrendingComparison compares what the user watched with TMDB's trending
+// list for the same window, e.g. "You watched 3 of this week's top 10"
+func (h *Handler) getTrendingComparison(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	if h.tmdb == nil {
+		respondError(w, http.StatusServiceUnavailable, "TMDB not configured", fmt.Errorf("tmdb.api_key is required"))
+		return
+	}
+
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = "week"
+	}
+	var days int
+	switch window {
+	case "day":
+		days = 1
+	case "week":
+		days = 7
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid window parameter", fmt.Errorf("window must be day or week"))
+		return
+	}
+
+	items, err := h.tmdb.Trending(r.Context(), window)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to fetch trending titles", err)
+		return
+	}
+	if len(items) > trendingListSize {
+		items = items[:trendingListSize]
+	}
+	trending := make([]insights.TrendingEntry, 0, len(items))
+	for _, item := range items {
+		trending = append(trending, insights.TrendingEntry{Title: item.DisplayTitle(), MediaType: item.MediaType})
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+	watched, err := db.GetTopTitles(startDate, endDate, 500)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch watched titles", err)
+		return
+	}
+
+	matches, watchedCount := insights.MatchTrending(trending, watched)
+
+	response := map[string]interface{}{
+		"window":        window,
+		"trending":      matches,
+		"watched_count": watchedCount,
+		"summary":       fmt.Sprintf("You watched %d of this %s's top %d", watchedCount, window, len(matches)),
+		"start_date":    startDate.Format("2006-01-02"),
+		"end_date":      endDate.Format("2006-01-02"),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// getTitleVariants clusters titles that look like spellings of the same show
+// or movie and suggests alias mappings to confirm via POST /api/title-aliases
+func (h *Handler) getTitleVariants(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	startDate, endDate, err := parseYearMonthRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	// Minimum similarity as a percentage (default 85)
+	similarity := parseIntParam(query.Get("similarity"), 85)
+	if similarity <= 0 || similarity > 100 {
+		respondError(w, http.StatusBadRequest, "Invalid similarity parameter", fmt.Errorf("similarity must be between 1 and 100"))
+		return
+	}
+
+	titles, err := db.GetTitleSpellings(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch titles", err)
+		return
+	}
+
+	clusters := insights.ClusterTitleVariants(titles, float64(similarity)/100)
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"clusters":   clusters,
+		"similarity": similarity,
+	})
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestGetHistoryGaps(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	// Watch something every day for the last 60 days except a 10 day stretch
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		if i >= 20 && i < 30 {
+			continue
+		}
+		db.InsertWatchHistory(&database.WatchHistory{
+			ServiceID:       service.ID,
+			Title:           "Daily Show",
+			DurationMinutes: 30,
+			WatchedAt:       today.AddDate(0, 0, -i),
+		})
+	}
+
+	req, err := http.NewRequest("GET", "/api/insights/gaps?days=90", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getHistoryGaps(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var response struct {
+		Services []serviceGaps `json:"services"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Services) != 1 {
+		t.Fatalf("Expected gaps for 1 service, got %d", len(response.Services))
+	}
+	if response.Services[0].ServiceName != "Netflix" {
+		t.Errorf("Expected Netflix, got '%s'", response.Services[0].ServiceName)
+	}
+	if len(response.Services[0].Gaps) != 1 || response.Services[0].Gaps[0].Days != 10 {
+		t.Errorf("Expected a single 10 day gap, got %+v", response.Services[0].Gaps)
+	}
+}
+
+func TestGetHistoryGapsInvalidService(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("GET", "/api/insights/gaps?service_id=abc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getHistoryGaps(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestGetFootprint(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	// Netflix streams in 4K, everything else uses the default
+	handler.config.Services["netflix"] = config.ServiceConfig{Enabled: true, Resolution: "4k"}
+	handler.config.Insights.Footprint = config.FootprintConfig{DefaultResolution: "hd", KWhPerHour: 0.08}
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       service.ID,
+		Title:           "Test Movie",
+		DurationMinutes: 120,
+		WatchedAt:       time.Now(),
+	})
+
+	req, err := http.NewRequest("GET", "/api/insights/footprint", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getFootprint(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var response struct {
+		Services []serviceFootprint `json:"services"`
+		DataGB   float64            `json:"data_gb"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.DataGB != 14 {
+		t.Errorf("Expected 14 GB for two hours of 4K, got %v", response.DataGB)
+	}
+	if len(response.Services) != 1 || response.Services[0].Resolution != "4k" {
+		t.Errorf("Expected Netflix footprint at 4k, got %+v", response.Services)
+	}
+}
+
+func TestGetTrendingComparison(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": [
+			{"id": 1, "media_type": "tv", "name": "The Bear"},
+			{"id": 2, "media_type": "movie", "title": "Dune: Part Two"}
+		]}`))
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       service.ID,
+		Title:           "The Bear",
+		DurationMinutes: 30,
+		WatchedAt:       time.Now().Add(-24 * time.Hour),
+	})
+
+	req, err := http.NewRequest("GET", "/api/insights/trending", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getTrendingComparison(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Trending     []insights.TrendingMatch `json:"trending"`
+		WatchedCount int                      `json:"watched_count"`
+		Summary      string                   `json:"summary"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.WatchedCount != 1 || !response.Trending[0].Watched {
+		t.Errorf("Expected The Bear to be watched, got %+v", response.Trending)
+	}
+	if response.Summary != "You watched 1 of this week's top 2" {
+		t.Errorf("Unexpected summary %q", response.Summary)
+	}
+}
+
+func TestGetTrendingComparisonInvalidWindow(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("GET", "/api/insights/trending?window=month", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getTrendingComparison(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestGetTitleVariants(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "The Office", DurationMinutes: 22, WatchedAt: now})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "The Office", DurationMinutes: 22, WatchedAt: now.Add(-time.Hour)})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "The Office (U.S.)", DurationMinutes: 22, WatchedAt: now})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: now})
+
+	req, err := http.NewRequest("GET", "/api/insights/title-variants", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getTitleVariants(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var resp struct {
+		Clusters []insights.TitleCluster `json:"clusters"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Clusters) != 1 {
+		t.Fatalf("Expected 1 cluster, got %+v", resp.Clusters)
+	}
+	suggestions := resp.Clusters[0].Suggestions
+	if len(suggestions) != 1 || suggestions[0].Alias != "The Office (U.S.)" || suggestions[0].Canonical != "The Office" {
+		t.Errorf("Unexpected suggestions: %+v", suggestions)
+	}
+}
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+)
+
+// latencyWindowDays is how soon after airing an episode must be watched to
+// count as keeping up with a show, unless ?window_days= says otherwise
+const latencyWindowDays = 30
+
+// showRefresh is how long a show's cached latest and next episodes are
+// trusted before it's looked up again, since shows still airing gain episodes
+const showRefresh = 24 * time.Hour
+
+// getEpisodeLatency reports how many days after airing new episodes are
+// watched, per show and overall, using TMDB air dates, and lists followed
+// shows that have aired episodes not watched yet. Like the originals stats,
+// at most lookup_limit shows are looked up or refreshed per request
+// (default 25); shows not yet looked up are left out until a later request.
+// Compacted history has no episodes, so detail_since is set once history has
+// been compacted and earlier episodes aren't counted.
+func (h *Handler) getEpisodeLatency(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	if h.tmdb == nil {
+		respondError(w, http.StatusServiceUnavailable, "TMDB not configured", fmt.Errorf("tmdb.api_key is required"))
+		return
+	}
+
+	query := r.URL.Query()
+	startDate, endDate, err := parseYearMonthRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+	windowDays := parseIntParam(query.Get("window_days"), latencyWindowDays)
+	if windowDays <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid window_days parameter", fmt.Errorf("window_days must be positive"))
+		return
+	}
+	lookupLimit := parseIntParam(query.Get("lookup_limit"), 25)
+
+	watched, err := db.GetWatchedEpisodes(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch watched episodes", err)
+		return
+	}
+
+	var found []insights.ShowEpisodes
+	lookups := &showLookups{limit: lookupLimit}
+	for _, show := range groupEpisodesByTitle(watched) {
+		cached, airDates, err := h.showAirDates(r, show.Title, show.Watched, lookups)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch air dates", err)
+			return
+		}
+		if cached == nil || cached.TMDBID == 0 {
+			continue
+		}
+		show.AirDates = airDates
+		if cached.LatestAirDate != "" {
+			show.Latest = &database.EpisodeAirDate{Season: cached.LatestSeason, Episode: cached.LatestEpisode, AirDate: cached.LatestAirDate}
+		}
+		found = append(found, *show)
+	}
+
+	latencies, behind, average := insights.EpisodeLatency(found, time.Now(), windowDays)
+
+	detailSince, err := db.DetailSince()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch compacted history", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"average_days_behind": average,
+		"shows":               latencies,
+		"behind":              behind,
+		"window_days":         windowDays,
+		"pending_lookups":     lookups.pending,
+		"start_date":          startDate.Format("2006-01-02"),
+		"end_date":            endDate.Format("2006-01-02"),
+	}
+	if detailSince != "" {
+		response["detail_since"] = detailSince
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// groupEpisodesByTitle groups watched episodes by show, ignoring case, most
+// watched first so those are looked up first
+func groupEpisodesByTitle(watched []database.WatchedEpisode) []*insights.ShowEpisodes {
+	var shows []*insights.ShowEpisodes
+	byTitle := make(map[string]*insights.ShowEpisodes)
+	for _, ep := range watched {
+		key := strings.ToLower(ep.Title)
+		show, ok := byTitle[key]
+		if !ok {
+			show = &insights.ShowEpisodes{Title: ep.Title}
+			byTitle[key] = show
+			shows = append(shows, show)
+		}
+		show.Watched = append(show.Watched, ep)
+	}
+	slices.SortStableFunc(shows, func(a, b *insights.ShowEpisodes) int {
+		return len(b.Watched) - len(a.Watched)
+	})
+	return shows
+}
+
+// showLookups counts a request's TMDB show lookups against its limit
+type showLookups struct {
+	limit   int
+	made    int
+	pending int // Shows left stale or missing once the limit was reached
+}
+
+// showAirDates returns a show's cached airing details and episode air dates,
+// looking the show up on TMDB first when the cache is missing or stale and
+// lookups remain. The show is nil until it has been looked up; failed
+// lookups are logged and leave the cache as it was.
+func (h *Handler) showAirDates(r *http.Request, title string, watched []database.WatchedEpisode, lookups *showLookups) (*database.TVShow, []database.EpisodeAirDate, error) {
+	show, err := h.db.GetTVShow(title)
+	if err != nil {
+		return nil, nil, err
+	}
+	airDates := []database.EpisodeAirDate{}
+	if show != nil && show.TMDBID != 0 {
+		if airDates, err = h.db.GetEpisodeAirDates(show.TMDBID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	seasons := showLookupSeasons(show, airDates, watched)
+	if seasons == nil {
+		return show, airDates, nil
+	}
+	if lookups.made >= lookups.limit {
+		lookups.pending++
+		return show, airDates, nil
+	}
+	lookups.made++
+
+	looked, err := h.lookupShowAirDates(r, title, seasons)
+	if err != nil {
+		log.Printf("Failed to look up air dates of '%s': %v", title, err)
+		return show, airDates, nil
+	}
+	airDates = []database.EpisodeAirDate{}
+	if looked.TMDBID != 0 {
+		if airDates, err = h.db.GetEpisodeAirDates(looked.TMDBID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return looked, airDates, nil
+}
+
+// showLookupSeasons returns the seasons whose air dates should be fetched
+// for a show, or nil if its cache is current: the watched seasons missing
+// from the cache, and when refreshing, the seasons from the last one watched
+// on so episodes aired since are counted. Titles that aren't TV shows on
+// TMDB aren't looked up again.
+func showLookupSeasons(show *database.TVShow, airDates []database.EpisodeAirDate, watched []database.WatchedEpisode) map[int]bool {
+	if show != nil && show.TMDBID == 0 {
+		return nil
+	}
+
+	cached := make(map[int]bool)
+	for _, ad := range airDates {
+		cached[ad.Season] = true
+	}
+	seasons := make(map[int]bool)
+	lastSeason := -1
+	for _, ep := range watched {
+		season, _, ok := insights.ParseEpisode(ep.EpisodeInfo)
+		if !ok {
+			continue
+		}
+		if !cached[season] {
+			seasons[season] = true
+		}
+		lastSeason = max(lastSeason, season)
+	}
+
+	if lastSeason < 0 {
+		return nil // No episode numbers to match air dates against
+	}
+	if show == nil || time.Since(show.Updated) > showRefresh {
+		seasons[lastSeason] = true
+		return seasons
+	}
+	if len(seasons) == 0 {
+		return nil
+	}
+	return seasons
+}
+
+// lookupShowAirDates finds a show's latest and next episodes and the air
+// dates of the given seasons, and everything after them, on TMDB and caches
+// the result, including misses so they aren't looked up again
+func (h *Handler) lookupShowAirDates(r *http.Request, title string, seasons map[int]bool) (*database.TVShow, error) {
+	show := &database.TVShow{Title: title}
+	var episodes []database.EpisodeAirDate
+
+	match, err := h.tmdb.SearchMulti(r.Context(), title)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil && match.MediaType == "tv" {
+		airing, err := h.tmdb.ShowAiring(r.Context(), match.ID)
+		if err != nil {
+			return nil, err
+		}
+		show.TMDBID = match.ID
+		if last := airing.LastEpisode; last != nil {
+			show.LatestSeason = last.SeasonNumber
+			show.LatestEpisode = last.EpisodeNumber
+			show.LatestAirDate = last.AirDate
+		}
+		if next := airing.NextEpisode; next != nil {
+			show.NextSeason = next.SeasonNumber
+			show.NextEpisode = next.EpisodeNumber
+			show.NextName = next.Name
+			show.NextAirDate = next.AirDate
+		}
+
+		fetch := []int{}
+		lastSeason := 0
+		for season := range seasons {
+			fetch = append(fetch, season)
+			lastSeason = max(lastSeason, season)
+		}
+		for season := lastSeason + 1; season <= show.LatestSeason; season++ {
+			fetch = append(fetch, season)
+		}
+		slices.Sort(fetch)
+
+		for _, season := range fetch {
+			seasonEpisodes, err := h.tmdb.SeasonEpisodes(r.Context(), match.ID, season)
+			if err != nil {
+				return nil, err
+			}
+			for _, ep := range seasonEpisodes {
+				if ep.AirDate != "" {
+					episodes = append(episodes, database.EpisodeAirDate{Season: ep.SeasonNumber, Episode: ep.EpisodeNumber, AirDate: ep.AirDate})
+				}
+			}
+		}
+	}
+
+	if err := h.db.SetTVShow(show, episodes); err != nil {
+		return nil, err
+	}
+	return show, nil
+}
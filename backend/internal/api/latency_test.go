@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestGetEpisodeLatency(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	airDate := func(daysAgo int) string {
+		return now.AddDate(0, 0, -daysAgo).Format("2006-01-02")
+	}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/search/multi":
+			switch r.URL.Query().Get("query") {
+			case "The Bear":
+				w.Write([]byte(`{"results": [{"id": 1, "media_type": "tv", "name": "The Bear"}]}`))
+			case "Heat":
+				w.Write([]byte(`{"results": [{"id": 2, "media_type": "movie", "title": "Heat"}]}`))
+			default:
+				w.Write([]byte(`{"results": []}`))
+			}
+		case "/tv/1":
+			w.Write([]byte(`{"last_episode_to_air": {"season_number": 1, "episode_number": 3, "air_date": "` + airDate(2) + `"}}`))
+		case "/tv/1/season/1":
+			w.Write([]byte(`{"episodes": [
+				{"season_number": 1, "episode_number": 1, "air_date": "` + airDate(20) + `"},
+				{"season_number": 1, "episode_number": 2, "air_date": "` + airDate(10) + `"},
+				{"season_number": 1, "episode_number": 3, "air_date": "` + airDate(2) + `"}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "The Bear", EpisodeInfo: "S01E01", DurationMinutes: 30, WatchedAt: now.AddDate(0, 0, -19)},
+		{ServiceID: netflix.ID, Title: "The Bear", EpisodeInfo: "S01E02", DurationMinutes: 30, WatchedAt: now.AddDate(0, 0, -7)},
+		{ServiceID: netflix.ID, Title: "The Bear", EpisodeInfo: "S01E01", DurationMinutes: 30, WatchedAt: now.AddDate(0, 0, -1)},
+		{ServiceID: netflix.ID, Title: "Heat", EpisodeInfo: "S01E01", DurationMinutes: 170, WatchedAt: now},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	var response struct {
+		AverageDaysBehind float64                `json:"average_days_behind"`
+		Shows             []insights.ShowLatency `json:"shows"`
+		Behind            []insights.BehindShow  `json:"behind"`
+		PendingLookups    int                    `json:"pending_lookups"`
+	}
+	for i := range 2 {
+		req, _ := http.NewRequest("GET", "/api/insights/latency", nil)
+		rr := httptest.NewRecorder()
+		handler.getEpisodeLatency(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		// The first watch of each episode counts: 1 and 3 days after airing
+		if len(response.Shows) != 1 || response.Shows[0].Title != "The Bear" || response.Shows[0].AverageDaysBehind != 2 {
+			t.Errorf("Request %d: expected The Bear 2 days behind, got %+v", i, response.Shows)
+		}
+		if len(response.Behind) != 1 || response.Behind[0].EpisodesBehind != 1 || response.Behind[0].LatestAired != "S01E03" {
+			t.Errorf("Request %d: expected The Bear one episode behind, got %+v", i, response.Behind)
+		}
+		if response.PendingLookups != 0 {
+			t.Errorf("Request %d: expected no pending lookups, got %d", i, response.PendingLookups)
+		}
+
+		// Lookups, including the movie miss, are cached
+		if i == 0 {
+			requests.Store(0)
+		} else if n := requests.Load(); n != 0 {
+			t.Errorf("Expected cached air dates to be reused, got %d TMDB requests", n)
+		}
+	}
+}
+
+func TestGetEpisodeLatencyWithoutTMDB(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.tmdb = nil
+
+	req, _ := http.NewRequest("GET", "/api/insights/latency", nil)
+	rr := httptest.NewRecorder()
+	handler.getEpisodeLatency(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
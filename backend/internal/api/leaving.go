@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// getLeavingSoon lists rewatched titles that the scheduled availability check
+// found gone from the service they're rewatched on in the last ?days=
+// (default 30), in ?region= (default notifications.leaving.region)
+func (h *Handler) getLeavingSoon(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	days := parseIntParam(query.Get("days"), 30)
+	if days <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid days parameter", fmt.Errorf("days must be positive"))
+		return
+	}
+	region := strings.ToUpper(query.Get("region"))
+	if region == "" {
+		region = h.config.Notifications.Leaving.Region
+	}
+	if region == "" {
+		region = "US"
+	}
+
+	titles, err := h.db.GetLeavingTitles(region, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch leaving titles", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"titles":  titles,
+		"region":  region,
+		"days":    days,
+		"enabled": h.config.Notifications.Leaving.Enabled,
+	})
+}
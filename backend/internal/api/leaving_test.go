@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetLeavingSoon(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	gone := time.Now().AddDate(0, 0, -3)
+	if err := db.SetTitleAvailability(&database.TitleAvailability{Title: "The Office", ServiceID: netflix.ID, Region: "US", GoneSince: &gone}); err != nil {
+		t.Fatalf("Failed to set availability: %v", err)
+	}
+
+	for query, want := range map[string]int{"": 1, "?days=2": 0, "?region=gb": 0} {
+		req, _ := http.NewRequest("GET", "/api/leaving-soon"+query, nil)
+		rr := httptest.NewRecorder()
+		handler.getLeavingSoon(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		var response struct {
+			Titles []database.TitleAvailability `json:"titles"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Titles) != want {
+			t.Errorf("Expected %d titles for %q, got %+v", want, query, response.Titles)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/leaving-soon?days=0", nil)
+	rr := httptest.NewRecorder()
+	handler.getLeavingSoon(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// getMaturityStats reports watch time per content rating (TV-MA, PG-13, ...)
+// using TMDB certifications for ?region= (default US), so e.g. ?profile=kids
+// shows how much mature content a child's profile watched. Passing ?rating=TV-MA
+// also lists the titles with that rating. Like the originals stats, at most
+// lookup_limit new titles are looked up per request (default 25) and titles
+// not yet looked up are counted under an empty rating until a later request.
+func (h *Handler) getMaturityStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	if h.tmdb == nil {
+		respondError(w, http.StatusServiceUnavailable, "TMDB not configured", fmt.Errorf("tmdb.api_key is required"))
+		return
+	}
+
+	query := r.URL.Query()
+	startDate, endDate, err := parseYearMonthRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+	region := strings.ToUpper(query.Get("region"))
+	if region == "" {
+		region = "US"
+	}
+	lookupLimit := parseIntParam(query.Get("lookup_limit"), 25)
+
+	unrated, err := db.GetUnratedTitles(startDate, endDate, region)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch unrated titles", err)
+		return
+	}
+	lookups := 0
+	for _, title := range unrated {
+		if lookups >= lookupLimit {
+			break
+		}
+		lookups++
+		if _, err := h.lookupMaturityRating(r, title, region); err != nil {
+			log.Printf("Failed to look up maturity rating of '%s': %v", title, err)
+		}
+	}
+
+	stats, err := db.GetMaturityStats(startDate, endDate, region)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch maturity stats", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"ratings":         stats,
+		"region":          region,
+		"pending_lookups": len(unrated) - lookups,
+		"start_date":      startDate.Format("2006-01-02"),
+		"end_date":        endDate.Format("2006-01-02"),
+	}
+
+	if rating := strings.TrimSpace(query.Get("rating")); rating != "" {
+		titles, err := db.GetTitlesByMaturityRating(startDate, endDate, region, rating)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch titles", err)
+			return
+		}
+		response["rating"] = rating
+		response["titles"] = titles
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// lookupMaturityRating finds a title's content rating in a region on TMDB and
+// caches the result, including misses so they aren't looked up again
+func (h *Handler) lookupMaturityRating(r *http.Request, title, region string) (*database.MaturityRating, error) {
+	rating := &database.MaturityRating{Title: title, Region: region}
+
+	match, err := h.tmdb.SearchMulti(r.Context(), title)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil {
+		certification, err := h.tmdb.ContentRating(r.Context(), match.MediaType, match.ID, region)
+		if err != nil {
+			return nil, err
+		}
+		rating.TMDBID = match.ID
+		rating.Rating = certification
+	}
+
+	if err := h.db.SetMaturityRating(rating); err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
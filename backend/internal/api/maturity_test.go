@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestGetMaturityStats(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/multi":
+			switch r.URL.Query().Get("query") {
+			case "Squid Game":
+				w.Write([]byte(`{"results": [{"id": 1, "media_type": "tv", "name": "Squid Game"}]}`))
+			case "Bluey":
+				w.Write([]byte(`{"results": [{"id": 2, "media_type": "tv", "name": "Bluey"}]}`))
+			default:
+				w.Write([]byte(`{"results": []}`))
+			}
+		case "/tv/1/content_ratings":
+			w.Write([]byte(`{"results": [{"iso_3166_1": "US", "rating": "TV-MA"}]}`))
+		case "/tv/2/content_ratings":
+			w.Write([]byte(`{"results": [{"iso_3166_1": "US", "rating": "TV-Y"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	kids, _ := db.EnsureProfile("Kids")
+	now := time.Now()
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "Squid Game", DurationMinutes: 60, WatchedAt: now, ProfileID: kids.ID},
+		{ServiceID: netflix.ID, Title: "Bluey", DurationMinutes: 20, WatchedAt: now, ProfileID: kids.ID},
+		{ServiceID: netflix.ID, Title: "Squid Game", DurationMinutes: 60, WatchedAt: now.Add(-time.Hour)},
+		{ServiceID: netflix.ID, Title: "Home Video", DurationMinutes: 5, WatchedAt: now, ProfileID: kids.ID},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/stats/maturity?profile=kids&rating=tv-ma", nil)
+	rr := httptest.NewRecorder()
+	handler.getMaturityStats(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Ratings        []database.MaturityStats     `json:"ratings"`
+		Titles         []database.ServiceTitleStats `json:"titles"`
+		PendingLookups int                          `json:"pending_lookups"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Ratings) != 3 || response.Ratings[0].Rating != "TV-MA" || response.Ratings[0].TotalMinutes != 60 {
+		t.Errorf("Expected only the kids profile's 60 TV-MA minutes first, got %+v", response.Ratings)
+	}
+	if len(response.Titles) != 1 || response.Titles[0].Title != "Squid Game" {
+		t.Errorf("Expected Squid Game as the TV-MA title, got %+v", response.Titles)
+	}
+	if response.PendingLookups != 0 {
+		t.Errorf("Expected every title to be looked up, got %d pending", response.PendingLookups)
+	}
+
+	// Lookups, including misses, are cached
+	if rating, _ := db.GetMaturityRating("Bluey", "US"); rating == nil || rating.Rating != "TV-Y" {
+		t.Errorf("Expected Bluey's rating to be cached, got %+v", rating)
+	}
+	server.Close()
+	req, _ = http.NewRequest("GET", "/api/stats/maturity?lookup_limit=1", nil)
+	rr = httptest.NewRecorder()
+	handler.getMaturityStats(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	json.NewDecoder(rr.Body).Decode(&response)
+	if response.PendingLookups != 0 || response.Ratings[0].Rating != "TV-MA" || response.Ratings[0].TotalMinutes != 120 {
+		t.Errorf("Expected cached ratings across all profiles, got %+v with %d pending", response.Ratings, response.PendingLookups)
+	}
+}
+
+func TestGetMaturityStatsWithoutTMDB(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.tmdb = nil
+
+	req, _ := http.NewRequest("GET", "/api/stats/maturity", nil)
+	rr := httptest.NewRecorder()
+	handler.getMaturityStats(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// mergeService consolidates one service's history into another (e.g., HBO Max
+// into Max) and archives the source service
+func (h *Handler) mergeService(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sourceID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service ID", err)
+		return
+	}
+	targetID, err := strconv.ParseInt(vars["other"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid target service ID", err)
+		return
+	}
+	if sourceID == targetID {
+		respondError(w, http.StatusBadRequest, "Invalid merge", fmt.Errorf("cannot merge a service into itself"))
+		return
+	}
+
+	for _, id := range []int64{sourceID, targetID} {
+		service, err := h.db.GetServiceByID(id)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch service", err)
+			return
+		}
+		if service == nil {
+			respondError(w, http.StatusNotFound, "Service not found", fmt.Errorf("service with ID %d not found", id))
+			return
+		}
+		if service.Archived {
+			respondError(w, http.StatusConflict, "Service is archived", fmt.Errorf("service with ID %d is archived", id))
+			return
+		}
+	}
+
+	result, err := h.db.MergeServices(sourceID, targetID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to merge services", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
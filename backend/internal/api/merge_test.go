@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestMergeService(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	source, _ := db.GetServiceByName("HBO Max")
+	target, _ := db.GetServiceByName("Peacock")
+
+	merge := func() *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/api/services/x/merge-into/y", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{
+			"id":    strconv.FormatInt(source.ID, 10),
+			"other": strconv.FormatInt(target.ID, 10),
+		})
+		rr := httptest.NewRecorder()
+		handler.mergeService(rr, req)
+		return rr
+	}
+
+	rr := merge()
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var result database.MergeResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.SourceID != source.ID || result.TargetID != target.ID {
+		t.Errorf("Unexpected merge result: %+v", result)
+	}
+
+	// Merging an archived service again is a conflict
+	if rr := merge(); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d for archived source, got %d", http.StatusConflict, rr.Code)
+	}
+}
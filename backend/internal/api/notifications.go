@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// getNotifications returns the most recent notifications, newest first
+// (?limit=, default 50)
+func (h *Handler) getNotifications(w http.ResponseWriter, r *http.Request) {
+	limit := parseIntParam(r.URL.Query().Get("limit"), 50)
+	if limit <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid limit parameter", fmt.Errorf("limit must be positive"))
+		return
+	}
+
+	notifications, err := h.db.GetNotifications(limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch notifications", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, notifications)
+}
+
+// getMutedShows returns the shows with notifications turned off
+func (h *Handler) getMutedShows(w http.ResponseWriter, r *http.Request) {
+	titles, err := h.db.GetMutedShows()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch muted shows", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"titles": titles})
+}
+
+// setShowMuted turns notifications about a show off, or back on with
+// "muted": false
+func (h *Handler) setShowMuted(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title string `json:"title"`
+		Muted bool   `json:"muted"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		respondError(w, http.StatusBadRequest, "Invalid title", fmt.Errorf("title is required"))
+		return
+	}
+
+	if err := h.db.SetShowMuted(req.Title, req.Muted); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update show notifications", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"title": req.Title, "muted": req.Muted})
+}
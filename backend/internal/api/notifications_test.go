@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetNotifications(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	if err := db.AddNotification(&database.Notification{Kind: "new_season", Title: "Andor", Message: "Season 2 of Andor premiered"}); err != nil {
+		t.Fatalf("Failed to add notification: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/notifications", nil)
+	rr := httptest.NewRecorder()
+	handler.getNotifications(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var notifications []database.Notification
+	if err := json.NewDecoder(rr.Body).Decode(&notifications); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(notifications) != 1 || notifications[0].Title != "Andor" {
+		t.Errorf("Unexpected notifications: %+v", notifications)
+	}
+}
+
+func TestMuteShow(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("PUT", "/api/notifications/muted", strings.NewReader(`{"title": "Fargo", "muted": true}`))
+	rr := httptest.NewRecorder()
+	handler.setShowMuted(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/notifications/muted", nil)
+	rr = httptest.NewRecorder()
+	handler.getMutedShows(rr, req)
+
+	var response struct {
+		Titles []string `json:"titles"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Titles) != 1 || response.Titles[0] != "Fargo" {
+		t.Errorf("Expected Fargo muted, got %v", response.Titles)
+	}
+
+	req, _ = http.NewRequest("PUT", "/api/notifications/muted", strings.NewReader(`{"muted": true}`))
+	rr = httptest.NewRecorder()
+	handler.setShowMuted(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without a title, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
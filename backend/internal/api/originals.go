@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+)
+
+// serviceOrigins splits a service's watch time into its own originals and licensed content
+type serviceOrigins struct {
+	ServiceID          int64    `json:"service_id"`
+	ServiceName        string   `json:"service_name"`
+	OriginalMinutes    int      `json:"original_minutes"`
+	LicensedMinutes    int      `json:"licensed_minutes"`
+	UnknownMinutes     int      `json:"unknown_minutes"`
+	OriginalPercentage float64  `json:"original_percentage"` // Share of classified minutes
+	TopOriginals       []string `json:"top_originals"`
+	TopLicensed        []string `json:"top_licensed"`
+}
+
+// topOriginTitles is how many example titles are listed per classification
+const topOriginTitles = 5
+
+// getOriginalsStats classifies watched titles as platform originals or licensed
+// content using TMDB networks and production companies. Lookups are cached, and
+// at most lookup_limit new titles are looked up per request (default 25);
+// titles not yet looked up count as unknown until a later request.
+func (h *Handler) getOriginalsStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	if h.tmdb == nil {
+		respondError(w, http.StatusServiceUnavailable, "TMDB not configured", fmt.Errorf("tmdb.api_key is required"))
+		return
+	}
+
+	query := r.URL.Query()
+	startDate, endDate, err := parseYearMonthRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+	lookupLimit := parseIntParam(query.Get("lookup_limit"), 25)
+
+	titles, err := db.GetServiceTitleStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch title stats", err)
+		return
+	}
+
+	results := []*serviceOrigins{}
+	byService := make(map[int64]*serviceOrigins)
+	lookups, pending := 0, 0
+	for _, ts := range titles {
+		origin, err := db.GetTitleOrigin(ts.Title)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch title origin", err)
+			return
+		}
+		if origin == nil && lookups < lookupLimit {
+			lookups++
+			origin, err = h.lookupTitleOrigin(r, ts.Title)
+			if err != nil {
+				log.Printf("Failed to look up origin of '%s': %v", ts.Title, err)
+			}
+		}
+
+		classification := insights.OriginUnknown
+		if origin == nil {
+			pending++
+		} else {
+			classification = insights.ClassifyOrigin(ts.ServiceName, origin.Producers, origin.TMDBID != 0)
+		}
+
+		so, ok := byService[ts.ServiceID]
+		if !ok {
+			so = &serviceOrigins{
+				ServiceID:    ts.ServiceID,
+				ServiceName:  ts.ServiceName,
+				TopOriginals: []string{},
+				TopLicensed:  []string{},
+			}
+			byService[ts.ServiceID] = so
+			results = append(results, so)
+		}
+
+		// Titles arrive in descending watch time, so the first few are the top ones
+		switch classification {
+		case insights.OriginOriginal:
+			so.OriginalMinutes += ts.TotalMinutes
+			if len(so.TopOriginals) < topOriginTitles {
+				so.TopOriginals = append(so.TopOriginals, ts.Title)
+			}
+		case insights.OriginLicensed:
+			so.LicensedMinutes += ts.TotalMinutes
+			if len(so.TopLicensed) < topOriginTitles {
+				so.TopLicensed = append(so.TopLicensed, ts.Title)
+			}
+		default:
+			so.UnknownMinutes += ts.TotalMinutes
+		}
+	}
+
+	for _, so := range results {
+		if classified := so.OriginalMinutes + so.LicensedMinutes; classified > 0 {
+			so.OriginalPercentage = math.Round(float64(so.OriginalMinutes)/float64(classified)*1000) / 10
+		}
+	}
+
+	response := map[string]interface{}{
+		"services":        results,
+		"pending_lookups": pending,
+		"start_date":      startDate.Format("2006-01-02"),
+		"end_date":        endDate.Format("2006-01-02"),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// lookupTitleOrigin finds a title's producers on TMDB and caches the result,
+// including misses so they aren't looked up again
+func (h *Handler) lookupTitleOrigin(r *http.Request, title string) (*database.TitleOrigin, error) {
+	origin := &database.TitleOrigin{Title: title, Producers: []string{}}
+
+	match, err := h.tmdb.SearchMulti(r.Context(), title)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil {
+		producers, err := h.tmdb.Producers(r.Context(), match.MediaType, match.ID)
+		if err != nil {
+			return nil, err
+		}
+		origin.TMDBID = match.ID
+		origin.MediaType = match.MediaType
+		origin.Producers = producers
+	}
+
+	if err := h.db.SetTitleOrigin(origin); err != nil {
+		return nil, err
+	}
+	return origin, nil
+}
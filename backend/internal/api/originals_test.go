@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestGetOriginalsStats(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/multi":
+			switch r.URL.Query().Get("query") {
+			case "Stranger Things":
+				w.Write([]byte(`{"results": [{"id": 1, "media_type": "tv", "name": "Stranger Things"}]}`))
+			case "The Office":
+				w.Write([]byte(`{"results": [{"id": 2, "media_type": "tv", "name": "The Office"}]}`))
+			default:
+				w.Write([]byte(`{"results": []}`))
+			}
+		case "/tv/1":
+			w.Write([]byte(`{"networks": [{"name": "Netflix"}]}`))
+		case "/tv/2":
+			w.Write([]byte(`{"networks": [{"name": "NBC"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	now := time.Now()
+	for i, item := range []struct {
+		title   string
+		minutes int
+	}{{"Stranger Things", 100}, {"The Office", 300}, {"Home Video", 50}} {
+		db.InsertWatchHistory(&database.WatchHistory{
+			ServiceID:       service.ID,
+			Title:           item.title,
+			DurationMinutes: item.minutes,
+			WatchedAt:       now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+
+	req, err := http.NewRequest("GET", "/api/stats/originals", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getOriginalsStats(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Services []serviceOrigins `json:"services"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Services) != 1 {
+		t.Fatalf("Expected 1 service, got %d", len(response.Services))
+	}
+	netflix := response.Services[0]
+	if netflix.OriginalMinutes != 100 || netflix.LicensedMinutes != 300 || netflix.UnknownMinutes != 50 {
+		t.Errorf("Unexpected split: %+v", netflix)
+	}
+	if netflix.OriginalPercentage != 25 {
+		t.Errorf("Expected 25%% originals, got %v", netflix.OriginalPercentage)
+	}
+
+	// Lookups are cached for later requests
+	origin, _ := db.GetTitleOrigin("The Office")
+	if origin == nil || origin.TMDBID != 2 {
+		t.Errorf("Expected cached origin for The Office, got %+v", origin)
+	}
+}
+
+func TestGetOriginalsStatsWithoutTMDB(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.tmdb = nil
+
+	req, _ := http.NewRequest("GET", "/api/stats/originals", nil)
+	rr := httptest.NewRecorder()
+	handler.getOriginalsStats(rr, req)
+
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, status)
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// overviewTopTitles is how many titles GET /api/stats/overview returns
+// unless ?limit= asks for another number
+const overviewTopTitles = 10
+
+// overviewStats is the response of GET /api/stats/overview
+type overviewStats struct {
+	StartDate        string                  `json:"start_date"`
+	EndDate          string                  `json:"end_date"`
+	TotalMinutes     int                     `json:"total_minutes"`
+	EffectiveMinutes *int                    `json:"effective_minutes,omitempty"` // Without intros and credits, when configured
+	AdMinutes        *int                    `json:"ad_minutes,omitempty"`        // Estimated commercials on ad-supported services
+	TotalItems       int                     `json:"total_items"`
+	Services         []database.ServiceStats `json:"services"`
+	TopTitles        []database.TitleStats   `json:"top_titles"`
+	Rewatches        *database.RewatchStats  `json:"rewatches"`
+	TopRewatches     []database.TitleStats   `json:"top_rewatches"`
+	Weekdays         []weekdayTotal          `json:"weekdays"`
+	BusiestDay       *weekdayTotal           `json:"busiest_day"`
+}
+
+// weekdayTotal is the watch time that fell on one day of the week
+type weekdayTotal struct {
+	Day     string `json:"day"`
+	Minutes int    `json:"minutes"`
+}
+
+// getOverview returns everything the dashboard shows for a date range in one
+// call: totals, the per-service breakdown, top titles, the split between
+// first watches and rewatches, and watch time by day of the week. The range is ?start_date=&end_date= (inclusive), or the usual
+// ?year=&month= filters, defaulting to all-time.
+func (h *Handler) getOverview(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+	startDate, endDate, err := parseOverviewRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date range", err)
+		return
+	}
+	limit := parseIntParam(query.Get("limit"), overviewTopTitles)
+	if limit <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid limit parameter", fmt.Errorf("limit must be positive"))
+		return
+	}
+
+	services, err := db.GetServiceStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service stats", err)
+		return
+	}
+	effective, err := h.addEffectiveMinutes(db, services, startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to estimate effective minutes", err)
+		return
+	}
+	titles, err := db.GetTopTitles(startDate, endDate, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch top titles", err)
+		return
+	}
+	rewatches, err := db.GetRewatchStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch rewatch stats", err)
+		return
+	}
+	topRewatches, err := db.GetTopRewatchedTitles(startDate, endDate, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch rewatched titles", err)
+		return
+	}
+	daily, err := db.GetDailyTotals(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch daily totals", err)
+		return
+	}
+
+	overview := overviewStats{
+		StartDate:        startDate.Format("2006-01-02"),
+		EndDate:          endDate.Format("2006-01-02"),
+		EffectiveMinutes: effective,
+		AdMinutes:        h.addAdMinutes(services),
+		Services:         []database.ServiceStats{},
+		TopTitles:        titles,
+		Rewatches:        rewatches,
+		TopRewatches:     topRewatches,
+		Weekdays:         weekdayTotals(daily, h.config.Display.FirstDayOfWeek()),
+	}
+	for _, stat := range services {
+		overview.TotalMinutes += stat.TotalMinutes
+		overview.TotalItems += stat.TotalShows
+		overview.Services = append(overview.Services, stat)
+	}
+	for i, day := range overview.Weekdays {
+		if day.Minutes > 0 && (overview.BusiestDay == nil || day.Minutes > overview.BusiestDay.Minutes) {
+			overview.BusiestDay = &overview.Weekdays[i]
+		}
+	}
+
+	respondJSON(w, http.StatusOK, overview)
+}
+
+// parseOverviewRange returns the range selected by start_date and end_date,
+// falling back to the year and month filters when neither is given
+func parseOverviewRange(query url.Values) (time.Time, time.Time, error) {
+	if query.Get("start_date") == "" && query.Get("end_date") == "" {
+		return parseYearMonthRange(query)
+	}
+	allTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	return parseDateRange(query, "start_date", "end_date", allTime, time.Now().AddDate(1, 0, 0))
+}
+
+// weekdayTotals sums daily minutes (keyed YYYY-MM-DD) by day of the week,
+// ordered from the configured first day of the week
+func weekdayTotals(daily map[string]int, first time.Weekday) []weekdayTotal {
+	var minutes [7]int
+	for day, total := range daily {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		minutes[date.Weekday()] += total
+	}
+
+	totals := make([]weekdayTotal, 0, 7)
+	for i := range 7 {
+		day := time.Weekday((int(first) + i) % 7)
+		totals = append(totals, weekdayTotal{Day: day.String(), Minutes: minutes[day]})
+	}
+	return totals
+}
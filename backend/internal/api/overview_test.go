@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetOverview(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hulu, _ := db.GetServiceByName("Hulu")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.UpdateServiceEnabled(hulu.ID, true)
+
+	// 2025-03-01 is a Saturday and 2025-03-03 a Monday
+	saturday := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	monday := time.Date(2025, 3, 3, 20, 0, 0, 0, time.UTC)
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: saturday},
+		{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: saturday.Add(time.Hour)},
+		{ServiceID: hulu.ID, Title: "The Bear", DurationMinutes: 30, WatchedAt: monday},
+		{ServiceID: hulu.ID, Title: "Shogun", DurationMinutes: 60, WatchedAt: time.Date(2025, 4, 1, 20, 0, 0, 0, time.UTC)},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/stats/overview?start_date=2025-03-01&end_date=2025-03-31", nil)
+	rr := httptest.NewRecorder()
+	handler.getOverview(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var overview overviewStats
+	if err := json.NewDecoder(rr.Body).Decode(&overview); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if overview.TotalMinutes != 130 || overview.TotalItems != 3 {
+		t.Errorf("Expected 130 minutes over 3 items, got %d over %d", overview.TotalMinutes, overview.TotalItems)
+	}
+	if overview.EndDate != "2025-04-01" {
+		t.Errorf("Expected the end date to include all of March 31, got %s", overview.EndDate)
+	}
+	if len(overview.Services) < 2 || overview.Services[0].ServiceName != "Netflix" {
+		t.Errorf("Expected Netflix to lead the breakdown, got %+v", overview.Services)
+	}
+	if len(overview.TopTitles) != 2 || overview.TopTitles[0].Title != "Severance" {
+		t.Errorf("Expected Severance then The Bear, got %+v", overview.TopTitles)
+	}
+	if len(overview.Weekdays) != 7 || overview.Weekdays[0].Day != "Monday" {
+		t.Errorf("Expected seven weekdays starting Monday, got %+v", overview.Weekdays)
+	}
+	if overview.BusiestDay == nil || overview.BusiestDay.Day != "Saturday" || overview.BusiestDay.Minutes != 100 {
+		t.Errorf("Expected Saturday to be the busiest day, got %+v", overview.BusiestDay)
+	}
+	if overview.Rewatches == nil || overview.Rewatches.RewatchMinutes != 50 || overview.Rewatches.NewMinutes != 80 {
+		t.Errorf("Expected the second Severance viewing to be a rewatch, got %+v", overview.Rewatches)
+	}
+	if len(overview.TopRewatches) != 1 || overview.TopRewatches[0].Title != "Severance" {
+		t.Errorf("Expected Severance as the top rewatch, got %+v", overview.TopRewatches)
+	}
+}
+
+func TestGetOverviewInvalidRange(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	for _, query := range []string{"start_date=March", "start_date=2025-03-31&end_date=2025-03-01", "limit=0"} {
+		req, _ := http.NewRequest("GET", "/api/stats/overview?"+query, nil)
+		rr := httptest.NewRecorder()
+		handler.getOverview(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestGetOverviewAdMinutes(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Insights.Ads.MinutesPerHour = 4
+	handler.config.Services["netflix"] = config.ServiceConfig{Enabled: true, AdSupported: true}
+	handler.config.Services["hulu"] = config.ServiceConfig{Enabled: true, AdSupported: true, AdMinutesPerHour: 9}
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hulu, _ := db.GetServiceByName("Hulu")
+	hbo, _ := db.GetServiceByName("HBO Max")
+	now := time.Now()
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 120, WatchedAt: now},
+		{ServiceID: hulu.ID, Title: "Shogun", DurationMinutes: 60, WatchedAt: now},
+		{ServiceID: hbo.ID, Title: "The Last of Us", DurationMinutes: 60, WatchedAt: now},
+	} {
+		db.UpdateServiceEnabled(wh.ServiceID, true)
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/stats/overview", nil)
+	rr := httptest.NewRecorder()
+	handler.getOverview(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var overview overviewStats
+	if err := json.NewDecoder(rr.Body).Decode(&overview); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if overview.AdMinutes == nil || *overview.AdMinutes != 17 {
+		t.Errorf("Expected 8 Netflix and 9 Hulu ad minutes, got %v", overview.AdMinutes)
+	}
+	for _, stat := range overview.Services {
+		if stat.ServiceName == "HBO Max" && stat.AdMinutes != nil {
+			t.Errorf("Expected no ad estimate for an ad-free service, got %d", *stat.AdMinutes)
+		}
+	}
+}
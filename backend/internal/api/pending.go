@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// getPendingItems returns scraped items awaiting review, optionally for one service
+func (h *Handler) getPendingItems(w http.ResponseWriter, r *http.Request) {
+	var serviceID int64
+	if serviceIDStr := r.URL.Query().Get("service_id"); serviceIDStr != "" {
+		id, err := strconv.ParseInt(serviceIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid service_id", err)
+			return
+		}
+		serviceID = id
+	}
+
+	items, err := h.db.GetPendingItems(serviceID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch pending items", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, items)
+}
+
+// updatePendingItem edits a pending item before it's approved. Omitted fields
+// are left unchanged.
+func (h *Handler) updatePendingItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid pending item ID", err)
+		return
+	}
+
+	var req struct {
+		Title           *string    `json:"title"`
+		DurationMinutes *int       `json:"duration_minutes"`
+		WatchedAt       *time.Time `json:"watched_at"`
+		EpisodeInfo     *string    `json:"episode_info"`
+		Genre           *string    `json:"genre"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	item, err := h.db.GetPendingItem(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch pending item", err)
+		return
+	}
+	if item == nil {
+		respondError(w, http.StatusNotFound, "Pending item not found", fmt.Errorf("pending item with ID %d not found", id))
+		return
+	}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			respondError(w, http.StatusBadRequest, "Invalid title", fmt.Errorf("title must not be empty"))
+			return
+		}
+		item.Title = title
+	}
+	if req.DurationMinutes != nil {
+		if *req.DurationMinutes < 0 {
+			respondError(w, http.StatusBadRequest, "Invalid duration_minutes", fmt.Errorf("duration must not be negative"))
+			return
+		}
+		item.DurationMinutes = *req.DurationMinutes
+	}
+	if req.WatchedAt != nil {
+		item.WatchedAt = *req.WatchedAt
+	}
+	if req.EpisodeInfo != nil {
+		item.EpisodeInfo = *req.EpisodeInfo
+	}
+	if req.Genre != nil {
+		item.Genre = *req.Genre
+	}
+
+	if _, err := h.db.UpdatePendingItem(item); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update pending item", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, item)
+}
+
+// pendingIDsRequest is the body of the approve and reject endpoints
+type pendingIDsRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// decodePendingIDs reads the pending item IDs from a request body
+func decodePendingIDs(w http.ResponseWriter, r *http.Request) ([]int64, bool) {
+	var req pendingIDsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return nil, false
+	}
+	if len(req.IDs) == 0 {
+		respondError(w, http.StatusBadRequest, "Invalid ids", fmt.Errorf("at least one pending item ID is required"))
+		return nil, false
+	}
+	return req.IDs, true
+}
+
+// approvePendingItems merges pending items into watch history
+func (h *Handler) approvePendingItems(w http.ResponseWriter, r *http.Request) {
+	ids, ok := decodePendingIDs(w, r)
+	if !ok {
+		return
+	}
+
+	approved, err := h.db.ApprovePendingItems(ids)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to approve pending items", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"approved": approved,
+	})
+}
+
+// rejectPendingItems discards pending items without adding them to watch history
+func (h *Handler) rejectPendingItems(w http.ResponseWriter, r *http.Request) {
+	ids, ok := decodePendingIDs(w, r)
+	if !ok {
+		return
+	}
+
+	rejected, err := h.db.RejectPendingItems(ids)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to reject pending items", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"rejected": rejected,
+	})
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestReviewPendingItems(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+	db.InsertPendingItem(&database.WatchHistory{ServiceID: service.ID, Title: "Misparsed Title", DurationMinutes: 30, WatchedAt: now})
+	db.InsertPendingItem(&database.WatchHistory{ServiceID: service.ID, Title: "Bogus Row", DurationMinutes: 0, WatchedAt: now})
+
+	req, _ := http.NewRequest("GET", "/api/pending?service_id="+strconv.FormatInt(service.ID, 10), nil)
+	rr := httptest.NewRecorder()
+	handler.getPendingItems(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	var items []database.PendingItem
+	if err := json.NewDecoder(rr.Body).Decode(&items); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 pending items, got %d", len(items))
+	}
+	ids := map[string]string{}
+	for _, item := range items {
+		ids[item.Title] = strconv.FormatInt(item.ID, 10)
+	}
+
+	req, _ = http.NewRequest("PUT", "/api/pending/"+ids["Misparsed Title"], strings.NewReader(`{"title": "Fixed Title"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": ids["Misparsed Title"]})
+	rr = httptest.NewRecorder()
+	handler.updatePendingItem(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/api/pending/approve", strings.NewReader(`{"ids": [`+ids["Misparsed Title"]+`]}`))
+	rr = httptest.NewRecorder()
+	handler.approvePendingItems(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/pending/reject", strings.NewReader(`{"ids": [`+ids["Bogus Row"]+`]}`))
+	rr = httptest.NewRecorder()
+	handler.rejectPendingItems(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	history, _ := db.GetWatchHistory(service.ID, now.Add(-time.Hour), now.Add(time.Hour), 10, 0)
+	if len(history) != 1 || history[0].Title != "Fixed Title" {
+		t.Errorf("Expected only the edited item in watch history, got %+v", history)
+	}
+}
+
+func TestUpdatePendingItemNotFound(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("PUT", "/api/pending/42", strings.NewReader(`{"genre": "Drama"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "42"})
+	rr := httptest.NewRecorder()
+	handler.updatePendingItem(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestRejectPendingItemsRequiresIDs(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("POST", "/api/pending/reject", strings.NewReader(`{"ids": []}`))
+	rr := httptest.NewRecorder()
+	handler.rejectPendingItems(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// getProfiles returns the profiles stats and history can be filtered by
+func (h *Handler) getProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.db.GetProfiles()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch profiles", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profiles)
+}
+
+// profileDB returns the database scoped to the profile named by the
+// ?profile= parameter, which takes a profile's name or ID, or the whole
+// database when the parameter isn't set
+func (h *Handler) profileDB(r *http.Request) (*database.DB, error) {
+	param := r.URL.Query().Get("profile")
+	if param == "" {
+		return h.db, nil
+	}
+
+	var profile *database.Profile
+	var err error
+	if id, parseErr := strconv.ParseInt(param, 10, 64); parseErr == nil {
+		profile, err = h.db.GetProfile(id)
+	} else {
+		profile, err = h.db.GetProfileByName(param)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("unknown profile %q", param)
+	}
+	return h.db.ForProfile(profile.ID), nil
+}
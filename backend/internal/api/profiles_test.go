@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetProfiles(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	db.EnsureProfile("Sam")
+	db.EnsureProfile("Alex")
+
+	req, _ := http.NewRequest("GET", "/api/profiles", nil)
+	rr := httptest.NewRecorder()
+	handler.getProfiles(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var profiles []database.Profile
+	if err := json.NewDecoder(rr.Body).Decode(&profiles); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != "Alex" {
+		t.Errorf("Expected Alex and Sam by name, got %+v", profiles)
+	}
+}
+
+func TestStatsFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	alex, _ := db.EnsureProfile("Alex")
+	sam, _ := db.EnsureProfile("Sam")
+	now := time.Now()
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: now, ProfileID: alex.ID})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: now, ProfileID: sam.ID})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Love Is Blind", DurationMinutes: 60, WatchedAt: now, ProfileID: sam.ID})
+
+	for _, tc := range []struct {
+		query   string
+		minutes int
+	}{
+		{"", 160},
+		{"?profile=alex", 50},
+		{"?profile=" + strconv.FormatInt(sam.ID, 10), 110},
+	} {
+		req, _ := http.NewRequest("GET", "/api/services"+tc.query, nil)
+		rr := httptest.NewRecorder()
+		handler.getServices(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%q: expected status code %d, got %d: %s", tc.query, http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		var stats []database.ServiceStats
+		if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		var minutes int
+		for _, s := range stats {
+			minutes += s.TotalMinutes
+		}
+		if minutes != tc.minutes {
+			t.Errorf("%q: expected %d minutes, got %d", tc.query, tc.minutes, minutes)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/services?profile=nobody", nil)
+	rr := httptest.NewRecorder()
+	handler.getServices(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an unknown profile, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+// watchAs records a Netflix play of title for the named profile, enabling
+// Netflix and creating the profile as needed
+func watchAs(t *testing.T, db *database.DB, profile, title string, minutes int, watchedAt time.Time) {
+	t.Helper()
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	p, err := db.EnsureProfile(profile)
+	if err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	if err := db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: title, DurationMinutes: minutes, WatchedAt: watchedAt, ProfileID: p.ID}); err != nil {
+		t.Fatalf("Failed to insert history: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/query"
+)
+
+// queryRequest is the body of a natural-language stats question
+type queryRequest struct {
+	Question string `json:"question"`
+}
+
+// postQuery answers questions like "how many hours of Netflix in March" by
+// parsing them into a structured query and running it against watch history
+func (h *Handler) postQuery(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if strings.TrimSpace(req.Question) == "" {
+		respondError(w, http.StatusBadRequest, "Question is required", fmt.Errorf("question is empty"))
+		return
+	}
+
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	services, err := db.GetAllServices()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		names = append(names, svc.Name)
+	}
+
+	q, err := query.Parse(req.Question, names, time.Now(), h.config.Display.FirstDayOfWeek())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Could not understand question", err)
+		return
+	}
+
+	minutes, count, err := db.GetWatchTotals(q.Service, q.Title, q.Start, q.End)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to run query", err)
+		return
+	}
+
+	var value float64
+	switch q.Aggregate {
+	case query.AggregateMinutes:
+		value = float64(minutes)
+	case query.AggregateCount:
+		value = float64(count)
+	default:
+		value = float64(minutes) / 60
+	}
+
+	response := map[string]interface{}{
+		"question": req.Question,
+		"answer":   query.Answer(q, minutes, count),
+		"value":    value,
+		"query":    q,
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
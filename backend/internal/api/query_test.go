@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestPostQuery(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	for _, title := range []string{"The Office", "The Office", "Severance"} {
+		db.InsertWatchHistory(&database.WatchHistory{
+			ServiceID:       service.ID,
+			Title:           title,
+			DurationMinutes: 30,
+			WatchedAt:       time.Now().Add(-time.Hour),
+		})
+	}
+
+	body := strings.NewReader(`{"question": "How many hours of Netflix?"}`)
+	req, err := http.NewRequest("POST", "/api/query", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.postQuery(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Answer string  `json:"answer"`
+		Value  float64 `json:"value"`
+		Query  struct {
+			Aggregate string `json:"aggregate"`
+			Service   string `json:"service"`
+		} `json:"query"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Value != 1.5 {
+		t.Errorf("Expected 1.5 hours, got %v", response.Value)
+	}
+	if response.Query.Service != "Netflix" || response.Query.Aggregate != "hours" {
+		t.Errorf("Unexpected structured query: %+v", response.Query)
+	}
+	if response.Answer != "You watched 1.5 hours of Netflix of all time." {
+		t.Errorf("Unexpected answer: %s", response.Answer)
+	}
+}
+
+func TestPostQueryFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	watchAs(t, db, "Alex", "Severance", 30, time.Now().Add(-time.Hour))
+	watchAs(t, db, "Sam", "Love Is Blind", 60, time.Now().Add(-time.Hour))
+
+	body := strings.NewReader(`{"question": "How many hours of Netflix?"}`)
+	req, _ := http.NewRequest("POST", "/api/query?profile=alex", body)
+	rr := httptest.NewRecorder()
+	handler.postQuery(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Value float64 `json:"value"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Value != 0.5 {
+		t.Errorf("Expected only Alex's 0.5 hours, got %v", response.Value)
+	}
+}
+
+func TestPostQueryTitleCount(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	for _, title := range []string{"The Office", "the office", "Severance"} {
+		db.InsertWatchHistory(&database.WatchHistory{
+			ServiceID:       service.ID,
+			Title:           title,
+			DurationMinutes: 30,
+			WatchedAt:       time.Now().Add(-time.Hour),
+		})
+	}
+
+	body := strings.NewReader(`{"question": "how many times did I watch \"The Office\""}`)
+	req, _ := http.NewRequest("POST", "/api/query", body)
+	rr := httptest.NewRecorder()
+	handler.postQuery(rr, req)
+
+	var response struct {
+		Value float64 `json:"value"`
+	}
+	json.NewDecoder(rr.Body).Decode(&response)
+	if response.Value != 2 {
+		t.Errorf("Expected 2 watches, got %v", response.Value)
+	}
+}
+
+func TestPostQueryEmpty(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("POST", "/api/query", strings.NewReader(`{"question": ""}`))
+	rr := httptest.NewRecorder()
+	handler.postQuery(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// readOnlyPosts are POST endpoints that only read, left open in read-only mode
+var readOnlyPosts = map[string]bool{
+	"/api/query":          true,
+	"/api/voice/summary":  true,
+	"/api/grafana/search": true,
+	"/api/grafana/query":  true,
+}
+
+// rejectWritesWhenReadOnly refuses every request that could change data,
+// trigger a scrape or launch a browser when server.read_only is set, so an
+// instance can be exposed publicly for its stats alone
+func (h *Handler) rejectWritesWhenReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.config.Server.ReadOnly && !isReadRequest(r) {
+			respondError(w, http.StatusForbidden, "Server is read-only",
+				fmt.Errorf("%s %s is disabled by server.read_only", r.Method, r.URL.Path))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isReadRequest reports whether a request only reads data
+func isReadRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return readOnlyPosts[r.URL.Path]
+	default:
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Server.ReadOnly = true
+	router := NewRouter(handler)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"GET", "/api/services", "", http.StatusOK},
+		{"POST", "/api/query", `{"question": "how much did I watch this week"}`, http.StatusOK},
+		{"POST", "/api/scrape/Netflix", "", http.StatusForbidden},
+		{"POST", "/api/ignored-titles", `{"title": "Dark"}`, http.StatusForbidden},
+		{"DELETE", "/api/title-aliases/1", "", http.StatusForbidden},
+		{"PATCH", "/api/history/bulk", `{}`, http.StatusForbidden},
+		{"PUT", "/api/history/1/rating", `{"rating": 5}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.status, rr.Code)
+		}
+	}
+}
+
+func TestReadOnlyDisabledAllowsWrites(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	router := NewRouter(handler)
+
+	req, _ := http.NewRequest("POST", "/api/ignored-titles", strings.NewReader(`{"title": "Dark"}`))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code == http.StatusForbidden {
+		t.Errorf("Expected writes to be allowed when not read-only, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/recommend"
+)
+
+// getRecommendations suggests titles similar to the user's most watched recent
+// titles that are available on services they subscribe to
+func (h *Handler) getRecommendations(w http.ResponseWriter, r *http.Request) {
+	if h.tmdb == nil {
+		respondError(w, http.StatusServiceUnavailable, "TMDB not configured", fmt.Errorf("tmdb.api_key is required"))
+		return
+	}
+
+	query := r.URL.Query()
+	days := parseIntParam(query.Get("days"), 90)
+	seedCount := parseIntParam(query.Get("seeds"), 5)
+	limit := parseIntParam(query.Get("limit"), 20)
+	if days <= 0 || seedCount <= 0 || limit <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid parameters", fmt.Errorf("days, seeds and limit must be positive"))
+		return
+	}
+	region := strings.ToUpper(query.Get("region"))
+	if region == "" {
+		region = "US"
+	}
+
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	now := time.Now()
+	seeds, err := db.GetTopTitles(now.AddDate(0, 0, -days), now, seedCount)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch top titles", err)
+		return
+	}
+
+	// Everything ever watched is excluded from suggestions
+	watched, err := db.GetTopTitles(time.Time{}, now.AddDate(1, 0, 0), 100000)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch watched titles", err)
+		return
+	}
+
+	services, err := h.db.GetAllServices()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+	var subscribed []string
+	for _, svc := range services {
+		if svc.Enabled && !svc.Archived {
+			subscribed = append(subscribed, svc.Name)
+		}
+	}
+
+	recs, err := recommend.Build(r.Context(), h.tmdb, seeds, watched, recommend.Options{
+		Region:     region,
+		Limit:      limit,
+		Subscribed: subscribed,
+	})
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to build recommendations", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"recommendations": recs,
+		"seeds":           seeds,
+		"region":          region,
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
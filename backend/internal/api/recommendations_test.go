@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/recommend"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestGetRecommendations(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/multi":
+			w.Write([]byte(`{"results": [{"id": 1, "media_type": "tv", "name": "Severance"}]}`))
+		case "/tv/1/recommendations":
+			w.Write([]byte(`{"results": [{"id": 10, "name": "Silo"}, {"id": 11, "name": "Dark"}]}`))
+		case "/tv/10/watch/providers":
+			w.Write([]byte(`{"results": {"US": {"flatrate": [{"provider_name": "Apple TV Plus"}]}}}`))
+		case "/tv/11/watch/providers":
+			w.Write([]byte(`{"results": {"US": {"flatrate": [{"provider_name": "Netflix"}]}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       service.ID,
+		Title:           "Severance",
+		DurationMinutes: 50,
+		WatchedAt:       time.Now().Add(-24 * time.Hour),
+	})
+
+	req, err := http.NewRequest("GET", "/api/recommendations", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getRecommendations(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Recommendations []recommend.Recommendation `json:"recommendations"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// Silo is only on Apple TV+, which isn't enabled
+	if len(response.Recommendations) != 1 || response.Recommendations[0].Title != "Dark" {
+		t.Errorf("Expected only Dark, got %+v", response.Recommendations)
+	}
+}
+
+func TestGetRecommendationsFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	var searched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/multi" {
+			searched = append(searched, r.URL.Query().Get("query"))
+		}
+		w.Write([]byte(`{"results": []}`))
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	watchAs(t, db, "Alex", "Severance", 50, time.Now().Add(-24*time.Hour))
+	watchAs(t, db, "Sam", "Love Is Blind", 60, time.Now().Add(-24*time.Hour))
+
+	req, _ := http.NewRequest("GET", "/api/recommendations?profile=alex", nil)
+	rr := httptest.NewRecorder()
+	handler.getRecommendations(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	// Only Alex's titles seed recommendations
+	if len(searched) != 1 || searched[0] != "Severance" {
+		t.Errorf("Expected to search only for Severance, searched %v", searched)
+	}
+}
+
+func TestGetRecommendationsWithoutTMDB(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.tmdb = nil
+
+	req, err := http.NewRequest("GET", "/api/recommendations", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getRecommendations(rr, req)
+
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, status)
+	}
+}
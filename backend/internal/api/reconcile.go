@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/reconcile"
+)
+
+// reconcileHistory merges inferred sessions from device usage and network
+// traffic into better evidence for the same viewing. ?since=YYYY-MM-DD limits
+// it to recent history and ?dry_run=true only reports what would merge.
+func (h *Handler) reconcileHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var since time.Time
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		var err error
+		if since, err = time.Parse("2006-01-02", sinceStr); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid since parameter", err)
+			return
+		}
+	}
+
+	result, err := reconcile.Run(h.db, since, query.Get("dry_run") == "true")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to reconcile history", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/reconcile"
+)
+
+func TestReconcileHistory(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	evening := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Device Usage", DurationMinutes: 70, WatchedAt: evening, Confidence: database.ConfidenceMedium})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Network Activity", DurationMinutes: 90, WatchedAt: evening, Confidence: database.ConfidenceLow})
+
+	req, _ := http.NewRequest("POST", "/api/reconcile?dry_run=true&since=2025-01-01", nil)
+	rr := httptest.NewRecorder()
+	handler.reconcileHistory(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var result reconcile.Result
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !result.DryRun || result.Removed != 1 || result.Merges[0].Removed.Title != "Network Activity" {
+		t.Errorf("Expected the network session to merge into device usage, got %+v", result)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/reconcile?since=yesterday", nil)
+	rr = httptest.NewRecorder()
+	handler.reconcileHistory(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -13,17 +13,108 @@ func NewRouter(handler *Handler) http.Handler {
 
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
+	api.Use(handler.rejectWritesWhenReadOnly)
+	api.Use(handler.formatDurations)
 
 	api.HandleFunc("/health", handler.healthCheck).Methods("GET")
 	api.HandleFunc("/services", handler.getServices).Methods("GET")
 	api.HandleFunc("/services/{id:[0-9]+}/history", handler.getServiceHistory).Methods("GET")
+	api.HandleFunc("/services/{id:[0-9]+}/history", handler.deleteServiceHistory).Methods("DELETE")
+	api.HandleFunc("/services/{id:[0-9]+}/merge-into/{other:[0-9]+}", handler.mergeService).Methods("POST")
+	api.HandleFunc("/services/{id:[0-9]+}/check-auth", handler.checkServiceAuth).Methods("POST")
+	api.HandleFunc("/services/{id:[0-9]+}/cookies", handler.importServiceCookies).Methods("POST")
+	api.HandleFunc("/services/{id:[0-9]+}/cookies", handler.deleteServiceCookies).Methods("DELETE")
+	api.HandleFunc("/services/{id:[0-9]+}/consent", handler.getServiceConsent).Methods("GET")
+	api.HandleFunc("/services/{id:[0-9]+}/consent", handler.setServiceConsent).Methods("PUT")
+	api.HandleFunc("/scrape/jobs", handler.getScrapeJobs).Methods("GET")
+	api.HandleFunc("/scrape/jobs/{id:[0-9]+}", handler.getScrapeJob).Methods("GET")
 	api.HandleFunc("/scrape/{service}", handler.triggerScrape).Methods("POST")
 	api.HandleFunc("/scraper/status", handler.getScraperStatus).Methods("GET")
+	api.HandleFunc("/scraper/circuits", handler.getScraperCircuits).Methods("GET")
+	api.HandleFunc("/scraper/consent", handler.getScraperConsent).Methods("GET")
+	api.HandleFunc("/scraper/reliability", handler.getScraperReliability).Methods("GET")
+	api.HandleFunc("/scraper/runs/{id:[0-9]+}/bundle", handler.downloadRunBundle).Methods("POST")
+	api.HandleFunc("/import/{service}", handler.importHistory).Methods("POST")
+	api.HandleFunc("/upload/youtube-takeout", handler.importYouTubeTakeout).Methods("POST")
+	api.HandleFunc("/screen-time", handler.importScreenTime).Methods("POST")
+	api.HandleFunc("/ingest/device", handler.ingestDeviceEvents).Methods("POST")
+	api.HandleFunc("/ingest/network", handler.ingestNetworkObservations).Methods("POST")
+	api.HandleFunc("/reconcile", handler.reconcileHistory).Methods("POST")
+	api.HandleFunc("/pending", handler.getPendingItems).Methods("GET")
+	api.HandleFunc("/pending/approve", handler.approvePendingItems).Methods("POST")
+	api.HandleFunc("/pending/reject", handler.rejectPendingItems).Methods("POST")
+	api.HandleFunc("/pending/{id:[0-9]+}", handler.updatePendingItem).Methods("PUT")
+	api.HandleFunc("/ignored-titles", handler.getIgnoredTitles).Methods("GET")
+	api.HandleFunc("/ignored-titles", handler.addIgnoredTitle).Methods("POST")
+	api.HandleFunc("/ignored-titles/{id:[0-9]+}", handler.deleteIgnoredTitle).Methods("DELETE")
+	api.HandleFunc("/title-aliases", handler.getTitleAliases).Methods("GET")
+	api.HandleFunc("/title-aliases", handler.addTitleAlias).Methods("POST")
+	api.HandleFunc("/title-aliases/apply", handler.applyTitleAliases).Methods("POST")
+	api.HandleFunc("/title-aliases/{id:[0-9]+}", handler.deleteTitleAlias).Methods("DELETE")
+	api.HandleFunc("/genre-mappings", handler.getGenreMappings).Methods("GET")
+	api.HandleFunc("/genre-mappings", handler.addGenreMapping).Methods("POST")
+	api.HandleFunc("/genre-mappings/{id:[0-9]+}", handler.deleteGenreMapping).Methods("DELETE")
+	api.HandleFunc("/profiles", handler.getProfiles).Methods("GET")
+	api.HandleFunc("/stats/overview", handler.getOverview).Methods("GET")
+	api.HandleFunc("/stats/daily", handler.getDailyServiceStats).Methods("GET")
+	api.HandleFunc("/stats/top-titles", handler.getTopTitles).Methods("GET")
+	api.HandleFunc("/stats/devices", handler.getDeviceStats).Methods("GET")
+	api.HandleFunc("/stats/genres", handler.getGenreStats).Methods("GET")
+	api.HandleFunc("/stats/distribution", handler.getDistribution).Methods("GET")
+	api.HandleFunc("/stats/originals", handler.getOriginalsStats).Methods("GET")
+	api.HandleFunc("/stats/media-kinds", handler.getMediaKindStats).Methods("GET")
+	api.HandleFunc("/stats/ratings", handler.getRatingStats).Methods("GET")
+	api.HandleFunc("/stats/maturity", handler.getMaturityStats).Methods("GET")
+	api.HandleFunc("/stats/screen-time", handler.getScreenTime).Methods("GET")
+	api.HandleFunc("/stats/today", handler.getTodayStats).Methods("GET")
+	api.HandleFunc("/gaming/sessions", handler.getGamingSessions).Methods("GET")
+	api.HandleFunc("/gaming/sessions", handler.addGamingSession).Methods("POST")
+	api.HandleFunc("/gaming/sessions/{id:[0-9]+}", handler.deleteGamingSession).Methods("DELETE")
+	api.HandleFunc("/gaming/steam/sync", handler.syncSteam).Methods("POST")
+	api.HandleFunc("/sync/trakt", handler.syncTrakt).Methods("POST")
+	api.HandleFunc("/sync/trakt/auth", handler.authorizeTrakt).Methods("POST")
+	api.HandleFunc("/badges/hours.svg", handler.getHoursBadge).Methods("GET")
+	api.HandleFunc("/debug/timeline", handler.getDebugTimeline).Methods("GET")
+	api.HandleFunc("/insights/gaps", handler.getHistoryGaps).Methods("GET")
+	api.HandleFunc("/insights/footprint", handler.getFootprint).Methods("GET")
+	api.HandleFunc("/insights/trending", handler.getTrendingComparison).Methods("GET")
+	api.HandleFunc("/insights/title-variants", handler.getTitleVariants).Methods("GET")
+	api.HandleFunc("/insights/latency", handler.getEpisodeLatency).Methods("GET")
+	api.HandleFunc("/upcoming", handler.getUpcomingEpisodes).Methods("GET")
+	api.HandleFunc("/leaving-soon", handler.getLeavingSoon).Methods("GET")
+	api.HandleFunc("/notifications", handler.getNotifications).Methods("GET")
+	api.HandleFunc("/notifications/muted", handler.getMutedShows).Methods("GET")
+	api.HandleFunc("/notifications/muted", handler.setShowMuted).Methods("PUT")
+	api.HandleFunc("/recommendations", handler.getRecommendations).Methods("GET")
+	api.HandleFunc("/goals", handler.getGoals).Methods("GET")
+	api.HandleFunc("/goals/screen-free-days", handler.getScreenFreeDays).Methods("GET")
+	api.HandleFunc("/goals/screen-free-days", handler.addScreenFreeDay).Methods("POST")
+	api.HandleFunc("/goals/screen-free-days/sync", handler.syncScreenFreeDays).Methods("POST")
+	api.HandleFunc("/goals/screen-free-days/{date}", handler.deleteScreenFreeDay).Methods("DELETE")
+	api.HandleFunc("/changes", handler.getChanges).Methods("GET")
+	api.HandleFunc("/history/bulk", handler.bulkEditHistory).Methods("PATCH")
+	api.HandleFunc("/history/search", handler.searchHistory).Methods("GET")
+	api.HandleFunc("/history/{id:[0-9]+}/notes", handler.updateHistoryNotes).Methods("PUT")
+	api.HandleFunc("/history/{id:[0-9]+}/rating", handler.updateHistoryRating).Methods("PUT")
+	api.HandleFunc("/views", handler.getSavedViews).Methods("GET")
+	api.HandleFunc("/views", handler.createSavedView).Methods("POST")
+	api.HandleFunc("/views/{id}", handler.getSavedView).Methods("GET")
+	api.HandleFunc("/views/{id}", handler.updateSavedView).Methods("PUT")
+	api.HandleFunc("/views/{id}", handler.deleteSavedView).Methods("DELETE")
+	api.HandleFunc("/views/{id}/data", handler.getSavedViewData).Methods("GET")
+	api.HandleFunc("/query", handler.postQuery).Methods("POST")
+	api.HandleFunc("/voice/summary", handler.requireVoiceToken(handler.getVoiceSummary)).Methods("GET", "POST")
+	api.HandleFunc("/widget", handler.requireWidgetToken(handler.getWidgetSummary)).Methods("GET")
+	api.HandleFunc("/federation/summary", handler.requireFederationToken(handler.getFederationSummary)).Methods("GET")
+	api.HandleFunc("/household", handler.getHousehold).Methods("GET")
+	api.HandleFunc("/grafana", handler.grafanaHealth).Methods("GET")
+	api.HandleFunc("/grafana/search", handler.grafanaSearch).Methods("POST")
+	api.HandleFunc("/grafana/query", handler.grafanaQuery).Methods("POST")
 
 	// Configure CORS
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:5173"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization"},
 		AllowCredentials: true,
 	})
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/importer"
+)
+
+// importScreenTime imports Apple Screen Time app usage, posted as JSON by a
+// Shortcuts automation or uploaded as a CSV export, as estimated daily
+// entries for the apps' services. With ?dry_run=true nothing is written.
+// When screen_time.token is set it must be sent as a bearer token or ?token=.
+func (h *Handler) importScreenTime(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if expected := h.config.ScreenTime.Token; expected != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = query.Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respondError(w, http.StatusUnauthorized, "Invalid token", fmt.Errorf("missing or invalid screen time token"))
+			return
+		}
+	}
+
+	data, _, err := readImportFile(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to read upload", err)
+		return
+	}
+
+	usage, rowErrors, err := importer.ParseScreenTime(bytes.NewReader(data))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to parse Screen Time data", err)
+		return
+	}
+
+	opts := importer.Options{
+		DryRun:      query.Get("dry_run") == "true",
+		PreviewRows: parseIntParam(query.Get("preview_rows"), 20),
+	}
+	summary, err := importer.ApplyScreenTime(h.db, usage, h.config.ScreenTime.Apps, opts)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import Screen Time data", err)
+		return
+	}
+	if rowErrors != nil {
+		summary.Errors = rowErrors
+	}
+
+	respondJSON(w, http.StatusOK, summary)
+}
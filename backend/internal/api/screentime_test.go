@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/importer"
+)
+
+func TestImportScreenTime(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	body := `{"date": "2025-01-14", "apps": [{"app": "Hulu", "minutes": 30}, {"app": "Clock", "minutes": 5}]}`
+	req, _ := http.NewRequest("POST", "/api/screen-time", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.importScreenTime(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var summary importer.UsageSummary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Imported != 1 || len(summary.Unmapped) != 1 {
+		t.Errorf("Expected 1 imported and 1 unmapped app, got %+v", summary)
+	}
+}
+
+func TestImportScreenTimeRequiresConfiguredToken(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.ScreenTime.Token = "secret"
+
+	req, _ := http.NewRequest("POST", "/api/screen-time", strings.NewReader(`{"date": "2025-01-14", "apps": []}`))
+	rr := httptest.NewRecorder()
+	handler.importScreenTime(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/screen-time?token=secret", strings.NewReader(`{"date": "2025-01-14", "apps": []}`))
+	rr = httptest.NewRecorder()
+	handler.importScreenTime(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/insights"
+)
+
+// getDeviceStats returns watch time broken down by device
+func (h *Handler) getDeviceStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	startDate, endDate, err := parseYearMonthRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	var serviceID int64
+	if serviceIDStr := query.Get("service_id"); serviceIDStr != "" {
+		serviceID, err = strconv.ParseInt(serviceIDStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid service_id parameter", err)
+			return
+		}
+	}
+
+	stats, err := db.GetDeviceStats(serviceID, startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch device stats", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+// getDailyServiceStats returns per-day watch time stacked by service, for
+// ?start=&end= (inclusive YYYY-MM-DD, default the last 30 days), so a stacked
+// chart needs one request rather than one per service
+func (h *Handler) getDailyServiceStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	startDate, endDate, err := parseDateRange(r.URL.Query(), "start", "end", tomorrow.AddDate(0, 0, -30), tomorrow)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	stats, err := db.GetDailyServiceStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch daily stats", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"days":       stats,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+	})
+}
+
+// getTopTitles returns the most watched titles with their total minutes and
+// episode (entry) counts for ?start=&end= (inclusive YYYY-MM-DD, default
+// all-time). ?group_by=service lists each title once per service it was
+// watched on rather than combined across services.
+func (h *Handler) getTopTitles(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+	allTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	startDate, endDate, err := parseDateRange(query, "start", "end", allTime, time.Now().AddDate(1, 0, 0))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+	limit := parseIntParam(query.Get("limit"), 20)
+	if limit <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid limit parameter", fmt.Errorf("limit must be positive"))
+		return
+	}
+
+	var titles interface{}
+	switch groupBy := query.Get("group_by"); groupBy {
+	case "", "title":
+		titles, err = db.GetTopTitles(startDate, endDate, limit)
+	case "service":
+		titles, err = db.GetTopServiceTitles(startDate, endDate, limit)
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid group_by parameter", fmt.Errorf("group_by must be title or service, got %q", groupBy))
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch top titles", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"titles":     titles,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+	})
+}
+
+// getMediaKindStats compares listening time (audiobooks) with streaming time
+func (h *Handler) getMediaKindStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	startDate, endDate, err := parseYearMonthRange(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	stats, err := db.GetMediaKindStats(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch media kind stats", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+// getDistribution returns histograms and percentiles of daily totals and
+// viewing session lengths (?gap_minutes=30 controls how sessions are split)
+func (h *Handler) getDistribution(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	startDate, endDate, err := parseYearMonthRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	gapMinutes := parseIntParam(query.Get("gap_minutes"), 30)
+	if gapMinutes < 0 {
+		respondError(w, http.StatusBadRequest, "Invalid gap_minutes parameter", fmt.Errorf("gap_minutes must not be negative"))
+		return
+	}
+
+	history, err := db.GetWatchEntries(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch watch history", err)
+		return
+	}
+
+	entries := make([]insights.TimedEntry, len(history))
+	for i, wh := range history {
+		entries[i] = insights.TimedEntry{Start: wh.WatchedAt, Minutes: wh.DurationMinutes}
+	}
+
+	response := map[string]interface{}{
+		"daily":    insights.Distribute(insights.DailyTotals(entries), insights.DailyBuckets),
+		"sessions": insights.Distribute(insights.SessionLengths(entries, time.Duration(gapMinutes)*time.Minute), insights.SessionBuckets),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// getRatingStats returns average personal ratings per service and genre, and
+// the best rated titles for the period
+func (h *Handler) getRatingStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	startDate, endDate, err := parseYearMonthRange(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid date parameters", err)
+		return
+	}
+
+	limit := parseIntParam(query.Get("limit"), 10)
+	if limit <= 0 {
+		limit = 10
+	}
+
+	byService, err := db.GetRatingStatsByService(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch service ratings", err)
+		return
+	}
+
+	byGenre, err := db.GetRatingStatsByGenre(startDate, endDate)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch genre ratings", err)
+		return
+	}
+
+	best, err := db.GetTopRatedTitles(startDate, endDate, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch top rated titles", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"services":   byService,
+		"genres":     byGenre,
+		"best":       best,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+	})
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+)
+
+func TestGetDeviceStats(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+
+	now := time.Now()
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Show A", DurationMinutes: 70, WatchedAt: now, Device: "Living Room TV"})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Show B", DurationMinutes: 30, WatchedAt: now.Add(-time.Hour)})
+
+	req, err := http.NewRequest("GET", "/api/stats/devices", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getDeviceStats(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var stats []database.DeviceStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 device rows, got %d", len(stats))
+	}
+	if stats[0].Device != "Living Room TV" || stats[0].Percentage != 70 {
+		t.Errorf("Expected Living Room TV at 70%%, got %+v", stats[0])
+	}
+	if stats[1].Device != "Unknown" {
+		t.Errorf("Expected rows without device grouped as Unknown, got %q", stats[1].Device)
+	}
+}
+
+func TestGetDeviceStatsInvalidYear(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("GET", "/api/stats/devices?year=1900", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getDeviceStats(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestGetDailyServiceStats(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hulu, _ := db.GetServiceByName("Hulu")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.UpdateServiceEnabled(hulu.ID, true)
+
+	march1 := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: march1},
+		{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 45, WatchedAt: march1.Add(time.Hour)},
+		{ServiceID: hulu.ID, Title: "The Bear", DurationMinutes: 30, WatchedAt: march1},
+		{ServiceID: hulu.ID, Title: "The Bear", DurationMinutes: 30, WatchedAt: march1.AddDate(0, 0, 1)},
+		{ServiceID: hulu.ID, Title: "Shogun", DurationMinutes: 60, WatchedAt: march1.AddDate(0, 0, 2)},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/stats/daily?start=2025-03-01&end=2025-03-02", nil)
+	rr := httptest.NewRecorder()
+	handler.getDailyServiceStats(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Days []database.DailyServiceStats `json:"days"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Days) != 3 {
+		t.Fatalf("Expected Netflix and Hulu on March 1 and Hulu on March 2, got %+v", response.Days)
+	}
+	for _, day := range response.Days {
+		if day.Date == "2025-03-01" && day.ServiceID == netflix.ID && day.Minutes != 95 {
+			t.Errorf("Expected 95 Netflix minutes on March 1, got %d", day.Minutes)
+		}
+	}
+	if last := response.Days[2]; last.Date != "2025-03-02" || last.ServiceID != hulu.ID || last.Minutes != 30 {
+		t.Errorf("Expected March 2 to hold only Hulu's 30 minutes, got %+v", last)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/stats/daily?start=2025-03-02&end=2025-03-01", nil)
+	rr = httptest.NewRecorder()
+	handler.getDailyServiceStats(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for a reversed range, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestGetTopTitles(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hulu, _ := db.GetServiceByName("Hulu")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.UpdateServiceEnabled(hulu.ID, true)
+
+	day := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	for i, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "The Office", DurationMinutes: 22},
+		{ServiceID: netflix.ID, Title: "The Office", DurationMinutes: 22},
+		{ServiceID: hulu.ID, Title: "The Office", DurationMinutes: 22},
+		{ServiceID: hulu.ID, Title: "Shogun", DurationMinutes: 60},
+		{ServiceID: hulu.ID, Title: "The Bear", DurationMinutes: 30},
+	} {
+		wh.WatchedAt = day.Add(time.Duration(i) * time.Hour)
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/stats/top-titles?start=2025-03-01&end=2025-03-01&limit=2", nil)
+	rr := httptest.NewRecorder()
+	handler.getTopTitles(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var combined struct {
+		Titles []database.TitleStats `json:"titles"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&combined); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(combined.Titles) != 2 || combined.Titles[0].Title != "The Office" || combined.Titles[0].WatchCount != 3 {
+		t.Errorf("Expected The Office's 3 episodes across services first, got %+v", combined.Titles)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/stats/top-titles?group_by=service", nil)
+	rr = httptest.NewRecorder()
+	handler.getTopTitles(rr, req)
+	var byService struct {
+		Titles []database.ServiceTitleStats `json:"titles"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&byService); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(byService.Titles) != 4 || byService.Titles[0].Title != "Shogun" {
+		t.Errorf("Expected The Office split by service behind Shogun, got %+v", byService.Titles)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/stats/top-titles?group_by=genre", nil)
+	rr = httptest.NewRecorder()
+	handler.getTopTitles(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an unknown grouping, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestGetMediaKindStats(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Audible")
+	db.UpdateServiceEnabled(service.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Audiobook", DurationMinutes: 300, WatchedAt: time.Now(), MediaKind: database.MediaKindAudio})
+
+	req, err := http.NewRequest("GET", "/api/stats/media-kinds", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getMediaKindStats(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var stats []database.MediaKindStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(stats) != 1 || stats[0].MediaKind != "audio" || stats[0].TotalMinutes != 300 {
+		t.Errorf("Expected 300 minutes of audio, got %+v", stats)
+	}
+}
+
+func TestGetDistribution(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	evening := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Show", EpisodeInfo: "E1", DurationMinutes: 45, WatchedAt: evening})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Show", EpisodeInfo: "E2", DurationMinutes: 45, WatchedAt: evening.Add(50 * time.Minute)})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Film", DurationMinutes: 120, WatchedAt: evening.AddDate(0, 0, 1)})
+
+	req, err := http.NewRequest("GET", "/api/stats/distribution?year=2025", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getDistribution(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Daily    insights.Distribution `json:"daily"`
+		Sessions insights.Distribution `json:"sessions"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Daily.Count != 2 || response.Daily.Median != 105 {
+		t.Errorf("Unexpected daily distribution: %+v", response.Daily)
+	}
+	if response.Sessions.Count != 2 || response.Sessions.P90 != 117 {
+		t.Errorf("Unexpected session distribution: %+v", response.Sessions)
+	}
+}
+
+func TestGetRatingStats(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	wh := &database.WatchHistory{ServiceID: service.ID, Title: "Severance", Genre: "Drama", DurationMinutes: 50, WatchedAt: time.Now()}
+	db.InsertWatchHistory(wh)
+	db.UpdateWatchHistoryRating(wh.ID, 4)
+
+	req, err := http.NewRequest("GET", "/api/stats/ratings", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.getRatingStats(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var resp struct {
+		Services []database.RatingStats `json:"services"`
+		Genres   []database.RatingStats `json:"genres"`
+		Best     []database.TitleRating `json:"best"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Services) != 1 || resp.Services[0].AverageRating != 4 {
+		t.Errorf("Unexpected service ratings: %+v", resp.Services)
+	}
+	if len(resp.Genres) != 1 || resp.Genres[0].Name != "Drama" {
+		t.Errorf("Unexpected genre ratings: %+v", resp.Genres)
+	}
+	if len(resp.Best) != 1 || resp.Best[0].Title != "Severance" {
+		t.Errorf("Unexpected best titles: %+v", resp.Best)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// getTitleAliases returns all title alias mappings
+func (h *Handler) getTitleAliases(w http.ResponseWriter, r *http.Request) {
+	aliases, err := h.db.GetTitleAliases()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch title aliases", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, aliases)
+}
+
+// addTitleAlias maps a variant title to a canonical one. New history is stored
+// under the canonical title, and existing rows are renamed right away.
+func (h *Handler) addTitleAlias(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Alias     string `json:"alias"`
+		Canonical string `json:"canonical"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	req.Alias = strings.TrimSpace(req.Alias)
+	req.Canonical = strings.TrimSpace(req.Canonical)
+	if req.Alias == "" || req.Canonical == "" {
+		respondError(w, http.StatusBadRequest, "Invalid title alias", fmt.Errorf("alias and canonical are required"))
+		return
+	}
+	if strings.EqualFold(req.Alias, req.Canonical) {
+		respondError(w, http.StatusBadRequest, "Invalid title alias", fmt.Errorf("alias and canonical must differ"))
+		return
+	}
+
+	ta := &database.TitleAlias{
+		Alias:     req.Alias,
+		Canonical: req.Canonical,
+	}
+	if err := h.db.InsertTitleAlias(ta); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to add title alias", err)
+		return
+	}
+
+	renamed, removed, err := h.db.ApplyTitleAliases()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to apply title aliases", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"alias":   ta,
+		"renamed": renamed,
+		"removed": removed,
+	})
+}
+
+// deleteTitleAlias removes a title alias. History already renamed keeps the
+// canonical title.
+func (h *Handler) deleteTitleAlias(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid title alias ID", err)
+		return
+	}
+
+	deleted, err := h.db.DeleteTitleAlias(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete title alias", err)
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Title alias not found", fmt.Errorf("title alias with ID %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deleted": id,
+	})
+}
+
+// applyTitleAliases renames existing history to canonical titles, dropping rows
+// that duplicate one already stored under the canonical title
+func (h *Handler) applyTitleAliases(w http.ResponseWriter, r *http.Request) {
+	renamed, removed, err := h.db.ApplyTitleAliases()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to apply title aliases", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"renamed": renamed,
+		"removed": removed,
+	})
+}
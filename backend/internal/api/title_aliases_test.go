@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestAddTitleAliasRenamesHistory(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "The Office (U.S.)", DurationMinutes: 22, WatchedAt: time.Now()})
+
+	req, err := http.NewRequest("POST", "/api/title-aliases", strings.NewReader(`{"alias": "The Office (U.S.)", "canonical": "The Office"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.addTitleAlias(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, rr.Body.String())
+	}
+
+	var resp struct {
+		Alias   database.TitleAlias `json:"alias"`
+		Renamed int64               `json:"renamed"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Alias.ID == 0 || resp.Alias.Canonical != "The Office" {
+		t.Errorf("Unexpected alias: %+v", resp.Alias)
+	}
+	if resp.Renamed != 1 {
+		t.Errorf("Expected 1 renamed row, got %d", resp.Renamed)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/title-aliases/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr = httptest.NewRecorder()
+	handler.deleteTitleAlias(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+}
+
+func TestAddTitleAliasValidation(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	for _, body := range []string{
+		`{"alias": "", "canonical": "The Office"}`,
+		`{"alias": "the office", "canonical": "The Office"}`,
+	} {
+		req, _ := http.NewRequest("POST", "/api/title-aliases", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.addTitleAlias(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, body, status)
+		}
+	}
+}
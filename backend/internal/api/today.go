@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// todayStats is the response of GET /api/stats/today
+type todayStats struct {
+	Date         string         `json:"date"`
+	TotalMinutes int            `json:"total_minutes"`
+	Services     []todayService `json:"services"`
+	AsOf         time.Time      `json:"as_of"`
+}
+
+// todayService is one service's watch time so far today
+type todayService struct {
+	ServiceID   int64  `json:"service_id"`
+	ServiceName string `json:"service_name"`
+	Color       string `json:"color"`
+	Minutes     int    `json:"minutes"`
+}
+
+// getTodayStats returns minutes watched so far today (UTC, like other daily
+// stats) per service. It is meant to be polled every minute by widgets and
+// smart displays, so it reads the daily totals kept as history is stored
+// rather than the history itself.
+func (h *Handler) getTodayStats(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	stats, err := loadTodayStats(db, time.Now())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch today's stats", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+// loadTodayStats returns the totals per service for the UTC day of now
+func loadTodayStats(db *database.DB, now time.Time) (*todayStats, error) {
+	now = now.UTC()
+	stats := &todayStats{Date: now.Format("2006-01-02"), Services: []todayService{}, AsOf: now}
+	totals, err := db.GetDayServiceTotals(stats.Date)
+	if err != nil {
+		return nil, err
+	}
+	for _, total := range totals {
+		stats.TotalMinutes += total.TotalMinutes
+		stats.Services = append(stats.Services, todayService{
+			ServiceID:   total.ServiceID,
+			ServiceName: total.ServiceName,
+			Color:       total.Color,
+			Minutes:     total.TotalMinutes,
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetTodayStats(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	now := time.Now().UTC()
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Today Show", DurationMinutes: 45, WatchedAt: now})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Yesterday Show", DurationMinutes: 30, WatchedAt: now.AddDate(0, 0, -1)})
+
+	get := func() todayStats {
+		t.Helper()
+		req, _ := http.NewRequest("GET", "/api/stats/today", nil)
+		rr := httptest.NewRecorder()
+		handler.getTodayStats(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var stats todayStats
+		if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return stats
+	}
+
+	stats := get()
+	if stats.Date != now.Format("2006-01-02") || stats.TotalMinutes != 45 {
+		t.Errorf("Expected 45 minutes today, got %+v", stats)
+	}
+	if len(stats.Services) != 1 || stats.Services[0].ServiceName != "Netflix" || stats.Services[0].Minutes != 45 {
+		t.Errorf("Expected only Netflix with 45 minutes, got %+v", stats.Services)
+	}
+
+	// New history shows up in the daily totals right away
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Later Show", DurationMinutes: 20, WatchedAt: now})
+	if stats := get(); stats.TotalMinutes != 65 {
+		t.Errorf("Expected 65 minutes after another show, got %d", stats.TotalMinutes)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/jgoulah/streamtime/internal/integrations/trakt"
+)
+
+// errTraktNotConfigured is reported by the Trakt endpoints without an API app in the config
+var errTraktNotConfigured = fmt.Errorf("trakt.client_id and trakt.client_secret are required")
+
+// authorizeTrakt starts authorizing a Trakt account and returns the code to
+// enter at the verification URL. The token is stored in the background once
+// the code is entered.
+func (h *Handler) authorizeTrakt(w http.ResponseWriter, r *http.Request) {
+	if h.trakt == nil {
+		respondError(w, http.StatusBadRequest, "Trakt not configured", errTraktNotConfigured)
+		return
+	}
+
+	code, err := h.trakt.RequestAuth(r.Context())
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to start Trakt authorization", err)
+		return
+	}
+
+	go func() {
+		if err := h.trakt.WaitForAuth(context.Background(), code); err != nil {
+			log.Printf("Trakt: authorization failed: %v", err)
+			return
+		}
+		log.Printf("Trakt: account authorized")
+	}()
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"user_code":        code.UserCode,
+		"verification_url": code.VerificationURL,
+		"expires_in":       code.ExpiresIn,
+	})
+}
+
+// syncTrakt pushes new history to the authorized Trakt account and pulls
+// its plays in, as configured
+func (h *Handler) syncTrakt(w http.ResponseWriter, r *http.Request) {
+	if h.trakt == nil {
+		respondError(w, http.StatusBadRequest, "Trakt not configured", errTraktNotConfigured)
+		return
+	}
+
+	result, err := h.trakt.Sync(r.Context())
+	if errors.Is(err, trakt.ErrNotAuthorized) {
+		respondError(w, http.StatusConflict, "Trakt not authorized; authorize with POST /api/sync/trakt/auth", err)
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Trakt sync failed", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+func TestSyncTraktNotConfigured(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, err := http.NewRequest("POST", "/api/sync/trakt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.syncTrakt(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestSyncTraktNotAuthorized(t *testing.T) {
+	_, db := setupTestAPI(t)
+	defer db.Close()
+
+	cfg := &config.Config{Trakt: config.TraktConfig{ClientID: "id", ClientSecret: "secret", Push: true}}
+	handler := NewHandler(db, nil, cfg)
+
+	req, err := http.NewRequest("POST", "/api/sync/trakt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.syncTrakt(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+}
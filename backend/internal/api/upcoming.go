@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// upcomingActiveDays is how recently a show must have had a new episode
+// watched to count as in progress, unless ?active_days= says otherwise
+const upcomingActiveDays = 60
+
+// upcomingEpisode is the next scheduled episode of a show in progress
+type upcomingEpisode struct {
+	Title     string `json:"title"`
+	TMDBID    int64  `json:"tmdb_id"`
+	Season    int    `json:"season"`
+	Episode   int    `json:"episode"`
+	Name      string `json:"name,omitempty"`
+	AirDate   string `json:"air_date"` // YYYY-MM-DD
+	DaysUntil int    `json:"days_until"`
+}
+
+// getUpcomingEpisodes lists the next air date of each show in progress, those
+// with an episode first watched in the last active_days, soonest first. With
+// ?format=ical it returns the same episodes as an iCalendar feed of all-day
+// events that calendar apps can subscribe to. Shows are looked up on TMDB
+// like the latency insights, sharing their cache.
+func (h *Handler) getUpcomingEpisodes(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	if h.tmdb == nil {
+		respondError(w, http.StatusServiceUnavailable, "TMDB not configured", fmt.Errorf("tmdb.api_key is required"))
+		return
+	}
+
+	query := r.URL.Query()
+	activeDays := parseIntParam(query.Get("active_days"), upcomingActiveDays)
+	if activeDays <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid active_days parameter", fmt.Errorf("active_days must be positive"))
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "ical" {
+		respondError(w, http.StatusBadRequest, "Invalid format parameter", fmt.Errorf("format must be json or ical"))
+		return
+	}
+	lookupLimit := parseIntParam(query.Get("lookup_limit"), 25)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	watched, err := db.GetWatchedEpisodes(now.AddDate(0, 0, -activeDays), now.Add(time.Minute))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch watched episodes", err)
+		return
+	}
+
+	upcoming := []upcomingEpisode{}
+	lookups := &showLookups{limit: lookupLimit}
+	for _, title := range groupEpisodesByTitle(watched) {
+		show, _, err := h.showAirDates(r, title.Title, title.Watched, lookups)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch air dates", err)
+			return
+		}
+		if show == nil || show.NextAirDate == "" {
+			continue
+		}
+		airDate, err := time.Parse("2006-01-02", show.NextAirDate)
+		if err != nil || airDate.Before(today) {
+			continue // Aired since the show was last looked up
+		}
+		upcoming = append(upcoming, upcomingEpisode{
+			Title:     title.Title,
+			TMDBID:    show.TMDBID,
+			Season:    show.NextSeason,
+			Episode:   show.NextEpisode,
+			Name:      show.NextName,
+			AirDate:   show.NextAirDate,
+			DaysUntil: int(airDate.Sub(today).Hours() / 24),
+		})
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].AirDate < upcoming[j].AirDate
+	})
+
+	if format == "ical" {
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="upcoming.ics"`)
+		w.Write([]byte(upcomingCalendar(upcoming, now)))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"episodes":        upcoming,
+		"active_days":     activeDays,
+		"pending_lookups": lookups.pending,
+	})
+}
+
+// icalEscaper escapes text values in iCalendar properties
+var icalEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\n", `\n`)
+
+// upcomingCalendar renders upcoming episodes as an iCalendar feed with one
+// all-day event per episode
+func upcomingCalendar(episodes []upcomingEpisode, now time.Time) string {
+	var b strings.Builder
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//streamtime//Upcoming episodes//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Upcoming episodes",
+	}
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, ep := range episodes {
+		airDate, _ := time.Parse("2006-01-02", ep.AirDate)
+		summary := fmt.Sprintf("%s S%02dE%02d", ep.Title, ep.Season, ep.Episode)
+		if ep.Name != "" {
+			summary += " - " + ep.Name
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:tmdb-%d-s%de%d@streamtime", ep.TMDBID, ep.Season, ep.Episode),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+airDate.Format("20060102"),
+			"DTEND;VALUE=DATE:"+airDate.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+icalEscaper.Replace(summary),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		b.WriteString(foldICalLine(line))
+	}
+	return b.String()
+}
+
+// foldICalLine ends a content line with CRLF, folding it onto continuation
+// lines so none exceeds 75 octets, without splitting UTF-8 characters
+func foldICalLine(line string) string {
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestGetUpcomingEpisodes(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	now := time.Now()
+	nextWeek := now.AddDate(0, 0, 7).Format("2006-01-02")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/multi":
+			switch r.URL.Query().Get("query") {
+			case "Andor":
+				w.Write([]byte(`{"results": [{"id": 1, "media_type": "tv", "name": "Andor"}]}`))
+			case "Chernobyl":
+				w.Write([]byte(`{"results": [{"id": 2, "media_type": "tv", "name": "Chernobyl"}]}`))
+			default:
+				w.Write([]byte(`{"results": []}`))
+			}
+		case "/tv/1":
+			w.Write([]byte(`{"next_episode_to_air": {"season_number": 2, "episode_number": 4, "name": "Ever Been to Ghorman?", "air_date": "` + nextWeek + `"}}`))
+		case "/tv/2":
+			w.Write([]byte(`{"last_episode_to_air": {"season_number": 1, "episode_number": 5, "air_date": "2019-06-03"}, "next_episode_to_air": null}`))
+		case "/tv/1/season/2", "/tv/2/season/1":
+			w.Write([]byte(`{"episodes": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	handler.tmdb = tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "Andor", EpisodeInfo: "S02E03", DurationMinutes: 50, WatchedAt: now.AddDate(0, 0, -2)},
+		{ServiceID: netflix.ID, Title: "Chernobyl", EpisodeInfo: "S01E05", DurationMinutes: 70, WatchedAt: now.AddDate(0, 0, -3)},
+		{ServiceID: netflix.ID, Title: "Lost", EpisodeInfo: "S01E01", DurationMinutes: 45, WatchedAt: now.AddDate(-1, 0, 0)},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/api/upcoming", nil)
+	rr := httptest.NewRecorder()
+	handler.getUpcomingEpisodes(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	// Chernobyl has ended and Lost isn't in progress
+	var response struct {
+		Episodes []upcomingEpisode `json:"episodes"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Episodes) != 1 {
+		t.Fatalf("Expected only Andor's next episode, got %+v", response.Episodes)
+	}
+	if ep := response.Episodes[0]; ep.Title != "Andor" || ep.Episode != 4 || ep.AirDate != nextWeek || ep.DaysUntil != 7 {
+		t.Errorf("Unexpected upcoming episode: %+v", ep)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/upcoming?format=ical", nil)
+	rr = httptest.NewRecorder()
+	handler.getUpcomingEpisodes(rr, req)
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Expected a calendar, got %s", ct)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART;VALUE=DATE:" + strings.ReplaceAll(nextWeek, "-", "") + "\r\n",
+		`SUMMARY:Andor S02E04 - Ever Been to Ghorman?`,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 60)
+	folded := foldICalLine(line)
+	for _, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("Line exceeds 75 octets: %q", part)
+		}
+	}
+	if unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""); unfolded != line {
+		t.Errorf("Expected folding to be reversible, got %q", unfolded)
+	}
+}
@@ -0,0 +1,322 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/units"
+)
+
+// Allowed saved view settings
+var (
+	viewDateRanges  = []string{"this_week", "this_month", "last_30_days", "this_year", "all_time", "custom"}
+	viewGranularity = []string{"day", "week", "month"}
+	viewChartTypes  = []string{"line", "bar", "pie", "table"}
+)
+
+// viewPoint is watch time for one period of a view's series
+type viewPoint struct {
+	Period  string `json:"period"` // Start date of the day, week or month
+	Minutes int    `json:"minutes"`
+}
+
+// viewSeries is one service's data in a rendered view
+type viewSeries struct {
+	ServiceID    int64       `json:"service_id"`
+	ServiceName  string      `json:"service_name"`
+	Color        string      `json:"color"`
+	TotalMinutes int         `json:"total_minutes"`
+	Points       []viewPoint `json:"points"`
+}
+
+// getSavedViews lists saved views for the frontend's dashboard picker
+func (h *Handler) getSavedViews(w http.ResponseWriter, r *http.Request) {
+	views, err := h.db.GetSavedViews()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch saved views", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, views)
+}
+
+// getSavedView returns a single saved view
+func (h *Handler) getSavedView(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.loadSavedView(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, view)
+}
+
+// createSavedView stores a new saved view
+func (h *Handler) createSavedView(w http.ResponseWriter, r *http.Request) {
+	var view database.SavedView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if err := validateSavedView(&view); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid saved view", err)
+		return
+	}
+
+	if err := h.db.InsertSavedView(&view); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			respondError(w, http.StatusConflict, "Saved view already exists", err)
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to save view", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, view)
+}
+
+// updateSavedView replaces a saved view's configuration
+func (h *Handler) updateSavedView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid view ID", err)
+		return
+	}
+
+	var view database.SavedView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if err := validateSavedView(&view); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid saved view", err)
+		return
+	}
+	view.ID = id
+
+	updated, err := h.db.UpdateSavedView(&view)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			respondError(w, http.StatusConflict, "Saved view already exists", err)
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to update view", err)
+		return
+	}
+	if !updated {
+		respondError(w, http.StatusNotFound, "Saved view not found", fmt.Errorf("saved view with ID %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, view)
+}
+
+// deleteSavedView removes a saved view
+func (h *Handler) deleteSavedView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid view ID", err)
+		return
+	}
+
+	deleted, err := h.db.DeleteSavedView(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete view", err)
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Saved view not found", fmt.Errorf("saved view with ID %d not found", id))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deleted": id,
+	})
+}
+
+// getSavedViewData resolves a saved view into chart-ready series, one per
+// service, bucketed by the view's granularity
+func (h *Handler) getSavedViewData(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.loadSavedView(w, r)
+	if !ok {
+		return
+	}
+
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	startDate, endDate := resolveViewRange(view, time.Now().UTC(), h.config.Display.FirstDayOfWeek())
+
+	services, err := db.GetAllServices()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch services", err)
+		return
+	}
+	selected := make(map[int64]bool)
+	for _, id := range view.ServiceIDs {
+		selected[id] = true
+	}
+
+	series := []viewSeries{}
+	for _, svc := range services {
+		if len(selected) > 0 && !selected[svc.ID] || len(selected) == 0 && !svc.Enabled {
+			continue
+		}
+
+		daily, err := db.GetDailyStats(svc.ID, startDate, endDate)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch daily stats", err)
+			return
+		}
+
+		s := viewSeries{ServiceID: svc.ID, ServiceName: svc.Name, Color: svc.Color, Points: []viewPoint{}}
+		buckets := make(map[string]int)
+		for day, minutes := range daily {
+			date, err := time.Parse("2006-01-02", day)
+			if err != nil {
+				continue
+			}
+			buckets[periodStart(date, view.Granularity, h.config.Display.FirstDayOfWeek())] += minutes
+			s.TotalMinutes += minutes
+		}
+		for period, minutes := range buckets {
+			s.Points = append(s.Points, viewPoint{Period: period, Minutes: minutes})
+		}
+		sort.Slice(s.Points, func(i, j int) bool { return s.Points[i].Period < s.Points[j].Period })
+
+		series = append(series, s)
+	}
+
+	response := map[string]interface{}{
+		"view":       view,
+		"series":     series,
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// loadSavedView fetches the view named by the {id} path variable, writing an
+// error response and returning false if it can't
+func (h *Handler) loadSavedView(w http.ResponseWriter, r *http.Request) (*database.SavedView, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid view ID", err)
+		return nil, false
+	}
+
+	view, err := h.db.GetSavedView(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch saved view", err)
+		return nil, false
+	}
+	if view == nil {
+		respondError(w, http.StatusNotFound, "Saved view not found", fmt.Errorf("saved view with ID %d not found", id))
+		return nil, false
+	}
+	return view, true
+}
+
+// validateSavedView checks a view's settings, filling in defaults
+func validateSavedView(view *database.SavedView) error {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if view.DateRange == "" {
+		view.DateRange = "this_month"
+	}
+	if view.Granularity == "" {
+		view.Granularity = "day"
+	}
+	if view.ChartType == "" {
+		view.ChartType = "bar"
+	}
+
+	if !contains(viewDateRanges, view.DateRange) {
+		return fmt.Errorf("date_range must be one of %s", strings.Join(viewDateRanges, ", "))
+	}
+	if !contains(viewGranularity, view.Granularity) {
+		return fmt.Errorf("granularity must be one of %s", strings.Join(viewGranularity, ", "))
+	}
+	if !contains(viewChartTypes, view.ChartType) {
+		return fmt.Errorf("chart_type must be one of %s", strings.Join(viewChartTypes, ", "))
+	}
+
+	if view.DateRange == "custom" {
+		start, err := time.Parse("2006-01-02", view.StartDate)
+		if err != nil {
+			return fmt.Errorf("start_date must be YYYY-MM-DD for custom ranges")
+		}
+		end, err := time.Parse("2006-01-02", view.EndDate)
+		if err != nil {
+			return fmt.Errorf("end_date must be YYYY-MM-DD for custom ranges")
+		}
+		if end.Before(start) {
+			return fmt.Errorf("end_date must not be before start_date")
+		}
+	} else {
+		view.StartDate, view.EndDate = "", ""
+	}
+
+	if view.ServiceIDs == nil {
+		view.ServiceIDs = []int64{}
+	}
+	return nil
+}
+
+// resolveViewRange turns a view's date range into [start, end) dates, with
+// weeks starting on firstDay
+func resolveViewRange(view *database.SavedView, now time.Time, firstDay time.Weekday) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	switch view.DateRange {
+	case "this_week":
+		return units.WeekStart(today, firstDay), tomorrow
+	case "last_30_days":
+		return today.AddDate(0, 0, -29), tomorrow
+	case "this_year":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC), tomorrow
+	case "all_time":
+		return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), tomorrow
+	case "custom":
+		start, _ := time.Parse("2006-01-02", view.StartDate)
+		end, _ := time.Parse("2006-01-02", view.EndDate)
+		return start, end.AddDate(0, 0, 1)
+	default: // this_month
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), tomorrow
+	}
+}
+
+// periodStart returns the first day of the day, week (starting on firstDay)
+// or month containing date
+func periodStart(date time.Time, granularity string, firstDay time.Weekday) string {
+	switch granularity {
+	case "week":
+		date = units.WeekStart(date, firstDay)
+	case "month":
+		date = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return date.Format("2006-01-02")
+}
+
+// contains reports whether values includes s
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestCreateSavedView(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	body := `{"name": "Sports only", "date_range": "last_30_days", "service_ids": [12], "granularity": "week", "chart_type": "line"}`
+	req, err := http.NewRequest("POST", "/api/views", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.createSavedView(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, rr.Body.String())
+	}
+
+	var view database.SavedView
+	if err := json.NewDecoder(rr.Body).Decode(&view); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if view.ID == 0 || view.Granularity != "week" || len(view.ServiceIDs) != 1 {
+		t.Errorf("Unexpected view: %+v", view)
+	}
+
+	// Same name again conflicts
+	req, _ = http.NewRequest("POST", "/api/views", strings.NewReader(body))
+	rr = httptest.NewRecorder()
+	handler.createSavedView(rr, req)
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, status)
+	}
+}
+
+func TestCreateSavedViewInvalid(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	tests := []string{
+		`{"name": ""}`,
+		`{"name": "Bad", "granularity": "hour"}`,
+		`{"name": "Bad", "chart_type": "radar"}`,
+		`{"name": "Bad", "date_range": "custom", "start_date": "2025-03-10", "end_date": "2025-03-01"}`,
+	}
+	for _, body := range tests {
+		req, _ := http.NewRequest("POST", "/api/views", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.createSavedView(rr, req)
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, body, status)
+		}
+	}
+}
+
+func TestGetSavedViewData(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	peacock, _ := db.GetServiceByName("Peacock")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.UpdateServiceEnabled(peacock.ID, true)
+
+	// Monday and Wednesday of the same week, then the next Monday
+	for _, day := range []string{"2025-03-03", "2025-03-05", "2025-03-10"} {
+		watchedAt, _ := time.Parse("2006-01-02", day)
+		db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Show", DurationMinutes: 30, WatchedAt: watchedAt.Add(20 * time.Hour)})
+	}
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: peacock.ID, Title: "Other", DurationMinutes: 60, WatchedAt: time.Date(2025, 3, 4, 20, 0, 0, 0, time.UTC)})
+
+	view := &database.SavedView{
+		Name:        "Netflix in March",
+		DateRange:   "custom",
+		StartDate:   "2025-03-01",
+		EndDate:     "2025-03-31",
+		ServiceIDs:  []int64{netflix.ID},
+		Granularity: "week",
+		ChartType:   "bar",
+	}
+	db.InsertSavedView(view)
+
+	req, _ := http.NewRequest("GET", "/api/views/1/data", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+	handler.getSavedViewData(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response struct {
+		Series  []viewSeries `json:"series"`
+		EndDate string       `json:"end_date"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Series) != 1 || response.Series[0].ServiceName != "Netflix" {
+		t.Fatalf("Expected only the Netflix series, got %+v", response.Series)
+	}
+	points := response.Series[0].Points
+	if len(points) != 2 || points[0].Period != "2025-03-03" || points[0].Minutes != 60 || points[1].Minutes != 30 {
+		t.Errorf("Unexpected weekly points: %+v", points)
+	}
+	if response.EndDate != "2025-03-31" {
+		t.Errorf("Expected end date 2025-03-31, got %s", response.EndDate)
+	}
+}
+
+func TestGetSavedViewDataFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	watchedAt := time.Date(2025, 3, 3, 20, 0, 0, 0, time.UTC)
+	watchAs(t, db, "Alex", "Severance", 30, watchedAt)
+	watchAs(t, db, "Sam", "Love Is Blind", 60, watchedAt)
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.InsertSavedView(&database.SavedView{
+		Name:        "Netflix in March",
+		DateRange:   "custom",
+		StartDate:   "2025-03-01",
+		EndDate:     "2025-03-31",
+		ServiceIDs:  []int64{netflix.ID},
+		Granularity: "day",
+		ChartType:   "bar",
+	})
+
+	req, _ := http.NewRequest("GET", "/api/views/1/data?profile=alex", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+	handler.getSavedViewData(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Series []viewSeries `json:"series"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Series) != 1 || len(response.Series[0].Points) != 1 || response.Series[0].Points[0].Minutes != 30 {
+		t.Errorf("Expected only Alex's 30 minutes, got %+v", response.Series)
+	}
+}
+
+func TestDeleteSavedViewNotFound(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	req, _ := http.NewRequest("DELETE", "/api/views/99", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "99"})
+	rr := httptest.NewRecorder()
+	handler.deleteSavedView(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, status)
+	}
+}
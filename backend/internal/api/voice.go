@@ -0,0 +1,124 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/units"
+)
+
+// requireVoiceToken rejects requests that don't carry the configured voice token,
+// either as a bearer token or a token query parameter
+func (h *Handler) requireVoiceToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expected := h.config.Voice.Token
+		if expected == "" {
+			respondError(w, http.StatusServiceUnavailable, "Voice summary not configured", fmt.Errorf("voice.token is required"))
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respondError(w, http.StatusUnauthorized, "Invalid token", fmt.Errorf("missing or invalid voice token"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// getVoiceSummary returns a one-sentence spoken summary of recent viewing for
+// voice assistant webhooks (?period=today|week|month, default week)
+func (h *Handler) getVoiceSummary(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "week"
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var startDate time.Time
+	var phrase string
+	switch period {
+	case "today":
+		startDate, phrase = today, "today"
+	case "week":
+		startDate, phrase = units.WeekStart(today, h.config.Display.FirstDayOfWeek()), "this week"
+	case "month":
+		startDate, phrase = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), "this month"
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid period parameter", fmt.Errorf("period must be today, week or month"))
+		return
+	}
+
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	stats, err := db.GetServiceStats(startDate, today.AddDate(0, 0, 1))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch stats", err)
+		return
+	}
+
+	speech := spokenSummary(phrase, stats)
+	response := map[string]interface{}{
+		"speech": speech,
+		"text":   speech,
+		"period": period,
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// spokenSummary phrases viewing totals the way a voice assistant would say them,
+// e.g. "You've watched 5 hours and 20 minutes this week, mostly on YouTube TV."
+func spokenSummary(phrase string, stats []database.ServiceStats) string {
+	total := 0
+	var top database.ServiceStats
+	for _, stat := range stats {
+		total += stat.TotalMinutes
+		if stat.TotalMinutes > top.TotalMinutes {
+			top = stat
+		}
+	}
+
+	if total == 0 {
+		return fmt.Sprintf("You haven't watched anything %s.", phrase)
+	}
+
+	summary := fmt.Sprintf("You've watched %s %s", spokenDuration(total), phrase)
+	if top.TotalMinutes == total {
+		return fmt.Sprintf("%s, all on %s.", summary, top.ServiceName)
+	}
+	return fmt.Sprintf("%s, mostly on %s.", summary, top.ServiceName)
+}
+
+// spokenDuration formats minutes as words, e.g. "5 hours and 20 minutes"
+func spokenDuration(minutes int) string {
+	unit := func(n int, noun string) string {
+		if n == 1 {
+			return "1 " + noun
+		}
+		return fmt.Sprintf("%d %ss", n, noun)
+	}
+
+	hours, mins := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return unit(mins, "minute")
+	case mins == 0:
+		return unit(hours, "hour")
+	default:
+		return unit(hours, "hour") + " and " + unit(mins, "minute")
+	}
+}
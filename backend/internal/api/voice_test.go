@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetVoiceSummary(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Voice.Token = "secret"
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       service.ID,
+		Title:           "Severance",
+		DurationMinutes: 320,
+		WatchedAt:       time.Now(),
+	})
+
+	req, err := http.NewRequest("GET", "/api/voice/summary?period=today", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+
+	rr := httptest.NewRecorder()
+	handler.requireVoiceToken(handler.getVoiceSummary)(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, rr.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := "You've watched 5 hours and 20 minutes today, all on Netflix."
+	if response["speech"] != expected {
+		t.Errorf("Expected speech '%s', got '%v'", expected, response["speech"])
+	}
+}
+
+func TestGetVoiceSummaryFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	watchAs(t, db, "Alex", "Severance", 320, time.Now())
+	watchAs(t, db, "Sam", "Love Is Blind", 60, time.Now())
+
+	req, _ := http.NewRequest("GET", "/api/voice/summary?period=today&profile=alex", nil)
+	rr := httptest.NewRecorder()
+	handler.getVoiceSummary(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := "You've watched 5 hours and 20 minutes today, all on Netflix."
+	if response["speech"] != expected {
+		t.Errorf("Expected speech '%s', got '%v'", expected, response["speech"])
+	}
+}
+
+func TestGetVoiceSummaryRequiresToken(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	protected := handler.requireVoiceToken(handler.getVoiceSummary)
+
+	// Disabled when no token is configured
+	req, _ := http.NewRequest("GET", "/api/voice/summary", nil)
+	rr := httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+
+	handler.config.Voice.Token = "secret"
+	req, _ = http.NewRequest("GET", "/api/voice/summary?token=wrong", nil)
+	rr = httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/voice/summary?token=secret", nil)
+	rr = httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestSpokenSummary(t *testing.T) {
+	stats := []database.ServiceStats{
+		{ServiceName: "YouTube TV", TotalMinutes: 200},
+		{ServiceName: "Netflix", TotalMinutes: 120},
+	}
+	expected := "You've watched 5 hours and 20 minutes this week, mostly on YouTube TV."
+	if got := spokenSummary("this week", stats); got != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, got)
+	}
+
+	if got := spokenSummary("today", nil); got != "You haven't watched anything today." {
+		t.Errorf("Unexpected empty summary: %s", got)
+	}
+
+	if got := spokenDuration(61); got != "1 hour and 1 minute" {
+		t.Errorf("Unexpected duration: %s", got)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/units"
+)
+
+// widgetMaxAge is how long widgets and caches may reuse a summary. Home
+// screen widgets refresh every 15 minutes or so at best, so a few minutes of
+// staleness costs nothing.
+const widgetMaxAge = 5 * time.Minute
+
+// widgetSummary is the response of GET /api/widget, kept to a handful of
+// fields so it stays well under a kilobyte
+type widgetSummary struct {
+	Date         string `json:"date"`
+	TodayMinutes int    `json:"today_minutes"`
+	WeekMinutes  int    `json:"week_minutes"`
+	TopService   string `json:"top_service,omitempty"` // Most watched this week
+	TopColor     string `json:"top_color,omitempty"`
+	TopMinutes   int    `json:"top_minutes,omitempty"`
+}
+
+// requireWidgetToken rejects requests that don't carry the configured widget
+// token, either as a bearer token or a token query parameter
+func (h *Handler) requireWidgetToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expected := h.config.Widget.Token
+		if expected == "" {
+			respondError(w, http.StatusServiceUnavailable, "Widget summary not configured", fmt.Errorf("widget.token is required"))
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respondError(w, http.StatusUnauthorized, "Invalid token", fmt.Errorf("missing or invalid widget token"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// getWidgetSummary returns today's and this week's totals and the week's top
+// service for home screen widgets and Scriptable scripts. Responses carry an
+// ETag so a widget polling an unchanged summary gets an empty 304.
+func (h *Handler) getWidgetSummary(w http.ResponseWriter, r *http.Request) {
+	db, err := h.profileDB(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid profile", err)
+		return
+	}
+
+	now := time.Now()
+	today, err := loadTodayStats(db, now)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch today's stats", err)
+		return
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := units.WeekStart(midnight, h.config.Display.FirstDayOfWeek())
+	week, err := db.GetServiceStats(weekStart, midnight.AddDate(0, 0, 1))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch this week's stats", err)
+		return
+	}
+
+	summary := widgetSummary{Date: today.Date, TodayMinutes: today.TotalMinutes}
+	for _, stat := range week {
+		summary.WeekMinutes += stat.TotalMinutes
+		if stat.TotalMinutes > summary.TopMinutes {
+			summary.TopService, summary.TopColor, summary.TopMinutes = stat.ServiceName, stat.Color, stat.TotalMinutes
+		}
+	}
+
+	body, err := json.Marshal(summary)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to encode summary", err)
+		return
+	}
+	hash := fnv.New64a()
+	hash.Write(body)
+	etag := fmt.Sprintf(`"%x"`, hash.Sum64())
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(widgetMaxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestGetWidgetSummary(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+	handler.config.Widget.Token = "secret"
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: time.Now()})
+
+	protected := handler.requireWidgetToken(handler.getWidgetSummary)
+	req, _ := http.NewRequest("GET", "/api/widget?token=secret", nil)
+	rr := httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr.Body.Len() >= 1024 {
+		t.Errorf("Expected a sub-kilobyte response, got %d bytes", rr.Body.Len())
+	}
+	if cc := rr.Header().Get("Cache-Control"); cc != "private, max-age=300" {
+		t.Errorf("Expected a five minute private cache, got %q", cc)
+	}
+
+	var summary widgetSummary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.TodayMinutes != 50 || summary.WeekMinutes != 50 || summary.TopService != "Netflix" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	// An unchanged summary is answered with an empty 304
+	req, _ = http.NewRequest("GET", "/api/widget?token=secret", nil)
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	rr = httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("Expected an empty %d, got %d with %q", http.StatusNotModified, rr.Code, rr.Body.String())
+	}
+}
+
+func TestGetWidgetSummaryFilteredByProfile(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	watchAs(t, db, "Alex", "Severance", 50, time.Now())
+	watchAs(t, db, "Sam", "Love Is Blind", 60, time.Now())
+
+	req, _ := http.NewRequest("GET", "/api/widget?profile=alex", nil)
+	rr := httptest.NewRecorder()
+	handler.getWidgetSummary(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var summary widgetSummary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.TodayMinutes != 50 || summary.WeekMinutes != 50 || summary.TopMinutes != 50 {
+		t.Errorf("Expected only Alex's 50 minutes, got %+v", summary)
+	}
+}
+
+func TestGetWidgetSummaryRequiresToken(t *testing.T) {
+	handler, db := setupTestAPI(t)
+	defer db.Close()
+
+	protected := handler.requireWidgetToken(handler.getWidgetSummary)
+
+	req, _ := http.NewRequest("GET", "/api/widget", nil)
+	rr := httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d without a configured token, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+
+	handler.config.Widget.Token = "secret"
+	req, _ = http.NewRequest("GET", "/api/widget?token=wrong", nil)
+	rr = httptest.NewRecorder()
+	protected(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d for a wrong token, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package browsercookies
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Browsers whose cookie stores can be read
+const (
+	BrowserChrome   = "chrome"
+	BrowserChromium = "chromium"
+	BrowserFirefox  = "firefox"
+)
+
+// Read returns the cookies for domain and its subdomains from a local browser
+// profile, so sessions kept alive by a daily-use browser can be reused.
+// Chrome cookie values are decrypted with the OS keychain where possible.
+func Read(browser, profilePath, domain string) ([]config.Cookie, error) {
+	profilePath, err := expandHome(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	if domain == "" {
+		return nil, fmt.Errorf("no cookie domain given")
+	}
+
+	var cookies []config.Cookie
+	switch strings.ToLower(browser) {
+	case BrowserChrome, BrowserChromium, "":
+		cookies, err = readChrome(strings.ToLower(browser), profilePath, domain)
+	case BrowserFirefox:
+		cookies, err = readFirefox(profilePath, domain)
+	default:
+		return nil, fmt.Errorf("unsupported browser %q", browser)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("no %s cookies in %s", domain, profilePath)
+	}
+	return cookies, nil
+}
+
+// openCopy copies a browser's cookie database (and its write-ahead log, if
+// any) to a temporary directory and opens the copy read-only, since the
+// browser keeps the original locked while it runs. The returned func closes
+// the database and removes the copy.
+func openCopy(path string) (*sql.DB, func(), error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil, fmt.Errorf("cookie database not found: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "streamtime-cookies-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	copyPath := filepath.Join(dir, filepath.Base(path))
+	for _, suffix := range []string{"", "-wal"} {
+		if err := copyFile(path+suffix, copyPath+suffix); err != nil && !(suffix != "" && os.IsNotExist(err)) {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite3", copyPath+"?mode=ro")
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return db, func() {
+		db.Close()
+		cleanup()
+	}, nil
+}
+
+// copyFile copies src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// sameSiteName converts the SameSite enums both browsers store to config names
+func sameSiteName(value int) string {
+	switch value {
+	case 0:
+		return "None"
+	case 1:
+		return "Lax"
+	case 2:
+		return "Strict"
+	default:
+		return ""
+	}
+}
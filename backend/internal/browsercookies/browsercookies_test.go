@@ -0,0 +1,126 @@
+package browsercookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// createDB creates a SQLite database at path with the given statements
+func createDB(t *testing.T, path string, statements ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to run %q: %v", stmt, err)
+		}
+	}
+}
+
+func TestReadFirefox(t *testing.T) {
+	profile := t.TempDir()
+	createDB(t, filepath.Join(profile, "cookies.sqlite"),
+		`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT, path TEXT,
+			expiry INTEGER, isSecure INTEGER, isHttpOnly INTEGER, sameSite INTEGER)`,
+		`INSERT INTO moz_cookies (name, value, host, path, expiry, isSecure, isHttpOnly, sameSite)
+			VALUES ('NetflixId', 'abc', '.netflix.com', '/', 1767225600000, 1, 1, 1),
+			       ('nfvdid', 'def', 'www.netflix.com', '/', 1767225600, 1, 0, 0),
+			       ('other', 'ghi', '.notnetflix.com', '/', 1767225600, 1, 0, 0)`,
+	)
+
+	cookies, err := Read(BrowserFirefox, profile, ".netflix.com")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("Expected 2 Netflix cookies, got %d", len(cookies))
+	}
+	if c := cookies[0]; c.Value != "abc" || c.Expires != 1767225600 || c.SameSite != "Lax" || !*c.HTTPOnly {
+		t.Errorf("Unexpected cookie: %+v", c)
+	}
+}
+
+// encryptChromeV10 encrypts a value the way Chrome does on Linux without a keyring
+func encryptChromeV10(t *testing.T, host, value string) []byte {
+	t.Helper()
+	key, err := pbkdf2.Key(sha1.New, "peanuts", []byte("saltysalt"), 1, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte(host))
+	plaintext := append(hash[:], value...)
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	plaintext = append(plaintext, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	block, _ := aes.NewCipher(key)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, bytes.Repeat([]byte(" "), aes.BlockSize)).CryptBlocks(ciphertext, plaintext)
+	return append([]byte("v10"), ciphertext...)
+}
+
+func TestReadChrome(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fixed-password decryption only applies on Linux")
+	}
+
+	profile := t.TempDir()
+	if err := os.Mkdir(filepath.Join(profile, "Network"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(profile, "Network", "Cookies")
+	createDB(t, path,
+		`CREATE TABLE meta (key TEXT PRIMARY KEY, value TEXT)`,
+		`INSERT INTO meta VALUES ('version', '24')`,
+		`CREATE TABLE cookies (host_key TEXT, name TEXT, value TEXT, encrypted_value BLOB, path TEXT,
+			expires_utc INTEGER, is_secure INTEGER, is_httponly INTEGER, samesite INTEGER)`,
+	)
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2026-01-01 in microseconds since 1601
+	expires := (int64(1767225600) + chromeEpochOffset) * 1e6
+	_, err = db.Exec(`INSERT INTO cookies VALUES (?, ?, '', ?, '/', ?, 1, 1, 0), ('.amazon.com', 'plain', 'visible', X'', '/', 0, 0, 0, -1)`,
+		".amazon.com", "session-token", encryptChromeV10(t, ".amazon.com", "decrypted-token"), expires)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies, err := Read(BrowserChrome, profile, "amazon.com")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("Expected 2 cookies, got %d", len(cookies))
+	}
+	if c := cookies[0]; c.Value != "decrypted-token" || c.Expires != 1767225600 || c.SameSite != "None" {
+		t.Errorf("Unexpected cookie: %+v", c)
+	}
+	if c := cookies[1]; c.Value != "visible" || c.Expires != 0 || c.SameSite != "" {
+		t.Errorf("Unexpected unencrypted cookie: %+v", c)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	if _, err := Read("safari", t.TempDir(), "netflix.com"); err == nil {
+		t.Error("Expected error for an unsupported browser")
+	}
+	if _, err := Read(BrowserFirefox, t.TempDir(), "netflix.com"); err == nil {
+		t.Error("Expected error for a profile without a cookie database")
+	}
+}
@@ -0,0 +1,178 @@
+package browsercookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+// chromeEpochOffset is the number of seconds between Chrome's 1601 epoch and the Unix epoch
+const chromeEpochOffset = 11644473600
+
+// chromeHostHashVersion is the cookie database version from which decrypted
+// values start with a SHA-256 of the cookie's host
+const chromeHostHashVersion = 24
+
+// keychainPassword looks up the password Chrome encrypts cookies with in the
+// OS keychain. It is a variable so tests can stub it out.
+var keychainPassword = func(browser string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		service := "Chrome Safe Storage"
+		if browser == BrowserChromium {
+			service = "Chromium Safe Storage"
+		}
+		cmd = exec.Command("security", "find-generic-password", "-w", "-s", service)
+	case "linux":
+		application := "chrome"
+		if browser == BrowserChromium {
+			application = "chromium"
+		}
+		cmd = exec.Command("secret-tool", "lookup", "application", application)
+	default:
+		return "", fmt.Errorf("keychain lookup is not supported on %s", runtime.GOOS)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("keychain lookup failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// readChrome reads cookies from a Chrome or Chromium profile, decrypting
+// encrypted values
+func readChrome(browser, profilePath, domain string) ([]config.Cookie, error) {
+	// Newer versions keep the database under Network/
+	path := filepath.Join(profilePath, "Network", "Cookies")
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(profilePath, "Cookies")
+	}
+
+	db, closeDB, err := openCopy(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB()
+
+	// Older databases without a meta version simply don't get the host hash stripped
+	var version int
+	db.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&version)
+
+	rows, err := db.Query(`
+		SELECT host_key, name, value, encrypted_value, path, expires_utc, is_secure, is_httponly, samesite
+		FROM cookies
+		WHERE host_key = ? OR host_key = ? OR host_key LIKE ?
+	`, domain, "."+domain, "%."+domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string][]byte)
+	var cookies []config.Cookie
+	for rows.Next() {
+		var cookie config.Cookie
+		var encrypted []byte
+		var expiresUTC int64
+		var secure, httpOnly bool
+		var sameSite int
+		if err := rows.Scan(&cookie.Domain, &cookie.Name, &cookie.Value, &encrypted, &cookie.Path,
+			&expiresUTC, &secure, &httpOnly, &sameSite); err != nil {
+			return nil, err
+		}
+
+		if cookie.Value == "" && len(encrypted) > 3 {
+			prefix := string(encrypted[:3])
+			key, ok := keys[prefix]
+			if !ok {
+				if key, err = chromeKey(browser, prefix); err != nil {
+					return nil, err
+				}
+				keys[prefix] = key
+			}
+
+			value, err := decryptChromeValue(key, encrypted, version >= chromeHostHashVersion)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt cookie %s: %w", cookie.Name, err)
+			}
+			cookie.Value = value
+		}
+
+		if expiresUTC > 0 {
+			cookie.Expires = expiresUTC/1e6 - chromeEpochOffset
+		}
+		cookie.Secure = &secure
+		cookie.HTTPOnly = &httpOnly
+		cookie.SameSite = sameSiteName(sameSite)
+		cookies = append(cookies, cookie)
+	}
+
+	return cookies, rows.Err()
+}
+
+// chromeKey derives the AES key for values with the given version prefix.
+// On Linux "v10" values use a fixed password and "v11" values use the
+// keyring; on macOS the password is always in the keychain.
+func chromeKey(browser, prefix string) ([]byte, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		password, err := keychainPassword(browser)
+		if err != nil {
+			return nil, err
+		}
+		return pbkdf2.Key(sha1.New, password, []byte("saltysalt"), 1003, 16)
+	case "linux":
+		password := "peanuts"
+		if prefix == "v11" {
+			var err error
+			if password, err = keychainPassword(browser); err != nil {
+				return nil, err
+			}
+		}
+		return pbkdf2.Key(sha1.New, password, []byte("saltysalt"), 1, 16)
+	default:
+		return nil, fmt.Errorf("decrypting %s cookies is not supported on %s", browser, runtime.GOOS)
+	}
+}
+
+// decryptChromeValue decrypts an AES-128-CBC cookie value after its version prefix
+func decryptChromeValue(key, encrypted []byte, hostHash bool) (string, error) {
+	ciphertext := encrypted[3:]
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("invalid ciphertext length %d", len(ciphertext))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	iv := bytes.Repeat([]byte(" "), aes.BlockSize)
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(plaintext) {
+		return "", fmt.Errorf("invalid padding, the keychain password may be wrong")
+	}
+	plaintext = plaintext[:len(plaintext)-padding]
+
+	if hostHash {
+		if len(plaintext) < 32 {
+			return "", fmt.Errorf("value shorter than its host hash")
+		}
+		plaintext = plaintext[32:]
+	}
+	return string(plaintext), nil
+}
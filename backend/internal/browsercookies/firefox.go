@@ -0,0 +1,51 @@
+package browsercookies
+
+import (
+	"path/filepath"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+// readFirefox reads cookies from a Firefox profile's cookies.sqlite, which
+// stores values unencrypted
+func readFirefox(profilePath, domain string) ([]config.Cookie, error) {
+	db, closeDB, err := openCopy(filepath.Join(profilePath, "cookies.sqlite"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB()
+
+	rows, err := db.Query(`
+		SELECT host, name, value, path, expiry, isSecure, isHttpOnly, sameSite
+		FROM moz_cookies
+		WHERE host = ? OR host = ? OR host LIKE ?
+	`, domain, "."+domain, "%."+domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cookies []config.Cookie
+	for rows.Next() {
+		var cookie config.Cookie
+		var expiry int64
+		var secure, httpOnly bool
+		var sameSite int
+		if err := rows.Scan(&cookie.Domain, &cookie.Name, &cookie.Value, &cookie.Path,
+			&expiry, &secure, &httpOnly, &sameSite); err != nil {
+			return nil, err
+		}
+
+		// Newer Firefox versions store the expiry in milliseconds
+		if expiry > 1e12 {
+			expiry /= 1000
+		}
+		cookie.Expires = expiry
+		cookie.Secure = &secure
+		cookie.HTTPOnly = &httpOnly
+		cookie.SameSite = sameSiteName(sameSite)
+		cookies = append(cookies, cookie)
+	}
+
+	return cookies, rows.Err()
+}
@@ -0,0 +1,218 @@
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// Redacted replaces scrubbed values
+const Redacted = "[REDACTED]"
+
+var (
+	// emailPattern matches email addresses, which scrapers tend to log and pages tend to show
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+	// cookieHeaderPattern matches Cookie and Set-Cookie headers up to the end of the line
+	cookieHeaderPattern = regexp.MustCompile(`(?i)((?:set-)?cookie:\s*)[^\r\n]+`)
+
+	// valueAttrPattern matches value attributes in HTML, such as pre-filled form fields
+	valueAttrPattern = regexp.MustCompile(`(?i)(\svalue\s*=\s*)("[^"]*"|'[^']*')`)
+)
+
+// Scrubber removes credentials and personal details from diagnostics before
+// they leave the machine
+type Scrubber struct {
+	secrets []string
+}
+
+// NewScrubber builds a scrubber that redacts every cookie value, password,
+// email, token and API key in the configuration, plus any extra cookies such
+// as ones imported through the API or read from a browser profile
+func NewScrubber(cfg *config.Config, extra ...config.Cookie) *Scrubber {
+	var secrets []string
+	for _, cookie := range extra {
+		secrets = append(secrets, cookie.Value)
+	}
+	for _, svc := range cfg.Services {
+		for _, cookie := range svc.Cookies {
+			secrets = append(secrets, cookie.Value)
+		}
+		for _, profile := range svc.Profiles {
+			for _, cookie := range profile.Cookies {
+				secrets = append(secrets, cookie.Value)
+			}
+		}
+		secrets = append(secrets, svc.Email, svc.Password)
+	}
+	for _, peer := range cfg.Federation.Peers {
+		secrets = append(secrets, peer.Token)
+	}
+	secrets = append(secrets,
+		cfg.TMDB.APIKey,
+		cfg.Gaming.Steam.APIKey,
+		cfg.Voice.Token,
+		cfg.Widget.Token,
+		cfg.Federation.Token,
+		cfg.ScreenTime.Token,
+		cfg.DeviceIngest.Token,
+		cfg.NetworkIngest.Token,
+		cfg.Trakt.ClientSecret,
+		cfg.MQTT.Password,
+		cfg.Influx.Token,
+		cfg.Goals.CalDAV.Password,
+		cfg.Storage.S3.SecretKey,
+	)
+
+	s := &Scrubber{}
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		// Very short values would redact unrelated text
+		if len(secret) < 4 || seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		s.secrets = append(s.secrets, secret)
+	}
+
+	// Replace longer secrets first so one containing another is fully redacted
+	sort.Slice(s.secrets, func(i, j int) bool { return len(s.secrets[i]) > len(s.secrets[j]) })
+	return s
+}
+
+// Scrub redacts known secrets, cookie headers and email addresses from text
+func (s *Scrubber) Scrub(text string) string {
+	for _, secret := range s.secrets {
+		text = strings.ReplaceAll(text, secret, Redacted)
+	}
+	text = cookieHeaderPattern.ReplaceAllString(text, "${1}"+Redacted)
+	return emailPattern.ReplaceAllString(text, Redacted)
+}
+
+// ScrubHTML scrubs text and also blanks value attributes, since form fields
+// on a login or account page can hold credentials the config doesn't know about
+func (s *Scrubber) ScrubHTML(html string) string {
+	html = valueAttrPattern.ReplaceAllString(html, `${1}"`+Redacted+`"`)
+	return s.Scrub(html)
+}
+
+// VersionInfo describes the build that produced a bundle
+type VersionInfo struct {
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// CurrentVersion reads version details from the running binary's build info
+func CurrentVersion() VersionInfo {
+	info := VersionInfo{
+		Version:   "unknown",
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = build.Main.Path
+	if build.Main.Version != "" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
+
+// runSummary is the run.json entry of a bundle
+type runSummary struct {
+	ID           int64          `json:"id"`
+	Service      string         `json:"service"`
+	RanAt        time.Time      `json:"ran_at"`
+	Status       string         `json:"status"`
+	ErrorMessage string         `json:"error_message,omitempty"`
+	ItemsScraped int            `json:"items_scraped"`
+	SelectorHits map[string]int `json:"selector_hits,omitempty"`
+	GeneratedAt  time.Time      `json:"generated_at"`
+}
+
+// Write packages a scraper run into a zip for attaching to a bug report:
+// run.json (status, error and selector hit counts), logs.txt, dom.html and
+// version.json. Everything is passed through the scrubber first.
+func Write(w io.Writer, run *database.ScraperRun, serviceName string, scrubber *Scrubber) error {
+	zw := zip.NewWriter(w)
+
+	summary := runSummary{
+		ID:           run.ID,
+		Service:      serviceName,
+		RanAt:        run.RanAt,
+		Status:       run.Status,
+		ErrorMessage: scrubber.Scrub(run.ErrorMessage),
+		ItemsScraped: run.ItemsScraped,
+		SelectorHits: run.SelectorHits,
+		GeneratedAt:  time.Now().UTC(),
+	}
+	if err := writeJSON(zw, "run.json", summary); err != nil {
+		return err
+	}
+
+	logs := run.Logs
+	if logs == "" {
+		logs = "No log output was captured for this run.\n"
+	}
+	if err := writeFile(zw, "logs.txt", scrubber.Scrub(logs)); err != nil {
+		return err
+	}
+
+	if run.DOMSnapshot != "" {
+		if err := writeFile(zw, "dom.html", scrubber.ScrubHTML(run.DOMSnapshot)); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSON(zw, "version.json", CurrentVersion()); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeFile adds a text file to the zip
+func writeFile(zw *zip.Writer, name, content string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+// writeJSON adds an indented JSON file to the zip
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(zw, name, string(data)+"\n")
+}
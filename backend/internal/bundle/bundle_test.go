@@ -0,0 +1,156 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"netflix": {
+				Enabled: true,
+				Cookies: []config.Cookie{{Name: "NetflixId", Value: "v%3D2%26ct%3Dsecret"}},
+			},
+			"vudu": {
+				Enabled:  true,
+				Password: "hunter22",
+			},
+		},
+		TMDB: config.TMDBConfig{APIKey: "tmdb-key-123"},
+	}
+}
+
+func TestScrub(t *testing.T) {
+	scrubber := NewScrubber(testConfig())
+
+	text := scrubber.Scrub("cookie NetflixId=v%3D2%26ct%3Dsecret for jane@example.com, password hunter22, key tmdb-key-123\n" +
+		"Cookie: SecureNetflixId=abc; nfvdid=def\nnext line")
+
+	for _, leaked := range []string{"v%3D2%26ct%3Dsecret", "jane@example.com", "hunter22", "tmdb-key-123", "abc; nfvdid"} {
+		if strings.Contains(text, leaked) {
+			t.Errorf("Expected %q to be scrubbed, got %q", leaked, text)
+		}
+	}
+	if !strings.Contains(text, "next line") {
+		t.Errorf("Expected the line after a cookie header to be kept, got %q", text)
+	}
+}
+
+// readBundle returns the contents of each file in a bundle by name
+func readBundle(t *testing.T, buf *bytes.Buffer) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestScrubConfigSecrets(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"netflix": {
+				Enabled: true,
+				Profiles: []config.ProfileConfig{
+					{Name: "alex", Cookies: []config.Cookie{{Name: "NetflixId", Value: "profile-cookie-value"}}},
+				},
+			},
+		},
+		Widget:        config.WidgetConfig{Token: "widget-token"},
+		Federation:    config.FederationConfig{Token: "federation-token", Peers: []config.PeerConfig{{Name: "sam", Token: "peer-token"}}},
+		ScreenTime:    config.ScreenTimeConfig{Token: "screen-time-token"},
+		DeviceIngest:  config.DeviceIngestConfig{Token: "device-token"},
+		NetworkIngest: config.NetworkIngestConfig{Token: "network-token"},
+		Trakt:         config.TraktConfig{ClientID: "trakt-id", ClientSecret: "trakt-secret"},
+	}
+	secrets := []string{"profile-cookie-value", "widget-token", "federation-token", "peer-token",
+		"screen-time-token", "device-token", "network-token", "trakt-secret", "browser-cookie-value"}
+
+	run := &database.ScraperRun{
+		ID:           8,
+		Status:       "failed",
+		ErrorMessage: strings.Join(secrets, " "),
+		Logs:         strings.Join(secrets, "\n"),
+		DOMSnapshot:  "<p>" + strings.Join(secrets, "</p><p>") + "</p>",
+	}
+	var buf bytes.Buffer
+	scrubber := NewScrubber(cfg, config.Cookie{Name: "SecureNetflixId", Value: "browser-cookie-value"})
+	if err := Write(&buf, run, "Netflix", scrubber); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	for name, contents := range readBundle(t, &buf) {
+		for _, secret := range secrets {
+			if strings.Contains(contents, secret) {
+				t.Errorf("Expected %q to be scrubbed from %s", secret, name)
+			}
+		}
+	}
+}
+
+func TestScrubHTMLValues(t *testing.T) {
+	scrubber := NewScrubber(testConfig())
+
+	html := scrubber.ScrubHTML(`<input name="email" value="someone"><input type='password' value='pw'>`)
+	if strings.Contains(html, "someone") || strings.Contains(html, "'pw'") {
+		t.Errorf("Expected form values to be scrubbed, got %q", html)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	run := &database.ScraperRun{
+		ID:           7,
+		RanAt:        time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC),
+		Status:       "failed",
+		ErrorMessage: "no data found for jane@example.com",
+		SelectorHits: map[string]int{".retableRow": 0},
+		Logs:         "Loading cookie NetflixId=v%3D2%26ct%3Dsecret\n",
+		DOMSnapshot:  "<html><body>Sign in</body></html>",
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, run, "Netflix", NewScrubber(testConfig())); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	files := readBundle(t, &buf)
+	for _, name := range []string{"run.json", "logs.txt", "dom.html", "version.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s in the bundle", name)
+		}
+	}
+
+	var summary runSummary
+	if err := json.Unmarshal([]byte(files["run.json"]), &summary); err != nil {
+		t.Fatalf("Failed to decode run.json: %v", err)
+	}
+	if summary.Service != "Netflix" || summary.SelectorHits[".retableRow"] != 0 {
+		t.Errorf("Unexpected run summary: %+v", summary)
+	}
+	if strings.Contains(summary.ErrorMessage, "jane@example.com") {
+		t.Errorf("Expected email scrubbed from the error, got %q", summary.ErrorMessage)
+	}
+	if strings.Contains(files["logs.txt"], "secret") {
+		t.Errorf("Expected cookie value scrubbed from logs, got %q", files["logs.txt"])
+	}
+}
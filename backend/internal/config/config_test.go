@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -31,6 +32,17 @@ services:
     email: test2@example.com
     password: testpass2
     use_oauth: true
+    cookies:
+      - name: SID
+        value: sid-value
+      - name: __Secure-3PSID
+        value: secure-value
+        domain: .google.com
+        path: /
+        expires: 1767225600
+        same_site: None
+        secure: true
+        http_only: false
 
 scraper:
   schedule: "0 2 * * *"
@@ -89,6 +101,19 @@ scraper:
 	if !youtubeTv.UseOAuth {
 		t.Error("Expected YouTube TV to use OAuth")
 	}
+	if len(youtubeTv.Cookies) != 2 {
+		t.Fatalf("Expected 2 YouTube TV cookies, got %d", len(youtubeTv.Cookies))
+	}
+	if plain := youtubeTv.Cookies[0]; plain.Domain != "" || plain.Secure != nil || plain.HTTPOnly != nil {
+		t.Errorf("Expected unset attributes on a name/value cookie, got %+v", plain)
+	}
+	secure := youtubeTv.Cookies[1]
+	if secure.Domain != ".google.com" || secure.Path != "/" || secure.Expires != 1767225600 || secure.SameSite != "None" {
+		t.Errorf("Unexpected cookie attributes: %+v", secure)
+	}
+	if secure.Secure == nil || !*secure.Secure || secure.HTTPOnly == nil || *secure.HTTPOnly {
+		t.Errorf("Expected secure and not http_only, got %+v", secure)
+	}
 
 	// Verify scraper config
 	if cfg.Scraper.Schedule != "0 2 * * *" {
@@ -139,6 +164,9 @@ services:
 	if cfg.Database.Path != "./data/streamtime.db" {
 		t.Errorf("Expected default database path './data/streamtime.db', got '%s'", cfg.Database.Path)
 	}
+	if cfg.Database.Driver != "sqlite" || cfg.Database.DataSource() != cfg.Database.Path {
+		t.Errorf("Expected the sqlite driver on the database path by default, got '%s' on '%s'", cfg.Database.Driver, cfg.Database.DataSource())
+	}
 	if cfg.Scraper.Schedule != "0 3 * * *" {
 		t.Errorf("Expected default schedule '0 3 * * *', got '%s'", cfg.Scraper.Schedule)
 	}
@@ -148,6 +176,205 @@ services:
 	if cfg.Scraper.UserAgent == "" {
 		t.Error("Expected default user agent to be set")
 	}
+	if cfg.Scraper.MaxConsecutiveFailures != 3 {
+		t.Errorf("Expected default max consecutive failures 3, got %d", cfg.Scraper.MaxConsecutiveFailures)
+	}
+	if cfg.Scraper.BackoffHours != 24 {
+		t.Errorf("Expected default backoff hours 24, got %d", cfg.Scraper.BackoffHours)
+	}
+	if cfg.Scraper.FirstRunLookbackDays != 730 || cfg.Scraper.IncrementalLookbackDays != 7 {
+		t.Errorf("Expected default lookback 730/7 days, got %d/%d", cfg.Scraper.FirstRunLookbackDays, cfg.Scraper.IncrementalLookbackDays)
+	}
+	if cfg.Insights.Footprint.DefaultResolution != "hd" {
+		t.Errorf("Expected default resolution 'hd', got '%s'", cfg.Insights.Footprint.DefaultResolution)
+	}
+	if cfg.Insights.Footprint.KWhPerHour != 0.08 {
+		t.Errorf("Expected default kWh per hour 0.08, got %v", cfg.Insights.Footprint.KWhPerHour)
+	}
+	if cfg.MQTT.TopicPrefix != "streamtime" || cfg.MQTT.ClientID != "streamtime" {
+		t.Errorf("Expected default MQTT prefix and client ID 'streamtime', got '%s' and '%s'", cfg.MQTT.TopicPrefix, cfg.MQTT.ClientID)
+	}
+	if cfg.Influx.Measurement != "watch_time" || cfg.Influx.BackfillDays != 30 {
+		t.Errorf("Expected default Influx measurement 'watch_time' and 30 backfill days, got '%s' and %d", cfg.Influx.Measurement, cfg.Influx.BackfillDays)
+	}
+	if cfg.Goals.StreakThresholdMinutes != 60 || cfg.Goals.CalDAV.Match != "screen-free" {
+		t.Errorf("Expected default streak threshold 60 and match 'screen-free', got %d and '%s'", cfg.Goals.StreakThresholdMinutes, cfg.Goals.CalDAV.Match)
+	}
+	if cfg.Display.DurationFormat != "short" || cfg.Display.FirstDayOfWeek() != time.Monday {
+		t.Errorf("Expected short durations and Monday week start by default, got '%s' and %s", cfg.Display.DurationFormat, cfg.Display.FirstDayOfWeek())
+	}
+}
+
+func TestLoadInvalidDisplay(t *testing.T) {
+	for _, content := range []string{"display:\n  week_start: friday\n", "display:\n  duration_format: verbose\n"} {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if _, err := Load(configPath); err == nil {
+			t.Errorf("Expected an error loading %q", content)
+		}
+	}
+}
+
+func TestLoadDatabaseDriver(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "database:\n  driver: postgres\n  dsn: postgres://streamtime@localhost/streamtime\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Database.DataSource() != "postgres://streamtime@localhost/streamtime" {
+		t.Errorf("Expected the postgres DSN as the data source, got '%s'", cfg.Database.DataSource())
+	}
+
+	for _, content := range []string{"database:\n  driver: postgres\n", "database:\n  driver: mysql\n"} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if _, err := Load(configPath); err == nil {
+			t.Errorf("Expected an error loading %q", content)
+		}
+	}
+}
+
+func TestLoadServiceProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "services:\n  netflix:\n    enabled: true\n    profiles:\n      - name: alex\n        cookies:\n          - name: NetflixId\n            value: abc\n      - name: sam\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	profiles := cfg.Services["netflix"].Profiles
+	if len(profiles) != 2 || profiles[0].Name != "alex" || len(profiles[0].Cookies) != 1 {
+		t.Errorf("Expected two profiles with alex's cookie, got %+v", profiles)
+	}
+
+	for _, content := range []string{
+		"services:\n  netflix:\n    profiles:\n      - cookies: []\n",
+		"services:\n  netflix:\n    profiles:\n      - name: alex\n      - name: Alex\n",
+	} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if _, err := Load(configPath); err == nil {
+			t.Errorf("Expected an error loading %q", content)
+		}
+	}
+}
+
+func TestLoadInvalidEffectiveTime(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	for _, content := range []string{
+		"insights:\n  effective_time:\n    intro_percent: -5\n",
+		"insights:\n  effective_time:\n    intro_percent: 60\n    credits_percent: 40\n",
+		"insights:\n  effective_time:\n    shows:\n      The Office:\n        intro_minutes: -1\n",
+	} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if _, err := Load(configPath); err == nil {
+			t.Errorf("Expected an error loading %q", content)
+		}
+	}
+}
+
+func TestLoadMarketplace(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	for content, wantErr := range map[string]bool{
+		"services:\n  amazon_video:\n    marketplace: amazon.co.uk\n":  false,
+		"services:\n  amazon_video:\n    marketplace: amazon.de\n":     false,
+		"services:\n  amazon_video:\n    marketplace: www.amazon.de\n": true,
+		"services:\n  amazon_video:\n    marketplace: netflix.com\n":   true,
+	} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if _, err := Load(configPath); (err != nil) != wantErr {
+			t.Errorf("Load(%q) error = %v, want error %v", content, err, wantErr)
+		}
+	}
+}
+
+func TestLoadThrottle(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	for content, wantErr := range map[string]bool{
+		"services:\n  netflix:\n    throttle:\n      min_delay_seconds: 3.5\n      max_pages: 20\n": false,
+		"services:\n  netflix:\n    throttle:\n      max_runs_per_day: 2\n":                         false,
+		"services:\n  netflix:\n    throttle:\n      min_delay_seconds: -1\n":                       true,
+		"services:\n  netflix:\n    throttle:\n      max_pages: -5\n":                               true,
+	} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if _, err := Load(configPath); (err != nil) != wantErr {
+			t.Errorf("Load(%q) error = %v, want error %v", content, err, wantErr)
+		}
+	}
+}
+
+func TestLoadMaxConsecutiveFailures(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	for content, expected := range map[string]int{
+		"scraper:\n  schedule: \"0 3 * * *\"\n":      3,
+		"scraper:\n  max_consecutive_failures: 5\n":  5,
+		"scraper:\n  max_consecutive_failures: -1\n": -1, // Kept negative, so runs never back off
+	} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load(%q) failed: %v", content, err)
+		}
+		if cfg.Scraper.MaxConsecutiveFailures != expected {
+			t.Errorf("Load(%q): expected max consecutive failures %d, got %d", content, expected, cfg.Scraper.MaxConsecutiveFailures)
+		}
+	}
+}
+
+func TestLoadRandomize(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	for content, wantErr := range map[string]bool{
+		"scraper:\n  randomize:\n    window_minutes: 90\n    skip_chance: 0.1\n    scroll_pacing: true\n": false,
+		"scraper:\n  randomize:\n    window_minutes: -5\n":                                                true,
+		"scraper:\n  randomize:\n    skip_chance: 1\n":                                                    true,
+	} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if _, err := Load(configPath); (err != nil) != wantErr {
+			t.Errorf("Load(%q) error = %v, want error %v", content, err, wantErr)
+		}
+	}
+}
+
+func TestLoadNotifications(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	for content, wantErr := range map[string]bool{
+		"notifications:\n  new_seasons:\n    enabled: true\n":                        true,
+		"tmdb:\n  api_key: key\nnotifications:\n  new_seasons:\n    enabled: true\n": false,
+		"notifications:\n  leaving:\n    enabled: true\n":                            true,
+		"notifications:\n  leaving:\n    titles: -1\n":                               true,
+		"notifications:\n  webhook_url: not a url\n":                                 true,
+	} {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		cfg, err := Load(configPath)
+		if (err != nil) != wantErr {
+			t.Errorf("Load(%q) error = %v, want error %v", content, err, wantErr)
+		}
+		if err == nil && cfg.Notifications.NewSeasons.Schedule != "0 9 * * *" {
+			t.Errorf("Expected the default schedule, got %q", cfg.Notifications.NewSeasons.Schedule)
+		}
+	}
 }
 
 func TestLoadInvalidPath(t *testing.T) {
@@ -214,3 +441,22 @@ func TestGetEnabledServices(t *testing.T) {
 		t.Error("Did not expect youtube_tv to be in enabled services")
 	}
 }
+
+func TestProviderFor(t *testing.T) {
+	cfg := &Config{
+		Services: map[string]ServiceConfig{
+			"netflix":      {Enabled: true},
+			"netflix_kids": {Enabled: true, Provider: "netflix"},
+		},
+	}
+
+	if got := cfg.ProviderFor("netflix"); got != "netflix" {
+		t.Errorf("Expected netflix, got '%s'", got)
+	}
+	if got := cfg.ProviderFor("netflix_kids"); got != "netflix" {
+		t.Errorf("Expected netflix_kids to use the netflix provider, got '%s'", got)
+	}
+	if got := cfg.ProviderFor("unknown"); got != "unknown" {
+		t.Errorf("Expected unknown services to default to their key, got '%s'", got)
+	}
+}
@@ -0,0 +1,173 @@
+package cookiefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+// Formats reported by Parse
+const (
+	FormatNetscape = "netscape" // cookies.txt, as written by curl, wget, yt-dlp and many extensions
+	FormatJSON     = "json"     // EditThisCookie and Cookie-Editor exports
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in cookies.txt files, on lines that
+// would otherwise be comments
+const httpOnlyPrefix = "#HttpOnly_"
+
+// Parse reads cookies from a cookies.txt or browser extension JSON export,
+// detecting the format from the content. It returns the detected format.
+func Parse(data []byte) ([]config.Cookie, string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, "", fmt.Errorf("cookie file is empty")
+	}
+
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		cookies, err := ParseJSON(trimmed)
+		return cookies, FormatJSON, err
+	}
+
+	cookies, err := ParseNetscape(trimmed)
+	return cookies, FormatNetscape, err
+}
+
+// ParseNetscape reads a Netscape cookies.txt file: one cookie per line with
+// tab-separated domain, include-subdomains flag, path, secure flag, expiry,
+// name and value
+func ParseNetscape(data []byte) ([]config.Cookie, error) {
+	var cookies []config.Cookie
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(text, httpOnlyPrefix) {
+			httpOnly = true
+			text = strings.TrimPrefix(text, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", line, len(fields))
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", line, fields[4])
+		}
+
+		secure := strings.EqualFold(fields[3], "TRUE")
+		cookies = append(cookies, config.Cookie{
+			Name:     fields[5],
+			Value:    strings.Join(fields[6:], "\t"),
+			Domain:   fields[0],
+			Path:     fields[2],
+			Expires:  expires,
+			Secure:   &secure,
+			HTTPOnly: &httpOnly,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("no cookies found")
+	}
+	return cookies, nil
+}
+
+// extensionCookie is a cookie as exported by EditThisCookie or Cookie-Editor
+type extensionCookie struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	ExpirationDate float64 `json:"expirationDate"` // Unix seconds, with a fraction; absent for session cookies
+	SameSite       string  `json:"sameSite"`       // "no_restriction", "lax", "strict" or "unspecified"
+	Secure         bool    `json:"secure"`
+	HTTPOnly       bool    `json:"httpOnly"`
+}
+
+// ParseJSON reads a JSON array of cookies in the format browser extensions
+// export. A single cookie object is also accepted.
+func ParseJSON(data []byte) ([]config.Cookie, error) {
+	var exported []extensionCookie
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var single extensionCookie
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("invalid cookie JSON: %w", err)
+		}
+		exported = append(exported, single)
+	} else if err := json.Unmarshal(data, &exported); err != nil {
+		return nil, fmt.Errorf("invalid cookie JSON: %w", err)
+	}
+
+	var cookies []config.Cookie
+	for i, c := range exported {
+		if c.Name == "" {
+			return nil, fmt.Errorf("cookie %d: missing name", i+1)
+		}
+
+		secure, httpOnly := c.Secure, c.HTTPOnly
+		cookies = append(cookies, config.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  int64(c.ExpirationDate),
+			SameSite: sameSiteFromExtension(c.SameSite),
+			Secure:   &secure,
+			HTTPOnly: &httpOnly,
+		})
+	}
+
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("no cookies found")
+	}
+	return cookies, nil
+}
+
+// sameSiteFromExtension converts the browser extension API's SameSite values
+// to the cookie attribute names used in config
+func sameSiteFromExtension(sameSite string) string {
+	switch strings.ToLower(sameSite) {
+	case "no_restriction", "none":
+		return "None"
+	case "lax":
+		return "Lax"
+	case "strict":
+		return "Strict"
+	default:
+		return ""
+	}
+}
+
+// FilterDomain keeps cookies whose domain matches or is a subdomain of domain,
+// for exports that include cookies from every site
+func FilterDomain(cookies []config.Cookie, domain string) []config.Cookie {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+
+	var filtered []config.Cookie
+	for _, cookie := range cookies {
+		host := strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			filtered = append(filtered, cookie)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,87 @@
+package cookiefile
+
+import (
+	"testing"
+)
+
+const netscapeFile = `# Netscape HTTP Cookie File
+# https://curl.se/docs/http-cookies.html
+
+.netflix.com	TRUE	/	TRUE	1767225600	NetflixId	v%3D2%26ct%3Dabc
+#HttpOnly_.netflix.com	TRUE	/	TRUE	0	SecureNetflixId	v%3D2%26mac%3Ddef
+.google.com	TRUE	/	FALSE	1767225600	NID	xyz
+`
+
+func TestParseNetscape(t *testing.T) {
+	cookies, format, err := Parse([]byte(netscapeFile))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if format != FormatNetscape {
+		t.Errorf("Expected netscape format, got %q", format)
+	}
+	if len(cookies) != 3 {
+		t.Fatalf("Expected 3 cookies, got %d", len(cookies))
+	}
+
+	first := cookies[0]
+	if first.Name != "NetflixId" || first.Value != "v%3D2%26ct%3Dabc" || first.Domain != ".netflix.com" || first.Expires != 1767225600 {
+		t.Errorf("Unexpected cookie: %+v", first)
+	}
+	if first.HTTPOnly == nil || *first.HTTPOnly {
+		t.Errorf("Expected NetflixId not to be HttpOnly")
+	}
+	if second := cookies[1]; second.HTTPOnly == nil || !*second.HTTPOnly || second.Expires != 0 {
+		t.Errorf("Expected an HttpOnly session cookie, got %+v", second)
+	}
+	if third := cookies[2]; third.Secure == nil || *third.Secure {
+		t.Errorf("Expected NID not to be secure")
+	}
+}
+
+func TestParseNetscapeInvalid(t *testing.T) {
+	if _, err := ParseNetscape([]byte(".netflix.com\tTRUE\t/\n")); err == nil {
+		t.Error("Expected error for a short line")
+	}
+	if _, err := ParseNetscape([]byte("# only comments\n")); err == nil {
+		t.Error("Expected error for a file without cookies")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	data := `[
+		{"domain": ".netflix.com", "expirationDate": 1767225600.5, "hostOnly": false, "httpOnly": true,
+		 "name": "NetflixId", "path": "/", "sameSite": "no_restriction", "secure": true, "session": false, "value": "abc"},
+		{"domain": "www.netflix.com", "httpOnly": false, "name": "profilesNewSession", "path": "/",
+		 "sameSite": "unspecified", "secure": false, "session": true, "value": "0"}
+	]`
+
+	cookies, format, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if format != FormatJSON || len(cookies) != 2 {
+		t.Fatalf("Expected 2 JSON cookies, got %d (%s)", len(cookies), format)
+	}
+	if c := cookies[0]; c.SameSite != "None" || c.Expires != 1767225600 || !*c.HTTPOnly || !*c.Secure {
+		t.Errorf("Unexpected cookie: %+v", c)
+	}
+	if c := cookies[1]; c.SameSite != "" || c.Expires != 0 {
+		t.Errorf("Expected a session cookie without SameSite, got %+v", c)
+	}
+}
+
+func TestFilterDomain(t *testing.T) {
+	cookies, _, err := Parse([]byte(netscapeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filtered := FilterDomain(cookies, "netflix.com")
+	if len(filtered) != 2 {
+		t.Errorf("Expected 2 Netflix cookies, got %d", len(filtered))
+	}
+	if len(FilterDomain(cookies, "flix.com")) != 0 {
+		t.Error("Expected no match on a partial domain")
+	}
+}
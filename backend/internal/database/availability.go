@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetTitleAvailability returns the last availability check of a title on a
+// service in a region, or nil if it hasn't been checked yet
+func (db *DB) GetTitleAvailability(title string, serviceID int64, region string) (*TitleAvailability, error) {
+	rows, err := db.queryAvailability(`WHERE ta.title = ? AND ta.service_id = ? AND ta.region = ?`, title, serviceID, region)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// SetTitleAvailability records a title's availability on a service
+func (db *DB) SetTitleAvailability(a *TitleAvailability) error {
+	a.Checked = time.Now()
+	_, err := db.Exec(`
+		INSERT INTO title_availability (title, service_id, region, tmdb_id, available, gone_since, checked)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(title, service_id, region) DO UPDATE SET
+			tmdb_id = excluded.tmdb_id,
+			available = excluded.available,
+			gone_since = excluded.gone_since,
+			checked = excluded.checked
+	`, a.Title, a.ServiceID, a.Region, a.TMDBID, a.Available, a.GoneSince, a.Checked)
+	return err
+}
+
+// GetLeavingTitles returns titles that have gone from the service they were
+// watched on in a region since a time, most recently gone first
+func (db *DB) GetLeavingTitles(region string, since time.Time) ([]TitleAvailability, error) {
+	return db.queryAvailability(`
+		WHERE ta.region = ?
+		  AND ta.available = FALSE
+		  AND ta.gone_since >= ?
+		ORDER BY ta.gone_since DESC, ta.title`, region, since)
+}
+
+// queryAvailability returns availability checks matching a WHERE clause on
+// title_availability "ta", with their service names
+func (db *DB) queryAvailability(where string, args ...interface{}) ([]TitleAvailability, error) {
+	rows, err := db.Query(`
+		SELECT ta.title, ta.service_id, s.name, ta.region, ta.tmdb_id, ta.available, ta.gone_since, ta.checked
+		FROM title_availability ta
+		JOIN services s ON ta.service_id = s.id
+		`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []TitleAvailability{}
+	for rows.Next() {
+		var a TitleAvailability
+		var goneSince sql.NullTime
+		if err := rows.Scan(&a.Title, &a.ServiceID, &a.ServiceName, &a.Region, &a.TMDBID, &a.Available, &goneSince, &a.Checked); err != nil {
+			return nil, err
+		}
+		if goneSince.Valid {
+			a.GoneSince = &goneSince.Time
+		}
+		titles = append(titles, a)
+	}
+
+	return titles, rows.Err()
+}
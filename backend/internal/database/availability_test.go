@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTitleAvailability(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	if a, err := db.GetTitleAvailability("The Office", netflix.ID, "US"); err != nil || a != nil {
+		t.Fatalf("Expected no availability, got %+v (%v)", a, err)
+	}
+
+	if err := db.SetTitleAvailability(&TitleAvailability{Title: "The Office", ServiceID: netflix.ID, Region: "US", TMDBID: 2316, Available: true}); err != nil {
+		t.Fatalf("Failed to set availability: %v", err)
+	}
+	gone := time.Now().Add(-time.Hour)
+	if err := db.SetTitleAvailability(&TitleAvailability{Title: "The Office", ServiceID: netflix.ID, Region: "US", TMDBID: 2316, GoneSince: &gone}); err != nil {
+		t.Fatalf("Failed to update availability: %v", err)
+	}
+	// A title never seen on the service isn't leaving it
+	if err := db.SetTitleAvailability(&TitleAvailability{Title: "Friends", ServiceID: netflix.ID, Region: "US", TMDBID: 1668}); err != nil {
+		t.Fatalf("Failed to set availability: %v", err)
+	}
+
+	a, err := db.GetTitleAvailability("the office", netflix.ID, "US")
+	if err != nil || a == nil || a.Available || a.GoneSince == nil || a.ServiceName != "Netflix" {
+		t.Fatalf("Expected The Office gone from Netflix, got %+v (%v)", a, err)
+	}
+
+	leaving, err := db.GetLeavingTitles("US", time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("Failed to get leaving titles: %v", err)
+	}
+	if len(leaving) != 1 || leaving[0].Title != "The Office" {
+		t.Errorf("Expected only The Office, got %+v", leaving)
+	}
+	if leaving, _ := db.GetLeavingTitles("GB", time.Now().AddDate(0, 0, -1)); len(leaving) != 0 {
+		t.Errorf("Expected nothing leaving in GB, got %+v", leaving)
+	}
+}
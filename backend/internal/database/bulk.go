@@ -0,0 +1,231 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// likeEscaper escapes LIKE wildcards so only "*" in title patterns is special
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// HistoryFilter selects watch history rows for a bulk edit. Zero values match everything.
+type HistoryFilter struct {
+	TitlePattern    string // Case-insensitive, "*" matches any text
+	ServiceID       int64
+	StartDate       time.Time // Inclusive
+	EndDate         time.Time // Exclusive
+	DurationMinutes *int      // Current duration, e.g. to find every 45-minute placeholder
+}
+
+// HistoryUpdate lists the fields a bulk edit changes. Nil or zero fields are left alone.
+type HistoryUpdate struct {
+	ServiceID       int64
+	DurationMinutes *int
+	Genre           *string
+}
+
+// BulkEditResult summarizes a bulk edit
+type BulkEditResult struct {
+	Matched           int64          `json:"matched"`
+	Updated           int64          `json:"updated"`
+	DuplicatesRemoved int64          `json:"duplicates_removed"` // Rows dropped because the target service already had them
+	DryRun            bool           `json:"dry_run"`
+	Sample            []WatchHistory `json:"sample"`
+}
+
+// bulkEditSampleSize is how many matching rows are returned for review
+const bulkEditSampleSize = 10
+
+// BulkDeleteResult summarizes a bulk delete
+type BulkDeleteResult struct {
+	Matched int64          `json:"matched"`
+	Deleted int64          `json:"deleted"`
+	DryRun  bool           `json:"dry_run"`
+	Sample  []WatchHistory `json:"sample"`
+}
+
+// IsEmpty reports whether the filter would match all history
+func (f HistoryFilter) IsEmpty() bool {
+	return f.TitlePattern == "" && f.ServiceID == 0 && f.StartDate.IsZero() && f.EndDate.IsZero() && f.DurationMinutes == nil
+}
+
+// IsEmpty reports whether the update changes nothing
+func (u HistoryUpdate) IsEmpty() bool {
+	return u.ServiceID == 0 && u.DurationMinutes == nil && u.Genre == nil
+}
+
+// where builds the SQL condition and arguments for the filter, matching
+// titles case-insensitively in the dialect d
+func (f HistoryFilter) where(d dialect) (string, []interface{}) {
+	conds := []string{"1 = 1"}
+	var args []interface{}
+
+	if f.TitlePattern != "" {
+		conds = append(conds, `title `+d.like()+` ? ESCAPE '\'`)
+		args = append(args, strings.ReplaceAll(likeEscaper.Replace(f.TitlePattern), "*", "%"))
+	}
+	if f.ServiceID != 0 {
+		conds = append(conds, "service_id = ?")
+		args = append(args, f.ServiceID)
+	}
+	if !f.StartDate.IsZero() {
+		conds = append(conds, "watched_at >= ?")
+		args = append(args, f.StartDate)
+	}
+	if !f.EndDate.IsZero() {
+		conds = append(conds, "watched_at < ?")
+		args = append(args, f.EndDate)
+	}
+	if f.DurationMinutes != nil {
+		conds = append(conds, "duration_minutes = ?")
+		args = append(args, *f.DurationMinutes)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// BulkEditHistory applies an update to every watch history row matching the
+// filter. With dryRun set it only reports what would change.
+func (db *DB) BulkEditHistory(filter HistoryFilter, update HistoryUpdate, dryRun bool) (*BulkEditResult, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("filter must not match all history")
+	}
+	if update.IsEmpty() {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	where, args := filter.where(db.dialect)
+	result := &BulkEditResult{DryRun: dryRun}
+
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM watch_history WHERE `+where, args...).Scan(&result.Matched); err != nil {
+		return nil, err
+	}
+
+	if result.Sample, err = bulkSample(tx, where, args); err != nil {
+		return nil, err
+	}
+
+	if dryRun || result.Matched == 0 {
+		return result, nil
+	}
+
+	// Moving rows to another service would collide with history it already
+	// has for the same title and time, so drop those rows first
+	if update.ServiceID != 0 {
+		res, err := tx.Exec(`
+			DELETE FROM watch_history
+			WHERE `+where+`
+			  AND service_id != ?
+			  AND EXISTS (
+				SELECT 1 FROM watch_history t
+				WHERE t.service_id = ?
+				  AND t.profile_id = watch_history.profile_id
+				  AND t.title = watch_history.title
+				  AND t.watched_at = watch_history.watched_at
+			  )
+		`, append(args, update.ServiceID, update.ServiceID)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove duplicate history: %w", err)
+		}
+		result.DuplicatesRemoved, _ = res.RowsAffected()
+	}
+
+	var sets []string
+	var setArgs []interface{}
+	if update.ServiceID != 0 {
+		sets = append(sets, "service_id = ?")
+		setArgs = append(setArgs, update.ServiceID)
+	}
+	if update.DurationMinutes != nil {
+		sets = append(sets, "duration_minutes = ?")
+		setArgs = append(setArgs, *update.DurationMinutes)
+	}
+	if update.Genre != nil {
+		sets = append(sets, "genre = ?")
+		setArgs = append(setArgs, *update.Genre)
+	}
+
+	res, err := tx.Exec(`UPDATE watch_history SET `+strings.Join(sets, ", ")+` WHERE `+where, append(setArgs, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update history: %w", err)
+	}
+	result.Updated, _ = res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BulkDeleteHistory deletes every watch history row matching the filter,
+// e.g. a window of entries a broken scrape stored with wrong dates. With
+// dryRun set it only reports what would be deleted.
+func (db *DB) BulkDeleteHistory(filter HistoryFilter, dryRun bool) (*BulkDeleteResult, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("filter must not match all history")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	where, args := filter.where(db.dialect)
+	result := &BulkDeleteResult{DryRun: dryRun}
+
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM watch_history WHERE `+where, args...).Scan(&result.Matched); err != nil {
+		return nil, err
+	}
+	if result.Sample, err = bulkSample(tx, where, args); err != nil {
+		return nil, err
+	}
+
+	if dryRun || result.Matched == 0 {
+		return result, nil
+	}
+
+	res, err := tx.Exec(`DELETE FROM watch_history WHERE `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete history: %w", err)
+	}
+	result.Deleted, _ = res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// bulkSample returns the most recent rows matching a bulk operation's
+// condition, for review
+func bulkSample(tx *Tx, where string, args []interface{}) ([]WatchHistory, error) {
+	rows, err := tx.Query(`
+		SELECT id, service_id, title, COALESCE(episode_info, ''), duration_minutes, watched_at, COALESCE(genre, '')
+		FROM watch_history
+		WHERE `+where+`
+		ORDER BY watched_at DESC
+		LIMIT ?
+	`, append(args, bulkEditSampleSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sample := []WatchHistory{}
+	for rows.Next() {
+		var wh WatchHistory
+		if err := rows.Scan(&wh.ID, &wh.ServiceID, &wh.Title, &wh.EpisodeInfo, &wh.DurationMinutes, &wh.WatchedAt, &wh.Genre); err != nil {
+			return nil, err
+		}
+		sample = append(sample, wh)
+	}
+	return sample, rows.Err()
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBulkEditHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	youtube, _ := db.GetServiceByName("YouTube TV")
+	now := time.Now()
+	for i, title := range []string{"NBC Nightly News", "CBS News", "Jeopardy!"} {
+		db.InsertWatchHistory(&WatchHistory{ServiceID: youtube.ID, Title: title, DurationMinutes: 45, WatchedAt: now.Add(-time.Duration(i) * time.Hour)})
+	}
+
+	fortyFive := 45
+	thirty := 30
+	filter := HistoryFilter{TitlePattern: "*news*", ServiceID: youtube.ID, DurationMinutes: &fortyFive}
+	update := HistoryUpdate{DurationMinutes: &thirty}
+
+	result, err := db.BulkEditHistory(filter, update, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if result.Matched != 2 || result.Updated != 0 || len(result.Sample) != 2 {
+		t.Errorf("Unexpected dry run result: %+v", result)
+	}
+
+	result, err = db.BulkEditHistory(filter, update, false)
+	if err != nil {
+		t.Fatalf("Bulk edit failed: %v", err)
+	}
+	if result.Updated != 2 {
+		t.Errorf("Expected 2 rows updated, got %d", result.Updated)
+	}
+
+	var total int
+	db.QueryRow(`SELECT SUM(duration_minutes) FROM watch_history`).Scan(&total)
+	if total != 105 {
+		t.Errorf("Expected 105 total minutes after edit, got %d", total)
+	}
+}
+
+func TestBulkEditHistoryReassignService(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	youtube, _ := db.GetServiceByName("YouTube TV")
+	peacock, _ := db.GetServiceByName("Peacock")
+	watchedAt := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&WatchHistory{ServiceID: youtube.ID, Title: "The Office", DurationMinutes: 22, WatchedAt: watchedAt})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: youtube.ID, Title: "The Office", DurationMinutes: 22, WatchedAt: watchedAt.Add(time.Hour)})
+	// Peacock already has the first episode
+	db.InsertWatchHistory(&WatchHistory{ServiceID: peacock.ID, Title: "The Office", DurationMinutes: 22, WatchedAt: watchedAt})
+
+	result, err := db.BulkEditHistory(HistoryFilter{TitlePattern: "The Office", ServiceID: youtube.ID}, HistoryUpdate{ServiceID: peacock.ID}, false)
+	if err != nil {
+		t.Fatalf("Bulk edit failed: %v", err)
+	}
+	if result.DuplicatesRemoved != 1 || result.Updated != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM watch_history WHERE service_id = ?`, peacock.ID).Scan(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 Peacock rows, got %d", count)
+	}
+}
+
+func TestBulkEditHistoryRequiresFilterAndUpdate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	genre := "News"
+	if _, err := db.BulkEditHistory(HistoryFilter{}, HistoryUpdate{Genre: &genre}, true); err == nil {
+		t.Error("Expected error for empty filter")
+	}
+	if _, err := db.BulkEditHistory(HistoryFilter{TitlePattern: "*"}, HistoryUpdate{}, true); err == nil {
+		t.Error("Expected error for empty update")
+	}
+}
+
+func TestBulkDeleteHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		db.InsertWatchHistory(&WatchHistory{ServiceID: netflix.ID, Title: "Wednesday", DurationMinutes: 50, WatchedAt: start.AddDate(0, 0, i)})
+	}
+
+	filter := HistoryFilter{ServiceID: netflix.ID, StartDate: start.AddDate(0, 0, 1), EndDate: start.AddDate(0, 0, 3)}
+	result, err := db.BulkDeleteHistory(filter, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if result.Matched != 2 || result.Deleted != 0 {
+		t.Errorf("Unexpected dry run result: %+v", result)
+	}
+
+	result, err = db.BulkDeleteHistory(filter, false)
+	if err != nil {
+		t.Fatalf("Bulk delete failed: %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("Expected 2 rows deleted, got %d", result.Deleted)
+	}
+	// Deletions reach the change feed like single deletes
+	deleted, _ := db.GetHistoryDeletedSince(start)
+	if len(deleted) != 2 {
+		t.Errorf("Expected 2 recorded deletions, got %v", deleted)
+	}
+
+	if _, err := db.BulkDeleteHistory(HistoryFilter{}, false); err == nil {
+		t.Error("Expected an empty filter to be rejected")
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import "time"
+
+// syncTimeFormat matches how SQLite's CURRENT_TIMESTAMP stores updated times
+const syncTimeFormat = "2006-01-02 15:04:05"
+
+// GetHistoryChangedSince returns history entries inserted or modified at or
+// after a time, oldest change first
+func (db *DB) GetHistoryChangedSince(since time.Time) ([]WatchHistory, error) {
+	rows, err := db.Query(`
+		SELECT `+watchHistoryColumns+`
+		FROM `+db.history()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE wh.updated >= ?
+		  AND `+notIgnoredClause+`
+		ORDER BY wh.updated, wh.id
+	`, since.UTC().Format(syncTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWatchHistory(rows)
+}
+
+// GetHistoryDeletedSince returns the IDs of history entries deleted at or after a time
+func (db *DB) GetHistoryDeletedSince(since time.Time) ([]int64, error) {
+	rows, err := db.Query(`
+		SELECT history_id
+		FROM watch_history_deletions
+		WHERE deleted >= ?
+		ORDER BY deleted, history_id
+	`, since.UTC().Format(syncTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetServicesChangedSince returns services added or modified at or after a time
+func (db *DB) GetServicesChangedSince(since time.Time) ([]Service, error) {
+	rows, err := db.Query(`
+		SELECT id, name, service_key, color, logo_url, enabled, archived, created, updated
+		FROM services
+		WHERE updated >= ?
+		ORDER BY name
+	`, since.UTC().Format(syncTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	services := []Service{}
+	for rows.Next() {
+		var svc Service
+		if err := rows.Scan(&svc.ID, &svc.Name, &svc.Key, &svc.Color, &svc.LogoURL, &svc.Enabled, &svc.Archived, &svc.Created, &svc.Updated); err != nil {
+			return nil, err
+		}
+		services = append(services, svc)
+	}
+
+	return services, rows.Err()
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChangesSince(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	watchedAt := time.Now().Add(-48 * time.Hour)
+	old := &WatchHistory{ServiceID: service.ID, Title: "Old Show", DurationMinutes: 30, WatchedAt: watchedAt}
+	gone := &WatchHistory{ServiceID: service.ID, Title: "Deleted Show", DurationMinutes: 30, WatchedAt: watchedAt}
+	db.InsertWatchHistory(old)
+	db.InsertWatchHistory(gone)
+
+	// Pretend the initial sync happened long ago
+	db.Exec(`UPDATE watch_history SET updated = '2020-01-01 00:00:00'`)
+	db.Exec(`UPDATE services SET updated = '2020-01-01 00:00:00'`)
+	since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	changed, err := db.GetHistoryChangedSince(since)
+	if err != nil {
+		t.Fatalf("Failed to get changes: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("Expected no changes, got %+v", changed)
+	}
+
+	// Re-scraping an unchanged row isn't a change
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Old Show", DurationMinutes: 30, WatchedAt: watchedAt})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "New Show", DurationMinutes: 45, WatchedAt: time.Now()})
+	db.UpdateWatchHistoryRating(old.ID, 4)
+	db.Exec(`DELETE FROM watch_history WHERE id = ?`, gone.ID)
+	db.UpdateServiceEnabled(service.ID, true)
+
+	changed, _ = db.GetHistoryChangedSince(since)
+	if len(changed) != 2 {
+		t.Fatalf("Expected the rated and new rows, got %+v", changed)
+	}
+	titles := map[string]bool{changed[0].Title: true, changed[1].Title: true}
+	if !titles["Old Show"] || !titles["New Show"] {
+		t.Errorf("Unexpected changed rows: %+v", changed)
+	}
+
+	deleted, err := db.GetHistoryDeletedSince(since)
+	if err != nil {
+		t.Fatalf("Failed to get deletions: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != gone.ID {
+		t.Errorf("Expected deleted ID %d, got %v", gone.ID, deleted)
+	}
+
+	services, err := db.GetServicesChangedSince(since)
+	if err != nil {
+		t.Fatalf("Failed to get changed services: %v", err)
+	}
+	if len(services) != 1 || services[0].ID != service.ID || !services[0].Enabled {
+		t.Errorf("Expected the enabled service, got %+v", services)
+	}
+}
+
+func TestUpdatedTimestamps(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	if service.Updated.IsZero() {
+		t.Error("Expected seeded service to have an updated time")
+	}
+
+	db.InsertScraperRun(&ScraperRun{ServiceID: service.ID, RanAt: time.Now(), Status: "success"})
+	runs, err := db.GetLatestScraperRuns()
+	if err != nil {
+		t.Fatalf("Failed to get scraper runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Updated.IsZero() {
+		t.Errorf("Expected scraper run with an updated time, got %+v", runs)
+	}
+
+	db.Exec(`UPDATE services SET updated = '2020-01-01 00:00:00' WHERE id = ?`, service.ID)
+	db.UpdateServiceEnabled(service.ID, true)
+	service, _ = db.GetServiceByID(service.ID)
+	if service.Updated.Year() == 2020 {
+		t.Error("Expected updated time to be bumped by a write")
+	}
+}
+
+func TestHistoryUpdatedTracksLaterColumns(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	watchedAt := time.Now()
+	wh := &WatchHistory{ServiceID: service.ID, Title: "Dark", DurationMinutes: 50, WatchedAt: watchedAt,
+		Raw: &RawPayload{Title: "Dark", Date: "Today", ScrapedAt: watchedAt}}
+	if err := db.InsertWatchHistory(wh); err != nil {
+		t.Fatalf("Failed to insert entry: %v", err)
+	}
+
+	// Re-scraping the entry later leaves it unchanged
+	db.Exec(`UPDATE watch_history SET updated = '2020-01-01 00:00:00' WHERE id = ?`, wh.ID)
+	rescraped := &WatchHistory{ServiceID: service.ID, Title: "Dark", DurationMinutes: 50, WatchedAt: watchedAt,
+		Raw: &RawPayload{Title: "Dark", Date: "Yesterday", ScrapedAt: watchedAt.AddDate(0, 0, 1)}}
+	if err := db.InsertWatchHistory(rescraped); err != nil {
+		t.Fatalf("Failed to re-insert entry: %v", err)
+	}
+	var updated time.Time
+	db.QueryRow(`SELECT updated FROM watch_history WHERE id = ?`, wh.ID).Scan(&updated)
+	if updated.Year() != 2020 {
+		t.Errorf("Expected a re-scrape not to bump the updated time, got %v", updated)
+	}
+
+	for _, change := range []string{
+		`UPDATE watch_history SET profile_id = 3 WHERE id = ?`,
+		`UPDATE watch_history SET confidence = 'low' WHERE id = ?`,
+		`UPDATE watch_history SET raw_payload = '{"title":"Dark"}' WHERE id = ?`,
+	} {
+		db.Exec(`UPDATE watch_history SET updated = '2020-01-01 00:00:00' WHERE id = ?`, wh.ID)
+		if _, err := db.Exec(change, wh.ID); err != nil {
+			t.Fatalf("Failed to update entry: %v", err)
+		}
+		db.QueryRow(`SELECT updated FROM watch_history WHERE id = ?`, wh.ID).Scan(&updated)
+		if updated.Year() == 2020 {
+			t.Errorf("Expected updated time to be bumped by %q", change)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// watchTimeSource stands in for watch_history in stats queries, adding the
+// per-day rollups that compaction leaves in place of older entries. Each row
+// carries the number of entries it counts for; rollups have no device.
+const watchTimeSource = `(
+			SELECT service_id, title, duration_minutes, watched_at, genre, media_kind, device, 1 AS entries
+			FROM watch_history
+			UNION ALL
+			SELECT service_id, title, duration_minutes, watched_at, genre, media_kind, '' AS device, entries
+			FROM watch_history_rollups
+		)`
+
+// CompactResult summarizes a compaction
+type CompactResult struct {
+	EntriesCompacted int64 `json:"entries_compacted"`
+	Rollups          int64 `json:"rollups"`
+}
+
+// CompactHistory rolls entries watched before the cutoff up into one row per
+// service, day and title, then deletes them. Entries with a rating or notes
+// are kept, since a rollup can't hold them.
+func (db *DB) CompactHistory(before time.Time) (*CompactResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const compactable = `watched_at < ? AND COALESCE(notes, '') = '' AND COALESCE(rating, 0) = 0`
+
+	res, err := tx.Exec(`
+		INSERT INTO watch_history_rollups (service_id, day, title, watched_at, duration_minutes, entries, genre, media_kind)
+		SELECT service_id, `+db.dialect.date("watched_at")+`, title, MIN(watched_at), SUM(duration_minutes), COUNT(*),
+			MAX(COALESCE(genre, '')), COALESCE(NULLIF(MAX(media_kind), ''), 'video')
+		FROM watch_history
+		WHERE `+compactable+`
+		GROUP BY service_id, `+db.dialect.date("watched_at")+`, title
+		ON CONFLICT(service_id, day, title) DO UPDATE SET
+			watched_at = CASE WHEN excluded.watched_at < watch_history_rollups.watched_at
+				THEN excluded.watched_at ELSE watch_history_rollups.watched_at END,
+			duration_minutes = watch_history_rollups.duration_minutes + excluded.duration_minutes,
+			entries = watch_history_rollups.entries + excluded.entries
+	`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll up history: %w", err)
+	}
+	result := &CompactResult{}
+	result.Rollups, _ = res.RowsAffected()
+
+	res, err = tx.Exec(`DELETE FROM watch_history WHERE `+compactable, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove compacted history: %w", err)
+	}
+	result.EntriesCompacted, _ = res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DetailSince returns the first day (YYYY-MM-DD) after the newest compacted
+// day, from which every entry keeps its detail, or "" if nothing has been
+// compacted. Stats that need per-entry detail only cover history from then on.
+func (db *DB) DetailSince() (string, error) {
+	var newest sql.NullString
+	if err := db.QueryRow(`SELECT MAX(day) FROM watch_history_rollups`).Scan(&newest); err != nil || !newest.Valid {
+		return "", err
+	}
+	day, err := time.Parse("2006-01-02", newest.String)
+	if err != nil {
+		return "", err
+	}
+	return day.AddDate(0, 0, 1).Format("2006-01-02"), nil
+}
+
+// isRolledUp reports whether a title's day on a service has already been
+// compacted, so a re-scrape doesn't count it twice
+func isRolledUp(q rowQuerier, serviceID int64, title string, watchedAt time.Time) (bool, error) {
+	var exists bool
+	err := q.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM watch_history_rollups
+			WHERE service_id = ? AND title = ? AND day = `+q.sqlDialect().date("?")+`
+		)
+	`, serviceID, title, watchedAt).Scan(&exists)
+	return exists, err
+}
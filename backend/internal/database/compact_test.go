@@ -0,0 +1,129 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompactHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	old := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	recent := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, wh := range []WatchHistory{
+		{Title: "Dark", EpisodeInfo: "S01E01", DurationMinutes: 50, WatchedAt: old, Genre: "Drama"},
+		{Title: "Dark", EpisodeInfo: "S01E02", DurationMinutes: 45, WatchedAt: old.Add(time.Hour), Genre: "Drama"},
+		{Title: "Heat", DurationMinutes: 170, WatchedAt: old},
+		{Title: "Dark", EpisodeInfo: "S02E01", DurationMinutes: 55, WatchedAt: recent, Genre: "Drama"},
+	} {
+		wh.ServiceID = service.ID
+		if err := db.InsertWatchHistory(&wh); err != nil {
+			t.Fatalf("Failed to insert entry: %v", err)
+		}
+	}
+	db.Exec(`UPDATE watch_history SET rating = 5 WHERE title = 'Heat'`)
+
+	before, _ := db.GetServiceStats(old.AddDate(0, -1, 0), recent.AddDate(0, 1, 0))
+
+	result, err := db.CompactHistory(cutoff)
+	if err != nil {
+		t.Fatalf("CompactHistory failed: %v", err)
+	}
+	if result.EntriesCompacted != 2 || result.Rollups != 1 {
+		t.Errorf("Expected 2 entries in 1 rollup, got %+v", result)
+	}
+
+	// Rated entries keep their detail
+	history, _ := db.GetWatchHistory(service.ID, old.AddDate(0, -1, 0), recent.AddDate(0, 1, 0), 100, 0)
+	if len(history) != 2 {
+		t.Errorf("Expected the rated and recent entries to remain, got %d", len(history))
+	}
+
+	// Stats combine detail and rollups as if nothing changed
+	after, _ := db.GetServiceStats(old.AddDate(0, -1, 0), recent.AddDate(0, 1, 0))
+	if after[0].TotalMinutes != before[0].TotalMinutes || after[0].TotalShows != before[0].TotalShows {
+		t.Errorf("Expected stats %d min / %d shows to be unchanged, got %d / %d",
+			before[0].TotalMinutes, before[0].TotalShows, after[0].TotalMinutes, after[0].TotalShows)
+	}
+	titles, _ := db.GetTopTitles(old.AddDate(0, -1, 0), recent.AddDate(0, 1, 0), 10)
+	if len(titles) != 2 || titles[0].Title != "Heat" || titles[1].TotalMinutes != 150 || titles[1].WatchCount != 3 {
+		t.Errorf("Unexpected top titles after compaction: %+v", titles)
+	}
+	genres, _ := db.GetGenreStats(old.AddDate(0, -1, 0), recent.AddDate(0, 1, 0))
+	if len(genres) != 1 || genres[0].TotalMinutes != 150 {
+		t.Errorf("Unexpected genre stats after compaction: %+v", genres)
+	}
+
+	// Re-scraping a compacted day doesn't count it twice
+	if err := db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Dark", EpisodeInfo: "S01E01", DurationMinutes: 50, WatchedAt: old}); err != nil {
+		t.Fatalf("Failed to re-insert entry: %v", err)
+	}
+	if totals, _, _ := db.GetWatchTotals("", "Dark", old.AddDate(0, -1, 0), recent.AddDate(0, 1, 0)); totals != 150 {
+		t.Errorf("Expected Dark total to stay 150, got %d", totals)
+	}
+}
+
+func TestStatsIncludeCompactedDays(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	old := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	recent := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	start, end := old.AddDate(0, -1, 0), recent.AddDate(0, 1, 0)
+
+	for _, wh := range []WatchHistory{
+		{Title: "Dark", EpisodeInfo: "S01E01", DurationMinutes: 50, WatchedAt: old, Device: "Living Room TV"},
+		{Title: "Dark", EpisodeInfo: "S01E02", DurationMinutes: 45, WatchedAt: old.Add(time.Hour), Device: "Living Room TV"},
+		{Title: "Dark", EpisodeInfo: "S01E01", DurationMinutes: 50, WatchedAt: recent, Device: "iPad"},
+	} {
+		wh.ServiceID = service.ID
+		if err := db.InsertWatchHistory(&wh); err != nil {
+			t.Fatalf("Failed to insert entry: %v", err)
+		}
+	}
+	if since, _ := db.DetailSince(); since != "" {
+		t.Errorf("Expected no detail window before compacting, got %q", since)
+	}
+	if _, err := db.CompactHistory(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("CompactHistory failed: %v", err)
+	}
+
+	// Goals and streaks see the compacted day
+	daily, _ := db.GetDailyScreenTime(start, end)
+	if daily["2024-03-01"] != 95 || daily["2025-03-01"] != 50 {
+		t.Errorf("Expected the compacted day in daily screen time, got %v", daily)
+	}
+
+	titles, _ := db.GetServiceTitleStats(start, end)
+	if len(titles) != 1 || titles[0].TotalMinutes != 145 || titles[0].WatchCount != 3 {
+		t.Errorf("Expected compacted entries in service title stats, got %+v", titles)
+	}
+
+	// The rollup stands in for the day's entries as one starting at the first
+	entries, _ := db.GetWatchEntries(start, end)
+	if len(entries) != 2 || entries[0].DurationMinutes != 95 || !entries[0].WatchedAt.Equal(old) {
+		t.Errorf("Expected the rollup as one entry, got %+v", entries)
+	}
+
+	devices, _ := db.GetDeviceStats(0, start, end)
+	if len(devices) != 2 || devices[0].Device != "Unknown" || devices[0].TotalMinutes != 95 || devices[0].TotalShows != 2 {
+		t.Errorf("Expected compacted time under Unknown, got %+v", devices)
+	}
+
+	// Stats needing episode detail say where it starts
+	rewatches, _ := db.GetRewatchStats(start, end)
+	if rewatches.DetailSince != "2024-03-02" || rewatches.NewMinutes != 50 || rewatches.RewatchCount != 0 {
+		t.Errorf("Expected rewatches from 2024-03-02 only, got %+v", rewatches)
+	}
+	episodes, _ := db.GetWatchedEpisodes(start, end)
+	if len(episodes) != 1 || !episodes[0].FirstWatched.Equal(recent) {
+		t.Errorf("Expected only the detailed episode, got %+v", episodes)
+	}
+}
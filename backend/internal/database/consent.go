@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetServiceConsent returns a service's recorded consent, or nil if consent
+// was never given or withdrawn
+func (db *DB) GetServiceConsent(serviceID int64) (*ServiceConsent, error) {
+	var consent ServiceConsent
+	err := db.QueryRow(`
+		SELECT service_id, accepted, source, updated
+		FROM service_consent
+		WHERE service_id = ?
+	`, serviceID).Scan(&consent.ServiceID, &consent.Accepted, &consent.Source, &consent.Updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// SetServiceConsent records that consent to automate a service was given or
+// withdrawn, and where
+func (db *DB) SetServiceConsent(serviceID int64, accepted bool, source string) (*ServiceConsent, error) {
+	consent := &ServiceConsent{ServiceID: serviceID, Accepted: accepted, Source: source, Updated: time.Now()}
+	_, err := db.Exec(`
+		INSERT INTO service_consent (service_id, accepted, source, updated)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(service_id) DO UPDATE SET
+			accepted = excluded.accepted,
+			source = excluded.source,
+			updated = excluded.updated
+	`, consent.ServiceID, consent.Accepted, consent.Source, consent.Updated)
+	if err != nil {
+		return nil, err
+	}
+	return consent, nil
+}
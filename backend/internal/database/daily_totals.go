@@ -0,0 +1,124 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dailyTotalSource is a table whose rows add up in daily_title_minutes. Its
+// expressions take the row, NEW or OLD, as %[1]s.
+type dailyTotalSource struct {
+	table   string
+	day     string
+	profile string
+	entries string
+	columns []string // Columns a total depends on
+}
+
+// dailyTotalSources are watch history and the rollups compaction moves it into
+func dailyTotalSources(d dialect) []dailyTotalSource {
+	return []dailyTotalSource{
+		{"watch_history", d.date("%[1]s.watched_at"), "%[1]s.profile_id", "1",
+			[]string{"service_id", "profile_id", "title", "duration_minutes", "watched_at"}},
+		{"watch_history_rollups", "%[1]s.day", "0", "%[1]s.entries",
+			[]string{"service_id", "title", "duration_minutes", "day", "entries"}},
+	}
+}
+
+// add returns the trigger statement adding the NEW row to its day's total
+func (s dailyTotalSource) add() string {
+	return s.statement(`INSERT INTO daily_title_minutes (day, service_id, profile_id, title, minutes, entries)
+			VALUES (%[1]s, NEW.service_id, %[2]s, NEW.title, NEW.duration_minutes, %[3]s)
+			ON CONFLICT (day, service_id, profile_id, title) DO UPDATE SET
+				minutes = daily_title_minutes.minutes + excluded.minutes,
+				entries = daily_title_minutes.entries + excluded.entries;`, "NEW")
+}
+
+// remove returns the trigger statements taking the OLD row out of its day's
+// total, deleting the total once it has no entries left
+func (s dailyTotalSource) remove() string {
+	return s.statement(`UPDATE daily_title_minutes
+			SET minutes = minutes - OLD.duration_minutes, entries = entries - %[3]s
+			WHERE day = %[1]s AND service_id = OLD.service_id AND profile_id = %[2]s AND title = OLD.title;
+			DELETE FROM daily_title_minutes
+			WHERE day = %[1]s AND service_id = OLD.service_id AND profile_id = %[2]s AND title = OLD.title
+			  AND entries <= 0;`, "OLD")
+}
+
+func (s dailyTotalSource) statement(stmt, row string) string {
+	expr := func(e string) string {
+		return strings.ReplaceAll(e, "%[1]s", row)
+	}
+	return fmt.Sprintf(stmt, expr(s.day), expr(s.profile), expr(s.entries))
+}
+
+// createDailyTotals adds daily_title_minutes, minutes and entries per day,
+// service, profile and title. Triggers keep it current as history is
+// inserted, changed, merged or compacted, so today's totals can be polled
+// without reading the history itself.
+func createDailyTotals(tx *Tx) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS daily_title_minutes (
+			day DATE NOT NULL,
+			service_id INTEGER NOT NULL,
+			profile_id INTEGER NOT NULL DEFAULT 0,
+			title TEXT NOT NULL,
+			minutes INTEGER NOT NULL,
+			entries INTEGER NOT NULL,
+			PRIMARY KEY (day, service_id, profile_id, title)
+		)`,
+		`INSERT INTO daily_title_minutes (day, service_id, profile_id, title, minutes, entries)
+		SELECT day, service_id, profile_id, title, SUM(minutes), SUM(entries)
+		FROM (
+			SELECT ` + tx.dialect.date("watched_at") + ` AS day, service_id, profile_id, title,
+				duration_minutes AS minutes, 1 AS entries
+			FROM watch_history
+			UNION ALL
+			SELECT day, service_id, 0, title, duration_minutes, entries
+			FROM watch_history_rollups
+		) history
+		GROUP BY day, service_id, profile_id, title`,
+	}
+	return execAll(tx, append(stmts, tx.dialect.dailyTotalTriggers()...))
+}
+
+func dropDailyTotals(tx *Tx) error {
+	return execAll(tx, append(tx.dialect.dropDailyTotalTriggers(), `DROP TABLE IF EXISTS daily_title_minutes`))
+}
+
+// GetDayServiceTotals returns the minutes and entries per enabled service on
+// a day (YYYY-MM-DD) from the daily totals, leaving out ignored titles and
+// services with nothing that day, most watched first
+func (db *DB) GetDayServiceTotals(day string) ([]ServiceStats, error) {
+	profile := ""
+	if db.profileID != 0 {
+		profile = fmt.Sprintf("AND wh.profile_id = %d", db.profileID)
+	}
+
+	rows, err := db.Query(`
+		SELECT s.id, s.name, s.color, s.logo_url, SUM(wh.minutes) AS total_minutes, SUM(wh.entries)
+		FROM daily_title_minutes wh
+		JOIN services s ON s.id = wh.service_id
+		WHERE wh.day = ?
+		  AND s.enabled = TRUE
+		  AND `+notIgnoredClause+`
+		  `+profile+`
+		GROUP BY s.id, s.name, s.color, s.logo_url
+		HAVING SUM(wh.minutes) > 0
+		ORDER BY total_minutes DESC
+	`, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []ServiceStats
+	for rows.Next() {
+		var stat ServiceStats
+		if err := rows.Scan(&stat.ServiceID, &stat.ServiceName, &stat.Color, &stat.LogoURL, &stat.TotalMinutes, &stat.TotalShows); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyTotalsFollowHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hulu, _ := db.GetServiceByName("Hulu")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.UpdateServiceEnabled(hulu.ID, true)
+	day := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	totals := func(db *DB) map[string]int {
+		t.Helper()
+		stats, err := db.GetDayServiceTotals("2025-03-01")
+		if err != nil {
+			t.Fatalf("GetDayServiceTotals failed: %v", err)
+		}
+		minutes := map[string]int{}
+		for _, stat := range stats {
+			minutes[stat.ServiceName] = stat.TotalMinutes
+		}
+		return minutes
+	}
+
+	entries := []WatchHistory{
+		{ServiceID: netflix.ID, Title: "Dark", EpisodeInfo: "S01E01", DurationMinutes: 50, WatchedAt: day},
+		{ServiceID: netflix.ID, Title: "Dark", EpisodeInfo: "S01E02", DurationMinutes: 45, WatchedAt: day.Add(time.Hour)},
+		{ServiceID: netflix.ID, Title: "Heat", DurationMinutes: 170, WatchedAt: day, ProfileID: 7},
+		{ServiceID: hulu.ID, Title: "Shogun", DurationMinutes: 60, WatchedAt: day},
+		{ServiceID: netflix.ID, Title: "Tomorrow", DurationMinutes: 30, WatchedAt: day.AddDate(0, 0, 1)},
+	}
+	for i := range entries {
+		if err := db.InsertWatchHistory(&entries[i]); err != nil {
+			t.Fatalf("Failed to insert entry: %v", err)
+		}
+	}
+	if got := totals(db); got["Netflix"] != 265 || got["Hulu"] != 60 {
+		t.Errorf("Expected 265 Netflix and 60 Hulu minutes, got %v", got)
+	}
+	if got := totals(db.ForProfile(7)); len(got) != 1 || got["Netflix"] != 170 {
+		t.Errorf("Expected only the profile's 170 minutes, got %v", got)
+	}
+
+	// Re-scraping an entry with a new duration replaces its minutes
+	rescraped := entries[0]
+	rescraped.ID, rescraped.DurationMinutes = 0, 55
+	if err := db.InsertWatchHistory(&rescraped); err != nil {
+		t.Fatalf("Failed to re-insert entry: %v", err)
+	}
+	if got := totals(db); got["Netflix"] != 270 {
+		t.Errorf("Expected 270 Netflix minutes after the re-scrape, got %v", got)
+	}
+
+	// Deleted and ignored entries drop out
+	if _, err := db.DeleteWatchHistoryEntries([]int64{entries[1].ID}); err != nil {
+		t.Fatalf("Failed to delete entry: %v", err)
+	}
+	db.InsertIgnoredTitle(&IgnoredTitle{Title: "Heat"})
+	if got := totals(db); got["Netflix"] != 55 {
+		t.Errorf("Expected 55 Netflix minutes, got %v", got)
+	}
+
+	// Compacting the day and merging services keep its total
+	if _, err := db.CompactHistory(day.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("CompactHistory failed: %v", err)
+	}
+	if got := totals(db); got["Netflix"] != 55 || got["Hulu"] != 60 {
+		t.Errorf("Expected compacted minutes kept, got %v", got)
+	}
+	if _, err := db.MergeServices(hulu.ID, netflix.ID); err != nil {
+		t.Fatalf("MergeServices failed: %v", err)
+	}
+	if got := totals(db); len(got) != 1 || got["Netflix"] != 115 {
+		t.Errorf("Expected Hulu's minutes moved to Netflix, got %v", got)
+	}
+
+	// Totals are rebuilt from the history and rollups when added
+	if _, err := db.MigrateDown(21); err != nil {
+		t.Fatalf("MigrateDown failed: %v", err)
+	}
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if got := totals(db); len(got) != 1 || got["Netflix"] != 115 {
+		t.Errorf("Expected the totals rebuilt, got %v", got)
+	}
+}
@@ -5,16 +5,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// DB wraps the SQL database connection
+// DB wraps the SQL database connection, rewriting queries for its dialect
 type DB struct {
 	*sql.DB
+	dialect   dialect
+	profileID int64 // Set by ForProfile to limit history reads to one profile
 }
 
-// New creates a new database connection and runs migrations
+// Tx wraps a transaction, rewriting queries like DB
+type Tx struct {
+	*sql.Tx
+	dialect dialect
+}
+
+// New creates a new SQLite database connection and runs migrations
 func New(dbPath string) (*DB, error) {
 	// Ensure the directory exists
 	dir := filepath.Dir(dbPath)
@@ -28,9 +37,31 @@ func New(dbPath string) (*DB, error) {
 	if dbPath == ":memory:" {
 		connStr = "file::memory:?cache=shared&_loc=auto"
 	} else {
-		connStr = dbPath + "?_loc=auto"
+		// Wait on locks rather than failing, since scrapers write concurrently
+		connStr = dbPath + "?_loc=auto&_busy_timeout=5000"
 	}
-	sqlDB, err := sql.Open("sqlite3", connStr)
+	return open(sqliteDialect{}, connStr)
+}
+
+// Open connects to the database for a driver ("sqlite" or "postgres") and
+// runs migrations. For SQLite the DSN is the database file's path.
+func Open(driver, dsn string) (*DB, error) {
+	d, err := dialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.(sqliteDialect); ok {
+		return New(dsn)
+	}
+	if !slices.Contains(sql.Drivers(), d.driverName()) {
+		return nil, fmt.Errorf("%s support is not compiled in, rebuild with -tags %s", driver, driver)
+	}
+	return open(d, dsn)
+}
+
+// open connects with a dialect's driver and runs migrations
+func open(d dialect, dsn string) (*DB, error) {
+	sqlDB, err := sql.Open(d.driverName(), dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -40,7 +71,7 @@ func New(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	db := &DB{sqlDB}
+	db := &DB{DB: sqlDB, dialect: d}
 
 	// Run migrations
 	if err := db.migrate(); err != nil {
@@ -50,82 +81,70 @@ func New(dbPath string) (*DB, error) {
 	return db, nil
 }
 
-// migrate runs database migrations
-func (db *DB) migrate() error {
-	migrations := []string{
-		`CREATE TABLE IF NOT EXISTS services (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE,
-			color TEXT NOT NULL,
-			logo_url TEXT,
-			enabled BOOLEAN DEFAULT 1,
-			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS watch_history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			service_id INTEGER NOT NULL,
-			title TEXT NOT NULL,
-			duration_minutes INTEGER NOT NULL,
-			watched_at TIMESTAMP NOT NULL,
-			episode_info TEXT,
-			thumbnail_url TEXT,
-			genre TEXT,
-			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (service_id) REFERENCES services(id),
-			UNIQUE(service_id, title, watched_at)
-		)`,
-		`CREATE TABLE IF NOT EXISTS scraper_runs (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			service_id INTEGER NOT NULL,
-			ran_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			status TEXT NOT NULL,
-			error_message TEXT,
-			items_scraped INTEGER DEFAULT 0,
-			FOREIGN KEY (service_id) REFERENCES services(id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_watch_history_service_id ON watch_history(service_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_watch_history_watched_at ON watch_history(watched_at)`,
-		`CREATE INDEX IF NOT EXISTS idx_scraper_runs_service_id ON scraper_runs(service_id)`,
-	}
+// Exec runs a statement written with ? placeholders
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.dialect.rebind(query), args...)
+}
 
-	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
-			return fmt.Errorf("migration failed: %w", err)
-		}
-	}
+// Query runs a query written with ? placeholders
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.dialect.rebind(query), args...)
+}
 
-	// Seed default services
-	if err := db.seedServices(); err != nil {
-		return fmt.Errorf("failed to seed services: %w", err)
+// QueryRow runs a single-row query written with ? placeholders
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.dialect.rebind(query), args...)
+}
+
+// Begin starts a transaction
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
 	}
+	return &Tx{tx, db.dialect}, nil
+}
 
-	return nil
+// Exec runs a statement written with ? placeholders
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.dialect.rebind(query), args...)
 }
 
-// seedServices inserts default streaming services if they don't exist
-func (db *DB) seedServices() error {
-	services := []struct {
-		name    string
-		color   string
-		logoURL string
-	}{
-		{"Netflix", "#E50914", "/logos/netflix.svg"},
-		{"YouTube TV", "#FF0000", "/logos/youtube-tv.svg"},
-		{"Amazon Video", "#00A8E1", "/logos/amazon-video.svg"},
-		{"HBO Max", "#7B3FF2", "/logos/hbo-max.svg"},
-		{"Apple TV+", "#000000", "/logos/apple-tv.svg"},
-		{"Peacock", "#000000", "/logos/peacock.svg"},
-	}
+// Query runs a query written with ? placeholders
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.dialect.rebind(query), args...)
+}
+
+// QueryRow runs a single-row query written with ? placeholders
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.dialect.rebind(query), args...)
+}
 
-	for _, svc := range services {
-		_, err := db.Exec(`
-			INSERT OR IGNORE INTO services (name, color, logo_url, enabled)
-			VALUES (?, ?, ?, 0)
-		`, svc.name, svc.color, svc.logoURL)
-		if err != nil {
-			return err
+// Prepare prepares a statement written with ? placeholders
+func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
+	return tx.Tx.Prepare(tx.dialect.rebind(query))
+}
+
+// sqlDialect returns the dialect queries are rewritten for
+func (db *DB) sqlDialect() dialect { return db.dialect }
+
+// sqlDialect returns the dialect queries are rewritten for
+func (tx *Tx) sqlDialect() dialect { return tx.dialect }
+
+// migrate applies pending schema migrations, which also seed default
+// services, then matches the search index to this build
+func (db *DB) migrate() error {
+	for _, stmt := range db.dialect.setup() {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
 		}
 	}
 
+	if _, err := db.Migrate(); err != nil {
+		return err
+	}
+	if err := db.syncSearchIndex(); err != nil {
+		return fmt.Errorf("failed to sync search index: %w", err)
+	}
 	return nil
 }
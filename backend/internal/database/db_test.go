@@ -36,8 +36,8 @@ func TestMigrateCreatesTablesAndSeeds(t *testing.T) {
 		t.Fatalf("Failed to query services: %v", err)
 	}
 
-	if count != 6 {
-		t.Errorf("Expected 6 seeded services, got %d", count)
+	if count != 16 {
+		t.Errorf("Expected 16 seeded services, got %d", count)
 	}
 
 	// Verify watch_history table exists
@@ -62,8 +62,8 @@ func TestGetAllServices(t *testing.T) {
 		t.Fatalf("Failed to get all services: %v", err)
 	}
 
-	if len(services) != 6 {
-		t.Errorf("Expected 6 services, got %d", len(services))
+	if len(services) != 16 {
+		t.Errorf("Expected 16 services, got %d", len(services))
 	}
 
 	// Verify first service has expected fields
@@ -135,6 +135,28 @@ func TestGetServiceByName(t *testing.T) {
 	}
 }
 
+func TestEnsureService(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, err := db.EnsureService("Netflix (kids)", "#E50914", "")
+	if err != nil {
+		t.Fatalf("Failed to ensure service: %v", err)
+	}
+	if service == nil || !service.Enabled {
+		t.Fatalf("Expected new enabled service, got %+v", service)
+	}
+
+	// Calling again returns the existing service
+	again, err := db.EnsureService("Netflix (kids)", "#000000", "")
+	if err != nil {
+		t.Fatalf("Failed to ensure existing service: %v", err)
+	}
+	if again.ID != service.ID || again.Color != "#E50914" {
+		t.Errorf("Expected existing service to be returned unchanged, got %+v", again)
+	}
+}
+
 func TestInsertAndGetWatchHistory(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -186,6 +208,48 @@ func TestInsertAndGetWatchHistory(t *testing.T) {
 	}
 }
 
+func TestInsertWatchHistoryBatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.InsertTitleAlias(&TitleAlias{Alias: "The Office (U.S.)", Canonical: "The Office"})
+	now := time.Now()
+
+	items := []WatchHistory{
+		{ServiceID: service.ID, Title: "The Office (U.S.)", DurationMinutes: 22, WatchedAt: now},
+		{ServiceID: service.ID, Title: "Stranger Things", DurationMinutes: 50, WatchedAt: now.Add(-time.Hour)},
+	}
+	if err := db.InsertWatchHistoryBatch(items); err != nil {
+		t.Fatalf("Failed to insert batch: %v", err)
+	}
+	if items[0].ID == 0 || items[1].ID == 0 {
+		t.Errorf("Expected IDs set on the items, got %+v", items)
+	}
+	if items[0].Title != "The Office" {
+		t.Errorf("Expected the aliased title stored as canonical, got %q", items[0].Title)
+	}
+
+	// Re-inserting upserts rather than duplicating
+	items[1].DurationMinutes = 55
+	if err := db.InsertWatchHistoryBatch(items[1:]); err != nil {
+		t.Fatalf("Failed to insert batch: %v", err)
+	}
+	history, _ := db.GetWatchHistory(service.ID, now.Add(-2*time.Hour), now.Add(time.Hour), 10, 0)
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(history))
+	}
+	for _, wh := range history {
+		if wh.Title == "Stranger Things" && wh.DurationMinutes != 55 {
+			t.Errorf("Expected the re-inserted entry updated, got %d minutes", wh.DurationMinutes)
+		}
+	}
+
+	if err := db.InsertWatchHistoryBatch(nil); err != nil {
+		t.Errorf("Expected an empty batch to be a no-op, got %v", err)
+	}
+}
+
 func TestInsertWatchHistoryDuplicate(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -418,3 +482,125 @@ func TestUpdateServiceEnabled(t *testing.T) {
 		t.Error("Expected service to be disabled")
 	}
 }
+
+func TestInsertWatchHistoryKeepsDevice(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Test Movie", DurationMinutes: 120, WatchedAt: now, Device: "Living Room TV", Location: "US"})
+
+	// Re-scraping from a source without device info shouldn't erase it
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Test Movie", DurationMinutes: 120, WatchedAt: now})
+
+	history, err := db.GetWatchHistory(service.ID, now.Add(-1*time.Hour), now.Add(1*time.Hour), 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get watch history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 watch history entry, got %d", len(history))
+	}
+	if history[0].Device != "Living Room TV" || history[0].Location != "US" {
+		t.Errorf("Expected device and location to be kept, got %q / %q", history[0].Device, history[0].Location)
+	}
+}
+
+func TestGetMediaKindStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	audible, _ := db.GetServiceByName("Audible")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.UpdateServiceEnabled(audible.ID, true)
+
+	now := time.Now()
+	db.InsertWatchHistory(&WatchHistory{ServiceID: netflix.ID, Title: "Test Movie", DurationMinutes: 90, WatchedAt: now})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: audible.ID, Title: "Test Audiobook", DurationMinutes: 270, WatchedAt: now, MediaKind: MediaKindAudio})
+
+	stats, err := db.GetMediaKindStats(now.Add(-1*time.Hour), now.Add(1*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get media kind stats: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 media kinds, got %d", len(stats))
+	}
+	if stats[0].MediaKind != MediaKindAudio || stats[0].TotalMinutes != 270 || stats[0].Percentage != 75 {
+		t.Errorf("Expected audio first with 270 minutes (75%%), got %+v", stats[0])
+	}
+	if stats[1].MediaKind != MediaKindVideo || stats[1].TotalMinutes != 90 {
+		t.Errorf("Expected video with 90 minutes, got %+v", stats[1])
+	}
+}
+
+func TestGetTopTitles(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+
+	now := time.Now()
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Show A", DurationMinutes: 30, WatchedAt: now})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Show A", DurationMinutes: 30, WatchedAt: now.Add(-time.Hour)})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Movie B", DurationMinutes: 50, WatchedAt: now})
+
+	titles, err := db.GetTopTitles(now.Add(-2*time.Hour), now.Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("Failed to get top titles: %v", err)
+	}
+	if len(titles) != 2 {
+		t.Fatalf("Expected 2 titles, got %d", len(titles))
+	}
+	if titles[0].Title != "Show A" || titles[0].TotalMinutes != 60 || titles[0].WatchCount != 2 {
+		t.Errorf("Expected Show A first with 60 minutes over 2 watches, got %+v", titles[0])
+	}
+}
+
+func TestInferredHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	day := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	entries := []WatchHistory{
+		{ServiceID: service.ID, Title: "Network Activity", DurationMinutes: 150, WatchedAt: day, Confidence: ConfidenceLow},
+		{ServiceID: service.ID, Title: "Device Usage", DurationMinutes: 60, WatchedAt: day.AddDate(0, 0, 1), Confidence: ConfidenceMedium},
+		{ServiceID: service.ID, Title: "Stranger Things", DurationMinutes: 50, WatchedAt: day.Add(time.Hour)},
+	}
+	for i := range entries {
+		if err := db.InsertWatchHistory(&entries[i]); err != nil {
+			t.Fatalf("Failed to insert history: %v", err)
+		}
+	}
+
+	// Only history read from the service counts, and only where its watch
+	// time overlaps
+	if read, _ := db.HasWatchHistoryDuring(service.ID, day.Add(90*time.Minute), day.Add(2*time.Hour)); !read {
+		t.Error("Expected the scraped entry to overlap")
+	}
+	if read, _ := db.HasWatchHistoryDuring(service.ID, day.Add(2*time.Hour), day.Add(3*time.Hour)); read {
+		t.Error("Expected no read history after the scraped entry ends")
+	}
+	if read, _ := db.HasWatchHistoryDuring(service.ID, day.AddDate(0, 0, 1), day.AddDate(0, 0, 1).Add(time.Hour)); read {
+		t.Error("Expected only inferred sessions to have no read history")
+	}
+
+	inferred, err := db.GetInferredHistory(day.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get inferred history: %v", err)
+	}
+	if len(inferred) != 2 || inferred[0].Confidence != ConfidenceLow || inferred[1].Confidence != ConfidenceMedium {
+		t.Fatalf("Expected both inferred sessions oldest first, got %+v", inferred)
+	}
+
+	deleted, err := db.DeleteWatchHistoryEntries([]int64{inferred[0].ID, inferred[1].ID})
+	if err != nil || deleted != 2 {
+		t.Fatalf("Expected 2 entries deleted, got %d: %v", deleted, err)
+	}
+	if inferred, _ = db.GetInferredHistory(time.Time{}); len(inferred) != 0 {
+		t.Errorf("Expected no inferred sessions left, got %+v", inferred)
+	}
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"math"
+	"time"
+)
+
+// GetDeviceStats returns watch time per device for a time period, optionally
+// limited to one service (serviceID 0 means all services). Rows without
+// device information, including compacted history, are grouped under "Unknown".
+func (db *DB) GetDeviceStats(serviceID int64, startDate, endDate time.Time) ([]DeviceStats, error) {
+	rows, err := db.Query(`
+		SELECT
+			COALESCE(NULLIF(wh.device, ''), 'Unknown') as device,
+			SUM(wh.duration_minutes) as total_minutes,
+			SUM(wh.entries) as total_shows
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND (? = 0 OR wh.service_id = ?)
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY 1
+		ORDER BY total_minutes DESC
+	`, serviceID, serviceID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []DeviceStats{}
+	totalMinutes := 0
+	for rows.Next() {
+		var stat DeviceStats
+		if err := rows.Scan(&stat.Device, &stat.TotalMinutes, &stat.TotalShows); err != nil {
+			return nil, err
+		}
+		totalMinutes += stat.TotalMinutes
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Share of total watch time, rounded to one decimal place
+	for i := range stats {
+		if totalMinutes > 0 {
+			stats[i].Percentage = math.Round(float64(stats[i].TotalMinutes)*1000/float64(totalMinutes)) / 10
+		}
+	}
+
+	return stats, nil
+}
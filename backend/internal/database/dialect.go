@@ -0,0 +1,503 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Database drivers accepted by Open
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// dialect covers the SQL that differs between database engines. Queries are
+// written for SQLite with ? placeholders, ON CONFLICT upserts and RETURNING,
+// which Postgres also accepts once the placeholders are rebound.
+type dialect interface {
+	// driverName is the database/sql driver the dialect opens
+	driverName() string
+
+	// rebind rewrites ? placeholders into the driver's style
+	rebind(query string) string
+
+	// date returns an expression formatting a timestamp as YYYY-MM-DD text
+	date(expr string) string
+
+	// dateTime returns an expression formatting a timestamp as YYYY-MM-DD HH:MM:SS text
+	dateTime(expr string) string
+
+	// like is the case-insensitive LIKE operator
+	like() string
+
+	// schema adapts a CREATE or ALTER statement written for SQLite
+	schema(stmt string) string
+
+	// setup returns statements run before the schema is created
+	setup() []string
+
+	// triggers returns the statements that keep updated timestamps and
+	// deletion tombstones current
+	triggers() []string
+
+	// dropTriggers returns the statements that remove what triggers creates
+	dropTriggers() []string
+
+	// historyUpdatedTrigger returns the statements that (re)create
+	// watch_history_update_updated, bumping updated when one of the columns
+	// changes
+	historyUpdatedTrigger(columns []string) []string
+
+	// dailyTotalTriggers returns the statements that keep daily_title_minutes
+	// current with the history and rollups it totals
+	dailyTotalTriggers() []string
+
+	// dropDailyTotalTriggers returns the statements that remove what
+	// dailyTotalTriggers creates
+	dropDailyTotalTriggers() []string
+
+	// tableExists reports whether a table has been created
+	tableExists(q querier, table string) (bool, error)
+
+	// columnExists reports whether a table has a column
+	columnExists(q querier, table, column string) (bool, error)
+
+	// changeUnique replaces a table's UNIQUE(from...) constraint with UNIQUE(to...)
+	changeUnique(tx *Tx, table string, from, to []string) error
+
+	// fullTextSearch reports whether the engine can keep the FTS5 history
+	// search index
+	fullTextSearch(q querier) (bool, error)
+}
+
+// uniqueClause is a UNIQUE constraint as the schema writes it
+func uniqueClause(columns []string) string {
+	return "UNIQUE(" + strings.Join(columns, ", ") + ")"
+}
+
+// querier is satisfied by *DB and *Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// updatedTriggers names the triggers every dialect creates, with their tables
+var updatedTriggers = []struct {
+	table string
+	name  string
+}{
+	{"watch_history", "watch_history_insert_updated"},
+	{"watch_history", "watch_history_update_updated"},
+	{"watch_history", "watch_history_delete_tombstone"},
+	{"services", "services_insert_updated"},
+	{"services", "services_update_updated"},
+	{"scraper_runs", "scraper_runs_insert_updated"},
+	{"scraper_runs", "scraper_runs_update_updated"},
+}
+
+// historyUpdatedColumns are the watch_history columns whose changes bump its
+// updated timestamp. Migration 3 tracked the first twelve, since later ones
+// like profile_id didn't exist yet; migration 23 tracks them all.
+var historyUpdatedColumns = []string{
+	"service_id", "title", "duration_minutes", "watched_at", "episode_info", "thumbnail_url",
+	"genre", "device", "location", "media_kind", "notes", "rating",
+	"profile_id", "confidence", "raw_payload",
+}
+
+// dialectFor returns the dialect for a driver name
+func dialectFor(driver string) (dialect, error) {
+	switch driver {
+	case "", DriverSQLite:
+		return sqliteDialect{}, nil
+	case DriverPostgres:
+		return postgresDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+// sqliteDialect is the default, and the dialect queries are written in
+type sqliteDialect struct{}
+
+func (sqliteDialect) driverName() string { return "sqlite3" }
+
+func (sqliteDialect) rebind(query string) string { return query }
+
+func (sqliteDialect) date(expr string) string { return "DATE(" + expr + ")" }
+
+func (sqliteDialect) dateTime(expr string) string { return "DATETIME(" + expr + ")" }
+
+func (sqliteDialect) like() string { return "LIKE" }
+
+func (sqliteDialect) schema(stmt string) string { return stmt }
+
+func (sqliteDialect) setup() []string { return nil }
+
+func (d sqliteDialect) triggers() []string {
+	stmts := []string{
+		`CREATE TRIGGER IF NOT EXISTS watch_history_insert_updated AFTER INSERT ON watch_history
+		BEGIN
+			UPDATE watch_history SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS watch_history_delete_tombstone AFTER DELETE ON watch_history
+		BEGIN
+			INSERT OR REPLACE INTO watch_history_deletions (history_id, deleted) VALUES (OLD.id, CURRENT_TIMESTAMP);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS services_insert_updated AFTER INSERT ON services
+		BEGIN
+			UPDATE services SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS services_update_updated AFTER UPDATE ON services
+		WHEN NEW.updated IS OLD.updated
+		BEGIN
+			UPDATE services SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scraper_runs_insert_updated AFTER INSERT ON scraper_runs
+		BEGIN
+			UPDATE scraper_runs SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scraper_runs_update_updated AFTER UPDATE ON scraper_runs
+		WHEN NEW.updated IS OLD.updated
+		BEGIN
+			UPDATE scraper_runs SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
+		END`,
+	}
+	return append(stmts, d.historyUpdatedTrigger(historyUpdatedColumns[:12])...)
+}
+
+func (sqliteDialect) historyUpdatedTrigger(columns []string) []string {
+	changed := make([]string, len(columns))
+	for i, col := range columns {
+		changed[i] = fmt.Sprintf("NEW.%[1]s IS NOT OLD.%[1]s", col)
+	}
+	return []string{
+		"DROP TRIGGER IF EXISTS watch_history_update_updated",
+		// Re-scraped rows are upserted unchanged, so only bump on a real change
+		`CREATE TRIGGER watch_history_update_updated AFTER UPDATE ON watch_history
+		WHEN NEW.updated IS OLD.updated AND (` + strings.Join(changed, " OR ") + `)
+		BEGIN
+			UPDATE watch_history SET updated = CURRENT_TIMESTAMP WHERE id = NEW.id;
+		END`,
+	}
+}
+
+func (sqliteDialect) dropTriggers() []string {
+	var stmts []string
+	for _, trigger := range updatedTriggers {
+		stmts = append(stmts, "DROP TRIGGER IF EXISTS "+trigger.name)
+	}
+	return stmts
+}
+
+func (d sqliteDialect) dailyTotalTriggers() []string {
+	var stmts []string
+	for _, src := range dailyTotalSources(d) {
+		var changed []string
+		for _, col := range src.columns {
+			changed = append(changed, fmt.Sprintf("NEW.%[1]s IS NOT OLD.%[1]s", col))
+		}
+		stmts = append(stmts,
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_insert_daily AFTER INSERT ON %[1]s
+		BEGIN
+			%[2]s
+		END`, src.table, src.add()),
+			// Upserts that change nothing leave the totals alone
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_update_daily AFTER UPDATE ON %[1]s
+		WHEN %[2]s
+		BEGIN
+			%[3]s
+			%[4]s
+		END`, src.table, strings.Join(changed, " OR "), src.remove(), src.add()),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_delete_daily AFTER DELETE ON %[1]s
+		BEGIN
+			%[2]s
+		END`, src.table, src.remove()),
+		)
+	}
+	return stmts
+}
+
+func (d sqliteDialect) dropDailyTotalTriggers() []string {
+	var stmts []string
+	for _, src := range dailyTotalSources(d) {
+		for _, op := range []string{"insert", "update", "delete"} {
+			stmts = append(stmts, fmt.Sprintf("DROP TRIGGER IF EXISTS %s_%s_daily", src.table, op))
+		}
+	}
+	return stmts
+}
+
+func (sqliteDialect) tableExists(q querier, table string) (bool, error) {
+	var count int
+	err := q.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+	return count > 0, err
+}
+
+// columnExists reads PRAGMA table_info, since SQLite has no ADD COLUMN IF NOT EXISTS
+func (sqliteDialect) columnExists(q querier, table, column string) (bool, error) {
+	rows, err := q.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// changeUnique rebuilds the table, since SQLite can't alter a constraint:
+// the old table is renamed aside, recreated with the new constraint and
+// copied back, then its indexes and triggers are restored
+func (sqliteDialect) changeUnique(tx *Tx, table string, from, to []string) error {
+	var create string
+	if err := tx.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&create); err != nil {
+		return err
+	}
+	if !strings.Contains(create, uniqueClause(from)) {
+		return fmt.Errorf("%s has no %s constraint", table, uniqueClause(from))
+	}
+
+	rows, err := tx.Query(`SELECT sql FROM sqlite_master WHERE type IN ('index', 'trigger') AND tbl_name = ? AND sql IS NOT NULL`, table)
+	if err != nil {
+		return err
+	}
+	var dependents []string
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			rows.Close()
+			return err
+		}
+		dependents = append(dependents, stmt)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	old := table + "_rebuild"
+	stmts := []string{
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, old),
+		strings.Replace(create, uniqueClause(from), uniqueClause(to), 1),
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", table, old),
+		"DROP TABLE " + old,
+	}
+	for _, stmt := range append(stmts, dependents...) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fullTextSearch checks for FTS5, which go-sqlite3 only compiles in with the
+// sqlite_fts5 build tag
+func (sqliteDialect) fullTextSearch(q querier) (bool, error) {
+	var enabled bool
+	err := q.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled)
+	return enabled, err
+}
+
+// postgresDialect runs the SQLite-flavoured schema and queries on Postgres.
+// Case-insensitive columns use a nondeterministic "nocase" collation, so
+// COLLATE NOCASE works unchanged, and days are stored as YYYY-MM-DD text
+// like SQLite does.
+type postgresDialect struct{}
+
+func (postgresDialect) driverName() string { return "postgres" }
+
+// rebind numbers ? placeholders as $1, $2, ..., leaving quoted strings and
+// identifiers alone
+func (postgresDialect) rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func (postgresDialect) date(expr string) string {
+	return "TO_CHAR(CAST(" + expr + " AS TIMESTAMP), 'YYYY-MM-DD')"
+}
+
+func (postgresDialect) dateTime(expr string) string {
+	return "TO_CHAR(CAST(" + expr + " AS TIMESTAMP), 'YYYY-MM-DD HH24:MI:SS')"
+}
+
+func (postgresDialect) like() string { return "ILIKE" }
+
+// postgresTypes maps SQLite column definitions to their Postgres equivalents
+var postgresTypes = strings.NewReplacer(
+	"INTEGER PRIMARY KEY AUTOINCREMENT", "BIGSERIAL PRIMARY KEY",
+	"DATETIME", "TIMESTAMP",
+	"DATE NOT NULL", "TEXT NOT NULL",
+)
+
+func (postgresDialect) schema(stmt string) string {
+	return postgresTypes.Replace(stmt)
+}
+
+func (postgresDialect) setup() []string {
+	return []string{
+		`CREATE COLLATION IF NOT EXISTS nocase (provider = icu, locale = 'und-u-ks-level2', deterministic = false)`,
+	}
+}
+
+func (d postgresDialect) triggers() []string {
+	stmts := []string{
+		`CREATE OR REPLACE FUNCTION streamtime_touch_updated() RETURNS trigger AS $$
+		BEGIN
+			NEW.updated := CURRENT_TIMESTAMP;
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE FUNCTION streamtime_watch_history_tombstone() RETURNS trigger AS $$
+		BEGIN
+			INSERT INTO watch_history_deletions (history_id, deleted) VALUES (OLD.id, CURRENT_TIMESTAMP)
+			ON CONFLICT (history_id) DO UPDATE SET deleted = excluded.deleted;
+			RETURN OLD;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE TRIGGER watch_history_insert_updated BEFORE INSERT ON watch_history
+		FOR EACH ROW EXECUTE FUNCTION streamtime_touch_updated()`,
+		`CREATE OR REPLACE TRIGGER watch_history_delete_tombstone AFTER DELETE ON watch_history
+		FOR EACH ROW EXECUTE FUNCTION streamtime_watch_history_tombstone()`,
+		`CREATE OR REPLACE TRIGGER services_insert_updated BEFORE INSERT ON services
+		FOR EACH ROW EXECUTE FUNCTION streamtime_touch_updated()`,
+		`CREATE OR REPLACE TRIGGER services_update_updated BEFORE UPDATE ON services
+		FOR EACH ROW WHEN (NEW.updated IS NOT DISTINCT FROM OLD.updated)
+		EXECUTE FUNCTION streamtime_touch_updated()`,
+		`CREATE OR REPLACE TRIGGER scraper_runs_insert_updated BEFORE INSERT ON scraper_runs
+		FOR EACH ROW EXECUTE FUNCTION streamtime_touch_updated()`,
+		`CREATE OR REPLACE TRIGGER scraper_runs_update_updated BEFORE UPDATE ON scraper_runs
+		FOR EACH ROW WHEN (NEW.updated IS NOT DISTINCT FROM OLD.updated)
+		EXECUTE FUNCTION streamtime_touch_updated()`,
+	}
+	return append(stmts, d.historyUpdatedTrigger(historyUpdatedColumns[:12])...)
+}
+
+func (postgresDialect) historyUpdatedTrigger(columns []string) []string {
+	row := func(alias string) string {
+		cols := make([]string, len(columns))
+		for i, col := range columns {
+			cols[i] = alias + "." + col
+		}
+		return strings.Join(cols, ", ")
+	}
+	return []string{
+		// Re-scraped rows are upserted unchanged, so only bump on a real change
+		`CREATE OR REPLACE TRIGGER watch_history_update_updated BEFORE UPDATE ON watch_history
+		FOR EACH ROW WHEN (NEW.updated IS NOT DISTINCT FROM OLD.updated AND
+			(` + row("NEW") + `) IS DISTINCT FROM (` + row("OLD") + `))
+		EXECUTE FUNCTION streamtime_touch_updated()`,
+	}
+}
+
+func (postgresDialect) dropTriggers() []string {
+	var stmts []string
+	for _, trigger := range updatedTriggers {
+		stmts = append(stmts, fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", trigger.name, trigger.table))
+	}
+	return append(stmts,
+		"DROP FUNCTION IF EXISTS streamtime_touch_updated()",
+		"DROP FUNCTION IF EXISTS streamtime_watch_history_tombstone()",
+	)
+}
+
+func (d postgresDialect) dailyTotalTriggers() []string {
+	var stmts []string
+	for _, src := range dailyTotalSources(d) {
+		stmts = append(stmts,
+			fmt.Sprintf(`CREATE OR REPLACE FUNCTION streamtime_%[1]s_daily() RETURNS trigger AS $$
+		BEGIN
+			IF TG_OP <> 'INSERT' THEN
+				%[2]s
+			END IF;
+			IF TG_OP <> 'DELETE' THEN
+				%[3]s
+			END IF;
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`, src.table, src.remove(), src.add()),
+			fmt.Sprintf(`CREATE OR REPLACE TRIGGER %[1]s_daily AFTER INSERT OR UPDATE OR DELETE ON %[1]s
+		FOR EACH ROW EXECUTE FUNCTION streamtime_%[1]s_daily()`, src.table),
+		)
+	}
+	return stmts
+}
+
+func (d postgresDialect) dropDailyTotalTriggers() []string {
+	var stmts []string
+	for _, src := range dailyTotalSources(d) {
+		stmts = append(stmts,
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %[1]s_daily ON %[1]s", src.table),
+			fmt.Sprintf("DROP FUNCTION IF EXISTS streamtime_%s_daily()", src.table),
+		)
+	}
+	return stmts
+}
+
+func (postgresDialect) tableExists(q querier, table string) (bool, error) {
+	var exists bool
+	err := q.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name = ?
+		)
+	`, table).Scan(&exists)
+	return exists, err
+}
+
+func (postgresDialect) columnExists(q querier, table, column string) (bool, error) {
+	var exists bool
+	err := q.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?
+		)
+	`, table, column).Scan(&exists)
+	return exists, err
+}
+
+// changeUnique renames the constraint's columns the way Postgres names a
+// UNIQUE constraint by default
+func (postgresDialect) changeUnique(tx *Tx, table string, from, to []string) error {
+	_, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s_%s_key, ADD %s",
+		table, table, strings.Join(from, "_"), uniqueClause(to)))
+	return err
+}
+
+// fullTextSearch is false since FTS5 is SQLite's; searches use ILIKE
+func (postgresDialect) fullTextSearch(q querier) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostgresRebind(t *testing.T) {
+	got := postgresDialect{}.rebind(`SELECT id FROM t WHERE a = ? AND b LIKE ? ESCAPE '\' AND c = '?' AND d = ?`)
+	want := `SELECT id FROM t WHERE a = $1 AND b LIKE $2 ESCAPE '\' AND c = '?' AND d = $3`
+	if got != want {
+		t.Errorf("rebind = %q, want %q", got, want)
+	}
+
+	query := `SELECT ? FROM t`
+	if got := (sqliteDialect{}).rebind(query); got != query {
+		t.Errorf("sqlite rebind changed the query to %q", got)
+	}
+}
+
+func TestPostgresSchema(t *testing.T) {
+	got := postgresDialect{}.schema(`CREATE TABLE t (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		day DATE NOT NULL,
+		played_at DATETIME NOT NULL
+	)`)
+	for _, want := range []string{"id BIGSERIAL PRIMARY KEY", "day TEXT NOT NULL", "played_at TIMESTAMP NOT NULL"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in translated schema:\n%s", want, got)
+		}
+	}
+}
+
+func TestOpenUnsupportedDriver(t *testing.T) {
+	if _, err := Open("mysql", "streamtime"); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+	// The postgres driver is only compiled in with -tags postgres
+	if _, err := Open(DriverPostgres, "postgres://localhost/streamtime"); err == nil || !strings.Contains(err.Error(), "-tags postgres") {
+		t.Errorf("Expected a build tag hint without the postgres driver, got %v", err)
+	}
+}
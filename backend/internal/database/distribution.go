@@ -0,0 +1,35 @@
+package database
+
+import "time"
+
+// GetWatchEntries returns the start time and length of every watch history
+// entry for a time period across enabled services, oldest first. A compacted
+// day counts each title once, starting at its first watch that day with the
+// day's total minutes. Entries are returned without IDs.
+func (db *DB) GetWatchEntries(startDate, endDate time.Time) ([]WatchHistory, error) {
+	rows, err := db.Query(`
+		SELECT wh.service_id, wh.title, wh.duration_minutes, wh.watched_at
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		ORDER BY wh.watched_at
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []WatchHistory{}
+	for rows.Next() {
+		var wh WatchHistory
+		if err := rows.Scan(&wh.ServiceID, &wh.Title, &wh.DurationMinutes, &wh.WatchedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, wh)
+	}
+
+	return entries, rows.Err()
+}
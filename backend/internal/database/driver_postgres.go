@@ -0,0 +1,6 @@
+//go:build postgres
+
+package database
+
+// The Postgres driver is only linked into builds with -tags postgres
+import _ "github.com/lib/pq"
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetTVShow returns the cached latest and next episodes of a TV show, or nil
+// if the title hasn't been looked up yet
+func (db *DB) GetTVShow(title string) (*TVShow, error) {
+	var show TVShow
+	err := db.QueryRow(`
+		SELECT title, tmdb_id, latest_season, latest_episode, latest_air_date,
+			next_season, next_episode, next_name, next_air_date, updated
+		FROM tv_shows
+		WHERE title = ?
+	`, title).Scan(&show.Title, &show.TMDBID, &show.LatestSeason, &show.LatestEpisode, &show.LatestAirDate,
+		&show.NextSeason, &show.NextEpisode, &show.NextName, &show.NextAirDate, &show.Updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &show, nil
+}
+
+// SetTVShow caches a TV show's latest and next episodes and the air dates
+// of the given episodes, replacing any dates cached for them before
+func (db *DB) SetTVShow(show *TVShow, episodes []EpisodeAirDate) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	show.Updated = time.Now()
+	if _, err := tx.Exec(`
+		INSERT INTO tv_shows (title, tmdb_id, latest_season, latest_episode, latest_air_date,
+			next_season, next_episode, next_name, next_air_date, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(title) DO UPDATE SET
+			tmdb_id = excluded.tmdb_id,
+			latest_season = excluded.latest_season,
+			latest_episode = excluded.latest_episode,
+			latest_air_date = excluded.latest_air_date,
+			next_season = excluded.next_season,
+			next_episode = excluded.next_episode,
+			next_name = excluded.next_name,
+			next_air_date = excluded.next_air_date,
+			updated = excluded.updated
+	`, show.Title, show.TMDBID, show.LatestSeason, show.LatestEpisode, show.LatestAirDate,
+		show.NextSeason, show.NextEpisode, show.NextName, show.NextAirDate, show.Updated); err != nil {
+		return err
+	}
+	for _, ep := range episodes {
+		if _, err := tx.Exec(`
+			INSERT INTO episode_air_dates (tmdb_id, season, episode, air_date)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(tmdb_id, season, episode) DO UPDATE SET air_date = excluded.air_date
+		`, show.TMDBID, ep.Season, ep.Episode, ep.AirDate); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetEpisodeAirDates returns the cached air dates of a TV show's episodes,
+// in season and episode order
+func (db *DB) GetEpisodeAirDates(tmdbID int64) ([]EpisodeAirDate, error) {
+	rows, err := db.Query(`
+		SELECT season, episode, air_date
+		FROM episode_air_dates
+		WHERE tmdb_id = ?
+		ORDER BY season, episode
+	`, tmdbID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	episodes := []EpisodeAirDate{}
+	for rows.Next() {
+		var ep EpisodeAirDate
+		if err := rows.Scan(&ep.Season, &ep.Episode, &ep.AirDate); err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, ep)
+	}
+
+	return episodes, rows.Err()
+}
+
+// GetWatchedEpisodes returns each episode first watched in a time period on
+// enabled services, oldest first. Later rewatches don't move an episode's
+// first watch, so an episode first seen before the period isn't included.
+// Compacted history has no episodes, so only days from DetailSince on count.
+func (db *DB) GetWatchedEpisodes(startDate, endDate time.Time) ([]WatchedEpisode, error) {
+	rows, err := db.Query(`
+		SELECT wh.title, wh.episode_info, `+db.dialect.dateTime("MIN(wh.watched_at)")+` as first_watched
+		FROM `+db.history()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND COALESCE(wh.episode_info, '') <> ''
+		  AND `+notIgnoredClause+`
+		GROUP BY wh.title COLLATE NOCASE, wh.episode_info COLLATE NOCASE
+		HAVING MIN(wh.watched_at) >= ? AND MIN(wh.watched_at) < ?
+		ORDER BY first_watched
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	episodes := []WatchedEpisode{}
+	for rows.Next() {
+		var ep WatchedEpisode
+		var firstWatched string
+		if err := rows.Scan(&ep.Title, &ep.EpisodeInfo, &firstWatched); err != nil {
+			return nil, err
+		}
+		ep.FirstWatched, err = time.Parse("2006-01-02 15:04:05", firstWatched)
+		if err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, ep)
+	}
+
+	return episodes, rows.Err()
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTVShowCache(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	show, err := db.GetTVShow("Severance")
+	if err != nil || show != nil {
+		t.Fatalf("Expected no cached show, got %+v (%v)", show, err)
+	}
+
+	show = &TVShow{Title: "Severance", TMDBID: 95396, LatestSeason: 2, LatestEpisode: 1, LatestAirDate: "2025-01-17"}
+	if err := db.SetTVShow(show, []EpisodeAirDate{{Season: 2, Episode: 1, AirDate: "2025-01-16"}}); err != nil {
+		t.Fatalf("Failed to cache show: %v", err)
+	}
+	// A refresh corrects earlier dates and adds new episodes
+	show.LatestEpisode = 2
+	err = db.SetTVShow(show, []EpisodeAirDate{
+		{Season: 2, Episode: 2, AirDate: "2025-01-24"},
+		{Season: 2, Episode: 1, AirDate: "2025-01-17"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to refresh show: %v", err)
+	}
+
+	show, err = db.GetTVShow("severance")
+	if err != nil || show == nil || show.LatestEpisode != 2 {
+		t.Fatalf("Expected the refreshed show, got %+v (%v)", show, err)
+	}
+	episodes, err := db.GetEpisodeAirDates(95396)
+	if err != nil {
+		t.Fatalf("Failed to get air dates: %v", err)
+	}
+	if len(episodes) != 2 || episodes[0].AirDate != "2025-01-17" || episodes[1].Episode != 2 {
+		t.Errorf("Unexpected air dates: %+v", episodes)
+	}
+}
+
+func TestGetWatchedEpisodes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	start := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	for _, wh := range []*WatchHistory{
+		{ServiceID: netflix.ID, Title: "Severance", EpisodeInfo: "S02E01", DurationMinutes: 50, WatchedAt: start.AddDate(0, 0, -10)},
+		{ServiceID: netflix.ID, Title: "Severance", EpisodeInfo: "S02E01", DurationMinutes: 50, WatchedAt: start.AddDate(0, 0, 3)},
+		{ServiceID: netflix.ID, Title: "severance", EpisodeInfo: "S02E02", DurationMinutes: 50, WatchedAt: start.AddDate(0, 0, 2)},
+		{ServiceID: netflix.ID, Title: "Severance", EpisodeInfo: "S02E02", DurationMinutes: 50, WatchedAt: start.AddDate(0, 0, 4)},
+		{ServiceID: netflix.ID, Title: "Glass Onion", DurationMinutes: 140, WatchedAt: start.AddDate(0, 0, 5)},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	// S02E01 was first watched before the period, and movies have no episodes
+	episodes, err := db.GetWatchedEpisodes(start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("Failed to get watched episodes: %v", err)
+	}
+	if len(episodes) != 1 || episodes[0].EpisodeInfo != "S02E02" || !episodes[0].FirstWatched.Equal(start.AddDate(0, 0, 2)) {
+		t.Errorf("Expected only S02E02's first watch, got %+v", episodes)
+	}
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// InsertGamingSession records a gaming session
+func (db *DB) InsertGamingSession(gs *GamingSession) error {
+	if gs.Source == "" {
+		gs.Source = "manual"
+	}
+
+	return db.QueryRow(`
+		INSERT INTO gaming_sessions (platform, game, minutes, played_at, source)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`, gs.Platform, gs.Game, gs.Minutes, gs.PlayedAt, gs.Source).Scan(&gs.ID)
+}
+
+// GetGamingSessions returns gaming sessions for a time period, newest first
+func (db *DB) GetGamingSessions(startDate, endDate time.Time) ([]GamingSession, error) {
+	rows, err := db.Query(`
+		SELECT id, platform, game, minutes, played_at, source, created
+		FROM gaming_sessions
+		WHERE played_at >= ? AND played_at < ?
+		ORDER BY played_at DESC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []GamingSession{}
+	for rows.Next() {
+		var gs GamingSession
+		if err := rows.Scan(&gs.ID, &gs.Platform, &gs.Game, &gs.Minutes, &gs.PlayedAt, &gs.Source, &gs.Created); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, gs)
+	}
+
+	return sessions, rows.Err()
+}
+
+// DeleteGamingSession removes a gaming session
+func (db *DB) DeleteGamingSession(id int64) (bool, error) {
+	result, err := db.Exec(`DELETE FROM gaming_sessions WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetPlatformStats returns gaming time per platform for a time period
+func (db *DB) GetPlatformStats(startDate, endDate time.Time) ([]PlatformStats, error) {
+	rows, err := db.Query(`
+		SELECT platform, SUM(minutes) as total_minutes, COUNT(DISTINCT game) as total_games
+		FROM gaming_sessions
+		WHERE played_at >= ? AND played_at < ?
+		GROUP BY platform
+		ORDER BY total_minutes DESC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []PlatformStats{}
+	for rows.Next() {
+		var stat PlatformStats
+		if err := rows.Scan(&stat.Platform, &stat.TotalMinutes, &stat.TotalGames); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetSteamPlaytime returns the last recorded lifetime playtime for a Steam app,
+// and false if the app hasn't been seen before
+func (db *DB) GetSteamPlaytime(appID int64) (int, bool, error) {
+	var minutes int
+	err := db.QueryRow(`
+		SELECT playtime_minutes FROM steam_playtime WHERE app_id = ?
+	`, appID).Scan(&minutes)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return minutes, true, nil
+}
+
+// SetSteamPlaytime records the lifetime playtime for a Steam app
+func (db *DB) SetSteamPlaytime(appID int64, name string, minutes int) error {
+	_, err := db.Exec(`
+		INSERT INTO steam_playtime (app_id, name, playtime_minutes, updated)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(app_id) DO UPDATE SET
+			name = excluded.name,
+			playtime_minutes = excluded.playtime_minutes,
+			updated = CURRENT_TIMESTAMP
+	`, appID, name, minutes)
+	return err
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// normalizedGenreExpr resolves a history row's genre through the genre
+// mappings, so stats group provider variants under one name
+const normalizedGenreExpr = `COALESCE(
+			(SELECT gm.genre FROM genre_mappings gm WHERE gm.source = wh.genre),
+			wh.genre
+		)`
+
+// defaultGenreMappings consolidates the genre names metadata providers
+// disagree on most, seeded when the mapping table is created
+var defaultGenreMappings = []struct {
+	source string
+	genre  string
+}{
+	{"Sci-Fi & Fantasy", "Science Fiction"},
+	{"Sci-Fi", "Science Fiction"},
+	{"Action & Adventure", "Action"},
+	{"War & Politics", "War"},
+	{"Kids", "Family"},
+	{"Children & Family", "Family"},
+	{"Documentaries", "Documentary"},
+	{"Comedies", "Comedy"},
+	{"Dramas", "Drama"},
+	{"Thrillers", "Thriller"},
+	{"Horror Movies", "Horror"},
+	{"Romantic", "Romance"},
+}
+
+// seedGenreMappings inserts the default genre mappings
+func seedGenreMappings(tx *Tx) error {
+	for _, m := range defaultGenreMappings {
+		if _, err := tx.Exec(`INSERT INTO genre_mappings (source, genre) VALUES (?, ?) ON CONFLICT DO NOTHING`, m.source, m.genre); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGenreMappings returns all genre mappings ordered by target genre
+func (db *DB) GetGenreMappings() ([]GenreMapping, error) {
+	rows, err := db.Query(`
+		SELECT id, source, genre, created
+		FROM genre_mappings
+		ORDER BY genre, source
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mappings := []GenreMapping{}
+	for rows.Next() {
+		var gm GenreMapping
+		if err := rows.Scan(&gm.ID, &gm.Source, &gm.Genre, &gm.Created); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, gm)
+	}
+
+	return mappings, rows.Err()
+}
+
+// InsertGenreMapping maps a source genre to a normalized one, replacing any
+// existing mapping for the source. Like title aliases, mappings are kept one
+// level deep: a target that is itself mapped is resolved, and mappings onto
+// the new source are repointed to its target.
+func (db *DB) InsertGenreMapping(gm *GenreMapping) error {
+	gm.Source = strings.TrimSpace(gm.Source)
+	gm.Genre = strings.TrimSpace(gm.Genre)
+
+	var target string
+	if err := db.QueryRow(`SELECT genre FROM genre_mappings WHERE source = ?`, gm.Genre).Scan(&target); err == nil {
+		gm.Genre = target
+	}
+	if strings.EqualFold(gm.Source, gm.Genre) {
+		return fmt.Errorf("source and genre must differ")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO genre_mappings (source, genre)
+		VALUES (?, ?)
+		ON CONFLICT(source) DO UPDATE SET genre = excluded.genre
+	`, gm.Source, gm.Genre); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE genre_mappings SET genre = ? WHERE genre = ? COLLATE NOCASE`, gm.Genre, gm.Source); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return db.QueryRow(`SELECT id, created FROM genre_mappings WHERE source = ?`, gm.Source).Scan(&gm.ID, &gm.Created)
+}
+
+// DeleteGenreMapping removes a genre mapping, so the source genre shows up
+// under its own name again
+func (db *DB) DeleteGenreMapping(id int64) (bool, error) {
+	result, err := db.Exec(`DELETE FROM genre_mappings WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetGenreStats returns time per normalized genre for a time period across
+// enabled services. Entries without a genre are skipped.
+func (db *DB) GetGenreStats(startDate, endDate time.Time) ([]GenreStats, error) {
+	rows, err := db.Query(`
+		SELECT
+			`+normalizedGenreExpr+` AS normalized_genre,
+			SUM(wh.duration_minutes) as total_minutes,
+			SUM(wh.entries) as total_items
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND COALESCE(wh.genre, '') != ''
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY normalized_genre COLLATE NOCASE
+		ORDER BY total_minutes DESC, normalized_genre
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []GenreStats{}
+	totalMinutes := 0
+	for rows.Next() {
+		var stat GenreStats
+		if err := rows.Scan(&stat.Genre, &stat.TotalMinutes, &stat.TotalItems); err != nil {
+			return nil, err
+		}
+		totalMinutes += stat.TotalMinutes
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Share of total time, rounded to one decimal place
+	for i := range stats {
+		if totalMinutes > 0 {
+			stats[i].Percentage = math.Round(float64(stats[i].TotalMinutes)*1000/float64(totalMinutes)) / 10
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenreStatsConsolidateMappedGenres(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+
+	now := time.Now()
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Space Show", DurationMinutes: 30, WatchedAt: now, Genre: "Sci-Fi & Fantasy"})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Space Movie", DurationMinutes: 90, WatchedAt: now, Genre: "Science Fiction"})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Baking Show", DurationMinutes: 60, WatchedAt: now, Genre: "Reality TV"})
+
+	stats, err := db.GetGenreStats(now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get genre stats: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Genre != "Science Fiction" || stats[0].TotalMinutes != 120 {
+		t.Fatalf("Expected Sci-Fi variants consolidated by the default mappings, got %+v", stats)
+	}
+
+	// Chained mappings resolve to the final genre
+	if err := db.InsertGenreMapping(&GenreMapping{Source: "Reality TV", Genre: "Reality"}); err != nil {
+		t.Fatalf("Failed to add genre mapping: %v", err)
+	}
+	gm := &GenreMapping{Source: "Science Fiction", Genre: "Reality"}
+	if err := db.InsertGenreMapping(gm); err != nil {
+		t.Fatalf("Failed to add genre mapping: %v", err)
+	}
+
+	stats, _ = db.GetGenreStats(now.Add(-time.Hour), now.Add(time.Hour))
+	if len(stats) != 1 || stats[0].Genre != "Reality" || stats[0].TotalMinutes != 180 {
+		t.Errorf("Expected every genre consolidated under Reality, got %+v", stats)
+	}
+
+	if deleted, err := db.DeleteGenreMapping(gm.ID); err != nil || !deleted {
+		t.Fatalf("Failed to delete genre mapping: %v", err)
+	}
+	mappings, _ := db.GetGenreMappings()
+	for _, m := range mappings {
+		if m.Source == "Sci-Fi & Fantasy" && m.Genre != "Reality" {
+			t.Errorf("Expected mappings onto Science Fiction to be repointed, got %+v", m)
+		}
+	}
+}
+
+func TestInsertGenreMappingRejectsSelfMapping(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := db.InsertGenreMapping(&GenreMapping{Source: "Anime", Genre: "anime"}); err == nil {
+		t.Error("Expected an error mapping a genre to itself")
+	}
+}
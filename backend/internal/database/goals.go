@@ -0,0 +1,92 @@
+package database
+
+import "time"
+
+// GetScreenFreeDays returns planned screen-free days between two dates
+// (YYYY-MM-DD, inclusive), oldest first
+func (db *DB) GetScreenFreeDays(startDate, endDate string) ([]ScreenFreeDay, error) {
+	rows, err := db.Query(`
+		SELECT date, note, source, created
+		FROM screen_free_days
+		WHERE date >= ? AND date <= ?
+		ORDER BY date
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []ScreenFreeDay{}
+	for rows.Next() {
+		var d ScreenFreeDay
+		if err := rows.Scan(&d.Date, &d.Note, &d.Source, &d.Created); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+
+	return days, rows.Err()
+}
+
+// UpsertScreenFreeDay plans a screen-free day, replacing any existing note.
+// Days synced from a calendar never overwrite manually added ones.
+func (db *DB) UpsertScreenFreeDay(d *ScreenFreeDay) error {
+	if d.Source == "" {
+		d.Source = "manual"
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO screen_free_days (date, note, source)
+		VALUES (?, ?, ?)
+		ON CONFLICT(date) DO UPDATE SET note = excluded.note, source = excluded.source
+		WHERE screen_free_days.source = excluded.source OR excluded.source = 'manual'
+	`, d.Date, d.Note, d.Source)
+	return err
+}
+
+// DeleteScreenFreeDay removes a planned screen-free day
+func (db *DB) DeleteScreenFreeDay(date string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM screen_free_days WHERE date = ?`, date)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetDailyScreenTime returns combined streaming and gaming minutes per day
+// (YYYY-MM-DD) across enabled services, including compacted days
+func (db *DB) GetDailyScreenTime(startDate, endDate time.Time) (map[string]int, error) {
+	rows, err := db.Query(`
+		SELECT day, SUM(minutes) FROM (
+			SELECT `+db.dialect.date("wh.watched_at")+` as day, wh.duration_minutes as minutes
+			FROM `+db.watchTime()+` wh
+			JOIN services s ON wh.service_id = s.id
+			WHERE s.enabled = TRUE
+			  AND wh.watched_at >= ?
+			  AND wh.watched_at < ?
+			  AND `+notIgnoredClause+`
+			UNION ALL
+			SELECT `+db.dialect.date("played_at")+` as day, minutes
+			FROM gaming_sessions
+			WHERE played_at >= ? AND played_at < ?
+		) daily
+		GROUP BY day
+	`, startDate, endDate, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	daily := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var minutes int
+		if err := rows.Scan(&day, &minutes); err != nil {
+			return nil, err
+		}
+		daily[day] = minutes
+	}
+
+	return daily, rows.Err()
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpsertScreenFreeDayKeepsManualEntries(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := db.UpsertScreenFreeDay(&ScreenFreeDay{Date: "2025-03-15", Note: "Hiking"}); err != nil {
+		t.Fatalf("Failed to add screen-free day: %v", err)
+	}
+	// A calendar sync must not overwrite the manual note
+	db.UpsertScreenFreeDay(&ScreenFreeDay{Date: "2025-03-15", Note: "Screen-free", Source: "caldav"})
+	db.UpsertScreenFreeDay(&ScreenFreeDay{Date: "2025-03-16", Note: "Screen-free", Source: "caldav"})
+
+	days, err := db.GetScreenFreeDays("2025-03-01", "2025-03-31")
+	if err != nil {
+		t.Fatalf("Failed to get screen-free days: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(days))
+	}
+	if days[0].Note != "Hiking" || days[0].Source != "manual" {
+		t.Errorf("Expected manual entry to be kept, got %+v", days[0])
+	}
+
+	deleted, err := db.DeleteScreenFreeDay("2025-03-16")
+	if err != nil || !deleted {
+		t.Errorf("Expected day to be deleted, got %v, %v", deleted, err)
+	}
+}
+
+func TestGetDailyScreenTime(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+
+	day := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Show", DurationMinutes: 45, WatchedAt: day})
+	db.InsertGamingSession(&GamingSession{Platform: "switch", Game: "Zelda", Minutes: 30, PlayedAt: day.Add(time.Hour)})
+	db.InsertGamingSession(&GamingSession{Platform: "switch", Game: "Zelda", Minutes: 20, PlayedAt: day.AddDate(0, 0, 1)})
+
+	daily, err := db.GetDailyScreenTime(day.AddDate(0, 0, -1), day.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("Failed to get daily screen time: %v", err)
+	}
+	if daily["2025-03-14"] != 75 || daily["2025-03-15"] != 20 {
+		t.Errorf("Unexpected daily screen time: %v", daily)
+	}
+}
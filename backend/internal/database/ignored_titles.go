@@ -0,0 +1,75 @@
+package database
+
+// notIgnoredClause filters out watch_history rows (aliased "wh") whose title is on the ignore list
+const notIgnoredClause = `NOT EXISTS (
+			SELECT 1 FROM ignored_titles it
+			WHERE it.title = wh.title
+			  AND (it.service_id = 0 OR it.service_id = wh.service_id)
+		)`
+
+// GetIgnoredTitles returns all ignored titles
+func (db *DB) GetIgnoredTitles() ([]IgnoredTitle, error) {
+	rows, err := db.Query(`
+		SELECT id, service_id, title, created
+		FROM ignored_titles
+		ORDER BY title
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []IgnoredTitle{}
+	for rows.Next() {
+		var it IgnoredTitle
+		if err := rows.Scan(&it.ID, &it.ServiceID, &it.Title, &it.Created); err != nil {
+			return nil, err
+		}
+		titles = append(titles, it)
+	}
+
+	return titles, rows.Err()
+}
+
+// InsertIgnoredTitle adds a title to the ignore list
+func (db *DB) InsertIgnoredTitle(it *IgnoredTitle) error {
+	_, err := db.Exec(`
+		INSERT INTO ignored_titles (service_id, title)
+		VALUES (?, ?)
+		ON CONFLICT DO NOTHING
+	`, it.ServiceID, it.Title)
+	if err != nil {
+		return err
+	}
+
+	// Look up the ID, which also covers a title that was already ignored
+	return db.QueryRow(`
+		SELECT id FROM ignored_titles WHERE service_id = ? AND title = ?
+	`, it.ServiceID, it.Title).Scan(&it.ID)
+}
+
+// DeleteIgnoredTitle removes a title from the ignore list
+func (db *DB) DeleteIgnoredTitle(id int64) (bool, error) {
+	result, err := db.Exec(`DELETE FROM ignored_titles WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// IsTitleIgnored checks whether a title is ignored for a service
+func (db *DB) IsTitleIgnored(serviceID int64, title string) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM ignored_titles
+		WHERE title = ?
+		  AND (service_id = 0 OR service_id = ?)
+	`, title, serviceID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIgnoredTitlesExcludedFromStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+
+	now := time.Now()
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Kept Show", DurationMinutes: 30, WatchedAt: now})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Roommate Show", DurationMinutes: 60, WatchedAt: now})
+
+	// Ignore with different casing to verify case-insensitive matching
+	if err := db.InsertIgnoredTitle(&IgnoredTitle{Title: "roommate show"}); err != nil {
+		t.Fatalf("Failed to ignore title: %v", err)
+	}
+
+	history, err := db.GetWatchHistory(service.ID, now.Add(-time.Hour), now.Add(time.Hour), 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get watch history: %v", err)
+	}
+	if len(history) != 1 || history[0].Title != "Kept Show" {
+		t.Errorf("Expected only 'Kept Show' in history, got %+v", history)
+	}
+
+	stats, err := db.GetServiceStats(now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get service stats: %v", err)
+	}
+	for _, stat := range stats {
+		if stat.ServiceID == service.ID && stat.TotalMinutes != 30 {
+			t.Errorf("Expected 30 total minutes excluding ignored title, got %d", stat.TotalMinutes)
+		}
+	}
+
+	daily, err := db.GetDailyStats(service.ID, now.Add(-24*time.Hour), now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get daily stats: %v", err)
+	}
+	if daily[now.Format("2006-01-02")] != 30 {
+		t.Errorf("Expected 30 daily minutes excluding ignored title, got %v", daily)
+	}
+}
+
+func TestIgnoredTitleServiceScope(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	peacock, _ := db.GetServiceByName("Peacock")
+
+	db.InsertIgnoredTitle(&IgnoredTitle{ServiceID: netflix.ID, Title: "White Noise"})
+
+	ignored, _ := db.IsTitleIgnored(netflix.ID, "White Noise")
+	if !ignored {
+		t.Error("Expected title to be ignored on Netflix")
+	}
+
+	ignored, _ = db.IsTitleIgnored(peacock.ID, "White Noise")
+	if ignored {
+		t.Error("Expected title not to be ignored on Peacock")
+	}
+}
+
+func TestInsertIgnoredTitleTwice(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	first := &IgnoredTitle{Title: "White Noise"}
+	db.InsertIgnoredTitle(first)
+	second := &IgnoredTitle{Title: "White Noise"}
+	if err := db.InsertIgnoredTitle(second); err != nil {
+		t.Fatalf("Failed to insert duplicate ignored title: %v", err)
+	}
+
+	if second.ID != first.ID {
+		t.Errorf("Expected existing ID %d, got %d", first.ID, second.ID)
+	}
+
+	deleted, err := db.DeleteIgnoredTitle(first.ID)
+	if err != nil || !deleted {
+		t.Errorf("Expected ignored title to be deleted, got %v, %v", deleted, err)
+	}
+}
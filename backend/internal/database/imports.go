@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+)
+
+// GetImportByHash returns a previous import of the same file for a service, or nil if none
+func (db *DB) GetImportByHash(serviceID int64, contentHash string) (*Import, error) {
+	var imp Import
+	var filename sql.NullString
+	err := db.QueryRow(`
+		SELECT id, service_id, content_hash, filename, total_rows, imported, created
+		FROM imports
+		WHERE service_id = ? AND content_hash = ?
+	`, serviceID, contentHash).Scan(&imp.ID, &imp.ServiceID, &imp.ContentHash, &filename,
+		&imp.TotalRows, &imp.Imported, &imp.Created)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	imp.Filename = filename.String
+
+	return &imp, nil
+}
+
+// InsertImport records an imported file, replacing any earlier record for the same content
+func (db *DB) InsertImport(imp *Import) error {
+	return db.QueryRow(`
+		INSERT INTO imports (service_id, content_hash, filename, total_rows, imported)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(service_id, content_hash) DO UPDATE SET
+			filename = excluded.filename,
+			total_rows = excluded.total_rows,
+			imported = excluded.imported,
+			created = CURRENT_TIMESTAMP
+		RETURNING id
+	`, imp.ServiceID, imp.ContentHash, imp.Filename, imp.TotalRows, imp.Imported).Scan(&imp.ID)
+}
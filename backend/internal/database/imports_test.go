@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestInsertAndGetImportByHash(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+
+	// Unknown hash returns nil
+	imp, err := db.GetImportByHash(service.ID, "abc123")
+	if err != nil {
+		t.Fatalf("Failed to get import: %v", err)
+	}
+	if imp != nil {
+		t.Fatal("Expected nil for unknown hash")
+	}
+
+	err = db.InsertImport(&Import{
+		ServiceID:   service.ID,
+		ContentHash: "abc123",
+		Filename:    "NetflixViewingHistory.csv",
+		TotalRows:   10,
+		Imported:    8,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert import: %v", err)
+	}
+
+	imp, err = db.GetImportByHash(service.ID, "abc123")
+	if err != nil {
+		t.Fatalf("Failed to get import: %v", err)
+	}
+	if imp == nil {
+		t.Fatal("Expected import to be found")
+	}
+	if imp.Filename != "NetflixViewingHistory.csv" || imp.Imported != 8 {
+		t.Errorf("Unexpected import record: %+v", imp)
+	}
+
+	// Re-inserting the same content updates rather than duplicating
+	err = db.InsertImport(&Import{ServiceID: service.ID, ContentHash: "abc123", TotalRows: 10})
+	if err != nil {
+		t.Fatalf("Failed to re-insert import: %v", err)
+	}
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM imports").Scan(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 import record, got %d", count)
+	}
+}
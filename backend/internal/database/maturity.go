@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetMaturityRating returns the cached content rating for a title in a
+// region, or nil if the title hasn't been looked up there yet
+func (db *DB) GetMaturityRating(title, region string) (*MaturityRating, error) {
+	var rating MaturityRating
+	err := db.QueryRow(`
+		SELECT title, region, tmdb_id, rating, updated
+		FROM title_maturity_ratings
+		WHERE title = ? AND region = ?
+	`, title, region).Scan(&rating.Title, &rating.Region, &rating.TMDBID, &rating.Rating, &rating.Updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// SetMaturityRating caches the content rating for a title in a region
+func (db *DB) SetMaturityRating(rating *MaturityRating) error {
+	_, err := db.Exec(`
+		INSERT INTO title_maturity_ratings (title, region, tmdb_id, rating, updated)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(title, region) DO UPDATE SET
+			tmdb_id = excluded.tmdb_id,
+			rating = excluded.rating,
+			updated = excluded.updated
+	`, rating.Title, rating.Region, rating.TMDBID, rating.Rating, time.Now())
+	return err
+}
+
+// GetUnratedTitles returns titles watched in a time period on enabled
+// services that haven't been looked up in a region yet, most watched first
+func (db *DB) GetUnratedTitles(startDate, endDate time.Time, region string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT wh.title
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		  AND NOT EXISTS (
+			SELECT 1 FROM title_maturity_ratings m
+			WHERE m.title = wh.title AND m.region = ?
+		  )
+		GROUP BY wh.title COLLATE NOCASE
+		ORDER BY SUM(wh.duration_minutes) DESC
+	`, startDate, endDate, region)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []string{}
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+
+	return titles, rows.Err()
+}
+
+// GetMaturityStats returns watch time per content rating in a region across
+// enabled services, ordered by watch time. Titles that have no rating, or
+// haven't been looked up yet, are grouped under an empty rating.
+func (db *DB) GetMaturityStats(startDate, endDate time.Time, region string) ([]MaturityStats, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(m.rating, '') as rating, SUM(wh.duration_minutes) as total_minutes, SUM(wh.entries) as watch_count
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		LEFT JOIN title_maturity_ratings m ON m.title = wh.title AND m.region = ?
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY COALESCE(m.rating, '')
+		ORDER BY total_minutes DESC
+	`, region, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []MaturityStats{}
+	for rows.Next() {
+		var ms MaturityStats
+		if err := rows.Scan(&ms.Rating, &ms.TotalMinutes, &ms.WatchCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, ms)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetTitlesByMaturityRating returns watch time per title on each enabled
+// service for titles carrying a content rating in a region, most watched first
+func (db *DB) GetTitlesByMaturityRating(startDate, endDate time.Time, region, rating string) ([]ServiceTitleStats, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.name, wh.title, SUM(wh.duration_minutes) as total_minutes, SUM(wh.entries) as watch_count
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		JOIN title_maturity_ratings m ON m.title = wh.title AND m.region = ?
+		WHERE s.enabled = TRUE
+		  AND m.rating = ? COLLATE NOCASE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY s.id, s.name, wh.title COLLATE NOCASE
+		ORDER BY total_minutes DESC
+	`, region, rating, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []ServiceTitleStats{}
+	for rows.Next() {
+		var st ServiceTitleStats
+		if err := rows.Scan(&st.ServiceID, &st.ServiceName, &st.Title, &st.TotalMinutes, &st.WatchCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
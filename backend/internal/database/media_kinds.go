@@ -0,0 +1,52 @@
+package database
+
+import (
+	"math"
+	"time"
+)
+
+// GetMediaKindStats returns time per media kind (video vs audio) for a time
+// period, so listening time can be compared with streaming time
+func (db *DB) GetMediaKindStats(startDate, endDate time.Time) ([]MediaKindStats, error) {
+	rows, err := db.Query(`
+		SELECT
+			COALESCE(NULLIF(wh.media_kind, ''), 'video') as media_kind,
+			SUM(wh.duration_minutes) as total_minutes,
+			SUM(wh.entries) as total_items
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY 1
+		ORDER BY total_minutes DESC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []MediaKindStats{}
+	totalMinutes := 0
+	for rows.Next() {
+		var stat MediaKindStats
+		if err := rows.Scan(&stat.MediaKind, &stat.TotalMinutes, &stat.TotalItems); err != nil {
+			return nil, err
+		}
+		totalMinutes += stat.TotalMinutes
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Share of total time, rounded to one decimal place
+	for i := range stats {
+		if totalMinutes > 0 {
+			stats[i].Percentage = math.Round(float64(stats[i].TotalMinutes)*1000/float64(totalMinutes)) / 10
+		}
+	}
+
+	return stats, nil
+}
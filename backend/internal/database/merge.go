@@ -0,0 +1,138 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeResult summarizes a service merge
+type MergeResult struct {
+	SourceID          int64 `json:"source_id"`
+	TargetID          int64 `json:"target_id"`
+	HistoryMoved      int64 `json:"history_moved"`
+	DuplicatesRemoved int64 `json:"duplicates_removed"`
+	ScraperRunsMoved  int64 `json:"scraper_runs_moved"`
+}
+
+// MergeServices moves all history, scraper runs, and per-service settings from
+// the source service into the target, dropping source rows that duplicate
+// existing target history, and archives the source service
+func (db *DB) MergeServices(sourceID, targetID int64) (*MergeResult, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("cannot merge a service into itself")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &MergeResult{SourceID: sourceID, TargetID: targetID}
+
+	// Drop source rows the target already has for the same profile (same title
+	// at the same time, or the same title and episode on the same day,
+	// matching WatchHistoryExists)
+	res, err := tx.Exec(`
+		DELETE FROM watch_history
+		WHERE service_id = ?
+		  AND EXISTS (
+			SELECT 1 FROM watch_history t
+			WHERE t.service_id = ?
+			  AND t.profile_id = watch_history.profile_id
+			  AND t.title = watch_history.title
+			  AND (t.watched_at = watch_history.watched_at
+			       OR (COALESCE(t.episode_info, '') = COALESCE(watch_history.episode_info, '')
+			           AND `+db.dialect.date("t.watched_at")+` = `+db.dialect.date("watch_history.watched_at")+`))
+		  )
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove duplicate history: %w", err)
+	}
+	result.DuplicatesRemoved, _ = res.RowsAffected()
+
+	res, err = tx.Exec(`UPDATE watch_history SET service_id = ? WHERE service_id = ?`, targetID, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move history: %w", err)
+	}
+	result.HistoryMoved, _ = res.RowsAffected()
+
+	// Rollups of a day the target also has are duplicates like the rows above
+	if _, err := tx.Exec(moveServiceRows("watch_history_rollups", "day", "title"), targetID, sourceID, targetID); err != nil {
+		return nil, fmt.Errorf("failed to move rollups: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM watch_history_rollups WHERE service_id = ?`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to remove duplicate rollups: %w", err)
+	}
+
+	res, err = tx.Exec(`UPDATE scraper_runs SET service_id = ? WHERE service_id = ?`, targetID, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move scraper runs: %w", err)
+	}
+	result.ScraperRunsMoved, _ = res.RowsAffected()
+
+	// Summaries of pruned runs add up on days both services have them
+	if _, err := tx.Exec(`
+		INSERT INTO scraper_run_summaries (service_id, day, runs, succeeded, failed, items_scraped)
+		SELECT ?, day, runs, succeeded, failed, items_scraped FROM scraper_run_summaries WHERE service_id = ?
+		ON CONFLICT(service_id, day) DO UPDATE SET
+			runs = scraper_run_summaries.runs + excluded.runs,
+			succeeded = scraper_run_summaries.succeeded + excluded.succeeded,
+			failed = scraper_run_summaries.failed + excluded.failed,
+			items_scraped = scraper_run_summaries.items_scraped + excluded.items_scraped
+	`, targetID, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to move scraper run summaries: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM scraper_run_summaries WHERE service_id = ?`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to clean up scraper run summaries: %w", err)
+	}
+
+	// Per-service settings: move what doesn't already exist on the target, drop the rest
+	for _, settings := range []struct {
+		table string
+		key   string
+	}{
+		{"ignored_titles", "title"},
+		{"imports", "content_hash"},
+	} {
+		if _, err := tx.Exec(moveServiceRows(settings.table, settings.key), targetID, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("failed to move %s: %w", settings.table, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE service_id = ?`, settings.table), sourceID); err != nil {
+			return nil, fmt.Errorf("failed to clean up %s: %w", settings.table, err)
+		}
+	}
+
+	// Keep the target enabled if either service was, and archive the source
+	if _, err := tx.Exec(`
+		UPDATE services
+		SET enabled = enabled OR (SELECT enabled FROM services WHERE id = ?)
+		WHERE id = ?
+	`, sourceID, targetID); err != nil {
+		return nil, fmt.Errorf("failed to update target service: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE services SET enabled = FALSE, archived = TRUE WHERE id = ?`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to archive source service: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// moveServiceRows returns a statement moving a table's rows to the target
+// service, skipping rows whose unique key the target already has. It takes
+// the target ID, source ID and target ID again.
+func moveServiceRows(table string, keys ...string) string {
+	conds := make([]string, len(keys))
+	for i, key := range keys {
+		conds[i] = fmt.Sprintf("t.%s = %s.%s", key, table, key)
+	}
+	return fmt.Sprintf(`
+		UPDATE %s SET service_id = ?
+		WHERE service_id = ?
+		  AND NOT EXISTS (SELECT 1 FROM %s t WHERE t.service_id = ? AND %s)
+	`, table, table, strings.Join(conds, " AND "))
+}
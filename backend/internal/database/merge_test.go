@@ -0,0 +1,67 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeServices(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	source, _ := db.GetServiceByName("HBO Max")
+	target, _ := db.GetServiceByName("Peacock")
+	db.UpdateServiceEnabled(source.ID, true)
+
+	watchedAt := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	// One unique row on the source, one duplicated on both services
+	db.InsertWatchHistory(&WatchHistory{ServiceID: source.ID, Title: "Succession", EpisodeInfo: "S01E01", DurationMinutes: 60, WatchedAt: watchedAt})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: source.ID, Title: "The Wire", EpisodeInfo: "S01E01", DurationMinutes: 60, WatchedAt: watchedAt})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: target.ID, Title: "The Wire", EpisodeInfo: "S01E01", DurationMinutes: 60, WatchedAt: watchedAt})
+	db.InsertScraperRun(&ScraperRun{ServiceID: source.ID, RanAt: watchedAt, Status: "success"})
+	db.InsertIgnoredTitle(&IgnoredTitle{ServiceID: source.ID, Title: "Trailer"})
+
+	result, err := db.MergeServices(source.ID, target.ID)
+	if err != nil {
+		t.Fatalf("Failed to merge services: %v", err)
+	}
+
+	if result.HistoryMoved != 1 {
+		t.Errorf("Expected 1 history row moved, got %d", result.HistoryMoved)
+	}
+	if result.DuplicatesRemoved != 1 {
+		t.Errorf("Expected 1 duplicate removed, got %d", result.DuplicatesRemoved)
+	}
+	if result.ScraperRunsMoved != 1 {
+		t.Errorf("Expected 1 scraper run moved, got %d", result.ScraperRunsMoved)
+	}
+
+	history, _ := db.GetWatchHistory(target.ID, watchedAt.Add(-time.Hour), watchedAt.Add(time.Hour), 10, 0)
+	if len(history) != 2 {
+		t.Errorf("Expected 2 history rows on target, got %d", len(history))
+	}
+
+	ignored, _ := db.IsTitleIgnored(target.ID, "Trailer")
+	if !ignored {
+		t.Error("Expected ignored title to move to target service")
+	}
+
+	source, _ = db.GetServiceByID(source.ID)
+	if !source.Archived || source.Enabled {
+		t.Errorf("Expected source to be archived and disabled, got %+v", source)
+	}
+	target, _ = db.GetServiceByID(target.ID)
+	if !target.Enabled {
+		t.Error("Expected target to inherit enabled status from source")
+	}
+}
+
+func TestMergeServicesIntoItself(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.MergeServices(1, 1); err == nil {
+		t.Error("Expected error when merging a service into itself")
+	}
+}
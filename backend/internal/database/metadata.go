@@ -0,0 +1,77 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetTitleMetadata returns the cached TMDB details of a title, or nil if the
+// title hasn't been looked up yet
+func (db *DB) GetTitleMetadata(title string) (*TitleMetadata, error) {
+	var meta TitleMetadata
+	err := db.QueryRow(`
+		SELECT title, tmdb_id, media_type, runtime, genre, thumbnail_url, updated
+		FROM title_metadata
+		WHERE title = ?
+	`, title).Scan(&meta.Title, &meta.TMDBID, &meta.MediaType, &meta.Runtime, &meta.Genre, &meta.ThumbnailURL, &meta.Updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// SetTitleMetadata caches the TMDB details of a title, including misses so
+// they aren't looked up again
+func (db *DB) SetTitleMetadata(meta *TitleMetadata) error {
+	meta.Updated = time.Now()
+	_, err := db.Exec(`
+		INSERT INTO title_metadata (title, tmdb_id, media_type, runtime, genre, thumbnail_url, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(title) DO UPDATE SET
+			tmdb_id = excluded.tmdb_id,
+			media_type = excluded.media_type,
+			runtime = excluded.runtime,
+			genre = excluded.genre,
+			thumbnail_url = excluded.thumbnail_url,
+			updated = excluded.updated
+	`, meta.Title, meta.TMDBID, meta.MediaType, meta.Runtime, meta.Genre, meta.ThumbnailURL, meta.Updated)
+	return err
+}
+
+// FillWatchHistoryMetadata sets the duration, genre and thumbnail of watch
+// history entries from a title's metadata, only where the entry has none, and
+// returns how many entries changed
+func (db *DB) FillWatchHistoryMetadata(ids []int64, meta *TitleMetadata) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	filled := 0
+	for _, id := range ids {
+		result, err := tx.Exec(`
+			UPDATE watch_history SET
+				duration_minutes = CASE WHEN duration_minutes = 0 THEN ? ELSE duration_minutes END,
+				genre = CASE WHEN COALESCE(genre, '') = '' THEN ? ELSE genre END,
+				thumbnail_url = CASE WHEN COALESCE(thumbnail_url, '') = '' THEN ? ELSE thumbnail_url END
+			WHERE id = ?
+			  AND ((duration_minutes = 0 AND ? > 0)
+			    OR (COALESCE(genre, '') = '' AND ? <> '')
+			    OR (COALESCE(thumbnail_url, '') = '' AND ? <> ''))
+		`, meta.Runtime, meta.Genre, meta.ThumbnailURL, id, meta.Runtime, meta.Genre, meta.ThumbnailURL)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		filled += int(n)
+	}
+
+	return filled, tx.Commit()
+}
@@ -0,0 +1,814 @@
+package database
+
+import (
+	"fmt"
+)
+
+// migration is one versioned schema change. Each is applied, or reverted,
+// in its own transaction together with its schema_migrations row.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *Tx) error
+	down        func(tx *Tx) error
+}
+
+// migrations are applied in order on startup. Add schema changes as a new
+// migration with the next version rather than editing one that has shipped.
+// Databases created before versioning start at version 0 and have every
+// migration applied, which is safe since the early ones are idempotent.
+var migrations = []migration{
+	{1, "initial schema", createInitialSchema, dropInitialSchema},
+	{2, "columns added after the initial schema", addColumns(laterColumns), dropColumns(laterColumns)},
+	{3, "updated timestamps and deletion tombstones", createUpdatedTriggers, dropUpdatedTriggers},
+	{4, "label app usage as medium confidence", labelUsageConfidence, noMigration},
+	{5, "profiles", createProfiles, dropProfiles},
+	{6, "maturity ratings", createMaturityRatings, dropMaturityRatings},
+	// The search index depends on the build rather than the schema version,
+	// so syncSearchIndex creates or drops it on every start instead
+	{7, "full-text search index", noMigration, noMigration},
+	{8, "episode air dates", createEpisodeAirDates, dropEpisodeAirDates},
+	{9, "upcoming episodes", addColumns(upcomingColumns), dropColumns(upcomingColumns)},
+	{10, "notifications", createNotifications, dropNotifications},
+	{11, "title availability", createTitleAvailability, dropTitleAvailability},
+	{12, "title metadata", createTitleMetadata, dropTitleMetadata},
+	{13, "service consent", createServiceConsent, dropServiceConsent},
+	{14, "tmdb cache", createTMDBCache, dropTMDBCache},
+	{15, "rejected scraper items", addColumns(rejectedColumns), dropColumns(rejectedColumns)},
+	{16, "seed services", seedServices(initialServices), noMigration},
+	{17, "service logo files", restyleServices(logoFixes), restyleServices(initialServices)},
+	{18, "rename Apple TV+ to Apple TV", renameServices(serviceRenames[:1]), revertRenames(serviceRenames[:1])},
+	{19, "service keys", createServiceKeys, dropServiceKeys},
+	{20, "trakt sync", createTraktSync, dropTraktSync},
+	{21, "skipped and ignored scraper items", addColumns(droppedColumns), dropColumns(droppedColumns)},
+	{22, "daily totals", createDailyTotals, dropDailyTotals},
+	{23, "updated timestamps for later history columns", trackHistoryUpdates(historyUpdatedColumns), trackHistoryUpdates(historyUpdatedColumns[:12])},
+}
+
+// Migrate applies pending migrations in order and returns the versions applied
+func (db *DB) Migrate() ([]int, error) {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := db.runMigration(m.up, `INSERT INTO schema_migrations (version, description) VALUES (?, ?)`, m.version, m.description); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+		applied = append(applied, m.version)
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts applied migrations newer than version, newest first,
+// and returns the versions reverted. Data in dropped tables and columns is lost.
+func (db *DB) MigrateDown(version int) ([]int, error) {
+	if version < 0 {
+		return nil, fmt.Errorf("invalid schema version %d", version)
+	}
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	// The search index covers columns the migrations drop; the next start
+	// rebuilds it
+	if version < current {
+		if err := db.dropSearchIndex(); err != nil {
+			return nil, fmt.Errorf("failed to drop search index: %w", err)
+		}
+	}
+
+	var reverted []int
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.version > current || m.version <= version {
+			continue
+		}
+		if err := db.runMigration(m.down, `DELETE FROM schema_migrations WHERE version = ?`, m.version); err != nil {
+			return reverted, fmt.Errorf("reverting migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+		reverted = append(reverted, m.version)
+	}
+	return reverted, nil
+}
+
+// SchemaVersion returns the newest applied migration, or 0 for an empty database
+func (db *DB) SchemaVersion() (int, error) {
+	var version int
+	err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// LatestSchemaVersion returns the version Migrate brings a database to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// runMigration runs a migration step and records it in one transaction
+func (db *DB) runMigration(step func(tx *Tx) error, record string, args ...interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := step(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// execAll runs statements in order
+func execAll(tx *Tx, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(tx.dialect.schema(stmt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// noMigration is the down step of data-only migrations, which have nothing to undo
+func noMigration(tx *Tx) error {
+	return nil
+}
+
+// initialTables are the tables created by the initial schema, in creation order
+var initialTables = []string{
+	"services", "watch_history", "scraper_runs", "imports", "ignored_titles", "gaming_sessions",
+	"steam_playtime", "screen_free_days", "title_origins", "saved_views", "title_aliases",
+	"genre_mappings", "service_cookies", "pending_items", "watch_history_rollups", "scraper_run_summaries",
+}
+
+func createInitialSchema(tx *Tx) error {
+	// Default genre mappings are only seeded into a new table, so deleted ones stay deleted
+	genreMappingsExist, err := tx.dialect.tableExists(tx, "genre_mappings")
+	if err != nil {
+		return err
+	}
+
+	tables := []string{
+		`CREATE TABLE IF NOT EXISTS services (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			color TEXT NOT NULL,
+			logo_url TEXT,
+			enabled BOOLEAN DEFAULT TRUE,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS watch_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			service_id INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			duration_minutes INTEGER NOT NULL,
+			watched_at TIMESTAMP NOT NULL,
+			episode_info TEXT,
+			thumbnail_url TEXT,
+			genre TEXT,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (service_id) REFERENCES services(id),
+			UNIQUE(service_id, title, watched_at)
+		)`,
+		`CREATE TABLE IF NOT EXISTS scraper_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			service_id INTEGER NOT NULL,
+			ran_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			status TEXT NOT NULL,
+			error_message TEXT,
+			items_scraped INTEGER DEFAULT 0,
+			FOREIGN KEY (service_id) REFERENCES services(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			service_id INTEGER NOT NULL,
+			content_hash TEXT NOT NULL,
+			filename TEXT,
+			total_rows INTEGER DEFAULT 0,
+			imported INTEGER DEFAULT 0,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (service_id) REFERENCES services(id),
+			UNIQUE(service_id, content_hash)
+		)`,
+		`CREATE TABLE IF NOT EXISTS ignored_titles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			service_id INTEGER NOT NULL DEFAULT 0,
+			title TEXT NOT NULL COLLATE NOCASE,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(service_id, title)
+		)`,
+		`CREATE TABLE IF NOT EXISTS gaming_sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			platform TEXT NOT NULL,
+			game TEXT NOT NULL,
+			minutes INTEGER NOT NULL,
+			played_at DATETIME NOT NULL,
+			source TEXT NOT NULL DEFAULT 'manual',
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS steam_playtime (
+			app_id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			playtime_minutes INTEGER NOT NULL,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS screen_free_days (
+			date TEXT PRIMARY KEY,
+			note TEXT NOT NULL DEFAULT '',
+			source TEXT NOT NULL DEFAULT 'manual',
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS title_origins (
+			title TEXT PRIMARY KEY COLLATE NOCASE,
+			tmdb_id INTEGER NOT NULL DEFAULT 0,
+			media_type TEXT NOT NULL DEFAULT '',
+			producers TEXT NOT NULL DEFAULT '',
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS saved_views (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			date_range TEXT NOT NULL,
+			start_date TEXT NOT NULL DEFAULT '',
+			end_date TEXT NOT NULL DEFAULT '',
+			service_ids TEXT NOT NULL DEFAULT '',
+			granularity TEXT NOT NULL,
+			chart_type TEXT NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS title_aliases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			alias TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			canonical TEXT NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS genre_mappings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			genre TEXT NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS service_cookies (
+			service_id INTEGER PRIMARY KEY,
+			cookies TEXT NOT NULL,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (service_id) REFERENCES services(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS pending_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			service_id INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			duration_minutes INTEGER NOT NULL,
+			watched_at TIMESTAMP NOT NULL,
+			episode_info TEXT,
+			thumbnail_url TEXT,
+			genre TEXT,
+			device TEXT DEFAULT '',
+			location TEXT DEFAULT '',
+			media_kind TEXT DEFAULT 'video',
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (service_id) REFERENCES services(id),
+			UNIQUE(service_id, title, watched_at)
+		)`,
+		`CREATE TABLE IF NOT EXISTS watch_history_rollups (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			service_id INTEGER NOT NULL,
+			day DATE NOT NULL,
+			title TEXT NOT NULL,
+			watched_at TIMESTAMP NOT NULL,
+			duration_minutes INTEGER NOT NULL,
+			entries INTEGER NOT NULL,
+			genre TEXT DEFAULT '',
+			media_kind TEXT DEFAULT 'video',
+			FOREIGN KEY (service_id) REFERENCES services(id),
+			UNIQUE(service_id, day, title)
+		)`,
+		`CREATE TABLE IF NOT EXISTS scraper_run_summaries (
+			service_id INTEGER NOT NULL,
+			day DATE NOT NULL,
+			runs INTEGER NOT NULL,
+			succeeded INTEGER NOT NULL,
+			failed INTEGER NOT NULL,
+			items_scraped INTEGER NOT NULL,
+			FOREIGN KEY (service_id) REFERENCES services(id),
+			UNIQUE(service_id, day)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_gaming_sessions_played_at ON gaming_sessions(played_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_watch_history_service_id ON watch_history(service_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_watch_history_watched_at ON watch_history(watched_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_scraper_runs_service_id ON scraper_runs(service_id)`,
+	}
+	if err := execAll(tx, tables); err != nil {
+		return err
+	}
+
+	if !genreMappingsExist {
+		return seedGenreMappings(tx)
+	}
+	return nil
+}
+
+func dropInitialSchema(tx *Tx) error {
+	for i := len(initialTables) - 1; i >= 0; i-- {
+		if _, err := tx.Exec("DROP TABLE IF EXISTS " + initialTables[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// column is a column added to an existing table
+type column struct {
+	table      string
+	column     string
+	definition string
+}
+
+// laterColumns were added after the initial schema
+var laterColumns = []column{
+	{"services", "archived", "BOOLEAN DEFAULT FALSE"},
+	{"watch_history", "device", "TEXT DEFAULT ''"},
+	{"watch_history", "location", "TEXT DEFAULT ''"},
+	{"watch_history", "media_kind", "TEXT DEFAULT 'video'"},
+	{"watch_history", "notes", "TEXT DEFAULT ''"},
+	{"watch_history", "rating", "INTEGER DEFAULT 0"},
+	{"watch_history", "updated", "TIMESTAMP"},
+	{"services", "updated", "TIMESTAMP"},
+	{"scraper_runs", "updated", "TIMESTAMP"},
+	{"scraper_runs", "selector_hits", "TEXT DEFAULT ''"},
+	{"scraper_runs", "log_tail", "TEXT DEFAULT ''"},
+	{"scraper_runs", "dom_snapshot", "TEXT DEFAULT ''"},
+	{"scraper_runs", "triggered_by", "TEXT DEFAULT 'manual'"},
+	{"watch_history", "confidence", "TEXT DEFAULT ''"},
+	{"watch_history", "raw_payload", "TEXT DEFAULT ''"},
+	{"pending_items", "raw_payload", "TEXT DEFAULT ''"},
+}
+
+// addColumns returns a step adding columns that don't exist yet
+func addColumns(columns []column) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for _, col := range columns {
+			if err := addColumnIfMissing(tx, col.table, col.column, col.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// dropColumns returns a step dropping columns, newest first
+func dropColumns(columns []column) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for i := len(columns) - 1; i >= 0; i-- {
+			if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", columns[i].table, columns[i].column)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumnIfMissing adds a column to an existing table, since SQLite has no ADD COLUMN IF NOT EXISTS
+func addColumnIfMissing(tx *Tx, table, column, definition string) error {
+	exists, err := tx.dialect.columnExists(tx, table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = tx.Exec(tx.dialect.schema(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)))
+	return err
+}
+
+// createUpdatedTriggers keeps updated timestamps current for delta sync and
+// auditing. SQLite can't add a column with a CURRENT_TIMESTAMP default, so
+// triggers fill it in, and rows from before the column existed fall back to
+// their created time.
+func createUpdatedTriggers(tx *Tx) error {
+	stmts := []string{
+		`UPDATE watch_history SET updated = created WHERE updated IS NULL`,
+		`UPDATE services SET updated = created WHERE updated IS NULL`,
+		`UPDATE scraper_runs SET updated = ran_at WHERE updated IS NULL`,
+		`CREATE TABLE IF NOT EXISTS watch_history_deletions (
+			history_id INTEGER PRIMARY KEY,
+			deleted TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_watch_history_updated ON watch_history(updated)`,
+	}
+	return execAll(tx, append(stmts, tx.dialect.triggers()...))
+}
+
+func dropUpdatedTriggers(tx *Tx) error {
+	stmts := append(tx.dialect.dropTriggers(),
+		`DROP INDEX IF EXISTS idx_watch_history_updated`,
+		`DROP TABLE IF EXISTS watch_history_deletions`,
+	)
+	return execAll(tx, stmts)
+}
+
+// trackHistoryUpdates returns a step recreating the trigger that bumps
+// watch_history's updated timestamp to watch the given columns
+func trackHistoryUpdates(columns []string) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		return execAll(tx, tx.dialect.historyUpdatedTrigger(columns))
+	}
+}
+
+// labelUsageConfidence labels app usage stored before confidence labels existed
+func labelUsageConfidence(tx *Tx) error {
+	_, err := tx.Exec(`UPDATE watch_history SET confidence = 'medium' WHERE COALESCE(confidence, '') = '' AND title IN ('Screen Time', 'Device Usage')`)
+	return err
+}
+
+// profileColumns attribute history and runs to the profile they were scraped for
+var profileColumns = []column{
+	{"watch_history", "profile_id", "INTEGER NOT NULL DEFAULT 0"},
+	{"scraper_runs", "profile_id", "INTEGER NOT NULL DEFAULT 0"},
+	{"pending_items", "profile_id", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// profileKeyTables hold one entry per title and time, now per profile too, so
+// two people watching the same episode on the same day each keep theirs
+var profileKeyTables = []string{"watch_history", "pending_items"}
+
+var (
+	historyKey        = []string{"service_id", "title", "watched_at"}
+	profileHistoryKey = []string{"service_id", "profile_id", "title", "watched_at"}
+)
+
+func createProfiles(tx *Tx) error {
+	if err := execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS profiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}); err != nil {
+		return err
+	}
+
+	// The key already includes the profile if an unversioned database is re-migrated
+	keyed, err := tx.dialect.columnExists(tx, "watch_history", "profile_id")
+	if err != nil {
+		return err
+	}
+	if err := addColumns(profileColumns)(tx); err != nil {
+		return err
+	}
+	if !keyed {
+		for _, table := range profileKeyTables {
+			if err := tx.dialect.changeUnique(tx, table, historyKey, profileHistoryKey); err != nil {
+				return err
+			}
+		}
+	}
+	return execAll(tx, []string{
+		`CREATE INDEX IF NOT EXISTS idx_watch_history_profile_id ON watch_history(profile_id)`,
+	})
+}
+
+// dropProfiles fails if the same entry was stored for several profiles, since
+// the old unique key can only hold one of them
+func dropProfiles(tx *Tx) error {
+	if _, err := tx.Exec(`DROP INDEX IF EXISTS idx_watch_history_profile_id`); err != nil {
+		return err
+	}
+	for _, table := range profileKeyTables {
+		if err := tx.dialect.changeUnique(tx, table, profileHistoryKey, historyKey); err != nil {
+			return err
+		}
+	}
+	if err := dropColumns(profileColumns)(tx); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DROP TABLE IF EXISTS profiles`)
+	return err
+}
+
+// createMaturityRatings caches each title's content rating per region, since
+// certifications differ between countries
+func createMaturityRatings(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS title_maturity_ratings (
+			title TEXT NOT NULL COLLATE NOCASE,
+			region TEXT NOT NULL,
+			tmdb_id INTEGER NOT NULL DEFAULT 0,
+			rating TEXT NOT NULL DEFAULT '',
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (title, region)
+		)`,
+	})
+}
+
+func dropMaturityRatings(tx *Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS title_maturity_ratings`)
+	return err
+}
+
+// searchIndexTriggers keep the external-content FTS5 index in step with
+// watch_history. Deletes must repeat the indexed values to remove them.
+var searchIndexTriggers = []struct{ name, stmt string }{
+	{"watch_history_fts_insert", `CREATE TRIGGER IF NOT EXISTS watch_history_fts_insert AFTER INSERT ON watch_history
+		BEGIN
+			INSERT INTO watch_history_fts (rowid, title, episode_info, notes) VALUES (NEW.id, NEW.title, NEW.episode_info, NEW.notes);
+		END`},
+	{"watch_history_fts_delete", `CREATE TRIGGER IF NOT EXISTS watch_history_fts_delete AFTER DELETE ON watch_history
+		BEGIN
+			INSERT INTO watch_history_fts (watch_history_fts, rowid, title, episode_info, notes) VALUES ('delete', OLD.id, OLD.title, OLD.episode_info, OLD.notes);
+		END`},
+	{"watch_history_fts_update", `CREATE TRIGGER IF NOT EXISTS watch_history_fts_update AFTER UPDATE OF title, episode_info, notes ON watch_history
+		BEGIN
+			INSERT INTO watch_history_fts (watch_history_fts, rowid, title, episode_info, notes) VALUES ('delete', OLD.id, OLD.title, OLD.episode_info, OLD.notes);
+			INSERT INTO watch_history_fts (rowid, title, episode_info, notes) VALUES (NEW.id, NEW.title, NEW.episode_info, NEW.notes);
+		END`},
+}
+
+// syncSearchIndex indexes titles, episodes and notes for ranked search on
+// SQLite builds with FTS5, building the index when it is missing or was left
+// without its triggers. Other builds drop the triggers, which they can't
+// run, so moving a database between builds never needs a migration.
+func (db *DB) syncSearchIndex() error {
+	supported, err := db.dialect.fullTextSearch(db)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return db.dropSearchIndex()
+	}
+	exists, err := db.dialect.tableExists(db, "watch_history_fts")
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var triggers int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'watch_history_fts_%'`).Scan(&triggers); err != nil {
+		return err
+	}
+	if exists && triggers == len(searchIndexTriggers) {
+		return nil
+	}
+
+	stmts := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS watch_history_fts USING fts5(
+			title, episode_info, notes,
+			content = 'watch_history', content_rowid = 'id',
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+	}
+	for _, trigger := range searchIndexTriggers {
+		stmts = append(stmts, trigger.stmt)
+	}
+	// Index the history stored so far, or stored while the triggers were gone
+	stmts = append(stmts, `INSERT INTO watch_history_fts (watch_history_fts) VALUES ('rebuild')`)
+	if err := execAll(tx, stmts); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// dropSearchIndex removes the search index triggers, and the index itself
+// when this build has FTS5 to drop it with. Without FTS5 the index table is
+// left behind and rebuilt if the database moves back to an FTS5 build.
+func (db *DB) dropSearchIndex() error {
+	exists, err := db.dialect.tableExists(db, "watch_history_fts")
+	if err != nil || !exists {
+		return err
+	}
+	supported, err := db.dialect.fullTextSearch(db)
+	if err != nil {
+		return err
+	}
+
+	var stmts []string
+	for _, trigger := range searchIndexTriggers {
+		stmts = append(stmts, "DROP TRIGGER IF EXISTS "+trigger.name)
+	}
+	if supported {
+		stmts = append(stmts, `DROP TABLE IF EXISTS watch_history_fts`)
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createEpisodeAirDates caches TV shows' latest episodes and per-episode air
+// dates, for measuring how soon new episodes are watched
+func createEpisodeAirDates(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS tv_shows (
+			title TEXT PRIMARY KEY COLLATE NOCASE,
+			tmdb_id INTEGER NOT NULL DEFAULT 0,
+			latest_season INTEGER NOT NULL DEFAULT 0,
+			latest_episode INTEGER NOT NULL DEFAULT 0,
+			latest_air_date TEXT NOT NULL DEFAULT '',
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS episode_air_dates (
+			tmdb_id INTEGER NOT NULL,
+			season INTEGER NOT NULL,
+			episode INTEGER NOT NULL,
+			air_date TEXT NOT NULL,
+			PRIMARY KEY (tmdb_id, season, episode)
+		)`,
+	})
+}
+
+func dropEpisodeAirDates(tx *Tx) error {
+	return execAll(tx, []string{
+		`DROP TABLE IF EXISTS episode_air_dates`,
+		`DROP TABLE IF EXISTS tv_shows`,
+	})
+}
+
+// upcomingColumns cache the next scheduled episode of each TV show
+var upcomingColumns = []column{
+	{"tv_shows", "next_season", "INTEGER NOT NULL DEFAULT 0"},
+	{"tv_shows", "next_episode", "INTEGER NOT NULL DEFAULT 0"},
+	{"tv_shows", "next_name", "TEXT NOT NULL DEFAULT ''"},
+	{"tv_shows", "next_air_date", "TEXT NOT NULL DEFAULT ''"},
+}
+
+func createNotifications(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS notifications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			title TEXT NOT NULL,
+			message TEXT NOT NULL,
+			created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_notifications_created ON notifications(created)`,
+		`CREATE TABLE IF NOT EXISTS show_notices (
+			title TEXT PRIMARY KEY COLLATE NOCASE,
+			notified_season INTEGER NOT NULL DEFAULT 0,
+			muted BOOLEAN NOT NULL DEFAULT FALSE
+		)`,
+	})
+}
+
+func dropNotifications(tx *Tx) error {
+	return execAll(tx, []string{
+		`DROP TABLE IF EXISTS show_notices`,
+		`DROP TABLE IF EXISTS notifications`,
+	})
+}
+
+func createTitleAvailability(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS title_availability (
+			title TEXT NOT NULL COLLATE NOCASE,
+			service_id INTEGER NOT NULL,
+			region TEXT NOT NULL,
+			tmdb_id INTEGER NOT NULL DEFAULT 0,
+			available BOOLEAN NOT NULL DEFAULT FALSE,
+			gone_since TIMESTAMP,
+			checked TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (title, service_id, region)
+		)`,
+	})
+}
+
+func dropTitleAvailability(tx *Tx) error {
+	return execAll(tx, []string{`DROP TABLE IF EXISTS title_availability`})
+}
+
+func createTitleMetadata(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS title_metadata (
+			title TEXT PRIMARY KEY COLLATE NOCASE,
+			tmdb_id INTEGER NOT NULL DEFAULT 0,
+			media_type TEXT NOT NULL DEFAULT '',
+			runtime INTEGER NOT NULL DEFAULT 0,
+			genre TEXT NOT NULL DEFAULT '',
+			thumbnail_url TEXT NOT NULL DEFAULT '',
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	})
+}
+
+func dropTitleMetadata(tx *Tx) error {
+	return execAll(tx, []string{`DROP TABLE IF EXISTS title_metadata`})
+}
+
+func createServiceConsent(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS service_consent (
+			service_id INTEGER PRIMARY KEY,
+			accepted BOOLEAN NOT NULL DEFAULT FALSE,
+			source TEXT NOT NULL DEFAULT '',
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	})
+}
+
+func dropServiceConsent(tx *Tx) error {
+	return execAll(tx, []string{`DROP TABLE IF EXISTS service_consent`})
+}
+
+func createTMDBCache(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS tmdb_cache (
+			request TEXT NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			response TEXT NOT NULL,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (request, title)
+		)`,
+	})
+}
+
+func dropTMDBCache(tx *Tx) error {
+	return execAll(tx, []string{`DROP TABLE IF EXISTS tmdb_cache`})
+}
+
+// rejectedColumns count the scraped items each run rejected as invalid
+var rejectedColumns = []column{
+	{"scraper_runs", "items_rejected", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// droppedColumns count the valid scraped items each run didn't store: those
+// outside its lookback or on compacted days, and ignored titles
+var droppedColumns = []column{
+	{"scraper_runs", "items_skipped", "INTEGER NOT NULL DEFAULT 0"},
+	{"scraper_runs", "items_ignored", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// serviceKeyColumns hold the config key each service is routed by, e.g. "netflix"
+var serviceKeyColumns = []column{
+	{"services", "service_key", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// createServiceKeys adds service keys, unique among services that have one,
+// and gives the seeded services their built-in keys
+func createServiceKeys(tx *Tx) error {
+	if err := addColumns(serviceKeyColumns)(tx); err != nil {
+		return err
+	}
+	if err := execAll(tx, []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_services_service_key ON services(service_key) WHERE service_key != ''`,
+	}); err != nil {
+		return err
+	}
+	return keyServices(initialServiceKeys)(tx)
+}
+
+func dropServiceKeys(tx *Tx) error {
+	if err := execAll(tx, []string{`DROP INDEX IF EXISTS idx_services_service_key`}); err != nil {
+		return err
+	}
+	return dropColumns(serviceKeyColumns)(tx)
+}
+
+func createTraktSync(tx *Tx) error {
+	return execAll(tx, []string{
+		`CREATE TABLE IF NOT EXISTS trakt_auth (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			access_token TEXT NOT NULL,
+			refresh_token TEXT NOT NULL,
+			expires TIMESTAMP NOT NULL,
+			last_pulled TIMESTAMP,
+			updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS trakt_pushed (
+			watch_history_id INTEGER PRIMARY KEY,
+			watched_at INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			tmdb_id INTEGER NOT NULL DEFAULT 0,
+			found BOOLEAN NOT NULL DEFAULT TRUE,
+			pushed TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_trakt_pushed_play ON trakt_pushed(watched_at, title)`,
+	})
+}
+
+func dropTraktSync(tx *Tx) error {
+	return execAll(tx, []string{
+		`DROP TABLE IF EXISTS trakt_pushed`,
+		`DROP TABLE IF EXISTS trakt_auth`,
+	})
+}
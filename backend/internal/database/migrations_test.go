@@ -0,0 +1,94 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateRecordsSchemaVersion(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("Failed to read schema version: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("Expected schema version %d, got %d", LatestSchemaVersion(), version)
+	}
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no pending migrations, applied %v", applied)
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "streamtime.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	reverted, err := db.MigrateDown(1)
+	if err != nil {
+		t.Fatalf("Failed to migrate down: %v", err)
+	}
+	if len(reverted) != LatestSchemaVersion()-1 {
+		t.Errorf("Expected %d migrations reverted, got %v", LatestSchemaVersion()-1, reverted)
+	}
+	if exists, _ := db.dialect.columnExists(db, "watch_history", "notes"); exists {
+		t.Error("Expected later columns to be dropped")
+	}
+	if exists, _ := db.dialect.tableExists(db, "services"); !exists {
+		t.Error("Expected the initial schema to be kept")
+	}
+
+	if _, err := db.MigrateDown(0); err != nil {
+		t.Fatalf("Failed to migrate down to an empty database: %v", err)
+	}
+	if exists, _ := db.dialect.tableExists(db, "services"); exists {
+		t.Error("Expected the initial schema to be dropped")
+	}
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("Failed to migrate up again: %v", err)
+	}
+	if len(applied) != LatestSchemaVersion() {
+		t.Errorf("Expected every migration applied, got %v", applied)
+	}
+	if exists, _ := db.dialect.columnExists(db, "watch_history", "notes"); !exists {
+		t.Error("Expected later columns to be added back")
+	}
+}
+
+func TestMigrateUnversionedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "streamtime.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	// A database from before versioning, where a default genre mapping was deleted
+	db.Exec(`DROP TABLE schema_migrations`)
+	db.Exec(`DELETE FROM genre_mappings WHERE source = 'Comedies'`)
+	db.Close()
+
+	db, err = New(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen unversioned database: %v", err)
+	}
+	defer db.Close()
+
+	if version, _ := db.SchemaVersion(); version != LatestSchemaVersion() {
+		t.Errorf("Expected schema version %d, got %d", LatestSchemaVersion(), version)
+	}
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM genre_mappings WHERE source = 'Comedies'`).Scan(&count)
+	if count != 0 {
+		t.Error("Expected the deleted genre mapping to stay deleted")
+	}
+}
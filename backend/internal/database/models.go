@@ -6,45 +6,392 @@ import (
 
 // Service represents a streaming service (Netflix, YouTube TV, etc.)
 type Service struct {
-	ID       int64  `json:"id"`
-	Name     string `json:"name"`
-	Color    string `json:"color"`    // Hex color for UI
-	LogoURL  string `json:"logo_url"` // URL or path to logo
-	Enabled  bool   `json:"enabled"`
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Key      string    `json:"key"`      // Config key the API and scrapers route by, e.g. "netflix"
+	Color    string    `json:"color"`    // Hex color for UI
+	LogoURL  string    `json:"logo_url"` // URL or path to logo
+	Enabled  bool      `json:"enabled"`
+	Archived bool      `json:"archived"` // Set when merged into another service
 	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// Profile is a person sharing the server, whose history is scraped with
+// their own cookies on each service they're configured for
+type Profile struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
 }
 
 // WatchHistory represents a single viewing session
 type WatchHistory struct {
-	ID              int64     `json:"id"`
-	ServiceID       int64     `json:"service_id"`
-	ServiceName     string    `json:"service_name"`
-	Title           string    `json:"title"`
-	DurationMinutes int       `json:"duration_minutes"`
-	WatchedAt       time.Time `json:"watched_at"`
-	EpisodeInfo     string    `json:"episode_info"`  // e.g., "S01E05"
-	ThumbnailURL    string    `json:"thumbnail_url"`
-	Genre           string    `json:"genre"`
-	Created         time.Time `json:"created"`
+	ID              int64       `json:"id"`
+	ServiceID       int64       `json:"service_id"`
+	ServiceName     string      `json:"service_name"`
+	Title           string      `json:"title"`
+	DurationMinutes int         `json:"duration_minutes"`
+	WatchedAt       time.Time   `json:"watched_at"`
+	EpisodeInfo     string      `json:"episode_info"` // e.g., "S01E05"
+	ThumbnailURL    string      `json:"thumbnail_url"`
+	Genre           string      `json:"genre"`
+	Device          string      `json:"device,omitempty"`     // e.g., "Living Room TV", from imports and ingests; scrapers don't see it
+	Location        string      `json:"location,omitempty"`   // e.g., country, from Netflix's account data download
+	MediaKind       string      `json:"media_kind"`           // MediaKindVideo or MediaKindAudio
+	Notes           string      `json:"notes,omitempty"`      // User annotation, e.g. "watched with parents"
+	Rating          int         `json:"rating,omitempty"`     // Personal rating 1-5, 0 when unrated
+	Confidence      string      `json:"confidence,omitempty"` // ConfidenceMedium or ConfidenceLow for inferred sessions; empty when read from the service
+	Raw             *RawPayload `json:"-"`                    // What the scraper read, for reprocessing
+	ProfileID       int64       `json:"profile_id,omitempty"` // Profile it was scraped for; 0 when not attributed to one
+	Created         time.Time   `json:"created"`
+	Updated         time.Time   `json:"updated"`
 }
 
+// RawPayload is what a scraper read for an entry before parsing it, stored so
+// entries can be re-derived after a parser fix without scraping the site again
+type RawPayload struct {
+	Title     string    `json:"title"`
+	Episode   string    `json:"episode,omitempty"`
+	Date      string    `json:"date,omitempty"` // Date text as shown, e.g. "Yesterday"
+	Time      string    `json:"time,omitempty"` // Time text, for services that show it separately
+	Runtime   string    `json:"runtime,omitempty"`
+	Year      string    `json:"year,omitempty"`
+	Format    string    `json:"format,omitempty"`
+	Platform  string    `json:"platform,omitempty"` // e.g. "YouTube TV" on Google My Activity
+	URL       string    `json:"url,omitempty"`      // Link the row points at, e.g. a YouTube video
+	HTML      string    `json:"html,omitempty"`     // The row's markup, truncated
+	ScrapedAt time.Time `json:"scraped_at"`         // Resolves relative dates like "Yesterday"
+}
+
+// Confidence labels for sessions inferred rather than read from a service's
+// history. Entries read from the service have no label.
+const (
+	ConfidenceMedium = "medium" // App usage reported by devices, like Screen Time
+	ConfidenceLow    = "low"    // Streaming traffic seen on the home network
+)
+
+// ScreenTimeTitle is the title of entries estimated from Screen Time. They
+// are daily totals placed at noon, with no time of day of their own.
+const ScreenTimeTitle = "Screen Time"
+
+// Media kinds for watch history entries
+const (
+	MediaKindVideo = "video"
+	MediaKindAudio = "audio" // Audiobooks and other listening time
+)
+
 // ScraperRun tracks scraper execution history
 type ScraperRun struct {
-	ID           int64     `json:"id"`
-	ServiceID    int64     `json:"service_id"`
-	RanAt        time.Time `json:"ran_at"`
-	Status       string    `json:"status"` // "success", "failed", "partial"
-	ErrorMessage string    `json:"error_message,omitempty"`
-	ItemsScraped int       `json:"items_scraped"`
+	ID            int64          `json:"id"`
+	ServiceID     int64          `json:"service_id"`
+	RanAt         time.Time      `json:"ran_at"`
+	Status        string         `json:"status"` // "success", "failed", "partial"
+	ErrorMessage  string         `json:"error_message,omitempty"`
+	ItemsScraped  int            `json:"items_scraped"`
+	ItemsRejected int            `json:"items_rejected"`          // Items that failed validation and weren't stored
+	ItemsSkipped  int            `json:"items_skipped"`           // Items outside the lookback or on compacted days, not stored
+	ItemsIgnored  int            `json:"items_ignored"`           // Items with ignored titles, not stored
+	TriggeredBy   string         `json:"triggered_by"`            // "scheduled" or "manual"
+	SelectorHits  map[string]int `json:"selector_hits,omitempty"` // Elements matched per key selector, to spot site redesigns
+	Logs          string         `json:"-"`                       // Tail of the log output, kept for failed runs
+	DOMSnapshot   string         `json:"-"`                       // Last page HTML the scraper saw, kept for failed runs
+	ProfileID     int64          `json:"profile_id,omitempty"`    // Profile scraped, for services shared by several people
+	Updated       time.Time      `json:"updated"`
 }
 
 // ServiceStats represents aggregated statistics for a service
 type ServiceStats struct {
-	ServiceID       int64  `json:"service_id"`
-	ServiceName     string `json:"service_name"`
-	Color           string `json:"color"`
-	LogoURL         string `json:"logo_url"`
-	TotalMinutes    int    `json:"total_minutes"`
-	TotalShows      int    `json:"total_shows"`
-	LastWatched     *time.Time `json:"last_watched,omitempty"`
+	ServiceID        int64      `json:"service_id"`
+	ServiceName      string     `json:"service_name"`
+	Color            string     `json:"color"`
+	LogoURL          string     `json:"logo_url"`
+	TotalMinutes     int        `json:"total_minutes"`
+	TotalShows       int        `json:"total_shows"`
+	LastWatched      *time.Time `json:"last_watched,omitempty"`
+	EffectiveMinutes *int       `json:"effective_minutes,omitempty"` // Set by the API when intro/credits estimation is configured
+	AdMinutes        *int       `json:"ad_minutes,omitempty"`        // Set by the API for services marked ad_supported
+}
+
+// Import records a file that was imported for a service, identified by its content hash
+type Import struct {
+	ID          int64     `json:"id"`
+	ServiceID   int64     `json:"service_id"`
+	ContentHash string    `json:"content_hash"`
+	Filename    string    `json:"filename"`
+	TotalRows   int       `json:"total_rows"`
+	Imported    int       `json:"imported"`
+	Created     time.Time `json:"created"`
+}
+
+// IgnoredTitle is a title excluded from scraping, imports, and stats.
+// A ServiceID of 0 ignores the title on every service.
+type IgnoredTitle struct {
+	ID        int64     `json:"id"`
+	ServiceID int64     `json:"service_id"`
+	Title     string    `json:"title"`
+	Created   time.Time `json:"created"`
+}
+
+// DeviceStats represents aggregated watch time for a single device
+type DeviceStats struct {
+	Device       string  `json:"device"`
+	TotalMinutes int     `json:"total_minutes"`
+	TotalShows   int     `json:"total_shows"`
+	Percentage   float64 `json:"percentage"`
+}
+
+// MediaKindStats represents aggregated time for a single media kind
+type MediaKindStats struct {
+	MediaKind    string  `json:"media_kind"`
+	TotalMinutes int     `json:"total_minutes"`
+	TotalItems   int     `json:"total_items"`
+	Percentage   float64 `json:"percentage"`
+}
+
+// GenreMapping maps a provider's genre name to the genre used in stats
+type GenreMapping struct {
+	ID      int64     `json:"id"`
+	Source  string    `json:"source"`
+	Genre   string    `json:"genre"`
+	Created time.Time `json:"created"`
+}
+
+// GenreStats represents aggregated time for a single normalized genre
+type GenreStats struct {
+	Genre        string  `json:"genre"`
+	TotalMinutes int     `json:"total_minutes"`
+	TotalItems   int     `json:"total_items"`
+	Percentage   float64 `json:"percentage"`
+}
+
+// GamingSession is time spent playing a game, either synced from Steam or entered manually
+type GamingSession struct {
+	ID       int64     `json:"id"`
+	Platform string    `json:"platform"` // e.g., "steam", "switch", "playstation"
+	Game     string    `json:"game"`
+	Minutes  int       `json:"minutes"`
+	PlayedAt time.Time `json:"played_at"`
+	Source   string    `json:"source"` // "steam" or "manual"
+	Created  time.Time `json:"created"`
+}
+
+// PlatformStats represents aggregated gaming time for a single platform
+type PlatformStats struct {
+	Platform     string `json:"platform"`
+	TotalMinutes int    `json:"total_minutes"`
+	TotalGames   int    `json:"total_games"`
+}
+
+// TitleStats represents aggregated watch time for a single title across services
+type TitleStats struct {
+	Title        string `json:"title"`
+	TotalMinutes int    `json:"total_minutes"`
+	WatchCount   int    `json:"watch_count"`
+}
+
+// ScreenFreeDay is a day the user planned to keep screen-free
+type ScreenFreeDay struct {
+	Date    string    `json:"date"` // YYYY-MM-DD
+	Note    string    `json:"note"`
+	Source  string    `json:"source"` // "manual" or "caldav"
+	Created time.Time `json:"created"`
+}
+
+// TitleOrigin caches who produced a title, looked up on TMDB. A TMDBID of 0
+// means the title wasn't found.
+type TitleOrigin struct {
+	Title     string    `json:"title"`
+	TMDBID    int64     `json:"tmdb_id"`
+	MediaType string    `json:"media_type"`
+	Producers []string  `json:"producers"`
+	Updated   time.Time `json:"updated"`
+}
+
+// DailyServiceStats represents one service's watch time on one day
+type DailyServiceStats struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	ServiceID int64  `json:"service_id"`
+	Minutes   int    `json:"minutes"`
+}
+
+// RewatchStats splits watch time into first watches and rewatches
+type RewatchStats struct {
+	NewMinutes     int     `json:"new_minutes"`
+	NewCount       int     `json:"new_count"`
+	RewatchMinutes int     `json:"rewatch_minutes"`
+	RewatchCount   int     `json:"rewatch_count"`
+	RewatchRatio   float64 `json:"rewatch_ratio"`          // Share of minutes spent rewatching, 0-1
+	DetailSince    string  `json:"detail_since,omitempty"` // First day counted, when older history was compacted
+}
+
+// MaturityRating is a title's content rating (e.g. "TV-MA") in a region, as
+// looked up on TMDB. Rating is empty when no match or certification was found.
+type MaturityRating struct {
+	Title   string    `json:"title"`
+	Region  string    `json:"region"`
+	TMDBID  int64     `json:"tmdb_id"`
+	Rating  string    `json:"rating"`
+	Updated time.Time `json:"updated"`
+}
+
+// ServiceConsent records whether the user acknowledged the risk of automating
+// a service, which may break its terms of service, and when
+type ServiceConsent struct {
+	ServiceID int64     `json:"service_id"`
+	Accepted  bool      `json:"accepted"`
+	Source    string    `json:"source"`  // "config" or "api"
+	Updated   time.Time `json:"updated"` // When consent was given or withdrawn
+}
+
+// TraktAuth is the Trakt.tv account authorized for syncing history
+type TraktAuth struct {
+	AccessToken  string    `json:"-"`
+	RefreshToken string    `json:"-"`
+	Expires      time.Time `json:"expires"`
+	LastPulled   time.Time `json:"last_pulled"` // Zero until history is first pulled
+}
+
+// TitleMetadata caches what TMDB knows about a title, used to fill in details
+// scrapers couldn't read. A TMDBID of 0 means the title wasn't found.
+type TitleMetadata struct {
+	Title        string    `json:"title"`
+	TMDBID       int64     `json:"tmdb_id"`
+	MediaType    string    `json:"media_type"`    // "movie" or "tv"
+	Runtime      int       `json:"runtime"`       // Minutes; an episode's for TV shows
+	Genre        string    `json:"genre"`         // First genre TMDB lists
+	ThumbnailURL string    `json:"thumbnail_url"` // Poster image
+	Updated      time.Time `json:"updated"`
+}
+
+// TVShow caches a show's most recently aired and next scheduled episodes,
+// looked up on TMDB. A TMDBID of 0 means the title wasn't found as a TV show.
+type TVShow struct {
+	Title         string    `json:"title"`
+	TMDBID        int64     `json:"tmdb_id"`
+	LatestSeason  int       `json:"latest_season"`
+	LatestEpisode int       `json:"latest_episode"`
+	LatestAirDate string    `json:"latest_air_date"` // YYYY-MM-DD, empty if nothing has aired
+	NextSeason    int       `json:"next_season"`
+	NextEpisode   int       `json:"next_episode"`
+	NextName      string    `json:"next_name"`
+	NextAirDate   string    `json:"next_air_date"` // YYYY-MM-DD, empty if nothing is scheduled
+	Updated       time.Time `json:"updated"`
+}
+
+// EpisodeAirDate is when one episode of a TV show first aired
+type EpisodeAirDate struct {
+	Season  int    `json:"season"`
+	Episode int    `json:"episode"`
+	AirDate string `json:"air_date"` // YYYY-MM-DD
+}
+
+// WatchedEpisode is an episode of a title and when it was first watched
+type WatchedEpisode struct {
+	Title        string    `json:"title"`
+	EpisodeInfo  string    `json:"episode_info"`
+	FirstWatched time.Time `json:"first_watched"`
+}
+
+// Notification is a message about a show in the watch history, such as a
+// new season premiering
+type Notification struct {
+	ID      int64     `json:"id"`
+	Kind    string    `json:"kind"` // e.g. "new_season"
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Created time.Time `json:"created"`
+}
+
+// ShowNotice tracks the notifications sent about one show
+type ShowNotice struct {
+	Title          string `json:"title"`
+	NotifiedSeason int    `json:"notified_season"` // Latest season known when last checked
+	Muted          bool   `json:"muted"`
+}
+
+// TitleAvailability is whether a title watched on a service is still offered
+// by it in a region, as of the last check on TMDB
+type TitleAvailability struct {
+	Title       string     `json:"title"`
+	ServiceID   int64      `json:"service_id"`
+	ServiceName string     `json:"service_name"`
+	Region      string     `json:"region"`
+	TMDBID      int64      `json:"tmdb_id"`
+	Available   bool       `json:"available"`
+	GoneSince   *time.Time `json:"gone_since"` // First check that found it missing after being available
+	Checked     time.Time  `json:"checked"`
+}
+
+// MaturityStats represents aggregated watch time for one content rating
+type MaturityStats struct {
+	Rating       string `json:"rating"`
+	TotalMinutes int    `json:"total_minutes"`
+	WatchCount   int    `json:"watch_count"`
+}
+
+// ServiceTitleStats represents aggregated watch time for a title on one service
+type ServiceTitleStats struct {
+	ServiceID    int64  `json:"service_id"`
+	ServiceName  string `json:"service_name"`
+	Title        string `json:"title"`
+	TotalMinutes int    `json:"total_minutes"`
+	WatchCount   int    `json:"watch_count"`
+}
+
+// SavedView is a named stat configuration the frontend renders as a dashboard
+type SavedView struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	DateRange   string    `json:"date_range"`           // "this_week", "this_month", "last_30_days", "this_year", "all_time" or "custom"
+	StartDate   string    `json:"start_date,omitempty"` // YYYY-MM-DD, custom ranges only
+	EndDate     string    `json:"end_date,omitempty"`   // YYYY-MM-DD inclusive, custom ranges only
+	ServiceIDs  []int64   `json:"service_ids"`          // Empty means all enabled services
+	Granularity string    `json:"granularity"`          // "day", "week" or "month"
+	ChartType   string    `json:"chart_type"`           // "line", "bar", "pie" or "table"
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// TitleAlias maps a variant title from one source to the canonical title used in stats
+type TitleAlias struct {
+	ID        int64     `json:"id"`
+	Alias     string    `json:"alias"`
+	Canonical string    `json:"canonical"`
+	Created   time.Time `json:"created"`
+}
+
+// PendingItem is a scraped entry held for review before it's added to watch
+// history, for services whose scraper isn't trusted yet
+type PendingItem struct {
+	ID              int64       `json:"id"`
+	ServiceID       int64       `json:"service_id"`
+	ServiceName     string      `json:"service_name"`
+	Title           string      `json:"title"`
+	DurationMinutes int         `json:"duration_minutes"`
+	WatchedAt       time.Time   `json:"watched_at"`
+	EpisodeInfo     string      `json:"episode_info"`
+	ThumbnailURL    string      `json:"thumbnail_url"`
+	Genre           string      `json:"genre"`
+	Device          string      `json:"device,omitempty"`
+	Location        string      `json:"location,omitempty"`
+	MediaKind       string      `json:"media_kind"`
+	Raw             *RawPayload `json:"-"`
+	ProfileID       int64       `json:"profile_id,omitempty"`
+	Created         time.Time   `json:"created"`
+}
+
+// RatingStats is the average personal rating for a group of entries, such as a service or genre
+type RatingStats struct {
+	Name          string  `json:"name"`
+	AverageRating float64 `json:"average_rating"`
+	RatedCount    int     `json:"rated_count"`
+}
+
+// TitleRating is the average personal rating for a title
+type TitleRating struct {
+	Title         string  `json:"title"`
+	AverageRating float64 `json:"average_rating"`
+	RatedCount    int     `json:"rated_count"`
+	TotalMinutes  int     `json:"total_minutes"`
 }
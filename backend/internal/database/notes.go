@@ -0,0 +1,87 @@
+package database
+
+import "strings"
+
+// UpdateWatchHistoryNotes sets the notes on a history entry. It reports
+// whether the entry exists.
+func (db *DB) UpdateWatchHistoryNotes(id int64, notes string) (bool, error) {
+	result, err := db.Exec(`UPDATE watch_history SET notes = ? WHERE id = ?`, notes, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// SearchWatchHistory returns history entries whose title, episode or notes
+// contain the query. With the full-text index, words match by prefix and the
+// best matches come first, weighting titles highest; otherwise the newest
+// entries containing the query come first.
+func (db *DB) SearchWatchHistory(query string, limit int) ([]WatchHistory, error) {
+	indexed, err := db.searchIndexed()
+	if err != nil {
+		return nil, err
+	}
+	if match := ftsQuery(query); indexed && match != "" {
+		rows, err := db.Query(`
+			SELECT `+watchHistoryColumns+`
+			FROM watch_history_fts
+			JOIN `+db.history()+` wh ON wh.id = watch_history_fts.rowid
+			JOIN services s ON wh.service_id = s.id
+			WHERE watch_history_fts MATCH ?
+			  AND `+notIgnoredClause+`
+			ORDER BY bm25(watch_history_fts, 10.0, 2.0, 1.0), wh.watched_at DESC
+			LIMIT ?
+		`, match, limit)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		return scanWatchHistory(rows)
+	}
+
+	pattern := "%" + likeEscaper.Replace(strings.TrimSpace(query)) + "%"
+	like := db.dialect.like()
+
+	rows, err := db.Query(`
+		SELECT `+watchHistoryColumns+`
+		FROM `+db.history()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE (wh.title `+like+` ? ESCAPE '\' OR wh.episode_info `+like+` ? ESCAPE '\' OR wh.notes `+like+` ? ESCAPE '\')
+		  AND `+notIgnoredClause+`
+		ORDER BY wh.watched_at DESC
+		LIMIT ?
+	`, pattern, pattern, pattern, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWatchHistory(rows)
+}
+
+// searchIndexed reports whether the FTS5 search index exists and can be
+// queried by this build
+func (db *DB) searchIndexed() (bool, error) {
+	supported, err := db.dialect.fullTextSearch(db)
+	if err != nil || !supported {
+		return false, err
+	}
+	return db.dialect.tableExists(db, "watch_history_fts")
+}
+
+// ftsQuery turns free text into an FTS5 query matching every word as a
+// prefix, quoting each so operators and punctuation are searched literally
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
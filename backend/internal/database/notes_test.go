@@ -0,0 +1,140 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchHistoryNotes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	watchedAt := time.Now()
+	wh := &WatchHistory{ServiceID: service.ID, Title: "The Crown", DurationMinutes: 55, WatchedAt: watchedAt}
+	db.InsertWatchHistory(wh)
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: watchedAt})
+
+	found, err := db.UpdateWatchHistoryNotes(wh.ID, "Watched with parents")
+	if err != nil || !found {
+		t.Fatalf("Failed to update notes: %v (found %v)", err, found)
+	}
+
+	// A re-scrape of the same entry keeps the notes
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "The Crown", DurationMinutes: 58, WatchedAt: watchedAt})
+
+	results, err := db.SearchWatchHistory("parents", 10)
+	if err != nil {
+		t.Fatalf("Failed to search history: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "The Crown" || results[0].Notes != "Watched with parents" {
+		t.Errorf("Unexpected search results: %+v", results)
+	}
+
+	found, err = db.UpdateWatchHistoryNotes(9999, "missing")
+	if err != nil || found {
+		t.Errorf("Expected missing entry to be reported, got found=%v err=%v", found, err)
+	}
+}
+
+func TestSearchWatchHistoryFullText(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	if indexed, _ := db.searchIndexed(); !indexed {
+		t.Skip("SQLite was built without FTS5; run with -tags sqlite_fts5")
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+	noted := &WatchHistory{ServiceID: service.ID, Title: "Severance", EpisodeInfo: "S1E1", DurationMinutes: 50, WatchedAt: now}
+	for _, wh := range []*WatchHistory{
+		{ServiceID: service.ID, Title: "The Crown", EpisodeInfo: "Season 1: Wolferton Splash", DurationMinutes: 55, WatchedAt: now.Add(-2 * time.Hour)},
+		{ServiceID: service.ID, Title: "Crowned Heads", DurationMinutes: 90, WatchedAt: now.Add(-time.Hour)},
+		noted,
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+	db.UpdateWatchHistoryNotes(noted.ID, "the crown jewel of the year")
+
+	// Title matches outrank the newer entry matching only in its notes, and
+	// words match by prefix
+	results, err := db.SearchWatchHistory("crown", 10)
+	if err != nil {
+		t.Fatalf("Failed to search history: %v", err)
+	}
+	if len(results) != 3 || results[2].Title != "Severance" {
+		t.Errorf("Expected both titles ahead of the notes match, got %+v", results)
+	}
+
+	// Operators are searched literally rather than breaking the query
+	if _, err := db.SearchWatchHistory(`wolferton" OR (`, 10); err != nil {
+		t.Errorf("Expected punctuation to be quoted, got %v", err)
+	}
+
+	// Edited and deleted entries leave the index
+	db.UpdateWatchHistoryNotes(noted.ID, "")
+	db.DeleteWatchHistoryEntries([]int64{results[0].ID})
+	results, _ = db.SearchWatchHistory("crown", 10)
+	if len(results) != 1 {
+		t.Errorf("Expected the index to follow edits and deletes, got %+v", results)
+	}
+}
+
+func TestSyncSearchIndexRebuildsMissingTriggers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	if indexed, _ := db.searchIndexed(); !indexed {
+		t.Skip("SQLite was built without FTS5; run with -tags sqlite_fts5")
+	}
+
+	// History stored by a build without FTS5, which drops the triggers, is
+	// indexed on the next start of an FTS5 build
+	for _, trigger := range searchIndexTriggers {
+		if _, err := db.Exec("DROP TRIGGER " + trigger.name); err != nil {
+			t.Fatalf("Failed to drop %s: %v", trigger.name, err)
+		}
+	}
+	service, _ := db.GetServiceByName("Netflix")
+	if err := db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "The Crown", DurationMinutes: 55, WatchedAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to insert history: %v", err)
+	}
+	if err := db.syncSearchIndex(); err != nil {
+		t.Fatalf("Failed to sync search index: %v", err)
+	}
+
+	results, err := db.SearchWatchHistory("crown", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected the entry stored without triggers to be indexed, got %+v (err %v)", results, err)
+	}
+}
+
+func TestSyncSearchIndexWithoutFTS5(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	if supported, _ := db.dialect.fullTextSearch(db); supported {
+		t.Skip("SQLite was built with FTS5")
+	}
+
+	// Stand in for an index left by an FTS5 build, whose triggers this build
+	// can't run
+	stmts := []string{`CREATE TABLE watch_history_fts (watch_history_fts TEXT, title TEXT, episode_info TEXT, notes TEXT)`}
+	for _, trigger := range searchIndexTriggers {
+		stmts = append(stmts, strings.Replace(trigger.stmt, "BEGIN", "BEGIN SELECT RAISE(ABORT, 'no such module: fts5');", 1))
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up index: %v", err)
+		}
+	}
+	if err := db.syncSearchIndex(); err != nil {
+		t.Fatalf("Failed to sync search index: %v", err)
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	if err := db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "The Crown", DurationMinutes: 55, WatchedAt: time.Now()}); err != nil {
+		t.Errorf("Expected history to be stored once the index triggers are dropped, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AddNotification records a notification
+func (db *DB) AddNotification(n *Notification) error {
+	n.Created = time.Now()
+	return db.QueryRow(`
+		INSERT INTO notifications (kind, title, message, created)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`, n.Kind, n.Title, n.Message, n.Created).Scan(&n.ID)
+}
+
+// GetNotifications returns the most recent notifications, newest first
+func (db *DB) GetNotifications(limit int) ([]Notification, error) {
+	rows, err := db.Query(`
+		SELECT id, kind, title, message, created
+		FROM notifications
+		ORDER BY created DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.Kind, &n.Title, &n.Message, &n.Created); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// GetShowNotice returns what has been notified about a show, or nil if it
+// hasn't been checked yet
+func (db *DB) GetShowNotice(title string) (*ShowNotice, error) {
+	var notice ShowNotice
+	err := db.QueryRow(`
+		SELECT title, notified_season, muted
+		FROM show_notices
+		WHERE title = ?
+	`, title).Scan(&notice.Title, &notice.NotifiedSeason, &notice.Muted)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &notice, nil
+}
+
+// SetNotifiedSeason records the latest season known for a show
+func (db *DB) SetNotifiedSeason(title string, season int) error {
+	_, err := db.Exec(`
+		INSERT INTO show_notices (title, notified_season)
+		VALUES (?, ?)
+		ON CONFLICT(title) DO UPDATE SET notified_season = excluded.notified_season
+	`, title, season)
+	return err
+}
+
+// SetShowMuted turns notifications about a show off or back on
+func (db *DB) SetShowMuted(title string, muted bool) error {
+	_, err := db.Exec(`
+		INSERT INTO show_notices (title, muted)
+		VALUES (?, ?)
+		ON CONFLICT(title) DO UPDATE SET muted = excluded.muted
+	`, title, muted)
+	return err
+}
+
+// GetMutedShows returns the titles of shows with notifications turned off
+func (db *DB) GetMutedShows() ([]string, error) {
+	rows, err := db.Query(`
+		SELECT title FROM show_notices
+		WHERE muted = TRUE
+		ORDER BY title
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []string{}
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+
+	return titles, rows.Err()
+}
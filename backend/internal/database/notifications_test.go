@@ -0,0 +1,62 @@
+package database
+
+import "testing"
+
+func TestNotifications(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	for _, n := range []*Notification{
+		{Kind: "new_season", Title: "Andor", Message: "Season 2 of Andor premiered"},
+		{Kind: "new_season", Title: "Fargo", Message: "Season 5 of Fargo premiered"},
+	} {
+		if err := db.AddNotification(n); err != nil {
+			t.Fatalf("Failed to add notification: %v", err)
+		}
+	}
+
+	notifications, err := db.GetNotifications(1)
+	if err != nil {
+		t.Fatalf("Failed to get notifications: %v", err)
+	}
+	if len(notifications) != 1 || notifications[0].Message != "Season 5 of Fargo premiered" {
+		t.Errorf("Expected only the newest notification, got %+v", notifications)
+	}
+}
+
+func TestShowNotices(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	notice, err := db.GetShowNotice("Andor")
+	if err != nil || notice != nil {
+		t.Fatalf("Expected no notice, got %+v (%v)", notice, err)
+	}
+
+	if err := db.SetNotifiedSeason("Andor", 1); err != nil {
+		t.Fatalf("Failed to set notified season: %v", err)
+	}
+	if err := db.SetShowMuted("andor", true); err != nil {
+		t.Fatalf("Failed to mute show: %v", err)
+	}
+	if err := db.SetNotifiedSeason("Andor", 2); err != nil {
+		t.Fatalf("Failed to set notified season: %v", err)
+	}
+
+	// Muting and the notified season are kept independently
+	notice, err = db.GetShowNotice("ANDOR")
+	if err != nil || notice == nil || notice.NotifiedSeason != 2 || !notice.Muted {
+		t.Fatalf("Expected a muted notice at season 2, got %+v (%v)", notice, err)
+	}
+	muted, err := db.GetMutedShows()
+	if err != nil || len(muted) != 1 || muted[0] != "Andor" {
+		t.Errorf("Expected Andor muted, got %v (%v)", muted, err)
+	}
+
+	if err := db.SetShowMuted("Andor", false); err != nil {
+		t.Fatalf("Failed to unmute show: %v", err)
+	}
+	if muted, _ := db.GetMutedShows(); len(muted) != 0 {
+		t.Errorf("Expected no muted shows, got %v", muted)
+	}
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// producerSeparator joins producer names in the title_origins table
+const producerSeparator = "|"
+
+// GetTitleOrigin returns the cached producers for a title, or nil if the title
+// hasn't been looked up yet
+func (db *DB) GetTitleOrigin(title string) (*TitleOrigin, error) {
+	var origin TitleOrigin
+	var producers string
+	err := db.QueryRow(`
+		SELECT title, tmdb_id, media_type, producers, updated
+		FROM title_origins
+		WHERE title = ?
+	`, title).Scan(&origin.Title, &origin.TMDBID, &origin.MediaType, &producers, &origin.Updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	origin.Producers = []string{}
+	if producers != "" {
+		origin.Producers = strings.Split(producers, producerSeparator)
+	}
+	return &origin, nil
+}
+
+// SetTitleOrigin caches the producers for a title
+func (db *DB) SetTitleOrigin(origin *TitleOrigin) error {
+	_, err := db.Exec(`
+		INSERT INTO title_origins (title, tmdb_id, media_type, producers, updated)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(title) DO UPDATE SET
+			tmdb_id = excluded.tmdb_id,
+			media_type = excluded.media_type,
+			producers = excluded.producers,
+			updated = excluded.updated
+	`, origin.Title, origin.TMDBID, origin.MediaType, strings.Join(origin.Producers, producerSeparator), time.Now())
+	return err
+}
+
+// GetServiceTitleStats returns watch time per title on each enabled service,
+// ordered by service and then by watch time
+func (db *DB) GetServiceTitleStats(startDate, endDate time.Time) ([]ServiceTitleStats, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.name, wh.title, SUM(wh.duration_minutes) as total_minutes, SUM(wh.entries) as watch_count
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY s.id, wh.title COLLATE NOCASE
+		ORDER BY s.name, total_minutes DESC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []ServiceTitleStats{}
+	for rows.Next() {
+		var st ServiceTitleStats
+		if err := rows.Scan(&st.ServiceID, &st.ServiceName, &st.Title, &st.TotalMinutes, &st.WatchCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
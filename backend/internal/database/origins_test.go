@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTitleOriginCache(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	origin, err := db.GetTitleOrigin("Stranger Things")
+	if err != nil || origin != nil {
+		t.Fatalf("Expected no cached origin, got %+v (%v)", origin, err)
+	}
+
+	err = db.SetTitleOrigin(&TitleOrigin{
+		Title:     "Stranger Things",
+		TMDBID:    66732,
+		MediaType: "tv",
+		Producers: []string{"Netflix", "21 Laps Entertainment"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to cache origin: %v", err)
+	}
+
+	origin, err = db.GetTitleOrigin("stranger things")
+	if err != nil || origin == nil {
+		t.Fatalf("Expected cached origin, got %v", err)
+	}
+	if origin.TMDBID != 66732 || len(origin.Producers) != 2 || origin.Producers[1] != "21 Laps Entertainment" {
+		t.Errorf("Unexpected origin: %+v", origin)
+	}
+}
+
+func TestGetServiceTitleStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	now := time.Now()
+	db.InsertWatchHistory(&WatchHistory{ServiceID: netflix.ID, Title: "Show", EpisodeInfo: "E1", DurationMinutes: 30, WatchedAt: now})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: netflix.ID, Title: "Show", EpisodeInfo: "E2", DurationMinutes: 30, WatchedAt: now.Add(-time.Minute)})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: netflix.ID, Title: "Film", DurationMinutes: 100, WatchedAt: now})
+
+	stats, err := db.GetServiceTitleStats(now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get title stats: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Title != "Film" || stats[1].TotalMinutes != 60 {
+		t.Errorf("Unexpected title stats: %+v", stats)
+	}
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// InsertPendingItem holds a scraped entry for review. Scraping the same entry
+// again updates the pending copy rather than adding another.
+func (db *DB) InsertPendingItem(wh *WatchHistory) error {
+	mediaKind := wh.MediaKind
+	if mediaKind == "" {
+		mediaKind = MediaKindVideo
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO pending_items
+		(service_id, title, duration_minutes, watched_at, episode_info, thumbnail_url, genre, device, location, media_kind, raw_payload,
+		 profile_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(service_id, profile_id, title, watched_at) DO UPDATE SET
+			duration_minutes = excluded.duration_minutes,
+			episode_info = excluded.episode_info,
+			thumbnail_url = excluded.thumbnail_url,
+			genre = excluded.genre,
+			device = excluded.device,
+			location = excluded.location,
+			media_kind = excluded.media_kind,
+			raw_payload = excluded.raw_payload
+	`, wh.ServiceID, wh.Title, wh.DurationMinutes, wh.WatchedAt,
+		wh.EpisodeInfo, wh.ThumbnailURL, wh.Genre, wh.Device, wh.Location, mediaKind, encodeRawPayload(wh.Raw),
+		wh.ProfileID)
+	return err
+}
+
+// pendingItemColumns are the columns scanned by scanPendingItem
+const pendingItemColumns = `
+	p.id, p.service_id, s.name, p.title, p.duration_minutes, p.watched_at,
+	COALESCE(p.episode_info, ''), COALESCE(p.thumbnail_url, ''), COALESCE(p.genre, ''),
+	COALESCE(p.device, ''), COALESCE(p.location, ''), COALESCE(p.media_kind, 'video'),
+	COALESCE(p.raw_payload, ''), p.profile_id, p.created`
+
+// scanPendingItem scans a row selected with pendingItemColumns
+func scanPendingItem(row rowScanner) (PendingItem, error) {
+	var p PendingItem
+	var raw string
+	err := row.Scan(&p.ID, &p.ServiceID, &p.ServiceName, &p.Title, &p.DurationMinutes, &p.WatchedAt,
+		&p.EpisodeInfo, &p.ThumbnailURL, &p.Genre, &p.Device, &p.Location, &p.MediaKind, &raw, &p.ProfileID, &p.Created)
+	p.Raw = decodeRawPayload(raw)
+	return p, err
+}
+
+// GetPendingItems returns items awaiting review, newest first. A serviceID of
+// 0 returns every service's items.
+func (db *DB) GetPendingItems(serviceID int64) ([]PendingItem, error) {
+	rows, err := db.Query(`
+		SELECT `+pendingItemColumns+`
+		FROM pending_items p
+		JOIN services s ON p.service_id = s.id
+		WHERE ? = 0 OR p.service_id = ?
+		ORDER BY p.watched_at DESC
+	`, serviceID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []PendingItem{}
+	for rows.Next() {
+		item, err := scanPendingItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetPendingItem returns a single pending item, or nil if not found
+func (db *DB) GetPendingItem(id int64) (*PendingItem, error) {
+	item, err := scanPendingItem(db.QueryRow(`
+		SELECT `+pendingItemColumns+`
+		FROM pending_items p
+		JOIN services s ON p.service_id = s.id
+		WHERE p.id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdatePendingItem saves edits to a pending item's title, duration, watch
+// time, episode and genre. It reports whether the item exists.
+func (db *DB) UpdatePendingItem(item *PendingItem) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE pending_items
+		SET title = ?, duration_minutes = ?, watched_at = ?, episode_info = ?, genre = ?
+		WHERE id = ?
+	`, item.Title, item.DurationMinutes, item.WatchedAt, item.EpisodeInfo, item.Genre, item.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ApprovePendingItems moves pending items into watch history, returning how
+// many were approved. IDs that aren't pending are skipped.
+func (db *DB) ApprovePendingItems(ids []int64) (int, error) {
+	approved := 0
+	for _, id := range ids {
+		item, err := db.GetPendingItem(id)
+		if err != nil {
+			return approved, err
+		}
+		if item == nil {
+			continue
+		}
+
+		if err := db.InsertWatchHistory(&WatchHistory{
+			ServiceID:       item.ServiceID,
+			Title:           item.Title,
+			DurationMinutes: item.DurationMinutes,
+			WatchedAt:       item.WatchedAt,
+			EpisodeInfo:     item.EpisodeInfo,
+			ThumbnailURL:    item.ThumbnailURL,
+			Genre:           item.Genre,
+			Device:          item.Device,
+			Location:        item.Location,
+			MediaKind:       item.MediaKind,
+			Raw:             item.Raw,
+			ProfileID:       item.ProfileID,
+		}); err != nil {
+			return approved, err
+		}
+		if _, err := db.Exec(`DELETE FROM pending_items WHERE id = ?`, id); err != nil {
+			return approved, err
+		}
+		approved++
+	}
+
+	return approved, nil
+}
+
+// RejectPendingItems discards pending items, returning how many were removed
+func (db *DB) RejectPendingItems(ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	result, err := db.Exec(`DELETE FROM pending_items WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApproveAndRejectPendingItems(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now().Truncate(time.Second)
+
+	for _, title := range []string{"Keep Me", "Drop Me"} {
+		if err := db.InsertPendingItem(&WatchHistory{ServiceID: service.ID, Title: title, DurationMinutes: 30, WatchedAt: now}); err != nil {
+			t.Fatalf("Failed to insert pending item: %v", err)
+		}
+	}
+
+	items, err := db.GetPendingItems(service.ID)
+	if err != nil {
+		t.Fatalf("Failed to get pending items: %v", err)
+	}
+	if len(items) != 2 || items[0].ServiceName != "Netflix" {
+		t.Fatalf("Expected 2 pending Netflix items, got %+v", items)
+	}
+
+	var keep, drop PendingItem
+	for _, item := range items {
+		if item.Title == "Keep Me" {
+			keep = item
+		} else {
+			drop = item
+		}
+	}
+
+	keep.DurationMinutes = 45
+	if ok, err := db.UpdatePendingItem(&keep); err != nil || !ok {
+		t.Fatalf("Failed to update pending item: %v", err)
+	}
+
+	if approved, err := db.ApprovePendingItems([]int64{keep.ID, 9999}); err != nil || approved != 1 {
+		t.Fatalf("Expected 1 approved item, got %d (%v)", approved, err)
+	}
+	if rejected, err := db.RejectPendingItems([]int64{drop.ID}); err != nil || rejected != 1 {
+		t.Fatalf("Expected 1 rejected item, got %d (%v)", rejected, err)
+	}
+
+	history, err := db.GetWatchHistory(service.ID, now.Add(-time.Hour), now.Add(time.Hour), 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get watch history: %v", err)
+	}
+	if len(history) != 1 || history[0].Title != "Keep Me" || history[0].DurationMinutes != 45 {
+		t.Errorf("Expected only the edited approved item in history, got %+v", history)
+	}
+
+	if items, _ := db.GetPendingItems(0); len(items) != 0 {
+		t.Errorf("Expected the review queue to be empty, got %+v", items)
+	}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetProfiles returns all profiles ordered by name
+func (db *DB) GetProfiles() ([]Profile, error) {
+	rows, err := db.Query(`SELECT id, name, created FROM profiles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []Profile{}
+	for rows.Next() {
+		var p Profile
+		if err := rows.Scan(&p.ID, &p.Name, &p.Created); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetProfile returns a profile by ID, or nil if not found
+func (db *DB) GetProfile(id int64) (*Profile, error) {
+	return db.getProfile(`SELECT id, name, created FROM profiles WHERE id = ?`, id)
+}
+
+// GetProfileByName returns a profile by name, ignoring case, or nil if not found
+func (db *DB) GetProfileByName(name string) (*Profile, error) {
+	return db.getProfile(`SELECT id, name, created FROM profiles WHERE name = ?`, name)
+}
+
+func (db *DB) getProfile(query string, arg interface{}) (*Profile, error) {
+	var p Profile
+	err := db.QueryRow(query, arg).Scan(&p.ID, &p.Name, &p.Created)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// EnsureProfile returns the profile with the given name, creating it if it doesn't exist
+func (db *DB) EnsureProfile(name string) (*Profile, error) {
+	if _, err := db.Exec(`INSERT INTO profiles (name) VALUES (?) ON CONFLICT DO NOTHING`, name); err != nil {
+		return nil, err
+	}
+	return db.GetProfileByName(name)
+}
+
+// ForProfile returns a view of the database whose stats and history reads
+// only see entries scraped for the profile. Compacted history isn't
+// attributed to profiles, so it's left out. A profileID of 0 sees everything.
+func (db *DB) ForProfile(profileID int64) *DB {
+	return &DB{DB: db.DB, dialect: db.dialect, profileID: profileID}
+}
+
+// ProfileID returns the profile the database is scoped to with ForProfile,
+// or 0 when it sees everything
+func (db *DB) ProfileID() int64 {
+	return db.profileID
+}
+
+// history stands in for watch_history in reads, limited to the
+// profile's entries when scoped with ForProfile
+func (db *DB) history() string {
+	if db.profileID == 0 {
+		return "watch_history"
+	}
+	return fmt.Sprintf("(SELECT * FROM watch_history WHERE profile_id = %d)", db.profileID)
+}
+
+// watchTime stands in for watch_history in stats queries, adding the
+// rollups of compacted history unless scoped to a profile
+func (db *DB) watchTime() string {
+	if db.profileID == 0 {
+		return watchTimeSource
+	}
+	return fmt.Sprintf(`(
+			SELECT service_id, title, duration_minutes, watched_at, genre, media_kind, device, 1 AS entries
+			FROM watch_history
+			WHERE profile_id = %d
+		)`, db.profileID)
+}
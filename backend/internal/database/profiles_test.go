@@ -0,0 +1,122 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnsureProfile(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	alex, err := db.EnsureProfile("Alex")
+	if err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	again, err := db.EnsureProfile("alex")
+	if err != nil {
+		t.Fatalf("Failed to ensure profile: %v", err)
+	}
+	if again.ID != alex.ID || again.Name != "Alex" {
+		t.Errorf("Expected the existing profile regardless of case, got %+v", again)
+	}
+
+	profiles, err := db.GetProfiles()
+	if err != nil {
+		t.Fatalf("Failed to get profiles: %v", err)
+	}
+	if len(profiles) != 1 {
+		t.Errorf("Expected 1 profile, got %+v", profiles)
+	}
+	if missing, _ := db.GetProfile(alex.ID + 1); missing != nil {
+		t.Errorf("Expected no profile for an unknown ID, got %+v", missing)
+	}
+}
+
+func TestProfilesKeepTheirOwnHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	alex, _ := db.EnsureProfile("Alex")
+	sam, _ := db.EnsureProfile("Sam")
+
+	day := time.Now().Truncate(24 * time.Hour)
+	for _, wh := range []*WatchHistory{
+		{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: day, ProfileID: alex.ID},
+		{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: day, ProfileID: sam.ID},
+		{ServiceID: netflix.ID, Title: "Love Is Blind", DurationMinutes: 60, WatchedAt: day, ProfileID: sam.ID},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	start, end := day.Add(-time.Hour), day.Add(24*time.Hour)
+	for _, tc := range []struct {
+		profileID int64
+		minutes   int
+		entries   int
+	}{
+		{0, 160, 3},
+		{alex.ID, 50, 1},
+		{sam.ID, 110, 2},
+	} {
+		scoped := db.ForProfile(tc.profileID)
+		stats, err := scoped.GetServiceStats(start, end)
+		if err != nil {
+			t.Fatalf("Failed to get stats: %v", err)
+		}
+		var minutes int
+		for _, s := range stats {
+			minutes += s.TotalMinutes
+		}
+		if minutes != tc.minutes {
+			t.Errorf("Profile %d: expected %d minutes, got %d", tc.profileID, tc.minutes, minutes)
+		}
+
+		history, err := scoped.GetWatchHistory(netflix.ID, start, end, 100, 0)
+		if err != nil {
+			t.Fatalf("Failed to get history: %v", err)
+		}
+		if len(history) != tc.entries {
+			t.Errorf("Profile %d: expected %d entries, got %d", tc.profileID, tc.entries, len(history))
+		}
+		for _, wh := range history {
+			if tc.profileID != 0 && wh.ProfileID != tc.profileID {
+				t.Errorf("Profile %d: got another profile's entry %+v", tc.profileID, wh)
+			}
+		}
+	}
+}
+
+func TestProfileMigrationKeepsTriggers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	wh := &WatchHistory{ServiceID: netflix.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: time.Now()}
+	if err := db.InsertWatchHistory(wh); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+
+	var updated *time.Time
+	if err := db.QueryRow(`SELECT updated FROM watch_history WHERE id = ?`, wh.ID).Scan(&updated); err != nil {
+		t.Fatalf("Failed to read entry: %v", err)
+	}
+	if updated == nil {
+		t.Error("Expected the insert trigger to set updated after the table was rebuilt")
+	}
+
+	if _, err := db.DeleteWatchHistoryEntries([]int64{wh.ID}); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	deleted, err := db.GetHistoryDeletedSince(time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to get deletions: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != wh.ID {
+		t.Errorf("Expected a tombstone for the deleted entry, got %v", deleted)
+	}
+}
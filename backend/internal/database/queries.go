@@ -2,13 +2,15 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
 // GetAllServices returns all services
 func (db *DB) GetAllServices() ([]Service, error) {
 	rows, err := db.Query(`
-		SELECT id, name, color, logo_url, enabled, created
+		SELECT id, name, service_key, color, logo_url, enabled, archived, created, updated
 		FROM services
 		ORDER BY name
 	`)
@@ -20,7 +22,7 @@ func (db *DB) GetAllServices() ([]Service, error) {
 	var services []Service
 	for rows.Next() {
 		var svc Service
-		err := rows.Scan(&svc.ID, &svc.Name, &svc.Color, &svc.LogoURL, &svc.Enabled, &svc.Created)
+		err := rows.Scan(&svc.ID, &svc.Name, &svc.Key, &svc.Color, &svc.LogoURL, &svc.Enabled, &svc.Archived, &svc.Created, &svc.Updated)
 		if err != nil {
 			return nil, err
 		}
@@ -34,10 +36,10 @@ func (db *DB) GetAllServices() ([]Service, error) {
 func (db *DB) GetServiceByID(id int64) (*Service, error) {
 	var svc Service
 	err := db.QueryRow(`
-		SELECT id, name, color, logo_url, enabled, created
+		SELECT id, name, service_key, color, logo_url, enabled, archived, created, updated
 		FROM services
 		WHERE id = ?
-	`, id).Scan(&svc.ID, &svc.Name, &svc.Color, &svc.LogoURL, &svc.Enabled, &svc.Created)
+	`, id).Scan(&svc.ID, &svc.Name, &svc.Key, &svc.Color, &svc.LogoURL, &svc.Enabled, &svc.Archived, &svc.Created, &svc.Updated)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -49,14 +51,35 @@ func (db *DB) GetServiceByID(id int64) (*Service, error) {
 	return &svc, nil
 }
 
-// GetServiceByName returns a service by name
+// GetServiceByName returns a service by name, or by the name it was renamed
+// to when given a former one
 func (db *DB) GetServiceByName(name string) (*Service, error) {
+	svc, err := db.getServiceByName(name)
+	if svc == nil && err == nil && CurrentServiceName(name) != name {
+		return db.getServiceByName(CurrentServiceName(name))
+	}
+	return svc, err
+}
+
+func (db *DB) getServiceByName(name string) (*Service, error) {
+	return db.getService(`WHERE name = ?`, name)
+}
+
+// GetServiceByKey returns the service routed by a config key such as
+// "netflix" or "netflix_kids", or nil if no service has that key
+func (db *DB) GetServiceByKey(key string) (*Service, error) {
+	if key == "" {
+		return nil, nil
+	}
+	return db.getService(`WHERE service_key = ?`, key)
+}
+
+func (db *DB) getService(where string, arg interface{}) (*Service, error) {
 	var svc Service
 	err := db.QueryRow(`
-		SELECT id, name, color, logo_url, enabled, created
+		SELECT id, name, service_key, color, logo_url, enabled, archived, created, updated
 		FROM services
-		WHERE name = ?
-	`, name).Scan(&svc.ID, &svc.Name, &svc.Color, &svc.LogoURL, &svc.Enabled, &svc.Created)
+		`+where, arg).Scan(&svc.ID, &svc.Name, &svc.Key, &svc.Color, &svc.LogoURL, &svc.Enabled, &svc.Archived, &svc.Created, &svc.Updated)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -68,6 +91,57 @@ func (db *DB) GetServiceByName(name string) (*Service, error) {
 	return &svc, nil
 }
 
+// EnsureService returns the service with the given name, creating it (enabled) if it doesn't exist.
+// Former names of renamed services resolve to the service as it's named now.
+func (db *DB) EnsureService(name, color, logoURL string) (*Service, error) {
+	name = CurrentServiceName(name)
+	_, err := db.Exec(`
+		INSERT INTO services (name, color, logo_url, enabled)
+		VALUES (?, ?, ?, TRUE)
+		ON CONFLICT DO NOTHING
+	`, name, color, logoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetServiceByName(name)
+}
+
+// SetServiceKey routes a config key to the named service, moving the key
+// from any service that had it, e.g. when a display_name is added to a
+// configured service. It does nothing if the service doesn't exist.
+func (db *DB) SetServiceKey(name, key string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM services WHERE name = ?`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE services SET service_key = '' WHERE service_key = ? AND id != ?`, key, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE services SET service_key = ? WHERE id = ? AND service_key != ?`, key, id, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// EnableService enables the named service unless it was archived. Seeded
+// services start disabled until they're configured.
+func (db *DB) EnableService(name string) error {
+	_, err := db.Exec(`UPDATE services SET enabled = TRUE WHERE name = ? AND archived = FALSE`, name)
+	return err
+}
+
 // GetServiceStats returns aggregated statistics for all services for a given time period
 func (db *DB) GetServiceStats(startDate, endDate time.Time) ([]ServiceStats, error) {
 	rows, err := db.Query(`
@@ -77,13 +151,14 @@ func (db *DB) GetServiceStats(startDate, endDate time.Time) ([]ServiceStats, err
 			s.color,
 			s.logo_url,
 			COALESCE(SUM(wh.duration_minutes), 0) as total_minutes,
-			COUNT(wh.id) as total_shows,
-			DATETIME(MAX(wh.watched_at)) as last_watched
+			COALESCE(SUM(wh.entries), 0) as total_shows,
+			`+db.dialect.dateTime("MAX(wh.watched_at)")+` as last_watched
 		FROM services s
-		LEFT JOIN watch_history wh ON s.id = wh.service_id
+		LEFT JOIN `+db.watchTime()+` wh ON s.id = wh.service_id
 			AND wh.watched_at >= ?
 			AND wh.watched_at < ?
-		WHERE s.enabled = 1
+			AND `+notIgnoredClause+`
+		WHERE s.enabled = TRUE
 		GROUP BY s.id, s.name, s.color, s.logo_url
 		ORDER BY total_minutes DESC
 	`, startDate, endDate)
@@ -109,8 +184,7 @@ func (db *DB) GetServiceStats(startDate, endDate time.Time) ([]ServiceStats, err
 			return nil, err
 		}
 		if lastWatchedStr.Valid && lastWatchedStr.String != "" {
-			// Parse the datetime string from SQLite
-			// SQLite DATETIME() format: "YYYY-MM-DD HH:MM:SS"
+			// Parse the datetime string, formatted as "YYYY-MM-DD HH:MM:SS"
 			t, err := time.Parse("2006-01-02 15:04:05", lastWatchedStr.String)
 			if err == nil {
 				stat.LastWatched = &t
@@ -126,12 +200,15 @@ func (db *DB) GetServiceStats(startDate, endDate time.Time) ([]ServiceStats, err
 func (db *DB) GetWatchHistory(serviceID int64, startDate, endDate time.Time, limit, offset int) ([]WatchHistory, error) {
 	rows, err := db.Query(`
 		SELECT wh.id, wh.service_id, s.name as service_name, wh.title, wh.duration_minutes, wh.watched_at,
-		       wh.episode_info, wh.thumbnail_url, wh.genre, wh.created
-		FROM watch_history wh
+		       wh.episode_info, wh.thumbnail_url, wh.genre, COALESCE(wh.device, ''), COALESCE(wh.location, ''),
+		       COALESCE(wh.media_kind, 'video'), COALESCE(wh.notes, ''), COALESCE(wh.rating, 0), COALESCE(wh.confidence, ''),
+		       wh.profile_id, wh.created, wh.updated
+		FROM `+db.history()+` wh
 		JOIN services s ON wh.service_id = s.id
 		WHERE wh.service_id = ?
 		  AND wh.watched_at >= ?
 		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
 		ORDER BY wh.watched_at DESC
 		LIMIT ? OFFSET ?
 	`, serviceID, startDate, endDate, limit, offset)
@@ -146,7 +223,34 @@ func (db *DB) GetWatchHistory(serviceID int64, startDate, endDate time.Time, lim
 		err := rows.Scan(
 			&wh.ID, &wh.ServiceID, &wh.ServiceName, &wh.Title, &wh.DurationMinutes,
 			&wh.WatchedAt, &wh.EpisodeInfo, &wh.ThumbnailURL,
-			&wh.Genre, &wh.Created,
+			&wh.Genre, &wh.Device, &wh.Location, &wh.MediaKind, &wh.Notes, &wh.Rating, &wh.Confidence,
+			&wh.ProfileID, &wh.Created, &wh.Updated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, wh)
+	}
+
+	return history, rows.Err()
+}
+
+// watchHistoryColumns selects a full WatchHistory from "wh" joined with services "s"
+const watchHistoryColumns = `wh.id, wh.service_id, s.name, wh.title, wh.duration_minutes, wh.watched_at,
+		       wh.episode_info, wh.thumbnail_url, wh.genre, COALESCE(wh.device, ''), COALESCE(wh.location, ''),
+		       COALESCE(wh.media_kind, 'video'), COALESCE(wh.notes, ''), COALESCE(wh.rating, 0), COALESCE(wh.confidence, ''),
+		       wh.profile_id, wh.created, wh.updated`
+
+// scanWatchHistory reads rows selected with watchHistoryColumns
+func scanWatchHistory(rows *sql.Rows) ([]WatchHistory, error) {
+	history := []WatchHistory{}
+	for rows.Next() {
+		var wh WatchHistory
+		err := rows.Scan(
+			&wh.ID, &wh.ServiceID, &wh.ServiceName, &wh.Title, &wh.DurationMinutes,
+			&wh.WatchedAt, &wh.EpisodeInfo, &wh.ThumbnailURL,
+			&wh.Genre, &wh.Device, &wh.Location, &wh.MediaKind, &wh.Notes, &wh.Rating, &wh.Confidence,
+			&wh.ProfileID, &wh.Created, &wh.Updated,
 		)
 		if err != nil {
 			return nil, err
@@ -165,11 +269,11 @@ func (db *DB) WatchHistoryExists(serviceID int64, title, episodeInfo string, wat
 
 	err := db.QueryRow(`
 		SELECT COUNT(*)
-		FROM watch_history
+		FROM `+db.history()+` wh
 		WHERE service_id = ?
 		  AND title = ?
 		  AND episode_info = ?
-		  AND DATE(watched_at) = ?
+		  AND `+db.dialect.date("watched_at")+` = ?
 	`, serviceID, title, episodeInfo, dateOnly).Scan(&count)
 
 	if err != nil {
@@ -179,55 +283,222 @@ func (db *DB) WatchHistoryExists(serviceID int64, title, episodeInfo string, wat
 	return count > 0, nil
 }
 
-// InsertWatchHistory inserts or updates a watch history entry
-func (db *DB) InsertWatchHistory(wh *WatchHistory) error {
-	result, err := db.Exec(`
+// HasWatchHistoryDuring reports whether a service has history read from the
+// service, rather than inferred, whose watch time overlaps [start, end).
+// Entries are assumed to be under a day long.
+func (db *DB) HasWatchHistoryDuring(serviceID int64, start, end time.Time) (bool, error) {
+	rows, err := db.Query(`
+		SELECT watched_at, duration_minutes FROM watch_history
+		WHERE service_id = ? AND watched_at >= ? AND watched_at < ? AND COALESCE(confidence, '') = ''
+	`, serviceID, start.Add(-24*time.Hour), end)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var watchedAt time.Time
+		var minutes int
+		if err := rows.Scan(&watchedAt, &minutes); err != nil {
+			return false, err
+		}
+		if watchedAt.Add(time.Duration(minutes) * time.Minute).After(start) {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// GetInferredHistory returns inferred sessions watched since the given time,
+// oldest first
+func (db *DB) GetInferredHistory(since time.Time) ([]WatchHistory, error) {
+	rows, err := db.Query(`
+		SELECT `+watchHistoryColumns+`
+		FROM watch_history wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE COALESCE(wh.confidence, '') != '' AND wh.watched_at >= ?
+		ORDER BY wh.watched_at ASC, wh.id ASC
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWatchHistory(rows)
+}
+
+// DeleteWatchHistoryEntries deletes the given entries, returning how many existed
+func (db *DB) DeleteWatchHistoryEntries(ids []int64) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var deleted int64
+	for _, id := range ids {
+		res, err := tx.Exec(`DELETE FROM watch_history WHERE id = ?`, id)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+
+	return deleted, tx.Commit()
+}
+
+// MergeWatchHistorySessions deletes merged sessions and moves the sessions
+// they merged into to their combined spans, in one transaction
+func (db *DB) MergeWatchHistorySessions(spans []WatchHistory, removed []int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Delete first, since a moved session may take a removed one's start
+	for _, id := range removed {
+		if _, err := tx.Exec(`DELETE FROM watch_history WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	for _, wh := range spans {
+		if _, err := tx.Exec(`UPDATE watch_history SET watched_at = ?, duration_minutes = ? WHERE id = ?`,
+			wh.WatchedAt, wh.DurationMinutes, wh.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// HasWatchHistory reports whether a service has any stored history
+func (db *DB) HasWatchHistory(serviceID int64) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM `+db.history()+` wh WHERE service_id = ?)`, serviceID).Scan(&exists)
+	return exists, err
+}
+
+// rowQuerier is satisfied by *DB and *Tx
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	sqlDialect() dialect
+}
+
+// upsertWatchHistory inserts a watch history entry, updating the stored one
+// for the same service, profile, title and time, and returns its ID. The
+// stored raw payload is kept, since a re-scrape's differs in when it was read
+// and changing it would mark the entry updated.
+const upsertWatchHistory = `
 		INSERT INTO watch_history
-		(service_id, title, duration_minutes, watched_at, episode_info, thumbnail_url, genre)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(service_id, title, watched_at) DO UPDATE SET
+		(service_id, title, duration_minutes, watched_at, episode_info, thumbnail_url, genre, device, location, media_kind, notes,
+		 confidence, raw_payload, profile_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(service_id, profile_id, title, watched_at) DO UPDATE SET
 			duration_minutes = excluded.duration_minutes,
 			episode_info = excluded.episode_info,
 			thumbnail_url = excluded.thumbnail_url,
-			genre = excluded.genre
-	`, wh.ServiceID, wh.Title, wh.DurationMinutes, wh.WatchedAt,
-		wh.EpisodeInfo, wh.ThumbnailURL, wh.Genre)
+			genre = excluded.genre,
+			device = COALESCE(NULLIF(excluded.device, ''), watch_history.device),
+			location = COALESCE(NULLIF(excluded.location, ''), watch_history.location),
+			media_kind = excluded.media_kind,
+			notes = COALESCE(NULLIF(excluded.notes, ''), watch_history.notes),
+			confidence = excluded.confidence,
+			raw_payload = COALESCE(NULLIF(watch_history.raw_payload, ''), excluded.raw_payload)
+		RETURNING id
+	`
+
+// InsertWatchHistory inserts or updates a watch history entry, storing
+// aliased titles under their canonical title
+func (db *DB) InsertWatchHistory(wh *WatchHistory) error {
+	return insertWatchHistory(db, func(args ...interface{}) *sql.Row {
+		return db.QueryRow(upsertWatchHistory, args...)
+	}, wh)
+}
 
+// InsertWatchHistoryBatch inserts or updates many entries like
+// InsertWatchHistory, in one transaction with a prepared statement so large
+// scrapes and imports don't pay for a commit per row. Nothing is stored if
+// any entry fails. IDs and canonical titles are set on the items.
+func (db *DB) InsertWatchHistoryBatch(items []WatchHistory) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	id, err := result.LastInsertId()
-	if err == nil {
-		wh.ID = id
+	stmt, err := tx.Prepare(upsertWatchHistory)
+	if err != nil {
+		return err
 	}
+	defer stmt.Close()
 
-	return nil
-}
+	for i := range items {
+		if err := insertWatchHistory(tx, stmt.QueryRow, &items[i]); err != nil {
+			return fmt.Errorf("failed to insert %q: %w", items[i].Title, err)
+		}
+	}
 
-// InsertScraperRun records a scraper execution
-func (db *DB) InsertScraperRun(run *ScraperRun) error {
-	result, err := db.Exec(`
-		INSERT INTO scraper_runs (service_id, ran_at, status, error_message, items_scraped)
-		VALUES (?, ?, ?, ?, ?)
-	`, run.ServiceID, run.RanAt, run.Status, run.ErrorMessage, run.ItemsScraped)
+	return tx.Commit()
+}
 
+// insertWatchHistory resolves an entry's title and stores it with upsert,
+// which runs upsertWatchHistory
+func insertWatchHistory(q rowQuerier, upsert func(args ...interface{}) *sql.Row, wh *WatchHistory) error {
+	canonical, err := canonicalTitle(q, wh.Title)
 	if err != nil {
 		return err
 	}
+	wh.Title = canonical
+
+	// Days past the compaction window are already counted in a rollup
+	if rolledUp, err := isRolledUp(q, wh.ServiceID, wh.Title, wh.WatchedAt); err != nil || rolledUp {
+		return err
+	}
+
+	mediaKind := wh.MediaKind
+	if mediaKind == "" {
+		mediaKind = MediaKindVideo
+	}
+
+	return upsert(wh.ServiceID, wh.Title, wh.DurationMinutes, wh.WatchedAt,
+		wh.EpisodeInfo, wh.ThumbnailURL, wh.Genre, wh.Device, wh.Location, mediaKind, wh.Notes,
+		wh.Confidence, encodeRawPayload(wh.Raw), wh.ProfileID).Scan(&wh.ID)
+}
 
-	id, err := result.LastInsertId()
-	if err == nil {
-		run.ID = id
+// InsertScraperRun records a scraper execution
+func (db *DB) InsertScraperRun(run *ScraperRun) error {
+	var selectorHits string
+	if len(run.SelectorHits) > 0 {
+		encoded, err := json.Marshal(run.SelectorHits)
+		if err != nil {
+			return err
+		}
+		selectorHits = string(encoded)
+	}
+	triggeredBy := run.TriggeredBy
+	if triggeredBy == "" {
+		triggeredBy = "manual"
 	}
 
-	return nil
+	return db.QueryRow(`
+		INSERT INTO scraper_runs (service_id, ran_at, status, error_message, items_scraped, items_rejected,
+		                          items_skipped, items_ignored, selector_hits, log_tail, dom_snapshot, triggered_by, profile_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, run.ServiceID, run.RanAt, run.Status, run.ErrorMessage, run.ItemsScraped, run.ItemsRejected,
+		run.ItemsSkipped, run.ItemsIgnored, selectorHits, run.Logs, run.DOMSnapshot, triggeredBy, run.ProfileID).Scan(&run.ID)
 }
 
 // GetLatestScraperRuns returns the most recent scraper run for each service
 func (db *DB) GetLatestScraperRuns() ([]ScraperRun, error) {
 	rows, err := db.Query(`
-		SELECT sr.id, sr.service_id, sr.ran_at, sr.status, sr.error_message, sr.items_scraped
+		SELECT sr.id, sr.service_id, sr.ran_at, sr.status, sr.error_message, sr.items_scraped, sr.items_rejected, sr.items_skipped, sr.items_ignored,
+		       COALESCE(sr.selector_hits, ''), COALESCE(sr.triggered_by, 'manual'), sr.updated
 		FROM scraper_runs sr
 		INNER JOIN (
 			SELECT service_id, MAX(ran_at) as max_ran_at
@@ -244,13 +515,140 @@ func (db *DB) GetLatestScraperRuns() ([]ScraperRun, error) {
 	var runs []ScraperRun
 	for rows.Next() {
 		var run ScraperRun
+		var selectorHits string
+		err := rows.Scan(
+			&run.ID, &run.ServiceID, &run.RanAt,
+			&run.Status, &run.ErrorMessage, &run.ItemsScraped, &run.ItemsRejected, &run.ItemsSkipped, &run.ItemsIgnored, &selectorHits, &run.TriggeredBy, &run.Updated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if selectorHits != "" {
+			if err := json.Unmarshal([]byte(selectorHits), &run.SelectorHits); err != nil {
+				return nil, err
+			}
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// GetScraperRun returns a single scraper run with its logs and DOM snapshot, or nil if not found
+func (db *DB) GetScraperRun(id int64) (*ScraperRun, error) {
+	var run ScraperRun
+	var selectorHits string
+	err := db.QueryRow(`
+		SELECT id, service_id, ran_at, status, error_message, items_scraped, items_rejected, items_skipped, items_ignored, COALESCE(selector_hits, ''),
+		       COALESCE(log_tail, ''), COALESCE(dom_snapshot, ''), COALESCE(triggered_by, 'manual'), updated
+		FROM scraper_runs
+		WHERE id = ?
+	`, id).Scan(
+		&run.ID, &run.ServiceID, &run.RanAt, &run.Status, &run.ErrorMessage, &run.ItemsScraped, &run.ItemsRejected, &run.ItemsSkipped, &run.ItemsIgnored,
+		&selectorHits, &run.Logs, &run.DOMSnapshot, &run.TriggeredBy, &run.Updated,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if selectorHits != "" {
+		if err := json.Unmarshal([]byte(selectorHits), &run.SelectorHits); err != nil {
+			return nil, err
+		}
+	}
+
+	return &run, nil
+}
+
+// GetConsecutiveFailures returns how many of a service's most recent scraper runs
+// failed in a row, and when the latest of them ran
+func (db *DB) GetConsecutiveFailures(serviceID int64) (int, time.Time, error) {
+	rows, err := db.Query(`
+		SELECT ran_at, status
+		FROM scraper_runs
+		WHERE service_id = ?
+		ORDER BY ran_at DESC, id DESC
+		LIMIT 100
+	`, serviceID)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer rows.Close()
+
+	count := 0
+	var lastFailure time.Time
+	for rows.Next() {
+		var ranAt time.Time
+		var status string
+		if err := rows.Scan(&ranAt, &status); err != nil {
+			return 0, time.Time{}, err
+		}
+		if status != "failed" {
+			break
+		}
+		if count == 0 {
+			lastFailure = ranAt
+		}
+		count++
+	}
+
+	return count, lastFailure, rows.Err()
+}
+
+// CountScraperRuns returns how many times a service has been scraped since
+// a time. Services scraped once per profile record a run per profile, so the
+// busiest profile's count is used.
+func (db *DB) CountScraperRuns(serviceID int64, since time.Time) (int, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM scraper_runs
+		WHERE service_id = ?
+		  AND ran_at >= ?
+		GROUP BY profile_id
+		ORDER BY COUNT(*) DESC
+		LIMIT 1
+	`, serviceID, since).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return count, err
+}
+
+// GetScraperRuns returns scraper runs for a service within a time range, oldest first
+func (db *DB) GetScraperRuns(serviceID int64, startDate, endDate time.Time) ([]ScraperRun, error) {
+	rows, err := db.Query(`
+		SELECT id, service_id, ran_at, status, error_message, items_scraped, items_rejected, items_skipped, items_ignored, COALESCE(selector_hits, ''),
+		       COALESCE(triggered_by, 'manual'), updated
+		FROM scraper_runs
+		WHERE service_id = ?
+		  AND ran_at >= ?
+		  AND ran_at < ?
+		ORDER BY ran_at
+	`, serviceID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []ScraperRun
+	for rows.Next() {
+		var run ScraperRun
+		var selectorHits string
 		err := rows.Scan(
 			&run.ID, &run.ServiceID, &run.RanAt,
-			&run.Status, &run.ErrorMessage, &run.ItemsScraped,
+			&run.Status, &run.ErrorMessage, &run.ItemsScraped, &run.ItemsRejected, &run.ItemsSkipped, &run.ItemsIgnored, &selectorHits, &run.TriggeredBy, &run.Updated,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if selectorHits != "" {
+			if err := json.Unmarshal([]byte(selectorHits), &run.SelectorHits); err != nil {
+				return nil, err
+			}
+		}
 		runs = append(runs, run)
 	}
 
@@ -260,12 +658,13 @@ func (db *DB) GetLatestScraperRuns() ([]ScraperRun, error) {
 // GetDailyStats returns daily aggregated watch time for a service
 func (db *DB) GetDailyStats(serviceID int64, startDate, endDate time.Time) (map[string]int, error) {
 	rows, err := db.Query(`
-		SELECT DATE(watched_at) as day, SUM(duration_minutes) as total_minutes
-		FROM watch_history
-		WHERE service_id = ?
-		  AND watched_at >= ?
-		  AND watched_at < ?
-		GROUP BY DATE(watched_at)
+		SELECT `+db.dialect.date("wh.watched_at")+` as day, SUM(wh.duration_minutes) as total_minutes
+		FROM `+db.watchTime()+` wh
+		WHERE wh.service_id = ?
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY `+db.dialect.date("wh.watched_at")+`
 		ORDER BY day
 	`, serviceID, startDate, endDate)
 	if err != nil {
@@ -286,6 +685,70 @@ func (db *DB) GetDailyStats(serviceID int64, startDate, endDate time.Time) (map[
 	return stats, rows.Err()
 }
 
+// GetDailyTotals returns daily aggregated watch time (YYYY-MM-DD) across all
+// enabled services
+func (db *DB) GetDailyTotals(startDate, endDate time.Time) (map[string]int, error) {
+	rows, err := db.Query(`
+		SELECT `+db.dialect.date("wh.watched_at")+` as day, SUM(wh.duration_minutes) as total_minutes
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY `+db.dialect.date("wh.watched_at")+`
+		ORDER BY day
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var totalMinutes int
+		if err := rows.Scan(&day, &totalMinutes); err != nil {
+			return nil, err
+		}
+		totals[day] = totalMinutes
+	}
+
+	return totals, rows.Err()
+}
+
+// GetDailyServiceStats returns watch time per day (YYYY-MM-DD) and enabled
+// service, ordered by day and then service, for stacked charts. Days a
+// service wasn't watched are omitted.
+func (db *DB) GetDailyServiceStats(startDate, endDate time.Time) ([]DailyServiceStats, error) {
+	rows, err := db.Query(`
+		SELECT `+db.dialect.date("wh.watched_at")+` as day, wh.service_id, SUM(wh.duration_minutes) as total_minutes
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY `+db.dialect.date("wh.watched_at")+`, wh.service_id
+		ORDER BY day, wh.service_id
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []DailyServiceStats{}
+	for rows.Next() {
+		var ds DailyServiceStats
+		if err := rows.Scan(&ds.Date, &ds.ServiceID, &ds.Minutes); err != nil {
+			return nil, err
+		}
+		stats = append(stats, ds)
+	}
+
+	return stats, rows.Err()
+}
+
 // UpdateServiceEnabled updates the enabled status of a service
 func (db *DB) UpdateServiceEnabled(serviceID int64, enabled bool) error {
 	_, err := db.Exec(`
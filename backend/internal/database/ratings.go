@@ -0,0 +1,110 @@
+package database
+
+import (
+	"math"
+	"time"
+)
+
+// UpdateWatchHistoryRating sets the personal rating (1-5, 0 clears it) on a
+// history entry. It reports whether the entry exists.
+func (db *DB) UpdateWatchHistoryRating(id int64, rating int) (bool, error) {
+	result, err := db.Exec(`UPDATE watch_history SET rating = ? WHERE id = ?`, rating, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// GetRatingStatsByService returns the average rating of rated entries per
+// service in a time period
+func (db *DB) GetRatingStatsByService(startDate, endDate time.Time) ([]RatingStats, error) {
+	return db.queryRatingStats(`
+		SELECT s.name, AVG(wh.rating), COUNT(wh.id)
+		FROM `+db.history()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE wh.rating > 0
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY s.id
+		ORDER BY AVG(wh.rating) DESC, s.name
+	`, startDate, endDate)
+}
+
+// GetRatingStatsByGenre returns the average rating of rated entries per
+// normalized genre in a time period. Entries without a genre are skipped.
+func (db *DB) GetRatingStatsByGenre(startDate, endDate time.Time) ([]RatingStats, error) {
+	return db.queryRatingStats(`
+		SELECT `+normalizedGenreExpr+` AS normalized_genre, AVG(wh.rating), COUNT(wh.id)
+		FROM `+db.history()+` wh
+		WHERE wh.rating > 0
+		  AND COALESCE(wh.genre, '') != ''
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY normalized_genre COLLATE NOCASE
+		ORDER BY AVG(wh.rating) DESC, normalized_genre
+	`, startDate, endDate)
+}
+
+// queryRatingStats runs a query returning name, average rating and count rows
+func (db *DB) queryRatingStats(query string, args ...interface{}) ([]RatingStats, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []RatingStats{}
+	for rows.Next() {
+		var rs RatingStats
+		if err := rows.Scan(&rs.Name, &rs.AverageRating, &rs.RatedCount); err != nil {
+			return nil, err
+		}
+		rs.AverageRating = math.Round(rs.AverageRating*100) / 100
+		stats = append(stats, rs)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetTopRatedTitles returns the best rated titles in a time period, ordered by
+// average rating and then by watch time
+func (db *DB) GetTopRatedTitles(startDate, endDate time.Time, limit int) ([]TitleRating, error) {
+	rows, err := db.Query(`
+		SELECT wh.title,
+		       AVG(CASE WHEN wh.rating > 0 THEN wh.rating END) as average_rating,
+		       SUM(CASE WHEN wh.rating > 0 THEN 1 ELSE 0 END) as rated_count,
+		       SUM(wh.duration_minutes) as total_minutes
+		FROM `+db.history()+` wh
+		WHERE wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY wh.title COLLATE NOCASE
+		HAVING rated_count > 0
+		ORDER BY average_rating DESC, total_minutes DESC
+		LIMIT ?
+	`, startDate, endDate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []TitleRating{}
+	for rows.Next() {
+		var tr TitleRating
+		if err := rows.Scan(&tr.Title, &tr.AverageRating, &tr.RatedCount, &tr.TotalMinutes); err != nil {
+			return nil, err
+		}
+		tr.AverageRating = math.Round(tr.AverageRating*100) / 100
+		titles = append(titles, tr)
+	}
+
+	return titles, rows.Err()
+}
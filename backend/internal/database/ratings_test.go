@@ -0,0 +1,61 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRatingStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hbo, _ := db.GetServiceByName("HBO Max")
+	now := time.Now()
+
+	entries := []struct {
+		wh     *WatchHistory
+		rating int
+	}{
+		{&WatchHistory{ServiceID: netflix.ID, Title: "Severance", Genre: "Drama", DurationMinutes: 50, WatchedAt: now}, 5},
+		{&WatchHistory{ServiceID: netflix.ID, Title: "Severance", Genre: "Drama", DurationMinutes: 50, WatchedAt: now.Add(-time.Hour)}, 4},
+		{&WatchHistory{ServiceID: netflix.ID, Title: "Love Is Blind", Genre: "Reality", DurationMinutes: 60, WatchedAt: now}, 2},
+		{&WatchHistory{ServiceID: hbo.ID, Title: "The Wire", Genre: "Drama", DurationMinutes: 60, WatchedAt: now}, 5},
+		{&WatchHistory{ServiceID: hbo.ID, Title: "Unrated Show", DurationMinutes: 30, WatchedAt: now}, 0},
+	}
+	for _, e := range entries {
+		db.InsertWatchHistory(e.wh)
+		if e.rating > 0 {
+			db.UpdateWatchHistoryRating(e.wh.ID, e.rating)
+		}
+	}
+
+	start, end := now.Add(-24*time.Hour), now.Add(time.Hour)
+
+	byService, err := db.GetRatingStatsByService(start, end)
+	if err != nil {
+		t.Fatalf("Failed to get service ratings: %v", err)
+	}
+	if len(byService) != 2 || byService[0].Name != "HBO Max" || byService[0].AverageRating != 5 {
+		t.Errorf("Unexpected service ratings: %+v", byService)
+	}
+	if byService[1].AverageRating != 3.67 || byService[1].RatedCount != 3 {
+		t.Errorf("Expected Netflix average 3.67 over 3 entries, got %+v", byService[1])
+	}
+
+	byGenre, err := db.GetRatingStatsByGenre(start, end)
+	if err != nil {
+		t.Fatalf("Failed to get genre ratings: %v", err)
+	}
+	if len(byGenre) != 2 || byGenre[0].Name != "Drama" {
+		t.Errorf("Unexpected genre ratings: %+v", byGenre)
+	}
+
+	top, err := db.GetTopRatedTitles(start, end, 10)
+	if err != nil {
+		t.Fatalf("Failed to get top rated titles: %v", err)
+	}
+	if len(top) != 3 || top[0].Title != "The Wire" || top[1].Title != "Severance" {
+		t.Errorf("Unexpected top rated titles: %+v", top)
+	}
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// encodeRawPayload returns a raw payload as JSON for storage, or "" when there is none
+func encodeRawPayload(raw *RawPayload) string {
+	if raw == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// decodeRawPayload parses a stored raw payload, returning nil for entries
+// stored without one
+func decodeRawPayload(stored string) *RawPayload {
+	if stored == "" {
+		return nil
+	}
+	var raw RawPayload
+	if err := json.Unmarshal([]byte(stored), &raw); err != nil {
+		log.Printf("Ignoring unreadable raw payload: %v", err)
+		return nil
+	}
+	return &raw
+}
+
+// GetRawWatchHistory returns a service's entries that were stored with a raw
+// payload, oldest first, with only the fields a reprocess can re-derive
+func (db *DB) GetRawWatchHistory(serviceID int64) ([]WatchHistory, error) {
+	rows, err := db.Query(`
+		SELECT id, service_id, title, duration_minutes, watched_at, COALESCE(episode_info, ''), raw_payload
+		FROM watch_history
+		WHERE service_id = ? AND COALESCE(raw_payload, '') != ''
+		ORDER BY watched_at ASC
+	`, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []WatchHistory
+	for rows.Next() {
+		var wh WatchHistory
+		var raw string
+		if err := rows.Scan(&wh.ID, &wh.ServiceID, &wh.Title, &wh.DurationMinutes, &wh.WatchedAt, &wh.EpisodeInfo, &raw); err != nil {
+			return nil, err
+		}
+		if wh.Raw = decodeRawPayload(raw); wh.Raw != nil {
+			entries = append(entries, wh)
+		}
+	}
+
+	return entries, rows.Err()
+}
+
+// UpdateParsedFields saves the title, episode, watch time and duration
+// re-derived from an entry's raw payload
+func (db *DB) UpdateParsedFields(id int64, title, episodeInfo string, watchedAt time.Time, durationMinutes int) error {
+	_, err := db.Exec(`
+		UPDATE watch_history
+		SET title = ?, episode_info = ?, watched_at = ?, duration_minutes = ?
+		WHERE id = ?
+	`, title, episodeInfo, watchedAt, durationMinutes, id)
+	return err
+}
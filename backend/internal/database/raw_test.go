@@ -0,0 +1,64 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRawPayloadRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	watchedAt := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	raw := &RawPayload{Title: "Dark: Secrets", Date: "1/5/25", HTML: "<li>Dark</li>", ScrapedAt: watchedAt}
+
+	if err := db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Dark", EpisodeInfo: "Secrets", DurationMinutes: 40, WatchedAt: watchedAt, Raw: raw}); err != nil {
+		t.Fatalf("Failed to insert entry: %v", err)
+	}
+	if err := db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Imported", DurationMinutes: 40, WatchedAt: watchedAt}); err != nil {
+		t.Fatalf("Failed to insert entry: %v", err)
+	}
+
+	// Entries without a payload can't be reprocessed
+	entries, err := db.GetRawWatchHistory(service.ID)
+	if err != nil {
+		t.Fatalf("GetRawWatchHistory failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Title != "Dark" {
+		t.Fatalf("Expected only the scraped entry, got %+v", entries)
+	}
+	if got := entries[0].Raw; got == nil || got.Title != raw.Title || got.Date != raw.Date || got.HTML != raw.HTML {
+		t.Errorf("Expected payload %+v, got %+v", raw, got)
+	}
+
+	fixed := watchedAt.AddDate(0, 0, 1)
+	if err := db.UpdateParsedFields(entries[0].ID, "Dark", "S01E01", fixed, 50); err != nil {
+		t.Fatalf("UpdateParsedFields failed: %v", err)
+	}
+	entries, _ = db.GetRawWatchHistory(service.ID)
+	if entries[0].EpisodeInfo != "S01E01" || !entries[0].WatchedAt.Equal(fixed) || entries[0].DurationMinutes != 50 {
+		t.Errorf("Expected reparsed fields to be saved, got %+v", entries[0])
+	}
+}
+
+func TestApprovedPendingItemKeepsRawPayload(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	raw := &RawPayload{Title: "Dark", Date: "1/5/25"}
+	if err := db.InsertPendingItem(&WatchHistory{ServiceID: service.ID, Title: "Dark", DurationMinutes: 40, WatchedAt: time.Now(), Raw: raw}); err != nil {
+		t.Fatalf("Failed to insert pending item: %v", err)
+	}
+
+	items, _ := db.GetPendingItems(service.ID)
+	if _, err := db.ApprovePendingItems([]int64{items[0].ID}); err != nil {
+		t.Fatalf("Failed to approve: %v", err)
+	}
+
+	entries, err := db.GetRawWatchHistory(service.ID)
+	if err != nil || len(entries) != 1 || entries[0].Raw.Date != "1/5/25" {
+		t.Errorf("Expected approved entry to keep its payload, got %+v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"math"
+	"time"
+)
+
+// rewatchClause matches history entries "wh" whose title and episode were
+// already watched before, on any service, rather than seen for the first time
+func (db *DB) rewatchClause() string {
+	return `EXISTS (
+			SELECT 1 FROM ` + db.history() + ` prev
+			WHERE prev.title = wh.title COLLATE NOCASE
+			  AND COALESCE(prev.episode_info, '') = COALESCE(wh.episode_info, '') COLLATE NOCASE
+			  AND prev.watched_at < wh.watched_at
+		)`
+}
+
+// GetRewatchStats splits watch time in a time period across enabled services
+// into first watches and rewatches of a title and episode seen before,
+// including before the period. History compacted into daily rollups has no
+// episode details and is left out; DetailSince reports where detail starts.
+func (db *DB) GetRewatchStats(startDate, endDate time.Time) (*RewatchStats, error) {
+	rows, err := db.Query(`
+		SELECT `+db.rewatchClause()+` as rewatch, SUM(wh.duration_minutes), COUNT(*)
+		FROM `+db.history()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY rewatch
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &RewatchStats{}
+	for rows.Next() {
+		var rewatch bool
+		var minutes, count int
+		if err := rows.Scan(&rewatch, &minutes, &count); err != nil {
+			return nil, err
+		}
+		if rewatch {
+			stats.RewatchMinutes, stats.RewatchCount = minutes, count
+		} else {
+			stats.NewMinutes, stats.NewCount = minutes, count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if total := stats.NewMinutes + stats.RewatchMinutes; total > 0 {
+		stats.RewatchRatio = math.Round(float64(stats.RewatchMinutes)/float64(total)*1000) / 1000
+	}
+	stats.DetailSince, err = db.DetailSince()
+	return stats, err
+}
+
+// GetTopRewatchedTitles returns the titles with the most rewatch time in a
+// time period across enabled services, ordered by rewatch minutes. Like
+// GetRewatchStats, it only sees history that hasn't been compacted.
+func (db *DB) GetTopRewatchedTitles(startDate, endDate time.Time, limit int) ([]TitleStats, error) {
+	rows, err := db.Query(`
+		SELECT wh.title, SUM(wh.duration_minutes) as total_minutes, COUNT(*) as watch_count
+		FROM `+db.history()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		  AND `+db.rewatchClause()+`
+		GROUP BY wh.title COLLATE NOCASE
+		ORDER BY total_minutes DESC
+		LIMIT ?
+	`, startDate, endDate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []TitleStats{}
+	for rows.Next() {
+		var ts TitleStats
+		if err := rows.Scan(&ts.Title, &ts.TotalMinutes, &ts.WatchCount); err != nil {
+			return nil, err
+		}
+		titles = append(titles, ts)
+	}
+
+	return titles, rows.Err()
+}
+
+// GetTopRewatchedServiceTitles returns the titles with the most rewatch time
+// in a time period on each enabled service, so a title rewatched on two
+// services is listed twice, ordered by rewatch minutes. Compacted history is
+// left out.
+func (db *DB) GetTopRewatchedServiceTitles(startDate, endDate time.Time, limit int) ([]ServiceTitleStats, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.name, wh.title, SUM(wh.duration_minutes) as total_minutes, COUNT(*) as watch_count
+		FROM `+db.history()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		  AND `+db.rewatchClause()+`
+		GROUP BY s.id, s.name, wh.title COLLATE NOCASE
+		ORDER BY total_minutes DESC
+		LIMIT ?
+	`, startDate, endDate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []ServiceTitleStats{}
+	for rows.Next() {
+		var st ServiceTitleStats
+		if err := rows.Scan(&st.ServiceID, &st.ServiceName, &st.Title, &st.TotalMinutes, &st.WatchCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
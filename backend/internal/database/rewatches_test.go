@@ -0,0 +1,57 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetRewatchStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hulu, _ := db.GetServiceByName("Hulu")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	db.UpdateServiceEnabled(hulu.ID, true)
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, wh := range []*WatchHistory{
+		// Watched before the period, so its rewatch on Hulu counts as one
+		{ServiceID: netflix.ID, Title: "The Office", EpisodeInfo: "S2E1", DurationMinutes: 22, WatchedAt: start.AddDate(-1, 0, 0)},
+		{ServiceID: hulu.ID, Title: "the office", EpisodeInfo: "S2E1", DurationMinutes: 22, WatchedAt: start.Add(time.Hour)},
+		{ServiceID: hulu.ID, Title: "The Office", EpisodeInfo: "S2E2", DurationMinutes: 22, WatchedAt: start.Add(2 * time.Hour)},
+		{ServiceID: netflix.ID, Title: "Severance", EpisodeInfo: "S1E1", DurationMinutes: 50, WatchedAt: start.Add(3 * time.Hour)},
+		{ServiceID: netflix.ID, Title: "Severance", EpisodeInfo: "S1E1", DurationMinutes: 50, WatchedAt: start.AddDate(0, 0, 1)},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	stats, err := db.GetRewatchStats(start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("Failed to get rewatch stats: %v", err)
+	}
+	if stats.NewMinutes != 72 || stats.NewCount != 2 || stats.RewatchMinutes != 72 || stats.RewatchCount != 2 {
+		t.Errorf("Expected 72 new and 72 rewatched minutes over two entries each, got %+v", stats)
+	}
+	if stats.RewatchRatio != 0.5 {
+		t.Errorf("Expected a rewatch ratio of 0.5, got %v", stats.RewatchRatio)
+	}
+
+	titles, err := db.GetTopRewatchedTitles(start, start.AddDate(0, 1, 0), 10)
+	if err != nil {
+		t.Fatalf("Failed to get rewatched titles: %v", err)
+	}
+	if len(titles) != 2 || titles[0].Title != "Severance" || titles[0].TotalMinutes != 50 {
+		t.Errorf("Expected Severance then The Office, got %+v", titles)
+	}
+
+	serviceTitles, err := db.GetTopRewatchedServiceTitles(start, start.AddDate(0, 1, 0), 10)
+	if err != nil {
+		t.Fatalf("Failed to get rewatched service titles: %v", err)
+	}
+	if len(serviceTitles) != 2 || serviceTitles[1].ServiceName != "Hulu" || serviceTitles[1].WatchCount != 1 {
+		t.Errorf("Expected Severance on Netflix then The Office on Hulu, got %+v", serviceTitles)
+	}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunPruneResult summarizes a scraper run cleanup
+type RunPruneResult struct {
+	RunsDeleted int64   `json:"runs_deleted"`
+	RunIDs      []int64 `json:"-"` // Deleted runs, whose stored artifacts can go too
+}
+
+// RunReliability totals a service's scraper runs, counting both stored runs
+// and the daily summaries of pruned ones
+type RunReliability struct {
+	ServiceID    int64   `json:"service_id"`
+	ServiceName  string  `json:"service_name"`
+	Runs         int     `json:"runs"`
+	Succeeded    int     `json:"succeeded"`
+	Failed       int     `json:"failed"`
+	ItemsScraped int     `json:"items_scraped"`
+	SuccessRate  float64 `json:"success_rate"` // Fraction of runs that succeeded, 0 to 1
+	FirstDay     string  `json:"first_day"`    // YYYY-MM-DD of the earliest run counted
+}
+
+// runSucceeded is 1 for a successful run and 0 otherwise
+const runSucceeded = `CASE WHEN status = 'success' THEN 1 ELSE 0 END`
+
+// PruneScraperRuns rolls runs before the cutoff up into per-day summaries
+// and deletes them, always keeping each service's newest keepPerService runs
+// so recent failures and circuit backoff still see them
+func (db *DB) PruneScraperRuns(before time.Time, keepPerService int) (*RunPruneResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const prunable = `id IN (
+		SELECT id FROM (
+			SELECT id, ran_at, ROW_NUMBER() OVER (PARTITION BY service_id ORDER BY ran_at DESC, id DESC) AS newest
+			FROM scraper_runs
+		) ranked
+		WHERE ran_at < ? AND newest > ?
+	)`
+
+	result := &RunPruneResult{}
+	rows, err := tx.Query(`SELECT id FROM scraper_runs WHERE `+prunable, before, keepPerService)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result.RunIDs = append(result.RunIDs, id)
+	}
+	rows.Close()
+	if len(result.RunIDs) == 0 {
+		return result, nil
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO scraper_run_summaries (service_id, day, runs, succeeded, failed, items_scraped)
+		SELECT service_id, `+db.dialect.date("ran_at")+`, COUNT(*), SUM(`+runSucceeded+`), SUM(1 - `+runSucceeded+`), SUM(items_scraped)
+		FROM scraper_runs
+		WHERE `+prunable+`
+		GROUP BY service_id, `+db.dialect.date("ran_at")+`
+		ON CONFLICT(service_id, day) DO UPDATE SET
+			runs = scraper_run_summaries.runs + excluded.runs,
+			succeeded = scraper_run_summaries.succeeded + excluded.succeeded,
+			failed = scraper_run_summaries.failed + excluded.failed,
+			items_scraped = scraper_run_summaries.items_scraped + excluded.items_scraped
+	`, before, keepPerService); err != nil {
+		return nil, fmt.Errorf("failed to summarize scraper runs: %w", err)
+	}
+
+	res, err := tx.Exec(`DELETE FROM scraper_runs WHERE `+prunable, before, keepPerService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete scraper runs: %w", err)
+	}
+	result.RunsDeleted, _ = res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRunReliability totals runs since the given time for every service that
+// has any, ordered by service name
+func (db *DB) GetRunReliability(since time.Time) ([]RunReliability, error) {
+	day := since.Format("2006-01-02")
+	rows, err := db.Query(`
+		SELECT s.id, s.name, SUM(r.runs), SUM(r.succeeded), SUM(r.failed), SUM(r.items_scraped), MIN(r.day)
+		FROM (
+			SELECT service_id, day, runs, succeeded, failed, items_scraped
+			FROM scraper_run_summaries
+			WHERE day >= ?
+			UNION ALL
+			SELECT service_id, `+db.dialect.date("ran_at")+`, 1, `+runSucceeded+`, 1 - `+runSucceeded+`, items_scraped
+			FROM scraper_runs
+			WHERE `+db.dialect.date("ran_at")+` >= ?
+		) r
+		JOIN services s ON r.service_id = s.id
+		GROUP BY s.id
+		ORDER BY s.name
+	`, day, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reliability := []RunReliability{}
+	for rows.Next() {
+		var r RunReliability
+		if err := rows.Scan(&r.ServiceID, &r.ServiceName, &r.Runs, &r.Succeeded, &r.Failed, &r.ItemsScraped, &r.FirstDay); err != nil {
+			return nil, err
+		}
+		if r.Runs > 0 {
+			r.SuccessRate = float64(r.Succeeded) / float64(r.Runs)
+		}
+		reliability = append(reliability, r)
+	}
+	return reliability, rows.Err()
+}
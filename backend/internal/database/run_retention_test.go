@@ -0,0 +1,67 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPruneScraperRuns(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	db.Exec(`DELETE FROM scraper_runs`)
+	db.Exec(`DELETE FROM scraper_run_summaries`)
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	hulu, _ := db.GetServiceByName("Hulu")
+	old := time.Date(2024, 1, 10, 3, 0, 0, 0, time.UTC)
+	runs := []ScraperRun{
+		{ServiceID: netflix.ID, RanAt: old, Status: "success", ItemsScraped: 10},
+		{ServiceID: netflix.ID, RanAt: old.Add(time.Hour), Status: "failed"},
+		{ServiceID: netflix.ID, RanAt: old.AddDate(0, 0, 1), Status: "success", ItemsScraped: 5},
+		{ServiceID: netflix.ID, RanAt: time.Now(), Status: "success", ItemsScraped: 1},
+		// Hulu's only run is old but kept as its newest
+		{ServiceID: hulu.ID, RanAt: old, Status: "failed"},
+	}
+	for i := range runs {
+		if err := db.InsertScraperRun(&runs[i]); err != nil {
+			t.Fatalf("Failed to insert run: %v", err)
+		}
+	}
+
+	result, err := db.PruneScraperRuns(time.Now().AddDate(0, 0, -90), 1)
+	if err != nil {
+		t.Fatalf("Failed to prune runs: %v", err)
+	}
+	if result.RunsDeleted != 3 || len(result.RunIDs) != 3 {
+		t.Errorf("Expected Netflix's 3 old runs deleted, got %+v", result)
+	}
+
+	var left int
+	db.QueryRow(`SELECT COUNT(*) FROM scraper_runs`).Scan(&left)
+	if left != 2 {
+		t.Errorf("Expected 2 runs left, got %d", left)
+	}
+
+	// Pruned runs still count toward reliability
+	reliability, err := db.GetRunReliability(time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to get reliability: %v", err)
+	}
+	if len(reliability) != 2 {
+		t.Fatalf("Expected Hulu and Netflix, got %+v", reliability)
+	}
+	nf := reliability[1]
+	if nf.ServiceName != "Netflix" || nf.Runs != 4 || nf.Succeeded != 3 || nf.Failed != 1 || nf.ItemsScraped != 16 || nf.SuccessRate != 0.75 || nf.FirstDay != "2024-01-10" {
+		t.Errorf("Expected Netflix totals across pruned and kept runs, got %+v", nf)
+	}
+
+	recent, _ := db.GetRunReliability(time.Now().AddDate(0, 0, -1))
+	if len(recent) != 1 || recent[0].Runs != 1 {
+		t.Errorf("Expected only the recent run counted, got %+v", recent)
+	}
+
+	// Pruning again finds nothing new
+	if result, _ := db.PruneScraperRuns(time.Now().AddDate(0, 0, -90), 1); result.RunsDeleted != 0 {
+		t.Errorf("Expected nothing left to prune, got %+v", result)
+	}
+}
@@ -0,0 +1,177 @@
+package database
+
+import "log"
+
+// serviceSeed is a default streaming service and how it's shown
+type serviceSeed struct {
+	name    string
+	color   string
+	logoURL string
+}
+
+// initialServices are the services every database starts with. Like the
+// migrations that use it, don't edit this list once shipped: rename or
+// restyle services in a new migration instead.
+var initialServices = []serviceSeed{
+	{"Netflix", "#E50914", "/logos/netflix.svg"},
+	{"YouTube TV", "#FF0000", "/logos/youtube-tv.svg"},
+	{"YouTube", "#FF0000", "/logos/youtube.svg"},
+	{"Amazon Video", "#00A8E1", "/logos/amazon-video.svg"},
+	{"HBO Max", "#7B3FF2", "/logos/hbo-max.svg"},
+	{"Apple TV+", "#000000", "/logos/apple-tv.svg"},
+	{"Peacock", "#000000", "/logos/peacock.svg"},
+	{"Vudu", "#3399FF", "/logos/vudu.svg"},
+	{"Hulu", "#1CE783", "/logos/hulu.svg"},
+	{"Disney+", "#113CCF", "/logos/disney.svg"},
+	{"Kanopy", "#F26522", "/logos/kanopy.svg"},
+	{"Hoopla", "#009FDA", "/logos/hoopla.svg"},
+	{"MUBI", "#001489", "/logos/mubi.svg"},
+	{"Criterion Channel", "#1A1A1A", "/logos/criterion.svg"},
+	{"ESPN+", "#FFB800", "/logos/espn.svg"},
+	{"Audible", "#F8991C", "/logos/audible.svg"},
+}
+
+// logoFixes point services at the logo files the frontend ships, which were
+// seeded under other names
+var logoFixes = []serviceSeed{
+	{"Amazon Video", "#00A8E1", "/logos/amazon.svg"},
+	{"HBO Max", "#7B3FF2", "/logos/hbo.svg"},
+}
+
+// serviceRename renames a seeded service after the streaming service
+// rebrands, keeping its history
+type serviceRename struct {
+	from string
+	to   string
+}
+
+// serviceRenames are applied by migrations in order. CurrentServiceName
+// follows them, so names from older configs and exports keep working.
+var serviceRenames = []serviceRename{
+	{"Apple TV+", "Apple TV"}, // Rebranded in October 2025
+}
+
+// CurrentServiceName returns the name a service has now, following renames
+// from a former name such as "Apple TV+"
+func CurrentServiceName(name string) string {
+	for _, r := range serviceRenames {
+		if name == r.from {
+			name = r.to
+		}
+	}
+	return name
+}
+
+// serviceKey is the config key a seeded service is routed by
+type serviceKey struct {
+	key  string
+	name string
+}
+
+// initialServiceKeys are the built-in keys of the seeded services, by the
+// names they had when keys were added
+var initialServiceKeys = []serviceKey{
+	{"netflix", "Netflix"},
+	{"youtube_tv", "YouTube TV"},
+	{"youtube", "YouTube"},
+	{"amazon_video", "Amazon Video"},
+	{"hbo_max", "HBO Max"},
+	{"apple_tv", "Apple TV"},
+	{"peacock", "Peacock"},
+	{"vudu", "Vudu"},
+	{"hulu", "Hulu"},
+	{"disney_plus", "Disney+"},
+	{"kanopy", "Kanopy"},
+	{"hoopla", "Hoopla"},
+	{"mubi", "MUBI"},
+	{"criterion", "Criterion Channel"},
+	{"espn_plus", "ESPN+"},
+	{"audible", "Audible"},
+}
+
+// seedServices returns a step adding the services that don't exist yet. It
+// leaves existing ones alone, so it's safe on databases seeded before seeds
+// were versioned.
+func seedServices(seeds []serviceSeed) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for _, svc := range seeds {
+			if _, err := tx.Exec(`
+				INSERT INTO services (name, color, logo_url, enabled)
+				VALUES (?, ?, ?, FALSE)
+				ON CONFLICT DO NOTHING
+			`, svc.name, svc.color, svc.logoURL); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// restyleServices returns a step setting the color and logo of services;
+// services that don't exist are skipped
+func restyleServices(styles []serviceSeed) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for _, svc := range styles {
+			if _, err := tx.Exec(`UPDATE services SET color = ?, logo_url = ? WHERE name = ?`, svc.color, svc.logoURL, svc.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// renameServices returns a step applying renames in order. A service is
+// left under its old name if one with the new name already exists, such as
+// one created for a display_name, since merging history is up to the user.
+func renameServices(renames []serviceRename) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for _, r := range renames {
+			if err := renameService(tx, r.from, r.to); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// revertRenames returns a step undoing renames, newest first
+func revertRenames(renames []serviceRename) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for i := len(renames) - 1; i >= 0; i-- {
+			if err := renameService(tx, renames[i].to, renames[i].from); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// keyServices returns a step giving services their keys. Services that
+// don't exist, already have a key, or whose key is taken are skipped.
+func keyServices(keys []serviceKey) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for _, k := range keys {
+			if _, err := tx.Exec(`
+				UPDATE services SET service_key = ?
+				WHERE name = ? AND service_key = ''
+				  AND NOT EXISTS (SELECT 1 FROM services WHERE service_key = ?)
+			`, k.key, k.name, k.key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func renameService(tx *Tx, from, to string) error {
+	var taken int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM services WHERE name = ?`, to).Scan(&taken); err != nil {
+		return err
+	}
+	if taken > 0 {
+		log.Printf("Not renaming service %q to %q: a service with that name already exists; merge them with POST /api/services/:id/merge-into/:other", from, to)
+		return nil
+	}
+	_, err := tx.Exec(`UPDATE services SET name = ? WHERE name = ?`, to, from)
+	return err
+}
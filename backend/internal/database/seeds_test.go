@@ -0,0 +1,132 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSeededServicesFollowRenames(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	renamed, err := db.GetServiceByName("Apple TV")
+	if err != nil || renamed == nil {
+		t.Fatalf("Expected the renamed service to exist (%v)", err)
+	}
+	former, err := db.GetServiceByName("Apple TV+")
+	if err != nil || former == nil || former.ID != renamed.ID {
+		t.Errorf("Expected the former name to find the renamed service, got %+v (%v)", former, err)
+	}
+
+	hbo, _ := db.GetServiceByName("HBO Max")
+	if hbo.LogoURL != "/logos/hbo.svg" {
+		t.Errorf("Expected the fixed logo path, got %s", hbo.LogoURL)
+	}
+
+	ensured, err := db.EnsureService("Apple TV+", "#000000", "")
+	if err != nil || ensured.ID != renamed.ID {
+		t.Errorf("Expected EnsureService to reuse the renamed service, got %+v (%v)", ensured, err)
+	}
+}
+
+func TestRenameMigrationKeepsHistory(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "streamtime.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.MigrateDown(17); err != nil {
+		t.Fatalf("Failed to migrate down: %v", err)
+	}
+	appleID := serviceIDNamed(t, db, "Apple TV+")
+	if appleID == 0 {
+		t.Fatal("Expected reverting the rename to restore the former name")
+	}
+	if err := db.InsertWatchHistory(&WatchHistory{ServiceID: appleID, Title: "Severance", DurationMinutes: 50}); err != nil {
+		t.Fatalf("Failed to insert history: %v", err)
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate up: %v", err)
+	}
+	renamed, _ := db.getServiceByName("Apple TV")
+	if renamed == nil || renamed.ID != appleID {
+		t.Fatalf("Expected the service to be renamed in place, got %+v", renamed)
+	}
+	if has, _ := db.HasWatchHistory(renamed.ID); !has {
+		t.Error("Expected history to stay with the renamed service")
+	}
+}
+
+func TestRenameSkippedWhenNameTaken(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "streamtime.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.MigrateDown(17); err != nil {
+		t.Fatalf("Failed to migrate down: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO services (name, color, logo_url, enabled) VALUES ('Apple TV', '#111111', '', TRUE)`); err != nil {
+		t.Fatalf("Failed to add service: %v", err)
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate up: %v", err)
+	}
+	if former, _ := db.getServiceByName("Apple TV+"); former == nil {
+		t.Error("Expected the former service to keep its name when the new one is taken")
+	}
+}
+
+// serviceIDNamed looks a service up with a query that works on older
+// schemas, returning 0 if there's none with the name
+func serviceIDNamed(t *testing.T, db *DB, name string) int64 {
+	var id int64
+	if err := db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM services WHERE name = ?`, name).Scan(&id); err != nil {
+		t.Fatalf("Failed to look up service %s: %v", name, err)
+	}
+	return id
+}
+
+func TestServiceKeys(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	apple, err := db.GetServiceByKey("apple_tv")
+	if err != nil || apple == nil || apple.Name != "Apple TV" {
+		t.Fatalf("Expected apple_tv to route to the renamed service, got %+v (%v)", apple, err)
+	}
+
+	custom, err := db.EnsureService("Netflix (Family)", "#E50914", "")
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if err := db.SetServiceKey("Netflix (Family)", "netflix"); err != nil {
+		t.Fatalf("Failed to set service key: %v", err)
+	}
+	if svc, _ := db.GetServiceByKey("netflix"); svc == nil || svc.ID != custom.ID {
+		t.Errorf("Expected the key to move to the new service, got %+v", svc)
+	}
+	if netflix, _ := db.GetServiceByName("Netflix"); netflix.Key != "" {
+		t.Errorf("Expected Netflix to lose its key, got %q", netflix.Key)
+	}
+
+	if err := db.SetServiceKey("Missing", "missing"); err != nil {
+		t.Errorf("Expected setting the key of a missing service to do nothing, got %v", err)
+	}
+	if svc, _ := db.GetServiceByKey(""); svc != nil {
+		t.Errorf("Expected no service for an empty key, got %+v", svc)
+	}
+}
+
+func TestCurrentServiceName(t *testing.T) {
+	if got := CurrentServiceName("Apple TV+"); got != "Apple TV" {
+		t.Errorf("Expected Apple TV, got %s", got)
+	}
+	if got := CurrentServiceName("Netflix"); got != "Netflix" {
+		t.Errorf("Expected names that weren't renamed to be kept, got %s", got)
+	}
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"database/sql"
+)
+
+// SaveServiceCookies stores imported cookies for a service as JSON, replacing
+// any imported earlier. Scrapers use them instead of the cookies in config.
+func (db *DB) SaveServiceCookies(serviceID int64, cookies string) error {
+	_, err := db.Exec(`
+		INSERT INTO service_cookies (service_id, cookies, updated)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(service_id) DO UPDATE SET cookies = excluded.cookies, updated = CURRENT_TIMESTAMP
+	`, serviceID, cookies)
+	return err
+}
+
+// GetServiceCookies returns a service's imported cookies as JSON, or "" if none were imported
+func (db *DB) GetServiceCookies(serviceID int64) (string, error) {
+	var cookies string
+	err := db.QueryRow(`SELECT cookies FROM service_cookies WHERE service_id = ?`, serviceID).Scan(&cookies)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return cookies, err
+}
+
+// DeleteServiceCookies removes a service's imported cookies so its config
+// cookies are used again. It reports whether any were stored.
+func (db *DB) DeleteServiceCookies(serviceID int64) (bool, error) {
+	result, err := db.Exec(`DELETE FROM service_cookies WHERE service_id = ?`, serviceID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
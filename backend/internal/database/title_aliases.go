@@ -0,0 +1,171 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// GetTitleAliases returns all title aliases ordered by canonical title
+func (db *DB) GetTitleAliases() ([]TitleAlias, error) {
+	rows, err := db.Query(`
+		SELECT id, alias, canonical, created
+		FROM title_aliases
+		ORDER BY canonical, alias
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []TitleAlias{}
+	for rows.Next() {
+		var ta TitleAlias
+		if err := rows.Scan(&ta.ID, &ta.Alias, &ta.Canonical, &ta.Created); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, ta)
+	}
+
+	return aliases, rows.Err()
+}
+
+// CanonicalTitle returns the canonical title for an alias, or the title itself
+// if it isn't aliased
+func (db *DB) CanonicalTitle(title string) (string, error) {
+	return canonicalTitle(db, title)
+}
+
+// canonicalTitle looks up a title's canonical title through a connection or transaction
+func canonicalTitle(q rowQuerier, title string) (string, error) {
+	var canonical string
+	err := q.QueryRow(`SELECT canonical FROM title_aliases WHERE alias = ?`, title).Scan(&canonical)
+	if err == sql.ErrNoRows {
+		return title, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return canonical, nil
+}
+
+// InsertTitleAlias maps an alias to a canonical title. Aliases are kept one
+// level deep: a canonical title that is itself an alias is resolved, and
+// existing aliases of the new alias are repointed to the canonical title.
+func (db *DB) InsertTitleAlias(ta *TitleAlias) error {
+	ta.Alias = strings.TrimSpace(ta.Alias)
+	canonical, err := db.CanonicalTitle(strings.TrimSpace(ta.Canonical))
+	if err != nil {
+		return err
+	}
+	ta.Canonical = canonical
+	if strings.EqualFold(ta.Alias, ta.Canonical) {
+		return fmt.Errorf("alias and canonical title must differ")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO title_aliases (alias, canonical)
+		VALUES (?, ?)
+		ON CONFLICT(alias) DO UPDATE SET canonical = excluded.canonical
+	`, ta.Alias, ta.Canonical); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE title_aliases SET canonical = ? WHERE canonical = ? COLLATE NOCASE`, ta.Canonical, ta.Alias); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return db.QueryRow(`SELECT id, created FROM title_aliases WHERE alias = ?`, ta.Alias).Scan(&ta.ID, &ta.Created)
+}
+
+// DeleteTitleAlias removes a title alias. History already renamed keeps its
+// canonical title.
+func (db *DB) DeleteTitleAlias(id int64) (bool, error) {
+	result, err := db.Exec(`DELETE FROM title_aliases WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ApplyTitleAliases renames existing history to canonical titles, dropping
+// rows that duplicate an entry already stored under the canonical title.
+// It returns the number of rows renamed and removed.
+func (db *DB) ApplyTitleAliases() (int64, int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE watch_history
+		SET title = (SELECT canonical FROM title_aliases ta WHERE ta.alias = watch_history.title)
+		WHERE id IN (` + renameableAliases("watch_history", "profile_id", "watched_at") + `)
+	`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to rename history: %w", err)
+	}
+	renamed, _ := res.RowsAffected()
+
+	// Rows left behind collided with the same entry under the canonical title
+	res, err = tx.Exec(`
+		DELETE FROM watch_history
+		WHERE EXISTS (SELECT 1 FROM title_aliases ta WHERE ta.alias = watch_history.title)
+	`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to remove duplicate history: %w", err)
+	}
+	removed, _ := res.RowsAffected()
+
+	// Compacted history is renamed the same way
+	if _, err := tx.Exec(`
+		UPDATE watch_history_rollups
+		SET title = (SELECT canonical FROM title_aliases ta WHERE ta.alias = watch_history_rollups.title)
+		WHERE id IN (` + renameableAliases("watch_history_rollups", "day") + `)
+	`); err != nil {
+		return 0, 0, fmt.Errorf("failed to rename rollups: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM watch_history_rollups
+		WHERE EXISTS (SELECT 1 FROM title_aliases ta WHERE ta.alias = watch_history_rollups.title)
+	`); err != nil {
+		return 0, 0, fmt.Errorf("failed to remove duplicate rollups: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return renamed, removed, nil
+}
+
+// renameableAliases returns a query for the IDs of a table's aliased rows to
+// rename: one per canonical title and keys, skipping those the canonical
+// title already has, so the rename can't break the table's unique key
+func renameableAliases(table string, keys ...string) string {
+	conds := make([]string, len(keys))
+	groups := make([]string, len(keys))
+	for i, key := range keys {
+		conds[i] = fmt.Sprintf("t.%[1]s = r.%[1]s", key)
+		groups[i] = "r." + key
+	}
+	return fmt.Sprintf(`
+		SELECT MIN(r.id)
+		FROM %[1]s r
+		JOIN title_aliases ta ON ta.alias = r.title
+		WHERE NOT EXISTS (
+			SELECT 1 FROM %[1]s t
+			WHERE t.service_id = r.service_id AND t.title = ta.canonical AND %[2]s
+		)
+		GROUP BY r.service_id, ta.canonical, %[3]s
+	`, table, strings.Join(conds, " AND "), strings.Join(groups, ", "))
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTitleAliasAppliedOnInsert(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := db.InsertTitleAlias(&TitleAlias{Alias: "The Office (U.S.)", Canonical: "The Office"}); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	wh := &WatchHistory{ServiceID: service.ID, Title: "the office (u.s.)", DurationMinutes: 22, WatchedAt: time.Now()}
+	if err := db.InsertWatchHistory(wh); err != nil {
+		t.Fatalf("Failed to insert history: %v", err)
+	}
+	if wh.Title != "The Office" {
+		t.Errorf("Expected canonical title, got '%s'", wh.Title)
+	}
+}
+
+func TestApplyTitleAliases(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	watchedAt := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "The Office (U.S.)", DurationMinutes: 22, WatchedAt: watchedAt})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "The Office (U.S.)", DurationMinutes: 22, WatchedAt: watchedAt.Add(time.Hour)})
+	// Same episode already stored under the canonical title
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "The Office", DurationMinutes: 22, WatchedAt: watchedAt})
+
+	db.InsertTitleAlias(&TitleAlias{Alias: "The Office (U.S.)", Canonical: "The Office"})
+
+	renamed, removed, err := db.ApplyTitleAliases()
+	if err != nil {
+		t.Fatalf("Failed to apply aliases: %v", err)
+	}
+	if renamed != 1 || removed != 1 {
+		t.Errorf("Expected 1 renamed and 1 removed, got %d and %d", renamed, removed)
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM watch_history WHERE title = 'The Office'`).Scan(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 rows under the canonical title, got %d", count)
+	}
+}
+
+func TestApplyTitleAliasesCollidingAliases(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	watchedAt := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	// Two aliases of the same title watched at the same time rename to one entry
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "The Office (U.S.)", DurationMinutes: 22, WatchedAt: watchedAt})
+	db.InsertWatchHistory(&WatchHistory{ServiceID: service.ID, Title: "Office US", DurationMinutes: 22, WatchedAt: watchedAt})
+
+	db.InsertTitleAlias(&TitleAlias{Alias: "The Office (U.S.)", Canonical: "The Office"})
+	db.InsertTitleAlias(&TitleAlias{Alias: "Office US", Canonical: "The Office"})
+
+	renamed, removed, err := db.ApplyTitleAliases()
+	if err != nil {
+		t.Fatalf("Failed to apply aliases: %v", err)
+	}
+	if renamed != 1 || removed != 1 {
+		t.Errorf("Expected 1 renamed and 1 removed, got %d and %d", renamed, removed)
+	}
+}
+
+func TestInsertTitleAliasFlattensChains(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	db.InsertTitleAlias(&TitleAlias{Alias: "Office US", Canonical: "The Office (U.S.)"})
+	db.InsertTitleAlias(&TitleAlias{Alias: "The Office (U.S.)", Canonical: "The Office"})
+
+	canonical, _ := db.CanonicalTitle("Office US")
+	if canonical != "The Office" {
+		t.Errorf("Expected existing alias to be repointed, got '%s'", canonical)
+	}
+
+	// A canonical title that is itself an alias resolves to its canonical
+	ta := &TitleAlias{Alias: "Office", Canonical: "Office US"}
+	db.InsertTitleAlias(ta)
+	if ta.Canonical != "The Office" {
+		t.Errorf("Expected canonical to resolve, got '%s'", ta.Canonical)
+	}
+
+	if err := db.InsertTitleAlias(&TitleAlias{Alias: "the office", Canonical: "The Office"}); err == nil {
+		t.Error("Expected error aliasing a title to itself")
+	}
+}
@@ -0,0 +1,149 @@
+package database
+
+import "time"
+
+// GetTopTitles returns the most watched titles for a time period across all
+// enabled services, ordered by total watch time
+func (db *DB) GetTopTitles(startDate, endDate time.Time, limit int) ([]TitleStats, error) {
+	rows, err := db.Query(`
+		SELECT wh.title, SUM(wh.duration_minutes) as total_minutes, SUM(wh.entries) as watch_count
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY wh.title COLLATE NOCASE
+		ORDER BY total_minutes DESC
+		LIMIT ?
+	`, startDate, endDate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []TitleStats{}
+	for rows.Next() {
+		var ts TitleStats
+		if err := rows.Scan(&ts.Title, &ts.TotalMinutes, &ts.WatchCount); err != nil {
+			return nil, err
+		}
+		titles = append(titles, ts)
+	}
+
+	return titles, rows.Err()
+}
+
+// GetTopServiceTitles returns the most watched titles for a time period on
+// each enabled service, so a show watched on two services is listed twice,
+// ordered by total watch time
+func (db *DB) GetTopServiceTitles(startDate, endDate time.Time, limit int) ([]ServiceTitleStats, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.name, wh.title, SUM(wh.duration_minutes) as total_minutes, SUM(wh.entries) as watch_count
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY s.id, s.name, wh.title COLLATE NOCASE
+		ORDER BY total_minutes DESC
+		LIMIT ?
+	`, startDate, endDate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []ServiceTitleStats{}
+	for rows.Next() {
+		var st ServiceTitleStats
+		if err := rows.Scan(&st.ServiceID, &st.ServiceName, &st.Title, &st.TotalMinutes, &st.WatchCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetWatchTotals returns total minutes and entry count for a time period across
+// enabled services, optionally restricted to one service name and/or title
+func (db *DB) GetWatchTotals(serviceName, title string, startDate, endDate time.Time) (int, int, error) {
+	var minutes, count int
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(wh.duration_minutes), 0), COALESCE(SUM(wh.entries), 0)
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND (? = '' OR s.name = ?)
+		  AND (? = '' OR wh.title = ? COLLATE NOCASE)
+		  AND `+notIgnoredClause+`
+	`, startDate, endDate, serviceName, serviceName, title, title).Scan(&minutes, &count)
+	return minutes, count, err
+}
+
+// GetTitleSpellings returns watch totals for every distinct spelling of a title
+// in a time period, across all services. Unlike GetTopTitles, titles differing
+// only in case are kept apart so they can be aliased.
+func (db *DB) GetTitleSpellings(startDate, endDate time.Time) ([]TitleStats, error) {
+	rows, err := db.Query(`
+		SELECT wh.title, SUM(wh.duration_minutes) as total_minutes, SUM(wh.entries) as watch_count
+		FROM `+db.watchTime()+` wh
+		WHERE wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND `+notIgnoredClause+`
+		GROUP BY wh.title
+		ORDER BY total_minutes DESC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := []TitleStats{}
+	for rows.Next() {
+		var ts TitleStats
+		if err := rows.Scan(&ts.Title, &ts.TotalMinutes, &ts.WatchCount); err != nil {
+			return nil, err
+		}
+		titles = append(titles, ts)
+	}
+
+	return titles, rows.Err()
+}
+
+// GetVideoTitleStats returns watch time and entry counts per title on each
+// enabled service, leaving out listening (audiobooks), for adjustments that
+// only apply to video such as skipping intros and credits
+func (db *DB) GetVideoTitleStats(startDate, endDate time.Time) ([]ServiceTitleStats, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.name, wh.title, SUM(wh.duration_minutes) as total_minutes, SUM(wh.entries) as watch_count
+		FROM `+db.watchTime()+` wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE s.enabled = TRUE
+		  AND wh.watched_at >= ?
+		  AND wh.watched_at < ?
+		  AND COALESCE(NULLIF(wh.media_kind, ''), 'video') = 'video'
+		  AND `+notIgnoredClause+`
+		GROUP BY s.id, s.name, wh.title COLLATE NOCASE
+		ORDER BY s.name, total_minutes DESC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []ServiceTitleStats{}
+	for rows.Next() {
+		var st ServiceTitleStats
+		if err := rows.Scan(&st.ServiceID, &st.ServiceName, &st.Title, &st.TotalMinutes, &st.WatchCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
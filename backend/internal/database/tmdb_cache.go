@@ -0,0 +1,37 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetTMDBResponse returns a TMDB response cached for a request and
+// normalized title since a time, and whether one was found
+func (db *DB) GetTMDBResponse(request, title string, since time.Time) (string, bool, error) {
+	var response string
+	err := db.QueryRow(`
+		SELECT response
+		FROM tmdb_cache
+		WHERE request = ? AND title = ? AND updated >= ?
+	`, request, title, since).Scan(&response)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return response, true, nil
+}
+
+// SetTMDBResponse caches a TMDB response for a request and normalized title,
+// replacing any cached before
+func (db *DB) SetTMDBResponse(request, title, response string) error {
+	_, err := db.Exec(`
+		INSERT INTO tmdb_cache (request, title, response, updated)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(request, title) DO UPDATE SET
+			response = excluded.response,
+			updated = excluded.updated
+	`, request, title, response, time.Now())
+	return err
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTMDBResponseCache(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, ok, err := db.GetTMDBResponse("/search/movie", "stalker", time.Time{}); err != nil || ok {
+		t.Fatalf("Expected no cached response, got ok=%v err=%v", ok, err)
+	}
+
+	if err := db.SetTMDBResponse("/search/movie", "stalker", `{"results": []}`); err != nil {
+		t.Fatalf("SetTMDBResponse failed: %v", err)
+	}
+	if err := db.SetTMDBResponse("/search/movie", "stalker", `{"results": [{"id": 1398}]}`); err != nil {
+		t.Fatalf("SetTMDBResponse failed: %v", err)
+	}
+
+	response, ok, err := db.GetTMDBResponse("/search/movie", "stalker", time.Now().Add(-time.Hour))
+	if err != nil || !ok || response != `{"results": [{"id": 1398}]}` {
+		t.Errorf("Expected the latest response, got %q ok=%v err=%v", response, ok, err)
+	}
+
+	// Entries older than the cutoff are treated as missing
+	if _, ok, _ := db.GetTMDBResponse("/search/movie", "stalker", time.Now().Add(time.Hour)); ok {
+		t.Error("Expected a stale response to be ignored")
+	}
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// GetTraktAuth returns the authorized Trakt account, or nil if none was authorized
+func (db *DB) GetTraktAuth() (*TraktAuth, error) {
+	var auth TraktAuth
+	var lastPulled sql.NullTime
+	err := db.QueryRow(`
+		SELECT access_token, refresh_token, expires, last_pulled
+		FROM trakt_auth
+		WHERE id = 1
+	`).Scan(&auth.AccessToken, &auth.RefreshToken, &auth.Expires, &lastPulled)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lastPulled.Valid {
+		auth.LastPulled = lastPulled.Time
+	}
+	return &auth, nil
+}
+
+// SaveTraktAuth stores the tokens of an authorized or refreshed Trakt
+// account, keeping when history was last pulled
+func (db *DB) SaveTraktAuth(auth *TraktAuth) error {
+	_, err := db.Exec(`
+		INSERT INTO trakt_auth (id, access_token, refresh_token, expires, updated)
+		VALUES (1, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET
+			access_token = excluded.access_token,
+			refresh_token = excluded.refresh_token,
+			expires = excluded.expires,
+			updated = CURRENT_TIMESTAMP
+	`, auth.AccessToken, auth.RefreshToken, auth.Expires)
+	return err
+}
+
+// SetTraktLastPulled records when Trakt history was last pulled
+func (db *DB) SetTraktLastPulled(at time.Time) error {
+	_, err := db.Exec(`UPDATE trakt_auth SET last_pulled = ? WHERE id = 1`, at)
+	return err
+}
+
+// GetUnpushedTraktHistory returns up to limit video entries read from
+// services, oldest first, that haven't been pushed to Trakt. Entries inferred
+// from app usage or traffic, and those of the excluded services, are left out.
+func (db *DB) GetUnpushedTraktHistory(excludeServiceIDs []int64, limit int) ([]WatchHistory, error) {
+	conds := []string{
+		"COALESCE(wh.media_kind, 'video') = 'video'",
+		"COALESCE(wh.confidence, '') = ''",
+		"NOT EXISTS (SELECT 1 FROM trakt_pushed tp WHERE tp.watch_history_id = wh.id)",
+		notIgnoredClause,
+	}
+	var args []interface{}
+	for _, id := range excludeServiceIDs {
+		conds = append(conds, "wh.service_id != ?")
+		args = append(args, id)
+	}
+	args = append(args, limit)
+
+	rows, err := db.Query(`
+		SELECT `+watchHistoryColumns+`
+		FROM watch_history wh
+		JOIN services s ON wh.service_id = s.id
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY wh.id
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWatchHistory(rows)
+}
+
+// MarkTraktPushed records that entries were pushed to Trakt, with the TMDB
+// IDs they were sent under by watch history ID, found reporting whether
+// Trakt matched them to a movie or episode. Entries Trakt couldn't match are
+// recorded too, so they aren't pushed again.
+func (db *DB) MarkTraktPushed(items []WatchHistory, tmdbIDs map[int64]int64, found func(WatchHistory) bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		if _, err := tx.Exec(`
+			INSERT INTO trakt_pushed (watch_history_id, watched_at, title, tmdb_id, found)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, item.ID, item.WatchedAt.Unix(), strings.ToLower(item.Title), tmdbIDs[item.ID], found(item)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// WasPushedToTrakt reports whether a play at the given time was pushed to
+// Trakt, to recognize plays pulled back from Trakt. Plays match on the TMDB ID
+// they were sent under, since Trakt returns its own spelling of titles, and
+// otherwise on the title, case-insensitively. Times match to the second.
+func (db *DB) WasPushedToTrakt(tmdbID int64, title string, watchedAt time.Time) (bool, error) {
+	match := "title = ?"
+	args := []interface{}{watchedAt.Unix(), strings.ToLower(title)}
+	if tmdbID != 0 {
+		match = "(tmdb_id = ? OR title = ?)"
+		args = []interface{}{watchedAt.Unix(), tmdbID, strings.ToLower(title)}
+	}
+
+	var exists bool
+	err := db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM trakt_pushed WHERE watched_at = ? AND `+match+`)
+	`, args...).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// savedViewColumns are selected in the order scanSavedView expects
+const savedViewColumns = `id, name, date_range, start_date, end_date, service_ids, granularity, chart_type, created, updated`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// GetSavedViews returns all saved views ordered by name
+func (db *DB) GetSavedViews() ([]SavedView, error) {
+	rows, err := db.Query(`SELECT ` + savedViewColumns + ` FROM saved_views ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []SavedView{}
+	for rows.Next() {
+		view, err := scanSavedView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *view)
+	}
+
+	return views, rows.Err()
+}
+
+// GetSavedView returns a saved view by ID, or nil if it doesn't exist
+func (db *DB) GetSavedView(id int64) (*SavedView, error) {
+	view, err := scanSavedView(db.QueryRow(`SELECT `+savedViewColumns+` FROM saved_views WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return view, err
+}
+
+// InsertSavedView stores a new saved view
+func (db *DB) InsertSavedView(view *SavedView) error {
+	now := time.Now()
+	err := db.QueryRow(`
+		INSERT INTO saved_views (name, date_range, start_date, end_date, service_ids, granularity, chart_type, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, view.Name, view.DateRange, view.StartDate, view.EndDate, joinIDs(view.ServiceIDs), view.Granularity, view.ChartType, now, now).Scan(&view.ID)
+	if err != nil {
+		return err
+	}
+	view.Created, view.Updated = now, now
+
+	return nil
+}
+
+// UpdateSavedView replaces a saved view's configuration, returning false if it doesn't exist
+func (db *DB) UpdateSavedView(view *SavedView) (bool, error) {
+	view.Updated = time.Now()
+	result, err := db.Exec(`
+		UPDATE saved_views
+		SET name = ?, date_range = ?, start_date = ?, end_date = ?, service_ids = ?, granularity = ?, chart_type = ?, updated = ?
+		WHERE id = ?
+	`, view.Name, view.DateRange, view.StartDate, view.EndDate, joinIDs(view.ServiceIDs), view.Granularity, view.ChartType, view.Updated, view.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteSavedView removes a saved view
+func (db *DB) DeleteSavedView(id int64) (bool, error) {
+	result, err := db.Exec(`DELETE FROM saved_views WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// scanSavedView reads a saved view row
+func scanSavedView(row rowScanner) (*SavedView, error) {
+	var view SavedView
+	var serviceIDs string
+	err := row.Scan(&view.ID, &view.Name, &view.DateRange, &view.StartDate, &view.EndDate,
+		&serviceIDs, &view.Granularity, &view.ChartType, &view.Created, &view.Updated)
+	if err != nil {
+		return nil, err
+	}
+
+	view.ServiceIDs = []int64{}
+	for _, s := range strings.Split(serviceIDs, ",") {
+		if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+			view.ServiceIDs = append(view.ServiceIDs, id)
+		}
+	}
+	return &view, nil
+}
+
+// joinIDs stores a list of IDs as comma-separated text
+func joinIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
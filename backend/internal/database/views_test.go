@@ -0,0 +1,53 @@
+package database
+
+import "testing"
+
+func TestSavedViewCRUD(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	view := &SavedView{
+		Name:        "Kids TV this month",
+		DateRange:   "this_month",
+		ServiceIDs:  []int64{1, 3},
+		Granularity: "day",
+		ChartType:   "bar",
+	}
+	if err := db.InsertSavedView(view); err != nil {
+		t.Fatalf("Failed to insert view: %v", err)
+	}
+
+	// Names are unique regardless of case
+	if err := db.InsertSavedView(&SavedView{Name: "kids tv this month", DateRange: "all_time", Granularity: "month", ChartType: "line"}); err == nil {
+		t.Error("Expected duplicate name to fail")
+	}
+
+	got, err := db.GetSavedView(view.ID)
+	if err != nil || got == nil {
+		t.Fatalf("Failed to get view: %v", err)
+	}
+	if len(got.ServiceIDs) != 2 || got.ServiceIDs[1] != 3 {
+		t.Errorf("Expected service IDs [1 3], got %v", got.ServiceIDs)
+	}
+
+	got.ServiceIDs = nil
+	got.ChartType = "line"
+	if updated, err := db.UpdateSavedView(got); err != nil || !updated {
+		t.Fatalf("Failed to update view: %v", err)
+	}
+
+	views, err := db.GetSavedViews()
+	if err != nil {
+		t.Fatalf("Failed to list views: %v", err)
+	}
+	if len(views) != 1 || views[0].ChartType != "line" || len(views[0].ServiceIDs) != 0 {
+		t.Errorf("Unexpected views: %+v", views)
+	}
+
+	if deleted, _ := db.DeleteSavedView(view.ID); !deleted {
+		t.Error("Expected view to be deleted")
+	}
+	if got, _ := db.GetSavedView(view.ID); got != nil {
+		t.Error("Expected deleted view to be gone")
+	}
+}
@@ -0,0 +1,143 @@
+package estimate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Estimator guesses how long a title runs when its source has no runtime.
+// Heuristic estimators return 0 for titles they don't recognize, so they can
+// be chained in front of a fixed fallback.
+type Estimator interface {
+	Estimate(title, episodeInfo string) int
+}
+
+// Func adapts a function to the Estimator interface
+type Func func(title, episodeInfo string) int
+
+// Estimate calls f
+func (f Func) Estimate(title, episodeInfo string) int {
+	return f(title, episodeInfo)
+}
+
+// Fixed estimates every episode and every film with the same lengths
+type Fixed struct {
+	EpisodeMinutes int
+	FilmMinutes    int
+}
+
+// Estimate returns the episode length for titles with episode info and the
+// film length otherwise
+func (f Fixed) Estimate(title, episodeInfo string) int {
+	if episodeInfo != "" {
+		return f.EpisodeMinutes
+	}
+	return f.FilmMinutes
+}
+
+// Chain tries estimators in order, returning the first nonzero estimate
+type Chain []Estimator
+
+// Estimate returns the first nonzero estimate in the chain, or 0
+func (c Chain) Estimate(title, episodeInfo string) int {
+	for _, e := range c {
+		if minutes := e.Estimate(title, episodeInfo); minutes > 0 {
+			return minutes
+		}
+	}
+	return 0
+}
+
+// Strategy names, chosen per service in config or by the scraper
+const (
+	StrategyStandard = "standard" // Fixed episode and film lengths only
+	StrategyLiveTV   = "live_tv"  // Broadcast blocks: news, talk and sports
+	StrategySports   = "sports"   // Game and event lengths by league
+	StrategyKids     = "kids"     // Shorter episodes and films
+)
+
+// Provider defaults for sources that never have runtimes
+var (
+	// Netflix episodes average 30-45 minutes and films 90-120
+	Netflix = Fixed{EpisodeMinutes: 40, FilmMinutes: 105}
+
+	// YouTube TV history is mostly hour-long broadcast slots
+	YouTubeTV = Fixed{EpisodeMinutes: 45, FilmMinutes: 60}
+
+	// Plain YouTube videos watched through to the end average around 12 minutes
+	YouTube = Fixed{EpisodeMinutes: 12, FilmMinutes: 12}
+
+	// Vudu history is mostly purchased and rented films
+	Vudu = Fixed{EpisodeMinutes: 45, FilmMinutes: 110}
+
+	// Hulu is mostly TV, much of it half-hour network comedies
+	Hulu = Fixed{EpisodeMinutes: 35, FilmMinutes: 105}
+
+	// Disney+ series run 30-50 minutes and its films are mostly family length
+	DisneyPlus = Fixed{EpisodeMinutes: 40, FilmMinutes: 100}
+
+	// kids replaces a provider's lengths for kids profiles: episodes of
+	// preschool and kids series run 10-25 minutes and family films are short
+	kids = Fixed{EpisodeMinutes: 20, FilmMinutes: 85}
+)
+
+// Strategy estimates in two steps: content heuristics that only answer for
+// titles they recognize, then fixed episode and film lengths
+type Strategy struct {
+	Name      string
+	Heuristic Estimator // nil for StrategyStandard
+	Fallback  Fixed
+}
+
+// Estimate returns the heuristic's estimate if it has one, or the fallback
+func (s Strategy) Estimate(title, episodeInfo string) int {
+	if s.Heuristic != nil {
+		if minutes := s.Heuristic.Estimate(title, episodeInfo); minutes > 0 {
+			return minutes
+		}
+	}
+	return s.Fallback.Estimate(title, episodeInfo)
+}
+
+// New builds a named strategy over a provider's default lengths
+func New(name string, defaults Fixed) (Strategy, error) {
+	s := Strategy{Name: name, Fallback: defaults}
+	switch name {
+	case StrategyStandard, "":
+		s.Name = StrategyStandard
+	case StrategyLiveTV:
+		s.Heuristic = Chain{Sports, LiveTV}
+	case StrategySports:
+		s.Heuristic = Sports
+	case StrategyKids:
+		s.Fallback = kids
+	default:
+		return Strategy{}, fmt.Errorf("unknown duration estimator %q: must be standard, live_tv, sports or kids", name)
+	}
+	return s, nil
+}
+
+// liveTVPrograms are typical broadcast slot lengths in minutes for live TV
+// programming outside sports
+var liveTVPrograms = []struct {
+	pattern *regexp.Regexp
+	minutes int
+}{
+	{regexp.MustCompile(`\b(nightly news|world news|evening news|news ?hour|newsroom|news at \d+)\b`), 30},
+	{regexp.MustCompile(`\b(tonight show|late show|late night|daily show|jimmy kimmel|saturday night live|snl)\b`), 60},
+	{regexp.MustCompile(`\b(good morning america|today show|cbs mornings|morning joe|fox & friends)\b`), 120},
+	{regexp.MustCompile(`\b(news|live|breaking|special report|election|debate|press conference)\b`), 60},
+}
+
+// LiveTV estimates broadcast programming like news and talk shows, returning
+// 0 for titles that don't look like live TV
+var LiveTV Estimator = Func(func(title, episodeInfo string) int {
+	text := strings.ToLower(title + " " + episodeInfo)
+	for _, p := range liveTVPrograms {
+		if p.pattern.MatchString(text) {
+			return p.minutes
+		}
+	}
+	return 0
+})
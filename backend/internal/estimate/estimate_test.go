@@ -0,0 +1,47 @@
+package estimate
+
+import "testing"
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		strategy    string
+		title       string
+		episodeInfo string
+		expected    int
+	}{
+		{StrategyStandard, "Stranger Things", "S01E01", 40},
+		{StrategyStandard, "Bears vs. Packers", "", 105},
+		{StrategyLiveTV, "NBC Nightly News", "", 30},
+		{StrategyLiveTV, "The Tonight Show Starring Jimmy Fallon", "S12E40", 60},
+		{StrategyLiveTV, "Bears vs. Packers", "NFL", 195},
+		{StrategyLiveTV, "Some Movie", "", 105},
+		{StrategySports, "Rangers vs. Devils", "NHL", 150},
+		{StrategyKids, "Bluey", "S03E12", 20},
+		{StrategyKids, "Frozen", "", 85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+tt.title, func(t *testing.T) {
+			s, err := New(tt.strategy, Netflix)
+			if err != nil {
+				t.Fatalf("Failed to create strategy: %v", err)
+			}
+			if got := s.Estimate(tt.title, tt.episodeInfo); got != tt.expected {
+				t.Errorf("Expected %d minutes, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewUnknownStrategy(t *testing.T) {
+	if _, err := New("cartoons", Netflix); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain := Chain{Func(func(string, string) int { return 0 }), Fixed{EpisodeMinutes: 22}}
+	if got := chain.Estimate("Show", "S01E01"); got != 22 {
+		t.Errorf("Expected the first nonzero estimate, got %d", got)
+	}
+}
@@ -0,0 +1,49 @@
+package estimate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sportsDurations are typical event lengths in minutes, checked in order so
+// that short formats win over the league a clip belongs to
+var sportsDurations = []struct {
+	pattern *regexp.Regexp
+	minutes int
+}{
+	{regexp.MustCompile(`\b(highlights?|recap|top plays|best of)\b`), 10},
+	{regexp.MustCompile(`\bcondensed\b`), 45},
+	{regexp.MustCompile(`\b(sportscenter|first take|pardon the interruption|around the horn|daily wager)\b`), 60},
+	{regexp.MustCompile(`\b(nfl|college football|ncaaf|cfb|football)\b`), 195},
+	{regexp.MustCompile(`\b(mlb|baseball)\b`), 180},
+	{regexp.MustCompile(`\b(nba|wnba|college basketball|ncaab|basketball)\b`), 150},
+	{regexp.MustCompile(`\b(nhl|hockey)\b`), 150},
+	{regexp.MustCompile(`\b(mls|premier league|la ?liga|bundesliga|serie a|soccer|fc)\b`), 120},
+	{regexp.MustCompile(`\b(ufc|boxing|pfl)\b`), 240},
+	{regexp.MustCompile(`\b(golf|pga|lpga|masters)\b`), 240},
+	{regexp.MustCompile(`\b(tennis|atp|wta|us open|australian open|french open|wimbledon)\b`), 150},
+	{regexp.MustCompile(`\b(f1|formula 1|nascar|indycar|grand prix)\b`), 120},
+}
+
+// matchupPattern matches event titles like "Bears vs. Packers" or "Duke at UNC"
+var matchupPattern = regexp.MustCompile(`\b(vs\.?|v\.?|at|@)\s+\S`)
+
+// Sports guesses how long a sports title runs from keywords in its title and
+// subtitle, returning 0 for titles that don't look like sports (such as
+// documentaries) so they can be looked up instead
+var Sports Estimator = Func(func(title, episodeInfo string) int {
+	text := strings.ToLower(title + " " + episodeInfo)
+
+	for _, d := range sportsDurations {
+		if d.pattern.MatchString(text) {
+			return d.minutes
+		}
+	}
+
+	// An unrecognized matchup is still most likely a full game
+	if matchupPattern.MatchString(text) {
+		return 180
+	}
+
+	return 0
+})
@@ -0,0 +1,28 @@
+package estimate
+
+import "testing"
+
+func TestSports(t *testing.T) {
+	tests := []struct {
+		title       string
+		episodeInfo string
+		expected    int
+	}{
+		{"Bears vs. Packers", "NFL Week 12", 195},
+		{"NFL Highlights: Bears vs. Packers", "", 10},
+		{"Yankees at Red Sox", "MLB", 180},
+		{"Celtics vs. Lakers", "Condensed Game", 45},
+		{"UFC 300: Pereira vs. Hill", "", 240},
+		{"SportsCenter", "", 60},
+		{"Duke at North Carolina", "", 180},
+		{"30 for 30: The Two Escobars", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := Sports.Estimate(tt.title, tt.episodeInfo); got != tt.expected {
+				t.Errorf("Expected %d minutes, got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// SummaryPath is where instances serve their summary to peers
+const SummaryPath = "/api/federation/summary"
+
+// Summary is what an instance shares with its peers: watch time per service
+// for a period, never individual history entries
+type Summary struct {
+	Name         string           `json:"name"`
+	TotalMinutes int              `json:"total_minutes"`
+	Services     []ServiceSummary `json:"services"`
+}
+
+// ServiceSummary is one service's watch time in a summary
+type ServiceSummary struct {
+	ServiceName  string `json:"service_name"`
+	Color        string `json:"color"`
+	TotalMinutes int    `json:"total_minutes"`
+	TotalShows   int    `json:"total_shows"`
+}
+
+// Member is one instance's part of a household view. Error is set instead of
+// the summary when a peer couldn't be reached.
+type Member struct {
+	Summary
+	Error string `json:"error,omitempty"`
+}
+
+// Household combines the summaries of every instance in a household
+type Household struct {
+	TotalMinutes int              `json:"total_minutes"`
+	Members      []Member         `json:"members"`
+	Services     []ServiceSummary `json:"services"` // Summed by service name across members
+}
+
+// NewSummary builds an instance's summary from its service stats, leaving out
+// services with no watch time
+func NewSummary(name string, stats []database.ServiceStats) Summary {
+	summary := Summary{Name: name, Services: []ServiceSummary{}}
+	for _, stat := range stats {
+		if stat.TotalMinutes == 0 {
+			continue
+		}
+		summary.TotalMinutes += stat.TotalMinutes
+		summary.Services = append(summary.Services, ServiceSummary{
+			ServiceName:  stat.ServiceName,
+			Color:        stat.Color,
+			TotalMinutes: stat.TotalMinutes,
+			TotalShows:   stat.TotalShows,
+		})
+	}
+	return summary
+}
+
+// Client pulls summaries from peer instances
+type Client struct {
+	peers      []config.PeerConfig
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the configured peers
+func NewClient(peers []config.PeerConfig) *Client {
+	return &Client{
+		peers:      peers,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// FetchSummary requests a peer's summary for the period selected by query
+// (the same year and month parameters as /api/services), so each peer
+// resolves the period in its own time zone
+func (c *Client) FetchSummary(ctx context.Context, peer config.PeerConfig, query url.Values) (*Summary, error) {
+	endpoint := strings.TrimSuffix(peer.URL, "/") + SummaryPath
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if peer.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+peer.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("peer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+
+	var summary Summary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode peer summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// Household fetches every peer's summary in parallel and combines them with
+// the local one. Unreachable peers are listed with an error and left out of
+// the totals.
+func (c *Client) Household(ctx context.Context, local Summary, query url.Values) *Household {
+	members := make([]Member, len(c.peers)+1)
+	members[0] = Member{Summary: local}
+
+	var wg sync.WaitGroup
+	for i, peer := range c.peers {
+		wg.Add(1)
+		go func(i int, peer config.PeerConfig) {
+			defer wg.Done()
+			summary, err := c.FetchSummary(ctx, peer, query)
+			if err != nil {
+				members[i+1] = Member{Summary: Summary{Name: peer.Name, Services: []ServiceSummary{}}, Error: err.Error()}
+				return
+			}
+			// Label peers as configured here, not by their own name for themselves
+			summary.Name = peer.Name
+			members[i+1] = Member{Summary: *summary}
+		}(i, peer)
+	}
+	wg.Wait()
+
+	return Combine(members)
+}
+
+// Combine totals members' summaries, summing each service across members
+func Combine(members []Member) *Household {
+	household := &Household{Members: members, Services: []ServiceSummary{}}
+	byName := make(map[string]int)
+	for _, member := range members {
+		household.TotalMinutes += member.TotalMinutes
+		for _, svc := range member.Services {
+			i, ok := byName[svc.ServiceName]
+			if !ok {
+				byName[svc.ServiceName] = len(household.Services)
+				household.Services = append(household.Services, svc)
+				continue
+			}
+			household.Services[i].TotalMinutes += svc.TotalMinutes
+			household.Services[i].TotalShows += svc.TotalShows
+		}
+	}
+
+	sort.SliceStable(household.Services, func(i, j int) bool {
+		return household.Services[i].TotalMinutes > household.Services[j].TotalMinutes
+	})
+	return household
+}
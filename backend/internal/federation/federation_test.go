@@ -0,0 +1,44 @@
+package federation
+
+import (
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestNewSummarySkipsUnwatchedServices(t *testing.T) {
+	summary := NewSummary("me", []database.ServiceStats{
+		{ServiceName: "Netflix", TotalMinutes: 120, TotalShows: 3},
+		{ServiceName: "Hulu"},
+	})
+
+	if summary.TotalMinutes != 120 {
+		t.Errorf("Expected 120 minutes, got %d", summary.TotalMinutes)
+	}
+	if len(summary.Services) != 1 || summary.Services[0].ServiceName != "Netflix" {
+		t.Errorf("Expected only Netflix, got %+v", summary.Services)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	household := Combine([]Member{
+		{Summary: Summary{Name: "me", TotalMinutes: 90, Services: []ServiceSummary{
+			{ServiceName: "Netflix", TotalMinutes: 60, TotalShows: 2},
+			{ServiceName: "Hulu", TotalMinutes: 30, TotalShows: 1},
+		}}},
+		{Summary: Summary{Name: "Alex", TotalMinutes: 45, Services: []ServiceSummary{
+			{ServiceName: "Hulu", TotalMinutes: 45, TotalShows: 1},
+		}}},
+		{Summary: Summary{Name: "Sam"}, Error: "peer returned status 401"},
+	})
+
+	if household.TotalMinutes != 135 {
+		t.Errorf("Expected 135 minutes, got %d", household.TotalMinutes)
+	}
+	if len(household.Services) != 2 {
+		t.Fatalf("Expected 2 services, got %d", len(household.Services))
+	}
+	if household.Services[0].ServiceName != "Hulu" || household.Services[0].TotalMinutes != 75 || household.Services[0].TotalShows != 2 {
+		t.Errorf("Expected Hulu first with 75 minutes over 2 shows, got %+v", household.Services[0])
+	}
+}
@@ -0,0 +1,75 @@
+package gaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultSteamBaseURL is the Steam Web API
+const defaultSteamBaseURL = "https://api.steampowered.com"
+
+// SteamClient reads playtime from the Steam Web API
+type SteamClient struct {
+	apiKey     string
+	steamID    string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// SteamGame is an owned game with its lifetime playtime
+type SteamGame struct {
+	AppID           int64  `json:"appid"`
+	Name            string `json:"name"`
+	PlaytimeForever int    `json:"playtime_forever"` // Minutes
+}
+
+// NewSteamClient creates a Steam client for one account (64-bit Steam ID)
+func NewSteamClient(apiKey, steamID string) *SteamClient {
+	return &SteamClient{
+		apiKey:     apiKey,
+		steamID:    steamID,
+		baseURL:    defaultSteamBaseURL,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// OwnedGames returns every owned game with its lifetime playtime. The profile's
+// game details must be public for the API to return them.
+func (c *SteamClient) OwnedGames(ctx context.Context) ([]SteamGame, error) {
+	params := url.Values{}
+	params.Set("key", c.apiKey)
+	params.Set("steamid", c.steamID)
+	params.Set("include_appinfo", "1")
+	params.Set("include_played_free_games", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/IPlayerService/GetOwnedGames/v1/?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("steam request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("steam request returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Response struct {
+			Games []SteamGame `json:"games"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode steam response: %w", err)
+	}
+
+	return result.Response.Games, nil
+}
@@ -0,0 +1,60 @@
+package gaming
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// SyncResult reports the outcome of a Steam sync
+type SyncResult struct {
+	GamesChecked int `json:"games_checked"`
+	NewGames     int `json:"new_games"` // Seen for the first time; only their baseline was recorded
+	Sessions     int `json:"sessions"`
+	Minutes      int `json:"minutes"`
+}
+
+// SyncSteam records playtime added since the last sync as a session dated at
+// the sync time. Steam only reports lifetime totals, so the first sync just
+// records a baseline, and daily syncs give per-day playtime.
+func SyncSteam(ctx context.Context, db *database.DB, client *SteamClient, now time.Time) (*SyncResult, error) {
+	games, err := client.OwnedGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{GamesChecked: len(games)}
+	for _, game := range games {
+		previous, seen, err := db.GetSteamPlaytime(game.AppID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read playtime for %s: %w", game.Name, err)
+		}
+
+		if !seen {
+			result.NewGames++
+		} else if delta := game.PlaytimeForever - previous; delta > 0 {
+			err := db.InsertGamingSession(&database.GamingSession{
+				Platform: "steam",
+				Game:     game.Name,
+				Minutes:  delta,
+				PlayedAt: now,
+				Source:   "steam",
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to record session for %s: %w", game.Name, err)
+			}
+			result.Sessions++
+			result.Minutes += delta
+		}
+
+		if !seen || game.PlaytimeForever != previous {
+			if err := db.SetSteamPlaytime(game.AppID, game.Name, game.PlaytimeForever); err != nil {
+				return nil, fmt.Errorf("failed to save playtime for %s: %w", game.Name, err)
+			}
+		}
+	}
+
+	return result, nil
+}
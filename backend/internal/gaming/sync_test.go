@@ -0,0 +1,77 @@
+package gaming
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestSyncSteam(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	// Lifetime playtime grows by 90 minutes between the two syncs
+	playtime := 600
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "test-key" || r.URL.Query().Get("steamid") != "7656" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"response": {"game_count": 2, "games": [
+			{"appid": 620, "name": "Portal 2", "playtime_forever": %d},
+			{"appid": 440, "name": "Team Fortress 2", "playtime_forever": 30}
+		]}}`, playtime)
+	}))
+	defer server.Close()
+
+	client := NewSteamClient("test-key", "7656")
+	client.baseURL = server.URL
+
+	now := time.Now()
+	first, err := SyncSteam(context.Background(), db, client, now)
+	if err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+	if first.NewGames != 2 || first.Sessions != 0 {
+		t.Errorf("Expected first sync to only record baselines, got %+v", first)
+	}
+
+	playtime = 690
+	second, err := SyncSteam(context.Background(), db, client, now)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if second.Sessions != 1 || second.Minutes != 90 {
+		t.Errorf("Expected one 90 minute session, got %+v", second)
+	}
+
+	sessions, _ := db.GetGamingSessions(now.Add(-time.Hour), now.Add(time.Hour))
+	if len(sessions) != 1 || sessions[0].Game != "Portal 2" || sessions[0].Platform != "steam" {
+		t.Errorf("Unexpected sessions: %+v", sessions)
+	}
+}
+
+func TestSyncSteamError(t *testing.T) {
+	db, _ := database.New(":memory:")
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewSteamClient("bad-key", "7656")
+	client.baseURL = server.URL
+
+	if _, err := SyncSteam(context.Background(), db, client, time.Now()); err == nil {
+		t.Error("Expected error when Steam rejects the request")
+	}
+}
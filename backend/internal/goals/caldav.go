@@ -0,0 +1,181 @@
+package goals
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CalendarDay is an all-day (or timed) calendar event mapped to a date
+type CalendarDay struct {
+	Date    string // YYYY-MM-DD
+	Summary string
+}
+
+// CalDAVClient fetches events from a CalDAV calendar collection
+type CalDAVClient struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewCalDAVClient creates a client for a calendar collection URL
+func NewCalDAVClient(url, username, password string) *CalDAVClient {
+	return &CalDAVClient{
+		url:        url,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// calendarQuery asks for VEVENTs overlapping a time range
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%s" end="%s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+// multistatus is the subset of a CalDAV REPORT response we read
+type multistatus struct {
+	Responses []struct {
+		CalendarData string `xml:"propstat>prop>calendar-data"`
+	} `xml:"response"`
+}
+
+// FetchDays returns the days in [start, end) covered by events whose summary
+// contains match (case-insensitive)
+func (c *CalDAVClient) FetchDays(ctx context.Context, start, end time.Time, match string) ([]CalendarDay, error) {
+	body := fmt.Sprintf(calendarQuery, start.UTC().Format("20060102T150405Z"), end.UTC().Format("20060102T150405Z"))
+	req, err := http.NewRequestWithContext(ctx, "REPORT", c.url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calendar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var ms multistatus
+	if err := xml.Unmarshal(data, &ms); err != nil {
+		return nil, fmt.Errorf("failed to parse calendar response: %w", err)
+	}
+
+	var days []CalendarDay
+	for _, r := range ms.Responses {
+		for _, day := range ParseICSDays(r.CalendarData, match) {
+			if day.Date >= start.Format("2006-01-02") && day.Date < end.Format("2006-01-02") {
+				days = append(days, day)
+			}
+		}
+	}
+	return days, nil
+}
+
+// ParseICSDays extracts the days covered by VEVENTs in iCalendar data whose
+// summary contains match. Multi-day all-day events cover every day up to
+// their (exclusive) DTEND.
+func ParseICSDays(ics, match string) []CalendarDay {
+	match = strings.ToLower(match)
+	var days []CalendarDay
+	var inEvent bool
+	var summary string
+	var start, end time.Time
+
+	for _, line := range unfoldICS(ics) {
+		name, value := splitICSLine(line)
+		switch {
+		case line == "BEGIN:VEVENT":
+			inEvent, summary, start, end = true, "", time.Time{}, time.Time{}
+		case line == "END:VEVENT":
+			inEvent = false
+			if start.IsZero() || !strings.Contains(strings.ToLower(summary), match) {
+				continue
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				days = append(days, CalendarDay{Date: day.Format("2006-01-02"), Summary: summary})
+			}
+		case !inEvent:
+			continue
+		case name == "SUMMARY":
+			summary = value
+		case name == "DTSTART":
+			start = parseICSDate(value)
+		case name == "DTEND":
+			end = parseICSDate(value)
+		}
+	}
+
+	return days
+}
+
+// unfoldICS splits iCalendar data into logical lines, joining folded lines
+func unfoldICS(ics string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewBufferString(ics))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitICSLine returns a content line's property name (without parameters) and value
+func splitICSLine(line string) (string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return line, ""
+	}
+	name := line[:colon]
+	if semi := strings.Index(name, ";"); semi >= 0 {
+		name = name[:semi]
+	}
+	return strings.ToUpper(name), line[colon+1:]
+}
+
+// parseICSDate reads the date part of a DATE or DATE-TIME value
+func parseICSDate(value string) time.Time {
+	if len(value) < 8 {
+		return time.Time{}
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,76 @@
+package goals
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Screen-free weekend\r\n" +
+	"DTSTART;VALUE=DATE:20250315\r\n" +
+	"DTEND;VALUE=DATE:20250317\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Dentist\r\n" +
+	"DTSTART:20250318T140000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Family day (screen\r\n" +
+	" -free)\r\n" +
+	"DTSTART;TZID=America/New_York:20250322T090000\r\n" +
+	"DTEND;TZID=America/New_York:20250322T210000\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICSDays(t *testing.T) {
+	days := ParseICSDays(testICS, "screen-free")
+
+	var dates []string
+	for _, d := range days {
+		dates = append(dates, d.Date)
+	}
+	expected := "2025-03-15,2025-03-16,2025-03-22"
+	if got := strings.Join(dates, ","); got != expected {
+		t.Errorf("Expected days %s, got %s", expected, got)
+	}
+	if days[2].Summary != "Family day (screen-free)" {
+		t.Errorf("Expected folded summary to be unfolded, got '%s'", days[2].Summary)
+	}
+}
+
+func TestFetchDays(t *testing.T) {
+	var gotMethod, gotUser string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotUser, _, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(`<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:response>
+    <d:href>/cal/1.ics</d:href>
+    <d:propstat><d:prop><c:calendar-data>` + testICS + `</c:calendar-data></d:prop></d:propstat>
+  </d:response>
+</d:multistatus>`))
+	}))
+	defer server.Close()
+
+	client := NewCalDAVClient(server.URL, "me", "secret")
+	start := time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)
+	days, err := client.FetchDays(context.Background(), start, start.AddDate(0, 0, 30), "screen-free")
+	if err != nil {
+		t.Fatalf("FetchDays failed: %v", err)
+	}
+
+	if gotMethod != "REPORT" || gotUser != "me" {
+		t.Errorf("Expected authenticated REPORT request, got %s as '%s'", gotMethod, gotUser)
+	}
+	// 2025-03-15 falls before the requested range
+	if len(days) != 2 || days[0].Date != "2025-03-16" {
+		t.Errorf("Unexpected days: %+v", days)
+	}
+}
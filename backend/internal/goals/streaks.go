@@ -0,0 +1,103 @@
+package goals
+
+import (
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// Streak is a run of consecutive days under the screen time threshold
+type Streak struct {
+	Days  int    `json:"days"`
+	Start string `json:"start,omitempty"` // YYYY-MM-DD
+	End   string `json:"end,omitempty"`   // YYYY-MM-DD, inclusive
+}
+
+// StreakStats reports the current and longest runs of light screen time days
+type StreakStats struct {
+	ThresholdMinutes int    `json:"threshold_minutes"`
+	Current          Streak `json:"current"`
+	Longest          Streak `json:"longest"`
+}
+
+// Streaks finds runs of days in [start, end) with less than threshold minutes
+// of screen time. The current streak is the run ending on the last day.
+func Streaks(daily map[string]int, start, end time.Time, threshold int) StreakStats {
+	stats := StreakStats{ThresholdMinutes: threshold}
+
+	var run Streak
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		if daily[key] >= threshold {
+			run = Streak{}
+			continue
+		}
+
+		if run.Days == 0 {
+			run.Start = key
+		}
+		run.Days++
+		run.End = key
+		if run.Days > stats.Longest.Days {
+			stats.Longest = run
+		}
+	}
+	stats.Current = run
+
+	return stats
+}
+
+// Screen-free day statuses
+const (
+	StatusKept     = "kept"
+	StatusMissed   = "missed"
+	StatusUpcoming = "upcoming"
+)
+
+// ScreenFreeResult is how a planned screen-free day went
+type ScreenFreeResult struct {
+	Date    string `json:"date"`
+	Note    string `json:"note"`
+	Minutes int    `json:"minutes"`
+	Status  string `json:"status"`
+}
+
+// Adherence summarizes how many planned screen-free days were kept
+type Adherence struct {
+	Planned  int                `json:"planned"`
+	Kept     int                `json:"kept"`
+	Missed   int                `json:"missed"`
+	Upcoming int                `json:"upcoming"`
+	Rate     float64            `json:"rate"` // Kept / (kept + missed), 0 when nothing has been evaluated
+	Days     []ScreenFreeResult `json:"days"`
+}
+
+// EvaluateScreenFree checks each planned day against its screen time. A day is
+// kept when it has at most maxMinutes; today and later days are still upcoming.
+func EvaluateScreenFree(days []database.ScreenFreeDay, daily map[string]int, today time.Time, maxMinutes int) Adherence {
+	todayKey := today.Format("2006-01-02")
+	adherence := Adherence{Days: []ScreenFreeResult{}}
+
+	for _, day := range days {
+		result := ScreenFreeResult{Date: day.Date, Note: day.Note, Minutes: daily[day.Date]}
+		switch {
+		case day.Date >= todayKey:
+			result.Status = StatusUpcoming
+			adherence.Upcoming++
+		case result.Minutes <= maxMinutes:
+			result.Status = StatusKept
+			adherence.Kept++
+		default:
+			result.Status = StatusMissed
+			adherence.Missed++
+		}
+		adherence.Planned++
+		adherence.Days = append(adherence.Days, result)
+	}
+
+	if evaluated := adherence.Kept + adherence.Missed; evaluated > 0 {
+		adherence.Rate = float64(adherence.Kept) / float64(evaluated)
+	}
+
+	return adherence
+}
@@ -0,0 +1,64 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestStreaks(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	daily := map[string]int{
+		"2025-03-01": 30,
+		"2025-03-02": 0,
+		"2025-03-03": 45,
+		"2025-03-04": 120, // Breaks the streak
+		"2025-03-05": 20,
+		"2025-03-06": 10,
+	}
+
+	stats := Streaks(daily, start, start.AddDate(0, 0, 6), 60)
+
+	if stats.Longest.Days != 3 || stats.Longest.Start != "2025-03-01" || stats.Longest.End != "2025-03-03" {
+		t.Errorf("Unexpected longest streak: %+v", stats.Longest)
+	}
+	if stats.Current.Days != 2 || stats.Current.Start != "2025-03-05" {
+		t.Errorf("Unexpected current streak: %+v", stats.Current)
+	}
+}
+
+func TestStreaksEndingOverThreshold(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	daily := map[string]int{"2025-03-02": 90}
+
+	stats := Streaks(daily, start, start.AddDate(0, 0, 2), 60)
+	if stats.Current.Days != 0 {
+		t.Errorf("Expected no current streak, got %+v", stats.Current)
+	}
+	if stats.Longest.Days != 1 {
+		t.Errorf("Expected longest streak of 1 day, got %+v", stats.Longest)
+	}
+}
+
+func TestEvaluateScreenFree(t *testing.T) {
+	days := []database.ScreenFreeDay{
+		{Date: "2025-03-01", Note: "Hiking"},
+		{Date: "2025-03-08"},
+		{Date: "2025-03-20"},
+	}
+	daily := map[string]int{"2025-03-08": 95}
+	today := time.Date(2025, 3, 19, 12, 0, 0, 0, time.UTC)
+
+	adherence := EvaluateScreenFree(days, daily, today, 0)
+
+	if adherence.Planned != 3 || adherence.Kept != 1 || adherence.Missed != 1 || adherence.Upcoming != 1 {
+		t.Errorf("Unexpected adherence counts: %+v", adherence)
+	}
+	if adherence.Rate != 0.5 {
+		t.Errorf("Expected rate 0.5, got %v", adherence.Rate)
+	}
+	if adherence.Days[1].Status != StatusMissed || adherence.Days[1].Minutes != 95 {
+		t.Errorf("Expected missed day with 95 minutes, got %+v", adherence.Days[1])
+	}
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// ParseAudibleLibrary parses an Audible library export (as written by
+// audible-cli's "library export", TSV or CSV). Listening time is the title's
+// runtime scaled by how much of it was finished, and titles that were never
+// started are skipped. The export has no listening dates, so the date the
+// title was added to the library is used.
+func ParseAudibleLibrary(r io.Reader) (*ParseResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	// audible-cli writes TSV by default
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Contains(firstLine, []byte("\t")) {
+		reader.Comma = '\t'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "runtime_length_min", "date_added"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("header must contain a %s column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	result := &ParseResult{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		title := field(record, "title")
+		if title == "" {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: "empty title"})
+			continue
+		}
+
+		minutes, err := audibleListenedMinutes(field(record, "runtime_length_min"), field(record, "percent_complete"), field(record, "is_finished"))
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+		if minutes == 0 {
+			continue
+		}
+
+		addedAt, err := parseAudibleDate(field(record, "date_added"))
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		// "Fiction, Fantasy" -> "Fiction"
+		genre, _, _ := strings.Cut(field(record, "genres"), ",")
+
+		result.Records = append(result.Records, Record{
+			Line: line,
+			Item: database.WatchHistory{
+				Title:           title,
+				DurationMinutes: minutes,
+				WatchedAt:       addedAt,
+				Genre:           strings.TrimSpace(genre),
+				MediaKind:       database.MediaKindAudio,
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// audibleListenedMinutes returns how many minutes of a title were listened to
+func audibleListenedMinutes(runtime, percentComplete, isFinished string) (int, error) {
+	total, err := strconv.Atoi(runtime)
+	if err != nil {
+		return 0, fmt.Errorf("invalid runtime: %q", runtime)
+	}
+
+	if strings.EqualFold(isFinished, "true") {
+		return total, nil
+	}
+
+	if percentComplete == "" {
+		return 0, nil
+	}
+	percent, err := strconv.ParseFloat(percentComplete, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percent_complete: %q", percentComplete)
+	}
+
+	return int(math.Round(float64(total) * math.Min(percent, 100) / 100)), nil
+}
+
+// parseAudibleDate parses the dates used in Audible library exports
+func parseAudibleDate(dateStr string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05.000Z",
+		"2006-01-02",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, dateStr); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
@@ -0,0 +1,64 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestParseAudibleLibrary(t *testing.T) {
+	tsv := "asin\ttitle\truntime_length_min\tis_finished\tpercent_complete\tgenres\tdate_added\n" +
+		"B001\tProject Hail Mary\t970\tTrue\t100\tScience Fiction, Space Opera\t2024-03-01T10:00:00Z\n" +
+		"B002\tDune\t1263\tFalse\t50\tScience Fiction\t2024-04-02\n" +
+		"B003\tNever Started\t600\tFalse\t0\tHistory\t2024-05-03\n" +
+		"B004\tBroken\tabc\tFalse\t10\t\t2024-05-04\n"
+
+	result, err := ParseAudibleLibrary(strings.NewReader(tsv))
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 5 {
+		t.Errorf("Expected 1 error on line 5, got %+v", result.Errors)
+	}
+
+	finished := result.Records[0].Item
+	if finished.DurationMinutes != 970 || finished.Genre != "Science Fiction" {
+		t.Errorf("Unexpected finished title: %+v", finished)
+	}
+	if finished.MediaKind != database.MediaKindAudio {
+		t.Errorf("Expected media kind audio, got '%s'", finished.MediaKind)
+	}
+
+	partial := result.Records[1].Item
+	if partial.DurationMinutes != 632 {
+		t.Errorf("Expected half of 1263 minutes (632), got %d", partial.DurationMinutes)
+	}
+	if partial.WatchedAt.Format("2006-01-02") != "2024-04-02" {
+		t.Errorf("Expected date 2024-04-02, got %s", partial.WatchedAt.Format("2006-01-02"))
+	}
+}
+
+func TestParseAudibleLibraryCSV(t *testing.T) {
+	csvData := `title,runtime_length_min,is_finished,date_added
+"Born a Crime",527,true,2023-11-20
+`
+
+	result, err := ParseAudibleLibrary(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV export: %v", err)
+	}
+	if len(result.Records) != 1 || result.Records[0].Item.DurationMinutes != 527 {
+		t.Errorf("Expected one 527 minute record, got %+v", result.Records)
+	}
+}
+
+func TestParseAudibleLibraryMissingColumns(t *testing.T) {
+	if _, err := ParseAudibleLibrary(strings.NewReader("Title,Date\nFoo,1/1/25\n")); err == nil {
+		t.Error("Expected error for a file that isn't an Audible export")
+	}
+}
@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// DeviceUsageTitle is the title of entries estimated from app foreground
+// time reported by smart TVs and streaming sticks
+const DeviceUsageTitle = "Device Usage"
+
+// devicePackages maps Android TV and Fire TV app package names to services
+var devicePackages = map[string]string{
+	"com.netflix.ninja":                      "Netflix",
+	"com.netflix.mediaclient":                "Netflix",
+	"com.google.android.youtube.tvunplugged": "YouTube TV",
+	"com.amazon.avod":                        "Amazon Video",
+	"com.amazon.amazonvideo.livingroom":      "Amazon Video",
+	"com.hbo.hbonow":                         "HBO Max",
+	"com.wbd.stream":                         "HBO Max",
+	"com.apple.atve.androidtv.appletv":       "Apple TV",
+	"com.apple.atve.amazon.appletv":          "Apple TV",
+	"com.peacocktv.peacockandroid":           "Peacock",
+	"air.com.vudu.air.downloadertablet":      "Vudu",
+	"com.hulu.livingroomplus":                "Hulu",
+	"com.hulu.plus":                          "Hulu",
+	"com.disney.disneyplus":                  "Disney+",
+	"com.kanopy":                             "Kanopy",
+	"com.hoopladigital.android":              "Hoopla",
+	"com.mubi":                               "MUBI",
+	"com.criterionchannel":                   "Criterion Channel",
+	"com.espn.gtv":                           "ESPN+",
+	"com.audible.application":                "Audible",
+}
+
+// DeviceEvent is one stretch of time an app was in the foreground. The
+// duration comes from ended_at, or seconds when the script only counts time.
+type DeviceEvent struct {
+	Device    string    `json:"device"` // Overrides the batch's device
+	Package   string    `json:"package"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Seconds   int       `json:"seconds"`
+}
+
+// DeviceBatch is what a companion script posts: foreground events from one device
+type DeviceBatch struct {
+	Device string        `json:"device"`
+	Events []DeviceEvent `json:"events"`
+}
+
+// ParseDeviceEvents decodes a batch of foreground events
+func ParseDeviceEvents(r io.Reader) (*DeviceBatch, error) {
+	var batch DeviceBatch
+	if err := json.NewDecoder(r).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("failed to decode device events: %w", err)
+	}
+	return &batch, nil
+}
+
+// DevicePackageService returns the service an app package's time is counted
+// under, with configured mappings taking precedence over the built-in ones
+func DevicePackageService(pkg string, custom map[string]string) (string, bool) {
+	if service, ok := custom[pkg]; ok {
+		return service, true
+	}
+	service, ok := devicePackages[strings.ToLower(pkg)]
+	return service, ok
+}
+
+// ApplyDeviceEvents stores each foreground event as an estimated session
+// under its app's service, at the time the app was opened. Events under a
+// minute are dropped, and invalid events are reported by their position in
+// the batch.
+func ApplyDeviceEvents(db *database.DB, batch *DeviceBatch, packages map[string]string, opts Options) (*UsageSummary, error) {
+	var entries []usageEntry
+	var errs []RowError
+	for i, event := range batch.Events {
+		line := i + 1
+		minutes, err := event.minutes()
+		if err != nil {
+			errs = append(errs, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		device := event.Device
+		if device == "" {
+			device = batch.Device
+		}
+		entries = append(entries, usageEntry{
+			Line:    line,
+			App:     strings.TrimSpace(event.Package),
+			Device:  device,
+			At:      event.StartedAt,
+			Minutes: minutes,
+		})
+	}
+
+	summary, err := applyUsage(db, entries, DeviceUsageTitle, func(pkg string) (string, bool) {
+		return DevicePackageService(pkg, packages)
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if errs != nil {
+		summary.Errors = errs
+	}
+	return summary, nil
+}
+
+// minutes returns how long the app was in the foreground, to the nearest minute
+func (e DeviceEvent) minutes() (int, error) {
+	if strings.TrimSpace(e.Package) == "" {
+		return 0, fmt.Errorf("missing package")
+	}
+	if e.StartedAt.IsZero() {
+		return 0, fmt.Errorf("missing started_at")
+	}
+
+	seconds := float64(e.Seconds)
+	if !e.EndedAt.IsZero() {
+		seconds = e.EndedAt.Sub(e.StartedAt).Seconds()
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("event ends before it starts")
+	}
+	return int(math.Round(seconds / 60)), nil
+}
@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyDeviceEvents(t *testing.T) {
+	db, _ := setupTestDB(t)
+	defer db.Close()
+
+	body := `{"device": "Living Room Shield", "events": [
+		{"package": "com.hulu.livingroomplus", "started_at": "2025-01-14T20:00:00Z", "ended_at": "2025-01-14T21:10:20Z"},
+		{"package": "com.plexapp.android", "started_at": "2025-01-14T21:15:00Z", "seconds": 1800, "device": "Bedroom Fire TV"},
+		{"package": "com.netflix.ninja", "started_at": "2025-01-14T22:00:00Z", "seconds": 20},
+		{"package": "com.disney.disneyplus", "seconds": 600}
+	]}`
+	batch, err := ParseDeviceEvents(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to parse events: %v", err)
+	}
+
+	summary, err := ApplyDeviceEvents(db, batch, map[string]string{"com.plexapp.android": "Kanopy"}, Options{PreviewRows: 10})
+	if err != nil {
+		t.Fatalf("Failed to apply events: %v", err)
+	}
+	if summary.Imported != 2 {
+		t.Errorf("Expected 2 sessions, got %+v", summary)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Line != 4 {
+		t.Errorf("Expected an error for the event without started_at, got %+v", summary.Errors)
+	}
+
+	hulu, _ := db.GetServiceByName("Hulu")
+	start := time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)
+	history, _ := db.GetWatchHistory(hulu.ID, start, start.AddDate(0, 0, 1), 10, 0)
+	if len(history) != 1 || history[0].Title != DeviceUsageTitle || history[0].DurationMinutes != 70 || history[0].Device != "Living Room Shield" {
+		t.Errorf("Expected a 70 minute Hulu session on the Shield, got %+v", history)
+	}
+
+	kanopy, _ := db.GetServiceByName("Kanopy")
+	history, _ = db.GetWatchHistory(kanopy.ID, start, start.AddDate(0, 0, 1), 10, 0)
+	if len(history) != 1 || history[0].Device != "Bedroom Fire TV" {
+		t.Errorf("Expected the configured package mapped to Kanopy on its own device, got %+v", history)
+	}
+}
@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// ColumnMapping names the header of the column each field of a generic CSV
+// export is read from. Headers match ignoring case.
+type ColumnMapping struct {
+	Title    string
+	Date     string
+	Duration string // Minutes, or "h:mm" / "h:mm:ss"
+	Episode  string // Optional
+	Service  string // Optional; rows naming a service are imported into it
+}
+
+// DefaultMapping is the plain "title,date,minutes" layout of the generic format
+var DefaultMapping = ColumnMapping{
+	Title:    "title",
+	Date:     "date",
+	Duration: "minutes",
+	Episode:  "episode",
+	Service:  "service",
+}
+
+// Merge returns m with its empty fields taken from fallback
+func (m ColumnMapping) Merge(fallback ColumnMapping) ColumnMapping {
+	pick := func(col, def string) string {
+		if col = strings.ToLower(strings.TrimSpace(col)); col != "" {
+			return col
+		}
+		return strings.ToLower(def)
+	}
+	return ColumnMapping{
+		Title:    pick(m.Title, fallback.Title),
+		Date:     pick(m.Date, fallback.Date),
+		Duration: pick(m.Duration, fallback.Duration),
+		Episode:  pick(m.Episode, fallback.Episode),
+		Service:  pick(m.Service, fallback.Service),
+	}
+}
+
+// ParseGenericCSV parses a plain "title,date,minutes" CSV, the lowest common
+// denominator for spreadsheets and trackers without a dedicated importer. An
+// optional episode column is kept as episode info, and rows with no minutes
+// are estimated.
+func ParseGenericCSV(r io.Reader) (*ParseResult, error) {
+	return ParseMappedCSV(r, DefaultMapping)
+}
+
+// MappedCSV returns a parser for CSV exports laid out as the mapping says
+func MappedCSV(mapping ColumnMapping) Parser {
+	return func(r io.Reader) (*ParseResult, error) {
+		return ParseMappedCSV(r, mapping)
+	}
+}
+
+// ParseMappedCSV parses a CSV export whose columns are named by the mapping,
+// such as a JustWatch or spreadsheet export, reading unmapped fields from the
+// default columns. The title and date columns are required; rows without a
+// duration are estimated.
+func ParseMappedCSV(r io.Reader, mapping ColumnMapping) (*ParseResult, error) {
+	mapping = mapping.Merge(DefaultMapping)
+	table, err := newCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, required := range []string{mapping.Title, mapping.Date} {
+		if table.column(required) == "" {
+			return nil, fmt.Errorf("CSV header must contain a %s column", required)
+		}
+	}
+
+	return table.each(func(record []string) (*Record, error) {
+		title := table.field(record, mapping.Title)
+		if title == "" {
+			return nil, fmt.Errorf("empty title")
+		}
+
+		watchedAt, err := parseTrackerDate(table.field(record, mapping.Date))
+		if err != nil {
+			return nil, err
+		}
+
+		item := database.WatchHistory{
+			Title:       title,
+			EpisodeInfo: table.field(record, mapping.Episode),
+			WatchedAt:   watchedAt,
+		}
+
+		if duration := table.field(record, mapping.Duration); duration != "" {
+			if item.DurationMinutes, err = parseDurationMinutes(duration); err != nil {
+				return nil, err
+			}
+		}
+		if item.DurationMinutes == 0 {
+			item.DurationMinutes = trackerEstimate.Estimate(item.Title, item.EpisodeInfo)
+		}
+
+		return &Record{Item: item, Service: table.field(record, mapping.Service)}, nil
+	}), nil
+}
+
+// parseDurationMinutes reads a duration given in whole minutes or as
+// "h:mm" or "h:mm:ss", rounding seconds to the nearest minute
+func parseDurationMinutes(s string) (int, error) {
+	if minutes, err := strconv.Atoi(s); err == nil && minutes >= 0 {
+		return minutes, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid minutes: %q", s)
+	}
+	seconds := 0
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (i > 0 && n >= 60) {
+			return 0, fmt.Errorf("invalid minutes: %q", s)
+		}
+		seconds = seconds*60 + n
+	}
+	if len(parts) == 2 {
+		seconds *= 60
+	}
+	return (seconds + 30) / 60, nil
+}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGenericCSV(t *testing.T) {
+	csvData := "title,date,minutes,episode\n" +
+		"Planet Earth III,2024-01-05 20:30,55,S01E01\n" +
+		"Oppenheimer,1/6/2024,,\n" +
+		"Broken,2024-01-07,lots,\n"
+
+	result, err := ParseGenericCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 4 {
+		t.Errorf("Expected 1 error on line 4, got %+v", result.Errors)
+	}
+
+	first := result.Records[0].Item
+	if first.DurationMinutes != 55 || first.EpisodeInfo != "S01E01" {
+		t.Errorf("Unexpected first record: %+v", first)
+	}
+	if !first.WatchedAt.Equal(time.Date(2024, 1, 5, 20, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected watched at: %v", first.WatchedAt)
+	}
+	if second := result.Records[1].Item; second.DurationMinutes != trackerEstimate.FilmMinutes {
+		t.Errorf("Expected the estimated film length without minutes, got %d", second.DurationMinutes)
+	}
+}
+
+func TestParseGenericCSVMissingColumns(t *testing.T) {
+	if _, err := ParseGenericCSV(strings.NewReader("name,minutes\nFoo,30\n")); err == nil {
+		t.Error("Expected an error when the title and date columns are missing")
+	}
+}
+
+func TestParseMappedCSV(t *testing.T) {
+	csvData := "Name,Watched On,Runtime,Platform\n" +
+		"Severance,2024-02-01,0:52,Apple TV+\n" +
+		"Dune: Part Two,2024-03-02,2:46:10,\n" +
+		"Arrival,2024-03-03,1:75,\n"
+
+	mapping := ColumnMapping{Title: "name", Date: "Watched On", Duration: "RUNTIME", Service: "platform"}
+	result, err := ParseMappedCSV(strings.NewReader(csvData), mapping)
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 4 {
+		t.Errorf("Expected 1 error on line 4, got %+v", result.Errors)
+	}
+
+	first := result.Records[0]
+	if first.Item.Title != "Severance" || first.Item.DurationMinutes != 52 || first.Service != "Apple TV+" {
+		t.Errorf("Unexpected first record: %+v", first)
+	}
+	if second := result.Records[1]; second.Item.DurationMinutes != 166 || second.Service != "" {
+		t.Errorf("Unexpected second record: %+v", second)
+	}
+
+	if _, err := ParseMappedCSV(strings.NewReader(csvData), ColumnMapping{Title: "title"}); err == nil {
+		t.Error("Expected an error when a mapped column is missing")
+	}
+}
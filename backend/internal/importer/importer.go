@@ -0,0 +1,243 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+// Parser reads an export file into records
+type Parser func(io.Reader) (*ParseResult, error)
+
+// Formats maps an export format name to its parser. Services' own exports are
+// named after the service key; tracker exports can be imported into any service.
+var Formats = map[string]Parser{
+	"netflix":   ParseNetflixCSV,
+	"audible":   ParseAudibleLibrary,
+	"trakt":     ParseTraktHistory,
+	"serializd": ParseSerializdCSV,
+	"generic":   ParseGenericCSV,
+}
+
+// Record is a single successfully parsed row from an import file
+type Record struct {
+	Line    int
+	Item    database.WatchHistory
+	Service string // Service named by the row itself, if the format has one
+	Profile string // Profile named by the row itself, if the format has one
+	TMDBID  int64  // TMDB ID of the movie or show named by the row, if the format has one
+}
+
+// RowError describes a row that could not be parsed
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ParseResult holds the parsed records and row-level errors from an import file
+type ParseResult struct {
+	Records []Record
+	Errors  []RowError
+}
+
+// PreviewRow shows how a single row was interpreted
+type PreviewRow struct {
+	Line            int        `json:"line"`
+	Title           string     `json:"title"`
+	EpisodeInfo     string     `json:"episode_info"`
+	WatchedAt       time.Time  `json:"watched_at"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Duplicate       bool       `json:"duplicate"`
+	TMDB            *TMDBMatch `json:"tmdb,omitempty"` // Set when TMDB lookups are enabled and the title matched
+}
+
+// TMDBMatch is the TMDB movie or show a previewed title matched
+type TMDBMatch struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Kind  string `json:"kind"` // "movie" or "tv"
+}
+
+// Summary reports the outcome of an import (or what would happen, for a dry run)
+type Summary struct {
+	DryRun          bool             `json:"dry_run"`
+	AlreadyImported bool             `json:"already_imported"`
+	PreviousImport  *database.Import `json:"previous_import,omitempty"`
+	TotalRows       int              `json:"total_rows"`
+	Parsed          int              `json:"parsed"`
+	Duplicates      int              `json:"duplicates"`
+	Ignored         int              `json:"ignored"`
+	Imported        int              `json:"imported"`
+	Errors          []RowError       `json:"errors"`
+	Preview         []PreviewRow     `json:"preview"`
+}
+
+// Options controls how parsed records are applied to the database
+type Options struct {
+	DryRun      bool // When true, nothing is written to the database
+	PreviewRows int  // Number of interpreted rows to include in the summary
+
+	// Services maps lowercase service names to IDs for records that name
+	// their own service; records naming one not listed are rejected
+	Services map[string]int64
+
+	// TMDB, when set, looks up the title of each preview row. Lookups that
+	// fail are logged and leave the row without a match.
+	TMDB *tmdb.Client
+}
+
+// Fingerprint returns the content hash used to recognize a file that was already imported
+func Fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Apply checks each parsed record for duplicates and, unless DryRun is set,
+// inserts the new ones for the given service in one batch. Records that name
+// their own service are imported into that one instead, and records that name
+// a profile are attributed to it, creating the profile unless DryRun is set.
+func Apply(db *database.DB, serviceID int64, parsed *ParseResult, opts Options) (*Summary, error) {
+	summary := &Summary{
+		DryRun:    opts.DryRun,
+		TotalRows: len(parsed.Records) + len(parsed.Errors),
+		Parsed:    len(parsed.Records),
+		Errors:    parsed.Errors,
+		Preview:   []PreviewRow{},
+	}
+	if summary.Errors == nil {
+		summary.Errors = []RowError{}
+	}
+
+	// Track rows seen in this file so dry runs also catch duplicates within the file
+	seen := make(map[string]bool)
+	profiles := make(map[string]int64)
+	var batch []database.WatchHistory
+
+	for _, rec := range parsed.Records {
+		item := rec.Item
+		item.ServiceID = serviceID
+		if rec.Service != "" {
+			id, ok := opts.Services[strings.ToLower(rec.Service)]
+			if !ok {
+				id, ok = opts.Services[strings.ToLower(database.CurrentServiceName(rec.Service))]
+			}
+			if !ok {
+				summary.Parsed--
+				summary.Errors = append(summary.Errors, RowError{Line: rec.Line, Error: fmt.Sprintf("unknown service %q", rec.Service)})
+				continue
+			}
+			item.ServiceID = id
+		}
+		if rec.Profile != "" {
+			id, err := resolveProfile(db, profiles, rec.Profile, opts.DryRun)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve profile on line %d: %w", rec.Line, err)
+			}
+			item.ProfileID = id
+		}
+
+		// Skip titles on the ignore list
+		ignored, err := db.IsTitleIgnored(item.ServiceID, item.Title)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check ignore list on line %d: %w", rec.Line, err)
+		}
+		if ignored {
+			summary.Ignored++
+			continue
+		}
+
+		key := fmt.Sprintf("%d|%s|%s|%s|%s", item.ServiceID, strings.ToLower(rec.Profile), item.Title, item.EpisodeInfo, item.WatchedAt.Format("2006-01-02"))
+		duplicate := seen[key]
+		if !duplicate {
+			exists, err := db.ForProfile(item.ProfileID).WatchHistoryExists(item.ServiceID, item.Title, item.EpisodeInfo, item.WatchedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check for duplicate on line %d: %w", rec.Line, err)
+			}
+			duplicate = exists
+		}
+		seen[key] = true
+
+		if len(summary.Preview) < opts.PreviewRows {
+			summary.Preview = append(summary.Preview, PreviewRow{
+				Line:            rec.Line,
+				Title:           item.Title,
+				EpisodeInfo:     item.EpisodeInfo,
+				WatchedAt:       item.WatchedAt,
+				DurationMinutes: item.DurationMinutes,
+				Duplicate:       duplicate,
+				TMDB:            previewMatch(opts.TMDB, item.Title),
+			})
+		}
+
+		if duplicate {
+			summary.Duplicates++
+			continue
+		}
+
+		if opts.DryRun {
+			continue
+		}
+
+		batch = append(batch, item)
+	}
+
+	if err := db.InsertWatchHistoryBatch(batch); err != nil {
+		return nil, fmt.Errorf("failed to insert history: %w", err)
+	}
+	summary.Imported = len(batch)
+
+	return summary, nil
+}
+
+// previewMatch returns the TMDB match for a previewed title, or nil when
+// there's no client or nothing matched
+func previewMatch(client *tmdb.Client, title string) *TMDBMatch {
+	if client == nil {
+		return nil
+	}
+	item, err := client.SearchMulti(context.Background(), title)
+	if err != nil {
+		log.Printf("Import preview: TMDB lookup of %q failed: %v", title, err)
+		return nil
+	}
+	if item == nil {
+		return nil
+	}
+	return &TMDBMatch{ID: item.ID, Title: item.DisplayTitle(), Kind: item.MediaType}
+}
+
+// resolveProfile returns the ID of the named profile, caching lookups by
+// lowercase name since profile names are case-insensitive. Dry runs only look
+// the profile up, leaving a profile that doesn't exist yet as 0.
+func resolveProfile(db *database.DB, cache map[string]int64, name string, dryRun bool) (int64, error) {
+	key := strings.ToLower(name)
+	if id, ok := cache[key]; ok {
+		return id, nil
+	}
+
+	var profile *database.Profile
+	var err error
+	if dryRun {
+		profile, err = db.GetProfileByName(name)
+	} else {
+		profile, err = db.EnsureProfile(name)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	if profile != nil {
+		id = profile.ID
+	}
+	cache[key] = id
+	return id, nil
+}
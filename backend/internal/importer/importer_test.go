@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, int64) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	service, _ := db.GetServiceByName("Netflix")
+	return db, service.ID
+}
+
+func parseTestCSV(t *testing.T) *ParseResult {
+	csvData := `Title,Date
+"Stranger Things: Season 1: Chapter One","1/15/25"
+"Stranger Things: Season 1: Chapter One","1/15/25"
+"The Irishman","1/14/25"
+`
+	result, err := ParseNetflixCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	return result
+}
+
+func TestApplyDryRun(t *testing.T) {
+	db, serviceID := setupTestDB(t)
+	defer db.Close()
+
+	summary, err := Apply(db, serviceID, parseTestCSV(t), Options{DryRun: true, PreviewRows: 2})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if summary.Parsed != 3 {
+		t.Errorf("Expected 3 parsed rows, got %d", summary.Parsed)
+	}
+	if summary.Duplicates != 1 {
+		t.Errorf("Expected 1 duplicate within file, got %d", summary.Duplicates)
+	}
+	if summary.Imported != 0 {
+		t.Errorf("Expected nothing imported on dry run, got %d", summary.Imported)
+	}
+	if len(summary.Preview) != 2 {
+		t.Errorf("Expected 2 preview rows, got %d", len(summary.Preview))
+	}
+
+	// Nothing should have been written
+	history, _ := db.GetWatchHistory(serviceID, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), 10, 0)
+	if len(history) != 0 {
+		t.Errorf("Expected no history after dry run, got %d", len(history))
+	}
+}
+
+func TestApplyPreviewsTMDBMatches(t *testing.T) {
+	db, serviceID := setupTestDB(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") != "The Irishman" {
+			w.Write([]byte(`{"results": []}`))
+			return
+		}
+		w.Write([]byte(`{"results": [{"id": 398978, "media_type": "movie", "title": "The Irishman"}]}`))
+	}))
+	defer server.Close()
+	client := tmdb.NewClientWithBaseURL("test-key", server.URL)
+
+	summary, err := Apply(db, serviceID, parseTestCSV(t), Options{DryRun: true, PreviewRows: 3, TMDB: client})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(summary.Preview) != 3 {
+		t.Fatalf("Expected 3 preview rows, got %d", len(summary.Preview))
+	}
+
+	if match := summary.Preview[0].TMDB; match != nil {
+		t.Errorf("Expected no match for %s, got %+v", summary.Preview[0].Title, match)
+	}
+	expected := TMDBMatch{ID: 398978, Title: "The Irishman", Kind: "movie"}
+	if match := summary.Preview[2].TMDB; match == nil || *match != expected {
+		t.Errorf("Expected %+v, got %+v", expected, match)
+	}
+}
+
+func TestApplyImportsAndSkipsDuplicates(t *testing.T) {
+	db, serviceID := setupTestDB(t)
+	defer db.Close()
+
+	summary, err := Apply(db, serviceID, parseTestCSV(t), Options{})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if summary.Imported != 2 {
+		t.Errorf("Expected 2 imported rows, got %d", summary.Imported)
+	}
+
+	// Re-running should find everything as duplicates
+	summary, err = Apply(db, serviceID, parseTestCSV(t), Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if summary.Duplicates != 3 {
+		t.Errorf("Expected 3 duplicates on re-import, got %d", summary.Duplicates)
+	}
+}
+
+func TestApplyAttributesProfiles(t *testing.T) {
+	db, serviceID := setupTestDB(t)
+	defer db.Close()
+
+	watchedAt := time.Date(2025, 1, 15, 21, 0, 0, 0, time.UTC)
+	parsed := &ParseResult{Records: []Record{
+		{Line: 2, Profile: "Alice", Item: database.WatchHistory{Title: "The Irishman", WatchedAt: watchedAt, DurationMinutes: 209}},
+		{Line: 3, Profile: "bob", Item: database.WatchHistory{Title: "The Irishman", WatchedAt: watchedAt, DurationMinutes: 60}},
+		{Line: 4, Profile: "Bob", Item: database.WatchHistory{Title: "The Irishman", WatchedAt: watchedAt, DurationMinutes: 60}},
+	}}
+
+	summary, err := Apply(db, serviceID, parsed, Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if summary.Duplicates != 1 {
+		t.Errorf("Expected only the repeat within one profile to be a duplicate, got %d", summary.Duplicates)
+	}
+	if profile, _ := db.GetProfileByName("Alice"); profile != nil {
+		t.Error("Expected dry run not to create profiles")
+	}
+
+	summary, err = Apply(db, serviceID, parsed, Options{})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if summary.Imported != 2 {
+		t.Errorf("Expected 2 imported rows, got %d", summary.Imported)
+	}
+
+	alice, _ := db.GetProfileByName("Alice")
+	if alice == nil {
+		t.Fatal("Expected profile Alice to be created")
+	}
+	history, _ := db.ForProfile(alice.ID).GetWatchHistory(serviceID, watchedAt.Add(-time.Hour), watchedAt.Add(time.Hour), 10, 0)
+	if len(history) != 1 || history[0].DurationMinutes != 209 {
+		t.Errorf("Expected Alice's entry under that profile, got %+v", history)
+	}
+}
@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+var netflixEpisodePattern = regexp.MustCompile(`[Ss](\d+):?\s*[Ee](\d+)`)
+
+// viewingActivityEpisodePattern matches the episode titles of a full data
+// download, e.g. "Season 1: Chapter One (Episode 1)"
+var viewingActivityEpisodePattern = regexp.MustCompile(`^(?:Season|Part|Volume) (\d+):.*\(Episode (\d+)\)$`)
+
+// ParseNetflixCSV parses a NetflixViewingHistory.csv export (columns "Title","Date"),
+// or the ViewingActivity.csv of a full account data download, recognized by its
+// "Profile Name" and "Start Time" columns.
+// Rows that can't be interpreted are reported as RowErrors rather than failing the whole file.
+func ParseNetflixCSV(r io.Reader) (*ParseResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	if table, err := newCSVTable(bytes.NewReader(data)); err == nil && table.column("profile name") != "" && table.column("start time") != "" {
+		return parseViewingActivity(table)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	titleCol, dateCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "title":
+			titleCol = i
+		case "date":
+			dateCol = i
+		}
+	}
+	if titleCol == -1 || dateCol == -1 {
+		return nil, fmt.Errorf("CSV header must contain Title and Date columns")
+	}
+
+	result := &ParseResult{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+		if len(record) <= titleCol || len(record) <= dateCol {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: "missing columns"})
+			continue
+		}
+
+		item, err := parseNetflixRow(record[titleCol], record[dateCol])
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+		result.Records = append(result.Records, Record{Line: line, Item: item})
+	}
+
+	return result, nil
+}
+
+// parseNetflixRow interprets a single title/date pair the same way the Netflix scraper does
+func parseNetflixRow(rawTitle, rawDate string) (database.WatchHistory, error) {
+	var item database.WatchHistory
+
+	rawTitle = strings.TrimSpace(rawTitle)
+	if rawTitle == "" {
+		return item, fmt.Errorf("empty title")
+	}
+
+	watchedAt, err := parseNetflixDate(rawDate)
+	if err != nil {
+		return item, err
+	}
+	item.WatchedAt = watchedAt
+	item.Title = rawTitle
+
+	// "Show: Season 1: Episode" -> title "Show", episode "Season 1: Episode"
+	if parts := strings.SplitN(rawTitle, ":", 2); len(parts) == 2 {
+		item.Title = strings.TrimSpace(parts[0])
+		item.EpisodeInfo = strings.TrimSpace(parts[1])
+	}
+
+	if matches := netflixEpisodePattern.FindStringSubmatch(item.EpisodeInfo); len(matches) > 0 {
+		item.EpisodeInfo = fmt.Sprintf("S%02sE%02s", matches[1], matches[2])
+	}
+
+	// Netflix exports don't include duration, so use the scraper's estimates
+	item.DurationMinutes = estimate.Netflix.Estimate(item.Title, item.EpisodeInfo)
+
+	return item, nil
+}
+
+// parseViewingActivity parses the ViewingActivity.csv of a Netflix account
+// data download, which has every play with its profile, start time in UTC,
+// real duration and device. Trailers, previews and other supplemental videos
+// are skipped, as are plays under a minute.
+func parseViewingActivity(table *csvTable) (*ParseResult, error) {
+	if table.column("title") == "" || table.column("duration") == "" {
+		return nil, fmt.Errorf("CSV header must contain Title and Duration columns")
+	}
+
+	return table.each(func(record []string) (*Record, error) {
+		if table.field(record, "supplemental video type") != "" {
+			return nil, nil
+		}
+
+		rawTitle := table.field(record, "title")
+		if rawTitle == "" {
+			return nil, fmt.Errorf("empty title")
+		}
+		watchedAt, err := time.Parse("2006-01-02 15:04:05", table.field(record, "start time"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse start time: %s", table.field(record, "start time"))
+		}
+		minutes, err := parseDurationMinutes(table.field(record, "duration"))
+		if err != nil {
+			return nil, err
+		}
+		if minutes == 0 {
+			return nil, nil
+		}
+
+		item := database.WatchHistory{
+			Title:           rawTitle,
+			WatchedAt:       watchedAt,
+			DurationMinutes: minutes,
+			Device:          table.field(record, "device type"),
+			Location:        table.field(record, "country"),
+		}
+		if parts := strings.SplitN(rawTitle, ":", 2); len(parts) == 2 {
+			item.Title = strings.TrimSpace(parts[0])
+			item.EpisodeInfo = strings.TrimSpace(parts[1])
+		}
+		if m := viewingActivityEpisodePattern.FindStringSubmatch(item.EpisodeInfo); m != nil {
+			item.EpisodeInfo = fmt.Sprintf("S%02sE%02s", m[1], m[2])
+		}
+
+		return &Record{Item: item, Profile: table.field(record, "profile name")}, nil
+	}), nil
+}
+
+// parseNetflixDate parses the date formats used in Netflix exports
+func parseNetflixDate(dateStr string) (time.Time, error) {
+	dateStr = strings.TrimSpace(dateStr)
+
+	layouts := []string{
+		"1/2/06",          // M/D/YY (US export)
+		"1/2/2006",        // M/D/YYYY
+		"2006-01-02",      // YYYY-MM-DD
+		"January 2, 2006", // January 2, 2006
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, dateStr); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNetflixCSV(t *testing.T) {
+	csvData := `Title,Date
+"Stranger Things: Season 1: Chapter One: The Vanishing of Will Byers","1/15/25"
+"The Irishman","1/14/25"
+"Broken Row","not a date"
+`
+
+	result, err := ParseNetflixCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 4 {
+		t.Errorf("Expected 1 error on line 4, got %+v", result.Errors)
+	}
+
+	episode := result.Records[0].Item
+	if episode.Title != "Stranger Things" {
+		t.Errorf("Expected title 'Stranger Things', got '%s'", episode.Title)
+	}
+	if episode.EpisodeInfo != "Season 1: Chapter One: The Vanishing of Will Byers" {
+		t.Errorf("Unexpected episode info '%s'", episode.EpisodeInfo)
+	}
+	if episode.WatchedAt.Format("2006-01-02") != "2025-01-15" {
+		t.Errorf("Expected date 2025-01-15, got %s", episode.WatchedAt.Format("2006-01-02"))
+	}
+	if episode.DurationMinutes != 40 {
+		t.Errorf("Expected episode duration 40, got %d", episode.DurationMinutes)
+	}
+
+	movie := result.Records[1].Item
+	if movie.EpisodeInfo != "" || movie.DurationMinutes != 105 {
+		t.Errorf("Expected movie with 105 minutes, got %+v", movie)
+	}
+}
+
+func TestParseNetflixCSVMissingColumns(t *testing.T) {
+	_, err := ParseNetflixCSV(strings.NewReader("Name,When\nfoo,bar\n"))
+	if err == nil {
+		t.Error("Expected error for CSV without Title/Date columns")
+	}
+}
+
+func TestParseNetflixCSVWithBOM(t *testing.T) {
+	result, err := ParseNetflixCSV(strings.NewReader("\ufeffTitle,Date\nThe Irishman,1/14/25\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV with BOM: %v", err)
+	}
+	if len(result.Records) != 1 {
+		t.Errorf("Expected 1 record, got %d", len(result.Records))
+	}
+}
+
+func TestParseNetflixViewingActivity(t *testing.T) {
+	csvData := `Profile Name,Start Time,Duration,Attributes,Title,Supplemental Video Type,Device Type,Bookmark,Latest Bookmark,Country
+Alice,2025-01-15 21:03:11,00:47:30,,Stranger Things: Season 1: Chapter One: The Vanishing of Will Byers (Episode 1),,Apple TV 4K,00:47:30,00:47:30,US (United States)
+Alice,2025-01-15 21:01:02,00:01:10,,Stranger Things: Season 1 (Trailer),TRAILER,Apple TV 4K,00:01:10,00:01:10,US (United States)
+Bob,2025-01-14 19:00:00,02:09:05,,The Irishman,,Chrome PC (Cadmium),02:09:05,02:09:05,US (United States)
+Bob,2025-01-14 18:59:00,00:00:12,Autoplayed,Glass Onion,,Chrome PC (Cadmium),00:00:12,00:00:12,US (United States)
+Bob,yesterday,00:30:00,,Broken Row,,Chrome PC (Cadmium),00:30:00,00:30:00,US (United States)
+`
+
+	result, err := ParseNetflixCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d: %+v", len(result.Records), result.Records)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 6 {
+		t.Errorf("Expected 1 error on line 6, got %+v", result.Errors)
+	}
+
+	episode := result.Records[0]
+	if episode.Profile != "Alice" {
+		t.Errorf("Expected profile Alice, got %q", episode.Profile)
+	}
+	if episode.Item.Title != "Stranger Things" || episode.Item.EpisodeInfo != "S01E01" {
+		t.Errorf("Expected Stranger Things S01E01, got %q %q", episode.Item.Title, episode.Item.EpisodeInfo)
+	}
+	if episode.Item.DurationMinutes != 48 {
+		t.Errorf("Expected real duration of 48 minutes, got %d", episode.Item.DurationMinutes)
+	}
+	if !episode.Item.WatchedAt.Equal(time.Date(2025, 1, 15, 21, 3, 11, 0, time.UTC)) {
+		t.Errorf("Unexpected start time %v", episode.Item.WatchedAt)
+	}
+	if episode.Item.Device != "Apple TV 4K" || episode.Item.Location != "US (United States)" {
+		t.Errorf("Expected device and country, got %q %q", episode.Item.Device, episode.Item.Location)
+	}
+
+	movie := result.Records[1]
+	if movie.Profile != "Bob" || movie.Item.Title != "The Irishman" || movie.Item.DurationMinutes != 129 {
+		t.Errorf("Unexpected movie record %+v", movie)
+	}
+}
@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// NetworkActivityTitle is the title of low-confidence sessions inferred from
+// streaming traffic seen on the home network
+const NetworkActivityTitle = "Network Activity"
+
+// Sightings of a service's domains closer together than networkSessionGap
+// are one session, and sessions shorter than networkMinMinutes are dropped as
+// apps checking in rather than someone watching
+const (
+	networkSessionGap = 15 * time.Minute
+	networkMinMinutes = 5
+)
+
+// networkDomains maps streaming and CDN domains to services. Subdomains match too.
+var networkDomains = map[string]string{
+	"nflxvideo.net":        "Netflix",
+	"nflxso.net":           "Netflix",
+	"netflix.com":          "Netflix",
+	"aiv-cdn.net":          "Amazon Video",
+	"aiv-delivery.net":     "Amazon Video",
+	"primevideo.com":       "Amazon Video",
+	"hbomaxcdn.com":        "HBO Max",
+	"max.com":              "HBO Max",
+	"peacocktv.com":        "Peacock",
+	"hulustream.com":       "Hulu",
+	"hulu.com":             "Hulu",
+	"dssott.com":           "Disney+",
+	"disney-plus.net":      "Disney+",
+	"disneyplus.com":       "Disney+",
+	"tv.apple.com":         "Apple TV",
+	"vudu.com":             "Vudu",
+	"kanopy.com":           "Kanopy",
+	"hoopladigital.com":    "Hoopla",
+	"mubi.com":             "MUBI",
+	"criterionchannel.com": "Criterion Channel",
+}
+
+// NetworkObservation is traffic to a domain seen by a DNS resolver or traffic
+// monitor like Pi-hole or ntopng: a window from first_seen to last_seen, or
+// a single query at seen_at
+type NetworkObservation struct {
+	Domain    string    `json:"domain"`
+	Client    string    `json:"client"` // Device name or address, e.g. "192.168.1.20"
+	SeenAt    time.Time `json:"seen_at"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// NetworkBatch is what a log exporter posts
+type NetworkBatch struct {
+	Source       string               `json:"source"` // e.g. "pihole", kept for logging
+	Observations []NetworkObservation `json:"observations"`
+}
+
+// ParseNetworkObservations decodes a batch of network observations
+func ParseNetworkObservations(r io.Reader) (*NetworkBatch, error) {
+	var batch NetworkBatch
+	if err := json.NewDecoder(r).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("failed to decode network observations: %w", err)
+	}
+	return &batch, nil
+}
+
+// NetworkDomainService returns the service a domain's traffic is counted
+// under, matching subdomains and preferring configured mappings
+func NetworkDomainService(domain string, custom map[string]string) (string, bool) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	for _, mapping := range []map[string]string{custom, networkDomains} {
+		for suffix, service := range mapping {
+			suffix = strings.ToLower(suffix)
+			if domain == suffix || strings.HasSuffix(domain, "."+suffix) {
+				return service, true
+			}
+		}
+	}
+	return "", false
+}
+
+// networkWindow is traffic to one service from one client
+type networkWindow struct {
+	line       int
+	service    string
+	client     string
+	start, end time.Time
+}
+
+// ApplyNetworkObservations merges observations into sessions per service and
+// client and stores them as low-confidence entries, skipping sessions that
+// overlap history read from the service. Invalid observations are reported by their
+// position in the batch; domains of unknown services are ignored, since DNS
+// logs are full of them.
+func ApplyNetworkObservations(db *database.DB, batch *NetworkBatch, domains map[string]string, opts Options) (*UsageSummary, error) {
+	var windows []networkWindow
+	var errs []RowError
+	for i, obs := range batch.Observations {
+		line := i + 1
+		start, end := obs.FirstSeen, obs.LastSeen
+		if start.IsZero() {
+			start = obs.SeenAt
+		}
+		if end.IsZero() {
+			end = start
+		}
+		if start.IsZero() || end.Before(start) {
+			errs = append(errs, RowError{Line: line, Error: "observation needs seen_at, or first_seen before last_seen"})
+			continue
+		}
+
+		service, ok := NetworkDomainService(obs.Domain, domains)
+		if !ok {
+			continue
+		}
+		windows = append(windows, networkWindow{line: line, service: service, client: obs.Client, start: start, end: end})
+	}
+
+	var entries []usageEntry
+	for _, w := range mergeNetworkWindows(windows) {
+		entries = append(entries, usageEntry{
+			Line:       w.line,
+			App:        w.service,
+			Device:     w.client,
+			At:         w.start,
+			Minutes:    networkMinutes(w),
+			Confidence: database.ConfidenceLow,
+		})
+	}
+
+	summary, err := applyUsage(db, entries, NetworkActivityTitle, func(service string) (string, bool) {
+		return service, true
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if errs != nil {
+		summary.Errors = errs
+	}
+	return summary, nil
+}
+
+// mergeNetworkWindows joins windows for the same service and client that
+// overlap or are within networkSessionGap of each other
+func mergeNetworkWindows(windows []networkWindow) []networkWindow {
+	sort.SliceStable(windows, func(i, k int) bool {
+		if windows[i].service != windows[k].service {
+			return windows[i].service < windows[k].service
+		}
+		if windows[i].client != windows[k].client {
+			return windows[i].client < windows[k].client
+		}
+		return windows[i].start.Before(windows[k].start)
+	})
+
+	var merged []networkWindow
+	for _, w := range windows {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.service == w.service && last.client == w.client && !w.start.After(last.end.Add(networkSessionGap)) {
+				if w.end.After(last.end) {
+					last.end = w.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
+
+// networkMinutes returns a session's length, or 0 when it is too short to count
+func networkMinutes(w networkWindow) int {
+	minutes := int(w.end.Sub(w.start).Round(time.Minute) / time.Minute)
+	if minutes < networkMinMinutes {
+		return 0
+	}
+	return minutes
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestApplyNetworkObservations(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	body := `{"source": "pihole", "observations": [
+		{"domain": "ipv4-c001-sea001.nflxvideo.net", "client": "192.168.1.20", "seen_at": "2025-01-14T20:00:00Z"},
+		{"domain": "occ-0-1.nflxso.net.", "client": "192.168.1.20", "first_seen": "2025-01-14T20:10:00Z", "last_seen": "2025-01-14T22:20:00Z"},
+		{"domain": "api-global.netflix.com", "client": "192.168.1.20", "seen_at": "2025-01-14T22:30:00Z"},
+		{"domain": "atv-ext.amazon.com", "client": "192.168.1.21", "seen_at": "2025-01-14T21:00:00Z"},
+		{"domain": "hulu.com", "client": "192.168.1.21", "seen_at": "2025-01-14T21:00:00Z"},
+		{"domain": "media.plex.direct", "client": "192.168.1.22", "first_seen": "2025-01-14T19:00:00Z", "last_seen": "2025-01-14T19:45:00Z"},
+		{"domain": "netflix.com", "first_seen": "2025-01-14T23:00:00Z", "last_seen": "2025-01-14T22:00:00Z"}
+	]}`
+	batch, err := ParseNetworkObservations(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to parse observations: %v", err)
+	}
+
+	summary, err := ApplyNetworkObservations(db, batch, map[string]string{"plex.direct": "Kanopy"}, Options{})
+	if err != nil {
+		t.Fatalf("Failed to apply observations: %v", err)
+	}
+	if summary.Imported != 2 {
+		t.Errorf("Expected a Netflix and a Kanopy session, got %+v", summary)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Line != 7 {
+		t.Errorf("Expected an error for the observation ending before it starts, got %+v", summary.Errors)
+	}
+
+	start := time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)
+	history, _ := db.GetWatchHistory(netflixID, start, start.AddDate(0, 0, 1), 10, 0)
+	if len(history) != 1 {
+		t.Fatalf("Expected one merged Netflix session, got %+v", history)
+	}
+	session := history[0]
+	if session.Title != NetworkActivityTitle || session.DurationMinutes != 150 || session.Confidence != database.ConfidenceLow || session.Device != "192.168.1.20" {
+		t.Errorf("Expected a 150 minute low-confidence session from 192.168.1.20, got %+v", session)
+	}
+
+	hulu, _ := db.GetServiceByName("Hulu")
+	history, _ = db.GetWatchHistory(hulu.ID, start, start.AddDate(0, 0, 1), 10, 0)
+	if len(history) != 0 {
+		t.Errorf("Expected a single Hulu query to be too short for a session, got %+v", history)
+	}
+}
+
+func TestApplyNetworkObservationsSkipsScrapedTime(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	db.InsertWatchHistory(&database.WatchHistory{
+		ServiceID:       netflixID,
+		Title:           "Stranger Things",
+		DurationMinutes: 50,
+		WatchedAt:       time.Date(2025, 1, 14, 20, 30, 0, 0, time.UTC),
+	})
+
+	// Only the evening session overlaps the scraped episode
+	body := `{"observations": [
+		{"domain": "nflxvideo.net", "first_seen": "2025-01-14T20:00:00Z", "last_seen": "2025-01-14T21:00:00Z"},
+		{"domain": "nflxvideo.net", "first_seen": "2025-01-14T12:00:00Z", "last_seen": "2025-01-14T13:00:00Z"}
+	]}`
+	batch, err := ParseNetworkObservations(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to parse observations: %v", err)
+	}
+
+	summary, err := ApplyNetworkObservations(db, batch, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to apply observations: %v", err)
+	}
+	if summary.Imported != 1 || summary.Scraped != 1 {
+		t.Errorf("Expected the overlapping session skipped and the other kept, got %+v", summary)
+	}
+}
+
+func TestNetworkDomainService(t *testing.T) {
+	tests := []struct {
+		domain  string
+		service string
+		ok      bool
+	}{
+		{"nflxvideo.net", "Netflix", true},
+		{"IPV4-C001.NFLXVIDEO.NET", "Netflix", true},
+		{"notnflxvideo.net", "", false},
+		{"s3.aiv-cdn.net", "Amazon Video", true},
+		{"example.com", "", false},
+	}
+
+	for _, tt := range tests {
+		service, ok := NetworkDomainService(tt.domain, nil)
+		if service != tt.service || ok != tt.ok {
+			t.Errorf("NetworkDomainService(%q) = %q, %v, want %q, %v", tt.domain, service, ok, tt.service, tt.ok)
+		}
+	}
+}
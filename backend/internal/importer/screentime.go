@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// ScreenTimeTitle is the title of entries estimated from Screen Time, which
+// only knows which app was open and for how long
+const ScreenTimeTitle = database.ScreenTimeTitle
+
+// screenTimeApps maps lowercased Screen Time app names and bundle IDs to services
+var screenTimeApps = map[string]string{
+	"netflix":                         "Netflix",
+	"com.netflix.netflix":             "Netflix",
+	"youtube tv":                      "YouTube TV",
+	"com.google.ios.youtubeunplugged": "YouTube TV",
+	"prime video":                     "Amazon Video",
+	"com.amazon.aiv.aivapp":           "Amazon Video",
+	"max":                             "HBO Max",
+	"hbo max":                         "HBO Max",
+	"com.wbd.stream":                  "HBO Max",
+	"tv":                              "Apple TV",
+	"apple tv":                        "Apple TV",
+	"com.apple.tv":                    "Apple TV",
+	"peacock":                         "Peacock",
+	"com.peacocktv.peacockios":        "Peacock",
+	"vudu":                            "Vudu",
+	"fandango at home":                "Vudu",
+	"hulu":                            "Hulu",
+	"com.hulu.plus":                   "Hulu",
+	"disney+":                         "Disney+",
+	"com.disney.disneyplus":           "Disney+",
+	"kanopy":                          "Kanopy",
+	"hoopla":                          "Hoopla",
+	"mubi":                            "MUBI",
+	"criterion channel":               "Criterion Channel",
+	"espn":                            "ESPN+",
+	"audible":                         "Audible",
+	"com.audible.iphone":              "Audible",
+}
+
+// ScreenTimeUsage is one app's total for a day
+type ScreenTimeUsage struct {
+	Line    int
+	Date    time.Time
+	App     string
+	Device  string
+	Minutes int
+}
+
+// screenTimeDay is the JSON a Shortcuts automation posts: a day's per-app
+// minutes, alone or in an array of days
+type screenTimeDay struct {
+	Date   string `json:"date"`
+	Device string `json:"device"`
+	Apps   []struct {
+		App     string `json:"app"`
+		Minutes int    `json:"minutes"`
+	} `json:"apps"`
+}
+
+// ParseScreenTime parses Screen Time usage posted as JSON ({"date", "device",
+// "apps": [{"app", "minutes"}]}, or an array of those) or exported as a CSV
+// with date, app and minutes columns and an optional device column. Line
+// numbers are the day's position in JSON and the row in CSV.
+func ParseScreenTime(r io.Reader) ([]ScreenTimeUsage, []RowError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		return parseScreenTimeJSON(trimmed)
+	}
+	return parseScreenTimeCSV(data)
+}
+
+// parseScreenTimeJSON parses one or more days posted by a Shortcuts automation
+func parseScreenTimeJSON(data []byte) ([]ScreenTimeUsage, []RowError, error) {
+	var days []screenTimeDay
+	if data[0] == '{' {
+		var day screenTimeDay
+		if err := json.Unmarshal(data, &day); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode Screen Time JSON: %w", err)
+		}
+		days = append(days, day)
+	} else if err := json.Unmarshal(data, &days); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode Screen Time JSON: %w", err)
+	}
+
+	var usage []ScreenTimeUsage
+	var errs []RowError
+	for i, day := range days {
+		line := i + 1
+		date, err := parseScreenTimeDate(day.Date)
+		if err != nil {
+			errs = append(errs, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+		for _, app := range day.Apps {
+			if strings.TrimSpace(app.App) == "" || app.Minutes < 0 {
+				errs = append(errs, RowError{Line: line, Error: fmt.Sprintf("invalid app entry %q", app.App)})
+				continue
+			}
+			usage = append(usage, ScreenTimeUsage{
+				Line:    line,
+				Date:    date,
+				App:     strings.TrimSpace(app.App),
+				Device:  strings.TrimSpace(day.Device),
+				Minutes: app.Minutes,
+			})
+		}
+	}
+	return usage, errs, nil
+}
+
+// parseScreenTimeCSV parses a manually exported date,app,minutes CSV
+func parseScreenTimeCSV(data []byte) ([]ScreenTimeUsage, []RowError, error) {
+	table, err := newCSVTable(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, required := range []string{"date", "app", "minutes"} {
+		if table.column(required) == "" {
+			return nil, nil, fmt.Errorf("CSV header must contain a %s column", required)
+		}
+	}
+
+	var usage []ScreenTimeUsage
+	result := table.each(func(record []string) (*Record, error) {
+		date, err := parseScreenTimeDate(table.field(record, "date"))
+		if err != nil {
+			return nil, err
+		}
+		app := table.field(record, "app")
+		if app == "" {
+			return nil, fmt.Errorf("empty app")
+		}
+		minutes, err := strconv.Atoi(table.field(record, "minutes"))
+		if err != nil || minutes < 0 {
+			return nil, fmt.Errorf("invalid minutes: %q", table.field(record, "minutes"))
+		}
+
+		usage = append(usage, ScreenTimeUsage{
+			Line:    table.line,
+			Date:    date,
+			App:     app,
+			Device:  table.field(record, "device"),
+			Minutes: minutes,
+		})
+		return nil, nil
+	})
+
+	return usage, result.Errors, nil
+}
+
+// parseScreenTimeDate parses a usage day. Entries are placed at local noon so
+// they stay on the same day in any nearby time zone.
+func parseScreenTimeDate(dateStr string) (time.Time, error) {
+	t, err := parseTrackerDate(dateStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, time.Local), nil
+}
+
+// ScreenTimeService returns the service an app's time is counted under, with
+// configured mappings taking precedence over the built-in ones
+func ScreenTimeService(app string, custom map[string]string) (string, bool) {
+	for name, service := range custom {
+		if strings.EqualFold(name, app) {
+			return service, true
+		}
+	}
+	service, ok := screenTimeApps[strings.ToLower(app)]
+	return service, ok
+}
+
+// ApplyScreenTime stores each app's daily minutes as one estimated entry
+// under its service. Re-importing a day replaces its totals.
+func ApplyScreenTime(db *database.DB, usage []ScreenTimeUsage, apps map[string]string, opts Options) (*UsageSummary, error) {
+	entries := make([]usageEntry, len(usage))
+	for i, u := range usage {
+		entries[i] = usageEntry{Line: u.Line, App: u.App, Device: u.Device, At: u.Date, Minutes: u.Minutes}
+	}
+	return applyUsage(db, entries, ScreenTimeTitle, func(app string) (string, bool) {
+		return ScreenTimeService(app, apps)
+	}, opts)
+}
@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestParseScreenTimeJSON(t *testing.T) {
+	body := `{"date": "2025-01-14", "device": "Living Room Apple TV", "apps": [
+		{"app": "Netflix", "minutes": 42},
+		{"app": "com.google.ios.youtube", "minutes": 15}
+	]}`
+
+	usage, errs, err := ParseScreenTime(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("Unexpected errors: %+v", errs)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("Expected 2 apps, got %d", len(usage))
+	}
+	if usage[0].App != "Netflix" || usage[0].Minutes != 42 || usage[0].Device != "Living Room Apple TV" {
+		t.Errorf("Unexpected usage: %+v", usage[0])
+	}
+	if usage[0].Date.Day() != 14 || usage[0].Date.Hour() != 12 {
+		t.Errorf("Expected noon on the 14th, got %v", usage[0].Date)
+	}
+}
+
+func TestParseScreenTimeCSV(t *testing.T) {
+	csvData := "date,app,minutes\n2025-01-14,Hulu,30\n2025-01-14,Hulu,lots\n2025-01-15,Max,90\n"
+
+	usage, errs, err := ParseScreenTime(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Line != 3 {
+		t.Errorf("Expected 1 error on line 3, got %+v", errs)
+	}
+	if len(usage) != 2 || usage[1].App != "Max" || usage[1].Line != 4 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
+
+func TestApplyScreenTime(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	day := time.Date(2025, 1, 14, 12, 0, 0, 0, time.Local)
+	hulu, _ := db.GetServiceByName("Hulu")
+
+	// Netflix was scraped that day, so its Screen Time is left out
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: netflixID, Title: "Severance", DurationMinutes: 50, WatchedAt: day.Add(8 * time.Hour)})
+
+	usage := []ScreenTimeUsage{
+		{Line: 1, Date: day, App: "Netflix", Minutes: 60},
+		{Line: 1, Date: day, App: "com.hulu.plus", Minutes: 45},
+		{Line: 1, Date: day, App: "Solitaire", Minutes: 20},
+		{Line: 1, Date: day, App: "Fandango at Home", Minutes: 0},
+	}
+	summary, err := ApplyScreenTime(db, usage, nil, Options{})
+	if err != nil {
+		t.Fatalf("Failed to apply: %v", err)
+	}
+	if summary.Imported != 1 || summary.Scraped != 1 {
+		t.Errorf("Expected 1 imported and 1 skipped as scraped, got %+v", summary)
+	}
+	if len(summary.Unmapped) != 1 || summary.Unmapped[0] != "Solitaire" {
+		t.Errorf("Expected Solitaire unmapped, got %v", summary.Unmapped)
+	}
+
+	// A later post for the same day replaces the total
+	usage[1].Minutes = 70
+	if _, err := ApplyScreenTime(db, usage[1:2], nil, Options{}); err != nil {
+		t.Fatalf("Failed to re-apply: %v", err)
+	}
+	history, _ := db.GetWatchHistory(hulu.ID, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1), 10, 0)
+	if len(history) != 1 || history[0].Title != ScreenTimeTitle || history[0].DurationMinutes != 70 {
+		t.Errorf("Expected one 70 minute Screen Time entry, got %+v", history)
+	}
+}
+
+func TestScreenTimeServiceCustomMapping(t *testing.T) {
+	service, ok := ScreenTimeService("YouTube", map[string]string{"youtube": "YouTube TV"})
+	if !ok || service != "YouTube TV" {
+		t.Errorf("Expected configured mapping to YouTube TV, got %q", service)
+	}
+	if _, ok := ScreenTimeService("YouTube", nil); ok {
+		t.Error("Expected YouTube to be unmapped by default")
+	}
+}
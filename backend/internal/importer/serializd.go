@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// ParseSerializdCSV parses a Serializd diary CSV export, with one row per
+// logged episode or season. Serializd only tracks TV and doesn't record
+// runtimes, so durations are estimated per episode; rows logging a whole
+// season without an episode number count as one episode.
+func ParseSerializdCSV(r io.Reader) (*ParseResult, error) {
+	table, err := newCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+
+	titleCol := table.column("show name", "show", "title")
+	dateCol := table.column("date watched", "watched at", "logged at", "date")
+	if titleCol == "" || dateCol == "" {
+		return nil, fmt.Errorf("CSV header must contain show name and date columns")
+	}
+	seasonCol := table.column("season number", "season")
+	episodeCol := table.column("episode number", "episode")
+
+	return table.each(func(record []string) (*Record, error) {
+		title := table.field(record, titleCol)
+		if title == "" {
+			return nil, fmt.Errorf("empty title")
+		}
+
+		watchedAt, err := parseTrackerDate(table.field(record, dateCol))
+		if err != nil {
+			return nil, err
+		}
+
+		episodeInfo, err := serializdEpisode(table.field(record, seasonCol), table.field(record, episodeCol))
+		if err != nil {
+			return nil, err
+		}
+
+		return &Record{Item: database.WatchHistory{
+			Title:           title,
+			EpisodeInfo:     episodeInfo,
+			WatchedAt:       watchedAt,
+			DurationMinutes: trackerEstimate.EpisodeMinutes,
+		}}, nil
+	}), nil
+}
+
+// serializdEpisode formats season and episode numbers as "S01E02", or
+// "Season 1" when only the season was logged
+func serializdEpisode(season, episode string) (string, error) {
+	if season == "" {
+		return "", nil
+	}
+	seasonNum, err := strconv.Atoi(season)
+	if err != nil {
+		return "", fmt.Errorf("invalid season: %q", season)
+	}
+	if episode == "" {
+		return fmt.Sprintf("Season %d", seasonNum), nil
+	}
+	episodeNum, err := strconv.Atoi(episode)
+	if err != nil {
+		return "", fmt.Errorf("invalid episode: %q", episode)
+	}
+	return fmt.Sprintf("S%02dE%02d", seasonNum, episodeNum), nil
+}
@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSerializdCSV(t *testing.T) {
+	csvData := "Show Name,Season Number,Episode Number,Rating,Date Watched\n" +
+		"The Bear,2,7,5,2023-07-01\n" +
+		"Shogun,1,,4.5,2024-04-25\n" +
+		"Broken,x,1,,2024-05-01\n"
+
+	result, err := ParseSerializdCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 4 {
+		t.Errorf("Expected 1 error on line 4, got %+v", result.Errors)
+	}
+
+	if got := result.Records[0].Item; got.Title != "The Bear" || got.EpisodeInfo != "S02E07" {
+		t.Errorf("Unexpected episode: %+v", got)
+	}
+	if got := result.Records[1].Item; got.EpisodeInfo != "Season 1" || got.DurationMinutes != trackerEstimate.EpisodeMinutes {
+		t.Errorf("Expected a season log counted as one episode, got %+v", got)
+	}
+}
+
+func TestParseSerializdCSVMissingColumns(t *testing.T) {
+	if _, err := ParseSerializdCSV(strings.NewReader("Season,Episode\n1,2\n")); err == nil {
+		t.Error("Expected an error when the show and date columns are missing")
+	}
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// trackerEstimate fills in runtimes that tracker exports leave out
+var trackerEstimate = estimate.Fixed{EpisodeMinutes: 40, FilmMinutes: 105}
+
+// csvTable is a CSV export with columns looked up by header name
+type csvTable struct {
+	reader *csv.Reader
+	cols   map[string]int
+	line   int // Line of the row being read, counting the header as 1
+}
+
+// newCSVTable reads the header row of a CSV export
+func newCSVTable(r io.Reader) (*csvTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return &csvTable{reader: reader, cols: cols, line: 1}, nil
+}
+
+// column returns the first of names present in the header, or "" if none are
+func (t *csvTable) column(names ...string) string {
+	for _, name := range names {
+		if _, ok := t.cols[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// field returns a record's value in the named column
+func (t *csvTable) field(record []string, name string) string {
+	if i, ok := t.cols[name]; ok && i < len(record) {
+		return strings.TrimSpace(record[i])
+	}
+	return ""
+}
+
+// each calls fn with every data row and its line number, collecting rows fn
+// rejects as RowErrors. fn returns a nil record to skip a row silently.
+func (t *csvTable) each(fn func(record []string) (*Record, error)) *ParseResult {
+	result := &ParseResult{}
+	for {
+		record, err := t.reader.Read()
+		if err == io.EOF {
+			break
+		}
+		t.line++
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: t.line, Error: err.Error()})
+			continue
+		}
+
+		rec, err := fn(record)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: t.line, Error: err.Error()})
+			continue
+		}
+		if rec != nil {
+			rec.Line = t.line
+			result.Records = append(result.Records, *rec)
+		}
+	}
+	return result
+}
+
+// parseTrackerDate parses the date formats trackers write in their exports
+func parseTrackerDate(dateStr string) (time.Time, error) {
+	dateStr = strings.TrimSpace(dateStr)
+
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05.000Z",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"1/2/2006",
+		"Jan 2, 2006",
+		"January 2, 2006",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, dateStr); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
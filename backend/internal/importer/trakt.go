@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// traktHistoryItem is one play in a Trakt history export, as returned by
+// /sync/history (optionally with ?extended=full, which adds runtimes)
+type traktHistoryItem struct {
+	WatchedAt string `json:"watched_at"`
+	Type      string `json:"type"` // "movie" or "episode"
+	Movie     *struct {
+		Title   string   `json:"title"`
+		Runtime int      `json:"runtime"`
+		IDs     traktIDs `json:"ids"`
+	} `json:"movie"`
+	Show *struct {
+		Title   string   `json:"title"`
+		Runtime int      `json:"runtime"`
+		IDs     traktIDs `json:"ids"`
+	} `json:"show"`
+	Episode *struct {
+		Season  int `json:"season"`
+		Number  int `json:"number"`
+		Runtime int `json:"runtime"`
+	} `json:"episode"`
+}
+
+// traktIDs identifies a Trakt movie or show in other databases
+type traktIDs struct {
+	TMDB int64 `json:"tmdb"`
+}
+
+// ParseTraktHistory parses a Trakt watch history JSON export. Runtimes are
+// taken from the export when present (episode, then show) and estimated
+// otherwise. Line numbers are the item's position in the array.
+func ParseTraktHistory(r io.Reader) (*ParseResult, error) {
+	var items []traktHistoryItem
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("failed to decode Trakt history: %w", err)
+	}
+
+	result := &ParseResult{}
+	for i, entry := range items {
+		line := i + 1
+		item, err := parseTraktItem(entry)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+		result.Records = append(result.Records, Record{Line: line, Item: item, TMDBID: entry.tmdbID()})
+	}
+
+	return result, nil
+}
+
+// tmdbID returns the TMDB ID of the play's movie or show, or 0 if Trakt has none
+func (entry traktHistoryItem) tmdbID() int64 {
+	switch {
+	case entry.Type == "movie" && entry.Movie != nil:
+		return entry.Movie.IDs.TMDB
+	case entry.Type == "episode" && entry.Show != nil:
+		return entry.Show.IDs.TMDB
+	}
+	return 0
+}
+
+// parseTraktItem converts a single Trakt play to a watch history entry
+func parseTraktItem(entry traktHistoryItem) (database.WatchHistory, error) {
+	var item database.WatchHistory
+
+	watchedAt, err := parseTrackerDate(entry.WatchedAt)
+	if err != nil {
+		return item, err
+	}
+	item.WatchedAt = watchedAt
+
+	switch entry.Type {
+	case "movie":
+		if entry.Movie == nil {
+			return item, fmt.Errorf("movie entry without a movie")
+		}
+		item.Title = strings.TrimSpace(entry.Movie.Title)
+		item.DurationMinutes = entry.Movie.Runtime
+	case "episode":
+		if entry.Show == nil || entry.Episode == nil {
+			return item, fmt.Errorf("episode entry without a show or episode")
+		}
+		item.Title = strings.TrimSpace(entry.Show.Title)
+		item.EpisodeInfo = fmt.Sprintf("S%02dE%02d", entry.Episode.Season, entry.Episode.Number)
+		item.DurationMinutes = entry.Episode.Runtime
+		if item.DurationMinutes == 0 {
+			item.DurationMinutes = entry.Show.Runtime
+		}
+	default:
+		return item, fmt.Errorf("unsupported entry type %q", entry.Type)
+	}
+
+	if item.Title == "" {
+		return item, fmt.Errorf("empty title")
+	}
+	if item.DurationMinutes == 0 {
+		item.DurationMinutes = trackerEstimate.Estimate(item.Title, item.EpisodeInfo)
+	}
+
+	return item, nil
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTraktHistory(t *testing.T) {
+	export := `[
+		{"id": 1, "watched_at": "2024-02-10T21:15:00.000Z", "action": "watch", "type": "episode",
+		 "episode": {"season": 1, "number": 3, "title": "The Tell", "runtime": 47},
+		 "show": {"title": "Slow Horses", "year": 2022, "ids": {"trakt": 158361, "tmdb": 95480}}},
+		{"id": 2, "watched_at": "2024-02-11T20:00:00.000Z", "action": "scrobble", "type": "movie",
+		 "movie": {"title": "Dune: Part Two", "year": 2024}},
+		{"id": 3, "watched_at": "not a date", "type": "movie", "movie": {"title": "Broken"}},
+		{"id": 4, "watched_at": "2024-02-12T20:00:00.000Z", "type": "episode", "show": {"title": "No Episode"}}
+	]`
+
+	result, err := ParseTraktHistory(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Line != 3 || result.Errors[1].Line != 4 {
+		t.Errorf("Expected errors for items 3 and 4, got %+v", result.Errors)
+	}
+
+	episode := result.Records[0].Item
+	if episode.Title != "Slow Horses" || episode.EpisodeInfo != "S01E03" || episode.DurationMinutes != 47 {
+		t.Errorf("Unexpected episode: %+v", episode)
+	}
+	if id := result.Records[0].TMDBID; id != 95480 {
+		t.Errorf("Expected the show's TMDB ID, got %d", id)
+	}
+
+	movie := result.Records[1].Item
+	if movie.Title != "Dune: Part Two" || movie.EpisodeInfo != "" {
+		t.Errorf("Unexpected movie: %+v", movie)
+	}
+	if movie.DurationMinutes != trackerEstimate.FilmMinutes {
+		t.Errorf("Expected the estimated film length for a movie without a runtime, got %d", movie.DurationMinutes)
+	}
+}
+
+func TestParseTraktHistoryInvalidJSON(t *testing.T) {
+	if _, err := ParseTraktHistory(strings.NewReader("Title,Date\n")); err == nil {
+		t.Error("Expected an error for a file that isn't JSON")
+	}
+}
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/reconcile"
+)
+
+// UsageSummary reports the outcome of importing app usage from devices,
+// which only know which app was open and for how long
+type UsageSummary struct {
+	DryRun   bool         `json:"dry_run"`
+	Imported int          `json:"imported"`
+	Scraped  int          `json:"scraped"`  // Skipped because they overlap history read from the service
+	Merged   int          `json:"merged"`   // Overlapping inferred sessions merged after storing
+	Unmapped []string     `json:"unmapped"` // Apps with no matching service
+	Errors   []RowError   `json:"errors"`
+	Preview  []PreviewRow `json:"preview"`
+}
+
+// usageEntry is time spent in an app, stored as one estimated entry
+type usageEntry struct {
+	Line    int
+	App     string
+	Device  string
+	At      time.Time
+	Minutes int
+	// Confidence defaults to database.ConfidenceMedium
+	Confidence string
+}
+
+// applyUsage stores app usage as estimated entries with the given title under
+// the service each app maps to. Entries are upserted by time, so posting the
+// same usage again replaces it. Entries overlapping scraped or imported
+// history are skipped, since app usage is only a fallback for services that
+// can't be scraped, and stored entries are then reconciled with other
+// inferred sessions they overlap. Daily totals overlap their whole day.
+func applyUsage(db *database.DB, entries []usageEntry, title string, serviceFor func(app string) (string, bool), opts Options) (*UsageSummary, error) {
+	summary := &UsageSummary{
+		DryRun:   opts.DryRun,
+		Unmapped: []string{},
+		Errors:   []RowError{},
+		Preview:  []PreviewRow{},
+	}
+	unmapped := make(map[string]bool)
+	var batch []database.WatchHistory
+
+	for _, u := range entries {
+		if u.Minutes == 0 {
+			continue
+		}
+
+		serviceName, ok := serviceFor(u.App)
+		var service *database.Service
+		if ok {
+			var err error
+			if service, err = db.GetServiceByName(serviceName); err != nil {
+				return nil, fmt.Errorf("failed to look up service on line %d: %w", u.Line, err)
+			}
+		}
+		if service == nil {
+			if !unmapped[u.App] {
+				unmapped[u.App] = true
+				summary.Unmapped = append(summary.Unmapped, u.App)
+			}
+			continue
+		}
+
+		confidence := u.Confidence
+		if confidence == "" {
+			confidence = database.ConfidenceMedium
+		}
+		item := database.WatchHistory{
+			ServiceID:       service.ID,
+			Title:           title,
+			DurationMinutes: u.Minutes,
+			WatchedAt:       u.At,
+			Device:          u.Device,
+			Confidence:      confidence,
+		}
+
+		start, end := reconcile.Span(item)
+		scraped, err := db.HasWatchHistoryDuring(service.ID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check history on line %d: %w", u.Line, err)
+		}
+		if scraped {
+			summary.Scraped++
+			continue
+		}
+
+		if len(summary.Preview) < opts.PreviewRows {
+			summary.Preview = append(summary.Preview, PreviewRow{
+				Line:            u.Line,
+				Title:           service.Name,
+				WatchedAt:       item.WatchedAt,
+				DurationMinutes: item.DurationMinutes,
+			})
+		}
+
+		if opts.DryRun {
+			continue
+		}
+		batch = append(batch, item)
+	}
+
+	if err := db.InsertWatchHistoryBatch(batch); err != nil {
+		return nil, fmt.Errorf("failed to store usage: %w", err)
+	}
+	summary.Imported = len(batch)
+
+	if len(batch) > 0 {
+		earliest := batch[0].WatchedAt
+		for _, item := range batch[1:] {
+			if item.WatchedAt.Before(earliest) {
+				earliest = item.WatchedAt
+			}
+		}
+		// Sessions from the day before may run into the new ones
+		merged, err := reconcile.Run(db, earliest.AddDate(0, 0, -1), false)
+		if err != nil {
+			return nil, err
+		}
+		summary.Merged = merged.Removed
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// Services Takeout watch history is attributed to
+const (
+	YouTubeService   = "YouTube"
+	YouTubeTVService = "YouTube TV"
+)
+
+// takeoutEntry is one activity in a Google Takeout watch-history.json
+type takeoutEntry struct {
+	Header   string `json:"header"` // "YouTube", "YouTube TV" or "YouTube Music"
+	Title    string `json:"title"`  // e.g. "Watched Some Video"
+	TitleURL string `json:"titleUrl"`
+	Time     string `json:"time"`
+	Details  []struct {
+		Name string `json:"name"` // "From Google Ads" for ads
+	} `json:"details"`
+}
+
+// TakeoutOptions chooses which Takeout entries are imported
+type TakeoutOptions struct {
+	SkipAds    bool // Leave out ads shown from Google Ads
+	SkipShorts bool // Leave out Shorts, recognized by their URL or a #shorts title
+}
+
+// ParseYouTubeTakeout parses the watch-history.json of a Google Takeout
+// export. Entries are attributed to YouTube TV when they come from it and to
+// YouTube otherwise. Takeout has no durations, so they're estimated, with
+// ads and Shorts counted as a minute. Removed and private videos, which have
+// no title or link left, are skipped. Line numbers are the entry's position
+// in the array.
+func ParseYouTubeTakeout(r io.Reader, opts TakeoutOptions) (*ParseResult, error) {
+	var entries []takeoutEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode Takeout watch history: %w", err)
+	}
+
+	result := &ParseResult{}
+	for i, entry := range entries {
+		line := i + 1
+		title, watched := strings.CutPrefix(strings.TrimSpace(entry.Title), "Watched ")
+		if !watched || entry.TitleURL == "" {
+			continue
+		}
+
+		watchedAt, err := parseTrackerDate(entry.Time)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		ad, short := entry.isAd(), entry.isShort()
+		if (ad && opts.SkipAds) || (short && opts.SkipShorts) {
+			continue
+		}
+
+		service, estimator := YouTubeService, estimate.YouTube
+		if entry.isYouTubeTV() {
+			service, estimator = YouTubeTVService, estimate.YouTubeTV
+		}
+		item := database.WatchHistory{Title: strings.TrimSpace(title), WatchedAt: watchedAt}
+		item.DurationMinutes = estimator.Estimate(item.Title, "")
+		if ad || short {
+			item.DurationMinutes = 1
+		}
+
+		result.Records = append(result.Records, Record{Line: line, Item: item, Service: service})
+	}
+
+	return result, nil
+}
+
+// isAd reports whether the entry is an ad rather than a video chosen to watch
+func (e takeoutEntry) isAd() bool {
+	for _, d := range e.Details {
+		if d.Name == "From Google Ads" {
+			return true
+		}
+	}
+	return false
+}
+
+// isShort reports whether the entry is a YouTube Short. Takeout usually links
+// Shorts as regular videos, so only those linked or tagged as Shorts are found.
+func (e takeoutEntry) isShort() bool {
+	return strings.Contains(e.TitleURL, "/shorts/") || strings.Contains(strings.ToLower(e.Title), "#shorts")
+}
+
+// isYouTubeTV reports whether the entry was watched on YouTube TV
+func (e takeoutEntry) isYouTubeTV() bool {
+	if e.Header == YouTubeTVService {
+		return true
+	}
+	u, err := url.Parse(e.TitleURL)
+	return err == nil && u.Host == "tv.youtube.com"
+}
@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+const takeoutJSON = `[
+	{"header": "YouTube", "title": "Watched How Tides Work", "titleUrl": "https://www.youtube.com/watch?v=abc", "time": "2024-03-01T20:15:30.123Z", "products": ["YouTube"]},
+	{"header": "YouTube TV", "title": "Watched NBA Basketball", "titleUrl": "https://tv.youtube.com/watch/xyz", "time": "2024-03-02T01:00:00Z"},
+	{"header": "YouTube", "title": "Watched Cat jumps #shorts", "titleUrl": "https://www.youtube.com/watch?v=def", "time": "2024-03-02T09:00:00Z"},
+	{"header": "YouTube", "title": "Watched Big Sale Today", "titleUrl": "https://www.youtube.com/watch?v=ghi", "time": "2024-03-02T09:05:00Z", "details": [{"name": "From Google Ads"}]},
+	{"header": "YouTube", "title": "Watched a video that has been removed", "time": "2024-03-03T10:00:00Z"},
+	{"header": "YouTube", "title": "Watched Broken Timestamp", "titleUrl": "https://www.youtube.com/watch?v=jkl", "time": "yesterday"}
+]`
+
+func TestParseYouTubeTakeout(t *testing.T) {
+	result, err := ParseYouTubeTakeout(strings.NewReader(takeoutJSON), TakeoutOptions{})
+	if err != nil {
+		t.Fatalf("Failed to parse Takeout: %v", err)
+	}
+
+	if len(result.Records) != 4 {
+		t.Fatalf("Expected 4 records, got %d", len(result.Records))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 6 {
+		t.Errorf("Expected 1 error on line 6, got %+v", result.Errors)
+	}
+
+	first := result.Records[0]
+	if first.Item.Title != "How Tides Work" || first.Service != YouTubeService {
+		t.Errorf("Unexpected first record: %+v", first)
+	}
+	if first.Item.DurationMinutes != estimate.YouTube.FilmMinutes {
+		t.Errorf("Expected the YouTube estimate, got %d", first.Item.DurationMinutes)
+	}
+	if !first.Item.WatchedAt.Equal(time.Date(2024, 3, 1, 20, 15, 30, 123000000, time.UTC)) {
+		t.Errorf("Unexpected watched at: %v", first.Item.WatchedAt)
+	}
+
+	if tv := result.Records[1]; tv.Service != YouTubeTVService || tv.Item.DurationMinutes != estimate.YouTubeTV.FilmMinutes {
+		t.Errorf("Expected a YouTube TV record, got %+v", tv)
+	}
+	for _, rec := range result.Records[2:] {
+		if rec.Item.DurationMinutes != 1 {
+			t.Errorf("Expected %q to count as a minute, got %d", rec.Item.Title, rec.Item.DurationMinutes)
+		}
+	}
+}
+
+func TestParseYouTubeTakeoutSkipsAdsAndShorts(t *testing.T) {
+	result, err := ParseYouTubeTakeout(strings.NewReader(takeoutJSON), TakeoutOptions{SkipAds: true, SkipShorts: true})
+	if err != nil {
+		t.Fatalf("Failed to parse Takeout: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Errorf("Expected ads and Shorts to be skipped, got %+v", result.Records)
+	}
+}
@@ -0,0 +1,141 @@
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// tagEscaper escapes characters with special meaning in line protocol tag values
+var tagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// Exporter writes daily watch time per service to InfluxDB as line protocol:
+//
+//	watch_time,service=Netflix minutes=95i 1741910400
+//
+// Points are keyed by day and service, so re-exporting a day overwrites it.
+type Exporter struct {
+	cfg        config.InfluxConfig
+	db         *database.DB
+	httpClient *http.Client
+}
+
+// NewExporter creates an exporter for the configured InfluxDB instance
+func NewExporter(cfg config.InfluxConfig, db *database.DB) *Exporter {
+	return &Exporter{
+		cfg:        cfg,
+		db:         db,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// HandleResult re-exports recent days after a successful scraper run. It
+// matches the scraper manager's run listener signature.
+func (e *Exporter) HandleResult(result *scraper.Result) {
+	if !result.Success {
+		return
+	}
+
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -e.cfg.BackfillDays)
+	if err := e.Export(context.Background(), start, end); err != nil {
+		log.Printf("Influx: export failed: %v", err)
+	}
+}
+
+// Export writes one point per service per day with viewing in [start, end)
+func (e *Exporter) Export(ctx context.Context, start, end time.Time) error {
+	lines, err := e.lines(start, end)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return e.write(ctx, strings.Join(lines, "\n"))
+}
+
+// lines builds line protocol points from daily stats
+func (e *Exporter) lines(start, end time.Time) ([]string, error) {
+	services, err := e.db.GetAllServices()
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, svc := range services {
+		if !svc.Enabled {
+			continue
+		}
+		daily, err := e.db.GetDailyStats(svc.ID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get daily stats for %s: %w", svc.Name, err)
+		}
+
+		days := make([]string, 0, len(daily))
+		for day := range daily {
+			days = append(days, day)
+		}
+		sort.Strings(days)
+
+		for _, day := range days {
+			date, err := time.Parse("2006-01-02", day)
+			if err != nil {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s,service=%s minutes=%di %d",
+				e.cfg.Measurement, tagEscaper.Replace(svc.Name), daily[day], date.Unix()))
+		}
+	}
+
+	return lines, nil
+}
+
+// write posts line protocol using the v2 API when a bucket is configured and
+// the v1 API otherwise
+func (e *Exporter) write(ctx context.Context, body string) error {
+	params := url.Values{}
+	params.Set("precision", "s")
+	endpoint := strings.TrimSuffix(e.cfg.URL, "/")
+	if e.cfg.Bucket != "" {
+		endpoint += "/api/v2/write"
+		params.Set("org", e.cfg.Org)
+		params.Set("bucket", e.cfg.Bucket)
+	} else {
+		endpoint += "/write"
+		params.Set("db", e.cfg.Database)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if e.cfg.Token != "" {
+		req.Header.Set("Authorization", "Token "+e.cfg.Token)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("write request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package influx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestExport(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Apple TV")
+	db.UpdateServiceEnabled(service.ID, true)
+	day := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Severance", DurationMinutes: 50, WatchedAt: day})
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Severance", DurationMinutes: 45, WatchedAt: day.Add(time.Hour)})
+
+	var gotPath, gotQuery, gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotAuth = r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	exporter := NewExporter(config.InfluxConfig{
+		URL:         server.URL,
+		Token:       "secret",
+		Org:         "home",
+		Bucket:      "media",
+		Measurement: "watch_time",
+	}, db)
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := exporter.Export(context.Background(), start, start.AddDate(0, 1, 0)); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if gotPath != "/api/v2/write" {
+		t.Errorf("Expected v2 write path, got %s", gotPath)
+	}
+	if !strings.Contains(gotQuery, "bucket=media") || !strings.Contains(gotQuery, "precision=s") {
+		t.Errorf("Unexpected query string: %s", gotQuery)
+	}
+	if gotAuth != "Token secret" {
+		t.Errorf("Expected token auth, got '%s'", gotAuth)
+	}
+
+	expected := `watch_time,service=Apple\ TV minutes=95i 1741910400`
+	if gotBody != expected {
+		t.Errorf("Expected body '%s', got '%s'", expected, gotBody)
+	}
+}
+
+func TestExportV1Error(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(service.ID, true)
+	db.InsertWatchHistory(&database.WatchHistory{ServiceID: service.ID, Title: "Show", DurationMinutes: 30, WatchedAt: time.Now()})
+
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		http.Error(w, "database not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	exporter := NewExporter(config.InfluxConfig{URL: server.URL, Database: "media", Measurement: "watch_time"}, db)
+	err = exporter.Export(context.Background(), time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1))
+	if err == nil {
+		t.Fatal("Expected error from failed write")
+	}
+	if gotPath != "/write" {
+		t.Errorf("Expected v1 write path, got %s", gotPath)
+	}
+}
@@ -0,0 +1,9 @@
+package insights
+
+import "math"
+
+// EstimateAdMinutes estimates the commercials sat through alongside minutes
+// of content on an ad-supported tier with the given ad load per hour
+func EstimateAdMinutes(minutes int, adMinutesPerHour float64) int {
+	return int(math.Round(float64(minutes) / 60 * adMinutesPerHour))
+}
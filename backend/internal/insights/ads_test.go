@@ -0,0 +1,20 @@
+package insights
+
+import "testing"
+
+func TestEstimateAdMinutes(t *testing.T) {
+	tests := []struct {
+		minutes int
+		rate    float64
+		want    int
+	}{
+		{120, 4, 8},
+		{45, 4, 3},
+		{120, 0, 0},
+	}
+	for _, tc := range tests {
+		if got := EstimateAdMinutes(tc.minutes, tc.rate); got != tc.want {
+			t.Errorf("EstimateAdMinutes(%d, %g) = %d, want %d", tc.minutes, tc.rate, got, tc.want)
+		}
+	}
+}
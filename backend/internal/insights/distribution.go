@@ -0,0 +1,140 @@
+package insights
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Default histogram bucket lower bounds in minutes
+var (
+	DailyBuckets   = []int{0, 30, 60, 120, 180, 240}
+	SessionBuckets = []int{0, 15, 30, 60, 90, 120, 180}
+)
+
+// Bucket is one histogram bar covering [MinMinutes, MaxMinutes)
+type Bucket struct {
+	Label      string `json:"label"`
+	MinMinutes int    `json:"min_minutes"`
+	MaxMinutes int    `json:"max_minutes,omitempty"` // 0 for the open-ended last bucket
+	Count      int    `json:"count"`
+}
+
+// Distribution summarizes a set of durations
+type Distribution struct {
+	Count   int      `json:"count"`
+	Mean    float64  `json:"mean"`
+	Median  float64  `json:"median"`
+	P90     float64  `json:"p90"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+// TimedEntry is a single watch history item's start and length
+type TimedEntry struct {
+	Start   time.Time
+	Minutes int
+}
+
+// Distribute builds a histogram and percentiles for values in minutes. bounds
+// are ascending bucket lower bounds; the last bucket is open-ended.
+func Distribute(values []int, bounds []int) Distribution {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	dist := Distribution{
+		Count:   len(sorted),
+		Median:  Percentile(sorted, 50),
+		P90:     Percentile(sorted, 90),
+		Buckets: make([]Bucket, len(bounds)),
+	}
+
+	for i, min := range bounds {
+		dist.Buckets[i] = Bucket{MinMinutes: min}
+		if i+1 < len(bounds) {
+			dist.Buckets[i].MaxMinutes = bounds[i+1]
+			dist.Buckets[i].Label = fmt.Sprintf("%s-%s", formatMinutes(min), formatMinutes(bounds[i+1]))
+		} else {
+			dist.Buckets[i].Label = formatMinutes(min) + "+"
+		}
+	}
+
+	total := 0
+	for _, v := range sorted {
+		total += v
+		// Last bucket whose lower bound is <= v
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > v }) - 1
+		if i >= 0 {
+			dist.Buckets[i].Count++
+		}
+	}
+	if len(sorted) > 0 {
+		dist.Mean = math.Round(float64(total)/float64(len(sorted))*10) / 10
+	}
+
+	return dist
+}
+
+// Percentile returns the p-th percentile (0-100) of sorted values using
+// linear interpolation between closest ranks, rounded to one decimal place
+func Percentile(sorted []int, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	value := float64(sorted[lower])*(1-weight) + float64(sorted[upper])*weight
+	return math.Round(value*10) / 10
+}
+
+// DailyTotals sums minutes per calendar day, returning only days with viewing
+func DailyTotals(entries []TimedEntry) []int {
+	byDay := make(map[string]int)
+	for _, e := range entries {
+		byDay[e.Start.Format("2006-01-02")] += e.Minutes
+	}
+
+	totals := make([]int, 0, len(byDay))
+	for _, minutes := range byDay {
+		totals = append(totals, minutes)
+	}
+	return totals
+}
+
+// SessionLengths groups entries into viewing sessions: an entry starting within
+// gap of the previous entry's end continues the same session. Entries with
+// date-only timestamps therefore collapse into one session per day.
+func SessionLengths(entries []TimedEntry, gap time.Duration) []int {
+	sorted := append([]TimedEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var sessions []int
+	var sessionEnd time.Time
+	for i, e := range sorted {
+		end := e.Start.Add(time.Duration(e.Minutes) * time.Minute)
+		if i > 0 && !e.Start.After(sessionEnd.Add(gap)) {
+			sessions[len(sessions)-1] += e.Minutes
+			if end.After(sessionEnd) {
+				sessionEnd = end
+			}
+			continue
+		}
+		sessions = append(sessions, e.Minutes)
+		sessionEnd = end
+	}
+
+	return sessions
+}
+
+// formatMinutes renders a bucket bound, e.g. 30 -> "30m", 120 -> "2h"
+func formatMinutes(minutes int) string {
+	if minutes >= 60 && minutes%60 == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	if minutes > 60 {
+		return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
@@ -0,0 +1,65 @@
+package insights
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDistribute(t *testing.T) {
+	dist := Distribute([]int{240, 20, 90, 45, 90}, DailyBuckets)
+
+	if dist.Count != 5 {
+		t.Errorf("Expected count 5, got %d", dist.Count)
+	}
+	if dist.Median != 90 {
+		t.Errorf("Expected median 90, got %v", dist.Median)
+	}
+	// Rank 3.6 between 90 and 240
+	if dist.P90 != 180 {
+		t.Errorf("Expected p90 180, got %v", dist.P90)
+	}
+	if dist.Mean != 97 {
+		t.Errorf("Expected mean 97, got %v", dist.Mean)
+	}
+
+	expected := map[string]int{"0m-30m": 1, "30m-1h": 1, "1h-2h": 2, "2h-3h": 0, "3h-4h": 0, "4h+": 1}
+	for _, b := range dist.Buckets {
+		if b.Count != expected[b.Label] {
+			t.Errorf("Bucket %s: expected %d, got %d", b.Label, expected[b.Label], b.Count)
+		}
+	}
+}
+
+func TestDistributeEmpty(t *testing.T) {
+	dist := Distribute(nil, SessionBuckets)
+	if dist.Count != 0 || dist.Median != 0 || len(dist.Buckets) != len(SessionBuckets) {
+		t.Errorf("Unexpected empty distribution: %+v", dist)
+	}
+}
+
+func TestSessionLengths(t *testing.T) {
+	evening := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	entries := []TimedEntry{
+		{Start: evening.Add(50 * time.Minute), Minutes: 45}, // Starts 5 minutes after the first ends
+		{Start: evening, Minutes: 45},
+		{Start: evening.Add(3 * time.Hour), Minutes: 30}, // New session
+		{Start: evening.AddDate(0, 0, 1), Minutes: 20},
+	}
+
+	sessions := SessionLengths(entries, 30*time.Minute)
+	if len(sessions) != 3 || sessions[0] != 90 || sessions[1] != 30 || sessions[2] != 20 {
+		t.Errorf("Unexpected sessions: %v", sessions)
+	}
+}
+
+func TestDailyTotals(t *testing.T) {
+	day := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	totals := DailyTotals([]TimedEntry{
+		{Start: day, Minutes: 30},
+		{Start: day.Add(time.Hour), Minutes: 60},
+		{Start: day.AddDate(0, 0, 1), Minutes: 10},
+	})
+	if len(totals) != 2 || totals[0]+totals[1] != 100 {
+		t.Errorf("Unexpected daily totals: %v", totals)
+	}
+}
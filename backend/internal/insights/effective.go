@@ -0,0 +1,37 @@
+package insights
+
+import (
+	"math"
+	"strings"
+)
+
+// EffectiveTime estimates how much of the watch time was the content itself,
+// leaving out intros, recaps and end credits
+type EffectiveTime struct {
+	OverheadPercent float64            // Default share of every episode or movie, e.g. 8 for 8%
+	PerEpisode      map[string]float64 // Lowercased title -> overhead minutes per episode, overriding the percentage
+}
+
+// NewEffectiveTime builds an estimate from a default overhead percentage and
+// per-show overhead minutes keyed by title
+func NewEffectiveTime(overheadPercent float64, perEpisode map[string]float64) EffectiveTime {
+	e := EffectiveTime{OverheadPercent: overheadPercent, PerEpisode: make(map[string]float64)}
+	for title, minutes := range perEpisode {
+		e.PerEpisode[strings.ToLower(title)] = minutes
+	}
+	return e
+}
+
+// Enabled reports whether any overhead is configured
+func (e EffectiveTime) Enabled() bool {
+	return e.OverheadPercent > 0 || len(e.PerEpisode) > 0
+}
+
+// Minutes returns the effective content minutes of a title watched for
+// minutes over the given number of episodes
+func (e EffectiveTime) Minutes(title string, minutes, episodes int) int {
+	if overhead, ok := e.PerEpisode[strings.ToLower(title)]; ok {
+		return max(minutes-int(math.Round(overhead*float64(episodes))), 0)
+	}
+	return int(math.Round(float64(minutes) * (1 - e.OverheadPercent/100)))
+}
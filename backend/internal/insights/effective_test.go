@@ -0,0 +1,27 @@
+package insights
+
+import "testing"
+
+func TestEffectiveMinutes(t *testing.T) {
+	e := NewEffectiveTime(10, map[string]float64{"The Office": 1.5})
+
+	tests := []struct {
+		title    string
+		minutes  int
+		episodes int
+		want     int
+	}{
+		{"Severance", 100, 2, 90},
+		{"the office", 44, 2, 41},
+		{"The Office", 2, 2, 0},
+	}
+	for _, tc := range tests {
+		if got := e.Minutes(tc.title, tc.minutes, tc.episodes); got != tc.want {
+			t.Errorf("Minutes(%q, %d, %d) = %d, want %d", tc.title, tc.minutes, tc.episodes, got, tc.want)
+		}
+	}
+
+	if (EffectiveTime{}).Enabled() {
+		t.Error("Expected no adjustment without any overhead configured")
+	}
+}
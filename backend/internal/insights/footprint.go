@@ -0,0 +1,43 @@
+package insights
+
+import (
+	"math"
+	"strings"
+)
+
+// gbPerHour is the approximate data used per hour of streaming at each resolution
+var gbPerHour = map[string]float64{
+	"sd": 0.7,
+	"hd": 3.0,
+	"4k": 7.0,
+}
+
+// Footprint is the estimated data usage and energy for an amount of watch time
+type Footprint struct {
+	Resolution string  `json:"resolution"`
+	DataGB     float64 `json:"data_gb"`
+	EnergyKWh  float64 `json:"energy_kwh"`
+}
+
+// EstimateFootprint converts watch minutes into estimated data usage and energy.
+// Unknown resolutions fall back to HD.
+func EstimateFootprint(minutes int, resolution string, kwhPerHour float64) Footprint {
+	resolution = strings.ToLower(resolution)
+	rate, ok := gbPerHour[resolution]
+	if !ok {
+		resolution = "hd"
+		rate = gbPerHour["hd"]
+	}
+
+	hours := float64(minutes) / 60
+	return Footprint{
+		Resolution: resolution,
+		DataGB:     round2(hours * rate),
+		EnergyKWh:  round2(hours * kwhPerHour),
+	}
+}
+
+// round2 rounds to two decimal places
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
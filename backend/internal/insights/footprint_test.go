@@ -0,0 +1,34 @@
+package insights
+
+import "testing"
+
+func TestEstimateFootprint(t *testing.T) {
+	tests := []struct {
+		name       string
+		minutes    int
+		resolution string
+		wantRes    string
+		wantGB     float64
+		wantKWh    float64
+	}{
+		{"two hours of HD", 120, "hd", "hd", 6.0, 0.16},
+		{"one hour of 4K", 60, "4K", "4k", 7.0, 0.08},
+		{"half hour of SD", 30, "sd", "sd", 0.35, 0.04},
+		{"unknown resolution falls back to HD", 60, "8k", "hd", 3.0, 0.08},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateFootprint(tt.minutes, tt.resolution, 0.08)
+			if got.Resolution != tt.wantRes {
+				t.Errorf("Expected resolution %s, got %s", tt.wantRes, got.Resolution)
+			}
+			if got.DataGB != tt.wantGB {
+				t.Errorf("Expected %v GB, got %v", tt.wantGB, got.DataGB)
+			}
+			if got.EnergyKWh != tt.wantKWh {
+				t.Errorf("Expected %v kWh, got %v", tt.wantKWh, got.EnergyKWh)
+			}
+		})
+	}
+}
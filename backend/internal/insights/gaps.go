@@ -0,0 +1,108 @@
+package insights
+
+import (
+	"time"
+)
+
+// GapOptions controls how suspicious gaps in watch history are detected
+type GapOptions struct {
+	MinGapDays     int     // Minimum run of empty days to consider a gap
+	WindowDays     int     // Days before and after the gap used to measure regular viewing
+	MinActiveRatio float64 // Fraction of window days that must have viewing on both sides
+}
+
+// DefaultGapOptions returns the default gap detection settings
+func DefaultGapOptions() GapOptions {
+	return GapOptions{
+		MinGapDays:     7,
+		WindowDays:     14,
+		MinActiveRatio: 0.5,
+	}
+}
+
+// Gap describes a run of days with no watch history surrounded by regular viewing
+type Gap struct {
+	Start            string `json:"start"` // First empty day (YYYY-MM-DD)
+	End              string `json:"end"`   // Last empty day (YYYY-MM-DD)
+	Days             int    `json:"days"`
+	ActiveDaysBefore int    `json:"active_days_before"`
+	ActiveDaysAfter  int    `json:"active_days_after"`
+}
+
+// DetectGaps finds runs of empty days in daily stats that are likely caused by
+// scraper failures (e.g., expired cookies) rather than genuine breaks in viewing.
+// dailyStats maps "YYYY-MM-DD" to minutes watched, as returned by GetDailyStats.
+func DetectGaps(dailyStats map[string]int, start, end time.Time, opts GapOptions) []Gap {
+	if opts.MinGapDays <= 0 || opts.WindowDays <= 0 {
+		return nil
+	}
+
+	// Build an ordered list of days and whether each had any viewing
+	start = truncateDay(start)
+	end = truncateDay(end)
+	var days []time.Time
+	var active []bool
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+		active = append(active, dailyStats[d.Format("2006-01-02")] > 0)
+	}
+
+	var gaps []Gap
+	i := 0
+	for i < len(days) {
+		if active[i] {
+			i++
+			continue
+		}
+
+		// Find the end of this run of empty days
+		j := i
+		for j < len(days) && !active[j] {
+			j++
+		}
+		runLength := j - i
+
+		// Gaps touching either edge of the range can't be judged on both sides
+		if runLength >= opts.MinGapDays && i > 0 && j < len(days) {
+			before := countActive(active, i-opts.WindowDays, i)
+			after := countActive(active, j, j+opts.WindowDays)
+			threshold := int(float64(opts.WindowDays)*opts.MinActiveRatio + 0.5)
+
+			if before >= threshold && after >= threshold {
+				gaps = append(gaps, Gap{
+					Start:            days[i].Format("2006-01-02"),
+					End:              days[j-1].Format("2006-01-02"),
+					Days:             runLength,
+					ActiveDaysBefore: before,
+					ActiveDaysAfter:  after,
+				})
+			}
+		}
+
+		i = j
+	}
+
+	return gaps
+}
+
+// countActive counts active days in active[from:to], clamped to the slice bounds
+func countActive(active []bool, from, to int) int {
+	if from < 0 {
+		from = 0
+	}
+	if to > len(active) {
+		to = len(active)
+	}
+	count := 0
+	for k := from; k < to; k++ {
+		if active[k] {
+			count++
+		}
+	}
+	return count
+}
+
+// truncateDay returns midnight UTC of the given day
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,80 @@
+package insights
+
+import (
+	"testing"
+	"time"
+)
+
+// dailyViewing builds daily stats with viewing on every day in [start, end) except the skipped range
+func dailyViewing(start, end time.Time, skipFrom, skipTo time.Time) map[string]int {
+	stats := make(map[string]int)
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if !d.Before(skipFrom) && d.Before(skipTo) {
+			continue
+		}
+		stats[d.Format("2006-01-02")] = 60
+	}
+	return stats
+}
+
+func TestDetectGapsFindsSuspiciousGap(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	skipFrom := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	skipTo := time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)
+
+	gaps := DetectGaps(dailyViewing(start, end, skipFrom, skipTo), start, end, DefaultGapOptions())
+
+	if len(gaps) != 1 {
+		t.Fatalf("Expected 1 gap, got %d", len(gaps))
+	}
+	if gaps[0].Start != "2025-01-20" || gaps[0].End != "2025-01-29" {
+		t.Errorf("Expected gap 2025-01-20..2025-01-29, got %s..%s", gaps[0].Start, gaps[0].End)
+	}
+	if gaps[0].Days != 10 {
+		t.Errorf("Expected 10 day gap, got %d", gaps[0].Days)
+	}
+}
+
+func TestDetectGapsIgnoresShortGaps(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	skipFrom := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	skipTo := time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC)
+
+	gaps := DetectGaps(dailyViewing(start, end, skipFrom, skipTo), start, end, DefaultGapOptions())
+
+	if len(gaps) != 0 {
+		t.Errorf("Expected no gaps for a 3 day break, got %d", len(gaps))
+	}
+}
+
+func TestDetectGapsIgnoresSparseViewing(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Only watch something once every ten days
+	stats := make(map[string]int)
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 10) {
+		stats[d.Format("2006-01-02")] = 45
+	}
+
+	gaps := DetectGaps(stats, start, end, DefaultGapOptions())
+
+	if len(gaps) != 0 {
+		t.Errorf("Expected no gaps for sparse viewer, got %d", len(gaps))
+	}
+}
+
+func TestDetectGapsIgnoresGapAtEdge(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	skipFrom := time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	// Gap runs to the end of the range, e.g. nothing scraped recently
+	gaps := DetectGaps(dailyViewing(start, end, skipFrom, end), start, end, DefaultGapOptions())
+
+	if len(gaps) != 0 {
+		t.Errorf("Expected no gaps when gap touches range edge, got %d", len(gaps))
+	}
+}
@@ -0,0 +1,173 @@
+package insights
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// episodePatterns match season and episode numbers in episode info such as
+// "S02E05", "S2:E5" or "Season 2: Episode 5"
+var episodePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bS(\d+)\s*:?\s*E(\d+)\b`),
+	regexp.MustCompile(`(?i)Season\s+(\d+).*Episode\s+(\d+)`),
+}
+
+// ParseEpisode reads the season and episode numbers from episode info
+func ParseEpisode(info string) (season, episode int, ok bool) {
+	for _, pattern := range episodePatterns {
+		if m := pattern.FindStringSubmatch(info); m != nil {
+			season, _ = strconv.Atoi(m[1])
+			episode, _ = strconv.Atoi(m[2])
+			return season, episode, true
+		}
+	}
+	return 0, 0, false
+}
+
+// ShowEpisodes is one show's watched episodes alongside its air dates
+type ShowEpisodes struct {
+	Title    string
+	Watched  []database.WatchedEpisode
+	AirDates []database.EpisodeAirDate
+	Latest   *database.EpisodeAirDate // Most recently aired episode, if any
+}
+
+// ShowLatency is how soon after airing a show's new episodes were watched
+type ShowLatency struct {
+	Title             string  `json:"title"`
+	AverageDaysBehind float64 `json:"average_days_behind"`
+	Episodes          int     `json:"episodes"`          // Watched within the window of airing
+	CatchUpEpisodes   int     `json:"catch_up_episodes"` // Watched later, e.g. binging an older season
+}
+
+// BehindShow is a followed show with aired episodes that haven't been watched
+type BehindShow struct {
+	Title          string `json:"title"`
+	LastWatched    string `json:"last_watched"` // e.g. "S02E03"
+	LatestAired    string `json:"latest_aired"`
+	LatestAirDate  string `json:"latest_air_date"`
+	EpisodesBehind int    `json:"episodes_behind"`
+}
+
+// EpisodeLatency measures how many days after airing each show's episodes
+// were first watched. Only episodes watched within windowDays of airing
+// count as keeping up with new episodes; later ones are catch-up viewing
+// and left out of the averages. It also returns the shows being followed,
+// with a new episode watched within the window or any episode watched
+// in the last windowDays, that have since aired episodes beyond the last
+// one watched. The overall average covers every new episode.
+func EpisodeLatency(shows []ShowEpisodes, now time.Time, windowDays int) ([]ShowLatency, []BehindShow, float64) {
+	latencies := []ShowLatency{}
+	behind := []BehindShow{}
+	totalDays, totalEpisodes := 0.0, 0
+
+	for _, show := range shows {
+		aired := make(map[[2]int]time.Time)
+		for _, ad := range show.AirDates {
+			if date, err := time.Parse("2006-01-02", ad.AirDate); err == nil {
+				aired[[2]int{ad.Season, ad.Episode}] = date
+			}
+		}
+
+		latency := ShowLatency{Title: show.Title}
+		days := 0.0
+		var last [2]int
+		var lastWatched time.Time
+		for _, ep := range show.Watched {
+			season, episode, ok := ParseEpisode(ep.EpisodeInfo)
+			if !ok {
+				continue
+			}
+			key := [2]int{season, episode}
+			if later(key, last) {
+				last = key
+			}
+			if ep.FirstWatched.After(lastWatched) {
+				lastWatched = ep.FirstWatched
+			}
+
+			airDate, ok := aired[key]
+			if !ok {
+				continue
+			}
+			// Watching before the air date means a timezone or early release
+			behindBy := math.Max(ep.FirstWatched.Sub(airDate).Hours()/24, 0)
+			if behindBy > float64(windowDays) {
+				latency.CatchUpEpisodes++
+				continue
+			}
+			latency.Episodes++
+			days += behindBy
+		}
+
+		if latency.Episodes > 0 {
+			latency.AverageDaysBehind = roundTenth(days / float64(latency.Episodes))
+			totalDays += days
+			totalEpisodes += latency.Episodes
+		}
+		if latency.Episodes > 0 || latency.CatchUpEpisodes > 0 {
+			latencies = append(latencies, latency)
+		}
+
+		following := latency.Episodes > 0 || now.Sub(lastWatched) <= time.Duration(windowDays)*24*time.Hour
+		if show.Latest == nil || last == [2]int{} || !following {
+			continue
+		}
+		latest := [2]int{show.Latest.Season, show.Latest.Episode}
+		if !later(latest, last) {
+			continue
+		}
+		missed := 0
+		for key, airDate := range aired {
+			if later(key, last) && !later(key, latest) && !airDate.After(now) {
+				missed++
+			}
+		}
+		behind = append(behind, BehindShow{
+			Title:          show.Title,
+			LastWatched:    episodeLabel(last),
+			LatestAired:    episodeLabel(latest),
+			LatestAirDate:  show.Latest.AirDate,
+			EpisodesBehind: max(missed, 1),
+		})
+	}
+
+	// Shows with the most new episodes first, and the ones furthest behind
+	sort.SliceStable(latencies, func(i, j int) bool {
+		if latencies[i].Episodes != latencies[j].Episodes {
+			return latencies[i].Episodes > latencies[j].Episodes
+		}
+		return latencies[i].CatchUpEpisodes > latencies[j].CatchUpEpisodes
+	})
+	sort.SliceStable(behind, func(i, j int) bool {
+		return behind[i].EpisodesBehind > behind[j].EpisodesBehind
+	})
+
+	average := 0.0
+	if totalEpisodes > 0 {
+		average = roundTenth(totalDays / float64(totalEpisodes))
+	}
+	return latencies, behind, average
+}
+
+// later reports whether season and episode a comes after b
+func later(a, b [2]int) bool {
+	if a[0] != b[0] {
+		return a[0] > b[0]
+	}
+	return a[1] > b[1]
+}
+
+func episodeLabel(key [2]int) string {
+	return fmt.Sprintf("S%02dE%02d", key[0], key[1])
+}
+
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,75 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestParseEpisode(t *testing.T) {
+	for _, tc := range []struct {
+		info            string
+		season, episode int
+		ok              bool
+	}{
+		{"S02E05", 2, 5, true},
+		{"s1:e12", 1, 12, true},
+		{"Season 3: Episode 7 \"The Trip\"", 3, 7, true},
+		{"Chapter One", 0, 0, false},
+	} {
+		season, episode, ok := ParseEpisode(tc.info)
+		if season != tc.season || episode != tc.episode || ok != tc.ok {
+			t.Errorf("%q: expected %d, %d, %v, got %d, %d, %v", tc.info, tc.season, tc.episode, tc.ok, season, episode, ok)
+		}
+	}
+}
+
+func TestEpisodeLatency(t *testing.T) {
+	now := time.Date(2025, 3, 25, 12, 0, 0, 0, time.UTC)
+	day := func(d string, hours int) time.Time {
+		date, _ := time.Parse("2006-01-02", d)
+		return date.Add(time.Duration(hours) * time.Hour)
+	}
+
+	shows := []ShowEpisodes{
+		{
+			// Watched new episodes 1 and 3 days after airing, and missed the last two
+			Title: "Severance",
+			Watched: []database.WatchedEpisode{
+				{Title: "Severance", EpisodeInfo: "S02E01", FirstWatched: day("2025-01-18", 0)},
+				{Title: "Severance", EpisodeInfo: "S02E02", FirstWatched: day("2025-01-27", 0)},
+			},
+			AirDates: []database.EpisodeAirDate{
+				{Season: 2, Episode: 1, AirDate: "2025-01-17"},
+				{Season: 2, Episode: 2, AirDate: "2025-01-24"},
+				{Season: 2, Episode: 3, AirDate: "2025-01-31"},
+				{Season: 2, Episode: 4, AirDate: "2025-02-07"},
+			},
+			Latest: &database.EpisodeAirDate{Season: 2, Episode: 4, AirDate: "2025-02-07"},
+		},
+		{
+			// A finished show binged years later is catch-up viewing, not behind
+			Title: "The Wire",
+			Watched: []database.WatchedEpisode{
+				{Title: "The Wire", EpisodeInfo: "S01E01", FirstWatched: day("2024-06-01", 0)},
+			},
+			AirDates: []database.EpisodeAirDate{{Season: 1, Episode: 1, AirDate: "2002-06-02"}},
+			Latest:   &database.EpisodeAirDate{Season: 5, Episode: 10, AirDate: "2008-03-09"},
+		},
+	}
+
+	latencies, behind, average := EpisodeLatency(shows, now, 30)
+	if len(latencies) != 2 || latencies[0].Title != "Severance" || latencies[0].Episodes != 2 || latencies[0].AverageDaysBehind != 2 {
+		t.Errorf("Expected Severance 2 days behind on average, got %+v", latencies)
+	}
+	if latencies[1].CatchUpEpisodes != 1 || latencies[1].Episodes != 0 {
+		t.Errorf("Expected The Wire as catch-up viewing, got %+v", latencies[1])
+	}
+	if average != 2 {
+		t.Errorf("Expected an overall average of 2 days, got %v", average)
+	}
+	if len(behind) != 1 || behind[0].Title != "Severance" || behind[0].EpisodesBehind != 2 || behind[0].LastWatched != "S02E02" || behind[0].LatestAired != "S02E04" {
+		t.Errorf("Expected Severance 2 episodes behind, got %+v", behind)
+	}
+}
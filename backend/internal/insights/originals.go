@@ -0,0 +1,50 @@
+package insights
+
+import "strings"
+
+// Title origin classifications
+const (
+	OriginOriginal = "original"
+	OriginLicensed = "licensed"
+	OriginUnknown  = "unknown"
+)
+
+// originalStudios lists the networks and studios whose titles count as a
+// service's own originals, matched against TMDB networks and production companies
+var originalStudios = map[string][]string{
+	"Netflix":           {"netflix"},
+	"Amazon Video":      {"amazon", "prime video"},
+	"HBO Max":           {"hbo", "max"},
+	"Apple TV":          {"apple"},
+	"Peacock":           {"peacock"},
+	"Hulu":              {"hulu"},
+	"Disney+":           {"disney", "pixar", "marvel", "lucasfilm"},
+	"YouTube TV":        {"youtube"},
+	"MUBI":              {"mubi"},
+	"Criterion Channel": {"criterion"},
+	"ESPN+":             {"espn"},
+}
+
+// ClassifyOrigin decides whether a title is one of a service's originals based
+// on its producers. found is false when the title couldn't be matched on TMDB.
+// Extra account instances such as "Netflix (kids)" use their base service.
+func ClassifyOrigin(serviceName string, producers []string, found bool) string {
+	if !found {
+		return OriginUnknown
+	}
+
+	if i := strings.Index(serviceName, " ("); i > 0 {
+		serviceName = serviceName[:i]
+	}
+
+	for _, producer := range producers {
+		producer = strings.ToLower(strings.TrimSpace(producer))
+		for _, studio := range originalStudios[serviceName] {
+			// Match whole words so "max" doesn't match "Maximum Films"
+			if producer == studio || strings.HasPrefix(producer, studio+" ") || strings.HasPrefix(producer, studio+"+") {
+				return OriginOriginal
+			}
+		}
+	}
+	return OriginLicensed
+}
@@ -0,0 +1,29 @@
+package insights
+
+import "testing"
+
+func TestClassifyOrigin(t *testing.T) {
+	tests := []struct {
+		service   string
+		producers []string
+		found     bool
+		expected  string
+	}{
+		{"Netflix", []string{"Netflix", "21 Laps Entertainment"}, true, OriginOriginal},
+		{"Netflix", []string{"NBC", "Deedle-Dee Productions"}, true, OriginLicensed},
+		{"Netflix (kids)", []string{"Netflix Animation"}, true, OriginOriginal},
+		{"Amazon Video", []string{"Amazon Studios"}, true, OriginOriginal},
+		{"Amazon Video", []string{"Prime Video"}, true, OriginOriginal},
+		{"HBO Max", []string{"Max"}, true, OriginOriginal},
+		{"HBO Max", []string{"Maximum Films"}, true, OriginLicensed},
+		{"Apple TV", []string{"Apple TV+"}, true, OriginOriginal},
+		{"Kanopy", []string{"Janus Films"}, true, OriginLicensed},
+		{"Netflix", nil, false, OriginUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyOrigin(tt.service, tt.producers, tt.found); got != tt.expected {
+			t.Errorf("ClassifyOrigin(%s, %v) = %s, expected %s", tt.service, tt.producers, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package insights
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// TrendingMatch is one entry of a trending list and whether the user watched it
+type TrendingMatch struct {
+	Rank      int    `json:"rank"`
+	Title     string `json:"title"`
+	MediaType string `json:"media_type"`
+	Watched   bool   `json:"watched"`
+	Minutes   int    `json:"minutes"`
+}
+
+// TrendingEntry is a title on a public trending list, in rank order
+type TrendingEntry struct {
+	Title     string
+	MediaType string
+}
+
+// MatchTrending marks which trending titles appear in the user's watched titles.
+// Titles are compared ignoring case, punctuation and a leading "The".
+func MatchTrending(trending []TrendingEntry, watched []database.TitleStats) ([]TrendingMatch, int) {
+	minutesByTitle := make(map[string]int)
+	for _, ts := range watched {
+		minutesByTitle[NormalizeTitle(ts.Title)] += ts.TotalMinutes
+	}
+
+	matches := make([]TrendingMatch, 0, len(trending))
+	watchedCount := 0
+	for i, entry := range trending {
+		minutes, ok := minutesByTitle[NormalizeTitle(entry.Title)]
+		if ok {
+			watchedCount++
+		}
+		matches = append(matches, TrendingMatch{
+			Rank:      i + 1,
+			Title:     entry.Title,
+			MediaType: entry.MediaType,
+			Watched:   ok,
+			Minutes:   minutes,
+		})
+	}
+
+	return matches, watchedCount
+}
+
+// NormalizeTitle reduces a title to lowercase letters, digits and single spaces,
+// without a leading "the", so the same title from different sources compares equal
+func NormalizeTitle(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		case r == '\'' || r == '’':
+			// Drop apostrophes so "Grey's" matches "Greys"
+		default:
+			space = true
+		}
+	}
+	return strings.TrimPrefix(b.String(), "the ")
+}
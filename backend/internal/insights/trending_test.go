@@ -0,0 +1,37 @@
+package insights
+
+import (
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestMatchTrending(t *testing.T) {
+	trending := []TrendingEntry{
+		{Title: "The Bear", MediaType: "tv"},
+		{Title: "Dune: Part Two", MediaType: "movie"},
+		{Title: "Grey's Anatomy", MediaType: "tv"},
+	}
+	watched := []database.TitleStats{
+		{Title: "Bear", TotalMinutes: 120},
+		{Title: "Greys Anatomy", TotalMinutes: 45},
+		{Title: "Seinfeld", TotalMinutes: 300},
+	}
+
+	matches, count := MatchTrending(trending, watched)
+	if count != 2 {
+		t.Errorf("Expected 2 watched trending titles, got %d", count)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("Expected 3 matches, got %d", len(matches))
+	}
+	if !matches[0].Watched || matches[0].Minutes != 120 || matches[0].Rank != 1 {
+		t.Errorf("Expected The Bear to match 'Bear', got %+v", matches[0])
+	}
+	if matches[1].Watched {
+		t.Errorf("Did not expect Dune to be watched, got %+v", matches[1])
+	}
+	if !matches[2].Watched {
+		t.Errorf("Expected Grey's Anatomy to match without the apostrophe, got %+v", matches[2])
+	}
+}
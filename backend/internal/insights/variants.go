@@ -0,0 +1,167 @@
+package insights
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// minPrefixLength is the shortest normalized title that can match longer
+// titles by prefix, so "Up" doesn't swallow "Up in the Air"
+const minPrefixLength = 5
+
+// TitleVariant is one spelling of a title within a cluster
+type TitleVariant struct {
+	Title        string `json:"title"`
+	TotalMinutes int    `json:"total_minutes"`
+	WatchCount   int    `json:"watch_count"`
+}
+
+// AliasSuggestion proposes mapping a variant to the cluster's canonical title
+type AliasSuggestion struct {
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical"`
+}
+
+// TitleCluster is a group of titles that look like the same show or movie
+type TitleCluster struct {
+	Canonical   string            `json:"canonical"`
+	Variants    []TitleVariant    `json:"variants"`
+	Suggestions []AliasSuggestion `json:"suggestions"`
+}
+
+// ClusterTitleVariants groups titles whose normalized forms are within the
+// given similarity (0-1, from Levenshtein distance) or where one is a
+// word-boundary prefix of the other. The most watched variant of each cluster
+// is suggested as the canonical title. Clusters are ordered by total minutes.
+func ClusterTitleVariants(titles []database.TitleStats, minSimilarity float64) []TitleCluster {
+	normalized := make([]string, len(titles))
+	for i, ts := range titles {
+		normalized[i] = NormalizeTitle(ts.Title)
+	}
+
+	parent := make([]int, len(titles))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range titles {
+		if normalized[i] == "" {
+			continue
+		}
+		for j := i + 1; j < len(titles); j++ {
+			if normalized[j] == "" {
+				continue
+			}
+			if similarTitles(normalized[i], normalized[j], minSimilarity) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := make(map[int][]TitleVariant)
+	var roots []int
+	for i, ts := range titles {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], TitleVariant{
+			Title:        ts.Title,
+			TotalMinutes: ts.TotalMinutes,
+			WatchCount:   ts.WatchCount,
+		})
+	}
+
+	clusters := []TitleCluster{}
+	totals := make(map[string]int)
+	for _, root := range roots {
+		variants := groups[root]
+		if len(variants) < 2 {
+			continue
+		}
+
+		sort.SliceStable(variants, func(a, b int) bool {
+			if variants[a].TotalMinutes != variants[b].TotalMinutes {
+				return variants[a].TotalMinutes > variants[b].TotalMinutes
+			}
+			return len(variants[a].Title) < len(variants[b].Title)
+		})
+
+		cluster := TitleCluster{Canonical: variants[0].Title, Variants: variants}
+		for _, v := range variants[1:] {
+			cluster.Suggestions = append(cluster.Suggestions, AliasSuggestion{Alias: v.Title, Canonical: cluster.Canonical})
+			totals[cluster.Canonical] += v.TotalMinutes
+		}
+		totals[cluster.Canonical] += variants[0].TotalMinutes
+		clusters = append(clusters, cluster)
+	}
+
+	sort.SliceStable(clusters, func(a, b int) bool {
+		return totals[clusters[a].Canonical] > totals[clusters[b].Canonical]
+	})
+
+	return clusters
+}
+
+// similarTitles reports whether two normalized titles are likely the same
+func similarTitles(a, b string, minSimilarity float64) bool {
+	if a == b {
+		return true
+	}
+
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	if len(short) >= minPrefixLength && strings.HasPrefix(long, short+" ") {
+		return true
+	}
+
+	return TitleSimilarity(a, b) >= minSimilarity
+}
+
+// TitleSimilarity returns 1 minus the Levenshtein distance between two strings
+// divided by the longer length, so identical strings score 1
+func TitleSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,45 @@
+package insights
+
+import (
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	if s := TitleSimilarity("office", "office"); s != 1 {
+		t.Errorf("Expected identical titles to score 1, got %v", s)
+	}
+	if s := TitleSimilarity("kitten", "sitting"); s < 0.57 || s > 0.58 {
+		t.Errorf("Expected 3 edits over 7 characters, got %v", s)
+	}
+}
+
+func TestClusterTitleVariants(t *testing.T) {
+	titles := []database.TitleStats{
+		{Title: "The Office", TotalMinutes: 600, WatchCount: 30},
+		{Title: "The Office (U.S.)", TotalMinutes: 120, WatchCount: 6},
+		{Title: "office", TotalMinutes: 20, WatchCount: 1},
+		{Title: "Stranger Things", TotalMinutes: 300, WatchCount: 8},
+		{Title: "Stranger Thngs", TotalMinutes: 50, WatchCount: 1},
+		{Title: "Up", TotalMinutes: 96, WatchCount: 1},
+		{Title: "Up in the Air", TotalMinutes: 109, WatchCount: 1},
+	}
+
+	clusters := ClusterTitleVariants(titles, 0.85)
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %d: %+v", len(clusters), clusters)
+	}
+
+	office := clusters[0]
+	if office.Canonical != "The Office" || len(office.Variants) != 3 {
+		t.Errorf("Unexpected office cluster: %+v", office)
+	}
+	if len(office.Suggestions) != 2 || office.Suggestions[0].Alias != "The Office (U.S.)" {
+		t.Errorf("Unexpected suggestions: %+v", office.Suggestions)
+	}
+
+	if clusters[1].Canonical != "Stranger Things" {
+		t.Errorf("Expected typo to cluster with Stranger Things, got %+v", clusters[1])
+	}
+}
@@ -0,0 +1,268 @@
+package trakt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBaseURL is the Trakt API
+const defaultBaseURL = "https://api.trakt.tv"
+
+var (
+	// ErrAuthorizationPending is returned while the user hasn't entered the device code yet
+	ErrAuthorizationPending = errors.New("authorization pending")
+	// ErrSlowDown is returned when the device token is polled too often
+	ErrSlowDown = errors.New("polling too quickly")
+)
+
+// Client talks to the Trakt API for one API app
+type Client struct {
+	clientID     string
+	clientSecret string
+	baseURL      string
+	httpClient   *http.Client
+}
+
+// DeviceCode is the code a user enters at the verification URL to authorize
+// the app, and how long to wait between checks that they did
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"` // Seconds
+	Interval        int    `json:"interval"`   // Seconds
+}
+
+// Token is an OAuth access token for a Trakt account
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds after CreatedAt
+	CreatedAt    int64  `json:"created_at"` // Unix seconds
+}
+
+// Expires returns when the access token stops working
+func (t Token) Expires() time.Time {
+	return time.Unix(t.CreatedAt+int64(t.ExpiresIn), 0)
+}
+
+// HistoryRequest lists plays to add to a Trakt history
+type HistoryRequest struct {
+	Movies []HistoryMovie `json:"movies,omitempty"`
+	Shows  []HistoryShow  `json:"shows,omitempty"`
+}
+
+// HistoryMovie is a movie play. Trakt matches it by ID when one is given,
+// and by title otherwise.
+type HistoryMovie struct {
+	Title     string `json:"title"`
+	IDs       *IDs   `json:"ids,omitempty"`
+	WatchedAt string `json:"watched_at,omitempty"`
+}
+
+// HistoryShow is a show with the episodes played
+type HistoryShow struct {
+	Title   string          `json:"title"`
+	IDs     *IDs            `json:"ids,omitempty"`
+	Seasons []HistorySeason `json:"seasons,omitempty"`
+}
+
+// HistorySeason is a season with the episodes played
+type HistorySeason struct {
+	Number   int              `json:"number"`
+	Episodes []HistoryEpisode `json:"episodes"`
+}
+
+// HistoryEpisode is an episode play
+type HistoryEpisode struct {
+	Number    int    `json:"number"`
+	WatchedAt string `json:"watched_at"`
+}
+
+// IDs identifies a movie or show in other databases
+type IDs struct {
+	TMDB int64 `json:"tmdb,omitempty"`
+}
+
+// HistoryResponse reports what Trakt added to the history, and the movies
+// and shows it couldn't match
+type HistoryResponse struct {
+	Added struct {
+		Movies   int `json:"movies"`
+		Episodes int `json:"episodes"`
+	} `json:"added"`
+	NotFound struct {
+		Movies []HistoryMovie `json:"movies"`
+		Shows  []HistoryShow  `json:"shows"`
+	} `json:"not_found"`
+}
+
+// NewClient creates a client for the API app with the given credentials
+func NewClient(clientID, clientSecret string) *Client {
+	return &Client{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		baseURL:      defaultBaseURL,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// RequestDeviceCode starts authorizing an account with the device flow
+func (c *Client) RequestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	var code DeviceCode
+	if err := c.call(ctx, http.MethodPost, "/oauth/device/code", "", map[string]string{"client_id": c.clientID}, &code); err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// PollDeviceToken returns the account's token once the user has entered the
+// device code, and ErrAuthorizationPending or ErrSlowDown until then
+func (c *Client) PollDeviceToken(ctx context.Context, deviceCode string) (*Token, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/oauth/device/token", "", map[string]string{
+		"code":          deviceCode,
+		"client_id":     c.clientID,
+		"client_secret": c.clientSecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var token Token
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return nil, fmt.Errorf("failed to decode trakt token: %w", err)
+		}
+		return &token, nil
+	case http.StatusBadRequest:
+		return nil, ErrAuthorizationPending
+	case http.StatusTooManyRequests:
+		return nil, ErrSlowDown
+	case http.StatusNotFound, http.StatusConflict, http.StatusGone, http.StatusTeapot:
+		return nil, fmt.Errorf("device code invalid, already used, expired or denied (status %d)", resp.StatusCode)
+	default:
+		return nil, statusError(resp)
+	}
+}
+
+// RefreshToken exchanges a refresh token for a new access token
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
+	var token Token
+	err := c.call(ctx, http.MethodPost, "/oauth/token", "", map[string]string{
+		"refresh_token": refreshToken,
+		"client_id":     c.clientID,
+		"client_secret": c.clientSecret,
+		"redirect_uri":  "urn:ietf:wg:oauth:2.0:oob",
+		"grant_type":    "refresh_token",
+	}, &token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// AddHistory adds plays to the account's history
+func (c *Client) AddHistory(ctx context.Context, accessToken string, req *HistoryRequest) (*HistoryResponse, error) {
+	var result HistoryResponse
+	if err := c.call(ctx, http.MethodPost, "/sync/history", accessToken, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// History returns one page of the account's plays watched since startAt, as
+// the JSON array /sync/history returns with runtimes, along with the number
+// of pages. A zero startAt returns the whole history.
+func (c *Client) History(ctx context.Context, accessToken string, startAt time.Time, page int) ([]byte, int, error) {
+	params := url.Values{}
+	params.Set("extended", "full")
+	params.Set("limit", "100")
+	params.Set("page", strconv.Itoa(page))
+	if !startAt.IsZero() {
+		params.Set("start_at", startAt.UTC().Format(time.RFC3339))
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, "/sync/history?"+params.Encode(), accessToken, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, statusError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read trakt history: %w", err)
+	}
+
+	pages, err := strconv.Atoi(resp.Header.Get("X-Pagination-Page-Count"))
+	if err != nil {
+		pages = page
+	}
+	return data, pages, nil
+}
+
+// call sends a request and decodes a successful JSON response into out
+func (c *Client) call(ctx context.Context, method, path, accessToken string, body, out interface{}) error {
+	resp, err := c.do(ctx, method, path, accessToken, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return statusError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode trakt response: %w", err)
+	}
+	return nil
+}
+
+// do sends a request with the headers the Trakt API requires, signed with
+// accessToken when one is given
+func (c *Client) do(ctx context.Context, method, path, accessToken string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("trakt-api-version", "2")
+	req.Header.Set("trakt-api-key", c.clientID)
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("trakt request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// statusError describes an unexpected response
+func statusError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("trakt returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,355 @@
+package trakt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/importer"
+	"github.com/jgoulah/streamtime/internal/insights"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// ErrNotAuthorized is returned when syncing before a Trakt account was authorized
+var ErrNotAuthorized = errors.New("no Trakt account authorized")
+
+// pushBatchSize is how many entries are sent to Trakt per request
+const pushBatchSize = 100
+
+// refreshWindow is how long before it expires an access token is refreshed
+const refreshWindow = 24 * time.Hour
+
+// pullServiceColor is Trakt's brand red, for the service pulled history is stored under
+const pullServiceColor = "#ED1C24"
+
+// SyncResult reports the outcome of a Trakt sync
+type SyncResult struct {
+	Pushed     int `json:"pushed"`     // Plays Trakt added to its history
+	NotFound   int `json:"not_found"`  // Entries Trakt couldn't match, or without a season and episode to send
+	Pulled     int `json:"pulled"`     // Trakt plays imported
+	Duplicates int `json:"duplicates"` // Trakt plays already in the history, including ones pushed from here
+}
+
+// Syncer pushes new watch history to a Trakt account and pulls the account's
+// history in as another source. Syncs run one at a time, so entries aren't
+// pushed twice.
+type Syncer struct {
+	cfg    config.TraktConfig
+	db     *database.DB
+	client *Client
+	mu     sync.Mutex
+}
+
+// NewSyncer creates a syncer for the configured Trakt API app
+func NewSyncer(cfg config.TraktConfig, db *database.DB) *Syncer {
+	return &Syncer{
+		cfg:    cfg,
+		db:     db,
+		client: NewClient(cfg.ClientID, cfg.ClientSecret),
+	}
+}
+
+// RequestAuth starts authorizing a Trakt account. The user enters the
+// returned code at its verification URL while WaitForAuth polls for the token.
+func (s *Syncer) RequestAuth(ctx context.Context) (*DeviceCode, error) {
+	return s.client.RequestDeviceCode(ctx)
+}
+
+// WaitForAuth polls until the user enters the device code, then stores the
+// account's token. It gives up when the code expires or is denied.
+func (s *Syncer) WaitForAuth(ctx context.Context, code *DeviceCode) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+	defer cancel()
+
+	interval := time.Duration(code.Interval) * time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("device code expired before it was entered")
+		case <-time.After(interval):
+		}
+
+		token, err := s.client.PollDeviceToken(ctx, code.DeviceCode)
+		if errors.Is(err, ErrAuthorizationPending) {
+			continue
+		}
+		if errors.Is(err, ErrSlowDown) {
+			interval += time.Second
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return s.saveToken(token)
+	}
+}
+
+// HandleResult syncs after a successful scraper run, when an account is
+// authorized. It matches the scraper manager's run listener signature.
+func (s *Syncer) HandleResult(result *scraper.Result) {
+	if !result.Success {
+		return
+	}
+	synced, err := s.Sync(context.Background())
+	if errors.Is(err, ErrNotAuthorized) {
+		return
+	}
+	if err != nil {
+		log.Printf("Trakt: sync failed: %v", err)
+		return
+	}
+	if synced.Pushed > 0 || synced.Pulled > 0 {
+		log.Printf("Trakt: pushed %d plays, pulled %d", synced.Pushed, synced.Pulled)
+	}
+}
+
+// Sync pushes history that hasn't been pushed yet and pulls plays watched
+// since the last pull, as enabled by trakt.push and trakt.pull
+func (s *Syncer) Sync(ctx context.Context) (*SyncResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, err := s.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	pullService, err := s.pullService(s.cfg.Pull)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.Push {
+		if err := s.push(ctx, auth.AccessToken, pullService, result); err != nil {
+			return nil, fmt.Errorf("push failed: %w", err)
+		}
+	}
+	if s.cfg.Pull {
+		if err := s.pull(ctx, auth, pullService, result); err != nil {
+			return nil, fmt.Errorf("pull failed: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// auth returns the authorized account, refreshing its token when it's about to expire
+func (s *Syncer) auth(ctx context.Context) (*database.TraktAuth, error) {
+	auth, err := s.db.GetTraktAuth()
+	if err != nil {
+		return nil, err
+	}
+	if auth == nil {
+		return nil, ErrNotAuthorized
+	}
+	if time.Until(auth.Expires) > refreshWindow {
+		return auth, nil
+	}
+
+	token, err := s.client.RefreshToken(ctx, auth.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	if err := s.saveToken(token); err != nil {
+		return nil, err
+	}
+	return s.db.GetTraktAuth()
+}
+
+func (s *Syncer) saveToken(token *Token) error {
+	return s.db.SaveTraktAuth(&database.TraktAuth{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expires:      token.Expires(),
+	})
+}
+
+// push sends unpushed history in batches, leaving out the excluded services
+// and the one pulled history is stored under, so pulled plays aren't sent back
+func (s *Syncer) push(ctx context.Context, accessToken string, pullService *database.Service, result *SyncResult) error {
+	var exclude []int64
+	if pullService != nil {
+		exclude = append(exclude, pullService.ID)
+	}
+	for _, key := range s.cfg.ExcludeServices {
+		svc, err := s.db.GetServiceByKey(key)
+		if err != nil {
+			return err
+		}
+		if svc != nil {
+			exclude = append(exclude, svc.ID)
+		}
+	}
+
+	for {
+		items, err := s.db.GetUnpushedTraktHistory(exclude, pushBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		req, sentIDs, err := s.historyRequest(items)
+		if err != nil {
+			return err
+		}
+		notFound := map[string]bool{}
+		if len(req.Movies) > 0 || len(req.Shows) > 0 {
+			resp, err := s.client.AddHistory(ctx, accessToken, req)
+			if err != nil {
+				return err
+			}
+			result.Pushed += resp.Added.Movies + resp.Added.Episodes
+			for _, m := range resp.NotFound.Movies {
+				notFound[strings.ToLower(m.Title)] = true
+			}
+			for _, show := range resp.NotFound.Shows {
+				notFound[strings.ToLower(show.Title)] = true
+			}
+		}
+
+		found := func(item database.WatchHistory) bool {
+			_, sent := sentIDs[item.ID]
+			return sent && !notFound[strings.ToLower(item.Title)]
+		}
+		for _, item := range items {
+			if !found(item) {
+				result.NotFound++
+			}
+		}
+		if err := s.db.MarkTraktPushed(items, sentIDs, found); err != nil {
+			return err
+		}
+	}
+}
+
+// historyRequest describes entries as Trakt plays, identified by the TMDB ID
+// cached for the title when there is one. It also returns the TMDB ID each
+// entry was sent under by watch history ID, 0 for ones sent by title alone.
+// Episodes without a season and episode number can't be sent, and are left out.
+func (s *Syncer) historyRequest(items []database.WatchHistory) (*HistoryRequest, map[int64]int64, error) {
+	req := &HistoryRequest{}
+	sent := make(map[int64]int64)
+	shows := make(map[string]*HistoryShow)
+	var showOrder []string
+
+	for _, item := range items {
+		meta, err := s.db.GetTitleMetadata(item.Title)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read metadata for %s: %w", item.Title, err)
+		}
+		var ids *IDs
+		var tmdbID int64
+		if meta != nil && meta.TMDBID != 0 {
+			ids = &IDs{TMDB: meta.TMDBID}
+			tmdbID = meta.TMDBID
+		}
+		watchedAt := item.WatchedAt.UTC().Format(time.RFC3339)
+
+		if item.EpisodeInfo == "" {
+			req.Movies = append(req.Movies, HistoryMovie{Title: item.Title, IDs: ids, WatchedAt: watchedAt})
+			sent[item.ID] = tmdbID
+			continue
+		}
+
+		season, episode, ok := insights.ParseEpisode(item.EpisodeInfo)
+		if !ok {
+			continue
+		}
+		show, exists := shows[item.Title]
+		if !exists {
+			show = &HistoryShow{Title: item.Title, IDs: ids}
+			shows[item.Title] = show
+			showOrder = append(showOrder, item.Title)
+		}
+		addEpisode(show, season, HistoryEpisode{Number: episode, WatchedAt: watchedAt})
+		sent[item.ID] = tmdbID
+	}
+
+	for _, title := range showOrder {
+		req.Shows = append(req.Shows, *shows[title])
+	}
+	return req, sent, nil
+}
+
+// addEpisode adds an episode play under its season of a show
+func addEpisode(show *HistoryShow, season int, episode HistoryEpisode) {
+	for i := range show.Seasons {
+		if show.Seasons[i].Number == season {
+			show.Seasons[i].Episodes = append(show.Seasons[i].Episodes, episode)
+			return
+		}
+	}
+	show.Seasons = append(show.Seasons, HistorySeason{Number: season, Episodes: []HistoryEpisode{episode}})
+}
+
+// pullService returns the service pulled history is stored under, or nil if
+// there's none yet. With create set, a "Trakt" service is created for the
+// default key.
+func (s *Syncer) pullService(create bool) (*database.Service, error) {
+	svc, err := s.db.GetServiceByKey(s.cfg.PullService)
+	if err != nil || svc != nil || !create {
+		return svc, err
+	}
+	if s.cfg.PullService != "trakt" {
+		return nil, fmt.Errorf("trakt.pull_service %q is not a service key", s.cfg.PullService)
+	}
+
+	svc, err = s.db.EnsureService("Trakt", pullServiceColor, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Trakt service: %w", err)
+	}
+	if err := s.db.SetServiceKey(svc.Name, s.cfg.PullService); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// pull imports the plays watched since the last pull, page by page, skipping
+// ones that were pushed from here, recognized by their TMDB ID or title and time
+func (s *Syncer) pull(ctx context.Context, auth *database.TraktAuth, service *database.Service, result *SyncResult) error {
+	started := time.Now()
+	for page := 1; ; page++ {
+		data, pages, err := s.client.History(ctx, auth.AccessToken, auth.LastPulled, page)
+		if err != nil {
+			return err
+		}
+		parsed, err := importer.ParseTraktHistory(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+
+		records := parsed.Records[:0]
+		for _, rec := range parsed.Records {
+			pushed, err := s.db.WasPushedToTrakt(rec.TMDBID, rec.Item.Title, rec.Item.WatchedAt)
+			if err != nil {
+				return err
+			}
+			if pushed {
+				result.Duplicates++
+				continue
+			}
+			records = append(records, rec)
+		}
+		parsed.Records = records
+
+		summary, err := importer.Apply(s.db, service.ID, parsed, importer.Options{})
+		if err != nil {
+			return err
+		}
+		result.Pulled += summary.Imported
+		result.Duplicates += summary.Duplicates
+
+		if page >= pages {
+			break
+		}
+	}
+	return s.db.SetTraktLastPulled(started)
+}
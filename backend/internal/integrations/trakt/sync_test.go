@@ -0,0 +1,159 @@
+package trakt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func setupTestSyncer(t *testing.T, cfg config.TraktConfig, handler http.HandlerFunc) (*Syncer, *database.DB) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.ClientID, cfg.ClientSecret = "test-id", "test-secret"
+	if cfg.PullService == "" {
+		cfg.PullService = "trakt"
+	}
+	syncer := NewSyncer(cfg, db)
+	syncer.client.baseURL = server.URL
+	return syncer, db
+}
+
+func TestWaitForAuthStoresToken(t *testing.T) {
+	polls := 0
+	syncer, db := setupTestSyncer(t, config.TraktConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/device/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		polls++
+		if polls == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token": "access", "refresh_token": "refresh", "expires_in": 7776000, "created_at": %d}`, time.Now().Unix())
+	})
+	defer db.Close()
+
+	if err := syncer.WaitForAuth(context.Background(), &DeviceCode{DeviceCode: "device", ExpiresIn: 10}); err != nil {
+		t.Fatalf("WaitForAuth failed: %v", err)
+	}
+	if polls != 2 {
+		t.Errorf("Expected to poll until authorized, polled %d times", polls)
+	}
+
+	auth, err := db.GetTraktAuth()
+	if err != nil || auth == nil || auth.AccessToken != "access" || auth.RefreshToken != "refresh" {
+		t.Fatalf("Expected the token to be stored, got %+v (%v)", auth, err)
+	}
+	if time.Until(auth.Expires) < 80*24*time.Hour {
+		t.Errorf("Unexpected expiry %v", auth.Expires)
+	}
+}
+
+func TestSyncPushesAndPulls(t *testing.T) {
+	irishmanAt := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	var pushes []HistoryRequest
+	syncer, db := setupTestSyncer(t, config.TraktConfig{Push: true, Pull: true}, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" || r.Header.Get("trakt-api-key") != "test-id" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			var req HistoryRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			pushes = append(pushes, req)
+			fmt.Fprint(w, `{"added": {"movies": 1, "episodes": 1}, "not_found": {"movies": [], "shows": []}}`)
+			return
+		}
+		w.Header().Set("X-Pagination-Page-Count", "1")
+		fmt.Fprintf(w, `[
+			{"id": 1, "watched_at": %[1]q, "type": "movie", "movie": {"title": "The Irishman (2019)", "runtime": 209, "ids": {"tmdb": 398978}}},
+			{"id": 2, "watched_at": %[1]q, "type": "movie", "movie": {"title": "Knives Out", "runtime": 130, "ids": {"tmdb": 546554}}},
+			{"id": 3, "watched_at": "2025-01-16T21:00:00.000Z", "type": "episode",
+			 "show": {"title": "Severance", "runtime": 50}, "episode": {"season": 1, "number": 1, "runtime": 57}}
+		]`, irishmanAt.Format(time.RFC3339))
+	})
+	defer db.Close()
+
+	if err := db.SaveTraktAuth(&database.TraktAuth{AccessToken: "access", RefreshToken: "refresh", Expires: time.Now().AddDate(0, 3, 0)}); err != nil {
+		t.Fatalf("Failed to save auth: %v", err)
+	}
+	if err := db.SetTitleMetadata(&database.TitleMetadata{Title: "The Irishman", TMDBID: 398978, MediaType: "movie"}); err != nil {
+		t.Fatalf("Failed to save metadata: %v", err)
+	}
+	netflix, _ := db.GetServiceByName("Netflix")
+	for _, item := range []database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "The Irishman", DurationMinutes: 209, WatchedAt: irishmanAt},
+		{ServiceID: netflix.ID, Title: "Stranger Things", EpisodeInfo: "S01E02", DurationMinutes: 55, WatchedAt: irishmanAt.Add(24 * time.Hour)},
+		{ServiceID: netflix.ID, Title: "Dark", EpisodeInfo: "Chapter One", DurationMinutes: 50, WatchedAt: irishmanAt.Add(25 * time.Hour)},
+		{ServiceID: netflix.ID, Title: "Netflix", DurationMinutes: 30, WatchedAt: irishmanAt.Add(26 * time.Hour), Confidence: database.ConfidenceMedium},
+	} {
+		item := item
+		if err := db.InsertWatchHistory(&item); err != nil {
+			t.Fatalf("Failed to insert history: %v", err)
+		}
+	}
+
+	result, err := syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Pushed != 2 || result.NotFound != 1 {
+		t.Errorf("Expected 2 plays pushed and 1 without an episode number, got %+v", result)
+	}
+	// The pushed movie comes back under Trakt's own title and is matched by
+	// its TMDB ID, while another title watched in the same second isn't
+	if result.Pulled != 2 || result.Duplicates != 1 {
+		t.Errorf("Expected only the pushed movie to be skipped when pulling, got %+v", result)
+	}
+	if len(pushes) != 1 || len(pushes[0].Movies) != 1 || len(pushes[0].Shows) != 1 {
+		t.Fatalf("Expected one push of a movie and a show, got %+v", pushes)
+	}
+	if movie := pushes[0].Movies[0]; movie.IDs == nil || movie.IDs.TMDB != 398978 {
+		t.Errorf("Expected the movie to be pushed with its TMDB ID, got %+v", movie)
+	}
+	if show := pushes[0].Shows[0]; show.Title != "Stranger Things" || show.Seasons[0].Number != 1 || show.Seasons[0].Episodes[0].Number != 2 {
+		t.Errorf("Unexpected show pushed: %+v", show)
+	}
+
+	pulled, _ := db.GetServiceByKey("trakt")
+	if pulled == nil {
+		t.Fatal("Expected a Trakt service for pulled history")
+	}
+	if has, _ := db.HasWatchHistory(pulled.ID); !has {
+		t.Error("Expected pulled plays under the Trakt service")
+	}
+
+	// Nothing new to push, and pulled plays aren't sent back
+	result, err = syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if len(pushes) != 1 || result.Pushed != 0 || result.Pulled != 0 || result.Duplicates != 3 {
+		t.Errorf("Expected nothing new on the second sync, got %+v after %d pushes", result, len(pushes))
+	}
+}
+
+func TestSyncRequiresAuthorization(t *testing.T) {
+	syncer, db := setupTestSyncer(t, config.TraktConfig{Push: true}, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request to %s", r.URL.Path)
+	})
+	defer db.Close()
+
+	if _, err := syncer.Sync(context.Background()); !errors.Is(err, ErrNotAuthorized) {
+		t.Errorf("Expected ErrNotAuthorized, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Message is a single MQTT publish
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Client is a minimal MQTT 3.1.1 publisher. It connects for each batch of
+// messages and publishes them at QoS 0, which is all push-style dashboards need.
+type Client struct {
+	brokerURL string
+	username  string
+	password  string
+	clientID  string
+	timeout   time.Duration
+}
+
+// NewClient creates a client for a broker URL such as tcp://localhost:1883 or
+// ssl://broker.example.com:8883
+func NewClient(brokerURL, username, password, clientID string) *Client {
+	return &Client{
+		brokerURL: brokerURL,
+		username:  username,
+		password:  password,
+		clientID:  clientID,
+		timeout:   10 * time.Second,
+	}
+}
+
+// Publish connects to the broker, sends all messages and disconnects
+func (c *Client) Publish(ctx context.Context, messages []Message) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.timeout))
+
+	if _, err := conn.Write(c.connectPacket()); err != nil {
+		return fmt.Errorf("failed to send connect: %w", err)
+	}
+	if err := readConnAck(bufio.NewReader(conn)); err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if _, err := conn.Write(publishPacket(msg)); err != nil {
+			return fmt.Errorf("failed to publish %s: %w", msg.Topic, err)
+		}
+	}
+
+	// DISCONNECT
+	_, err = conn.Write([]byte{0xE0, 0x00})
+	return err
+}
+
+// dial opens a TCP or TLS connection to the broker
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	u, err := url.Parse(c.brokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "1883")
+		}
+		return dialer.DialContext(ctx, "tcp", host)
+	case "ssl", "tls", "mqtts":
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "8883")
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname()}}
+		return tlsDialer.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("unsupported broker scheme %q", u.Scheme)
+	}
+}
+
+// connectPacket builds a CONNECT packet with a clean session
+func (c *Client) connectPacket() []byte {
+	var flags byte = 0x02 // Clean session
+	payload := encodeString(c.clientID)
+	if c.username != "" {
+		flags |= 0x80
+		payload = append(payload, encodeString(c.username)...)
+		if c.password != "" {
+			flags |= 0x40
+			payload = append(payload, encodeString(c.password)...)
+		}
+	}
+
+	body := encodeString("MQTT")
+	body = append(body, 0x04, flags, 0x00, 0x3C) // Protocol level 4, keep alive 60s
+	body = append(body, payload...)
+	return append([]byte{0x10}, append(encodeLength(len(body)), body...)...)
+}
+
+// publishPacket builds a QoS 0 PUBLISH packet
+func publishPacket(msg Message) []byte {
+	var header byte = 0x30
+	if msg.Retain {
+		header |= 0x01
+	}
+	body := append(encodeString(msg.Topic), msg.Payload...)
+	return append([]byte{header}, append(encodeLength(len(body)), body...)...)
+}
+
+// readConnAck waits for the broker to accept the connection
+func readConnAck(r io.Reader) error {
+	packet := make([]byte, 4)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return fmt.Errorf("failed to read connack: %w", err)
+	}
+	if packet[0] != 0x20 || packet[1] != 0x02 {
+		return fmt.Errorf("unexpected packet 0x%02x waiting for connack", packet[0])
+	}
+	if packet[3] != 0 {
+		return fmt.Errorf("broker refused connection (code %d)", packet[3])
+	}
+	return nil
+}
+
+// encodeString encodes a length-prefixed UTF-8 string
+func encodeString(s string) []byte {
+	return append([]byte{byte(len(s) >> 8), byte(len(s))}, s...)
+}
+
+// encodeLength encodes the MQTT variable-length remaining length
+func encodeLength(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+)
+
+// packet is a decoded MQTT control packet received by the fake broker
+type packet struct {
+	header byte
+	body   []byte
+}
+
+// fakeBroker accepts one connection, acknowledges it with returnCode and
+// sends every packet received on the returned channel
+func fakeBroker(t *testing.T, returnCode byte) (string, <-chan packet) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	packets := make(chan packet, 100)
+	go func() {
+		defer close(packets)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			header, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			length, multiplier := 0, 1
+			for {
+				b, err := r.ReadByte()
+				if err != nil {
+					return
+				}
+				length += int(b&0x7F) * multiplier
+				multiplier *= 128
+				if b&0x80 == 0 {
+					break
+				}
+			}
+			body := make([]byte, length)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+			packets <- packet{header: header, body: body}
+
+			if header == 0x10 {
+				conn.Write([]byte{0x20, 0x02, 0x00, returnCode})
+			}
+		}
+	}()
+
+	return "tcp://" + ln.Addr().String(), packets
+}
+
+func TestPublish(t *testing.T) {
+	brokerURL, packets := fakeBroker(t, 0)
+	client := NewClient(brokerURL, "user", "pass", "streamtime")
+
+	err := client.Publish(context.Background(), []Message{
+		{Topic: "streamtime/today/minutes", Payload: []byte("95"), Retain: true},
+	})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	connect := <-packets
+	if connect.header != 0x10 {
+		t.Fatalf("Expected CONNECT, got 0x%02x", connect.header)
+	}
+	// Clean session with username and password
+	if flags := connect.body[7]; flags != 0xC2 {
+		t.Errorf("Expected connect flags 0xC2, got 0x%02x", flags)
+	}
+
+	publish := <-packets
+	if publish.header != 0x31 {
+		t.Fatalf("Expected retained PUBLISH, got 0x%02x", publish.header)
+	}
+	topicLen := int(publish.body[0])<<8 | int(publish.body[1])
+	if topic := string(publish.body[2 : 2+topicLen]); topic != "streamtime/today/minutes" {
+		t.Errorf("Expected topic streamtime/today/minutes, got %s", topic)
+	}
+	if payload := string(publish.body[2+topicLen:]); payload != "95" {
+		t.Errorf("Expected payload 95, got %s", payload)
+	}
+
+	if disconnect := <-packets; disconnect.header != 0xE0 {
+		t.Errorf("Expected DISCONNECT, got 0x%02x", disconnect.header)
+	}
+}
+
+func TestPublishRefused(t *testing.T) {
+	brokerURL, _ := fakeBroker(t, 5)
+	client := NewClient(brokerURL, "", "", "streamtime")
+
+	if err := client.Publish(context.Background(), nil); err == nil {
+		t.Error("Expected error when broker refuses the connection")
+	}
+}
+
+func TestEncodeLength(t *testing.T) {
+	tests := map[int][]byte{
+		0:     {0x00},
+		127:   {0x7F},
+		128:   {0x80, 0x01},
+		16383: {0xFF, 0x7F},
+	}
+	for n, expected := range tests {
+		got := encodeLength(n)
+		if string(got) != string(expected) {
+			t.Errorf("encodeLength(%d) = %v, expected %v", n, got, expected)
+		}
+	}
+}
+
+func TestTopicName(t *testing.T) {
+	tests := map[string]string{
+		"Netflix":           "netflix",
+		"Apple TV+":         "apple_tv",
+		"Criterion Channel": "criterion_channel",
+		"Netflix (kids)":    "netflix_kids",
+	}
+	for name, expected := range tests {
+		if got := topicName(name); got != expected {
+			t.Errorf("topicName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
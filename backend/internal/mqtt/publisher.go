@@ -0,0 +1,124 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// Publisher pushes stats and scraper status to retained MQTT topics:
+//
+//	<prefix>/today/minutes                    total minutes watched today
+//	<prefix>/services/<service>/minutes_today minutes watched today per service
+//	<prefix>/scraper/<service>/status         JSON status of the latest scraper run
+type Publisher struct {
+	client *Client
+	db     *database.DB
+	prefix string
+}
+
+// scraperStatus is the payload of a scraper status topic
+type scraperStatus struct {
+	Status       string    `json:"status"`
+	ItemsScraped int       `json:"items_scraped"`
+	Error        string    `json:"error,omitempty"`
+	RanAt        time.Time `json:"ran_at"`
+}
+
+// NewPublisher creates a publisher writing topics under prefix
+func NewPublisher(client *Client, db *database.DB, prefix string) *Publisher {
+	return &Publisher{
+		client: client,
+		db:     db,
+		prefix: strings.TrimSuffix(prefix, "/"),
+	}
+}
+
+// HandleResult publishes a scraper run's status along with refreshed daily
+// totals. It matches the scraper manager's run listener signature.
+func (p *Publisher) HandleResult(result *scraper.Result) {
+	status := scraperStatus{
+		Status:       "success",
+		ItemsScraped: result.ItemsScraped,
+		RanAt:        result.StartTime,
+	}
+	if !result.Success {
+		status.Status = "failed"
+		if result.Error != nil {
+			status.Error = result.Error.Error()
+		}
+	}
+	payload, _ := json.Marshal(status)
+
+	messages, err := p.statsMessages(time.Now())
+	if err != nil {
+		log.Printf("MQTT: failed to gather stats: %v", err)
+	}
+	messages = append(messages, Message{
+		Topic:   p.prefix + "/scraper/" + topicName(result.ServiceName) + "/status",
+		Payload: payload,
+		Retain:  true,
+	})
+
+	if err := p.client.Publish(context.Background(), messages); err != nil {
+		log.Printf("MQTT: failed to publish: %v", err)
+	}
+}
+
+// PublishStats publishes today's totals
+func (p *Publisher) PublishStats(ctx context.Context, now time.Time) error {
+	messages, err := p.statsMessages(now)
+	if err != nil {
+		return err
+	}
+	return p.client.Publish(ctx, messages)
+}
+
+// statsMessages builds the daily total and per-service minute topics
+func (p *Publisher) statsMessages(now time.Time) ([]Message, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	stats, err := p.db.GetServiceStats(today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	total := 0
+	for _, stat := range stats {
+		total += stat.TotalMinutes
+		messages = append(messages, Message{
+			Topic:   p.prefix + "/services/" + topicName(stat.ServiceName) + "/minutes_today",
+			Payload: []byte(strconv.Itoa(stat.TotalMinutes)),
+			Retain:  true,
+		})
+	}
+	messages = append(messages, Message{
+		Topic:   p.prefix + "/today/minutes",
+		Payload: []byte(strconv.Itoa(total)),
+		Retain:  true,
+	})
+
+	return messages, nil
+}
+
+// topicName turns a service name into a topic level, e.g. "Apple TV+" -> "apple_tv"
+func topicName(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/recommend"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+// LeavingCheck summarizes a run of CheckLeaving
+type LeavingCheck struct {
+	Titles   int `json:"titles"`
+	Gone     int `json:"gone"`
+	Notified int `json:"notified"`
+}
+
+// CheckLeaving refreshes the availability in a region of the titles rewatched
+// most on each service, up to limit, and sends a "leaving" notification when
+// one is no longer offered by the service it's rewatched on. TMDB's provider
+// listings (from JustWatch) carry no leaving dates, so titles are caught on
+// the first check after they drop out of a catalog. A title's first check
+// only records whether the service offers it.
+func CheckLeaving(ctx context.Context, db *database.DB, client *tmdb.Client, notifier *Notifier, region string, limit int) (*LeavingCheck, error) {
+	allTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	titles, err := db.GetTopRewatchedServiceTitles(allTime, time.Now().Add(time.Minute), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &LeavingCheck{Titles: len(titles)}
+	for _, title := range titles {
+		if ctx.Err() != nil {
+			return check, ctx.Err()
+		}
+
+		previous, err := db.GetTitleAvailability(title.Title, title.ServiceID, region)
+		if err != nil {
+			return check, err
+		}
+		current, err := lookupAvailability(ctx, client, title, region)
+		if err != nil {
+			log.Printf("Failed to look up availability of '%s': %v", title.Title, err)
+			continue
+		}
+		if current == nil {
+			continue // Not on TMDB
+		}
+
+		if !current.Available && previous != nil {
+			if previous.Available {
+				now := time.Now()
+				current.GoneSince = &now
+				message := fmt.Sprintf("%s is leaving %s", title.Title, title.ServiceName)
+				if err := notifier.Send(ctx, "leaving", title.Title, message); err != nil {
+					return check, err
+				}
+				check.Notified++
+			} else {
+				current.GoneSince = previous.GoneSince
+			}
+		}
+		if current.GoneSince != nil {
+			check.Gone++
+		}
+		if err := db.SetTitleAvailability(current); err != nil {
+			return check, err
+		}
+	}
+
+	return check, nil
+}
+
+// lookupAvailability checks on TMDB whether a title is offered by the
+// service it's rewatched on, returning nil if TMDB doesn't know the title
+func lookupAvailability(ctx context.Context, client *tmdb.Client, title database.ServiceTitleStats, region string) (*database.TitleAvailability, error) {
+	match, err := client.SearchMulti(ctx, title.Title)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil || (match.MediaType != "movie" && match.MediaType != "tv") {
+		return nil, nil
+	}
+
+	providers, err := client.WatchProviders(ctx, match.MediaType, match.ID, region)
+	if err != nil {
+		return nil, err
+	}
+	return &database.TitleAvailability{
+		Title:     title.Title,
+		ServiceID: title.ServiceID,
+		Region:    region,
+		TMDBID:    match.ID,
+		Available: recommend.Offers(providers, title.ServiceName),
+	}, nil
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestCheckLeaving(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	providers := `[{"provider_name": "Netflix"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/multi":
+			w.Write([]byte(`{"results": [{"id": 2316, "media_type": "tv", "name": "The Office"}]}`))
+		case "/tv/2316/watch/providers":
+			w.Write([]byte(`{"results": {"US": {"flatrate": ` + providers + `}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := tmdb.NewClientWithBaseURL("test_api_key", server.URL)
+	notifier := New(&config.Config{}, db)
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	now := time.Now()
+	for _, watchedAt := range []time.Time{now.AddDate(-1, 0, 0), now.AddDate(0, -1, 0)} {
+		wh := &database.WatchHistory{ServiceID: netflix.ID, Title: "The Office", EpisodeInfo: "S2E1", DurationMinutes: 22, WatchedAt: watchedAt}
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert history: %v", err)
+		}
+	}
+
+	check, err := CheckLeaving(context.Background(), db, client, notifier, "US", 25)
+	if err != nil {
+		t.Fatalf("CheckLeaving failed: %v", err)
+	}
+	if check.Titles != 1 || check.Gone != 0 || check.Notified != 0 {
+		t.Fatalf("Unexpected first check: %+v", check)
+	}
+
+	// The Office moves to Peacock
+	providers = `[{"provider_name": "Peacock Premium"}]`
+	for range 2 {
+		if check, err = CheckLeaving(context.Background(), db, client, notifier, "US", 25); err != nil {
+			t.Fatalf("CheckLeaving failed: %v", err)
+		}
+	}
+	if check.Gone != 1 || check.Notified != 0 {
+		t.Errorf("Expected The Office still gone without a repeat notification, got %+v", check)
+	}
+
+	notifications, _ := db.GetNotifications(10)
+	if len(notifications) != 1 || notifications[0].Message != "The Office is leaving Netflix" {
+		t.Errorf("Expected one leaving notification, got %+v", notifications)
+	}
+	leaving, _ := db.GetLeavingTitles("US", now.AddDate(0, 0, -1))
+	if len(leaving) != 1 || leaving[0].ServiceName != "Netflix" {
+		t.Errorf("Expected The Office leaving Netflix, got %+v", leaving)
+	}
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/mqtt"
+)
+
+// Notifier records notifications in the database, where the API lists them,
+// and delivers each one to the configured channels:
+//
+//	<mqtt prefix>/notifications  JSON notification, not retained
+//	notifications.webhook_url    JSON notification POSTed as the body
+type Notifier struct {
+	db         *database.DB
+	mqtt       *mqtt.Client
+	topic      string
+	webhookURL string
+	httpClient *http.Client
+}
+
+// New creates a notifier delivering to the channels in cfg
+func New(cfg *config.Config, db *database.DB) *Notifier {
+	n := &Notifier{
+		db:         db,
+		webhookURL: cfg.Notifications.WebhookURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	if cfg.MQTT.BrokerURL != "" {
+		n.mqtt = mqtt.NewClient(cfg.MQTT.BrokerURL, cfg.MQTT.Username, cfg.MQTT.Password, cfg.MQTT.ClientID)
+		n.topic = strings.TrimSuffix(cfg.MQTT.TopicPrefix, "/") + "/notifications"
+	}
+	return n
+}
+
+// Send records a notification and delivers it. Delivery failures are logged
+// rather than returned, since the notification is already recorded.
+func (n *Notifier) Send(ctx context.Context, kind, title, message string) error {
+	notification := &database.Notification{Kind: kind, Title: title, Message: message}
+	if err := n.db.AddNotification(notification); err != nil {
+		return err
+	}
+	log.Printf("Notification: %s", message)
+
+	payload, _ := json.Marshal(notification)
+	if n.mqtt != nil {
+		if err := n.mqtt.Publish(ctx, []mqtt.Message{{Topic: n.topic, Payload: payload}}); err != nil {
+			log.Printf("Notifications: failed to publish to MQTT: %v", err)
+		}
+	}
+	if n.webhookURL != "" {
+		if err := n.post(ctx, payload); err != nil {
+			log.Printf("Notifications: webhook failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// post sends a notification to the webhook
+func (n *Notifier) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+// showRefresh is how long a show's cached latest episode is trusted before
+// it's looked up again, matching the episode insights that share the cache
+const showRefresh = 24 * time.Hour
+
+// SeasonCheck summarizes a run of CheckNewSeasons
+type SeasonCheck struct {
+	Shows    int `json:"shows"`
+	LookedUp int `json:"looked_up"`
+	Notified int `json:"notified"`
+}
+
+// CheckNewSeasons looks up every show in the watch history on TMDB and sends
+// a "new_season" notification when a season later than any known before
+// has premiered, unless the show is muted or that season is already being
+// watched. The first check of a show only records its latest season, so
+// seasons that premiered before notifications were turned on aren't announced.
+func CheckNewSeasons(ctx context.Context, db *database.DB, client *tmdb.Client, notifier *Notifier) (*SeasonCheck, error) {
+	allTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	watched, err := db.GetWatchedEpisodes(allTime, time.Now().Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+
+	// The latest season watched of each show, keeping the first title seen
+	var titles []string
+	watchedSeason := make(map[string]int)
+	for _, ep := range watched {
+		key := strings.ToLower(ep.Title)
+		if _, ok := watchedSeason[key]; !ok {
+			titles = append(titles, ep.Title)
+			watchedSeason[key] = 0
+		}
+		if season, _, ok := insights.ParseEpisode(ep.EpisodeInfo); ok {
+			watchedSeason[key] = max(watchedSeason[key], season)
+		}
+	}
+
+	check := &SeasonCheck{Shows: len(titles)}
+	for _, title := range titles {
+		if ctx.Err() != nil {
+			return check, ctx.Err()
+		}
+
+		notice, err := db.GetShowNotice(title)
+		if err != nil {
+			return check, err
+		}
+		if notice != nil && notice.Muted {
+			continue
+		}
+
+		show, err := db.GetTVShow(title)
+		if err != nil {
+			return check, err
+		}
+		if show == nil || (show.TMDBID != 0 && time.Since(show.Updated) > showRefresh) {
+			check.LookedUp++
+			if show, err = lookupShow(ctx, db, client, title); err != nil {
+				log.Printf("Failed to look up '%s' for new seasons: %v", title, err)
+				continue
+			}
+		}
+		season := show.LatestSeason
+		if show.TMDBID == 0 || season == 0 {
+			continue
+		}
+
+		if notice != nil && season > notice.NotifiedSeason && season > watchedSeason[strings.ToLower(title)] {
+			message := fmt.Sprintf("Season %d of %s premiered", season, title)
+			if err := notifier.Send(ctx, "new_season", title, message); err != nil {
+				return check, err
+			}
+			check.Notified++
+		}
+		if notice == nil || season > notice.NotifiedSeason {
+			if err := db.SetNotifiedSeason(title, season); err != nil {
+				return check, err
+			}
+		}
+	}
+
+	return check, nil
+}
+
+// lookupShow finds a show's latest and next episodes on TMDB and caches them,
+// including misses so they aren't looked up again
+func lookupShow(ctx context.Context, db *database.DB, client *tmdb.Client, title string) (*database.TVShow, error) {
+	show := &database.TVShow{Title: title}
+
+	match, err := client.SearchMulti(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil && match.MediaType == "tv" {
+		airing, err := client.ShowAiring(ctx, match.ID)
+		if err != nil {
+			return nil, err
+		}
+		show.TMDBID = match.ID
+		if last := airing.LastEpisode; last != nil {
+			show.LatestSeason = last.SeasonNumber
+			show.LatestEpisode = last.EpisodeNumber
+			show.LatestAirDate = last.AirDate
+		}
+		if next := airing.NextEpisode; next != nil {
+			show.NextSeason = next.SeasonNumber
+			show.NextEpisode = next.EpisodeNumber
+			show.NextName = next.Name
+			show.NextAirDate = next.AirDate
+		}
+	}
+
+	if err := db.SetTVShow(show, nil); err != nil {
+		return nil, err
+	}
+	return show, nil
+}
@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestCheckNewSeasons(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	// Each show's latest season, bumped between checks
+	latest := map[string]int{"/tv/1": 1, "/tv/2": 2, "/tv/3": 1}
+	tmdbServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/multi" {
+			ids := map[string]string{"Andor": "1", "Fargo": "2", "Severance": "3"}
+			w.Write([]byte(`{"results": [{"id": ` + ids[r.URL.Query().Get("query")] + `, "media_type": "tv"}]}`))
+			return
+		}
+		season, ok := latest[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"last_episode_to_air": map[string]interface{}{"season_number": season, "episode_number": 1, "air_date": "2025-04-22"},
+		})
+	}))
+	defer tmdbServer.Close()
+	client := tmdb.NewClientWithBaseURL("test_api_key", tmdbServer.URL)
+
+	var delivered []database.Notification
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n database.Notification
+		json.NewDecoder(r.Body).Decode(&n)
+		delivered = append(delivered, n)
+	}))
+	defer webhook.Close()
+	notifier := New(&config.Config{Notifications: config.NotificationsConfig{WebhookURL: webhook.URL}}, db)
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	db.UpdateServiceEnabled(netflix.ID, true)
+	now := time.Now()
+	for _, wh := range []*database.WatchHistory{
+		{ServiceID: netflix.ID, Title: "Andor", EpisodeInfo: "S01E12", DurationMinutes: 50, WatchedAt: now.AddDate(-1, 0, 0)},
+		{ServiceID: netflix.ID, Title: "Fargo", EpisodeInfo: "S02E10", DurationMinutes: 50, WatchedAt: now.AddDate(-1, 0, 0)},
+		{ServiceID: netflix.ID, Title: "Severance", EpisodeInfo: "S01E09", DurationMinutes: 50, WatchedAt: now.AddDate(0, -6, 0)},
+	} {
+		if err := db.InsertWatchHistory(wh); err != nil {
+			t.Fatalf("Failed to insert %s: %v", wh.Title, err)
+		}
+	}
+
+	// The first check only records each show's latest season
+	check, err := CheckNewSeasons(context.Background(), db, client, notifier)
+	if err != nil {
+		t.Fatalf("CheckNewSeasons failed: %v", err)
+	}
+	if check.Shows != 3 || check.LookedUp != 3 || check.Notified != 0 {
+		t.Fatalf("Unexpected first check: %+v", check)
+	}
+
+	// Andor and Severance premiere new seasons; Severance is muted, and the
+	// cache is stale so both are looked up again
+	latest["/tv/1"], latest["/tv/3"] = 2, 2
+	db.SetShowMuted("Severance", true)
+	db.Exec(`UPDATE tv_shows SET updated = ?`, now.Add(-48*time.Hour))
+
+	check, err = CheckNewSeasons(context.Background(), db, client, notifier)
+	if err != nil {
+		t.Fatalf("CheckNewSeasons failed: %v", err)
+	}
+	if check.Notified != 1 || len(delivered) != 1 || delivered[0].Message != "Season 2 of Andor premiered" {
+		t.Fatalf("Expected one notification for Andor, got %+v and %+v", check, delivered)
+	}
+	notifications, _ := db.GetNotifications(10)
+	if len(notifications) != 1 || notifications[0].Kind != "new_season" {
+		t.Errorf("Expected the notification to be recorded, got %+v", notifications)
+	}
+
+	// A season is only announced once
+	db.Exec(`UPDATE tv_shows SET updated = ?`, now.Add(-48*time.Hour))
+	if check, _ = CheckNewSeasons(context.Background(), db, client, notifier); check.Notified != 0 {
+		t.Errorf("Expected no repeat notifications, got %+v", check)
+	}
+}
@@ -0,0 +1,40 @@
+package query
+
+import "fmt"
+
+// Answer phrases the result of a query as a sentence
+func Answer(q *Query, minutes, count int) string {
+	on := ""
+	if q.Service != "" {
+		on = " on " + q.Service
+	}
+
+	if q.Aggregate == AggregateCount {
+		if q.Title != "" {
+			return fmt.Sprintf("You watched %s%s %s %s.", q.Title, on, plural(count, "time"), q.Period)
+		}
+		return fmt.Sprintf("You watched %s%s %s.", plural(count, "title"), on, q.Period)
+	}
+
+	amount := fmt.Sprintf("%.1f hours", float64(minutes)/60)
+	if q.Aggregate == AggregateMinutes {
+		amount = plural(minutes, "minute")
+	}
+
+	switch {
+	case q.Title != "":
+		return fmt.Sprintf("You watched %s of %s%s %s.", amount, q.Title, on, q.Period)
+	case q.Service != "":
+		return fmt.Sprintf("You watched %s of %s %s.", amount, q.Service, q.Period)
+	default:
+		return fmt.Sprintf("You watched %s %s.", amount, q.Period)
+	}
+}
+
+// plural formats a count with a singular or plural noun
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,168 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/units"
+)
+
+// Aggregates supported by the query grammar
+const (
+	AggregateHours   = "hours"
+	AggregateMinutes = "minutes"
+	AggregateCount   = "count"
+)
+
+// Query is the structured form of a stats question
+type Query struct {
+	Aggregate string    `json:"aggregate"`         // hours, minutes or count
+	Service   string    `json:"service,omitempty"` // Database service name; empty means all services
+	Title     string    `json:"title,omitempty"`   // Exact title (case-insensitive); empty means all titles
+	Period    string    `json:"period"`            // Human-readable period, e.g. "in March 2025"
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+// serviceAliases are extra ways of naming a service in a question
+var serviceAliases = map[string]string{
+	"prime":         "Amazon Video",
+	"prime video":   "Amazon Video",
+	"amazon":        "Amazon Video",
+	"hbo":           "HBO Max",
+	"max":           "HBO Max",
+	"youtubetv":     "YouTube TV",
+	"espn":          "ESPN+",
+	"criterion":     "Criterion Channel",
+	"apple tv plus": "Apple TV",
+	"apple tv+":     "Apple TV",
+}
+
+var (
+	quotedPattern    = regexp.MustCompile(`"([^"]+)"|“([^”]+)”`)
+	lastDaysPattern  = regexp.MustCompile(`\b(?:last|past) (\d+) days\b`)
+	monthPattern     = regexp.MustCompile(`\b(?:in|during) (january|february|march|april|may|june|july|august|september|october|november|december)(?: (\d{4}))?\b`)
+	yearPattern      = regexp.MustCompile(`\b(?:in|during) (\d{4})\b`)
+	wordBoundaryTrim = strings.NewReplacer("?", " ", ",", " ", ".", " ", "!", " ")
+)
+
+// Parse translates a question like "how many hours of Netflix in March" into a
+// Query. services are the known service names; now anchors relative periods
+// and weeks start on firstDay. Titles must be quoted, e.g.
+// `how many times did I watch "The Office" this year`.
+func Parse(question string, services []string, now time.Time, firstDay time.Weekday) (*Query, error) {
+	q := &Query{Aggregate: AggregateHours}
+
+	// Pull out a quoted title first so its words don't match anything else
+	if m := quotedPattern.FindStringSubmatch(question); m != nil {
+		q.Title = strings.TrimSpace(m[1] + m[2])
+		question = strings.Replace(question, m[0], " ", 1)
+	}
+
+	text := " " + strings.Join(strings.Fields(strings.ToLower(wordBoundaryTrim.Replace(question))), " ") + " "
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("question is empty")
+	}
+
+	switch {
+	case strings.Contains(text, " minutes "):
+		q.Aggregate = AggregateMinutes
+	case strings.Contains(text, " how many times ") || strings.Contains(text, " how many episodes ") ||
+		strings.Contains(text, " how many shows ") || strings.Contains(text, " how often "):
+		q.Aggregate = AggregateCount
+	}
+
+	q.Service = matchService(text, services)
+
+	start, end, period, err := parsePeriod(text, now, firstDay)
+	if err != nil {
+		return nil, err
+	}
+	q.Start, q.End, q.Period = start, end, period
+
+	return q, nil
+}
+
+// matchService finds the longest service name or alias mentioned in the text
+func matchService(text string, services []string) string {
+	best, bestLen := "", 0
+	try := func(name, service string) {
+		name = strings.ToLower(name)
+		if len(name) > bestLen && strings.Contains(text, " "+name+" ") {
+			best, bestLen = service, len(name)
+		}
+	}
+
+	for _, service := range services {
+		try(service, service)
+		// "Apple TV+" is usually typed without the plus
+		try(strings.TrimSuffix(service, "+"), service)
+	}
+	for alias, service := range serviceAliases {
+		for _, known := range services {
+			if known == service {
+				try(alias, service)
+			}
+		}
+	}
+	return best
+}
+
+// parsePeriod finds the time period in the text, defaulting to all time
+func parsePeriod(text string, now time.Time, firstDay time.Weekday) (time.Time, time.Time, string, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := units.WeekStart(today, firstDay)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+
+	if m := lastDaysPattern.FindStringSubmatch(text); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		if days <= 0 {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("number of days must be positive")
+		}
+		return today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1), fmt.Sprintf("in the last %d days", days), nil
+	}
+
+	if m := monthPattern.FindStringSubmatch(text); m != nil {
+		month, _ := time.Parse("January", strings.ToUpper(m[1][:1])+m[1][1:])
+		year := now.Year()
+		if m[2] != "" {
+			year, _ = strconv.Atoi(m[2])
+		} else if month.Month() > now.Month() {
+			// "in December" asked in March means last December
+			year--
+		}
+		start := time.Date(year, month.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0), "in " + start.Format("January 2006"), nil
+	}
+
+	if m := yearPattern.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		start := time.Date(year, 1, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(1, 0, 0), fmt.Sprintf("in %d", year), nil
+	}
+
+	periods := []struct {
+		phrase     string
+		start, end time.Time
+	}{
+		{"today", today, today.AddDate(0, 0, 1)},
+		{"yesterday", today.AddDate(0, 0, -1), today},
+		{"this week", weekStart, weekStart.AddDate(0, 0, 7)},
+		{"last week", weekStart.AddDate(0, 0, -7), weekStart},
+		{"this month", monthStart, monthStart.AddDate(0, 1, 0)},
+		{"last month", monthStart.AddDate(0, -1, 0), monthStart},
+		{"this year", yearStart, yearStart.AddDate(1, 0, 0)},
+		{"last year", yearStart.AddDate(-1, 0, 0), yearStart},
+	}
+	for _, p := range periods {
+		if strings.Contains(text, " "+p.phrase+" ") {
+			return p.start, p.end, p.phrase, nil
+		}
+	}
+
+	return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), today.AddDate(1, 0, 0), "of all time", nil
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+var testServices = []string{"Netflix", "YouTube TV", "Amazon Video", "HBO Max", "Apple TV+"}
+
+func TestParse(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 19, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		question  string
+		aggregate string
+		service   string
+		title     string
+		start     time.Time
+		end       time.Time
+	}{
+		{
+			question:  "How many hours of Netflix in March?",
+			aggregate: AggregateHours,
+			service:   "Netflix",
+			start:     time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			end:       time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			question:  "how many hours of hbo in december",
+			aggregate: AggregateHours,
+			service:   "HBO Max",
+			start:     time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			question:  "minutes of youtube tv this week",
+			aggregate: AggregateMinutes,
+			service:   "YouTube TV",
+			start:     time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC),
+			end:       time.Date(2025, 3, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			question:  `How many times did I watch "The Office" in 2024?`,
+			aggregate: AggregateCount,
+			title:     "The Office",
+			start:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			question:  "hours on apple tv last month",
+			aggregate: AggregateHours,
+			service:   "Apple TV+",
+			start:     time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			end:       time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			question:  "how much did I watch in the last 7 days",
+			aggregate: AggregateHours,
+			start:     time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC),
+			end:       time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.question, func(t *testing.T) {
+			q, err := Parse(tt.question, testServices, now, time.Monday)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if q.Aggregate != tt.aggregate {
+				t.Errorf("Expected aggregate %s, got %s", tt.aggregate, q.Aggregate)
+			}
+			if q.Service != tt.service {
+				t.Errorf("Expected service '%s', got '%s'", tt.service, q.Service)
+			}
+			if q.Title != tt.title {
+				t.Errorf("Expected title '%s', got '%s'", tt.title, q.Title)
+			}
+			if !q.Start.Equal(tt.start) || !q.End.Equal(tt.end) {
+				t.Errorf("Expected %v - %v, got %v - %v", tt.start, tt.end, q.Start, q.End)
+			}
+		})
+	}
+}
+
+func TestParseDefaultsToAllTime(t *testing.T) {
+	q, err := Parse("how many hours of netflix", testServices, time.Now(), time.Monday)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if q.Period != "of all time" {
+		t.Errorf("Expected all time period, got '%s'", q.Period)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	if _, err := Parse("  ?", testServices, time.Now(), time.Monday); err == nil {
+		t.Error("Expected error for empty question")
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	q := &Query{Aggregate: AggregateHours, Service: "Netflix", Period: "in March 2025"}
+	if got := Answer(q, 90, 2); got != "You watched 1.5 hours of Netflix in March 2025." {
+		t.Errorf("Unexpected answer: %s", got)
+	}
+
+	q = &Query{Aggregate: AggregateCount, Title: "The Office", Period: "this year"}
+	if got := Answer(q, 90, 1); got != "You watched The Office 1 time this year." {
+		t.Errorf("Unexpected answer: %s", got)
+	}
+}
@@ -0,0 +1,179 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/insights"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+// Source provides title matching, recommendations and availability (TMDB)
+type Source interface {
+	SearchMulti(ctx context.Context, query string) (*tmdb.MediaItem, error)
+	Recommendations(ctx context.Context, mediaType string, id int64) ([]tmdb.MediaItem, error)
+	WatchProviders(ctx context.Context, mediaType string, id int64, region string) ([]string, error)
+}
+
+// Recommendation is a suggested title with why it was suggested and where to watch it
+type Recommendation struct {
+	TMDBID    int64    `json:"tmdb_id"`
+	Title     string   `json:"title"`
+	MediaType string   `json:"media_type"`
+	Overview  string   `json:"overview"`
+	Score     float64  `json:"score"`
+	Because   []string `json:"because"`   // Watched titles that led to this suggestion
+	Providers []string `json:"providers"` // Subscribed services that carry it
+}
+
+// Options controls how recommendations are built
+type Options struct {
+	Region     string   // Availability region, e.g. "US"
+	Limit      int      // Maximum number of recommendations
+	Subscribed []string // Service names the user subscribes to; empty disables filtering
+}
+
+// providerAliases maps service names to the names TMDB uses for their providers
+var providerAliases = map[string][]string{
+	"Amazon Video": {"Amazon Prime Video"},
+	"HBO Max":      {"Max"},
+	"Apple TV":     {"Apple TV Plus", "Apple TV+"},
+	"Disney+":      {"Disney Plus"},
+	"ESPN+":        {"ESPN Plus"},
+}
+
+// maxProviderLookups bounds availability lookups per requested recommendation
+const maxProviderLookups = 3
+
+// Build ranks titles recommended for the seeds (the user's most watched recent
+// titles, weighted by watch time), skipping anything in watched and anything
+// not available on a subscribed service
+func Build(ctx context.Context, src Source, seeds []database.TitleStats, watched []database.TitleStats, opts Options) ([]Recommendation, error) {
+	seen := make(map[string]bool)
+	for _, ts := range watched {
+		seen[insights.NormalizeTitle(ts.Title)] = true
+	}
+
+	totalMinutes := 0
+	for _, seed := range seeds {
+		totalMinutes += seed.TotalMinutes
+	}
+
+	candidates := make(map[int64]*Recommendation)
+	for _, seed := range seeds {
+		match, err := src.SearchMulti(ctx, seed.Title)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up %s: %w", seed.Title, err)
+		}
+		if match == nil {
+			continue
+		}
+
+		items, err := src.Recommendations(ctx, match.MediaType, match.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch recommendations for %s: %w", seed.Title, err)
+		}
+
+		weight := 1.0
+		if totalMinutes > 0 {
+			weight = float64(seed.TotalMinutes) / float64(totalMinutes)
+		}
+
+		for pos, item := range items {
+			if seen[insights.NormalizeTitle(item.DisplayTitle())] {
+				continue
+			}
+
+			rec, ok := candidates[item.ID]
+			if !ok {
+				rec = &Recommendation{
+					TMDBID:    item.ID,
+					Title:     item.DisplayTitle(),
+					MediaType: item.MediaType,
+					Overview:  item.Overview,
+					Because:   []string{},
+					Providers: []string{},
+				}
+				candidates[item.ID] = rec
+			}
+			// Earlier positions in TMDB's list count for more
+			rec.Score += weight / float64(pos+1)
+			rec.Because = append(rec.Because, seed.Title)
+		}
+	}
+
+	ranked := make([]*Recommendation, 0, len(candidates))
+	for _, rec := range candidates {
+		ranked = append(ranked, rec)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Title < ranked[j].Title
+	})
+
+	results := []Recommendation{}
+	lookups := 0
+	for _, rec := range ranked {
+		if len(results) >= opts.Limit {
+			break
+		}
+
+		if len(opts.Subscribed) > 0 {
+			if lookups >= opts.Limit*maxProviderLookups {
+				break
+			}
+			lookups++
+
+			providers, err := src.WatchProviders(ctx, rec.MediaType, rec.TMDBID, opts.Region)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch availability for %s: %w", rec.Title, err)
+			}
+			rec.Providers = subscribedProviders(providers, opts.Subscribed)
+			if len(rec.Providers) == 0 {
+				continue
+			}
+		}
+
+		rec.Score = math.Round(rec.Score*1000) / 1000
+		results = append(results, *rec)
+	}
+
+	return results, nil
+}
+
+// subscribedProviders returns the subscribed services among a title's providers
+func subscribedProviders(providers, subscribed []string) []string {
+	matched := []string{}
+	for _, service := range subscribed {
+		if Offers(providers, service) {
+			matched = append(matched, service)
+		}
+	}
+	return matched
+}
+
+// Offers reports whether a service is among a title's TMDB providers
+func Offers(providers []string, service string) bool {
+	return carries(providers, append([]string{service}, providerAliases[service]...))
+}
+
+// carries reports whether any provider matches one of the names. Provider
+// tiers like "Netflix Standard with Ads" match "Netflix".
+func carries(providers, names []string) bool {
+	for _, provider := range providers {
+		p := insights.NormalizeTitle(provider)
+		for _, name := range names {
+			n := insights.NormalizeTitle(name)
+			if p == n || strings.HasPrefix(p, n+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package recommend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+// fakeSource serves canned TMDB data
+type fakeSource struct {
+	matches   map[string]*tmdb.MediaItem
+	recs      map[int64][]tmdb.MediaItem
+	providers map[int64][]string
+}
+
+func (f *fakeSource) SearchMulti(ctx context.Context, query string) (*tmdb.MediaItem, error) {
+	return f.matches[query], nil
+}
+
+func (f *fakeSource) Recommendations(ctx context.Context, mediaType string, id int64) ([]tmdb.MediaItem, error) {
+	return f.recs[id], nil
+}
+
+func (f *fakeSource) WatchProviders(ctx context.Context, mediaType string, id int64, region string) ([]string, error) {
+	return f.providers[id], nil
+}
+
+func TestBuild(t *testing.T) {
+	src := &fakeSource{
+		matches: map[string]*tmdb.MediaItem{
+			"Severance": {ID: 1, MediaType: "tv", Name: "Severance"},
+			"The Bear":  {ID: 2, MediaType: "tv", Name: "The Bear"},
+		},
+		recs: map[int64][]tmdb.MediaItem{
+			1: {{ID: 10, Name: "Silo"}, {ID: 11, Name: "Dark"}, {ID: 2, Name: "The Bear"}},
+			2: {{ID: 11, Name: "Dark"}, {ID: 12, Name: "Shrinking"}},
+		},
+		providers: map[int64][]string{
+			10: {"Apple TV Plus"},
+			11: {"Netflix Standard with Ads"},
+			12: {"Apple TV Plus"},
+		},
+	}
+
+	seeds := []database.TitleStats{
+		{Title: "Severance", TotalMinutes: 300},
+		{Title: "The Bear", TotalMinutes: 100},
+	}
+
+	recs, err := Build(context.Background(), src, seeds, seeds, Options{
+		Region:     "US",
+		Limit:      10,
+		Subscribed: []string{"Netflix"},
+	})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// Only Dark is on a subscribed service; The Bear was already watched
+	if len(recs) != 1 {
+		t.Fatalf("Expected 1 recommendation, got %+v", recs)
+	}
+	if recs[0].Title != "Dark" || len(recs[0].Because) != 2 || recs[0].Providers[0] != "Netflix" {
+		t.Errorf("Unexpected recommendation: %+v", recs[0])
+	}
+}
+
+func TestBuildWithoutSubscriptions(t *testing.T) {
+	src := &fakeSource{
+		matches: map[string]*tmdb.MediaItem{"Severance": {ID: 1, MediaType: "tv"}},
+		recs:    map[int64][]tmdb.MediaItem{1: {{ID: 10, Name: "Silo"}, {ID: 11, Name: "Dark"}}},
+	}
+
+	recs, err := Build(context.Background(), src, []database.TitleStats{{Title: "Severance", TotalMinutes: 60}}, nil, Options{Limit: 1})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Title != "Silo" {
+		t.Errorf("Expected the top ranked title, got %+v", recs)
+	}
+}
+
+func TestSubscribedProviders(t *testing.T) {
+	providers := []string{"Max", "Amazon Prime Video", "Peacock Premium"}
+	got := subscribedProviders(providers, []string{"HBO Max", "Amazon Video", "Peacock", "Netflix"})
+
+	if len(got) != 3 || got[0] != "HBO Max" || got[1] != "Amazon Video" || got[2] != "Peacock" {
+		t.Errorf("Unexpected subscribed providers: %v", got)
+	}
+}
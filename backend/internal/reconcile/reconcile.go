@@ -0,0 +1,220 @@
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// Merge is an inferred session dropped because better evidence covers it
+type Merge struct {
+	Removed database.WatchHistory `json:"removed"`
+	// KeptID is the inferred session it merged into, or 0 when it overlaps
+	// history read from the service
+	KeptID int64 `json:"kept_id,omitempty"`
+}
+
+// Result summarizes a reconciliation
+type Result struct {
+	DryRun  bool    `json:"dry_run"`
+	Checked int     `json:"checked"`
+	Removed int     `json:"removed"`
+	Merges  []Merge `json:"merges"`
+	// Combined are kept sessions widened to the span of the sessions merged
+	// into them
+	Combined []database.WatchHistory `json:"combined"`
+}
+
+// rank orders evidence sources, higher being more trustworthy
+func rank(wh database.WatchHistory) int {
+	switch wh.Confidence {
+	case "":
+		return 3
+	case database.ConfidenceMedium:
+		return 2
+	case database.ConfidenceLow:
+		return 1
+	}
+	return 0
+}
+
+// dailyTotal reports whether an entry is a day's total rather than a session
+func dailyTotal(wh database.WatchHistory) bool {
+	return wh.Title == database.ScreenTimeTitle
+}
+
+// Span returns the time an inferred session covers. Daily totals have no time
+// of day, so they cover their whole day.
+func Span(wh database.WatchHistory) (time.Time, time.Time) {
+	if dailyTotal(wh) {
+		day := time.Date(wh.WatchedAt.Year(), wh.WatchedAt.Month(), wh.WatchedAt.Day(), 0, 0, 0, 0, wh.WatchedAt.Location())
+		return day, day.AddDate(0, 0, 1)
+	}
+	return wh.WatchedAt, wh.WatchedAt.Add(time.Duration(wh.DurationMinutes) * time.Minute)
+}
+
+// overlaps reports whether two sessions share any time
+func overlaps(a, b database.WatchHistory) bool {
+	aStart, aEnd := Span(a)
+	bStart, bEnd := Span(b)
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// separate reports whether overlapping sessions are separate viewing. The
+// kind of evidence is labeled by confidence, and one kind only sees the same
+// viewing twice from one device; on different devices it is people watching
+// at once.
+func separate(a, b database.WatchHistory) bool {
+	return a.Confidence == b.Confidence && a.Device != b.Device
+}
+
+// combine widens kept to cover wh as well and reports whether it changed.
+// Daily totals have no span to combine, so they are left as they are.
+func combine(kept *database.WatchHistory, wh database.WatchHistory) bool {
+	if dailyTotal(*kept) || dailyTotal(wh) {
+		return false
+	}
+	start, end := Span(*kept)
+	whStart, whEnd := Span(wh)
+	if whStart.Before(start) {
+		start = whStart
+	}
+	if whEnd.After(end) {
+		end = whEnd
+	}
+	minutes := int(end.Sub(start).Round(time.Minute) / time.Minute)
+	if start.Equal(kept.WatchedAt) && minutes == kept.DurationMinutes {
+		return false
+	}
+	kept.WatchedAt, kept.DurationMinutes = start, minutes
+	return true
+}
+
+// merger collects the sessions kept so far, in rank order, and merges each
+// new one into the best kept session it overlaps
+type merger struct {
+	kept     []database.WatchHistory
+	gone     []bool // Kept sessions since merged into a better one
+	combined []bool // Kept sessions whose span grew
+	merges   []Merge
+}
+
+// add keeps wh or merges it into a kept session. A session that grows may
+// then overlap others, which merge into the better of the two in turn.
+func (m *merger) add(wh database.WatchHistory) {
+	i := m.overlapping(wh, -1)
+	if i < 0 {
+		m.kept = append(m.kept, wh)
+		m.gone = append(m.gone, false)
+		m.combined = append(m.combined, false)
+		return
+	}
+
+	m.merge(i, wh)
+	for {
+		j := m.overlapping(m.kept[i], i)
+		if j < 0 {
+			return
+		}
+		if j < i {
+			i, j = j, i
+		}
+		m.gone[j] = true
+		m.merge(i, m.kept[j])
+	}
+}
+
+// merge records wh merging into kept session i, along with anything that
+// had merged into wh
+func (m *merger) merge(i int, wh database.WatchHistory) {
+	if combine(&m.kept[i], wh) {
+		m.combined[i] = true
+	}
+	for k := range m.merges {
+		if m.merges[k].KeptID == wh.ID {
+			m.merges[k].KeptID = m.kept[i].ID
+		}
+	}
+	m.merges = append(m.merges, Merge{Removed: wh, KeptID: m.kept[i].ID})
+}
+
+// overlapping returns the best kept session other than skip that wh overlaps
+// and is the same viewing as, or -1
+func (m *merger) overlapping(wh database.WatchHistory, skip int) int {
+	for i, k := range m.kept {
+		if i != skip && !m.gone[i] && k.ServiceID == wh.ServiceID && overlaps(k, wh) && !separate(k, wh) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Run merges inferred sessions watched since the given time with the other
+// evidence for the same viewing, so one evening seen by a scrape, a device
+// and the router counts once:
+//
+//   - Inferred sessions overlapping history read from the service, which has
+//     real titles, are dropped.
+//   - Overlapping inferred sessions on the same service merge into one
+//     spanning them all, under the title of the most confident, then
+//     longest, of them.
+//
+// Sessions from the same source and device never merge, since back-to-back
+// sessions are separate viewing. A dry run only reports what would change.
+func Run(db *database.DB, since time.Time, dryRun bool) (*Result, error) {
+	entries, err := db.GetInferredHistory(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inferred history: %w", err)
+	}
+
+	result := &Result{
+		DryRun:   dryRun,
+		Checked:  len(entries),
+		Merges:   []Merge{},
+		Combined: []database.WatchHistory{},
+	}
+
+	// Visit the best evidence first so it is what the rest merges into
+	sort.SliceStable(entries, func(i, k int) bool {
+		if ri, rk := rank(entries[i]), rank(entries[k]); ri != rk {
+			return ri > rk
+		}
+		return entries[i].DurationMinutes > entries[k].DurationMinutes
+	})
+
+	m := &merger{}
+	var removed []int64
+	for _, wh := range entries {
+		start, end := Span(wh)
+		read, err := db.HasWatchHistoryDuring(wh.ServiceID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check history for entry %d: %w", wh.ID, err)
+		}
+		if read {
+			result.Merges = append(result.Merges, Merge{Removed: wh})
+			removed = append(removed, wh.ID)
+			continue
+		}
+		m.add(wh)
+	}
+
+	for _, merge := range m.merges {
+		result.Merges = append(result.Merges, merge)
+		removed = append(removed, merge.Removed.ID)
+	}
+	for i, k := range m.kept {
+		if m.combined[i] && !m.gone[i] {
+			result.Combined = append(result.Combined, k)
+		}
+	}
+	result.Removed = len(removed)
+	if dryRun || len(removed) == 0 {
+		return result, nil
+	}
+	if err := db.MergeWatchHistorySessions(result.Combined, removed); err != nil {
+		return nil, fmt.Errorf("failed to merge sessions: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,187 @@
+package reconcile
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func setupTestDB(t *testing.T) (*database.DB, int64) {
+	db, err := database.New(filepath.Join(t.TempDir(), "reconcile.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	service, _ := db.GetServiceByName("Netflix")
+	return db, service.ID
+}
+
+func insert(t *testing.T, db *database.DB, items ...database.WatchHistory) []database.WatchHistory {
+	for i := range items {
+		if err := db.InsertWatchHistory(&items[i]); err != nil {
+			t.Fatalf("Failed to insert history: %v", err)
+		}
+	}
+	return items
+}
+
+func TestRunPrefersHigherConfidence(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	evening := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	items := insert(t, db,
+		database.WatchHistory{ServiceID: netflixID, Title: "Network Activity", DurationMinutes: 80, WatchedAt: evening, Confidence: database.ConfidenceLow},
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 70, WatchedAt: evening.Add(10 * time.Minute), Confidence: database.ConfidenceMedium},
+		// Back-to-back sessions from the same source stay separate
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 30, WatchedAt: evening.Add(85 * time.Minute), Confidence: database.ConfidenceMedium},
+		// Traffic later that night doesn't overlap anything
+		database.WatchHistory{ServiceID: netflixID, Title: "Network Activity", DurationMinutes: 20, WatchedAt: evening.Add(3 * time.Hour), Confidence: database.ConfidenceLow},
+	)
+
+	preview, err := Run(db, time.Time{}, true)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if preview.Checked != 4 || preview.Removed != 1 {
+		t.Fatalf("Expected 1 of 4 sessions merged, got %+v", preview)
+	}
+	if merge := preview.Merges[0]; merge.Removed.ID != items[0].ID || merge.KeptID != items[1].ID {
+		t.Errorf("Expected the network session merged into the longest device session, got %+v", merge)
+	}
+	if len(preview.Combined) != 1 || !preview.Combined[0].WatchedAt.Equal(evening) || preview.Combined[0].DurationMinutes != 80 {
+		t.Errorf("Expected the device session widened to the traffic's start, got %+v", preview.Combined)
+	}
+	if left, _ := db.GetInferredHistory(time.Time{}); len(left) != 4 {
+		t.Errorf("Expected a dry run to keep every session, got %d", len(left))
+	}
+
+	if _, err := Run(db, time.Time{}, false); err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	left, _ := db.GetInferredHistory(time.Time{})
+	if len(left) != 3 {
+		t.Errorf("Expected 3 sessions left, got %+v", left)
+	}
+	for _, wh := range left {
+		if wh.ID == items[0].ID {
+			t.Errorf("Expected the overlapping network session removed, got %+v", wh)
+		}
+	}
+}
+
+func TestRunDropsInferredOverlappingReadHistory(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	evening := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	insert(t, db,
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 70, WatchedAt: evening, Confidence: database.ConfidenceMedium},
+		// Earlier the same day, with no scraped history at the time
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 40, WatchedAt: evening.Add(-8 * time.Hour), Confidence: database.ConfidenceMedium},
+		database.WatchHistory{ServiceID: netflixID, Title: "Stranger Things", DurationMinutes: 50, WatchedAt: evening.Add(30 * time.Minute)},
+	)
+
+	result, err := Run(db, time.Time{}, false)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if result.Removed != 1 || result.Merges[0].KeptID != 0 || !result.Merges[0].Removed.WatchedAt.Equal(evening) {
+		t.Errorf("Expected only the session overlapping the scraped episode removed, got %+v", result)
+	}
+
+	left, _ := db.GetInferredHistory(time.Time{})
+	if len(left) != 1 || !left[0].WatchedAt.Equal(evening.Add(-8*time.Hour)) {
+		t.Errorf("Expected the morning session kept, got %+v", left)
+	}
+}
+
+func TestRunCombinesPartiallyOverlappingSessions(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	evening := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	items := insert(t, db,
+		// The router saw traffic before the device reported the app open,
+		// and the device kept it open after traffic stopped
+		database.WatchHistory{ServiceID: netflixID, Title: "Network Activity", DurationMinutes: 60, WatchedAt: evening, Confidence: database.ConfidenceLow, Device: "192.168.1.20"},
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 90, WatchedAt: evening.Add(30 * time.Minute), Confidence: database.ConfidenceMedium, Device: "Shield"},
+		// A second device watching at the same time is separate viewing
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 30, WatchedAt: evening.Add(40 * time.Minute), Confidence: database.ConfidenceMedium, Device: "Fire TV"},
+	)
+
+	result, err := Run(db, time.Time{}, false)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if result.Removed != 1 || result.Merges[0].Removed.ID != items[0].ID || result.Merges[0].KeptID != items[1].ID {
+		t.Fatalf("Expected the network session merged into the Shield session, got %+v", result)
+	}
+
+	left, _ := db.GetInferredHistory(time.Time{})
+	if len(left) != 2 {
+		t.Fatalf("Expected 2 sessions left, got %+v", left)
+	}
+	for _, wh := range left {
+		if wh.ID == items[1].ID && (!wh.WatchedAt.Equal(evening) || wh.DurationMinutes != 120 || wh.Title != "Device Usage") {
+			t.Errorf("Expected the Shield session to span 20:00-22:00, got %+v", wh)
+		}
+		if wh.ID == items[2].ID && wh.DurationMinutes != 30 {
+			t.Errorf("Expected the Fire TV session unchanged, got %+v", wh)
+		}
+	}
+}
+
+func TestRunScreenTimeCoversItsDay(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	// Screen Time totals are stored at noon but may have been watched any time
+	noon := time.Date(2025, 1, 14, 12, 0, 0, 0, time.UTC)
+	insert(t, db,
+		database.WatchHistory{ServiceID: netflixID, Title: database.ScreenTimeTitle, DurationMinutes: 60, WatchedAt: noon, Confidence: database.ConfidenceMedium},
+		database.WatchHistory{ServiceID: netflixID, Title: database.ScreenTimeTitle, DurationMinutes: 45, WatchedAt: noon.AddDate(0, 0, 1), Confidence: database.ConfidenceMedium},
+		database.WatchHistory{ServiceID: netflixID, Title: "Stranger Things", DurationMinutes: 50, WatchedAt: noon.Add(8 * time.Hour)},
+	)
+
+	result, err := Run(db, time.Time{}, false)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if result.Removed != 1 || !result.Merges[0].Removed.WatchedAt.Equal(noon) {
+		t.Errorf("Expected the total for the scraped day removed, got %+v", result)
+	}
+}
+
+func TestRunMergesSessionsCoveredByCombinedSpan(t *testing.T) {
+	db, netflixID := setupTestDB(t)
+	defer db.Close()
+
+	// Traffic through the evening covers both device sessions, so once the
+	// first grows to cover it the second is part of the same viewing
+	evening := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	items := insert(t, db,
+		database.WatchHistory{ServiceID: netflixID, Title: "Network Activity", DurationMinutes: 150, WatchedAt: evening, Confidence: database.ConfidenceLow},
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 70, WatchedAt: evening.Add(10 * time.Minute), Confidence: database.ConfidenceMedium},
+		database.WatchHistory{ServiceID: netflixID, Title: "Device Usage", DurationMinutes: 30, WatchedAt: evening.Add(85 * time.Minute), Confidence: database.ConfidenceMedium},
+	)
+
+	result, err := Run(db, time.Time{}, false)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if result.Removed != 2 {
+		t.Fatalf("Expected 2 sessions merged, got %+v", result)
+	}
+	for _, merge := range result.Merges {
+		if merge.KeptID != items[1].ID {
+			t.Errorf("Expected every merge into the longer device session, got %+v", merge)
+		}
+	}
+
+	left, _ := db.GetInferredHistory(time.Time{})
+	if len(left) != 1 || left[0].ID != items[1].ID || !left[0].WatchedAt.Equal(evening) || left[0].DurationMinutes != 150 {
+		t.Errorf("Expected one session spanning the evening, got %+v", left)
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2025, 1, 14, 10, 30, 0, 0, time.UTC) // A Tuesday
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"0 3 * * *", time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 14, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * mon-fri", time.Date(2025, 1, 14, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 mar *", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 20 * tue", time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.expected) {
+			t.Errorf("Next(%q) = %v, expected %v", tt.expr, got, tt.expected)
+		}
+	}
+}
+
+func TestScheduleNextImpossibleDate(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no run for February 30th, got %v", next)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "0 3 * *", "60 * * * *", "0 25 * * *", "*/0 * * * *", "0 0 * foo *", "5-1 * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected an error parsing %q", expr)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// job runs a group of services that share a schedule
+type job struct {
+	expr     string
+	schedule *Schedule
+	services []string
+	next     time.Time // When the next run starts, which scraper.randomize can put after it's due
+}
+
+// Scheduler runs registered scrapers on scraper.schedule, or on a service's
+// own schedule when it sets one
+type Scheduler struct {
+	manager   *scraper.Manager
+	jobs      []*job
+	randomize config.RandomizeConfig
+	random    func() float64 // Returns a number in [0, 1), replaced in tests
+}
+
+// New builds the schedule for every scraper registered with manager,
+// returning an error for invalid cron expressions
+func New(cfg *config.Config, manager *scraper.Manager) (*Scheduler, error) {
+	overrides := make(map[string]string)
+	for key, svc := range cfg.Services {
+		if svc.Schedule != "" {
+			overrides[scraper.ServiceNameFor(cfg, key)] = svc.Schedule
+		}
+	}
+
+	byExpr := make(map[string]*job)
+	for _, name := range manager.Names() {
+		expr := cfg.Scraper.Schedule
+		source := "scraper.schedule"
+		if override, ok := overrides[name]; ok {
+			expr, source = override, "schedule for "+name
+		}
+
+		j, ok := byExpr[expr]
+		if !ok {
+			schedule, err := Parse(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", source, err)
+			}
+			j = &job{expr: expr, schedule: schedule}
+			byExpr[expr] = j
+		}
+		j.services = append(j.services, name)
+	}
+
+	s := &Scheduler{manager: manager, randomize: cfg.Scraper.Randomize, random: rand.Float64}
+	for _, j := range byExpr {
+		s.jobs = append(s.jobs, j)
+	}
+	sort.Slice(s.jobs, func(i, k int) bool { return s.jobs[i].services[0] < s.jobs[k].services[0] })
+	return s, nil
+}
+
+// Run schedules runs until ctx is canceled. Services due at the same time run
+// one after another, and a run that is still going when the next one is due
+// skips that run rather than queueing it.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, j := range s.jobs {
+		s.plan(j, now)
+		log.Printf("Scheduled %s on %q, next run %s", strings.Join(j.services, ", "), j.expr, formatNext(j.next))
+	}
+
+	for {
+		next := s.nextRun()
+		if next.IsZero() {
+			log.Println("No upcoming scheduled runs")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runDue(ctx, time.Now())
+	}
+}
+
+// nextRun returns the earliest upcoming run, or the zero time if none
+func (s *Scheduler) nextRun() time.Time {
+	var next time.Time
+	for _, j := range s.jobs {
+		if !j.next.IsZero() && (next.IsZero() || j.next.Before(next)) {
+			next = j.next
+		}
+	}
+	return next
+}
+
+// runDue runs every job due at now, then schedules each one's next run
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	for _, j := range s.jobs {
+		if j.next.IsZero() || j.next.After(now) {
+			continue
+		}
+
+		// Now and then leave a run out, as a person wouldn't check every day
+		if s.randomize.SkipChance > 0 && s.random() < s.randomize.SkipChance {
+			s.plan(j, now)
+			log.Printf("Skipping this scheduled run of %s, next run %s", strings.Join(j.services, ", "), formatNext(j.next))
+			continue
+		}
+
+		summary := s.manager.RunMany(ctx, j.services, scraper.RunOptions{Trigger: scraper.TriggerScheduled})
+		for _, result := range summary.Results {
+			if errors.Is(result.Error, scraper.ErrRunInProgress) {
+				log.Printf("Scheduled run of %s skipped: it was already being scraped", result.ServiceName)
+				continue
+			}
+			if !result.Success {
+				log.Printf("Scheduled run of %s failed after %s: %v", result.ServiceName, result.Duration().Round(time.Second), result.Error)
+				continue
+			}
+			log.Printf("Scheduled run of %s scraped %d items in %s", result.ServiceName, result.ItemsScraped, result.Duration().Round(time.Second))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.plan(j, time.Now())
+		log.Printf("Next scheduled run of %s: %s", strings.Join(j.services, ", "), formatNext(j.next))
+	}
+}
+
+// plan sets when a job next runs: when its schedule is next due after from,
+// delayed by a random part of scraper.randomize.window_minutes
+func (s *Scheduler) plan(j *job, from time.Time) {
+	j.next = j.schedule.Next(from)
+	if j.next.IsZero() || s.randomize.WindowMinutes <= 0 {
+		return
+	}
+	window := time.Duration(s.randomize.WindowMinutes) * time.Minute
+	j.next = j.next.Add(time.Duration(s.random() * float64(window))).Truncate(time.Second)
+}
+
+// RunTask calls task each time schedule comes due until ctx is canceled, for
+// periodic work other than scraping
+func RunTask(ctx context.Context, schedule *Schedule, task func(ctx context.Context)) {
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		task(ctx)
+	}
+}
+
+// formatNext describes a next run time for logging
+func formatNext(next time.Time) string {
+	if next.IsZero() {
+		return "never"
+	}
+	return next.Format(time.RFC3339)
+}
@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/scraper"
+)
+
+// stubScraper returns no items
+type stubScraper struct {
+	name string
+	runs int
+}
+
+func (s *stubScraper) Name() string {
+	return s.name
+}
+
+func (s *stubScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	s.runs++
+	return nil, nil
+}
+
+func setupTestScheduler(t *testing.T, cfg *config.Config) (*Scheduler, *database.DB, map[string]*stubScraper) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+
+	manager := scraper.NewManager(db, cfg)
+	stubs := map[string]*stubScraper{
+		"Netflix": {name: "Netflix"},
+		"Hulu":    {name: "Hulu"},
+	}
+	for _, stub := range stubs {
+		manager.Register(stub)
+		if err := db.EnableService(stub.name); err != nil {
+			t.Fatalf("Failed to enable %s: %v", stub.name, err)
+		}
+	}
+
+	s, err := New(cfg, manager)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	return s, db, stubs
+}
+
+func TestNewGroupsServicesBySchedule(t *testing.T) {
+	cfg := &config.Config{
+		Scraper: config.ScraperConfig{Schedule: "0 3 * * *"},
+		Services: map[string]config.ServiceConfig{
+			"netflix": {Enabled: true},
+			"hulu":    {Enabled: true, Schedule: "0 */6 * * *"},
+		},
+	}
+	s, db, _ := setupTestScheduler(t, cfg)
+	defer db.Close()
+
+	if len(s.jobs) != 2 {
+		t.Fatalf("Expected 2 jobs, got %d", len(s.jobs))
+	}
+	for _, j := range s.jobs {
+		if len(j.services) != 1 {
+			t.Errorf("Expected one service per job, got %v", j.services)
+		}
+		if j.services[0] == "Hulu" && j.expr != "0 */6 * * *" {
+			t.Errorf("Expected Hulu on its own schedule, got %q", j.expr)
+		}
+	}
+}
+
+func TestNewRejectsInvalidSchedule(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{Scraper: config.ScraperConfig{Schedule: "daily"}}
+	manager := scraper.NewManager(db, cfg)
+	manager.Register(&stubScraper{name: "Netflix"})
+
+	if _, err := New(cfg, manager); err == nil {
+		t.Error("Expected an error for an invalid schedule")
+	}
+}
+
+func TestRunDueRecordsScheduledRuns(t *testing.T) {
+	cfg := &config.Config{
+		Scraper: config.ScraperConfig{Schedule: "0 3 * * *"},
+		Services: map[string]config.ServiceConfig{
+			"netflix": {Enabled: true},
+			"hulu":    {Enabled: true, Schedule: "0 4 * * *"},
+		},
+	}
+	s, db, stubs := setupTestScheduler(t, cfg)
+	defer db.Close()
+
+	now := time.Date(2025, 1, 14, 3, 0, 0, 0, time.Local)
+	for _, j := range s.jobs {
+		j.next = j.schedule.Next(now.Add(-time.Minute))
+	}
+	s.runDue(context.Background(), now)
+
+	if stubs["Netflix"].runs != 1 || stubs["Hulu"].runs != 0 {
+		t.Errorf("Expected only Netflix to run at 3 AM, got Netflix %d and Hulu %d runs", stubs["Netflix"].runs, stubs["Hulu"].runs)
+	}
+
+	runs, err := db.GetLatestScraperRuns()
+	if err != nil {
+		t.Fatalf("Failed to get runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].TriggeredBy != scraper.TriggerScheduled {
+		t.Errorf("Expected one scheduled run, got %+v", runs)
+	}
+	for _, j := range s.jobs {
+		if !j.next.After(now) {
+			t.Errorf("Expected the next run after %v, got %v", now, j.next)
+		}
+	}
+}
+
+func TestRandomizedSchedule(t *testing.T) {
+	cfg := &config.Config{
+		Scraper: config.ScraperConfig{
+			Schedule:  "0 3 * * *",
+			Randomize: config.RandomizeConfig{WindowMinutes: 60, SkipChance: 0.2},
+		},
+		Services: map[string]config.ServiceConfig{"netflix": {Enabled: true}},
+	}
+	s, db, stubs := setupTestScheduler(t, cfg)
+	defer db.Close()
+
+	// Runs start part way into the window after they're due
+	s.random = func() float64 { return 0.5 }
+	now := time.Date(2025, 1, 14, 2, 0, 0, 0, time.Local)
+	j := s.jobs[0]
+	s.plan(j, now)
+	if want := time.Date(2025, 1, 14, 3, 30, 0, 0, time.Local); !j.next.Equal(want) {
+		t.Errorf("Expected the run to start at %v, got %v", want, j.next)
+	}
+
+	// A low roll skips the run and plans the next one
+	s.random = func() float64 { return 0.1 }
+	due := j.next
+	s.runDue(context.Background(), due)
+	if stubs["Netflix"].runs != 0 || stubs["Hulu"].runs != 0 {
+		t.Errorf("Expected the run to be skipped, got %d Netflix runs", stubs["Netflix"].runs)
+	}
+	if !j.next.After(due) {
+		t.Errorf("Expected the next run after the skipped one, got %v", j.next)
+	}
+
+	s.random = func() float64 { return 0.9 }
+	j.next = due
+	s.runDue(context.Background(), due)
+	if stubs["Netflix"].runs+stubs["Hulu"].runs != 2 {
+		t.Errorf("Expected both services to run on a high roll, got Netflix %d and Hulu %d runs", stubs["Netflix"].runs, stubs["Hulu"].runs)
+	}
+}
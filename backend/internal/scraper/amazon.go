@@ -2,31 +2,60 @@ package scraper
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 	"github.com/jgoulah/streamtime/internal/config"
 	"github.com/jgoulah/streamtime/internal/database"
 )
 
+// amazonDefaultMarketplace is the Amazon site accounts belong to unless the
+// service sets a marketplace
+const amazonDefaultMarketplace = "amazon.com"
+
+// amazonHistoryPath is the Prime Video watch history page on every marketplace
+const amazonHistoryPath = "/gp/video/settings/watch-history"
+
+// amazonMaxScrolls caps how often the history page is scrolled to load older
+// date sections in one run
+const amazonMaxScrolls = 100
+
+// Selectors on the watch history page
+const (
+	amazonSectionSel = `div.RdNoU_.j98KWz` // One section per day, headed by an h3 date
+	amazonShowSel    = `div._6YbHut`       // A show or movie within a day
+	amazonTitleSel   = `a._1NNx6V.ZrYV9r`
+	amazonEpisodeSel = `p.vTfuZU` // Episodes of a show watched that day
+)
+
+// amazonDateWords translates the German marketplace's date headings, so
+// "28. Oktober 2024" and "Gestern" parse like their English equivalents
+var amazonDateWords = strings.NewReplacer(
+	"Heute", "Today", "Gestern", "Yesterday",
+	"Januar", "January", "Februar", "February", "März", "March", "Mai", "May",
+	"Juni", "June", "Juli", "July", "Oktober", "October", "Dezember", "December",
+)
+
 // AmazonScraper implements the Scraper interface for Amazon Prime Video
 type AmazonScraper struct {
-	config     *config.Config
-	db         *database.DB
-	serviceKey string
+	config      *config.Config
+	db          *database.DB
+	serviceKey  string
+	instanceKey string // Key of this instance in the services config
 }
 
 // NewAmazonScraper creates a new Amazon scraper
 func NewAmazonScraper(cfg *config.Config, db *database.DB) *AmazonScraper {
 	return &AmazonScraper{
-		config:     cfg,
-		db:         db,
-		serviceKey: "Amazon Video",
+		config:      cfg,
+		db:          db,
+		serviceKey:  "Amazon Video",
+		instanceKey: "amazon_video",
 	}
 }
 
@@ -38,10 +67,11 @@ func (s *AmazonScraper) Name() string {
 // Scrape fetches viewing history from Amazon Prime Video
 func (s *AmazonScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
 	// Get service config
-	serviceCfg, ok := s.config.Services["amazon_video"]
+	serviceCfg, ok := s.config.Services[s.instanceKey]
 	if !ok || !serviceCfg.Enabled {
-		return nil, fmt.Errorf("amazon_video not configured or not enabled")
+		return nil, fmt.Errorf("%s not configured or not enabled", s.instanceKey)
 	}
+	serviceCfg.Cookies = serviceCookies(ctx, s.db, s.serviceKey, serviceCfg, s.marketplace())
 
 	// Create chrome context with timeout
 	timeout := time.Duration(s.config.Scraper.Timeout) * time.Second
@@ -66,42 +96,73 @@ func (s *AmazonScraper) Scrape(ctx context.Context) ([]database.WatchHistory, er
 		return nil, fmt.Errorf("failed to load cookies: %w", err)
 	}
 
+	// Look up this instance's service so existing entries can be detected
+	var serviceID int64
+	if service, err := s.db.GetServiceByName(s.serviceKey); err == nil && service != nil {
+		serviceID = service.ID
+	}
+
 	// Navigate to watch history
 	if err := s.navigateToWatchHistory(chromeCtx); err != nil {
 		return nil, fmt.Errorf("navigation failed: %w", err)
 	}
+	s.loadMoreSections(chromeCtx, serviceID)
 
 	// Extract viewing history
 	items, err := s.extractViewingHistory(chromeCtx)
 	if err != nil {
 		return nil, fmt.Errorf("extraction failed: %w", err)
 	}
+	for i := range items {
+		items[i].ServiceID = serviceID
+	}
 
 	log.Printf("Amazon scraper extracted %d items", len(items))
 	return items, nil
 }
 
+// CheckAuth loads the watch history page to see whether the cookies are still signed in
+func (s *AmazonScraper) CheckAuth(ctx context.Context) (*AuthStatus, error) {
+	serviceCfg, err := serviceConfigFor(ctx, s.config, s.db, s.instanceKey, s.serviceKey, s.marketplace())
+	if err != nil {
+		return nil, err
+	}
+
+	return checkAuth(ctx, s.config, s.serviceKey, serviceCfg, s.loadCookies, authPage{
+		accountURL:   s.historyURL(),
+		loginMarkers: []string{"/ap/signin"},
+	})
+}
+
+// marketplace returns the Amazon site this instance's account belongs to,
+// e.g. "amazon.co.uk"
+func (s *AmazonScraper) marketplace() string {
+	if marketplace := s.config.Services[s.instanceKey].Marketplace; marketplace != "" {
+		return marketplace
+	}
+	return amazonDefaultMarketplace
+}
+
+// historyURL returns the watch history page on the account's marketplace
+func (s *AmazonScraper) historyURL() string {
+	return "https://www." + s.marketplace() + amazonHistoryPath
+}
+
 // loadCookies loads authentication cookies into the browser
 func (s *AmazonScraper) loadCookies(ctx context.Context, cookies []config.Cookie) error {
-	// First navigate to amazon.com to set cookies
-	if err := chromedp.Run(ctx, chromedp.Navigate("https://www.amazon.com")); err != nil {
-		return fmt.Errorf("failed to navigate to amazon.com: %w", err)
+	// First navigate to the marketplace to set cookies
+	marketplace := s.marketplace()
+	if err := chromedp.Run(ctx, navigate("https://www."+marketplace)); err != nil {
+		return fmt.Errorf("failed to navigate to %s: %w", marketplace, err)
 	}
 
 	// Wait a moment for the page to load
 	time.Sleep(2 * time.Second)
 
 	// Convert and set cookies
+	defaults := cookieDefaults{domain: "." + marketplace, lifetime: 365 * 24 * time.Hour}
 	for _, cookie := range cookies {
-		expr := cdp.TimeSinceEpoch(time.Now().Add(365 * 24 * time.Hour))
-		if err := chromedp.Run(ctx,
-			network.SetCookie(cookie.Name, cookie.Value).
-				WithDomain(".amazon.com").
-				WithPath("/").
-				WithHTTPOnly(false).
-				WithSecure(true).
-				WithExpires(&expr),
-		); err != nil {
+		if err := chromedp.Run(ctx, setCookieParams(cookie, defaults)); err != nil {
 			return fmt.Errorf("failed to set cookie %s: %w", cookie.Name, err)
 		}
 		log.Printf("Set cookie: %s", cookie.Name)
@@ -112,12 +173,12 @@ func (s *AmazonScraper) loadCookies(ctx context.Context, cookies []config.Cookie
 
 // navigateToWatchHistory navigates to the Prime Video watch history page
 func (s *AmazonScraper) navigateToWatchHistory(ctx context.Context) error {
-	url := "https://www.amazon.com/gp/video/settings/watch-history"
+	url := s.historyURL()
 
 	log.Printf("Navigating to Amazon watch history: %s", url)
 
 	if err := chromedp.Run(ctx,
-		chromedp.Navigate(url),
+		navigateHistory(url),
 		chromedp.WaitReady("body"),
 	); err != nil {
 		return fmt.Errorf("failed to navigate to watch history: %w", err)
@@ -133,20 +194,110 @@ func (s *AmazonScraper) navigateToWatchHistory(ctx context.Context) error {
 	return nil
 }
 
+// loadMoreSections scrolls the history page to load older date sections
+// until the oldest entry loaded is past the run's lookback or already
+// stored, scrolling stops loading more, or amazonMaxScrolls is reached
+func (s *AmazonScraper) loadMoreSections(ctx context.Context, serviceID int64) {
+	since := Lookback(ctx)
+	log.Printf("Loading Amazon watch history (will stop at existing data or %s)...", formatSince(since))
+
+	script := fmt.Sprintf(`
+		(() => {
+			const sections = document.querySelectorAll(%q);
+			const last = sections[sections.length - 1];
+			if (!last) return JSON.stringify({count: 0});
+			const text = el => el ? el.textContent.trim() : '';
+			const shows = last.querySelectorAll(%q);
+			const show = shows[shows.length - 1];
+			const episodes = show ? show.querySelectorAll(%q) : [];
+			return JSON.stringify({
+				count: sections.length,
+				date: text(last.querySelector('h3')),
+				title: show ? text(show.querySelector(%q)) : '',
+				episode: text(episodes[episodes.length - 1]),
+			});
+		})()
+	`, amazonSectionSel, amazonShowSel, amazonEpisodeSel, amazonTitleSel)
+
+	previousCount, stableScrolls := 0, 0
+	for scroll := 1; scroll <= amazonMaxScrolls; scroll++ {
+		var raw string
+		if err := chromedp.Run(ctx, chromedp.Evaluate(script, &raw)); err != nil {
+			log.Printf("Error reading loaded date sections: %v", err)
+			return
+		}
+		var last struct {
+			Count   int    `json:"count"`
+			Date    string `json:"date"`
+			Title   string `json:"title"`
+			Episode string `json:"episode"`
+		}
+		if err := json.Unmarshal([]byte(raw), &last); err != nil || last.Count == 0 {
+			return
+		}
+
+		// The last entry of the last section is the oldest loaded so far
+		oldest, err := s.parseRaw(database.RawPayload{Title: last.Title, Episode: last.Episode, Date: last.Date, ScrapedAt: time.Now()})
+		if err == nil && last.Title != "" {
+			if !since.IsZero() && oldest.WatchedAt.Before(since) {
+				log.Printf("Reached %s after %d scrolls. Stopping. Date sections: %d", oldest.WatchedAt.Format("2006-01-02"), scroll-1, last.Count)
+				return
+			}
+			if serviceID != 0 && !IsRefresh(ctx) {
+				exists, err := historyDB(ctx, s.db).WatchHistoryExists(serviceID, oldest.Title, oldest.EpisodeInfo, oldest.WatchedAt)
+				if err == nil && exists {
+					log.Printf("Found existing entry '%s' from %s after %d scrolls. Stopping. Date sections: %d",
+						oldest.Title, oldest.WatchedAt.Format("2006-01-02"), scroll-1, last.Count)
+					return
+				}
+			}
+		}
+
+		if last.Count == previousCount {
+			stableScrolls++
+			if stableScrolls >= 3 {
+				log.Printf("No more date sections after %d scrolls. Date sections: %d", scroll-1, last.Count)
+				return
+			}
+		} else {
+			stableScrolls = 0
+		}
+		previousCount = last.Count
+
+		// The page loads the next sections as the end of the list comes into view
+		if err := waitHistoryPage(ctx); err != nil {
+			if !pageLimitReached(err, s.serviceKey) {
+				log.Printf("Error waiting to load more history: %v", err)
+			}
+			return
+		}
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(`window.scrollTo(0, document.body.scrollHeight)`, nil),
+			scrollPause(s.config),
+		); err != nil {
+			log.Printf("Error scrolling watch history: %v", err)
+			return
+		}
+	}
+	log.Printf("Stopped after %d scrolls. Date sections: %d", amazonMaxScrolls, previousCount)
+}
+
 // extractViewingHistory extracts watch history from the current page
 func (s *AmazonScraper) extractViewingHistory(ctx context.Context) ([]database.WatchHistory, error) {
 	var items []database.WatchHistory
 	itemCount := 0
 
 	log.Println("Extracting viewing history from Amazon Prime Video...")
+	snapshotDOM(ctx)
 
-	// Find all date sections (div.RdNoU_.j98KWz)
+	// Find all date sections
 	var dateSections []*cdp.Node
 	if err := chromedp.Run(ctx,
-		chromedp.Nodes(`div.RdNoU_.j98KWz`, &dateSections, chromedp.ByQueryAll),
+		chromedp.Nodes(amazonSectionSel, &dateSections, chromedp.ByQueryAll),
 	); err != nil {
 		return nil, fmt.Errorf("failed to find date sections: %w", err)
 	}
+	RecordSelector(ctx, amazonSectionSel, len(dateSections))
 
 	log.Printf("Found %d date sections", len(dateSections))
 
@@ -160,8 +311,8 @@ func (s *AmazonScraper) extractViewingHistory(ctx context.Context) ([]database.W
 			continue
 		}
 
-		watchDate, err := parseAmazonDate(dateText)
-		if err != nil {
+		scrapedAt := time.Now()
+		if _, err := parseAmazonDate(dateText, scrapedAt); err != nil {
 			log.Printf("Failed to parse date '%s': %v", dateText, err)
 			continue
 		}
@@ -171,11 +322,12 @@ func (s *AmazonScraper) extractViewingHistory(ctx context.Context) ([]database.W
 		// Find all show/movie containers within this date section
 		var showContainers []*cdp.Node
 		if err := chromedp.Run(ctx,
-			chromedp.Nodes(`div._6YbHut`, &showContainers, chromedp.ByQueryAll, chromedp.FromNode(dateSection)),
+			chromedp.Nodes(amazonShowSel, &showContainers, chromedp.ByQueryAll, chromedp.FromNode(dateSection)),
 		); err != nil {
 			log.Printf("Failed to find show containers for date %s: %v", dateText, err)
 			continue
 		}
+		RecordSelector(ctx, amazonShowSel, len(showContainers))
 
 		log.Printf("Found %d shows/movies for date %s", len(showContainers), dateText)
 
@@ -183,28 +335,28 @@ func (s *AmazonScraper) extractViewingHistory(ctx context.Context) ([]database.W
 			// Extract the title
 			var title string
 			if err := chromedp.Run(ctx,
-				chromedp.TextContent(`a._1NNx6V.ZrYV9r`, &title, chromedp.ByQuery, chromedp.FromNode(container)),
+				chromedp.TextContent(amazonTitleSel, &title, chromedp.ByQuery, chromedp.FromNode(container)),
 			); err != nil {
 				log.Printf("Failed to extract title: %v", err)
 				continue
 			}
 
 			title = strings.TrimSpace(title)
+			RecordSelector(ctx, amazonTitleSel, boolHit(title != ""))
 			log.Printf("Processing: %s", title)
 
-			// Check if there are episodes (p.vTfuZU)
+			// Check if there are episodes
 			var episodeNodes []*cdp.Node
 			if err := chromedp.Run(ctx,
-				chromedp.Nodes(`p.vTfuZU`, &episodeNodes, chromedp.ByQueryAll, chromedp.FromNode(container)),
+				chromedp.Nodes(amazonEpisodeSel, &episodeNodes, chromedp.ByQueryAll, chromedp.FromNode(container)),
 			); err != nil || len(episodeNodes) == 0 {
 				// No episodes - this is a movie or single video
-				item := database.WatchHistory{
-					Title:           title,
-					DurationMinutes: 0, // Amazon doesn't show duration in history
-					WatchedAt:       watchDate,
-					EpisodeInfo:     "",
-					Created:         time.Now(),
-				}
+				item, _ := s.parseRaw(database.RawPayload{
+					Title:     title,
+					Date:      dateText,
+					HTML:      nodeHTML(ctx, container),
+					ScrapedAt: scrapedAt,
+				})
 				items = append(items, item)
 				itemCount++
 				log.Printf("Added movie/video: %s", title)
@@ -223,27 +375,26 @@ func (s *AmazonScraper) extractViewingHistory(ctx context.Context) ([]database.W
 
 					episodeName = strings.TrimSpace(episodeName)
 
-					// Create entry with format "Title - Episode Name"
-					item := database.WatchHistory{
-						Title:           fmt.Sprintf("%s - %s", title, episodeName),
-						DurationMinutes: 0, // Amazon doesn't show duration in history
-						WatchedAt:       watchDate,
-						EpisodeInfo:     episodeName,
-						Created:         time.Now(),
-					}
+					item, _ := s.parseRaw(database.RawPayload{
+						Title:     title,
+						Episode:   episodeName,
+						Date:      dateText,
+						HTML:      nodeHTML(ctx, container),
+						ScrapedAt: scrapedAt,
+					})
 					items = append(items, item)
 					itemCount++
 					log.Printf("Added episode: %s - %s", title, episodeName)
 
-					if s.config.Scraper.TestMode && itemCount >= s.config.Scraper.TestLimit {
-						log.Printf("Test mode: stopping at %d items", s.config.Scraper.TestLimit)
+					if limit := itemLimit(ctx, s.config); limit > 0 && itemCount >= limit {
+						log.Printf("Test mode: stopping at %d items", limit)
 						return items, nil
 					}
 				}
 			}
 
-			if s.config.Scraper.TestMode && itemCount >= s.config.Scraper.TestLimit {
-				log.Printf("Test mode: stopping at %d items", s.config.Scraper.TestLimit)
+			if limit := itemLimit(ctx, s.config); limit > 0 && itemCount >= limit {
+				log.Printf("Test mode: stopping at %d items", limit)
 				return items, nil
 			}
 		}
@@ -253,6 +404,36 @@ func (s *AmazonScraper) extractViewingHistory(ctx context.Context) ([]database.W
 	return items, nil
 }
 
+// Reparse re-derives an entry from the raw payload stored when it was
+// scraped, resolving relative dates against the original scrape time
+func (s *AmazonScraper) Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error) {
+	return s.parseRaw(raw)
+}
+
+// parseRaw builds an entry from the show title, episode name and date
+// section heading read from the history page
+func (s *AmazonScraper) parseRaw(raw database.RawPayload) (database.WatchHistory, error) {
+	watchDate, err := parseAmazonDate(raw.Date, raw.ScrapedAt)
+	if err != nil {
+		return database.WatchHistory{}, err
+	}
+
+	// Episodes are stored as "Title - Episode Name"
+	title := raw.Title
+	if raw.Episode != "" {
+		title = fmt.Sprintf("%s - %s", raw.Title, raw.Episode)
+	}
+
+	return database.WatchHistory{
+		Title:           title,
+		DurationMinutes: 0, // Amazon doesn't show duration in history
+		WatchedAt:       watchDate,
+		EpisodeInfo:     raw.Episode,
+		Raw:             &raw,
+		Created:         time.Now(),
+	}, nil
+}
+
 // Helper function to parse duration string (e.g., "1h 30m" -> minutes)
 func parseDuration(durationStr string) int {
 	// TODO: Implement based on Amazon's duration format
@@ -261,10 +442,11 @@ func parseDuration(durationStr string) int {
 }
 
 // parseAmazonDate parses Amazon's date format from watch history
-// Handles formats like "October 28, 2024", "Today", "Yesterday"
-func parseAmazonDate(dateStr string) (time.Time, error) {
-	dateStr = strings.TrimSpace(dateStr)
-	now := time.Now()
+// Handles formats like "October 28, 2024", "28 October 2024", "Today",
+// "Yesterday" and their German equivalents, with relative dates resolved
+// against now
+func parseAmazonDate(dateStr string, now time.Time) (time.Time, error) {
+	dateStr = amazonDateWords.Replace(strings.TrimSpace(dateStr))
 
 	// Handle relative dates
 	switch strings.ToLower(dateStr) {
@@ -277,19 +459,20 @@ func parseAmazonDate(dateStr string) (time.Time, error) {
 
 	// Try common date formats Amazon might use
 	formats := []string{
-		"January 2, 2006",   // "October 28, 2024"
-		"Jan 2, 2006",       // "Oct 28, 2024"
-		"1/2/2006",          // "10/28/2024"
-		"2006-01-02",        // "2024-10-28"
-		"January 2",         // "October 28" (assumes current year)
-		"Jan 2",             // "Oct 28" (assumes current year)
+		"January 2, 2006", // "October 28, 2024"
+		"Jan 2, 2006",     // "Oct 28, 2024"
+		"1/2/2006",        // "10/28/2024"
+		"2006-01-02",      // "2024-10-28"
+		"2 January 2006",  // "28 October 2024" (amazon.co.uk)
+		"2. January 2006", // "28. Oktober 2024" (amazon.de)
+		"January 2",       // "October 28" (most recent, see inferYear)
+		"Jan 2",           // "Oct 28" (most recent, see inferYear)
 	}
 
 	for _, format := range formats {
 		if t, err := time.Parse(format, dateStr); err == nil {
-			// If format doesn't include year, assume current year
 			if !strings.Contains(format, "2006") {
-				t = time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+				t = withInferredYear(t, now.In(time.Local))
 			}
 			return t, nil
 		}
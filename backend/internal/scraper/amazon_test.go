@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestParseAmazonDate(t *testing.T) {
+	now := time.Date(2024, 11, 2, 15, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"October 28, 2024", "2024-10-28", false},
+		{"Oct 28, 2024", "2024-10-28", false},
+		{"28 October 2024", "2024-10-28", false},
+		{"28. Oktober 2024", "2024-10-28", false},
+		{"3. März 2024", "2024-03-03", false},
+		{"Today", "2024-11-02", false},
+		{"Gestern", "2024-11-01", false},
+		{"last week", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAmazonDate(tt.input, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestAmazonMarketplace(t *testing.T) {
+	db, _ := database.New(":memory:")
+	defer db.Close()
+
+	scraper := NewAmazonScraper(&config.Config{}, db)
+	if got := scraper.historyURL(); got != "https://www.amazon.com/gp/video/settings/watch-history" {
+		t.Errorf("Expected the amazon.com history page by default, got %s", got)
+	}
+
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"amazon_video": {Enabled: true, Marketplace: "amazon.co.uk"},
+	}}
+	scraper = NewAmazonScraper(cfg, db)
+	if got := scraper.historyURL(); got != "https://www.amazon.co.uk/gp/video/settings/watch-history" {
+		t.Errorf("Expected the amazon.co.uk history page, got %s", got)
+	}
+}
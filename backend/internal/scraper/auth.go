@@ -0,0 +1,136 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// authCheckTimeout bounds a cookie check, which only loads a single page
+const authCheckTimeout = 45 * time.Second
+
+// defaultLoginMarkers are URL fragments of the sign-in pages services
+// redirect to when cookies have expired
+var defaultLoginMarkers = []string{"login", "signin", "sign-in", "sign_in"}
+
+// AuthStatus is the outcome of checking whether a service's cookies still work
+type AuthStatus struct {
+	Service    string    `json:"service"`
+	LoggedIn   bool      `json:"logged_in"`
+	CheckedURL string    `json:"checked_url"`         // Account page that was requested
+	FinalURL   string    `json:"final_url,omitempty"` // Where the browser ended up
+	Reason     string    `json:"reason,omitempty"`    // Why the session counts as logged out
+	CheckedAt  time.Time `json:"checked_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// AuthChecker is implemented by scrapers that can verify their cookies by
+// loading an account page, without running a full scrape
+type AuthChecker interface {
+	CheckAuth(ctx context.Context) (*AuthStatus, error)
+}
+
+// authPage describes the page used to check a service's session
+type authPage struct {
+	accountURL   string   // Page that redirects to sign-in when logged out
+	loginMarkers []string // URL fragments that mean the browser was sent to sign-in
+}
+
+// checkAuth loads cookies into a short-lived browser session and requests
+// the account page, reporting logged out if it redirects to a sign-in page
+func checkAuth(ctx context.Context, cfg *config.Config, serviceName string, serviceCfg config.ServiceConfig,
+	loadCookies func(ctx context.Context, cookies []config.Cookie) error, page authPage) (*AuthStatus, error) {
+	status := &AuthStatus{
+		Service:    serviceName,
+		CheckedURL: page.accountURL,
+		CheckedAt:  time.Now(),
+	}
+	defer func() {
+		status.DurationMS = time.Since(status.CheckedAt).Milliseconds()
+	}()
+
+	if len(serviceCfg.Cookies) == 0 {
+		status.Reason = "no cookies configured"
+		return status, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, authCheckTimeout)
+	defer cancel()
+	chromeCtx, chromeCancel := newChromeContext(ctx, cfg)
+	defer chromeCancel()
+
+	if err := loadCookies(chromeCtx, serviceCfg.Cookies); err != nil {
+		return nil, fmt.Errorf("failed to load cookies: %w", err)
+	}
+
+	log.Printf("Checking %s session: %s", serviceName, page.accountURL)
+	if err := chromedp.Run(chromeCtx,
+		navigate(page.accountURL),
+		chromedp.WaitReady("body"),
+		chromedp.Location(&status.FinalURL),
+	); err != nil {
+		return nil, fmt.Errorf("failed to load account page: %w", err)
+	}
+
+	markers := page.loginMarkers
+	if len(markers) == 0 {
+		markers = defaultLoginMarkers
+	}
+	if isLoginURL(status.FinalURL, markers) {
+		status.Reason = "redirected to sign-in"
+		return status, nil
+	}
+
+	status.LoggedIn = true
+	return status, nil
+}
+
+// isLoginURL reports whether a URL's host or path contains one of the markers.
+// The query is ignored since account pages often carry a return URL.
+func isLoginURL(rawURL string, markers []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	target := strings.ToLower(u.Host + u.Path)
+	for _, marker := range markers {
+		if strings.Contains(target, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceConfigFor returns an instance's config with the cookies a scrape would
+// use, or an error if it isn't enabled
+func serviceConfigFor(ctx context.Context, cfg *config.Config, db *database.DB, instanceKey, serviceName, cookieDomain string) (config.ServiceConfig, error) {
+	serviceCfg, ok := cfg.Services[instanceKey]
+	if !ok || !serviceCfg.Enabled {
+		return config.ServiceConfig{}, fmt.Errorf("%s not configured or not enabled", instanceKey)
+	}
+	serviceCfg.Cookies = serviceCookies(ctx, db, serviceName, serviceCfg, cookieDomain)
+	return serviceCfg, nil
+}
+
+// CheckAuth verifies a registered scraper's cookies without scraping
+func (m *Manager) CheckAuth(ctx context.Context, serviceName string) (*AuthStatus, error) {
+	scraper, ok := m.GetScraper(serviceName)
+	if !ok {
+		return nil, ErrScraperNotFound
+	}
+
+	checker, ok := scraper.(AuthChecker)
+	if !ok {
+		return nil, ErrAuthCheckUnsupported
+	}
+
+	return checker.CheckAuth(ctx)
+}
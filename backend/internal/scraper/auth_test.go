@@ -0,0 +1,52 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsLoginURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		markers []string
+		want    bool
+	}{
+		{"https://www.netflix.com/login?nextpage=%2Faccount", []string{"/login"}, true},
+		{"https://www.netflix.com/account", []string{"/login"}, false},
+		{"https://www.netflix.com/account?from=login", []string{"/login"}, false},
+		{"https://accounts.google.com/v3/signin/identifier", []string{"accounts.google.com"}, true},
+		{"https://www.amazon.com/ap/signin?openid.return_to=x", []string{"/ap/signin"}, true},
+		{"https://www.kanopy.com/en/Sign-In", defaultLoginMarkers, true},
+		{"https://www.kanopy.com/en/account/watch-history", defaultLoginMarkers, false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoginURL(tt.url, tt.markers); got != tt.want {
+			t.Errorf("isLoginURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestManagerCheckAuth(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	if _, err := manager.CheckAuth(context.Background(), "Netflix"); err != ErrScraperNotFound {
+		t.Errorf("Expected ErrScraperNotFound, got %v", err)
+	}
+
+	manager.Register(&MockScraper{name: "Mock"})
+	if _, err := manager.CheckAuth(context.Background(), "Mock"); err != ErrAuthCheckUnsupported {
+		t.Errorf("Expected ErrAuthCheckUnsupported, got %v", err)
+	}
+
+	// Without cookies the check fails fast, before launching a browser
+	manager.Register(NewNetflixScraper(manager.config, db))
+	status, err := manager.CheckAuth(context.Background(), "Netflix")
+	if err != nil {
+		t.Fatalf("CheckAuth failed: %v", err)
+	}
+	if status.LoggedIn || status.Reason == "" {
+		t.Errorf("Expected logged out with a reason, got %+v", status)
+	}
+}
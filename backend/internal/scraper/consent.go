@@ -0,0 +1,129 @@
+package scraper
+
+import (
+	"log"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// Where consent to automate a service was given
+const (
+	ConsentSourceConfig = "config"
+	ConsentSourceAPI    = "api"
+)
+
+// ConsentState is whether a service may be scraped under scraper.require_consent
+type ConsentState struct {
+	ServiceID   int64      `json:"service_id"`
+	ServiceName string     `json:"service_name"`
+	Required    bool       `json:"required"` // scraper.require_consent is on
+	Accepted    bool       `json:"accepted"`
+	Source      string     `json:"source,omitempty"`  // ConsentSourceConfig or ConsentSourceAPI
+	Updated     *time.Time `json:"updated,omitempty"` // When consent was last given or withdrawn
+	Allowed     bool       `json:"allowed"`           // Not required, or accepted
+}
+
+// Consent returns whether a service's automation risk has been acknowledged
+func (m *Manager) Consent(service *database.Service) (*ConsentState, error) {
+	state := &ConsentState{
+		ServiceID:   service.ID,
+		ServiceName: service.Name,
+		Required:    m.config.Scraper.RequireConsent,
+	}
+
+	recorded, err := m.db.GetServiceConsent(service.ID)
+	if err != nil {
+		return nil, err
+	}
+	if recorded != nil {
+		state.Accepted = recorded.Accepted
+		state.Source = recorded.Source
+		state.Updated = &recorded.Updated
+	}
+	if consentInConfig(m.config, service.Name) {
+		state.Accepted = true
+		state.Source = ConsentSourceConfig
+	}
+	state.Allowed = !state.Required || state.Accepted
+	return state, nil
+}
+
+// ConsentStates returns the consent state of every registered scraper's service
+func (m *Manager) ConsentStates() ([]ConsentState, error) {
+	states := []ConsentState{}
+	for _, name := range m.Names() {
+		service, err := m.db.GetServiceByName(name)
+		if err != nil {
+			return nil, err
+		}
+		if service == nil {
+			continue
+		}
+
+		state, err := m.Consent(service)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, *state)
+	}
+
+	return states, nil
+}
+
+// SetConsent records consent to automate a service given or withdrawn
+// through the API. Consent given in config can only be withdrawn there.
+func (m *Manager) SetConsent(service *database.Service, accepted bool) (*ConsentState, error) {
+	if !accepted && consentInConfig(m.config, service.Name) {
+		return nil, ErrConsentInConfig
+	}
+	if _, err := m.db.SetServiceConsent(service.ID, accepted, ConsentSourceAPI); err != nil {
+		return nil, err
+	}
+	if accepted {
+		log.Printf("Automation risk acknowledged for %s", service.Name)
+	} else {
+		log.Printf("Consent to scrape %s withdrawn", service.Name)
+	}
+	return m.Consent(service)
+}
+
+// RecordConfigConsent records when services were first acknowledged with
+// accept_automation_risk in config, keeping the time of earlier consent
+func (m *Manager) RecordConfigConsent() error {
+	for key, svc := range m.config.Services {
+		if !svc.AcceptAutomationRisk {
+			continue
+		}
+		service, err := m.db.GetServiceByName(ServiceNameFor(m.config, key))
+		if err != nil {
+			return err
+		}
+		if service == nil {
+			continue
+		}
+		recorded, err := m.db.GetServiceConsent(service.ID)
+		if err != nil {
+			return err
+		}
+		if recorded != nil && recorded.Accepted {
+			continue
+		}
+		if _, err := m.db.SetServiceConsent(service.ID, true, ConsentSourceConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consentInConfig reports whether a service's automation risk is
+// acknowledged in its config
+func consentInConfig(cfg *config.Config, serviceName string) bool {
+	for key, svc := range cfg.Services {
+		if svc.AcceptAutomationRisk && ServiceNameFor(cfg, key) == serviceName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package scraper
+
+import "time"
+
+// yearSlack is how far past the reference time a date shown without a year
+// may fall and still count as upcoming rather than a year old. Sites show
+// dates in the account's time zone, which can already be on the next day,
+// or the next year, while the server is still on the last.
+const yearSlack = 24 * time.Hour
+
+// inferYear returns the year of a date shown without one, such as "Dec 30"
+// on a history page: the latest year that doesn't put the date more than
+// yearSlack after ref, the time the page was scraped. Around New Year this
+// reads "Dec 30" scraped on Jan 2 as last year's and "Jan 1" scraped late on
+// Dec 31 as next year's. Feb 29 is placed in the last leap year.
+func inferYear(month time.Month, day int, ref time.Time) int {
+	limit := ref.Add(yearSlack)
+	for year := ref.Year() + 1; year >= ref.Year()-4; year-- {
+		t := time.Date(year, month, day, 0, 0, 0, 0, ref.Location())
+		if t.Month() == month && !t.After(limit) {
+			return year
+		}
+	}
+	return ref.Year()
+}
+
+// withInferredYear returns t, parsed from a date without a year, in the year
+// inferYear places it, at midnight in ref's location
+func withInferredYear(t, ref time.Time) time.Time {
+	return time.Date(inferYear(t.Month(), t.Day(), ref), t.Month(), t.Day(), 0, 0, 0, 0, ref.Location())
+}
@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInferYear(t *testing.T) {
+	tests := []struct {
+		name  string
+		month time.Month
+		day   int
+		ref   time.Time
+		want  int
+	}{
+		{"earlier this year", time.June, 10, time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC), 2025},
+		{"later in the year is last year's", time.August, 10, time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC), 2024},
+		{"late December scraped in early January", time.December, 30, time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), 2024},
+		{"New Year's Eve scraped on New Year's Day", time.December, 31, time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC), 2024},
+		{"early January scraped in early January", time.January, 1, time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), 2025},
+		{"early January scraped in late December", time.January, 3, time.Date(2024, 12, 28, 9, 0, 0, 0, time.UTC), 2024},
+		{"tomorrow in a time zone ahead", time.January, 1, time.Date(2024, 12, 31, 20, 0, 0, 0, time.UTC), 2025},
+		{"two days ahead is last year's", time.January, 2, time.Date(2024, 12, 31, 20, 0, 0, 0, time.UTC), 2024},
+		{"leap day", time.February, 29, time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC), 2024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inferYear(tt.month, tt.day, tt.ref); got != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDatesWithoutYearAroundNewYear(t *testing.T) {
+	newYearsDay := time.Date(2025, 1, 2, 9, 0, 0, 0, time.Local)
+	newYearsEve := time.Date(2024, 12, 31, 22, 0, 0, 0, time.Local)
+	scraper := NewYouTubeTVScraper(nil, nil)
+
+	tests := []struct {
+		name  string
+		parse func(now time.Time) (time.Time, error)
+		now   time.Time
+		want  string
+	}{
+		{"history page", func(now time.Time) (time.Time, error) { return parseRelativeHistoryDate("Mon, Dec 30", now) }, newYearsDay, "2024-12-30"},
+		{"history page next day", func(now time.Time) (time.Time, error) { return parseRelativeHistoryDate("Jan 1", now) }, newYearsEve, "2025-01-01"},
+		{"amazon", func(now time.Time) (time.Time, error) { return parseAmazonDate("December 30", now) }, newYearsDay, "2024-12-30"},
+		{"amazon this year", func(now time.Time) (time.Time, error) { return parseAmazonDate("Jan 1", now) }, newYearsDay, "2025-01-01"},
+		{"youtube tv header", func(now time.Time) (time.Time, error) { return scraper.parseDateAndTime("Dec 31", "11:30 PM", now) }, newYearsDay, "2024-12-31"},
+		{"youtube tv legacy", func(now time.Time) (time.Time, error) { return scraper.parseDate("Dec 30 at 8:00 PM", now) }, newYearsDay, "2024-12-30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got.Format("2006-01-02"))
+			}
+		})
+	}
+}
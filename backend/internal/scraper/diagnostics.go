@@ -0,0 +1,202 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/storage"
+)
+
+// Limits on the diagnostics kept for a failed run
+const (
+	maxRunLogBytes = 64 << 10
+	maxDOMBytes    = 2 << 20
+)
+
+// runLog keeps the tail of the log output written during a run
+type runLog struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write appends log output, dropping the oldest bytes past maxRunLogBytes
+func (l *runLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, p...)
+	if over := len(l.buf) - maxRunLogBytes; over > 0 {
+		l.buf = l.buf[over:]
+	}
+	return len(p), nil
+}
+
+// String returns the captured output
+func (l *runLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return string(l.buf)
+}
+
+// logTee copies the standard logger's output to every in-progress run. Runs
+// that overlap capture each other's lines, which is fine for a bug report.
+type logTee struct {
+	mu   sync.Mutex
+	out  io.Writer
+	runs map[*runLog]struct{}
+}
+
+// Write passes output through to the original writer and to each run
+func (t *logTee) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	for l := range t.runs {
+		l.Write(p)
+	}
+	t.mu.Unlock()
+	return t.out.Write(p)
+}
+
+var (
+	teeOnce sync.Once
+	tee     *logTee
+)
+
+// captureRunLog starts capturing log output for a run. The returned func stops it.
+func captureRunLog() (*runLog, func()) {
+	teeOnce.Do(func() {
+		tee = &logTee{out: log.Writer(), runs: make(map[*runLog]struct{})}
+		log.SetOutput(tee)
+	})
+
+	l := &runLog{}
+	tee.mu.Lock()
+	tee.runs[l] = struct{}{}
+	tee.mu.Unlock()
+
+	return l, func() {
+		tee.mu.Lock()
+		delete(tee.runs, l)
+		tee.mu.Unlock()
+	}
+}
+
+// domSnapshotKey is the context key for a run's latest DOM snapshot
+type domSnapshotKey struct{}
+
+// domSnapshot holds the most recent page HTML a scraper saw
+type domSnapshot struct {
+	mu   sync.Mutex
+	html string
+}
+
+// withDOMSnapshot returns a context that keeps the latest DOM snapshot
+func withDOMSnapshot(ctx context.Context) (context.Context, *domSnapshot) {
+	snap := &domSnapshot{}
+	return context.WithValue(ctx, domSnapshotKey{}, snap), snap
+}
+
+// RecordDOM stores the page HTML for the current run, replacing any earlier
+// snapshot. It does nothing outside a Manager run.
+func RecordDOM(ctx context.Context, html string) {
+	snap, ok := ctx.Value(domSnapshotKey{}).(*domSnapshot)
+	if !ok {
+		return
+	}
+	if len(html) > maxDOMBytes {
+		html = html[:maxDOMBytes]
+	}
+	snap.mu.Lock()
+	defer snap.mu.Unlock()
+	snap.html = html
+}
+
+// snapshotDOM records the current page's HTML from a chromedp context, so a
+// failed run can be reported with what the scraper was looking at
+func snapshotDOM(ctx context.Context) {
+	if _, ok := ctx.Value(domSnapshotKey{}).(*domSnapshot); !ok {
+		return
+	}
+	var html string
+	if err := chromedp.Run(ctx, chromedp.OuterHTML("html", &html, chromedp.ByQuery)); err != nil {
+		log.Printf("Failed to snapshot DOM: %v", err)
+		return
+	}
+	RecordDOM(ctx, html)
+}
+
+// String returns the latest snapshot
+func (s *domSnapshot) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.html
+}
+
+// DOMSnapshotKey is where a run's page snapshot is kept in the artifact store
+func DOMSnapshotKey(runID int64) string {
+	return fmt.Sprintf("scraper-runs/%d/dom.html", runID)
+}
+
+// SetArtifactStore keeps the page snapshots of failed runs in store instead
+// of the database, so they don't grow it on long-running instances
+func (m *Manager) SetArtifactStore(store storage.Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.artifacts = store
+}
+
+// artifactStore returns the configured artifact store, or nil
+func (m *Manager) artifactStore() storage.Store {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.artifacts
+}
+
+// recordFailedRun stores a failed run, moving its page snapshot to the
+// artifact store when there is one
+func (m *Manager) recordFailedRun(ctx context.Context, run *database.ScraperRun) {
+	store := m.artifactStore()
+	snapshot := run.DOMSnapshot
+	if store != nil {
+		run.DOMSnapshot = ""
+	}
+	if err := m.db.InsertScraperRun(run); err != nil {
+		log.Printf("Failed to record scraper run: %v", err)
+		return
+	}
+
+	if store != nil && snapshot != "" {
+		// The run's own context may be what failed, so don't tie the upload to it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		if err := store.Put(ctx, DOMSnapshotKey(run.ID), []byte(snapshot), "text/html; charset=utf-8"); err != nil {
+			log.Printf("Failed to store page snapshot for run %d: %v", run.ID, err)
+		}
+	}
+}
+
+// DOMSnapshot returns the page snapshot kept for a run, from the database or
+// the artifact store, or "" if there is none
+func (m *Manager) DOMSnapshot(ctx context.Context, run *database.ScraperRun) (string, error) {
+	if run.DOMSnapshot != "" {
+		return run.DOMSnapshot, nil
+	}
+	store := m.artifactStore()
+	if store == nil {
+		return "", nil
+	}
+
+	data, err := store.Get(ctx, DOMSnapshotKey(run.ID))
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
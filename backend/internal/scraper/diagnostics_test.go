@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/storage"
+)
+
+func TestCaptureRunLog(t *testing.T) {
+	runLog, stop := captureRunLog()
+	log.Printf("during run")
+	stop()
+	log.Printf("after run")
+
+	captured := runLog.String()
+	if !strings.Contains(captured, "during run") {
+		t.Errorf("Expected the run's log output, got %q", captured)
+	}
+	if strings.Contains(captured, "after run") {
+		t.Errorf("Expected capture to stop, got %q", captured)
+	}
+}
+
+func TestRunLogKeepsTail(t *testing.T) {
+	l := &runLog{}
+	l.Write([]byte(strings.Repeat("a", maxRunLogBytes)))
+	l.Write([]byte("tail"))
+
+	captured := l.String()
+	if len(captured) != maxRunLogBytes || !strings.HasSuffix(captured, "tail") {
+		t.Errorf("Expected the last %d bytes ending in the newest output, got %d bytes", maxRunLogBytes, len(captured))
+	}
+}
+
+func TestRecordDOM(t *testing.T) {
+	ctx, snap := withDOMSnapshot(context.Background())
+	RecordDOM(ctx, "<html>first</html>")
+	RecordDOM(ctx, "<html>second</html>")
+
+	if got := snap.String(); got != "<html>second</html>" {
+		t.Errorf("Expected the latest snapshot, got %q", got)
+	}
+}
+
+// snapshotScraper fails after recording a page snapshot
+type snapshotScraper struct{}
+
+func (s *snapshotScraper) Name() string {
+	return "Netflix"
+}
+
+func (s *snapshotScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	RecordDOM(ctx, "<html>login</html>")
+	return nil, ErrNavigationFailed
+}
+
+func TestFailedRunSnapshotInArtifactStore(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+	store := storage.NewLocal(t.TempDir())
+	manager.SetArtifactStore(store)
+	manager.Register(&snapshotScraper{})
+
+	if _, err := manager.Run(context.Background(), "Netflix"); err == nil {
+		t.Fatal("Expected the run to fail")
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	runs, _ := db.GetScraperRuns(service.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if len(runs) != 1 {
+		t.Fatalf("Expected the failed run recorded, got %d runs", len(runs))
+	}
+	run, _ := db.GetScraperRun(runs[0].ID)
+	if run.DOMSnapshot != "" {
+		t.Errorf("Expected the snapshot kept out of the database, got %q", run.DOMSnapshot)
+	}
+
+	snapshot, err := manager.DOMSnapshot(context.Background(), run)
+	if err != nil || snapshot != "<html>login</html>" {
+		t.Errorf("Expected the snapshot from the artifact store, got %q, %v", snapshot, err)
+	}
+}
@@ -0,0 +1,222 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// disneyHistoryURL is the viewing activity page in Disney+ account settings
+const disneyHistoryURL = "https://www.disneyplus.com/account/viewing-activity"
+
+// disneyMaxScrolls caps how many times the activity list is scrolled to load more rows
+const disneyMaxScrolls = 100
+
+// Selectors for the viewing activity page
+const (
+	disneyRowSel      = `[data-testid="viewing-activity-row"]`
+	disneyTitleSel    = `[data-testid="viewing-activity-title"]`
+	disneyEpisodeSel  = `[data-testid="viewing-activity-episode"]`
+	disneyDateSel     = `[data-testid="viewing-activity-date"]`
+	disneyDurationSel = `[data-testid="viewing-activity-duration"]`
+)
+
+// DisneyPlusScraper implements the Scraper interface for Disney+
+type DisneyPlusScraper struct {
+	config      *config.Config
+	db          *database.DB
+	serviceKey  string
+	instanceKey string // Key of this instance in the services config
+}
+
+// NewDisneyPlusScraper creates a new Disney+ scraper
+func NewDisneyPlusScraper(cfg *config.Config, db *database.DB) *DisneyPlusScraper {
+	return &DisneyPlusScraper{
+		config:      cfg,
+		db:          db,
+		serviceKey:  "Disney+",
+		instanceKey: "disney_plus",
+	}
+}
+
+// Name returns the service name
+func (s *DisneyPlusScraper) Name() string {
+	return s.serviceKey
+}
+
+// Scrape fetches viewing activity from the Disney+ account page
+func (s *DisneyPlusScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	// Get service config
+	serviceCfg, ok := s.config.Services[s.instanceKey]
+	if !ok || !serviceCfg.Enabled {
+		return nil, fmt.Errorf("%s not configured or not enabled", s.instanceKey)
+	}
+	serviceCfg.Cookies = serviceCookies(ctx, s.db, s.serviceKey, serviceCfg, "disneyplus.com")
+
+	chromeCtx, cancel := newChromeContext(ctx, s.config)
+	defer cancel()
+
+	// Load authentication cookies
+	if err := s.loadCookies(chromeCtx, serviceCfg.Cookies); err != nil {
+		return nil, fmt.Errorf("failed to load cookies: %w", err)
+	}
+
+	log.Printf("Navigating to Disney+ viewing activity: %s", disneyHistoryURL)
+	if err := chromedp.Run(chromeCtx,
+		navigateHistory(disneyHistoryURL),
+		chromedp.WaitVisible(disneyRowSel, chromedp.ByQuery),
+		chromedp.Sleep(2*time.Second), // Allow page to fully load
+	); err != nil {
+		snapshotDOM(chromeCtx)
+		return nil, ErrNavigationFailed
+	}
+
+	// Scroll through activity until we run out or reach data we already have
+	now := time.Now()
+	if err := scrollHistory(chromeCtx, s.config, s.db, s.serviceKey, disneyRowSel, disneyMaxScrolls, func() (database.WatchHistory, bool) {
+		rows, err := s.readRows(chromeCtx)
+		if err != nil || len(rows) == 0 {
+			return database.WatchHistory{}, false
+		}
+		last, err := s.rowToWatchHistory(rows[len(rows)-1], now)
+		return last, err == nil
+	}); err != nil {
+		return nil, fmt.Errorf("pagination failed: %w", err)
+	}
+
+	snapshotDOM(chromeCtx)
+	rows, err := s.readRows(chromeCtx)
+	if err != nil {
+		return nil, fmt.Errorf("extraction failed: %w", err)
+	}
+	recordHistoryRows(chromeCtx, rows, disneyRowSel, disneyTitleSel, disneyDateSel, disneyDurationSel)
+	if len(rows) == 0 {
+		return nil, ErrNoDataFound
+	}
+
+	var items []database.WatchHistory
+	for _, row := range rows {
+		item, err := s.rowToWatchHistory(row, now)
+		if err != nil {
+			log.Printf("Skipping Disney+ row '%s': %v", row.Title, err)
+			continue
+		}
+		items = append(items, item)
+
+		if limit := itemLimit(ctx, s.config); limit > 0 && len(items) >= limit {
+			log.Printf("Test mode: stopping at %d items", limit)
+			break
+		}
+	}
+
+	log.Printf("Disney+ scraper extracted %d items", len(items))
+	return items, nil
+}
+
+// CheckAuth loads the account page to see whether the cookies are still signed in
+func (s *DisneyPlusScraper) CheckAuth(ctx context.Context) (*AuthStatus, error) {
+	serviceCfg, err := serviceConfigFor(ctx, s.config, s.db, s.instanceKey, s.serviceKey, "disneyplus.com")
+	if err != nil {
+		return nil, err
+	}
+
+	return checkAuth(ctx, s.config, s.serviceKey, serviceCfg, s.loadCookies, authPage{
+		accountURL:   "https://www.disneyplus.com/account",
+		loginMarkers: []string{"/login", "/identity"},
+	})
+}
+
+// Reparse re-derives an entry from the raw payload stored when it was
+// scraped, resolving relative dates against the original scrape time
+func (s *DisneyPlusScraper) Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error) {
+	return s.rowToWatchHistory(rowFromPayload(raw), raw.ScrapedAt)
+}
+
+// loadCookies loads authentication cookies into the browser session
+func (s *DisneyPlusScraper) loadCookies(ctx context.Context, cookies []config.Cookie) error {
+	if len(cookies) == 0 {
+		return fmt.Errorf("no cookies provided - please configure Disney+ cookies in config.yaml")
+	}
+	return setCookies(ctx, "https://www.disneyplus.com", ".disneyplus.com", cookies)
+}
+
+// readRows reads every loaded activity row
+func (s *DisneyPlusScraper) readRows(ctx context.Context) ([]historyRow, error) {
+	var raw string
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(`
+			JSON.stringify(Array.from(document.querySelectorAll('`+disneyRowSel+`')).map(row => {
+				const text = sel => {
+					const el = row.querySelector(sel);
+					return el ? el.textContent.trim() : '';
+				};
+				return {
+					title: text('`+disneyTitleSel+`'),
+					episode: text('`+disneyEpisodeSel+`'),
+					date: text('`+disneyDateSel+`'),
+					runtime: text('`+disneyDurationSel+`'),
+					html: row.outerHTML.slice(0, `+strconv.Itoa(rawHTMLLimit)+`),
+				};
+			}))
+		`, &raw),
+	); err != nil {
+		return nil, err
+	}
+
+	var rows []historyRow
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode history rows: %w", err)
+	}
+	return rows, nil
+}
+
+// rowToWatchHistory converts an activity row to a watch history entry, with
+// relative dates resolved against now
+func (s *DisneyPlusScraper) rowToWatchHistory(row historyRow, now time.Time) (database.WatchHistory, error) {
+	title := strings.TrimSpace(row.Title)
+	episode := row.Episode
+
+	// Some rows put the episode in the title, e.g. "Andor: S1:E3 Reckoning"
+	if episode == "" {
+		if i := strings.Index(title, ":"); i > 0 && seasonEpisodePattern.MatchString(title[i+1:]) {
+			title, episode = strings.TrimSpace(title[:i]), title[i+1:]
+		}
+	}
+	if title == "" {
+		return database.WatchHistory{}, fmt.Errorf("missing title")
+	}
+
+	watchedAt, err := parseRelativeHistoryDate(row.Date, now)
+	if err != nil {
+		return database.WatchHistory{}, err
+	}
+
+	episodeInfo := normalizeEpisode(episode)
+	duration := parseRuntime(row.Runtime)
+	if duration == 0 {
+		duration = s.estimateDuration(title, episodeInfo)
+	}
+
+	return database.WatchHistory{
+		Title:           title,
+		EpisodeInfo:     episodeInfo,
+		DurationMinutes: duration,
+		WatchedAt:       watchedAt,
+		Raw:             row.payload(now),
+		Created:         time.Now(),
+	}, nil
+}
+
+// estimateDuration is used when a row has no duration
+func (s *DisneyPlusScraper) estimateDuration(title, episodeInfo string) int {
+	return durationEstimator(s.config, s.instanceKey, s.serviceKey, estimate.StrategyStandard, estimate.DisneyPlus).Estimate(title, episodeInfo)
+}
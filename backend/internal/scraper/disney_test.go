@@ -0,0 +1,59 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestDisneyPlusScraperName(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewDisneyPlusScraper(cfg, db)
+
+	if scraper.Name() != "Disney+" {
+		t.Errorf("Expected name 'Disney+', got '%s'", scraper.Name())
+	}
+}
+
+func TestDisneyPlusRowToWatchHistory(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewDisneyPlusScraper(cfg, db)
+	now := time.Date(2025, 3, 10, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		row      historyRow
+		title    string
+		episode  string
+		date     string
+		duration int
+	}{
+		{"episode column", historyRow{Title: "The Bear", Episode: "S3:E1 Tomorrow", Date: "Today", Runtime: "37m"}, "The Bear", "S03E01", "2025-03-10", 37},
+		{"episode in title", historyRow{Title: "Andor: S1:E3 Reckoning", Date: "Mar 2"}, "Andor", "S01E03", "2025-03-02", 40},
+		{"film with colon", historyRow{Title: "Star Wars: A New Hope", Date: "12/30/24"}, "Star Wars: A New Hope", "", "2024-12-30", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := scraper.rowToWatchHistory(tt.row, now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if item.Title != tt.title || item.EpisodeInfo != tt.episode {
+				t.Errorf("Expected %q %q, got %q %q", tt.title, tt.episode, item.Title, item.EpisodeInfo)
+			}
+			if item.WatchedAt.Format("2006-01-02") != tt.date {
+				t.Errorf("Expected date %s, got %s", tt.date, item.WatchedAt.Format("2006-01-02"))
+			}
+			if item.DurationMinutes != tt.duration {
+				t.Errorf("Expected %d minutes, got %d", tt.duration, item.DurationMinutes)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+// enrichQueueSize is how many runs' items can wait for enrichment before
+// later ones are skipped
+const enrichQueueSize = 32
+
+// EnrichResult counts what one enrichment pass did
+type EnrichResult struct {
+	Titles   int // Distinct titles missing details
+	LookedUp int // Titles looked up on TMDB rather than found in the cache
+	Filled   int // Watch history entries given a duration, genre or thumbnail
+}
+
+// StartEnrichment fills in the durations, genres and thumbnails that scrapers
+// couldn't read, looking newly stored titles up on TMDB in the background
+// until ctx is done. Lookups are cached in the database, so each title is
+// only looked up once.
+func (m *Manager) StartEnrichment(ctx context.Context, client *tmdb.Client) {
+	queue := make(chan []database.WatchHistory, enrichQueueSize)
+	m.mu.Lock()
+	m.enrichQueue = queue
+	m.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case items := <-queue:
+				result := Enrich(ctx, m.db, client, items)
+				if result.Filled > 0 {
+					log.Printf("Enriched %d history entries from TMDB (%d titles, %d looked up)",
+						result.Filled, result.Titles, result.LookedUp)
+				}
+			}
+		}
+	}()
+}
+
+// queueEnrichment hands stored items missing details to the enrichment
+// worker, if it's running. Items are dropped rather than holding up the run
+// when the queue is full.
+func (m *Manager) queueEnrichment(items []database.WatchHistory) {
+	m.mu.RLock()
+	queue := m.enrichQueue
+	m.mu.RUnlock()
+	if queue == nil {
+		return
+	}
+
+	var missing []database.WatchHistory
+	for _, item := range items {
+		if needsEnrichment(item) {
+			missing = append(missing, item)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	select {
+	case queue <- missing:
+	default:
+		log.Printf("Enrichment queue full, skipping %d items", len(missing))
+	}
+}
+
+// needsEnrichment reports whether a stored video is missing a duration,
+// genre or thumbnail
+func needsEnrichment(item database.WatchHistory) bool {
+	if item.ID == 0 || item.MediaKind == database.MediaKindAudio {
+		return false
+	}
+	return item.DurationMinutes == 0 || item.Genre == "" || item.ThumbnailURL == ""
+}
+
+// Enrich fills in missing durations, genres and thumbnails of watch history
+// entries from each title's TMDB details, looking titles up only when they
+// aren't cached. Failed lookups are logged and left uncached so a later run
+// tries again; entries keep any details they already have.
+func Enrich(ctx context.Context, db *database.DB, client *tmdb.Client, items []database.WatchHistory) EnrichResult {
+	var titles []string
+	ids := make(map[string][]int64)
+	for _, item := range items {
+		if !needsEnrichment(item) {
+			continue
+		}
+		key := strings.ToLower(item.Title)
+		if _, ok := ids[key]; !ok {
+			titles = append(titles, item.Title)
+		}
+		ids[key] = append(ids[key], item.ID)
+	}
+
+	result := EnrichResult{Titles: len(titles)}
+	for _, title := range titles {
+		if ctx.Err() != nil {
+			break
+		}
+
+		meta, err := db.GetTitleMetadata(title)
+		if err != nil {
+			log.Printf("Failed to read cached details of '%s': %v", title, err)
+			continue
+		}
+		if meta == nil {
+			if meta, err = lookupMetadata(ctx, client, title); err != nil {
+				log.Printf("Failed to look up '%s' on TMDB: %v", title, err)
+				continue
+			}
+			result.LookedUp++
+			if err := db.SetTitleMetadata(meta); err != nil {
+				log.Printf("Failed to cache details of '%s': %v", title, err)
+			}
+		}
+		if meta.TMDBID == 0 {
+			continue
+		}
+
+		filled, err := db.FillWatchHistoryMetadata(ids[strings.ToLower(title)], meta)
+		if err != nil {
+			log.Printf("Failed to fill in details of '%s': %v", title, err)
+			continue
+		}
+		result.Filled += filled
+	}
+
+	return result
+}
+
+// lookupMetadata finds a title on TMDB and reads its details, returning
+// metadata with a TMDBID of 0 when nothing matches
+func lookupMetadata(ctx context.Context, client *tmdb.Client, title string) (*database.TitleMetadata, error) {
+	meta := &database.TitleMetadata{Title: title}
+	match, err := client.SearchMulti(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return meta, nil
+	}
+
+	details, err := client.Details(ctx, match.MediaType, match.ID)
+	if err != nil {
+		return nil, err
+	}
+	meta.TMDBID = match.ID
+	meta.MediaType = match.MediaType
+	meta.Runtime = details.Runtime
+	meta.ThumbnailURL = details.ThumbnailURL
+	if len(details.Genres) > 0 {
+		meta.Genre = details.Genres[0]
+	}
+	return meta, nil
+}
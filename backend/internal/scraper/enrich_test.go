@@ -0,0 +1,101 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+func TestEnrich(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/multi":
+			lookups++
+			if r.URL.Query().Get("query") == "Home Video" {
+				w.Write([]byte(`{"results": []}`))
+				return
+			}
+			w.Write([]byte(`{"results": [{"id": 66732, "media_type": "tv", "name": "Stranger Things"}]}`))
+		case "/tv/66732":
+			w.Write([]byte(`{"id": 66732, "episode_run_time": [50], "genres": [{"name": "Drama"}], "poster_path": "/st.jpg"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := tmdb.NewClientWithBaseURL("test-key", server.URL)
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now().Add(-time.Hour)
+	items := []database.WatchHistory{
+		{ServiceID: service.ID, Title: "Stranger Things", EpisodeInfo: "S01E01", WatchedAt: now},
+		{ServiceID: service.ID, Title: "stranger things", EpisodeInfo: "S01E02", WatchedAt: now.Add(time.Minute), DurationMinutes: 48, Genre: "Sci-Fi"},
+		{ServiceID: service.ID, Title: "Home Video", WatchedAt: now.Add(2 * time.Minute)},
+	}
+	if err := db.InsertWatchHistoryBatch(items); err != nil {
+		t.Fatalf("Failed to insert history: %v", err)
+	}
+
+	result := Enrich(context.Background(), db, client, items)
+	if result.Titles != 2 || result.LookedUp != 2 || result.Filled != 2 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	history, err := db.GetWatchHistory(service.ID, now.Add(-time.Hour), now.Add(time.Hour), 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	byEpisode := make(map[string]database.WatchHistory)
+	for _, wh := range history {
+		byEpisode[wh.Title+wh.EpisodeInfo] = wh
+	}
+	if first := byEpisode["Stranger ThingsS01E01"]; first.DurationMinutes != 50 || first.Genre != "Drama" ||
+		first.ThumbnailURL != "https://image.tmdb.org/t/p/w342/st.jpg" {
+		t.Errorf("Expected missing details filled in, got %+v", first)
+	}
+	// Details the scraper read are kept
+	if second := byEpisode["stranger thingsS01E02"]; second.DurationMinutes != 48 || second.Genre != "Sci-Fi" ||
+		second.ThumbnailURL == "" {
+		t.Errorf("Expected only the thumbnail filled in, got %+v", second)
+	}
+	if home := byEpisode["Home Video"]; home.Genre != "" {
+		t.Errorf("Expected a title without a match left alone, got %+v", home)
+	}
+
+	// Both titles, including the miss, are cached
+	Enrich(context.Background(), db, client, items)
+	if lookups != 2 {
+		t.Errorf("Expected cached titles not to be looked up again, got %d lookups", lookups)
+	}
+}
+
+func TestQueueEnrichment(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	// Nothing is queued before enrichment starts
+	manager.queueEnrichment([]database.WatchHistory{{ID: 1, Title: "Show"}})
+
+	manager.enrichQueue = make(chan []database.WatchHistory, 1)
+	manager.queueEnrichment([]database.WatchHistory{
+		{ID: 1, Title: "Show"},
+		{ID: 2, Title: "Complete", DurationMinutes: 30, Genre: "Drama", ThumbnailURL: "https://example.com/a.jpg"},
+		{ID: 3, Title: "Audiobook", MediaKind: database.MediaKindAudio},
+	})
+	queued := <-manager.enrichQueue
+	if len(queued) != 1 || queued[0].ID != 1 {
+		t.Errorf("Expected only the item missing details queued, got %+v", queued)
+	}
+}
@@ -20,4 +20,28 @@ var (
 
 	// ErrTimeout is returned when a scraper operation times out
 	ErrTimeout = errors.New("scraper operation timed out")
+
+	// ErrAuthCheckUnsupported is returned when a scraper can't check its cookies on its own
+	ErrAuthCheckUnsupported = errors.New("scraper does not support auth checks")
+
+	// ErrCircuitOpen is returned when a service has failed repeatedly and automatic runs are backing off
+	ErrCircuitOpen = errors.New("scraper circuit open after repeated failures")
+
+	// ErrRunLimit is returned when a service has already been scraped as many times today as its throttle allows
+	ErrRunLimit = errors.New("scraper daily run limit reached")
+
+	// ErrNoConsent is returned when consent is required and a service's automation risk hasn't been acknowledged
+	ErrNoConsent = errors.New("automation risk not acknowledged for this service")
+
+	// ErrConsentInConfig is returned when withdrawing consent that was given in config
+	ErrConsentInConfig = errors.New("consent was given in config and can only be withdrawn there")
+
+	// ErrRunInProgress is returned when a service is already being scraped and the run doesn't wait for it
+	ErrRunInProgress = errors.New("scraper run already in progress for this service")
+
+	// ErrServiceInactive is returned when a service is disabled, or archived after being merged into another
+	ErrServiceInactive = errors.New("service is disabled or archived")
+
+	// ErrPageLimit is returned when a run has loaded as many pages as its service's throttle allows
+	ErrPageLimit = errors.New("scraper page limit reached")
 )
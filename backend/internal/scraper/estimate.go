@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// durationEstimator returns how a service instance estimates items without a
+// runtime: the strategy set in its config, the kids strategy for instances
+// named like a kids profile (e.g. netflix_kids), or the scraper's default.
+// Episode and film lengths set in config replace the strategy's.
+func durationEstimator(cfg *config.Config, instanceKey, serviceName, defaultStrategy string, defaults estimate.Fixed) estimate.Strategy {
+	svc := cfg.Services[instanceKey]
+
+	name := svc.Estimator
+	if name == "" {
+		name = defaultStrategy
+		if isKidsProfile(instanceKey) || isKidsProfile(serviceName) {
+			name = estimate.StrategyKids
+		}
+	}
+
+	s, err := estimate.New(name, defaults)
+	if err != nil {
+		// Unknown names are rejected when the scrapers are created
+		s, _ = estimate.New(defaultStrategy, defaults)
+	}
+	if svc.EpisodeMinutes > 0 {
+		s.Fallback.EpisodeMinutes = svc.EpisodeMinutes
+	}
+	if svc.FilmMinutes > 0 {
+		s.Fallback.FilmMinutes = svc.FilmMinutes
+	}
+	return s
+}
+
+// isKidsProfile reports whether an instance key or service name looks like a kids profile
+func isKidsProfile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "kids") || strings.Contains(name, "children")
+}
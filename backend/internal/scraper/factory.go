@@ -0,0 +1,235 @@
+package scraper
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// provider describes a scraper implementation that can be configured one or more times
+type provider struct {
+	serviceName  string // Default database service name (e.g., "Netflix")
+	cookieDomain string // Domain the session cookies belong to
+	historyURL   string // Page the scraper reads, which requires signing in
+	newScraper   func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper
+}
+
+// providers maps config provider keys to scraper implementations
+var providers = map[string]provider{
+	"netflix": {
+		serviceName:  "Netflix",
+		cookieDomain: "netflix.com",
+		historyURL:   "https://www.netflix.com/viewingactivity",
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewNetflixScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"youtube_tv": {
+		serviceName:  "YouTube TV",
+		cookieDomain: "google.com",
+		historyURL:   "https://myactivity.google.com/product/youtube",
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewYouTubeTVScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"amazon_video": {
+		serviceName:  "Amazon Video",
+		cookieDomain: "amazon.com",
+		historyURL:   "https://www.amazon.com/gp/video/settings/watch-history",
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewAmazonScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"vudu": {
+		serviceName:  "Vudu",
+		cookieDomain: "vudu.com",
+		historyURL:   vuduHistoryURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewVuduScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"hulu": {
+		serviceName:  "Hulu",
+		cookieDomain: "hulu.com",
+		historyURL:   huluHistoryURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewHuluScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"disney_plus": {
+		serviceName:  "Disney+",
+		cookieDomain: "disneyplus.com",
+		historyURL:   disneyHistoryURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewDisneyPlusScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"kanopy": {
+		serviceName:  "Kanopy",
+		cookieDomain: kanopySite.cookieDomain,
+		historyURL:   kanopySite.historyURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewKanopyScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"hoopla": {
+		serviceName:  "Hoopla",
+		cookieDomain: hooplaSite.cookieDomain,
+		historyURL:   hooplaSite.historyURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewHooplaScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"mubi": {
+		serviceName:  "MUBI",
+		cookieDomain: mubiSite.cookieDomain,
+		historyURL:   mubiSite.historyURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewMUBIScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"criterion": {
+		serviceName:  "Criterion Channel",
+		cookieDomain: criterionSite.cookieDomain,
+		historyURL:   criterionSite.historyURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewCriterionScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+	"espn_plus": {
+		serviceName:  "ESPN+",
+		cookieDomain: espnSite.cookieDomain,
+		historyURL:   espnSite.historyURL,
+		newScraper: func(cfg *config.Config, db *database.DB, instanceKey, serviceName string) Scraper {
+			s := NewESPNScraper(cfg, db)
+			s.instanceKey, s.serviceKey = instanceKey, serviceName
+			return s
+		},
+	},
+}
+
+// CookieSite is where a provider's users sign in and the domain its session
+// cookies belong to, for exporting cookies from a browser
+type CookieSite struct {
+	URL    string
+	Domain string // Without a leading dot, e.g. "netflix.com"
+}
+
+// CookieSiteFor returns the cookie site for a provider key
+func CookieSiteFor(providerKey string) (CookieSite, bool) {
+	p, ok := providers[providerKey]
+	if !ok {
+		return CookieSite{}, false
+	}
+	return CookieSite{URL: p.historyURL, Domain: strings.TrimPrefix(p.cookieDomain, ".")}, true
+}
+
+// ProviderKeys returns the config keys of every provider, sorted
+func ProviderKeys() []string {
+	var keys []string
+	for key := range providers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ServiceNameFor returns the database service name for a configured service
+// instance (e.g., "netflix_kids" -> "Netflix (kids)"), or "" if its provider is unknown
+func ServiceNameFor(cfg *config.Config, instanceKey string) string {
+	providerKey := cfg.ProviderFor(instanceKey)
+	p, ok := providers[providerKey]
+	if !ok {
+		return ""
+	}
+
+	if svc, ok := cfg.Services[instanceKey]; ok && svc.DisplayName != "" {
+		return database.CurrentServiceName(svc.DisplayName)
+	}
+	if instanceKey == providerKey {
+		return p.serviceName
+	}
+
+	suffix := strings.TrimPrefix(instanceKey, providerKey+"_")
+	return fmt.Sprintf("%s (%s)", p.serviceName, suffix)
+}
+
+// NewScrapersFromConfig creates one scraper per enabled service instance in the
+// config, adding a database service row for any instance that doesn't have one
+// yet, routing the instance's config key to its service and enabling it
+func NewScrapersFromConfig(cfg *config.Config, db *database.DB) ([]Scraper, error) {
+	// Sort for deterministic registration and logging
+	var keys []string
+	for key := range cfg.Services {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var scrapers []Scraper
+	for _, key := range keys {
+		if !cfg.Services[key].Enabled {
+			continue
+		}
+
+		providerKey := cfg.ProviderFor(key)
+		p, ok := providers[providerKey]
+		if !ok {
+			log.Printf("No scraper for service %s (provider %q), skipping", key, providerKey)
+			continue
+		}
+		if _, err := estimate.New(cfg.Services[key].Estimator, estimate.Fixed{}); err != nil {
+			return nil, fmt.Errorf("service %s: %w", key, err)
+		}
+
+		serviceName := ServiceNameFor(cfg, key)
+		if serviceName != p.serviceName {
+			// Extra instances share the provider's color and logo
+			base, err := db.GetServiceByName(p.serviceName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up %s: %w", p.serviceName, err)
+			}
+			color, logoURL := "#888888", ""
+			if base != nil {
+				color, logoURL = base.Color, base.LogoURL
+			}
+			if _, err := db.EnsureService(serviceName, color, logoURL); err != nil {
+				return nil, fmt.Errorf("failed to create service %s: %w", serviceName, err)
+			}
+		}
+		if err := db.SetServiceKey(serviceName, key); err != nil {
+			return nil, fmt.Errorf("failed to set key of service %s: %w", serviceName, err)
+		}
+		if err := db.EnableService(serviceName); err != nil {
+			return nil, fmt.Errorf("failed to enable service %s: %w", serviceName, err)
+		}
+
+		scrapers = append(scrapers, p.newScraper(cfg, db, key, serviceName))
+	}
+
+	return scrapers, nil
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestServiceNameFor(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"netflix":      {Enabled: true},
+			"netflix_kids": {Enabled: true, Provider: "netflix"},
+			"amazon_work":  {Enabled: true, Provider: "amazon_video", DisplayName: "Prime (Work)"},
+			"crunchyroll":  {Enabled: true},
+		},
+	}
+
+	tests := map[string]string{
+		"netflix":      "Netflix",
+		"netflix_kids": "Netflix (kids)",
+		"amazon_work":  "Prime (Work)",
+		"crunchyroll":  "",
+	}
+	for key, want := range tests {
+		if got := ServiceNameFor(cfg, key); got != want {
+			t.Errorf("ServiceNameFor(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestNewScrapersFromConfig(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"netflix":      {Enabled: true},
+			"netflix_kids": {Enabled: true, Provider: "netflix"},
+			"youtube_tv":   {Enabled: false},
+			"crunchyroll":  {Enabled: true},
+		},
+	}
+
+	scrapers, err := NewScrapersFromConfig(cfg, db)
+	if err != nil {
+		t.Fatalf("NewScrapersFromConfig failed: %v", err)
+	}
+
+	// Disabled services and unknown providers are skipped
+	if len(scrapers) != 2 {
+		t.Fatalf("Expected 2 scrapers, got %d", len(scrapers))
+	}
+	if scrapers[0].Name() != "Netflix" || scrapers[1].Name() != "Netflix (kids)" {
+		t.Errorf("Unexpected scraper names: %s, %s", scrapers[0].Name(), scrapers[1].Name())
+	}
+
+	// The second account gets its own service, styled like Netflix
+	netflix, _ := db.GetServiceByName("Netflix")
+	kids, err := db.GetServiceByName("Netflix (kids)")
+	if err != nil || kids == nil {
+		t.Fatalf("Expected Netflix (kids) service to be created, got %v", err)
+	}
+	if kids.ID == netflix.ID {
+		t.Error("Expected a separate service row for the second account")
+	}
+	if kids.Color != netflix.Color || !kids.Enabled {
+		t.Errorf("Expected enabled service with Netflix color, got %+v", kids)
+	}
+	if kids.Key != "netflix_kids" {
+		t.Errorf("Expected the instance's config key to route to its service, got %q", kids.Key)
+	}
+
+	// Seeded services are enabled once configured
+	if !netflix.Enabled {
+		t.Error("Expected the configured Netflix service enabled")
+	}
+	if youtubeTV, _ := db.GetServiceByName("YouTube TV"); youtubeTV.Enabled {
+		t.Error("Expected the unconfigured YouTube TV service left disabled")
+	}
+}
+
+func TestNewScrapersFromConfigRejectsUnknownEstimator(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"netflix": {Enabled: true, Estimator: "cartoons"},
+		},
+	}
+	if _, err := NewScrapersFromConfig(cfg, db); err == nil {
+		t.Error("Expected an error for an unknown estimator")
+	}
+}
+
+func TestCookieSiteFor(t *testing.T) {
+	for _, key := range ProviderKeys() {
+		site, ok := CookieSiteFor(key)
+		if !ok || site.URL == "" || site.Domain == "" || strings.HasPrefix(site.Domain, ".") {
+			t.Errorf("Expected a login URL and bare cookie domain for %s, got %+v", key, site)
+		}
+	}
+	if _, ok := CookieSiteFor("crunchyroll"); ok {
+		t.Error("Expected no cookie site for an unknown provider")
+	}
+}
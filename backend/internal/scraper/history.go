@@ -0,0 +1,417 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/browsercookies"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// historyRow is a single watch history row as read from a service's page
+type historyRow struct {
+	Title   string `json:"title"`
+	Episode string `json:"episode"`
+	Date    string `json:"date"`
+	Runtime string `json:"runtime"` // e.g. "PT1H52M", "1h 52m" or "112 min"
+	Year    string `json:"year"`    // Release year, when shown
+	Format  string `json:"format"`  // Media format, for services that also lend books or music
+	HTML    string `json:"html"`    // The row's markup, kept as part of its raw payload
+}
+
+// newChromeContext creates a browser context using the scraper config, with
+// the configured timeout applied. The returned cancel func releases everything.
+func newChromeContext(ctx context.Context, cfg *config.Config) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(cfg.Scraper.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", cfg.Scraper.Headless),
+		chromedp.UserAgent(cfg.Scraper.UserAgent),
+		chromedp.Flag("disable-blink-features", "AutomationControlled"),
+	)
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, opts...)
+	chromeCtx, chromeCancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(log.Printf))
+
+	return chromeCtx, func() {
+		chromeCancel()
+		allocCancel()
+		cancel()
+	}
+}
+
+// cookieDefaults are the attributes a scraper gives cookies that don't set their own
+type cookieDefaults struct {
+	domain   string
+	httpOnly bool
+	lifetime time.Duration // Expiry from now; 0 for a session cookie
+}
+
+// setCookieParams builds the command that loads a configured cookie, using
+// its own attributes where set and the scraper's defaults otherwise
+func setCookieParams(cookie config.Cookie, defaults cookieDefaults) *network.SetCookieParams {
+	domain := defaults.domain
+	if cookie.Domain != "" {
+		domain = cookie.Domain
+	}
+	path := "/"
+	if cookie.Path != "" {
+		path = cookie.Path
+	}
+	secure := true
+	if cookie.Secure != nil {
+		secure = *cookie.Secure
+	}
+	httpOnly := defaults.httpOnly
+	if cookie.HTTPOnly != nil {
+		httpOnly = *cookie.HTTPOnly
+	}
+
+	params := network.SetCookie(cookie.Name, cookie.Value).
+		WithDomain(domain).
+		WithPath(path).
+		WithSecure(secure).
+		WithHTTPOnly(httpOnly)
+
+	if cookie.Expires > 0 {
+		expires := cdp.TimeSinceEpoch(time.Unix(cookie.Expires, 0))
+		params = params.WithExpires(&expires)
+	} else if defaults.lifetime > 0 {
+		expires := cdp.TimeSinceEpoch(time.Now().Add(defaults.lifetime))
+		params = params.WithExpires(&expires)
+	}
+
+	switch strings.ToLower(cookie.SameSite) {
+	case "":
+	case "strict":
+		params = params.WithSameSite(network.CookieSameSiteStrict)
+	case "lax":
+		params = params.WithSameSite(network.CookieSameSiteLax)
+	case "none":
+		params = params.WithSameSite(network.CookieSameSiteNone)
+	default:
+		log.Printf("Ignoring unknown same_site %q on cookie %s", cookie.SameSite, cookie.Name)
+	}
+
+	return params
+}
+
+// setCookies visits siteURL and then loads authentication cookies for domain
+func setCookies(ctx context.Context, siteURL, domain string, cookies []config.Cookie) error {
+	if err := chromedp.Run(ctx, navigate(siteURL)); err != nil {
+		return fmt.Errorf("failed to navigate to %s: %w", siteURL, err)
+	}
+
+	// Wait a moment for the page to load
+	time.Sleep(2 * time.Second)
+
+	defaults := cookieDefaults{domain: domain, lifetime: 365 * 24 * time.Hour}
+	for _, cookie := range cookies {
+		if err := chromedp.Run(ctx, setCookieParams(cookie, defaults)); err != nil {
+			return fmt.Errorf("failed to set cookie %s: %w", cookie.Name, err)
+		}
+		log.Printf("Set cookie: %s", cookie.Name)
+	}
+
+	return nil
+}
+
+// serviceCookies returns the cookies a scrape should use: fresh ones from the
+// configured browser profile, else ones imported through the API, else the
+// cookies in config. Runs for a profile use the profile's cookies instead.
+func serviceCookies(ctx context.Context, db *database.DB, serviceName string, serviceCfg config.ServiceConfig, domain string) []config.Cookie {
+	if profile, ok := runProfile(ctx); ok {
+		if cookies, ok := browserProfileCookies(profile.BrowserProfile, serviceName+" profile "+profile.Name, domain); ok {
+			return cookies
+		}
+		return profile.Cookies
+	}
+
+	if cookies, ok := browserProfileCookies(serviceCfg.BrowserProfile, serviceName, domain); ok {
+		return cookies
+	}
+	return importedCookies(db, serviceName, serviceCfg.Cookies)
+}
+
+// browserProfileCookies reads a domain's cookies from a local browser profile,
+// if one is configured and readable
+func browserProfileCookies(profile config.BrowserProfileConfig, owner, domain string) ([]config.Cookie, bool) {
+	if profile.Path == "" {
+		return nil, false
+	}
+	cookies, err := browsercookies.Read(profile.Browser, profile.Path, domain)
+	if err != nil {
+		log.Printf("Failed to read browser profile cookies for %s, falling back: %v", owner, err)
+		return nil, false
+	}
+	log.Printf("Using %d cookies from browser profile %s for %s", len(cookies), profile.Path, owner)
+	return cookies, true
+}
+
+// BrowserProfileCookies reads the cookies a service instance and its profiles
+// take from their browser profiles, so diagnostics can scrub them. Profiles
+// that aren't configured or can't be read are skipped.
+func BrowserProfileCookies(cfg *config.Config, instanceKey string) []config.Cookie {
+	serviceCfg, ok := cfg.Services[instanceKey]
+	if !ok {
+		return nil
+	}
+	site, ok := CookieSiteFor(cfg.ProviderFor(instanceKey))
+	if !ok {
+		return nil
+	}
+	if serviceCfg.Marketplace != "" {
+		site.Domain = serviceCfg.Marketplace
+	}
+
+	cookies, _ := browserProfileCookies(serviceCfg.BrowserProfile, instanceKey, site.Domain)
+	for _, profile := range serviceCfg.Profiles {
+		profileCookies, _ := browserProfileCookies(profile.BrowserProfile, instanceKey+" profile "+profile.Name, site.Domain)
+		cookies = append(cookies, profileCookies...)
+	}
+	return cookies
+}
+
+// importedCookies returns the cookies imported through the API for a service,
+// which take the place of its config cookies, or the configured ones if none
+// were imported
+func importedCookies(db *database.DB, serviceName string, configured []config.Cookie) []config.Cookie {
+	if db == nil {
+		return configured
+	}
+	service, err := db.GetServiceByName(serviceName)
+	if err != nil || service == nil {
+		return configured
+	}
+
+	stored, err := db.GetServiceCookies(service.ID)
+	if err != nil || stored == "" {
+		return configured
+	}
+
+	var cookies []config.Cookie
+	if err := json.Unmarshal([]byte(stored), &cookies); err != nil {
+		log.Printf("Ignoring unreadable imported cookies for %s: %v", serviceName, err)
+		return configured
+	}
+	log.Printf("Using %d imported cookies for %s", len(cookies), serviceName)
+	return cookies
+}
+
+var (
+	isoDurationPattern = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?(?:\d+S)?$`)
+	hoursPattern       = regexp.MustCompile(`(\d+)\s*(?:h|hr|hrs|hour|hours)\b`)
+	minutesPattern     = regexp.MustCompile(`(\d+)\s*(?:m|min|mins|minute|minutes)\b`)
+)
+
+// parseRuntime converts a runtime such as "PT1H52M", "1h 52m" or "112 min"
+// to minutes, returning 0 if it can't be parsed
+func parseRuntime(runtime string) int {
+	runtime = strings.TrimSpace(runtime)
+	if runtime == "" {
+		return 0
+	}
+
+	if m := isoDurationPattern.FindStringSubmatch(strings.ToUpper(runtime)); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		return hours*60 + minutes
+	}
+
+	lower := strings.ToLower(runtime)
+	total := 0
+	if m := hoursPattern.FindStringSubmatch(lower); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+		total += hours * 60
+	}
+	if m := minutesPattern.FindStringSubmatch(lower); m != nil {
+		minutes, _ := strconv.Atoi(m[1])
+		total += minutes
+	}
+	return total
+}
+
+// historyDatePrefixes are labels some services put in front of the date
+var historyDatePrefixes = []string{"Watched on", "Watched", "Borrowed on", "Borrowed"}
+
+// parseHistoryDate parses dates shown on history pages, such as
+// "Watched Jan 5, 2025", "Borrowed 01/05/2025" or "2025-01-05"
+func parseHistoryDate(dateStr string) (time.Time, error) {
+	dateStr = strings.TrimSpace(dateStr)
+	for _, prefix := range historyDatePrefixes {
+		if strings.HasPrefix(dateStr, prefix) {
+			dateStr = strings.TrimSpace(strings.TrimPrefix(dateStr, prefix))
+			break
+		}
+	}
+
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02",
+		"01/02/2006",
+		"1/2/2006",
+		"Jan 2, 2006",
+		"January 2, 2006",
+	}
+
+	for _, format := range formats {
+		if t, err := time.Parse(format, dateStr); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
+
+var (
+	// seasonEpisodePattern matches episode labels like "S2 E5 • Pilot" or "S2:E5"
+	seasonEpisodePattern = regexp.MustCompile(`(?i)\bS(\d+)\s*:?\s*E(\d+)\b`)
+
+	// daysAgoPattern matches relative dates like "3 days ago"
+	daysAgoPattern = regexp.MustCompile(`(?i)^(\d+) days? ago$`)
+)
+
+// normalizeEpisode rewrites season and episode labels like "S2 E5 • Pilot"
+// as "S02E05", leaving other episode text as is
+func normalizeEpisode(episode string) string {
+	episode = strings.TrimSpace(episode)
+	if m := seasonEpisodePattern.FindStringSubmatch(episode); m != nil {
+		season, _ := strconv.Atoi(m[1])
+		number, _ := strconv.Atoi(m[2])
+		return fmt.Sprintf("S%02dE%02d", season, number)
+	}
+	return episode
+}
+
+// parseRelativeHistoryDate parses dates on history pages that show recent
+// rows relative to today: "Today", "Yesterday" and "3 days ago", "Mon, Jan 5"
+// without a year for this year's, and full dates for older ones
+func parseRelativeHistoryDate(dateStr string, now time.Time) (time.Time, error) {
+	dateStr = strings.TrimSpace(dateStr)
+	for _, prefix := range historyDatePrefixes {
+		if strings.HasPrefix(dateStr, prefix) {
+			dateStr = strings.TrimSpace(strings.TrimPrefix(dateStr, prefix))
+			break
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(dateStr) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if m := daysAgoPattern.FindStringSubmatch(dateStr); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		return today.AddDate(0, 0, -days), nil
+	}
+
+	// Dates without a year are the most recent ones, allowing for New Year
+	for _, layout := range []string{"Mon, Jan 2", "Jan 2", "January 2"} {
+		if t, err := time.ParseInLocation(layout, dateStr, now.Location()); err == nil {
+			return withInferredYear(t, now), nil
+		}
+	}
+
+	for _, layout := range []string{"Mon, Jan 2, 2006", "1/2/06"} {
+		if t, err := time.Parse(layout, dateStr); err == nil {
+			return t, nil
+		}
+	}
+	return parseHistoryDate(dateStr)
+}
+
+// scrollPause waits for the next batch of history to load after a scroll or
+// "show more" click: 2 seconds, or with scraper.randomize.scroll_pacing a
+// random 1.5 to 4 seconds, more like someone reading as they go
+func scrollPause(cfg *config.Config) chromedp.Action {
+	if !cfg.Scraper.Randomize.ScrollPacing {
+		return chromedp.Sleep(2 * time.Second)
+	}
+	return chromedp.Sleep(1500*time.Millisecond + rand.N(2500*time.Millisecond))
+}
+
+// scrollHistory scrolls an infinitely loading history list until no more rows
+// load, the oldest loaded row is past the run's lookback or already stored,
+// or maxScrolls is reached. oldest returns the last loaded entry, and false
+// when it can't be parsed.
+func scrollHistory(ctx context.Context, cfg *config.Config, db *database.DB, serviceName, rowSel string, maxScrolls int, oldest func() (database.WatchHistory, bool)) error {
+	// Look up this instance's service so existing entries can be detected
+	var serviceID int64
+	if service, err := db.GetServiceByName(serviceName); err == nil && service != nil {
+		serviceID = service.ID
+	}
+
+	previousCount := 0
+	stableScrolls := 0
+	for scroll := 1; scroll <= maxScrolls; scroll++ {
+		var currentCount int
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(fmt.Sprintf(`document.querySelectorAll(%q).length`, rowSel), &currentCount),
+		); err != nil {
+			return fmt.Errorf("failed to count history items: %w", err)
+		}
+
+		// Rows load in batches, so give a slow batch a couple of chances
+		if currentCount == previousCount {
+			stableScrolls++
+			if stableScrolls >= 3 {
+				log.Printf("No new history items after scroll %d. Total items: %d", scroll, currentCount)
+				return nil
+			}
+		} else {
+			stableScrolls = 0
+		}
+		previousCount = currentCount
+
+		if limit := itemLimit(ctx, cfg); limit > 0 && currentCount >= limit {
+			return nil
+		}
+
+		// Stop once the oldest loaded row is past the run's lookback or already stored
+		if last, ok := oldest(); ok {
+			if since := Lookback(ctx); !since.IsZero() && last.WatchedAt.Before(since) {
+				log.Printf("Reached lookback %s at scroll %d. Stopping pagination. Total items: %d",
+					formatSince(since), scroll, currentCount)
+				return nil
+			}
+			if serviceID != 0 && !IsRefresh(ctx) {
+				exists, err := historyDB(ctx, db).WatchHistoryExists(serviceID, last.Title, last.EpisodeInfo, last.WatchedAt)
+				if err == nil && exists {
+					log.Printf("Found existing entry '%s' at scroll %d. Stopping pagination. Total items: %d",
+						last.Title, scroll, currentCount)
+					return nil
+				}
+			}
+		}
+
+		if err := waitHistoryPage(ctx); err != nil {
+			if pageLimitReached(err, serviceName) {
+				return nil
+			}
+			return err
+		}
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(`window.scrollTo(0, document.body.scrollHeight)`, nil),
+			scrollPause(cfg), // Wait for the next batch to load
+		); err != nil {
+			return fmt.Errorf("failed to scroll history: %w", err)
+		}
+	}
+
+	log.Printf("Reached scroll limit of %d", maxScrolls)
+	return nil
+}
@@ -0,0 +1,165 @@
+package scraper
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestParseRuntime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"PT1H52M", 112},
+		{"PT45M", 45},
+		{"PT2H", 120},
+		{"1h 52m", 112},
+		{"1 hr 30 min", 90},
+		{"112 min", 112},
+		{"", 0},
+		{"unknown", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parseRuntime(tt.input); got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseHistoryDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"2025-01-15", "2025-01-15", false},
+		{"01/15/2025", "2025-01-15", false},
+		{"Watched Jan 15, 2025", "2025-01-15", false},
+		{"Watched on January 15, 2025", "2025-01-15", false},
+		{"Borrowed 1/5/2025", "2025-01-05", false},
+		{"2025-01-15T20:30:00Z", "2025-01-15", false},
+		{"last week", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := parseHistoryDate(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Format("2006-01-02") != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestSetCookieParams(t *testing.T) {
+	defaults := cookieDefaults{domain: ".example.com", httpOnly: true}
+
+	params := setCookieParams(config.Cookie{Name: "session", Value: "abc"}, defaults)
+	if params.Domain != ".example.com" || params.Path != "/" || !params.Secure || !params.HTTPOnly {
+		t.Errorf("Expected scraper defaults, got %+v", params)
+	}
+	if params.Expires != nil || params.SameSite != "" {
+		t.Errorf("Expected a session cookie without SameSite, got %+v", params)
+	}
+
+	insecure := false
+	params = setCookieParams(config.Cookie{
+		Name:     "SID",
+		Value:    "abc",
+		Domain:   ".accounts.example.com",
+		Path:     "/auth",
+		Expires:  1767225600,
+		SameSite: "lax",
+		Secure:   &insecure,
+		HTTPOnly: &insecure,
+	}, defaults)
+	if params.Domain != ".accounts.example.com" || params.Path != "/auth" || params.Secure || params.HTTPOnly {
+		t.Errorf("Expected the cookie's own attributes, got %+v", params)
+	}
+	if params.SameSite != network.CookieSameSiteLax {
+		t.Errorf("Expected SameSite Lax, got %q", params.SameSite)
+	}
+	if params.Expires == nil || !params.Expires.Time().Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("Expected the configured expiry, got %v", params.Expires)
+	}
+}
+
+func TestImportedCookies(t *testing.T) {
+	db, err := database.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	configured := []config.Cookie{{Name: "NetflixId", Value: "from-config"}}
+	if cookies := importedCookies(db, "Netflix", configured); cookies[0].Value != "from-config" {
+		t.Errorf("Expected config cookies without an import, got %+v", cookies)
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.SaveServiceCookies(service.ID, `[{"Name": "NetflixId", "Value": "imported", "Domain": ".netflix.com"}]`)
+	cookies := importedCookies(db, "Netflix", configured)
+	if len(cookies) != 1 || cookies[0].Value != "imported" || cookies[0].Domain != ".netflix.com" {
+		t.Errorf("Expected imported cookies, got %+v", cookies)
+	}
+}
+
+func TestBrowserProfileCookies(t *testing.T) {
+	// A Firefox cookie store per browser profile
+	store := func(value string) string {
+		dir := t.TempDir()
+		db, err := sql.Open("sqlite3", filepath.Join(dir, "cookies.sqlite"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		for _, stmt := range []string{
+			`CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT, path TEXT,
+				expiry INTEGER, isSecure INTEGER, isHttpOnly INTEGER, sameSite INTEGER)`,
+			`INSERT INTO moz_cookies (name, value, host, path, expiry, isSecure, isHttpOnly, sameSite)
+				VALUES ('NetflixId', '` + value + `', '.netflix.com', '/', 1767225600, 1, 1, 1)`,
+		} {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"netflix": {
+			Enabled:        true,
+			BrowserProfile: config.BrowserProfileConfig{Browser: "firefox", Path: store("service-value")},
+			Profiles: []config.ProfileConfig{
+				{Name: "alex", BrowserProfile: config.BrowserProfileConfig{Browser: "firefox", Path: store("alex-value")}},
+				{Name: "sam"},
+			},
+		},
+	}}
+
+	cookies := BrowserProfileCookies(cfg, "netflix")
+	if len(cookies) != 2 || cookies[0].Value != "service-value" || cookies[1].Value != "alex-value" {
+		t.Errorf("Expected the service's and alex's browser cookies, got %+v", cookies)
+	}
+	if cookies := BrowserProfileCookies(cfg, "hulu"); cookies != nil {
+		t.Errorf("Expected no cookies for an unconfigured service, got %+v", cookies)
+	}
+}
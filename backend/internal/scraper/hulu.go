@@ -0,0 +1,222 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// huluHistoryURL is the watch history collection under My Stuff
+const huluHistoryURL = "https://www.hulu.com/watch-history"
+
+// huluMaxScrolls caps how many times the history is scrolled to load more rows
+const huluMaxScrolls = 100
+
+// Selectors for the watch history page
+const (
+	huluRowSel      = `[data-automationid="watch-history-item"]`
+	huluTitleSel    = `[data-automationid="watch-history-item-title"]`
+	huluEpisodeSel  = `[data-automationid="watch-history-item-subtitle"]`
+	huluDateSel     = `[data-automationid="watch-history-item-date"]`
+	huluDurationSel = `[data-automationid="watch-history-item-duration"]`
+)
+
+// HuluScraper implements the Scraper interface for Hulu
+type HuluScraper struct {
+	config      *config.Config
+	db          *database.DB
+	serviceKey  string
+	instanceKey string // Key of this instance in the services config
+}
+
+// NewHuluScraper creates a new Hulu scraper
+func NewHuluScraper(cfg *config.Config, db *database.DB) *HuluScraper {
+	return &HuluScraper{
+		config:      cfg,
+		db:          db,
+		serviceKey:  "Hulu",
+		instanceKey: "hulu",
+	}
+}
+
+// Name returns the service name
+func (s *HuluScraper) Name() string {
+	return s.serviceKey
+}
+
+// Scrape fetches watch history from Hulu
+func (s *HuluScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	// Get service config
+	serviceCfg, ok := s.config.Services[s.instanceKey]
+	if !ok || !serviceCfg.Enabled {
+		return nil, fmt.Errorf("%s not configured or not enabled", s.instanceKey)
+	}
+	serviceCfg.Cookies = serviceCookies(ctx, s.db, s.serviceKey, serviceCfg, "hulu.com")
+
+	chromeCtx, cancel := newChromeContext(ctx, s.config)
+	defer cancel()
+
+	// Load authentication cookies
+	if err := s.loadCookies(chromeCtx, serviceCfg.Cookies); err != nil {
+		return nil, fmt.Errorf("failed to load cookies: %w", err)
+	}
+
+	log.Printf("Navigating to Hulu watch history: %s", huluHistoryURL)
+	if err := chromedp.Run(chromeCtx,
+		navigateHistory(huluHistoryURL),
+		chromedp.WaitVisible(huluRowSel, chromedp.ByQuery),
+		chromedp.Sleep(2*time.Second), // Allow page to fully load
+	); err != nil {
+		snapshotDOM(chromeCtx)
+		return nil, ErrNavigationFailed
+	}
+
+	// Scroll through history until we run out or reach data we already have
+	if err := s.loadMoreHistory(chromeCtx); err != nil {
+		return nil, fmt.Errorf("pagination failed: %w", err)
+	}
+
+	snapshotDOM(chromeCtx)
+	rows, err := s.readRows(chromeCtx)
+	if err != nil {
+		return nil, fmt.Errorf("extraction failed: %w", err)
+	}
+	recordHistoryRows(chromeCtx, rows, huluRowSel, huluTitleSel, huluDateSel, huluDurationSel)
+	if len(rows) == 0 {
+		return nil, ErrNoDataFound
+	}
+
+	now := time.Now()
+	var items []database.WatchHistory
+	for _, row := range rows {
+		item, err := s.rowToWatchHistory(row, now)
+		if err != nil {
+			log.Printf("Skipping Hulu row '%s': %v", row.Title, err)
+			continue
+		}
+		items = append(items, item)
+
+		if limit := itemLimit(ctx, s.config); limit > 0 && len(items) >= limit {
+			log.Printf("Test mode: stopping at %d items", limit)
+			break
+		}
+	}
+
+	log.Printf("Hulu scraper extracted %d items", len(items))
+	return items, nil
+}
+
+// CheckAuth loads the account page to see whether the cookies are still signed in
+func (s *HuluScraper) CheckAuth(ctx context.Context) (*AuthStatus, error) {
+	serviceCfg, err := serviceConfigFor(ctx, s.config, s.db, s.instanceKey, s.serviceKey, "hulu.com")
+	if err != nil {
+		return nil, err
+	}
+
+	return checkAuth(ctx, s.config, s.serviceKey, serviceCfg, s.loadCookies, authPage{
+		accountURL:   "https://secure.hulu.com/account",
+		loginMarkers: []string{"auth.hulu.com", "/login"},
+	})
+}
+
+// loadCookies loads authentication cookies into the browser session
+func (s *HuluScraper) loadCookies(ctx context.Context, cookies []config.Cookie) error {
+	if len(cookies) == 0 {
+		return fmt.Errorf("no cookies provided - please configure Hulu cookies in config.yaml")
+	}
+	return setCookies(ctx, "https://www.hulu.com", ".hulu.com", cookies)
+}
+
+// loadMoreHistory scrolls the history until it stops growing or reaches
+// data that doesn't need scraping
+func (s *HuluScraper) loadMoreHistory(ctx context.Context) error {
+	now := time.Now()
+	return scrollHistory(ctx, s.config, s.db, s.serviceKey, huluRowSel, huluMaxScrolls, func() (database.WatchHistory, bool) {
+		rows, err := s.readRows(ctx)
+		if err != nil || len(rows) == 0 {
+			return database.WatchHistory{}, false
+		}
+		last, err := s.rowToWatchHistory(rows[len(rows)-1], now)
+		return last, err == nil
+	})
+}
+
+// readRows reads every loaded history row
+func (s *HuluScraper) readRows(ctx context.Context) ([]historyRow, error) {
+	var raw string
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(`
+			JSON.stringify(Array.from(document.querySelectorAll('`+huluRowSel+`')).map(row => {
+				const text = sel => {
+					const el = row.querySelector(sel);
+					return el ? el.textContent.trim() : '';
+				};
+				return {
+					title: text('`+huluTitleSel+`'),
+					episode: text('`+huluEpisodeSel+`'),
+					date: text('`+huluDateSel+`'),
+					runtime: text('`+huluDurationSel+`'),
+					html: row.outerHTML.slice(0, `+strconv.Itoa(rawHTMLLimit)+`),
+				};
+			}))
+		`, &raw),
+	); err != nil {
+		return nil, err
+	}
+
+	var rows []historyRow
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode history rows: %w", err)
+	}
+	return rows, nil
+}
+
+// rowToWatchHistory converts a history row to a watch history entry, with
+// relative dates resolved against now
+func (s *HuluScraper) rowToWatchHistory(row historyRow, now time.Time) (database.WatchHistory, error) {
+	title := strings.TrimSpace(row.Title)
+	if title == "" {
+		return database.WatchHistory{}, fmt.Errorf("missing title")
+	}
+
+	watchedAt, err := parseRelativeHistoryDate(row.Date, now)
+	if err != nil {
+		return database.WatchHistory{}, err
+	}
+
+	episodeInfo := normalizeEpisode(row.Episode)
+
+	duration := parseRuntime(row.Runtime)
+	if duration == 0 {
+		duration = s.estimateDuration(title, episodeInfo)
+	}
+
+	return database.WatchHistory{
+		Title:           title,
+		EpisodeInfo:     episodeInfo,
+		DurationMinutes: duration,
+		WatchedAt:       watchedAt,
+		Raw:             row.payload(now),
+		Created:         time.Now(),
+	}, nil
+}
+
+// Reparse re-derives an entry from the raw payload stored when it was
+// scraped, resolving relative dates against the original scrape time
+func (s *HuluScraper) Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error) {
+	return s.rowToWatchHistory(rowFromPayload(raw), raw.ScrapedAt)
+}
+
+// estimateDuration is used when a row has no duration
+func (s *HuluScraper) estimateDuration(title, episodeInfo string) int {
+	return durationEstimator(s.config, s.instanceKey, s.serviceKey, estimate.StrategyStandard, estimate.Hulu).Estimate(title, episodeInfo)
+}
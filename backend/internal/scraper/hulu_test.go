@@ -0,0 +1,70 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestHuluScraperName(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewHuluScraper(cfg, db)
+
+	if scraper.Name() != "Hulu" {
+		t.Errorf("Expected name 'Hulu', got '%s'", scraper.Name())
+	}
+}
+
+func TestHuluRowToWatchHistory(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewHuluScraper(cfg, db)
+	now := time.Date(2025, 3, 10, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		row      historyRow
+		date     string
+		episode  string
+		duration int
+		wantErr  bool
+	}{
+		{"episode with runtime", historyRow{Title: "Shogun", Episode: "S1 E3 • Tomorrow Is Tomorrow", Date: "Yesterday", Runtime: "58 min"}, "2025-03-09", "S01E03", 58, false},
+		{"episode without runtime", historyRow{Title: "Abbott Elementary", Episode: "S4:E12", Date: "3 days ago"}, "2025-03-07", "S04E12", 35, false},
+		{"movie this year", historyRow{Title: "Prey", Date: "Mon, Feb 3"}, "2025-02-03", "", 105, false},
+		{"movie last year", historyRow{Title: "Palm Springs", Date: "Dec 24"}, "2024-12-24", "", 105, false},
+		{"full date", historyRow{Title: "Palm Springs", Date: "Jan 5, 2023"}, "2023-01-05", "", 105, false},
+		{"missing title", historyRow{Date: "Today"}, "", "", 0, true},
+		{"bad date", historyRow{Title: "Prey", Date: "a while ago"}, "", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := scraper.rowToWatchHistory(tt.row, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if item.WatchedAt.Format("2006-01-02") != tt.date {
+				t.Errorf("Expected date %s, got %s", tt.date, item.WatchedAt.Format("2006-01-02"))
+			}
+			if item.EpisodeInfo != tt.episode {
+				t.Errorf("Expected episode %q, got %q", tt.episode, item.EpisodeInfo)
+			}
+			if item.DurationMinutes != tt.duration {
+				t.Errorf("Expected %d minutes, got %d", tt.duration, item.DurationMinutes)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package scraper
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxJobs is how many finished jobs are kept for status polling
+const maxJobs = 100
+
+// Job states
+const (
+	JobQueued  = "queued" // Waiting for an earlier job for the same service
+	JobRunning = "running"
+	JobSuccess = "success"
+	JobFailed  = "failed"
+)
+
+// Job tracks a scraper run started in the background, so callers that
+// triggered it can poll for the outcome
+type Job struct {
+	ID            int64      `json:"id"`
+	ServiceName   string     `json:"service_name"`
+	State         string     `json:"state"`
+	ItemsScraped  int        `json:"items_scraped"`
+	ItemsRejected int        `json:"items_rejected"`
+	Error         string     `json:"error,omitempty"`
+	Created       time.Time  `json:"created"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// jobRegistry holds recent jobs and runs one job per service at a time
+type jobRegistry struct {
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[int64]*Job
+	active map[string]int // Queued or running jobs per service
+	locks  map[string]*sync.Mutex
+}
+
+// newJobRegistry creates an empty job registry
+func newJobRegistry() *jobRegistry {
+	return &jobRegistry{
+		jobs:   make(map[int64]*Job),
+		active: make(map[string]int),
+		locks:  make(map[string]*sync.Mutex),
+	}
+}
+
+// StartJob runs a scraper in the background with the given timeout and
+// returns its job. A job for a service that is already being scraped is
+// queued until the earlier one finishes, whether that was another job or a
+// scheduled run.
+func (m *Manager) StartJob(serviceName string, opts RunOptions, timeout time.Duration) Job {
+	r := m.jobs
+	r.mu.Lock()
+	r.nextID++
+	now := time.Now()
+	job := &Job{ID: r.nextID, ServiceName: serviceName, State: JobRunning, Created: now, StartedAt: &now}
+	if r.active[serviceName] > 0 {
+		job.State, job.StartedAt = JobQueued, nil
+	}
+	r.active[serviceName]++
+	lock, ok := r.locks[serviceName]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.locks[serviceName] = lock
+	}
+	r.jobs[job.ID] = job
+	r.prune()
+	started := *job
+	r.mu.Unlock()
+
+	go func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		r.update(job.ID, func(j *Job) {
+			if j.State == JobQueued {
+				startedAt := time.Now()
+				j.State, j.StartedAt = JobRunning, &startedAt
+			}
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		opts.Wait = true
+		result, err := m.RunWithOptions(ctx, serviceName, opts)
+
+		r.update(job.ID, func(j *Job) {
+			finishedAt := time.Now()
+			j.FinishedAt = &finishedAt
+			j.State = JobSuccess
+			if err != nil {
+				j.State, j.Error = JobFailed, err.Error()
+			}
+			if result != nil {
+				j.ItemsScraped = result.ItemsScraped
+				j.ItemsRejected = result.ItemsRejected
+			}
+		})
+
+		r.mu.Lock()
+		r.active[serviceName]--
+		r.mu.Unlock()
+	}()
+
+	return started
+}
+
+// GetJob returns a job by ID
+func (m *Manager) GetJob(id int64) (Job, bool) {
+	m.jobs.mu.Lock()
+	defer m.jobs.mu.Unlock()
+
+	job, ok := m.jobs.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Jobs returns recent jobs, newest first
+func (m *Manager) Jobs() []Job {
+	m.jobs.mu.Lock()
+	defer m.jobs.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.jobs.jobs))
+	for _, job := range m.jobs.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].ID > jobs[k].ID })
+	return jobs
+}
+
+// update changes a job under the registry lock
+func (r *jobRegistry) update(id int64, change func(j *Job)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[id]; ok {
+		change(job)
+	}
+}
+
+// prune drops the oldest finished jobs beyond maxJobs. Callers hold r.mu.
+func (r *jobRegistry) prune() {
+	if len(r.jobs) <= maxJobs {
+		return
+	}
+
+	var finished []int64
+	for id, job := range r.jobs {
+		if job.FinishedAt != nil {
+			finished = append(finished, id)
+		}
+	}
+	sort.Slice(finished, func(i, k int) bool { return finished[i] < finished[k] })
+	for _, id := range finished {
+		if len(r.jobs) <= maxJobs {
+			return
+		}
+		delete(r.jobs, id)
+	}
+}
@@ -0,0 +1,79 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// blockingScraper waits for release before returning its items
+type blockingScraper struct {
+	MockScraper
+	release chan struct{}
+}
+
+func (b *blockingScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	<-b.release
+	return b.MockScraper.Scrape(ctx)
+}
+
+// waitForJob polls until a job finishes
+func waitForJob(t *testing.T, manager *Manager, id int64) Job {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ := manager.GetJob(id); job.FinishedAt != nil {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %d didn't finish", id)
+	return Job{}
+}
+
+func TestStartJob(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	scraper := &blockingScraper{
+		MockScraper: MockScraper{name: "Netflix", items: []database.WatchHistory{
+			{Title: "Severance", DurationMinutes: 50, WatchedAt: time.Now()},
+		}},
+		release: make(chan struct{}),
+	}
+	manager.Register(scraper)
+
+	first := manager.StartJob("Netflix", RunOptions{Force: true}, time.Minute)
+	second := manager.StartJob("Netflix", RunOptions{Force: true}, time.Minute)
+	if first.State != JobRunning || second.State != JobQueued {
+		t.Errorf("Expected the second job queued behind the first, got %s and %s", first.State, second.State)
+	}
+
+	close(scraper.release)
+	done := waitForJob(t, manager, first.ID)
+	if done.State != JobSuccess || done.ItemsScraped != 1 {
+		t.Errorf("Expected a successful job with 1 item, got %+v", done)
+	}
+	waitForJob(t, manager, second.ID)
+
+	jobs := manager.Jobs()
+	if len(jobs) != 2 || jobs[0].ID != second.ID {
+		t.Errorf("Expected 2 jobs newest first, got %+v", jobs)
+	}
+}
+
+func TestStartJobFailure(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	job := manager.StartJob("Unregistered", RunOptions{}, time.Minute)
+	done := waitForJob(t, manager, job.ID)
+	if done.State != JobFailed || done.Error != ErrScraperNotFound.Error() {
+		t.Errorf("Expected a failed job, got %+v", done)
+	}
+
+	if _, ok := manager.GetJob(job.ID + 100); ok {
+		t.Error("Expected no job for an unknown ID")
+	}
+}
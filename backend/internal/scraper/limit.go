@@ -0,0 +1,29 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+// itemLimitKey is the context key for a run's item limit
+type itemLimitKey struct{}
+
+// WithItemLimit returns a context telling scrapers to stop after limit items,
+// for a quick verification run without turning on test mode in config
+func WithItemLimit(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, itemLimitKey{}, limit)
+}
+
+// itemLimit returns how many items a scraper should stop at, or 0 for no
+// limit: the run's own limit if it has one, else the config's test limit when
+// test mode is on
+func itemLimit(ctx context.Context, cfg *config.Config) int {
+	if limit, ok := ctx.Value(itemLimitKey{}).(int); ok && limit > 0 {
+		return limit
+	}
+	if cfg.Scraper.TestMode {
+		return cfg.Scraper.TestLimit
+	}
+	return 0
+}
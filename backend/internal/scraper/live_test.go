@@ -0,0 +1,133 @@
+//go:build live
+
+package scraper
+
+// Live smoke tests run each scraper against a real account, to check that its
+// selectors still match after a site redesign. They only build with -tags=live:
+//
+//	STREAMTIME_LIVE_NETFLIX_COOKIES=~/netflix-cookies.txt go test -tags=live -run TestLive -v ./internal/scraper
+//
+// STREAMTIME_LIVE_<PROVIDER>_COOKIES (e.g. STREAMTIME_LIVE_YOUTUBE_TV_COOKIES)
+// holds a cookies.txt or browser extension JSON export, inline or as a file
+// path. Providers without one are skipped. STREAMTIME_LIVE_LIMIT caps the
+// items scraped (default 10) and STREAMTIME_LIVE_HEADLESS=false shows the browser.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/cookiefile"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// liveDefaultLimit is the number of items scraped per service when STREAMTIME_LIVE_LIMIT isn't set
+const liveDefaultLimit = 10
+
+func TestLive(t *testing.T) {
+	var keys []string
+	for key := range providers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			cookies := liveCookies(t, key)
+			if cookies == nil {
+				t.Skipf("%s not set", liveEnvName(key))
+			}
+
+			db, err := database.New(":memory:")
+			if err != nil {
+				t.Fatalf("Failed to create test database: %v", err)
+			}
+			defer db.Close()
+
+			cfg := liveConfig(t, key, cookies)
+			scrapers, err := NewScrapersFromConfig(cfg, db)
+			if err != nil || len(scrapers) != 1 {
+				t.Fatalf("Failed to create %s scraper: %v", key, err)
+			}
+			manager := NewManager(db, cfg)
+			manager.Register(scrapers[0])
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			defer cancel()
+
+			result, err := manager.RunWithOptions(ctx, scrapers[0].Name(), RunOptions{Force: true, Limit: liveLimit()})
+			for selector, hits := range result.SelectorHits {
+				t.Logf("%-50s %d", selector, hits)
+			}
+			if err != nil {
+				t.Fatalf("%s scrape failed: %v", key, err)
+			}
+			if result.ItemsScraped == 0 {
+				t.Errorf("%s scraped no items; check the selector hits above", key)
+			}
+			t.Logf("%s scraped %d items in %s", key, result.ItemsScraped, result.EndTime.Sub(result.StartTime).Round(time.Second))
+		})
+	}
+}
+
+// liveEnvName returns the environment variable holding a provider's cookies
+func liveEnvName(providerKey string) string {
+	return "STREAMTIME_LIVE_" + strings.ToUpper(providerKey) + "_COOKIES"
+}
+
+// liveCookies reads a provider's cookies from the environment, or returns nil if unset
+func liveCookies(t *testing.T, providerKey string) []config.Cookie {
+	value := os.Getenv(liveEnvName(providerKey))
+	if value == "" {
+		return nil
+	}
+
+	data := []byte(value)
+	path := value
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, path[2:])
+	}
+	if contents, err := os.ReadFile(path); err == nil {
+		data = contents
+	}
+
+	cookies, _, err := cookiefile.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", liveEnvName(providerKey), err)
+	}
+	return cookies
+}
+
+// liveLimit returns the number of items to scrape per service
+func liveLimit() int {
+	if limit, err := strconv.Atoi(os.Getenv("STREAMTIME_LIVE_LIMIT")); err == nil && limit > 0 {
+		return limit
+	}
+	return liveDefaultLimit
+}
+
+// liveConfig loads a config with the usual defaults
+func liveConfig(t *testing.T, providerKey string, cookies []config.Cookie) *config.Config {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Scraper.Headless = os.Getenv("STREAMTIME_LIVE_HEADLESS") != "false"
+	cfg.Services = map[string]config.ServiceConfig{
+		providerKey: {Enabled: true, Cookies: cookies},
+	}
+
+	return cfg
+}
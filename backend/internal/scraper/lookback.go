@@ -0,0 +1,72 @@
+package scraper
+
+import (
+	"context"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+// Lookback modes, chosen by whether a service already has history
+const (
+	LookbackFirstRun    = "first_run"
+	LookbackIncremental = "incremental"
+	LookbackRefresh     = "refresh" // A manual re-scrape of a window
+)
+
+// lookbackKey is the context key for a run's history window
+type lookbackKey struct{}
+
+// lookback is the history window a run should fetch
+type lookback struct {
+	since   time.Time
+	refresh bool
+}
+
+// WithLookback returns a context telling scrapers not to fetch history watched
+// before since
+func WithLookback(ctx context.Context, since time.Time) context.Context {
+	return context.WithValue(ctx, lookbackKey{}, lookback{since: since})
+}
+
+// WithRefresh returns a context telling scrapers to re-fetch all history back
+// to since, even past entries that are already stored
+func WithRefresh(ctx context.Context, since time.Time) context.Context {
+	return context.WithValue(ctx, lookbackKey{}, lookback{since: since, refresh: true})
+}
+
+// Lookback returns the oldest watch time a scraper needs to fetch, or the zero
+// time when there is no limit
+func Lookback(ctx context.Context) time.Time {
+	lb, _ := ctx.Value(lookbackKey{}).(lookback)
+	return lb.since
+}
+
+// IsRefresh reports whether scrapers should keep going past stored entries
+// instead of stopping at the first one they find
+func IsRefresh(ctx context.Context) bool {
+	lb, _ := ctx.Value(lookbackKey{}).(lookback)
+	return lb.refresh
+}
+
+// lookbackDays returns how many days of history to fetch for a service in the
+// given mode, preferring the service's own setting. Zero or less means no limit.
+func lookbackDays(cfg *config.Config, serviceName, mode string) int {
+	days := cfg.Scraper.IncrementalLookbackDays
+	if mode == LookbackFirstRun {
+		days = cfg.Scraper.FirstRunLookbackDays
+	}
+
+	for key, svc := range cfg.Services {
+		if ServiceNameFor(cfg, key) != serviceName {
+			continue
+		}
+		if mode == LookbackFirstRun && svc.FirstRunLookbackDays != 0 {
+			days = svc.FirstRunLookbackDays
+		} else if mode == LookbackIncremental && svc.IncrementalLookbackDays != 0 {
+			days = svc.IncrementalLookbackDays
+		}
+	}
+
+	return days
+}
@@ -11,25 +11,27 @@ import (
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 	"github.com/jgoulah/streamtime/internal/config"
 	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
 )
 
 // NetflixScraper implements the Scraper interface for Netflix
 type NetflixScraper struct {
-	config     *config.Config
-	db         *database.DB
-	serviceKey string
+	config      *config.Config
+	db          *database.DB
+	serviceKey  string
+	instanceKey string // Key of this instance in the services config
 }
 
 // NewNetflixScraper creates a new Netflix scraper
 func NewNetflixScraper(cfg *config.Config, db *database.DB) *NetflixScraper {
 	return &NetflixScraper{
-		config:     cfg,
-		db:         db,
-		serviceKey: "Netflix",
+		config:      cfg,
+		db:          db,
+		serviceKey:  "Netflix",
+		instanceKey: "netflix",
 	}
 }
 
@@ -41,10 +43,11 @@ func (s *NetflixScraper) Name() string {
 // Scrape fetches viewing history from Netflix
 func (s *NetflixScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
 	// Get service config
-	serviceCfg, ok := s.config.Services["netflix"]
+	serviceCfg, ok := s.config.Services[s.instanceKey]
 	if !ok || !serviceCfg.Enabled {
-		return nil, fmt.Errorf("netflix not configured or not enabled")
+		return nil, fmt.Errorf("%s not configured or not enabled", s.instanceKey)
 	}
+	serviceCfg.Cookies = serviceCookies(ctx, s.db, s.serviceKey, serviceCfg, "netflix.com")
 
 	// Create chrome context with timeout
 	timeout := time.Duration(s.config.Scraper.Timeout) * time.Second
@@ -83,6 +86,19 @@ func (s *NetflixScraper) Scrape(ctx context.Context) ([]database.WatchHistory, e
 	return items, nil
 }
 
+// CheckAuth loads the account page to see whether the cookies are still signed in
+func (s *NetflixScraper) CheckAuth(ctx context.Context) (*AuthStatus, error) {
+	serviceCfg, err := serviceConfigFor(ctx, s.config, s.db, s.instanceKey, s.serviceKey, "netflix.com")
+	if err != nil {
+		return nil, err
+	}
+
+	return checkAuth(ctx, s.config, s.serviceKey, serviceCfg, s.loadCookies, authPage{
+		accountURL:   "https://www.netflix.com/account",
+		loginMarkers: []string{"/login"},
+	})
+}
+
 // loadCookies loads authentication cookies into the browser session
 func (s *NetflixScraper) loadCookies(ctx context.Context, cookies []config.Cookie) error {
 	log.Println("Loading Netflix authentication cookies...")
@@ -92,7 +108,7 @@ func (s *NetflixScraper) loadCookies(ctx context.Context, cookies []config.Cooki
 	}
 
 	// Navigate to Netflix first to set the domain
-	if err := chromedp.Run(ctx, chromedp.Navigate("https://www.netflix.com")); err != nil {
+	if err := chromedp.Run(ctx, navigate("https://www.netflix.com")); err != nil {
 		return fmt.Errorf("failed to navigate to Netflix: %w", err)
 	}
 
@@ -100,11 +116,7 @@ func (s *NetflixScraper) loadCookies(ctx context.Context, cookies []config.Cooki
 	for _, cookie := range cookies {
 		log.Printf("Setting cookie: %s", cookie.Name)
 
-		expr := network.SetCookie(cookie.Name, cookie.Value).
-			WithDomain(".netflix.com").
-			WithPath("/").
-			WithSecure(true).
-			WithHTTPOnly(true)
+		expr := setCookieParams(cookie, cookieDefaults{domain: ".netflix.com", httpOnly: true})
 
 		if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
 			return expr.Do(ctx)
@@ -124,12 +136,13 @@ func (s *NetflixScraper) navigateToViewingActivity(ctx context.Context) error {
 	viewingActivityURL := "https://www.netflix.com/viewingactivity"
 
 	err := chromedp.Run(ctx,
-		chromedp.Navigate(viewingActivityURL),
+		navigateHistory(viewingActivityURL),
 		chromedp.WaitVisible(`.retableRow`, chromedp.ByQuery),
 		chromedp.Sleep(2*time.Second), // Allow page to fully load
 	)
 
 	if err != nil {
+		snapshotDOM(ctx)
 		return ErrNavigationFailed
 	}
 
@@ -147,6 +160,8 @@ func (s *NetflixScraper) extractViewingHistory(ctx context.Context) ([]database.
 		return nil, err
 	}
 
+	snapshotDOM(ctx)
+
 	// Extract the viewing activity items
 	var htmlContent string
 	err = chromedp.Run(ctx,
@@ -163,6 +178,7 @@ func (s *NetflixScraper) extractViewingHistory(ctx context.Context) ([]database.
 		chromedp.Nodes(`.retableRow`, &nodes, chromedp.ByQueryAll),
 	)
 
+	RecordSelector(ctx, `.retableRow`, len(nodes))
 	if err != nil || len(nodes) == 0 {
 		return nil, ErrNoDataFound
 	}
@@ -185,16 +201,21 @@ func (s *NetflixScraper) extractViewingHistory(ctx context.Context) ([]database.
 	return items, nil
 }
 
-// scrollToLoadItems clicks "Show More" button to load more items until we reach existing data or 2024
+// scrollToLoadItems clicks "Show More" button to load more items until we reach existing data or the run's lookback
 func (s *NetflixScraper) scrollToLoadItems(ctx context.Context) error {
-	log.Println("Loading viewing history (will stop at existing data or year 2024)...")
+	since := Lookback(ctx)
+	log.Printf("Loading viewing history (will stop at existing data or %s)...", formatSince(since))
 
 	previousCount := 0
 	stableCountIterations := 0
-	targetYear := 2025
-	serviceID := int64(1) // Netflix service ID
 	clickCount := 0
 
+	// Look up this instance's service so existing entries can be detected
+	var serviceID int64
+	if service, err := s.db.GetServiceByName(s.serviceKey); err == nil && service != nil {
+		serviceID = service.ID
+	}
+
 	for {
 		clickCount++
 		// Try to click the "Show More" button
@@ -208,9 +229,15 @@ func (s *NetflixScraper) scrollToLoadItems(ctx context.Context) error {
 
 		// If Show More button exists, click it
 		if showMoreExists {
+			if err := waitHistoryPage(ctx); err != nil {
+				if pageLimitReached(err, s.serviceKey) {
+					return nil
+				}
+				return err
+			}
 			err = chromedp.Run(ctx,
 				chromedp.Click(`button.btn-blue.btn-small`, chromedp.ByQuery),
-				chromedp.Sleep(2*time.Second), // Wait for items to load
+				scrollPause(s.config), // Wait for items to load
 			)
 			if err != nil {
 				log.Printf("Error clicking Show More button: %v", err)
@@ -260,14 +287,14 @@ func (s *NetflixScraper) scrollToLoadItems(ctx context.Context) error {
 				// Parse the date
 				lastDate, dateErr := s.parseDate(strings.TrimSpace(lastItem.Date))
 
-				// Check if we've reached 2024 or earlier
-				if dateErr == nil && lastDate.Year() < targetYear {
-					log.Printf("Reached year %d at click %d. Stopping. Total items: %d", lastDate.Year(), clickCount, currentCount)
+				// Check if we've gone past the lookback
+				if dateErr == nil && !since.IsZero() && lastDate.Before(since) {
+					log.Printf("Reached %s at click %d. Stopping. Total items: %d", lastDate.Format("2006-01-02"), clickCount, currentCount)
 					break
 				}
 
-				// Check if this item already exists in the database
-				if dateErr == nil {
+				// Check if this item already exists in the database, unless re-scraping a window
+				if dateErr == nil && !IsRefresh(ctx) {
 					title := strings.TrimSpace(lastItem.Title)
 					episodeInfo := ""
 
@@ -281,7 +308,7 @@ func (s *NetflixScraper) scrollToLoadItems(ctx context.Context) error {
 					}
 
 					// Check if entry exists
-					exists, checkErr := s.db.WatchHistoryExists(serviceID, title, episodeInfo, lastDate)
+					exists, checkErr := historyDB(ctx, s.db).WatchHistoryExists(serviceID, title, episodeInfo, lastDate)
 					if checkErr == nil && exists {
 						log.Printf("Found existing entry '%s' from %s at click %d. Stopping pagination. Total items: %d",
 							title, lastDate.Format("2006-01-02"), clickCount, currentCount)
@@ -317,23 +344,43 @@ func (s *NetflixScraper) scrollToLoadItems(ctx context.Context) error {
 
 // parseViewingActivityRow parses a single viewing activity row
 func (s *NetflixScraper) parseViewingActivityRow(ctx context.Context, node *cdp.Node) (database.WatchHistory, error) {
-	var item database.WatchHistory
-
 	// Extract title
 	var title string
 	chromedp.Run(ctx,
 		chromedp.Text(`.title`, &title, chromedp.ByQuery, chromedp.FromNode(node)),
 	)
-	item.Title = strings.TrimSpace(title)
+	RecordSelector(ctx, `.title`, boolHit(strings.TrimSpace(title) != ""))
 
 	// Extract date
 	var dateStr string
 	chromedp.Run(ctx,
 		chromedp.Text(`.date`, &dateStr, chromedp.ByQuery, chromedp.FromNode(node)),
 	)
+	RecordSelector(ctx, `.date`, boolHit(strings.TrimSpace(dateStr) != ""))
+
+	return s.parseRaw(database.RawPayload{
+		Title:     title,
+		Date:      dateStr,
+		HTML:      nodeHTML(ctx, node),
+		ScrapedAt: time.Now(),
+	})
+}
+
+// Reparse re-derives an entry from the raw payload stored when it was scraped
+func (s *NetflixScraper) Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error) {
+	return s.parseRaw(raw)
+}
+
+// parseRaw parses the title and date text read from a viewing activity row
+func (s *NetflixScraper) parseRaw(raw database.RawPayload) (database.WatchHistory, error) {
+	title := raw.Title
+	item := database.WatchHistory{
+		Title: strings.TrimSpace(title),
+		Raw:   &raw,
+	}
 
 	// Parse date
-	watchedAt, err := s.parseDate(dateStr)
+	watchedAt, err := s.parseDate(raw.Date)
 	if err != nil {
 		return item, fmt.Errorf("failed to parse date: %w", err)
 	}
@@ -386,13 +433,7 @@ func (s *NetflixScraper) parseDate(dateStr string) (time.Time, error) {
 
 // estimateDuration estimates content duration based on title and episode info
 func (s *NetflixScraper) estimateDuration(title, episodeInfo string) int {
-	// If it has episode info, likely a TV show episode (average 30-45 min)
-	if episodeInfo != "" {
-		return 40
-	}
-
-	// Otherwise, assume it's a movie (average 90-120 min)
-	return 105
+	return durationEstimator(s.config, s.instanceKey, s.serviceKey, estimate.StrategyStandard, estimate.Netflix).Estimate(title, episodeInfo)
 }
 
 // Helper to convert season/episode string to structured format
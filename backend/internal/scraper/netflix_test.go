@@ -6,6 +6,7 @@ import (
 
 	"github.com/jgoulah/streamtime/internal/config"
 	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
 )
 
 func TestNetflixScraperName(t *testing.T) {
@@ -204,3 +205,23 @@ func TestDateParsing(t *testing.T) {
 		t.Errorf("Parsed date doesn't match expected date")
 	}
 }
+
+func TestDurationEstimatorFromConfig(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"netflix":      {Enabled: true, EpisodeMinutes: 50},
+			"netflix_kids": {Enabled: true, Provider: "netflix"},
+			"netflix_tv":   {Enabled: true, Provider: "netflix", Estimator: "live_tv"},
+		},
+	}
+
+	if got := durationEstimator(cfg, "netflix", "Netflix", "standard", estimate.Netflix).Estimate("Show", "S01E01"); got != 50 {
+		t.Errorf("Expected the configured episode length, got %d", got)
+	}
+	if got := durationEstimator(cfg, "netflix_kids", "Netflix (kids)", "standard", estimate.Netflix).Estimate("Show", "S01E01"); got != 20 {
+		t.Errorf("Expected the kids strategy for a kids profile, got %d", got)
+	}
+	if got := durationEstimator(cfg, "netflix_tv", "Netflix (tv)", "standard", estimate.Netflix).Estimate("World News Tonight", ""); got != 30 {
+		t.Errorf("Expected the configured live TV strategy, got %d", got)
+	}
+}
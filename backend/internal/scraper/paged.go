@@ -0,0 +1,393 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+	"github.com/jgoulah/streamtime/internal/tmdb"
+)
+
+// pagedMaxPages caps how many history pages are visited per run
+const pagedMaxPages = 50
+
+// pagedSite describes a service whose history is a simple paginated list
+type pagedSite struct {
+	homeURL      string // Visited first so cookies can be set
+	cookieDomain string // Domain the authentication cookies belong to
+	historyURL   string // History page; the page number is appended as ?page=N
+	rowSelector  string // Selector matching each history row
+	titleSel     string // Selectors for the fields within a row
+	episodeSel   string
+	dateSel      string
+	runtimeSel   string
+	yearSel      string   // Optional release year, used to narrow TMDB matches
+	formatSel    string   // Optional; rows whose format isn't in videoFormats are skipped
+	videoFormats []string // Lowercase formats that count as watch time
+	tmdbRuntimes bool     // Look up film runtimes on TMDB when the page has none
+
+	// Durations for rows without a runtime: the strategy's heuristics are
+	// tried first, then TMDB, then the fixed episode and film lengths
+	strategy  string
+	durations estimate.Fixed
+}
+
+// kanopySite reads Kanopy's watch history, which only contains video
+var kanopySite = pagedSite{
+	homeURL:      "https://www.kanopy.com",
+	cookieDomain: ".kanopy.com",
+	historyURL:   "https://www.kanopy.com/en/account/watch-history",
+	rowSelector:  ".watch-history-item",
+	titleSel:     ".watch-history-item__title",
+	episodeSel:   ".watch-history-item__episode",
+	dateSel:      ".watch-history-item__date",
+	runtimeSel:   ".watch-history-item__duration",
+	durations:    estimate.Fixed{EpisodeMinutes: 30, FilmMinutes: 95}, // Library TV is mostly documentary and kids series
+}
+
+// hooplaSite reads Hoopla's borrowing history, keeping only movies and TV
+// since Hoopla also lends audiobooks, ebooks, comics and music
+var hooplaSite = pagedSite{
+	homeURL:      "https://www.hoopladigital.com",
+	cookieDomain: ".hoopladigital.com",
+	historyURL:   "https://www.hoopladigital.com/my/history",
+	rowSelector:  "[data-testid='history-title']",
+	titleSel:     "[data-testid='title-name']",
+	episodeSel:   "[data-testid='episode-name']",
+	dateSel:      "[data-testid='borrowed-date']",
+	runtimeSel:   "[data-testid='runtime']",
+	formatSel:    "[data-testid='format']",
+	videoFormats: []string{"movie", "television"},
+	durations:    estimate.Fixed{EpisodeMinutes: 30, FilmMinutes: 95},
+}
+
+// mubiSite reads MUBI's watch history, which only lists films
+var mubiSite = pagedSite{
+	homeURL:      "https://mubi.com",
+	cookieDomain: ".mubi.com",
+	historyURL:   "https://mubi.com/en/account/watch-history",
+	rowSelector:  "[data-testid='film-tile']",
+	titleSel:     "[data-testid='film-title']",
+	dateSel:      "[data-testid='watched-at']",
+	yearSel:      "[data-testid='film-year']",
+	tmdbRuntimes: true,
+	durations:    estimate.Fixed{FilmMinutes: 110},
+}
+
+// criterionSite reads the Criterion Channel's watch history
+var criterionSite = pagedSite{
+	homeURL:      "https://www.criterionchannel.com",
+	cookieDomain: ".criterionchannel.com",
+	historyURL:   "https://www.criterionchannel.com/history",
+	rowSelector:  ".browse-item-card",
+	titleSel:     ".browse-item-title",
+	episodeSel:   ".browse-item-subtitle",
+	dateSel:      ".browse-item-watched-date",
+	runtimeSel:   ".duration-container",
+	yearSel:      ".browse-item-year",
+	tmdbRuntimes: true,
+	durations:    estimate.Fixed{EpisodeMinutes: 30, FilmMinutes: 110}, // Series are mostly shorts, interviews and supplements
+}
+
+// PagedScraper implements the Scraper interface for services whose history
+// pages are simple paginated lists, like Kanopy, Hoopla, MUBI and Criterion
+type PagedScraper struct {
+	config      *config.Config
+	db          *database.DB
+	tmdb        *tmdb.Client // nil when no TMDB API key is configured
+	site        pagedSite
+	serviceKey  string
+	instanceKey string // Key of this instance in the services config
+}
+
+// NewKanopyScraper creates a new Kanopy scraper
+func NewKanopyScraper(cfg *config.Config, db *database.DB) *PagedScraper {
+	return &PagedScraper{
+		config:      cfg,
+		db:          db,
+		site:        kanopySite,
+		serviceKey:  "Kanopy",
+		instanceKey: "kanopy",
+	}
+}
+
+// NewHooplaScraper creates a new Hoopla scraper
+func NewHooplaScraper(cfg *config.Config, db *database.DB) *PagedScraper {
+	return &PagedScraper{
+		config:      cfg,
+		db:          db,
+		site:        hooplaSite,
+		serviceKey:  "Hoopla",
+		instanceKey: "hoopla",
+	}
+}
+
+// NewMUBIScraper creates a new MUBI scraper
+func NewMUBIScraper(cfg *config.Config, db *database.DB) *PagedScraper {
+	return &PagedScraper{
+		config:      cfg,
+		db:          db,
+		tmdb:        newTMDBClient(cfg, db),
+		site:        mubiSite,
+		serviceKey:  "MUBI",
+		instanceKey: "mubi",
+	}
+}
+
+// NewCriterionScraper creates a new Criterion Channel scraper
+func NewCriterionScraper(cfg *config.Config, db *database.DB) *PagedScraper {
+	return &PagedScraper{
+		config:      cfg,
+		db:          db,
+		tmdb:        newTMDBClient(cfg, db),
+		site:        criterionSite,
+		serviceKey:  "Criterion Channel",
+		instanceKey: "criterion",
+	}
+}
+
+// newTMDBClient returns a TMDB client caching its lookups in the database,
+// if an API key is configured
+func newTMDBClient(cfg *config.Config, db *database.DB) *tmdb.Client {
+	if cfg.TMDB.APIKey == "" {
+		return nil
+	}
+	client := tmdb.NewClient(cfg.TMDB.APIKey)
+	client.SetCache(db)
+	return client
+}
+
+// Name returns the service name
+func (s *PagedScraper) Name() string {
+	return s.serviceKey
+}
+
+// Scrape fetches viewing history page by page until it reaches an empty page,
+// a page that was already stored, the run's lookback, the service's page
+// limit, or pagedMaxPages
+func (s *PagedScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	// Get service config
+	serviceCfg, ok := s.config.Services[s.instanceKey]
+	if !ok || !serviceCfg.Enabled {
+		return nil, fmt.Errorf("%s not configured or not enabled", s.instanceKey)
+	}
+	serviceCfg.Cookies = serviceCookies(ctx, s.db, s.serviceKey, serviceCfg, s.site.cookieDomain)
+
+	chromeCtx, cancel := newChromeContext(ctx, s.config)
+	defer cancel()
+
+	// Load authentication cookies
+	if err := setCookies(chromeCtx, s.site.homeURL, s.site.cookieDomain, serviceCfg.Cookies); err != nil {
+		return nil, fmt.Errorf("failed to load cookies: %w", err)
+	}
+
+	// Look up this instance's service so existing entries can be detected
+	var serviceID int64
+	if service, err := s.db.GetServiceByName(s.serviceKey); err == nil && service != nil {
+		serviceID = service.ID
+	}
+
+	var items []database.WatchHistory
+	for page := 1; page <= pagedMaxPages; page++ {
+		url := fmt.Sprintf("%s?page=%d", s.site.historyURL, page)
+		log.Printf("Loading %s history page %d: %s", s.serviceKey, page, url)
+
+		rows, err := s.readPage(chromeCtx, url)
+		if pageLimitReached(err, s.serviceKey) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+		if len(rows) == 0 {
+			log.Printf("No rows on page %d, done", page)
+			break
+		}
+
+		pageItems := s.rowsToWatchHistory(chromeCtx, rows)
+		items = append(items, pageItems...)
+
+		if limit := itemLimit(ctx, s.config); limit > 0 && len(items) >= limit {
+			log.Printf("Test mode: stopping at %d items", limit)
+			items = items[:limit]
+			break
+		}
+
+		// Stop once the oldest entry on this page is past the run's lookback
+		if since := Lookback(ctx); len(pageItems) > 0 && !since.IsZero() && pageItems[len(pageItems)-1].WatchedAt.Before(since) {
+			log.Printf("Reached lookback %s on page %d. Stopping pagination.", formatSince(since), page)
+			break
+		}
+
+		// Stop once the oldest entry on this page is already stored
+		if len(pageItems) > 0 && serviceID != 0 && !IsRefresh(ctx) {
+			last := pageItems[len(pageItems)-1]
+			exists, err := historyDB(ctx, s.db).WatchHistoryExists(serviceID, last.Title, last.EpisodeInfo, last.WatchedAt)
+			if err == nil && exists {
+				log.Printf("Found existing entry '%s' on page %d. Stopping pagination.", last.Title, page)
+				break
+			}
+		}
+	}
+
+	log.Printf("%s scraper extracted %d items", s.serviceKey, len(items))
+	return items, nil
+}
+
+// CheckAuth loads the first history page to see whether the cookies are still signed in
+func (s *PagedScraper) CheckAuth(ctx context.Context) (*AuthStatus, error) {
+	serviceCfg, err := serviceConfigFor(ctx, s.config, s.db, s.instanceKey, s.serviceKey, s.site.cookieDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	loadCookies := func(ctx context.Context, cookies []config.Cookie) error {
+		return setCookies(ctx, s.site.homeURL, s.site.cookieDomain, cookies)
+	}
+	return checkAuth(ctx, s.config, s.serviceKey, serviceCfg, loadCookies, authPage{accountURL: s.site.historyURL})
+}
+
+// readPage loads a history page and reads its rows
+func (s *PagedScraper) readPage(ctx context.Context, url string) ([]historyRow, error) {
+	script := fmt.Sprintf(`
+		JSON.stringify(Array.from(document.querySelectorAll(%q)).map(row => {
+			const text = sel => {
+				if (!sel) return '';
+				const el = row.querySelector(sel);
+				return el ? el.textContent.trim() : '';
+			};
+			return {
+				title: text(%q),
+				episode: text(%q),
+				date: text(%q),
+				runtime: text(%q),
+				year: text(%q),
+				format: text(%q),
+				html: row.outerHTML.slice(0, %d),
+			};
+		}))
+	`, s.site.rowSelector, s.site.titleSel, s.site.episodeSel, s.site.dateSel, s.site.runtimeSel, s.site.yearSel, s.site.formatSel, rawHTMLLimit)
+
+	var raw string
+	err := chromedp.Run(ctx,
+		navigateHistory(url),
+		chromedp.WaitReady("body"),
+		chromedp.Sleep(2*time.Second), // Wait for the list to render
+		chromedp.Evaluate(script, &raw),
+	)
+	snapshotDOM(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []historyRow
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode history rows: %w", err)
+	}
+	recordHistoryRows(ctx, rows, s.site.rowSelector, s.site.titleSel, s.site.dateSel, s.site.runtimeSel)
+	return rows, nil
+}
+
+// rowsToWatchHistory converts history rows to watch history entries, skipping
+// non-video formats and rows that can't be parsed
+func (s *PagedScraper) rowsToWatchHistory(ctx context.Context, rows []historyRow) []database.WatchHistory {
+	// Cache TMDB lookups for films watched more than once
+	runtimes := make(map[string]int)
+	estimator := s.estimator()
+
+	var items []database.WatchHistory
+	for _, row := range rows {
+		if !s.isVideo(row.Format) {
+			continue
+		}
+
+		title := strings.TrimSpace(row.Title)
+		if title == "" {
+			continue
+		}
+
+		watchedAt, err := parseHistoryDate(row.Date)
+		if err != nil {
+			log.Printf("Skipping %s row '%s': %v", s.serviceKey, title, err)
+			continue
+		}
+
+		episodeInfo := strings.TrimSpace(row.Episode)
+		duration := parseRuntime(row.Runtime)
+		if duration == 0 && estimator.Heuristic != nil {
+			duration = estimator.Heuristic.Estimate(title, episodeInfo)
+		}
+		if duration == 0 && episodeInfo == "" {
+			key := title + "|" + row.Year
+			if _, ok := runtimes[key]; !ok {
+				runtimes[key] = s.lookupFilmRuntime(ctx, title, row.Year)
+			}
+			duration = runtimes[key]
+		}
+		if duration == 0 {
+			duration = estimator.Fallback.Estimate(title, episodeInfo)
+		}
+
+		items = append(items, database.WatchHistory{
+			Title:           title,
+			EpisodeInfo:     episodeInfo,
+			DurationMinutes: duration,
+			WatchedAt:       watchedAt,
+			Raw:             row.payload(time.Now()),
+			Created:         time.Now(),
+		})
+	}
+	return items
+}
+
+// Reparse re-derives an entry from the raw payload stored when it was scraped
+func (s *PagedScraper) Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error) {
+	items := s.rowsToWatchHistory(ctx, []historyRow{rowFromPayload(raw)})
+	if len(items) == 0 {
+		return database.WatchHistory{}, fmt.Errorf("row no longer parses as a video entry")
+	}
+	return items[0], nil
+}
+
+// isVideo reports whether a row's format counts as watch time
+func (s *PagedScraper) isVideo(format string) bool {
+	if len(s.site.videoFormats) == 0 {
+		return true
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	for _, f := range s.site.videoFormats {
+		if format == f {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupFilmRuntime finds a film's runtime on TMDB, returning 0 if lookups
+// are disabled for this site or no match is found
+func (s *PagedScraper) lookupFilmRuntime(ctx context.Context, title, year string) int {
+	if !s.site.tmdbRuntimes || s.tmdb == nil {
+		return 0
+	}
+
+	releaseYear, _ := strconv.Atoi(strings.TrimSpace(year))
+	runtime, err := s.tmdb.MovieRuntime(ctx, title, releaseYear)
+	if err != nil {
+		log.Printf("TMDB lookup failed for '%s': %v", title, err)
+		return 0
+	}
+	return runtime
+}
+
+// estimator returns the duration estimator for rows without a runtime
+func (s *PagedScraper) estimator() estimate.Strategy {
+	return durationEstimator(s.config, s.instanceKey, s.serviceKey, s.site.strategy, s.site.durations)
+}
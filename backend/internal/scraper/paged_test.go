@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestPagedScraperNames(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+
+	if name := NewKanopyScraper(cfg, db).Name(); name != "Kanopy" {
+		t.Errorf("Expected name 'Kanopy', got '%s'", name)
+	}
+	if name := NewHooplaScraper(cfg, db).Name(); name != "Hoopla" {
+		t.Errorf("Expected name 'Hoopla', got '%s'", name)
+	}
+}
+
+func TestHooplaSkipsNonVideoFormats(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewHooplaScraper(cfg, db)
+
+	rows := []historyRow{
+		{Title: "Paddington", Date: "Borrowed 01/05/2025", Runtime: "1 hr 35 min", Format: "Movie"},
+		{Title: "Bluey", Episode: "Keepy Uppy", Date: "Borrowed 01/06/2025", Format: "Television"},
+		{Title: "Dune", Date: "Borrowed 01/07/2025", Runtime: "21 hr 2 min", Format: "Audiobook"},
+		{Title: "Saga", Date: "Borrowed 01/08/2025", Format: "Comic"},
+	}
+
+	items := scraper.rowsToWatchHistory(context.Background(), rows)
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 video items, got %d", len(items))
+	}
+	if items[0].Title != "Paddington" || items[0].DurationMinutes != 95 {
+		t.Errorf("Expected Paddington at 95 minutes, got %+v", items[0])
+	}
+	if items[1].EpisodeInfo != "Keepy Uppy" || items[1].DurationMinutes != 30 {
+		t.Errorf("Expected estimated episode duration, got %+v", items[1])
+	}
+}
+
+func TestKanopyRowsToWatchHistory(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewKanopyScraper(cfg, db)
+
+	rows := []historyRow{
+		{Title: "Seven Samurai", Date: "Watched on January 5, 2025", Runtime: "3h 27m"},
+		{Title: "", Date: "Watched on January 6, 2025"},
+		{Title: "Stalker", Date: "sometime"},
+	}
+
+	items := scraper.rowsToWatchHistory(context.Background(), rows)
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	if items[0].DurationMinutes != 207 {
+		t.Errorf("Expected 207 minutes, got %d", items[0].DurationMinutes)
+	}
+	if items[0].WatchedAt.Format("2006-01-02") != "2025-01-05" {
+		t.Errorf("Expected 2025-01-05, got %s", items[0].WatchedAt.Format("2006-01-02"))
+	}
+}
+
+func TestFilmScrapersWithoutTMDB(t *testing.T) {
+	// Without an API key, films fall back to the estimated runtime
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+
+	mubi := NewMUBIScraper(cfg, db)
+	if mubi.Name() != "MUBI" || mubi.tmdb != nil {
+		t.Fatalf("Expected MUBI scraper without TMDB client, got %+v", mubi)
+	}
+
+	items := mubi.rowsToWatchHistory(context.Background(), []historyRow{
+		{Title: "Close-Up", Year: "1990", Date: "2025-02-01"},
+	})
+	if len(items) != 1 || items[0].DurationMinutes != 110 {
+		t.Errorf("Expected a 110 minute estimate, got %+v", items)
+	}
+
+	criterion := NewCriterionScraper(&config.Config{TMDB: config.TMDBConfig{APIKey: "key"}}, db)
+	if criterion.Name() != "Criterion Channel" || criterion.tmdb == nil {
+		t.Errorf("Expected Criterion scraper with TMDB client, got %+v", criterion)
+	}
+}
@@ -0,0 +1,49 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// profileKey is the context key for the profile a run scrapes
+type profileKey struct{}
+
+// scrapeProfile is a configured profile and its database ID
+type scrapeProfile struct {
+	config.ProfileConfig
+	id int64
+}
+
+// withProfile returns a context telling scrapers to sign in with a profile's
+// cookies and check for stored history among the profile's entries only
+func withProfile(ctx context.Context, profile scrapeProfile) context.Context {
+	return context.WithValue(ctx, profileKey{}, profile)
+}
+
+// runProfile returns the profile a run scrapes, if the service has profiles
+func runProfile(ctx context.Context) (scrapeProfile, bool) {
+	profile, ok := ctx.Value(profileKey{}).(scrapeProfile)
+	return profile, ok
+}
+
+// historyDB scopes history lookups to the run's profile, so one person's
+// entries don't stop a scrape of another's before it reaches new history
+func historyDB(ctx context.Context, db *database.DB) *database.DB {
+	if profile, ok := runProfile(ctx); ok {
+		return db.ForProfile(profile.id)
+	}
+	return db
+}
+
+// profilesFor returns the profiles configured for a service, or nil when it
+// is scraped with its own cookies
+func profilesFor(cfg *config.Config, serviceName string) []config.ProfileConfig {
+	for key, svc := range cfg.Services {
+		if len(svc.Profiles) > 0 && ServiceNameFor(cfg, key) == serviceName {
+			return svc.Profiles
+		}
+	}
+	return nil
+}
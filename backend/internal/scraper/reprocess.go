@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// rawHTMLLimit caps how much of a row's markup is kept in its raw payload
+const rawHTMLLimit = 2000
+
+// ErrReprocessUnsupported is returned when a scraper can't re-parse stored payloads
+var ErrReprocessUnsupported = errors.New("scraper does not support reprocessing")
+
+// Reprocessor is implemented by scrapers that can re-derive entries from the
+// raw payloads stored with them, so a parser fix can be applied to existing
+// history without scraping the site again
+type Reprocessor interface {
+	Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error)
+}
+
+// ReprocessResult counts what re-parsing a service's stored payloads changed
+type ReprocessResult struct {
+	ServiceName string `json:"service_name"`
+	Checked     int    `json:"checked"`
+	Updated     int    `json:"updated"`
+	Failed      int    `json:"failed"`
+	DryRun      bool   `json:"dry_run"`
+}
+
+// Reprocess re-parses every stored raw payload for a service and updates the
+// title, episode, watch time and duration of entries whose parse changed.
+// A dry run only counts the entries that would change.
+func (m *Manager) Reprocess(ctx context.Context, serviceName string, dryRun bool) (*ReprocessResult, error) {
+	scraper, ok := m.GetScraper(serviceName)
+	if !ok {
+		return nil, ErrScraperNotFound
+	}
+	reprocessor, ok := scraper.(Reprocessor)
+	if !ok {
+		return nil, ErrReprocessUnsupported
+	}
+
+	service, err := m.db.GetServiceByName(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, ErrServiceNotFound
+	}
+
+	entries, err := m.db.GetRawWatchHistory(service.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReprocessResult{ServiceName: serviceName, DryRun: dryRun}
+	for _, entry := range entries {
+		result.Checked++
+
+		parsed, err := reprocessor.Reparse(ctx, *entry.Raw)
+		if err != nil {
+			log.Printf("Failed to reparse %s entry %d '%s': %v", serviceName, entry.ID, entry.Title, err)
+			result.Failed++
+			continue
+		}
+		title, err := m.db.CanonicalTitle(parsed.Title)
+		if err != nil {
+			return result, err
+		}
+
+		if title == entry.Title && parsed.EpisodeInfo == entry.EpisodeInfo &&
+			parsed.WatchedAt.Equal(entry.WatchedAt) && parsed.DurationMinutes == entry.DurationMinutes {
+			continue
+		}
+		if !dryRun {
+			if err := m.db.UpdateParsedFields(entry.ID, title, parsed.EpisodeInfo, parsed.WatchedAt, parsed.DurationMinutes); err != nil {
+				// Most likely the corrected entry collides with one already stored
+				log.Printf("Failed to update %s entry %d '%s': %v", serviceName, entry.ID, entry.Title, err)
+				result.Failed++
+				continue
+			}
+		}
+		result.Updated++
+	}
+
+	log.Printf("Reprocessed %s: %d checked, %d updated, %d failed", serviceName, result.Checked, result.Updated, result.Failed)
+	return result, nil
+}
+
+// payload returns the raw payload stored for a history row
+func (r historyRow) payload(scrapedAt time.Time) *database.RawPayload {
+	return &database.RawPayload{
+		Title:     r.Title,
+		Episode:   r.Episode,
+		Date:      r.Date,
+		Runtime:   r.Runtime,
+		Year:      r.Year,
+		Format:    r.Format,
+		HTML:      r.HTML,
+		ScrapedAt: scrapedAt,
+	}
+}
+
+// rowFromPayload rebuilds a history row from its stored raw payload
+func rowFromPayload(raw database.RawPayload) historyRow {
+	return historyRow{
+		Title:   raw.Title,
+		Episode: raw.Episode,
+		Date:    raw.Date,
+		Runtime: raw.Runtime,
+		Year:    raw.Year,
+		Format:  raw.Format,
+		HTML:    raw.HTML,
+	}
+}
+
+// nodeHTML returns a node's markup for its raw payload, truncated to
+// rawHTMLLimit, or "" if it can't be read
+func nodeHTML(ctx context.Context, node *cdp.Node) string {
+	var html string
+	chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		html, err = dom.GetOuterHTML().WithNodeID(node.NodeID).Do(ctx)
+		return err
+	}))
+	if len(html) > rawHTMLLimit {
+		html = html[:rawHTMLLimit]
+	}
+	return html
+}
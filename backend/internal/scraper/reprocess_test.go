@@ -0,0 +1,62 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestReprocessFixesStoredEntries(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+	manager.Register(NewVuduScraper(manager.config, db))
+
+	vudu, _ := db.GetServiceByName("Vudu")
+	wrong := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	raw := &database.RawPayload{Title: "Heat", Date: "Watched Jan 5, 2025", Runtime: "PT2H50M"}
+	unchanged := &database.RawPayload{Title: "Ronin", Date: "2025-02-01", Runtime: "2h 2m"}
+	for _, wh := range []database.WatchHistory{
+		// Stored by a parser that got the date and runtime wrong
+		{ServiceID: vudu.ID, Title: "Heat", DurationMinutes: 110, WatchedAt: wrong, Raw: raw},
+		{ServiceID: vudu.ID, Title: "Ronin", DurationMinutes: 122, WatchedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Raw: unchanged},
+	} {
+		if err := db.InsertWatchHistory(&wh); err != nil {
+			t.Fatalf("Failed to insert entry: %v", err)
+		}
+	}
+
+	result, err := manager.Reprocess(context.Background(), "Vudu", true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if result.Checked != 2 || result.Updated != 1 {
+		t.Errorf("Expected 2 checked and 1 to update, got %+v", result)
+	}
+	entries, _ := db.GetRawWatchHistory(vudu.ID)
+	if !entries[1].WatchedAt.Equal(wrong) {
+		t.Errorf("Dry run should not change entries, got %s", entries[1].WatchedAt)
+	}
+
+	if _, err := manager.Reprocess(context.Background(), "Vudu", false); err != nil {
+		t.Fatalf("Reprocess failed: %v", err)
+	}
+	entries, _ = db.GetRawWatchHistory(vudu.ID)
+	if entries[0].Title != "Heat" || entries[0].WatchedAt.Format("2006-01-02") != "2025-01-05" || entries[0].DurationMinutes != 170 {
+		t.Errorf("Expected Heat to be re-derived from its payload, got %+v", entries[0])
+	}
+}
+
+func TestReprocessUnsupported(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+	manager.Register(&MockScraper{name: "Netflix"})
+
+	if _, err := manager.Reprocess(context.Background(), "Netflix", false); err != ErrReprocessUnsupported {
+		t.Errorf("Expected ErrReprocessUnsupported, got %v", err)
+	}
+	if _, err := manager.Reprocess(context.Background(), "Nope", false); err != ErrScraperNotFound {
+		t.Errorf("Expected ErrScraperNotFound, got %v", err)
+	}
+}
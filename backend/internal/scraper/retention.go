@@ -0,0 +1,38 @@
+package scraper
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// PruneRuns rolls scraper runs past scraper.run_retention_days up into daily
+// summaries, keeping each service's newest scraper.run_retention_runs, and
+// deletes the pruned runs' stored artifacts. It does nothing when retention
+// is disabled.
+func (m *Manager) PruneRuns(ctx context.Context) (*database.RunPruneResult, error) {
+	days := m.config.Scraper.RunRetentionDays
+	if days <= 0 {
+		return &database.RunPruneResult{}, nil
+	}
+	keep := m.config.Scraper.RunRetentionRuns
+	if keep < 0 {
+		keep = 0
+	}
+
+	result, err := m.db.PruneScraperRuns(time.Now().AddDate(0, 0, -days), keep)
+	if err != nil {
+		return nil, err
+	}
+
+	if store := m.artifactStore(); store != nil {
+		for _, id := range result.RunIDs {
+			if err := store.Delete(ctx, DOMSnapshotKey(id)); err != nil {
+				log.Printf("Failed to delete page snapshot for run %d: %v", id, err)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/storage"
+)
+
+func TestPruneRunsDeletesArtifacts(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+	manager.config.Scraper.RunRetentionDays = 30
+	manager.config.Scraper.RunRetentionRuns = 1
+	store := storage.NewLocal(t.TempDir())
+	manager.SetArtifactStore(store)
+
+	service, _ := db.GetServiceByName("Netflix")
+	old := &database.ScraperRun{ServiceID: service.ID, RanAt: time.Now().AddDate(0, 0, -60), Status: "failed", DOMSnapshot: "<html>old</html>"}
+	manager.recordFailedRun(context.Background(), old)
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: time.Now(), Status: "success"})
+
+	result, err := manager.PruneRuns(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to prune runs: %v", err)
+	}
+	if result.RunsDeleted != 1 {
+		t.Errorf("Expected the old run pruned, got %+v", result)
+	}
+	if _, err := store.Get(context.Background(), DOMSnapshotKey(old.ID)); err != storage.ErrNotFound {
+		t.Errorf("Expected the pruned run's snapshot deleted, got %v", err)
+	}
+
+	manager.config.Scraper.RunRetentionDays = -1
+	if result, _ := manager.PruneRuns(context.Background()); result.RunsDeleted != 0 {
+		t.Errorf("Expected nothing pruned with retention disabled, got %+v", result)
+	}
+}
@@ -2,10 +2,16 @@ package scraper
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/jgoulah/streamtime/internal/config"
 	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/storage"
 )
 
 // Scraper defines the interface for all service scrapers
@@ -17,48 +23,165 @@ type Scraper interface {
 	Scrape(ctx context.Context) ([]database.WatchHistory, error)
 }
 
+// What started a scraper run, as recorded in scraper_runs
+const (
+	TriggerManual    = "manual"
+	TriggerScheduled = "scheduled"
+)
+
 // Result contains the outcome of a scraper run
 type Result struct {
-	ServiceName  string
-	ItemsScraped int
-	Success      bool
-	Error        error
-	StartTime    time.Time
-	EndTime      time.Time
+	ServiceName   string
+	Trigger       string // TriggerManual or TriggerScheduled
+	LookbackMode  string // LookbackFirstRun, LookbackIncremental or LookbackRefresh
+	ItemsScraped  int
+	ItemsRejected int            // Items that failed validation and weren't stored
+	SelectorHits  map[string]int // Elements matched per key selector
+	Success       bool
+	Error         error
+	StartTime     time.Time
+	EndTime       time.Time
+}
+
+// Duration returns how long the run took
+func (r *Result) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// RunSummary aggregates the results of running several scrapers
+type RunSummary struct {
+	Results   []*Result // One per scraper, sorted by service name
+	Succeeded int
+	Failed    int
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// RunOptions controls a single scraper run
+type RunOptions struct {
+	// Force runs the scraper even if its circuit is open or it has reached its
+	// daily run limit (used for manual triggers)
+	Force bool
+
+	// Since re-scrapes all history back to this time, upserting corrections to
+	// stored entries instead of stopping at the first one already stored
+	Since time.Time
+
+	// Limit stops the run after this many items, like test mode in config but
+	// for a single run (0 uses the config)
+	Limit int
+
+	// Review holds scraped items for approval instead of adding them to watch
+	// history, as if review were enabled for the service in config
+	Review bool
+
+	// Trigger records what started the run (default TriggerManual)
+	Trigger string
+
+	// Wait queues the run behind one already in progress for the same
+	// service, instead of skipping it with ErrRunInProgress
+	Wait bool
+}
+
+// CircuitState describes whether automatic runs for a service are backing off
+type CircuitState struct {
+	ServiceName         string     `json:"service_name"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Open                bool       `json:"open"`
+	RetryAfter          *time.Time `json:"retry_after,omitempty"`
 }
 
-// Manager coordinates multiple scrapers
+// RunListener is called after every scraper run that reaches the scraper
+type RunListener func(result *Result)
+
+// Manager coordinates multiple scrapers. It is safe for concurrent use:
+// scheduled, triggered and RunAll runs can overlap, but only one run per
+// service goes at a time, since runs of a service share its scraper and cookies.
 type Manager struct {
-	scrapers map[string]Scraper
-	db       *database.DB
-	config   *config.Config
+	mu        sync.RWMutex // Guards scrapers, listeners, artifacts and running
+	scrapers  map[string]Scraper
+	listeners []RunListener
+	artifacts storage.Store            // Where failed runs' page snapshots go; nil keeps them in the database
+	running   map[string]chan struct{} // Per-service run slot, full while a run is in progress
+
+	db     *database.DB
+	config *config.Config
+	jobs   *jobRegistry
+
+	enrichQueue chan []database.WatchHistory // Stored items awaiting TMDB details; nil until enrichment starts
 }
 
 // NewManager creates a new scraper manager
 func NewManager(db *database.DB, cfg *config.Config) *Manager {
 	return &Manager{
 		scrapers: make(map[string]Scraper),
+		running:  make(map[string]chan struct{}),
 		db:       db,
 		config:   cfg,
+		jobs:     newJobRegistry(),
 	}
 }
 
 // Register adds a scraper to the manager
 func (m *Manager) Register(scraper Scraper) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.scrapers[scraper.Name()] = scraper
 }
 
+// OnRunComplete registers a listener notified after each scraper run, used by
+// exporters that push stats elsewhere
+func (m *Manager) OnRunComplete(listener RunListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// notify passes a finished run to all listeners. Runs finish concurrently, so
+// listeners may be called from several goroutines at once.
+func (m *Manager) notify(result *Result) {
+	m.mu.RLock()
+	listeners := m.listeners
+	m.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(result)
+	}
+}
+
 // Run executes a specific scraper by name
 func (m *Manager) Run(ctx context.Context, serviceName string) (*Result, error) {
-	scraper, ok := m.scrapers[serviceName]
+	return m.RunWithOptions(ctx, serviceName, RunOptions{})
+}
+
+// RunWithOptions executes a specific scraper by name with the given options
+func (m *Manager) RunWithOptions(ctx context.Context, serviceName string, opts RunOptions) (*Result, error) {
+	scraper, ok := m.GetScraper(serviceName)
 	if !ok {
 		return nil, ErrScraperNotFound
 	}
 
 	result := &Result{
 		ServiceName: serviceName,
+		Trigger:     opts.Trigger,
 		StartTime:   time.Now(),
 	}
+	if result.Trigger == "" {
+		result.Trigger = TriggerManual
+	}
+
+	// Only one run per service at a time, whatever started it
+	release, err := m.claimRun(ctx, serviceName, opts.Wait)
+	if err != nil {
+		if errors.Is(err, ErrRunInProgress) {
+			log.Printf("Skipping %s: a run is already in progress", serviceName)
+		}
+		result.Error = err
+		result.EndTime = time.Now()
+		return result, err
+	}
+	defer release()
+	result.StartTime = time.Now()
 
 	// Get service from database
 	service, err := m.db.GetServiceByName(serviceName)
@@ -74,73 +197,447 @@ func (m *Manager) Run(ctx context.Context, serviceName string) (*Result, error)
 		return result, ErrServiceNotFound
 	}
 
-	// Run the scraper
-	items, err := scraper.Scrape(ctx)
+	// Disabled services aren't tracked, and a merged service's history lives on
+	// in the service it was merged into
+	if !service.Enabled || service.Archived {
+		log.Printf("Skipping %s: service is disabled or archived", serviceName)
+		result.Error = ErrServiceInactive
+		result.EndTime = time.Now()
+		return result, ErrServiceInactive
+	}
+
+	// Never scrape a service whose automation risk wasn't acknowledged, even manually
+	if consent, err := m.Consent(service); err != nil || !consent.Allowed {
+		if err == nil {
+			log.Printf("Skipping %s: automation risk not acknowledged", serviceName)
+			err = ErrNoConsent
+		}
+		result.Error = err
+		result.EndTime = time.Now()
+		return result, err
+	}
+
+	// Back off after repeated failures so a broken flow doesn't keep launching Chrome
+	if !opts.Force {
+		state, err := m.circuitState(serviceName, service.ID)
+		if err == nil && state.Open {
+			log.Printf("Skipping %s: %d consecutive failures, retrying after %s",
+				serviceName, state.ConsecutiveFailures, state.RetryAfter.Format(time.RFC3339))
+			result.Error = ErrCircuitOpen
+			result.EndTime = time.Now()
+			return result, ErrCircuitOpen
+		}
+
+		// Don't scrape more often than the service's throttle allows
+		if limit := throttleFor(m.config, serviceName).MaxRunsPerDay; limit > 0 {
+			runs, err := m.db.CountScraperRuns(service.ID, startOfDay(result.StartTime))
+			if err == nil && runs >= limit {
+				log.Printf("Skipping %s: already scraped %d times today, limit is %d", serviceName, runs, limit)
+				result.Error = ErrRunLimit
+				result.EndTime = time.Now()
+				return result, ErrRunLimit
+			}
+		}
+	}
+
+	// Services shared by several people are scraped once per profile, each
+	// with its own cookies; a nil profile uses the service's own
+	profiles := []*scrapeProfile{nil}
+	if configured := profilesFor(m.config, serviceName); len(configured) > 0 {
+		profiles = profiles[:0]
+		for _, p := range configured {
+			stored, err := m.db.EnsureProfile(p.Name)
+			if err != nil {
+				result.Error = err
+				result.EndTime = time.Now()
+				return result, err
+			}
+			profiles = append(profiles, &scrapeProfile{p, stored.ID})
+		}
+	}
+
+	var errs []error
+	for _, profile := range profiles {
+		if err := m.scrape(ctx, scraper, service, profile, opts, result); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	result.EndTime = time.Now()
+	if len(errs) == 1 {
+		result.Error = errs[0]
+	} else {
+		result.Error = errors.Join(errs...)
+	}
+	result.Success = result.Error == nil
+	m.notify(result)
+
+	return result, result.Error
+}
+
+// claimRun takes a service's run slot, waiting for a run in progress to finish
+// when wait is set and returning ErrRunInProgress otherwise. The returned func
+// frees the slot.
+func (m *Manager) claimRun(ctx context.Context, serviceName string, wait bool) (func(), error) {
+	m.mu.Lock()
+	slot, ok := m.running[serviceName]
+	if !ok {
+		slot = make(chan struct{}, 1)
+		m.running[serviceName] = slot
+	}
+	m.mu.Unlock()
+
+	if wait {
+		select {
+		case slot <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		select {
+		case slot <- struct{}{}:
+		default:
+			return nil, ErrRunInProgress
+		}
+	}
+	return func() { <-slot }, nil
+}
+
+// scrape runs a scraper once for a profile, or with the service's own cookies
+// when profile is nil, then stores what it found and records the run. Items
+// and selector hits are added to the result.
+func (m *Manager) scrape(ctx context.Context, scraper Scraper, service *database.Service, profile *scrapeProfile, opts RunOptions, result *Result) error {
+	started := time.Now()
+	var profileID int64
+	label := service.Name
+	if profile != nil {
+		profileID = profile.id
+		label += " profile " + profile.Name
+		ctx = withProfile(ctx, *profile)
+	}
+
+	// Go deep the first time a service is scraped, then only fetch recent days,
+	// unless a specific window was asked for
+	var since time.Time
+	if !opts.Since.IsZero() {
+		result.LookbackMode = LookbackRefresh
+		since = opts.Since
+		ctx = WithRefresh(ctx, since)
+	} else {
+		result.LookbackMode = LookbackIncremental
+		if hasHistory, err := historyDB(ctx, m.db).HasWatchHistory(service.ID); err == nil && !hasHistory {
+			result.LookbackMode = LookbackFirstRun
+		}
+		if days := lookbackDays(m.config, service.Name, result.LookbackMode); days > 0 {
+			since = started.AddDate(0, 0, -days)
+			ctx = WithLookback(ctx, since)
+		}
+	}
+	if opts.Limit > 0 {
+		ctx = WithItemLimit(ctx, opts.Limit)
+	}
+	ctx = withThrottle(ctx, throttleFor(m.config, service.Name))
+	log.Printf("Scraping %s (%s run, since %s)", label, result.LookbackMode, formatSince(since))
+
+	// Run the scraper, counting what its selectors match and keeping its
+	// log output and last page for a failure report
+	ctx, hits := withSelectorHits(ctx)
+	ctx, dom := withDOMSnapshot(ctx)
+	runLog, stopLog := captureRunLog()
+	items, err := scraper.Scrape(ctx)
+	stopLog()
+	selectorHits := hits.snapshot()
+	for key, n := range selectorHits {
+		if result.SelectorHits == nil {
+			result.SelectorHits = make(map[string]int)
+		}
+		result.SelectorHits[key] += n
+	}
+
+	var counts storeCounts
+	if err == nil {
+		// Enforce the limit for scrapers that can't stop early
+		if limit := itemLimit(ctx, m.config); limit > 0 && len(items) > limit {
+			items = items[:limit]
+		}
+		review := opts.Review || reviewEnabled(m.config, service.Name)
+		if counts, err = m.storeItems(items, service.ID, profileID, since, review); err != nil {
+			err = fmt.Errorf("failed to store scraped items: %w", err)
+		}
+	}
 
 	if err != nil {
-		result.Error = err
-		result.Success = false
+		if profile != nil {
+			err = fmt.Errorf("profile %s: %w", profile.Name, err)
+		}
 
 		// Record failed scraper run
-		m.db.InsertScraperRun(&database.ScraperRun{
+		m.recordFailedRun(ctx, &database.ScraperRun{
 			ServiceID:    service.ID,
-			RanAt:        result.StartTime,
+			RanAt:        started,
 			Status:       "failed",
 			ErrorMessage: err.Error(),
 			ItemsScraped: 0,
+			TriggeredBy:  result.Trigger,
+			SelectorHits: selectorHits,
+			Logs:         runLog.String(),
+			DOMSnapshot:  dom.String(),
+			ProfileID:    profileID,
 		})
+		return err
+	}
 
-		return result, err
+	result.ItemsScraped += len(items) - counts.rejected
+	result.ItemsRejected += counts.rejected
+	if counts.rejected > 0 {
+		log.Printf("Rejected %d invalid items scraped from %s", counts.rejected, label)
 	}
 
-	// Store items in database
+	// Record successful scraper run
+	m.db.InsertScraperRun(&database.ScraperRun{
+		ServiceID:     service.ID,
+		RanAt:         started,
+		Status:        "success",
+		ErrorMessage:  "",
+		ItemsScraped:  len(items) - counts.rejected,
+		ItemsRejected: counts.rejected,
+		ItemsSkipped:  counts.skipped,
+		ItemsIgnored:  counts.ignored,
+		TriggeredBy:   result.Trigger,
+		SelectorHits:  selectorHits,
+		ProfileID:     profileID,
+	})
+	return nil
+}
+
+// storeCounts counts the scraped items a run didn't store, by reason
+type storeCounts struct {
+	rejected int // Failed validation
+	skipped  int // Outside the lookback, or on a compacted day
+	ignored  int // Titles the user ignores
+}
+
+// storeItems adds scraped items to watch history in one batch, attributed to
+// the profile they were scraped for, or holds them for approval in review mode.
+// Invalid items are rejected first, and items that aren't stored are counted.
+// Stored items missing details are queued for enrichment.
+func (m *Manager) storeItems(items []database.WatchHistory, serviceID, profileID int64, since time.Time, review bool) (storeCounts, error) {
+	var counts storeCounts
 	for i := range items {
+		items[i].ProfileID = profileID
+
 		// Only set ServiceID if not already set by the scraper
 		// (Some scrapers like YouTube set it themselves to split items across services)
 		if items[i].ServiceID == 0 {
-			items[i].ServiceID = service.ID
+			items[i].ServiceID = serviceID
 		}
-		if err := m.db.InsertWatchHistory(&items[i]); err != nil {
-			// Log error but continue processing other items
+	}
+
+	items, rejected, err := m.validateItems(items)
+	if err != nil {
+		return counts, err
+	}
+	counts.rejected = rejected
+
+	var batch []database.WatchHistory
+	for i := range items {
+		// Skip history older than the lookback, for scrapers that can't stop early
+		if !since.IsZero() && items[i].WatchedAt.Before(since) {
+			counts.skipped++
 			continue
 		}
+
+		// Skip titles the user has chosen to ignore
+		ignored, err := m.db.IsTitleIgnored(items[i].ServiceID, items[i].Title)
+		if err != nil {
+			return counts, fmt.Errorf("failed to check if %s is ignored: %w", items[i].Title, err)
+		}
+		if ignored {
+			counts.ignored++
+			continue
+		}
+
+		if review {
+			if err := m.db.InsertPendingItem(&items[i]); err != nil {
+				// Log error but continue processing other items
+				log.Printf("Failed to hold %s for review: %v", items[i].Title, err)
+			}
+			continue
+		}
+		batch = append(batch, items[i])
 	}
 
-	result.ItemsScraped = len(items)
-	result.Success = true
+	if err := m.db.InsertWatchHistoryBatch(batch); err != nil {
+		return counts, err
+	}
+	// Days that were compacted already count the item in a rollup
+	for i := range batch {
+		if batch[i].ID == 0 {
+			counts.skipped++
+		}
+	}
+	m.queueEnrichment(batch)
+	return counts, nil
+}
 
-	// Record successful scraper run
-	m.db.InsertScraperRun(&database.ScraperRun{
-		ServiceID:    service.ID,
-		RanAt:        result.StartTime,
-		Status:       "success",
-		ErrorMessage: "",
-		ItemsScraped: len(items),
+// reviewEnabled reports whether a service's scraped items need approval
+func reviewEnabled(cfg *config.Config, serviceName string) bool {
+	for key, svc := range cfg.Services {
+		if svc.Review && ServiceNameFor(cfg, key) == serviceName {
+			return true
+		}
+	}
+	return false
+}
+
+// formatSince describes a lookback cutoff for logging
+func formatSince(since time.Time) string {
+	if since.IsZero() {
+		return "the beginning"
+	}
+	return since.Format("2006-01-02")
+}
+
+// RunAll executes all registered scrapers concurrently, up to
+// scraper.concurrency at a time
+func (m *Manager) RunAll(ctx context.Context) *RunSummary {
+	return m.RunMany(ctx, m.Names(), RunOptions{})
+}
+
+// RunMany executes the named scrapers with the same options, running up to
+// scraper.concurrency at a time since each one drives its own browser. A
+// failing scraper doesn't stop the others; its result records the error.
+// Disabled and archived services are skipped without a result.
+func (m *Manager) RunMany(ctx context.Context, names []string, opts RunOptions) *RunSummary {
+	names = m.activeNames(names)
+	summary := &RunSummary{
+		Results:   make([]*Result, len(names)),
+		StartTime: time.Now(),
+	}
+
+	workers := m.config.Scraper.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				now := time.Now()
+				summary.Results[i] = &Result{ServiceName: name, Trigger: opts.Trigger, Error: ctx.Err(), StartTime: now, EndTime: now}
+				return
+			}
+
+			result, err := m.RunWithOptions(ctx, name, opts)
+			if result == nil {
+				// Unknown scrapers fail before a run starts
+				now := time.Now()
+				result = &Result{ServiceName: name, Trigger: opts.Trigger, Error: err, StartTime: now, EndTime: now}
+			}
+			summary.Results[i] = result
+		}()
+	}
+	wg.Wait()
+	summary.EndTime = time.Now()
+
+	sort.SliceStable(summary.Results, func(i, k int) bool {
+		return summary.Results[i].ServiceName < summary.Results[k].ServiceName
 	})
+	for _, result := range summary.Results {
+		if result.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
 
-	return result, nil
+	return summary
 }
 
-// RunAll executes all registered scrapers
-func (m *Manager) RunAll(ctx context.Context) ([]*Result, error) {
-	var results []*Result
+// activeNames leaves out services that are disabled or archived. Services
+// missing from the database are kept, so their runs report the error.
+func (m *Manager) activeNames(names []string) []string {
+	var active []string
+	for _, name := range names {
+		service, err := m.db.GetServiceByName(name)
+		if err == nil && service != nil && (!service.Enabled || service.Archived) {
+			log.Printf("Skipping %s: service is disabled or archived", name)
+			continue
+		}
+		active = append(active, name)
+	}
+	return active
+}
 
-	for name := range m.scrapers {
-		result, err := m.Run(ctx, name)
+// CircuitStates returns the backoff state of every registered scraper
+func (m *Manager) CircuitStates() ([]CircuitState, error) {
+	var states []CircuitState
+	for _, name := range m.Names() {
+		service, err := m.db.GetServiceByName(name)
 		if err != nil {
-			// Continue with other scrapers even if one fails
-			results = append(results, result)
+			return nil, err
+		}
+		if service == nil {
 			continue
 		}
-		results = append(results, result)
+
+		state, err := m.circuitState(name, service.ID)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, *state)
 	}
 
-	return results, nil
+	return states, nil
+}
+
+// circuitState checks a service's recent failures against the configured backoff
+func (m *Manager) circuitState(serviceName string, serviceID int64) (*CircuitState, error) {
+	failures, lastFailure, err := m.db.GetConsecutiveFailures(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &CircuitState{
+		ServiceName:         serviceName,
+		ConsecutiveFailures: failures,
+	}
+
+	maxFailures := m.config.Scraper.MaxConsecutiveFailures
+	if maxFailures > 0 && failures >= maxFailures {
+		retryAfter := lastFailure.Add(time.Duration(m.config.Scraper.BackoffHours) * time.Hour)
+		state.RetryAfter = &retryAfter
+		state.Open = time.Now().Before(retryAfter)
+	}
+
+	return state, nil
+}
+
+// Names returns the names of all registered scrapers, sorted
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.scrapers))
+	for name := range m.scrapers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // GetScraper returns a scraper by name
 func (m *Manager) GetScraper(name string) (Scraper, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	scraper, ok := m.scrapers[name]
 	return scraper, ok
 }
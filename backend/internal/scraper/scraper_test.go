@@ -2,6 +2,7 @@ package scraper
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -41,6 +42,17 @@ func setupTestManager(t *testing.T) (*Manager, *database.DB) {
 		},
 	}
 
+	// Like configured services, the seeded ones tests scrape are enabled
+	services, err := db.GetAllServices()
+	if err != nil {
+		t.Fatalf("Failed to get services: %v", err)
+	}
+	for _, service := range services {
+		if err := db.EnableService(service.Name); err != nil {
+			t.Fatalf("Failed to enable %s: %v", service.Name, err)
+		}
+	}
+
 	manager := NewManager(db, cfg)
 	return manager, db
 }
@@ -270,33 +282,162 @@ func TestRunAll(t *testing.T) {
 
 	// Run all scrapers
 	ctx := context.Background()
-	results, err := manager.RunAll(ctx)
+	summary := manager.RunAll(ctx)
 
-	if err != nil {
-		t.Errorf("RunAll should not return error even if individual scrapers fail: %v", err)
+	if len(summary.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(summary.Results))
 	}
 
-	if len(results) != 2 {
-		t.Errorf("Expected 2 results, got %d", len(results))
+	// Failures don't stop the other scrapers
+	if summary.Succeeded != 1 || summary.Failed != 1 {
+		t.Errorf("Expected 1 success and 1 failure, got %d and %d", summary.Succeeded, summary.Failed)
+	}
+	if summary.Results[0].ServiceName != "Netflix" || !summary.Results[0].Success {
+		t.Errorf("Expected results sorted by name with Netflix succeeding, got %+v", summary.Results[0])
+	}
+	if summary.Results[1].Error == nil {
+		t.Error("Expected the failed result to keep its error")
 	}
+}
+
+// countingScraper records how many scrapes overlap
+type countingScraper struct {
+	name    string
+	mu      *sync.Mutex
+	running *int
+	peak    *int
+}
+
+func (s *countingScraper) Name() string {
+	return s.name
+}
 
-	// Check that at least one succeeded
-	var hasSuccess bool
-	var hasFailure bool
-	for _, result := range results {
-		if result.Success {
-			hasSuccess = true
-		} else {
-			hasFailure = true
+func (s *countingScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	s.mu.Lock()
+	*s.running++
+	if *s.running > *s.peak {
+		*s.peak = *s.running
+	}
+	s.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	*s.running--
+	s.mu.Unlock()
+	return nil, nil
+}
+
+func TestRunAllLimitsConcurrency(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+	manager.config.Scraper.Concurrency = 2
+
+	var mu sync.Mutex
+	var running, peak int
+	for _, name := range []string{"Netflix", "Hulu", "Disney+", "HBO Max"} {
+		manager.Register(&countingScraper{name: name, mu: &mu, running: &running, peak: &peak})
+	}
+
+	summary := manager.RunAll(context.Background())
+
+	if summary.Succeeded != 4 {
+		t.Errorf("Expected 4 successful runs, got %+v", summary.Results)
+	}
+	if peak != 2 {
+		t.Errorf("Expected 2 scrapers running at once, got %d", peak)
+	}
+	for _, result := range summary.Results {
+		if result.Duration() < 20*time.Millisecond {
+			t.Errorf("Expected %s's duration to cover its scrape, got %s", result.ServiceName, result.Duration())
 		}
 	}
+}
+
+func TestRunManyUnknownScraper(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
 
-	if !hasSuccess {
-		t.Error("Expected at least one successful result")
+	summary := manager.RunMany(context.Background(), []string{"nonexistent"}, RunOptions{})
+	if summary.Failed != 1 || summary.Results[0].Error != ErrScraperNotFound {
+		t.Errorf("Expected a failed result for the unknown scraper, got %+v", summary.Results)
 	}
+}
+
+// gatedScraper signals each scrape starting, then waits for release and
+// records how many scrapes overlap
+type gatedScraper struct {
+	MockScraper
+	started chan struct{}
+	release chan struct{}
+
+	mu      sync.Mutex
+	running int
+	peak    int
+}
 
-	if !hasFailure {
-		t.Error("Expected at least one failed result")
+func (g *gatedScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	g.mu.Lock()
+	g.running++
+	if g.running > g.peak {
+		g.peak = g.running
+	}
+	g.mu.Unlock()
+
+	g.started <- struct{}{}
+	<-g.release
+
+	g.mu.Lock()
+	g.running--
+	g.mu.Unlock()
+	return g.MockScraper.Scrape(ctx)
+}
+
+func TestScheduledAndTriggeredRunsDontOverlap(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	scraper := &gatedScraper{
+		MockScraper: MockScraper{name: "Netflix"},
+		started:     make(chan struct{}, 3),
+		release:     make(chan struct{}),
+	}
+	manager.Register(scraper)
+
+	// A run is triggered while a scheduled run is under way
+	scheduled := make(chan *RunSummary)
+	go func() {
+		scheduled <- manager.RunMany(context.Background(), []string{"Netflix"}, RunOptions{Trigger: TriggerScheduled})
+	}()
+	<-scraper.started
+	job := manager.StartJob("Netflix", RunOptions{Force: true}, time.Minute)
+
+	// The triggered run waits, and another run that doesn't wait is skipped
+	overlapping := make(chan *RunSummary, 1)
+	go func() { overlapping <- manager.RunAll(context.Background()) }()
+	select {
+	case summary := <-overlapping:
+		if summary.Results[0].Error != ErrRunInProgress {
+			t.Errorf("Expected an overlapping run to be skipped, got %+v", summary.Results[0])
+		}
+	case <-scraper.started:
+		t.Fatal("RunAll scraped while the scheduled run was going")
+	}
+	select {
+	case <-scraper.started:
+		t.Fatal("Triggered run started while the scheduled run was going")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(scraper.release)
+	if summary := <-scheduled; summary.Succeeded != 1 {
+		t.Errorf("Expected the scheduled run to succeed, got %+v", summary.Results[0])
+	}
+	if done := waitForJob(t, manager, job.ID); done.State != JobSuccess {
+		t.Errorf("Expected the triggered run to succeed after the scheduled one, got %+v", done)
+	}
+	if scraper.peak != 1 {
+		t.Errorf("Expected one scrape at a time, got %d at once", scraper.peak)
 	}
 }
 
@@ -337,3 +478,491 @@ func TestResultTiming(t *testing.T) {
 		t.Error("EndTime should be after StartTime")
 	}
 }
+
+func TestRunSkipsMergedService(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	source, _ := db.GetServiceByName("HBO Max")
+	target, _ := db.GetServiceByName("Netflix")
+	if _, err := db.MergeServices(source.ID, target.ID); err != nil {
+		t.Fatalf("Failed to merge services: %v", err)
+	}
+
+	manager.Register(&MockScraper{
+		name:  "HBO Max",
+		items: []database.WatchHistory{{Title: "The Pitt", DurationMinutes: 50, WatchedAt: time.Now()}},
+	})
+
+	if _, err := manager.RunWithOptions(context.Background(), "HBO Max", RunOptions{Force: true}); err != ErrServiceInactive {
+		t.Errorf("Expected ErrServiceInactive, got %v", err)
+	}
+	summary := manager.RunAll(context.Background())
+	if len(summary.Results) != 0 {
+		t.Errorf("Expected the merged service skipped, got %+v", summary.Results)
+	}
+
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM watch_history").Scan(&count)
+	if count != 0 {
+		t.Errorf("Expected no history stored, got %d rows", count)
+	}
+	runs, _ := db.GetScraperRuns(source.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if len(runs) != 0 {
+		t.Errorf("Expected no runs recorded, got %d", len(runs))
+	}
+}
+
+func TestRunSkipsIgnoredTitles(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	db.InsertIgnoredTitle(&database.IgnoredTitle{Title: "White Noise"})
+
+	now := time.Now()
+	manager.Register(&MockScraper{
+		name: "Netflix",
+		items: []database.WatchHistory{
+			{Title: "Real Show", DurationMinutes: 30, WatchedAt: now},
+			{Title: "White Noise", DurationMinutes: 480, WatchedAt: now},
+		},
+	})
+
+	if _, err := manager.Run(context.Background(), "Netflix"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM watch_history WHERE service_id = ?", service.ID).Scan(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 stored item with ignored title skipped, got %d", count)
+	}
+
+	runs, _ := db.GetScraperRuns(service.ID, now.Add(-time.Hour), now.Add(time.Hour))
+	if len(runs) != 1 || runs[0].ItemsIgnored != 1 || runs[0].ItemsSkipped != 0 {
+		t.Errorf("Expected the run to count 1 ignored item, got %+v", runs)
+	}
+}
+
+func TestRunFailsWhenIgnoredTitlesCantBeChecked(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.Register(&MockScraper{
+		name:  "Netflix",
+		items: []database.WatchHistory{{Title: "White Noise", DurationMinutes: 480, WatchedAt: time.Now()}},
+	})
+	if _, err := db.Exec("DROP TABLE ignored_titles"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.Run(context.Background(), "Netflix"); err == nil {
+		t.Fatal("Expected the run to fail")
+	}
+
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM watch_history").Scan(&count)
+	if count != 0 {
+		t.Errorf("Expected nothing stored when ignored titles can't be checked, got %d rows", count)
+	}
+}
+
+func TestRunBacksOffAfterRepeatedFailures(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.config.Scraper.MaxConsecutiveFailures = 3
+	manager.config.Scraper.BackoffHours = 24
+
+	manager.Register(&MockScraper{name: "Netflix", shouldErr: true})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Run(ctx, "Netflix"); err != ErrNoDataFound {
+			t.Fatalf("Run %d: expected ErrNoDataFound, got %v", i, err)
+		}
+	}
+
+	// The circuit is now open for automatic runs
+	_, err := manager.Run(ctx, "Netflix")
+	if err != ErrCircuitOpen {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	states, err := manager.CircuitStates()
+	if err != nil {
+		t.Fatalf("Failed to get circuit states: %v", err)
+	}
+	if len(states) != 1 || !states[0].Open || states[0].ConsecutiveFailures != 3 {
+		t.Errorf("Expected open circuit after 3 failures, got %+v", states)
+	}
+
+	// Forced (manual) runs still go through
+	_, err = manager.RunWithOptions(ctx, "Netflix", RunOptions{Force: true})
+	if err != ErrNoDataFound {
+		t.Errorf("Expected forced run to reach the scraper, got %v", err)
+	}
+}
+
+func TestCircuitClosesAfterSuccess(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.config.Scraper.MaxConsecutiveFailures = 2
+	manager.config.Scraper.BackoffHours = 24
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: now.Add(-3 * time.Hour), Status: "failed"})
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: now.Add(-2 * time.Hour), Status: "failed"})
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: now.Add(-1 * time.Hour), Status: "success"})
+
+	manager.Register(&MockScraper{name: "Netflix"})
+
+	if _, err := manager.Run(context.Background(), "Netflix"); err != nil {
+		t.Errorf("Expected run to proceed after a success, got %v", err)
+	}
+}
+
+func TestRunNeverBacksOffWhenDisabled(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.config.Scraper.MaxConsecutiveFailures = -1
+	manager.config.Scraper.BackoffHours = 24
+
+	service, _ := db.GetServiceByName("Netflix")
+	for i := 1; i <= 5; i++ {
+		db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: time.Now().Add(-time.Duration(i) * time.Hour), Status: "failed"})
+	}
+
+	manager.Register(&MockScraper{name: "Netflix"})
+
+	if _, err := manager.Run(context.Background(), "Netflix"); err != nil {
+		t.Errorf("Expected run to proceed with backoff disabled, got %v", err)
+	}
+}
+
+func TestRunSkipsAfterDailyRunLimit(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.config.Services["netflix"] = config.ServiceConfig{
+		Enabled:  true,
+		Throttle: config.ThrottleConfig{MaxRunsPerDay: 2},
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: now.AddDate(0, 0, -1), Status: "success"})
+	db.InsertScraperRun(&database.ScraperRun{ServiceID: service.ID, RanAt: now, Status: "success"})
+
+	manager.Register(&MockScraper{name: "Netflix"})
+
+	// Yesterday's run doesn't count
+	if _, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Trigger: TriggerScheduled}); err != nil {
+		t.Fatalf("Expected second run of the day to proceed, got %v", err)
+	}
+	if _, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Trigger: TriggerScheduled}); err != ErrRunLimit {
+		t.Errorf("Expected ErrRunLimit on third run of the day, got %v", err)
+	}
+
+	// Manual runs are let through
+	if _, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Force: true}); err != nil {
+		t.Errorf("Expected forced run to proceed, got %v", err)
+	}
+}
+
+func TestRunRequiresConsent(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.config.Scraper.RequireConsent = true
+	manager.Register(&MockScraper{name: "Netflix"})
+	manager.Register(&MockScraper{name: "Hulu"})
+	manager.config.Services["hulu"] = config.ServiceConfig{Enabled: true, AcceptAutomationRisk: true}
+
+	// Manual runs are refused too
+	if _, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Force: true}); err != ErrNoConsent {
+		t.Errorf("Expected ErrNoConsent before consent, got %v", err)
+	}
+	if _, err := manager.Run(context.Background(), "Hulu"); err != nil {
+		t.Errorf("Expected consent in config to allow runs, got %v", err)
+	}
+
+	netflix, _ := db.GetServiceByName("Netflix")
+	if _, err := manager.SetConsent(netflix, true); err != nil {
+		t.Fatalf("SetConsent failed: %v", err)
+	}
+	if _, err := manager.Run(context.Background(), "Netflix"); err != nil {
+		t.Errorf("Expected run to proceed after consent, got %v", err)
+	}
+
+	// Config consent is recorded with a timestamp and can't be withdrawn here
+	if err := manager.RecordConfigConsent(); err != nil {
+		t.Fatalf("RecordConfigConsent failed: %v", err)
+	}
+	hulu, _ := db.GetServiceByName("Hulu")
+	if recorded, _ := db.GetServiceConsent(hulu.ID); recorded == nil || !recorded.Accepted || recorded.Source != ConsentSourceConfig {
+		t.Errorf("Expected config consent recorded, got %+v", recorded)
+	}
+	if _, err := manager.SetConsent(hulu, false); err != ErrConsentInConfig {
+		t.Errorf("Expected ErrConsentInConfig, got %v", err)
+	}
+
+	states, err := manager.ConsentStates()
+	if err != nil {
+		t.Fatalf("ConsentStates failed: %v", err)
+	}
+	if len(states) != 2 || !states[0].Allowed || !states[1].Allowed {
+		t.Errorf("Expected both services allowed, got %+v", states)
+	}
+}
+
+func TestRunNotifiesListeners(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	var results []*Result
+	manager.OnRunComplete(func(result *Result) {
+		results = append(results, result)
+	})
+
+	manager.Register(&MockScraper{
+		name:  "Netflix",
+		items: []database.WatchHistory{{Title: "Show", DurationMinutes: 30, WatchedAt: time.Now()}},
+	})
+	manager.Register(&MockScraper{name: "Peacock", shouldErr: true})
+
+	manager.Run(context.Background(), "Netflix")
+	manager.Run(context.Background(), "Peacock")
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(results))
+	}
+	if !results[0].Success || results[0].ItemsScraped != 1 {
+		t.Errorf("Expected successful run with 1 item, got %+v", results[0])
+	}
+	if results[1].Success || results[1].Error == nil {
+		t.Errorf("Expected failed run, got %+v", results[1])
+	}
+}
+
+// lookbackScraper records the lookback it was run with
+type lookbackScraper struct {
+	MockScraper
+	since   time.Time
+	refresh bool
+}
+
+func (l *lookbackScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	l.since = Lookback(ctx)
+	l.refresh = IsRefresh(ctx)
+	return l.items, nil
+}
+
+func TestRunPicksLookbackMode(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.config.Scraper.FirstRunLookbackDays = 30
+	manager.config.Scraper.IncrementalLookbackDays = 3
+	netflixCfg := manager.config.Services["netflix"]
+	netflixCfg.IncrementalLookbackDays = 5
+	manager.config.Services["netflix"] = netflixCfg
+
+	now := time.Now()
+	mock := &lookbackScraper{MockScraper: MockScraper{
+		name: "Netflix",
+		items: []database.WatchHistory{
+			{Title: "Recent", DurationMinutes: 30, WatchedAt: now.AddDate(0, 0, -10)},
+			{Title: "Too Old", DurationMinutes: 30, WatchedAt: now.AddDate(0, 0, -60)},
+		},
+	}}
+	manager.Register(mock)
+
+	result, err := manager.Run(context.Background(), "Netflix")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.LookbackMode != LookbackFirstRun {
+		t.Errorf("Expected first run mode, got %q", result.LookbackMode)
+	}
+	if days := now.Sub(mock.since).Hours() / 24; days < 29.9 || days > 30.1 {
+		t.Errorf("Expected a 30 day lookback, got %.1f days", days)
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	history, _ := db.GetWatchHistory(service.ID, now.AddDate(-1, 0, 0), now, 10, 0)
+	if len(history) != 1 || history[0].Title != "Recent" {
+		t.Errorf("Expected only history within the lookback to be stored, got %+v", history)
+	}
+
+	result, _ = manager.Run(context.Background(), "Netflix")
+	if result.LookbackMode != LookbackIncremental {
+		t.Errorf("Expected incremental mode once history exists, got %q", result.LookbackMode)
+	}
+	if days := now.Sub(mock.since).Hours() / 24; days < 4.9 || days > 5.1 {
+		t.Errorf("Expected the service's 5 day incremental lookback, got %.1f days", days)
+	}
+}
+
+func TestRunRefreshWindow(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock := &lookbackScraper{MockScraper: MockScraper{name: "Netflix", items: []database.WatchHistory{
+		{Title: "Before the Window", DurationMinutes: 30, WatchedAt: since.AddDate(0, 0, -1)},
+		{Title: "In the Window", DurationMinutes: 30, WatchedAt: since.AddDate(0, 0, 1)},
+	}}}
+	manager.Register(mock)
+
+	result, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Force: true, Since: since})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.LookbackMode != LookbackRefresh {
+		t.Errorf("Expected refresh mode, got %q", result.LookbackMode)
+	}
+	if !mock.since.Equal(since) {
+		t.Errorf("Expected lookback %v, got %v", since, mock.since)
+	}
+	if !mock.refresh {
+		t.Error("Expected scraper to be told to re-scrape past stored entries")
+	}
+
+	// Items from before the window are counted but not stored
+	service, _ := db.GetServiceByName("Netflix")
+	runs, _ := db.GetScraperRuns(service.ID, result.StartTime.Add(-time.Minute), time.Now().Add(time.Minute))
+	if len(runs) != 1 || runs[0].ItemsScraped != 2 || runs[0].ItemsSkipped != 1 {
+		t.Errorf("Expected the run to count 1 skipped item, got %+v", runs)
+	}
+}
+
+func TestRunWithItemLimit(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+	var items []database.WatchHistory
+	for i := 0; i < 5; i++ {
+		items = append(items, database.WatchHistory{
+			ServiceID:       service.ID,
+			Title:           "Show",
+			DurationMinutes: 30,
+			WatchedAt:       now.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+	manager.Register(&MockScraper{name: "Netflix", items: items})
+
+	result, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Force: true, Limit: 2})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.ItemsScraped != 2 {
+		t.Errorf("Expected 2 items with a limit of 2, got %d", result.ItemsScraped)
+	}
+}
+
+func TestItemLimit(t *testing.T) {
+	cfg := &config.Config{Scraper: config.ScraperConfig{TestLimit: 100}}
+	ctx := context.Background()
+
+	if limit := itemLimit(ctx, cfg); limit != 0 {
+		t.Errorf("Expected no limit outside test mode, got %d", limit)
+	}
+	cfg.Scraper.TestMode = true
+	if limit := itemLimit(ctx, cfg); limit != 100 {
+		t.Errorf("Expected the config test limit, got %d", limit)
+	}
+	if limit := itemLimit(WithItemLimit(ctx, 10), cfg); limit != 10 {
+		t.Errorf("Expected the run's limit to win, got %d", limit)
+	}
+}
+
+func TestRunWithReviewHoldsItems(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	manager.Register(&MockScraper{name: "Netflix", items: []database.WatchHistory{
+		{ServiceID: service.ID, Title: "Unverified Show", DurationMinutes: 30, WatchedAt: time.Now()},
+	}})
+
+	if _, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Force: true, Review: true}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if hasHistory, _ := db.HasWatchHistory(service.ID); hasHistory {
+		t.Error("Expected reviewed items to stay out of watch history")
+	}
+	pending, err := db.GetPendingItems(service.ID)
+	if err != nil {
+		t.Fatalf("Failed to get pending items: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Title != "Unverified Show" {
+		t.Errorf("Expected the scraped item in the review queue, got %+v", pending)
+	}
+}
+
+// profileScraper records the cookies each run would sign in with
+type profileScraper struct {
+	MockScraper
+	cookies []string
+}
+
+func (p *profileScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	cookies := serviceCookies(ctx, nil, p.name, config.ServiceConfig{}, "netflix.com")
+	if len(cookies) == 0 {
+		return nil, ErrNoDataFound
+	}
+	p.cookies = append(p.cookies, cookies[0].Value)
+	return p.MockScraper.Scrape(ctx)
+}
+
+func TestRunScrapesEachProfile(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	netflixCfg := manager.config.Services["netflix"]
+	netflixCfg.Profiles = []config.ProfileConfig{
+		{Name: "Alex", Cookies: []config.Cookie{{Name: "NetflixId", Value: "alex"}}},
+		{Name: "Sam", Cookies: []config.Cookie{{Name: "NetflixId", Value: "sam"}}},
+		{Name: "Signed Out"},
+	}
+	manager.config.Services["netflix"] = netflixCfg
+
+	now := time.Now()
+	mock := &profileScraper{MockScraper: MockScraper{
+		name:  "Netflix",
+		items: []database.WatchHistory{{Title: "Severance", DurationMinutes: 50, WatchedAt: now}},
+	}}
+	manager.Register(mock)
+
+	result, err := manager.Run(context.Background(), "Netflix")
+	if err == nil || result.Success {
+		t.Error("Expected the profile without cookies to fail the run")
+	}
+	if result.ItemsScraped != 2 {
+		t.Errorf("Expected items from both signed in profiles, got %d", result.ItemsScraped)
+	}
+	if len(mock.cookies) != 2 || mock.cookies[0] != "alex" || mock.cookies[1] != "sam" {
+		t.Errorf("Expected each profile to use its own cookies, got %v", mock.cookies)
+	}
+
+	service, _ := db.GetServiceByName("Netflix")
+	alex, _ := db.GetProfileByName("Alex")
+	history, _ := db.ForProfile(alex.ID).GetWatchHistory(service.ID, now.Add(-time.Hour), now.Add(time.Hour), 10, 0)
+	if len(history) != 1 || history[0].ProfileID != alex.ID {
+		t.Errorf("Expected Alex's own copy of the entry, got %+v", history)
+	}
+
+	var runs int
+	db.QueryRow("SELECT COUNT(*) FROM scraper_runs WHERE service_id = ? AND profile_id != 0", service.ID).Scan(&runs)
+	if runs != 3 {
+		t.Errorf("Expected a run recorded per profile, got %d", runs)
+	}
+}
@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+)
+
+// selectorHitsKey is the context key for a run's selector hit counts
+type selectorHitsKey struct{}
+
+// selectorHits counts how many elements each key selector matched during a run
+type selectorHits struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// withSelectorHits returns a context that collects selector hit counts
+func withSelectorHits(ctx context.Context) (context.Context, *selectorHits) {
+	hits := &selectorHits{counts: make(map[string]int)}
+	return context.WithValue(ctx, selectorHitsKey{}, hits), hits
+}
+
+// RecordSelector adds n matched elements to a selector's count for the current
+// run. Recording 0 still lists the selector, which is what makes a broken one
+// stand out. It does nothing outside a Manager run.
+func RecordSelector(ctx context.Context, selector string, n int) {
+	hits, ok := ctx.Value(selectorHitsKey{}).(*selectorHits)
+	if !ok {
+		return
+	}
+	hits.mu.Lock()
+	defer hits.mu.Unlock()
+	hits.counts[selector] += n
+}
+
+// snapshot returns a copy of the counts, or nil if nothing was recorded
+func (h *selectorHits) snapshot() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.counts) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(h.counts))
+	for selector, n := range h.counts {
+		counts[selector] = n
+	}
+	return counts
+}
+
+// recordHistoryRows records hits for a row selector and for the title, date
+// and runtime selectors within the rows. Empty selectors are skipped.
+func recordHistoryRows(ctx context.Context, rows []historyRow, rowSel, titleSel, dateSel, runtimeSel string) {
+	var titles, dates, runtimes int
+	for _, row := range rows {
+		if row.Title != "" {
+			titles++
+		}
+		if row.Date != "" {
+			dates++
+		}
+		if row.Runtime != "" {
+			runtimes++
+		}
+	}
+
+	RecordSelector(ctx, rowSel, len(rows))
+	for _, field := range []struct {
+		selector string
+		hits     int
+	}{
+		{titleSel, titles},
+		{dateSel, dates},
+		{runtimeSel, runtimes},
+	} {
+		if field.selector != "" {
+			RecordSelector(ctx, field.selector, field.hits)
+		}
+	}
+}
+
+// boolHit converts whether a selector matched into a hit count
+func boolHit(matched bool) int {
+	if matched {
+		return 1
+	}
+	return 0
+}
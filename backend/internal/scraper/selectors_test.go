@@ -0,0 +1,45 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// selectorScraper records selector hits like a real scraper would
+type selectorScraper struct {
+	MockScraper
+}
+
+func (s *selectorScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	rows := []historyRow{
+		{Title: "Stalker", Date: "3/14/25"},
+		{Title: "Solaris", Date: ""},
+	}
+	recordHistoryRows(ctx, rows, ".row", ".title", ".date", ".runtime")
+	return nil, ErrNoDataFound
+}
+
+func TestRunRecordsSelectorHits(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	manager.Register(&selectorScraper{MockScraper{name: "Netflix"}})
+
+	result, _ := manager.Run(context.Background(), "Netflix")
+	want := map[string]int{".row": 2, ".title": 2, ".date": 1, ".runtime": 0}
+	for selector, n := range want {
+		if got, ok := result.SelectorHits[selector]; !ok || got != n {
+			t.Errorf("Expected %d hits for %s, got %d (recorded: %v)", n, selector, got, ok)
+		}
+	}
+
+	runs, err := db.GetLatestScraperRuns()
+	if err != nil {
+		t.Fatalf("Failed to get scraper runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].SelectorHits[".runtime"] != 0 || runs[0].SelectorHits[".row"] != 2 {
+		t.Errorf("Expected selector hits to be stored with the failed run, got %+v", runs)
+	}
+}
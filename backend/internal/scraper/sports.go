@@ -0,0 +1,35 @@
+package scraper
+
+import (
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// espnSite reads ESPN+ watch history. Live events and replays have no
+// runtime on the page, so durations come from the sports estimator.
+var espnSite = pagedSite{
+	homeURL:      "https://www.espn.com",
+	cookieDomain: ".espn.com",
+	historyURL:   "https://www.espn.com/watch/history",
+	rowSelector:  "[data-testid='history-tile']",
+	titleSel:     "[data-testid='tile-title']",
+	episodeSel:   "[data-testid='tile-subtitle']",
+	dateSel:      "[data-testid='tile-date']",
+	runtimeSel:   "[data-testid='tile-duration']",
+	tmdbRuntimes: true, // Documentaries like 30 for 30 are on TMDB
+	strategy:     estimate.StrategySports,
+	durations:    estimate.Fixed{EpisodeMinutes: 60, FilmMinutes: 90},
+}
+
+// NewESPNScraper creates a new ESPN+ scraper
+func NewESPNScraper(cfg *config.Config, db *database.DB) *PagedScraper {
+	return &PagedScraper{
+		config:      cfg,
+		db:          db,
+		tmdb:        newTMDBClient(cfg, db),
+		site:        espnSite,
+		serviceKey:  "ESPN+",
+		instanceKey: "espn_plus",
+	}
+}
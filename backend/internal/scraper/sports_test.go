@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestESPNScraper(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewESPNScraper(cfg, db)
+
+	if scraper.Name() != "ESPN+" {
+		t.Errorf("Expected name 'ESPN+', got '%s'", scraper.Name())
+	}
+
+	items := scraper.rowsToWatchHistory(context.Background(), []historyRow{
+		{Title: "Rangers vs. Devils", Episode: "NHL", Date: "2025-03-01"},
+		{Title: "Rangers vs. Devils", Episode: "Full Replay", Date: "2025-03-02", Runtime: "2h 41m"},
+		{Title: "30 for 30: The U", Date: "2025-03-03"},
+	})
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(items))
+	}
+
+	// Estimated from keywords, taken from the page, and the flat film fallback
+	expected := []int{150, 161, 90}
+	for i, item := range items {
+		if item.DurationMinutes != expected[i] {
+			t.Errorf("Item %d: expected %d minutes, got %d", i, expected[i], item.DurationMinutes)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+// throttleKey is the context key for a run's page load throttle
+type throttleKey struct{}
+
+// throttle spaces out a run's page loads and counts its history pages
+// against its limit
+type throttle struct {
+	minDelay time.Duration
+	maxPages int
+
+	mu    sync.Mutex
+	pages int
+	last  time.Time
+}
+
+// withThrottle returns a context whose page loads follow a service's
+// throttle settings
+func withThrottle(ctx context.Context, cfg config.ThrottleConfig) context.Context {
+	return context.WithValue(ctx, throttleKey{}, &throttle{
+		minDelay: time.Duration(cfg.MinDelaySeconds * float64(time.Second)),
+		maxPages: cfg.MaxPages,
+	})
+}
+
+// waitPageLoad blocks until the run may load another page. Runs without a
+// throttle never wait.
+func waitPageLoad(ctx context.Context) error {
+	return wait(ctx, false)
+}
+
+// waitHistoryPage blocks until the run may load another page of history,
+// including "show more" loads of an infinite list, and counts it. It returns
+// ErrPageLimit once the run has loaded as many history pages as allowed.
+func waitHistoryPage(ctx context.Context) error {
+	return wait(ctx, true)
+}
+
+func wait(ctx context.Context, history bool) error {
+	t, ok := ctx.Value(throttleKey{}).(*throttle)
+	if !ok {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if history && t.maxPages > 0 && t.pages >= t.maxPages {
+		return ErrPageLimit
+	}
+	if delay := time.Until(t.last.Add(t.minDelay)); !t.last.IsZero() && delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if history {
+		t.pages++
+	}
+	t.last = time.Now()
+	return nil
+}
+
+// navigate loads a page, such as a sign-in check or the site a scraper sets
+// cookies on, once the run's throttle allows it. Scrapers use it in place of
+// chromedp.Navigate.
+func navigate(url string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := waitPageLoad(ctx); err != nil {
+			return err
+		}
+		return chromedp.Navigate(url).Do(ctx)
+	})
+}
+
+// navigateHistory loads a page of history like navigate, counting it against
+// the run's page limit
+func navigateHistory(url string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := waitHistoryPage(ctx); err != nil {
+			return err
+		}
+		return chromedp.Navigate(url).Do(ctx)
+	})
+}
+
+// pageLimitReached reports whether err means the run is out of page loads,
+// logging that the rest of the history is left for the next run
+func pageLimitReached(err error, serviceName string) bool {
+	if !errors.Is(err, ErrPageLimit) {
+		return false
+	}
+	log.Printf("Reached %s page limit for this run; older history is left for the next run", serviceName)
+	return true
+}
+
+// throttleFor returns the throttle settings of a service, or none when it
+// isn't configured
+func throttleFor(cfg *config.Config, serviceName string) config.ThrottleConfig {
+	for key, svc := range cfg.Services {
+		if ServiceNameFor(cfg, key) == serviceName {
+			return svc.Throttle
+		}
+	}
+	return config.ThrottleConfig{}
+}
+
+// startOfDay returns local midnight on t's day, when daily run limits reset
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+func TestWaitHistoryPageLimit(t *testing.T) {
+	ctx := withThrottle(context.Background(), config.ThrottleConfig{MaxPages: 2})
+
+	for i := 0; i < 2; i++ {
+		if err := waitHistoryPage(ctx); err != nil {
+			t.Fatalf("Page %d: unexpected error %v", i+1, err)
+		}
+	}
+	// Other page loads don't count against the limit
+	if err := waitPageLoad(ctx); err != nil {
+		t.Fatalf("Expected non-history page load to proceed, got %v", err)
+	}
+	err := waitHistoryPage(ctx)
+	if !errors.Is(err, ErrPageLimit) {
+		t.Errorf("Expected ErrPageLimit on page 3, got %v", err)
+	}
+	if !pageLimitReached(err, "Netflix") {
+		t.Error("Expected page limit to be reported as reached")
+	}
+}
+
+func TestWaitPageLoadDelay(t *testing.T) {
+	ctx := withThrottle(context.Background(), config.ThrottleConfig{MinDelaySeconds: 0.05})
+
+	start := time.Now()
+	waitPageLoad(ctx)
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Expected first page load not to wait, waited %s", elapsed)
+	}
+	waitHistoryPage(ctx)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected second page load to wait for the minimum delay, waited %s", elapsed)
+	}
+}
+
+func TestWaitPageLoadUnthrottled(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		if err := waitHistoryPage(ctx); err != nil {
+			t.Fatalf("Expected no limit without a throttle, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+// earliestWatchedAt is the oldest watch time a scraped item may have. Dates
+// before it come from parsing bugs, such as a missing year read as year 0.
+var earliestWatchedAt = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// validateItem checks that a scraped item is safe to store: a title, a watch
+// time between 1990 and a day from now, a non-negative duration and a
+// service that exists. Items break this contract when a scraper misreads a
+// page, so they're rejected rather than stored as bad history.
+func validateItem(item database.WatchHistory, services map[int64]bool, now time.Time) error {
+	switch {
+	case strings.TrimSpace(item.Title) == "":
+		return fmt.Errorf("empty title")
+	case item.WatchedAt.Before(earliestWatchedAt):
+		return fmt.Errorf("watched_at %s is before %d", item.WatchedAt.Format(time.RFC3339), earliestWatchedAt.Year())
+	case item.WatchedAt.After(now.Add(24 * time.Hour)):
+		return fmt.Errorf("watched_at %s is in the future", item.WatchedAt.Format(time.RFC3339))
+	case item.DurationMinutes < 0:
+		return fmt.Errorf("negative duration %d", item.DurationMinutes)
+	case !services[item.ServiceID]:
+		return fmt.Errorf("unknown service ID %d", item.ServiceID)
+	}
+	return nil
+}
+
+// validateItems returns the scraped items that pass validateItem, logging
+// each one rejected, and how many were
+func (m *Manager) validateItems(items []database.WatchHistory) ([]database.WatchHistory, int, error) {
+	all, err := m.db.GetAllServices()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch services: %w", err)
+	}
+	services := make(map[int64]bool, len(all))
+	for _, svc := range all {
+		services[svc.ID] = true
+	}
+
+	now := time.Now()
+	valid := items[:0]
+	rejected := 0
+	for _, item := range items {
+		if err := validateItem(item, services, now); err != nil {
+			log.Printf("Rejected scraped item %q: %v", item.Title, err)
+			rejected++
+			continue
+		}
+		valid = append(valid, item)
+	}
+	return valid, rejected, nil
+}
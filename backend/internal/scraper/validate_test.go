@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestValidateItem(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	services := map[int64]bool{1: true}
+
+	tests := []struct {
+		name  string
+		item  database.WatchHistory
+		valid bool
+	}{
+		{"valid", database.WatchHistory{ServiceID: 1, Title: "Severance", WatchedAt: now, DurationMinutes: 50}, true},
+		{"later today in another time zone", database.WatchHistory{ServiceID: 1, Title: "Severance", WatchedAt: now.Add(12 * time.Hour)}, true},
+		{"blank title", database.WatchHistory{ServiceID: 1, Title: "  ", WatchedAt: now}, false},
+		{"year zero", database.WatchHistory{ServiceID: 1, Title: "Severance"}, false},
+		{"before 1990", database.WatchHistory{ServiceID: 1, Title: "Severance", WatchedAt: time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)}, false},
+		{"future", database.WatchHistory{ServiceID: 1, Title: "Severance", WatchedAt: now.AddDate(1, 0, 0)}, false},
+		{"negative duration", database.WatchHistory{ServiceID: 1, Title: "Severance", WatchedAt: now, DurationMinutes: -5}, false},
+		{"unknown service", database.WatchHistory{ServiceID: 2, Title: "Severance", WatchedAt: now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateItem(tt.item, services, now)
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestRunRejectsInvalidItems(t *testing.T) {
+	manager, db := setupTestManager(t)
+	defer db.Close()
+
+	service, _ := db.GetServiceByName("Netflix")
+	now := time.Now()
+	manager.Register(&MockScraper{name: "Netflix", items: []database.WatchHistory{
+		{ServiceID: service.ID, Title: "Good Show", DurationMinutes: 30, WatchedAt: now.Add(-time.Hour)},
+		{ServiceID: service.ID, Title: "Misparsed Date", DurationMinutes: 30, WatchedAt: now.AddDate(2, 0, 0)},
+		{ServiceID: service.ID, Title: "", DurationMinutes: 30, WatchedAt: now.Add(-time.Hour)},
+	}})
+
+	result, err := manager.RunWithOptions(context.Background(), "Netflix", RunOptions{Force: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.ItemsScraped != 1 || result.ItemsRejected != 2 {
+		t.Errorf("Expected 1 item stored and 2 rejected, got %d and %d", result.ItemsScraped, result.ItemsRejected)
+	}
+
+	history, err := db.GetWatchHistory(service.ID, now.AddDate(0, 0, -1), now.AddDate(3, 0, 0), 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to fetch history: %v", err)
+	}
+	if len(history) != 1 || history[0].Title != "Good Show" {
+		t.Errorf("Expected only the valid item to be stored, got %+v", history)
+	}
+
+	runs, err := db.GetLatestScraperRuns()
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Expected 1 scraper run, got %v (%v)", runs, err)
+	}
+	if runs[0].ItemsRejected != 2 {
+		t.Errorf("Expected the run to record 2 rejected items, got %d", runs[0].ItemsRejected)
+	}
+}
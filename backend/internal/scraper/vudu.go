@@ -0,0 +1,256 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
+)
+
+// vuduHistoryURL lists purchases and rentals with their last watched date
+const vuduHistoryURL = "https://www.vudu.com/content/account/history"
+
+// vuduMaxPages caps how many times "Load More" is clicked
+const vuduMaxPages = 100
+
+// VuduScraper implements the Scraper interface for Vudu (Fandango at Home)
+type VuduScraper struct {
+	config      *config.Config
+	db          *database.DB
+	serviceKey  string
+	instanceKey string // Key of this instance in the services config
+}
+
+// NewVuduScraper creates a new Vudu scraper
+func NewVuduScraper(cfg *config.Config, db *database.DB) *VuduScraper {
+	return &VuduScraper{
+		config:      cfg,
+		db:          db,
+		serviceKey:  "Vudu",
+		instanceKey: "vudu",
+	}
+}
+
+// Name returns the service name
+func (s *VuduScraper) Name() string {
+	return s.serviceKey
+}
+
+// Scrape fetches purchase and rental viewing history from Vudu
+func (s *VuduScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
+	// Get service config
+	serviceCfg, ok := s.config.Services[s.instanceKey]
+	if !ok || !serviceCfg.Enabled {
+		return nil, fmt.Errorf("%s not configured or not enabled", s.instanceKey)
+	}
+	serviceCfg.Cookies = serviceCookies(ctx, s.db, s.serviceKey, serviceCfg, "vudu.com")
+
+	chromeCtx, cancel := newChromeContext(ctx, s.config)
+	defer cancel()
+
+	// Load authentication cookies
+	if err := setCookies(chromeCtx, "https://www.vudu.com", ".vudu.com", serviceCfg.Cookies); err != nil {
+		return nil, fmt.Errorf("failed to load cookies: %w", err)
+	}
+
+	log.Printf("Navigating to Vudu history: %s", vuduHistoryURL)
+	if err := chromedp.Run(chromeCtx,
+		navigateHistory(vuduHistoryURL),
+		chromedp.WaitReady("body"),
+		chromedp.Sleep(3*time.Second),
+	); err != nil {
+		return nil, fmt.Errorf("navigation failed: %w", err)
+	}
+
+	// Page through history until we run out or reach data we already have
+	if err := s.loadMoreHistory(chromeCtx); err != nil {
+		return nil, fmt.Errorf("pagination failed: %w", err)
+	}
+
+	snapshotDOM(chromeCtx)
+	rows, err := s.readRows(chromeCtx)
+	if err != nil {
+		return nil, fmt.Errorf("extraction failed: %w", err)
+	}
+	recordHistoryRows(chromeCtx, rows, `[data-testid="history-item"]`, `[data-testid="title"]`,
+		`[data-testid="watched-date"]`, `[data-testid="runtime"]`)
+
+	var items []database.WatchHistory
+	for _, row := range rows {
+		item, err := s.rowToWatchHistory(row)
+		if err != nil {
+			log.Printf("Skipping Vudu row '%s': %v", row.Title, err)
+			continue
+		}
+		items = append(items, item)
+
+		if limit := itemLimit(ctx, s.config); limit > 0 && len(items) >= limit {
+			log.Printf("Test mode: stopping at %d items", limit)
+			break
+		}
+	}
+
+	log.Printf("Vudu scraper extracted %d items", len(items))
+	return items, nil
+}
+
+// CheckAuth loads the history page to see whether the cookies are still signed in
+func (s *VuduScraper) CheckAuth(ctx context.Context) (*AuthStatus, error) {
+	serviceCfg, err := serviceConfigFor(ctx, s.config, s.db, s.instanceKey, s.serviceKey, "vudu.com")
+	if err != nil {
+		return nil, err
+	}
+
+	loadCookies := func(ctx context.Context, cookies []config.Cookie) error {
+		return setCookies(ctx, "https://www.vudu.com", ".vudu.com", cookies)
+	}
+	return checkAuth(ctx, s.config, s.serviceKey, serviceCfg, loadCookies, authPage{accountURL: vuduHistoryURL})
+}
+
+// loadMoreHistory clicks "Load More" until no more pages load, the oldest
+// loaded row is already in the database, or vuduMaxPages is reached
+func (s *VuduScraper) loadMoreHistory(ctx context.Context) error {
+	// Look up this instance's service so existing entries can be detected
+	var serviceID int64
+	if service, err := s.db.GetServiceByName(s.serviceKey); err == nil && service != nil {
+		serviceID = service.ID
+	}
+
+	previousCount := 0
+	for page := 1; page <= vuduMaxPages; page++ {
+		var currentCount int
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(`document.querySelectorAll('[data-testid="history-item"]').length`, &currentCount),
+		); err != nil {
+			return fmt.Errorf("failed to count history items: %w", err)
+		}
+
+		if currentCount == previousCount && page > 1 {
+			log.Printf("No new history items after page %d. Total items: %d", page-1, currentCount)
+			return nil
+		}
+		previousCount = currentCount
+
+		if limit := itemLimit(ctx, s.config); limit > 0 && currentCount >= limit {
+			return nil
+		}
+
+		// Stop once the oldest loaded row is past the run's lookback or already stored
+		rows, err := s.readRows(ctx)
+		if err == nil && len(rows) > 0 && serviceID != 0 {
+			if last, err := s.rowToWatchHistory(rows[len(rows)-1]); err == nil {
+				if since := Lookback(ctx); !since.IsZero() && last.WatchedAt.Before(since) {
+					log.Printf("Reached lookback %s on page %d. Stopping pagination. Total items: %d",
+						formatSince(since), page, currentCount)
+					return nil
+				}
+				exists, checkErr := historyDB(ctx, s.db).WatchHistoryExists(serviceID, last.Title, last.EpisodeInfo, last.WatchedAt)
+				if checkErr == nil && exists && !IsRefresh(ctx) {
+					log.Printf("Found existing entry '%s' on page %d. Stopping pagination. Total items: %d",
+						last.Title, page, currentCount)
+					return nil
+				}
+			}
+		}
+
+		var hasMore bool
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(`document.querySelector('button[data-testid="load-more"]') !== null`, &hasMore),
+		); err != nil || !hasMore {
+			log.Printf("No Load More button on page %d. Total items: %d", page, currentCount)
+			return nil
+		}
+
+		if err := chromedp.Run(ctx,
+			chromedp.Click(`button[data-testid="load-more"]`, chromedp.ByQuery),
+			chromedp.Sleep(2*time.Second), // Wait for the next page to load
+		); err != nil {
+			return fmt.Errorf("failed to load page %d: %w", page+1, err)
+		}
+	}
+
+	log.Printf("Reached page limit of %d", vuduMaxPages)
+	return nil
+}
+
+// readRows reads every loaded history row, taking the runtime from the detail
+// metadata embedded in each row rather than opening every title's page
+func (s *VuduScraper) readRows(ctx context.Context) ([]historyRow, error) {
+	var raw string
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(`
+			JSON.stringify(Array.from(document.querySelectorAll('[data-testid="history-item"]')).map(row => {
+				const text = sel => {
+					const el = row.querySelector(sel);
+					return el ? el.textContent.trim() : '';
+				};
+				let runtime = row.getAttribute('data-runtime') || text('[data-testid="runtime"]');
+				const meta = row.querySelector('script[type="application/ld+json"]');
+				if (!runtime && meta) {
+					try { runtime = JSON.parse(meta.textContent).duration || ''; } catch (e) {}
+				}
+				return {
+					title: text('[data-testid="title"]'),
+					episode: text('[data-testid="episode"]'),
+					date: text('[data-testid="watched-date"]'),
+					runtime: runtime,
+					html: row.outerHTML.slice(0, `+strconv.Itoa(rawHTMLLimit)+`),
+				};
+			}))
+		`, &raw),
+	); err != nil {
+		return nil, err
+	}
+
+	var rows []historyRow
+	if err := json.Unmarshal([]byte(raw), &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode history rows: %w", err)
+	}
+	return rows, nil
+}
+
+// rowToWatchHistory converts a history row to a watch history entry
+func (s *VuduScraper) rowToWatchHistory(row historyRow) (database.WatchHistory, error) {
+	title := strings.TrimSpace(row.Title)
+	if title == "" {
+		return database.WatchHistory{}, fmt.Errorf("missing title")
+	}
+
+	watchedAt, err := parseHistoryDate(row.Date)
+	if err != nil {
+		return database.WatchHistory{}, err
+	}
+
+	episodeInfo := strings.TrimSpace(row.Episode)
+	duration := parseRuntime(row.Runtime)
+	if duration == 0 {
+		duration = s.estimateDuration(title, episodeInfo)
+	}
+
+	return database.WatchHistory{
+		Title:           title,
+		EpisodeInfo:     episodeInfo,
+		DurationMinutes: duration,
+		WatchedAt:       watchedAt,
+		Raw:             row.payload(time.Now()),
+		Created:         time.Now(),
+	}, nil
+}
+
+// Reparse re-derives an entry from the raw payload stored when it was scraped
+func (s *VuduScraper) Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error) {
+	return s.rowToWatchHistory(rowFromPayload(raw))
+}
+
+// estimateDuration is used when a title has no runtime metadata
+func (s *VuduScraper) estimateDuration(title, episodeInfo string) int {
+	return durationEstimator(s.config, s.instanceKey, s.serviceKey, estimate.StrategyStandard, estimate.Vudu).Estimate(title, episodeInfo)
+}
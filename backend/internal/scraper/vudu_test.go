@@ -0,0 +1,62 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestVuduScraperName(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewVuduScraper(cfg, db)
+
+	if scraper.Name() != "Vudu" {
+		t.Errorf("Expected name 'Vudu', got '%s'", scraper.Name())
+	}
+}
+
+func TestVuduRowToWatchHistory(t *testing.T) {
+	cfg := &config.Config{}
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewVuduScraper(cfg, db)
+
+	tests := []struct {
+		name     string
+		row      historyRow
+		date     string
+		duration int
+		wantErr  bool
+	}{
+		{"movie with runtime", historyRow{Title: "Heat", Date: "Watched Jan 5, 2025", Runtime: "PT2H50M"}, "2025-01-05", 170, false},
+		{"movie without runtime", historyRow{Title: "Heat", Date: "01/05/2025"}, "2025-01-05", 110, false},
+		{"episode without runtime", historyRow{Title: "Fargo", Episode: "S1 E1", Date: "2025-01-05"}, "2025-01-05", 45, false},
+		{"missing title", historyRow{Date: "2025-01-05"}, "", 0, true},
+		{"bad date", historyRow{Title: "Heat", Date: "last week"}, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := scraper.rowToWatchHistory(tt.row)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if item.WatchedAt.Format("2006-01-02") != tt.date {
+				t.Errorf("Expected date %s, got %s", tt.date, item.WatchedAt.Format("2006-01-02"))
+			}
+			if item.DurationMinutes != tt.duration {
+				t.Errorf("Expected %d minutes, got %d", tt.duration, item.DurationMinutes)
+			}
+		})
+	}
+}
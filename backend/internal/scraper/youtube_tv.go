@@ -7,20 +7,31 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 	"github.com/jgoulah/streamtime/internal/config"
 	"github.com/jgoulah/streamtime/internal/database"
+	"github.com/jgoulah/streamtime/internal/estimate"
 )
 
+// youTubePlatform labels plain YouTube videos on Google My Activity, which
+// are recorded under the service of the same name
+const youTubePlatform = "YouTube"
+
+// youTubeVideoEstimate estimates plain YouTube videos, which are far shorter
+// than YouTube TV's broadcast slots
+var youTubeVideoEstimate, _ = estimate.New(estimate.StrategyStandard, estimate.YouTube)
+
 // YouTubeTVScraper implements the Scraper interface for YouTube TV
 type YouTubeTVScraper struct {
 	config         *config.Config
 	db             *database.DB
 	serviceKey     string
+	instanceKey    string           // Key of this instance in the services config
+	serviceIDMu    sync.Mutex       // Guards serviceIDCache, for reprocessing alongside a run
 	serviceIDCache map[string]int64 // Cache service IDs to avoid repeated DB queries
 }
 
@@ -30,6 +41,7 @@ func NewYouTubeTVScraper(cfg *config.Config, db *database.DB) *YouTubeTVScraper
 		config:         cfg,
 		db:             db,
 		serviceKey:     "YouTube TV",
+		instanceKey:    "youtube_tv",
 		serviceIDCache: make(map[string]int64),
 	}
 }
@@ -42,10 +54,11 @@ func (s *YouTubeTVScraper) Name() string {
 // Scrape fetches viewing history from YouTube TV
 func (s *YouTubeTVScraper) Scrape(ctx context.Context) ([]database.WatchHistory, error) {
 	// Get service config
-	serviceCfg, ok := s.config.Services["youtube_tv"]
+	serviceCfg, ok := s.config.Services[s.instanceKey]
 	if !ok || !serviceCfg.Enabled {
-		return nil, fmt.Errorf("youtube_tv not configured or not enabled")
+		return nil, fmt.Errorf("%s not configured or not enabled", s.instanceKey)
 	}
+	serviceCfg.Cookies = serviceCookies(ctx, s.db, s.serviceKey, serviceCfg, "google.com")
 
 	// Create chrome context with timeout
 	timeout := time.Duration(s.config.Scraper.Timeout) * time.Second
@@ -86,11 +99,24 @@ func (s *YouTubeTVScraper) Scrape(ctx context.Context) ([]database.WatchHistory,
 	return items, nil
 }
 
+// CheckAuth loads My Activity to see whether the Google cookies are still signed in
+func (s *YouTubeTVScraper) CheckAuth(ctx context.Context) (*AuthStatus, error) {
+	serviceCfg, err := serviceConfigFor(ctx, s.config, s.db, s.instanceKey, s.serviceKey, "google.com")
+	if err != nil {
+		return nil, err
+	}
+
+	return checkAuth(ctx, s.config, s.serviceKey, serviceCfg, s.loadCookies, authPage{
+		accountURL:   "https://myactivity.google.com/product/youtube",
+		loginMarkers: []string{"accounts.google.com", "servicelogin", "signin"},
+	})
+}
+
 // loadCookies loads authentication cookies into the browser
 func (s *YouTubeTVScraper) loadCookies(ctx context.Context, cookies []config.Cookie) error {
 	// First navigate to myactivity.google.com so cookies can be set
 	if err := chromedp.Run(ctx,
-		chromedp.Navigate("https://myactivity.google.com"),
+		navigate("https://myactivity.google.com"),
 		chromedp.Sleep(2*time.Second),
 	); err != nil {
 		return err
@@ -100,16 +126,18 @@ func (s *YouTubeTVScraper) loadCookies(ctx context.Context, cookies []config.Coo
 	domains := []string{".google.com", ".accounts.google.com"}
 
 	cookiesSet := 0
-	for _, domain := range domains {
-		for _, cookie := range cookies {
-			// APISID cookies should not be HTTPOnly
-			httpOnly := !strings.Contains(cookie.Name, "APISID")
+	for _, cookie := range cookies {
+		// Cookies exported with a domain only go on that domain
+		cookieDomains := domains
+		if cookie.Domain != "" {
+			cookieDomains = []string{cookie.Domain}
+		}
 
-			expr := network.SetCookie(cookie.Name, cookie.Value).
-				WithDomain(domain).
-				WithPath("/").
-				WithSecure(true).
-				WithHTTPOnly(httpOnly)
+		// APISID cookies should not be HTTPOnly
+		httpOnly := !strings.Contains(cookie.Name, "APISID")
+
+		for _, domain := range cookieDomains {
+			expr := setCookieParams(cookie, cookieDefaults{domain: domain, httpOnly: httpOnly})
 
 			if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
 				return expr.Do(ctx)
@@ -134,7 +162,7 @@ func (s *YouTubeTVScraper) navigateToHistory(ctx context.Context) error {
 	var url string
 	var bodyText string
 	err := chromedp.Run(ctx,
-		chromedp.Navigate("https://myactivity.google.com/product/youtube"),
+		navigateHistory("https://myactivity.google.com/product/youtube"),
 		chromedp.Sleep(5*time.Second), // Wait for page to load
 		chromedp.Title(&pageTitle),
 		chromedp.Location(&url),
@@ -226,8 +254,8 @@ func (s *YouTubeTVScraper) scrollToLoadItems(ctx context.Context) chromedp.Actio
 		maxClicks := 200 // Safety limit for full history
 
 		// Check if test mode is enabled
-		testMode := s.config.Scraper.TestMode
-		testLimit := s.config.Scraper.TestLimit
+		testLimit := itemLimit(ctx, s.config)
+		testMode := testLimit > 0
 		if testMode {
 			log.Printf("Test mode enabled - will stop after %d items", testLimit)
 		}
@@ -288,9 +316,13 @@ func (s *YouTubeTVScraper) scrollToLoadItems(ctx context.Context) chromedp.Actio
 				`, &lastTitle).Do(ctx)
 
 				if lastTitle != "" {
-					lastDate, err := s.parseDate(lastDateText)
-					if err == nil {
-						exists, _ := s.db.WatchHistoryExists(service.ID, lastTitle, "", lastDate)
+					lastDate, err := s.parseDate(lastDateText, time.Now())
+					if since := Lookback(ctx); err == nil && !since.IsZero() && lastDate.Before(since) {
+						log.Printf("Reached lookback %s, stopping pagination", formatSince(since))
+						break
+					}
+					if err == nil && !IsRefresh(ctx) {
+						exists, _ := historyDB(ctx, s.db).WatchHistoryExists(service.ID, lastTitle, "", lastDate)
 						if exists {
 							log.Println("Found existing entry in database, stopping pagination")
 							break
@@ -318,6 +350,7 @@ func (s *YouTubeTVScraper) scrollToLoadItems(ctx context.Context) chromedp.Actio
 // extractViewingHistory extracts all viewing history items from the page
 func (s *YouTubeTVScraper) extractViewingHistory(ctx context.Context) ([]database.WatchHistory, error) {
 	var items []database.WatchHistory
+	snapshotDOM(ctx)
 
 	// Get all activity items from Google My Activity
 	var nodes []*cdp.Node
@@ -326,6 +359,7 @@ func (s *YouTubeTVScraper) extractViewingHistory(ctx context.Context) ([]databas
 	); err != nil {
 		return nil, err
 	}
+	RecordSelector(ctx, `div[jsname="MFYZYe"]`, len(nodes))
 
 	log.Printf("Found %d activity items to extract", len(nodes))
 
@@ -337,7 +371,7 @@ func (s *YouTubeTVScraper) extractViewingHistory(ctx context.Context) ([]databas
 			continue
 		}
 
-		if item != nil {
+		if item != nil && s.keep(item) {
 			items = append(items, *item)
 		}
 	}
@@ -345,13 +379,24 @@ func (s *YouTubeTVScraper) extractViewingHistory(ctx context.Context) ([]databas
 	return items, nil
 }
 
+// keep reports whether an item should be recorded: plain YouTube videos only
+// when the instance includes them, and nothing shorter than min_minutes
+func (s *YouTubeTVScraper) keep(item *database.WatchHistory) bool {
+	svc := s.config.Services[s.instanceKey]
+	if item.Raw != nil && strings.TrimSpace(item.Raw.Platform) == youTubePlatform && !svc.IncludesYouTube() {
+		return false
+	}
+	return item.DurationMinutes >= svc.MinMinutes
+}
+
 // extractHistoryItem extracts data from a single Google My Activity item
 func (s *YouTubeTVScraper) extractHistoryItem(ctx context.Context, node *cdp.Node, itemIndex int) (*database.WatchHistory, error) {
-	var title, timeText, dateHeader, platformLabel string
+	var title, link, timeText, dateHeader, platformLabel string
 
-	// Extract the show title from the link (a.l8sGWb)
+	// Extract the show title and where it links (a.l8sGWb)
 	chromedp.Run(ctx,
 		chromedp.Text("a.l8sGWb", &title, chromedp.ByQuery, chromedp.FromNode(node)),
+		chromedp.AttributeValue("a.l8sGWb", "href", &link, nil, chromedp.ByQuery, chromedp.FromNode(node)),
 	)
 
 	// Extract the platform label to distinguish YouTube vs YouTube TV
@@ -394,39 +439,63 @@ func (s *YouTubeTVScraper) extractHistoryItem(ctx context.Context, node *cdp.Nod
 		`, itemIndex, itemIndex), &dateHeader),
 	)
 
+	RecordSelector(ctx, "a.l8sGWb", boolHit(title != ""))
+	RecordSelector(ctx, "span.hJ7x8b", boolHit(platformLabel != ""))
+	RecordSelector(ctx, "div.wlgrwd", boolHit(timeText != ""))
+	RecordSelector(ctx, ".rp10kf", boolHit(dateHeader != ""))
+
+	return s.parseRaw(database.RawPayload{
+		Title:     title,
+		Date:      dateHeader,
+		Time:      timeText,
+		Platform:  platformLabel,
+		URL:       link,
+		HTML:      nodeHTML(ctx, node),
+		ScrapedAt: time.Now(),
+	})
+}
+
+// Reparse re-derives an entry from the raw payload stored when it was
+// scraped, resolving relative dates against the original scrape time
+func (s *YouTubeTVScraper) Reparse(ctx context.Context, raw database.RawPayload) (database.WatchHistory, error) {
+	item, err := s.parseRaw(raw)
+	if err != nil {
+		return database.WatchHistory{}, err
+	}
+	return *item, nil
+}
+
+// parseRaw parses the title, platform label, date header and time read from
+// a My Activity item
+func (s *YouTubeTVScraper) parseRaw(raw database.RawPayload) (*database.WatchHistory, error) {
+	title, timeText, dateHeader := raw.Title, raw.Time, raw.Date
+
 	// Skip items that don't have a title (these are likely category headers or UI elements)
 	if title == "" {
 		return nil, fmt.Errorf("missing title")
 	}
 
 	// Determine which service this belongs to based on platform label
-	platformLabel = strings.TrimSpace(platformLabel)
+	platformLabel := strings.TrimSpace(raw.Platform)
 	var serviceName string
 	if platformLabel == "YouTube TV" {
-		serviceName = "YouTube TV"
-	} else if platformLabel == "YouTube" {
-		serviceName = "YouTube"
+		serviceName = s.serviceKey
+	} else if platformLabel == youTubePlatform {
+		serviceName = youTubePlatform
 	} else {
 		// Unknown platform, skip
 		return nil, fmt.Errorf("unknown platform: %s", platformLabel)
 	}
 
-	// Look up service ID (with caching)
-	serviceID, ok := s.serviceIDCache[serviceName]
-	if !ok {
-		// Not in cache, query database
-		service, err := s.db.GetServiceByName(serviceName)
-		if err != nil || service == nil {
-			return nil, fmt.Errorf("service not found: %s", serviceName)
-		}
-		serviceID = service.ID
-		s.serviceIDCache[serviceName] = serviceID
+	serviceID, err := s.serviceID(serviceName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Combine date header and time to get full timestamp
 	// dateHeader is like "Yesterday", "Oct 27", etc.
 	// timeText is like "6:00 PM • Details"
-	watchedAt := time.Now() // Default to now if we can't parse
+	watchedAt := raw.ScrapedAt // Default to the scrape time if we can't parse
 
 	// Extract just the time part (before the bullet)
 	timePart := timeText
@@ -435,7 +504,7 @@ func (s *YouTubeTVScraper) extractHistoryItem(ctx context.Context, node *cdp.Nod
 	}
 
 	if dateHeader != "" && timePart != "" {
-		parsed, err := s.parseDateAndTime(dateHeader, timePart)
+		parsed, err := s.parseDateAndTime(dateHeader, timePart, raw.ScrapedAt)
 		if err == nil {
 			watchedAt = parsed
 		} else {
@@ -448,6 +517,7 @@ func (s *YouTubeTVScraper) extractHistoryItem(ctx context.Context, node *cdp.Nod
 		ServiceID: serviceID, // Set the correct service ID based on platform
 		Title:     strings.TrimSpace(title),
 		WatchedAt: watchedAt,
+		Raw:       &raw,
 	}
 
 	// Store the platform label as episode info for reference
@@ -456,11 +526,35 @@ func (s *YouTubeTVScraper) extractHistoryItem(ctx context.Context, node *cdp.Nod
 	}
 
 	// YouTube doesn't provide duration in history, default to estimate
-	item.DurationMinutes = s.estimateDuration(title, "")
+	switch {
+	case strings.Contains(raw.URL, "/shorts/"):
+		item.DurationMinutes = 1 // Shorts run a minute at most
+	case platformLabel == youTubePlatform:
+		item.DurationMinutes = youTubeVideoEstimate.Estimate(title, "")
+	default:
+		item.DurationMinutes = s.estimateDuration(title, "")
+	}
 
 	return item, nil
 }
 
+// serviceID looks up the ID of a service an item belongs to, caching it to
+// avoid repeated DB queries
+func (s *YouTubeTVScraper) serviceID(serviceName string) (int64, error) {
+	s.serviceIDMu.Lock()
+	defer s.serviceIDMu.Unlock()
+
+	if id, ok := s.serviceIDCache[serviceName]; ok {
+		return id, nil
+	}
+	service, err := s.db.GetServiceByName(serviceName)
+	if err != nil || service == nil {
+		return 0, fmt.Errorf("service not found: %s", serviceName)
+	}
+	s.serviceIDCache[serviceName] = service.ID
+	return service.ID, nil
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -468,10 +562,9 @@ func min(a, b int) int {
 	return b
 }
 
-// parseDateAndTime combines date header and time to create timestamp
-func (s *YouTubeTVScraper) parseDateAndTime(dateHeader, timeStr string) (time.Time, error) {
-	now := time.Now()
-
+// parseDateAndTime combines date header and time to create timestamp, with
+// relative headers like "Yesterday" resolved against now
+func (s *YouTubeTVScraper) parseDateAndTime(dateHeader, timeStr string, now time.Time) (time.Time, error) {
 	// Normalize Unicode spaces (Google uses narrow no-break space U+202F)
 	// Replace it with regular space for easier parsing
 	timeStr = strings.ReplaceAll(timeStr, "\u202F", " ")
@@ -535,12 +628,7 @@ func (s *YouTubeTVScraper) parseDateAndTime(dateHeader, timeStr string) (time.Ti
 			return time.Time{}, fmt.Errorf("unknown month: %s", monthStr)
 		}
 
-		// Determine year (if date is in future, it's from last year)
-		year := now.Year()
-		testDate := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
-		if testDate.After(now) {
-			year--
-		}
+		year := inferYear(month, day, now.In(time.Local))
 
 		return time.Date(year, month, day, hour, minute, 0, 0, time.Local), nil
 	}
@@ -548,13 +636,12 @@ func (s *YouTubeTVScraper) parseDateAndTime(dateHeader, timeStr string) (time.Ti
 	return time.Time{}, fmt.Errorf("unable to parse date header: %s", dateHeader)
 }
 
-// parseDate parses the date string from YouTube TV history (legacy, kept for compatibility)
-func (s *YouTubeTVScraper) parseDate(dateStr string) (time.Time, error) {
+// parseDate parses the date string from YouTube TV history (legacy, kept for
+// compatibility), with relative dates and years resolved against now
+func (s *YouTubeTVScraper) parseDate(dateStr string, now time.Time) (time.Time, error) {
 	// YouTube TV shows dates like "Dec 25 at 8:00 PM" or "Yesterday at 8:00 PM"
 	// We'll parse different formats
 
-	now := time.Now()
-
 	// Handle "Yesterday"
 	if strings.Contains(dateStr, "Yesterday") {
 		yesterday := now.AddDate(0, 0, -1)
@@ -615,12 +702,7 @@ func (s *YouTubeTVScraper) parseDate(dateStr string) (time.Time, error) {
 			return time.Time{}, fmt.Errorf("unknown month: %s", monthStr)
 		}
 
-		// Determine year (if date is in future, it's from last year)
-		year := now.Year()
-		testDate := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
-		if testDate.After(now) {
-			year--
-		}
+		year := inferYear(month, day, now.In(time.Local))
 
 		hour, minute := 20, 0 // Default time
 		if len(timeMatches) == 4 {
@@ -640,15 +722,8 @@ func (s *YouTubeTVScraper) parseDate(dateStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
 }
 
-// estimateDuration estimates the duration based on title and episode info
+// estimateDuration estimates the duration based on title and episode info,
+// recognizing live news, talk and sports broadcasts by default
 func (s *YouTubeTVScraper) estimateDuration(title, episodeInfo string) int {
-	// Default estimates
-	if episodeInfo != "" {
-		// TV show episode: typically 30-60 minutes
-		return 45
-	}
-
-	// Movies are typically 90-120 minutes
-	// For now, default to 60 minutes
-	return 60
+	return durationEstimator(s.config, s.instanceKey, s.serviceKey, estimate.StrategyLiveTV, estimate.YouTubeTV).Estimate(title, episodeInfo)
 }
@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgoulah/streamtime/internal/config"
+	"github.com/jgoulah/streamtime/internal/database"
+)
+
+func TestYouTubeTVParseRawSplitsPlatforms(t *testing.T) {
+	db, _ := database.New(":memory:")
+	defer db.Close()
+	scraper := NewYouTubeTVScraper(&config.Config{}, db)
+	scrapedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		raw      database.RawPayload
+		service  string
+		duration int
+	}{
+		{"YouTube TV broadcast", database.RawPayload{Title: "NBC Nightly News", Platform: "YouTube TV"}, "YouTube TV", 30},
+		{"YouTube video", database.RawPayload{Title: "How to Sharpen a Knife", Platform: "YouTube", URL: "https://www.youtube.com/watch?v=abc"}, "YouTube", 12},
+		{"YouTube Short", database.RawPayload{Title: "Knife trick", Platform: "YouTube", URL: "https://www.youtube.com/shorts/xyz"}, "YouTube", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.raw.Date, tt.raw.Time, tt.raw.ScrapedAt = "Yesterday", "6:00 PM • Details", scrapedAt
+			item, err := scraper.parseRaw(tt.raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			service, _ := db.GetServiceByID(item.ServiceID)
+			if service == nil || service.Name != tt.service {
+				t.Errorf("Expected service %s, got %+v", tt.service, service)
+			}
+			if item.DurationMinutes != tt.duration {
+				t.Errorf("Expected %d minutes, got %d", tt.duration, item.DurationMinutes)
+			}
+		})
+	}
+}
+
+func TestYouTubeTVKeep(t *testing.T) {
+	db, _ := database.New(":memory:")
+	defer db.Close()
+
+	exclude := false
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"youtube_tv": {Enabled: true, IncludeYouTube: &exclude, MinMinutes: 2},
+	}}
+	scraper := NewYouTubeTVScraper(cfg, db)
+
+	tv := &database.WatchHistory{DurationMinutes: 30, Raw: &database.RawPayload{Platform: "YouTube TV"}}
+	video := &database.WatchHistory{DurationMinutes: 12, Raw: &database.RawPayload{Platform: "YouTube"}}
+	short := &database.WatchHistory{DurationMinutes: 1, Raw: &database.RawPayload{Platform: "YouTube TV"}}
+	if !scraper.keep(tv) || scraper.keep(video) || scraper.keep(short) {
+		t.Error("Expected only the YouTube TV item long enough to be kept")
+	}
+
+	cfg.Services["youtube_tv"] = config.ServiceConfig{Enabled: true}
+	if !scraper.keep(video) || !scraper.keep(short) {
+		t.Error("Expected plain YouTube and short items kept by default")
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jgoulah/streamtime/internal/config"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// Store keeps large artifacts, such as the page snapshots of failed scraper
+// runs, outside the database. Keys are slash-separated paths like
+// "scraper-runs/42/dom.html".
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Storage types accepted in config
+const (
+	TypeLocal = "local"
+	TypeS3    = "s3"
+)
+
+// New creates the store selected in config, or returns nil when artifacts
+// are kept in the database
+func New(cfg config.StorageConfig) (Store, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case TypeLocal:
+		return NewLocal(cfg.Path), nil
+	case TypeS3:
+		return NewS3(cfg.S3), nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", cfg.Type)
+	}
+}
+
+// cleanKey validates a key, rejecting ones that would escape the store
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(key, "/"))
+	if key == "" || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,57 @@
+package tmdb
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// cacheTTL is how long a cached response is used before it's fetched again
+const cacheTTL = 30 * 24 * time.Hour
+
+// moviePath matches movie detail lookups, whose runtimes don't change
+var moviePath = regexp.MustCompile(`^/movie/\d+$`)
+
+// Cache stores API responses so the same title isn't looked up again, such
+// as when importing a long history that repeats shows. The database
+// implements it.
+type Cache interface {
+	// GetTMDBResponse returns a response cached since a time, if any
+	GetTMDBResponse(request, title string, since time.Time) (string, bool, error)
+	SetTMDBResponse(request, title, response string) error
+}
+
+// SetCache makes the client reuse responses from a cache. Title searches are
+// keyed on the normalized title, so "The Office" and "the  office" share an
+// entry, and movie details on the movie's ID. Other responses, such as a
+// show's airing episodes, change too often to cache.
+func (c *Client) SetCache(cache Cache) {
+	c.cache = cache
+}
+
+// cacheKey returns the request and normalized title a response is cached
+// under, or false if it shouldn't be cached
+func cacheKey(path string, params url.Values) (string, string, bool) {
+	if !strings.HasPrefix(path, "/search/") && !moviePath.MatchString(path) {
+		return "", "", false
+	}
+
+	title := NormalizeTitle(params.Get("query"))
+	rest := url.Values{}
+	for key, values := range params {
+		if key != "query" && key != "api_key" {
+			rest[key] = values
+		}
+	}
+	request := path
+	if len(rest) > 0 {
+		request += "?" + rest.Encode()
+	}
+	return request, title, true
+}
+
+// NormalizeTitle lowercases a title and collapses its whitespace
+func NormalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
@@ -0,0 +1,433 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBaseURL is The Movie Database v3 API
+const defaultBaseURL = "https://api.themoviedb.org/3"
+
+// maxRetries is how many times a rate-limited request is retried
+const maxRetries = 3
+
+// Client is a minimal TMDB API client. Requests are rate limited, retried
+// with backoff when TMDB still answers 429 Too Many Requests, and optionally
+// cached.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	limiter    *rateLimiter
+	retryDelay time.Duration // First backoff after a 429 without Retry-After, doubling each retry
+	cache      Cache         // nil when responses aren't cached
+}
+
+// Movie is a movie as returned by TMDB
+type Movie struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+	Runtime     int    `json:"runtime"` // Minutes; only set by GetMovie
+}
+
+// MediaItem is a movie or TV show in TMDB list results
+type MediaItem struct {
+	ID        int64  `json:"id"`
+	MediaType string `json:"media_type"` // "movie" or "tv"
+	Title     string `json:"title"`      // Set for movies
+	Name      string `json:"name"`       // Set for TV shows
+	Overview  string `json:"overview"`
+}
+
+// Episode is a TV episode as returned by TMDB
+type Episode struct {
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	Name          string `json:"name"`
+	AirDate       string `json:"air_date"` // YYYY-MM-DD; empty until a date is announced
+}
+
+// DisplayTitle returns the item's title for either media type
+func (t MediaItem) DisplayTitle() string {
+	if t.Title != "" {
+		return t.Title
+	}
+	return t.Name
+}
+
+// NewClient creates a TMDB client using a v3 API key
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:     apiKey,
+		baseURL:    defaultBaseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		limiter:    sharedLimiter,
+		retryDelay: time.Second,
+	}
+}
+
+// NewClientWithBaseURL creates a TMDB client that talks to a different API
+// root, such as a caching proxy or a test server
+func NewClientWithBaseURL(apiKey, baseURL string) *Client {
+	c := NewClient(apiKey)
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	return c
+}
+
+// SearchMovie returns the best match for a title, optionally narrowed by
+// release year (0 for any), or nil if nothing matches
+func (c *Client) SearchMovie(ctx context.Context, title string, year int) (*Movie, error) {
+	params := url.Values{}
+	params.Set("query", title)
+	if year > 0 {
+		params.Set("year", strconv.Itoa(year))
+	}
+
+	var result struct {
+		Results []Movie `json:"results"`
+	}
+	if err := c.get(ctx, "/search/movie", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Results) == 0 {
+		return nil, nil
+	}
+
+	// Prefer an exact title match over TMDB's popularity ordering
+	for i, movie := range result.Results {
+		if strings.EqualFold(movie.Title, title) {
+			return &result.Results[i], nil
+		}
+	}
+	return &result.Results[0], nil
+}
+
+// GetMovie returns the details for a movie, including its runtime
+func (c *Client) GetMovie(ctx context.Context, id int64) (*Movie, error) {
+	var movie Movie
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d", id), url.Values{}, &movie); err != nil {
+		return nil, err
+	}
+	return &movie, nil
+}
+
+// MovieRuntime looks up a film's runtime in minutes, returning 0 if no match is found
+func (c *Client) MovieRuntime(ctx context.Context, title string, year int) (int, error) {
+	match, err := c.SearchMovie(ctx, title, year)
+	if err != nil || match == nil {
+		return 0, err
+	}
+
+	movie, err := c.GetMovie(ctx, match.ID)
+	if err != nil {
+		return 0, err
+	}
+	return movie.Runtime, nil
+}
+
+// Trending returns the trending movies and TV shows for a window ("day" or
+// "week"), most popular first
+func (c *Client) Trending(ctx context.Context, window string) ([]MediaItem, error) {
+	var result struct {
+		Results []MediaItem `json:"results"`
+	}
+	if err := c.get(ctx, "/trending/all/"+window, url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	// The "all" list can include people
+	items := []MediaItem{}
+	for _, item := range result.Results {
+		if item.MediaType == "movie" || item.MediaType == "tv" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// SearchMulti returns the best movie or TV match for a title, or nil if nothing matches
+func (c *Client) SearchMulti(ctx context.Context, query string) (*MediaItem, error) {
+	params := url.Values{}
+	params.Set("query", query)
+
+	var result struct {
+		Results []MediaItem `json:"results"`
+	}
+	if err := c.get(ctx, "/search/multi", params, &result); err != nil {
+		return nil, err
+	}
+
+	var first *MediaItem
+	for i, item := range result.Results {
+		if item.MediaType != "movie" && item.MediaType != "tv" {
+			continue
+		}
+		if strings.EqualFold(item.DisplayTitle(), query) {
+			return &result.Results[i], nil
+		}
+		if first == nil {
+			first = &result.Results[i]
+		}
+	}
+	return first, nil
+}
+
+// Recommendations returns TMDB's recommendations for a movie or TV show
+func (c *Client) Recommendations(ctx context.Context, mediaType string, id int64) ([]MediaItem, error) {
+	var result struct {
+		Results []MediaItem `json:"results"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d/recommendations", mediaType, id), url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	// Results from these endpoints may omit media_type
+	for i := range result.Results {
+		if result.Results[i].MediaType == "" {
+			result.Results[i].MediaType = mediaType
+		}
+	}
+	return result.Results, nil
+}
+
+// WatchProviders returns the subscription (flat rate) providers for a movie or
+// TV show in a region, e.g. "Netflix" or "Amazon Prime Video"
+func (c *Client) WatchProviders(ctx context.Context, mediaType string, id int64, region string) ([]string, error) {
+	var result struct {
+		Results map[string]struct {
+			Flatrate []struct {
+				ProviderName string `json:"provider_name"`
+			} `json:"flatrate"`
+		} `json:"results"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d/watch/providers", mediaType, id), url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	providers := []string{}
+	for _, p := range result.Results[region].Flatrate {
+		providers = append(providers, p.ProviderName)
+	}
+	return providers, nil
+}
+
+// Producers returns the networks and production companies behind a movie or
+// TV show, e.g. ["Netflix", "Left Bank Pictures"]
+func (c *Client) Producers(ctx context.Context, mediaType string, id int64) ([]string, error) {
+	type company struct {
+		Name string `json:"name"`
+	}
+	var result struct {
+		Networks            []company `json:"networks"` // TV only
+		ProductionCompanies []company `json:"production_companies"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d", mediaType, id), url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	producers := []string{}
+	for _, c := range append(result.Networks, result.ProductionCompanies...) {
+		producers = append(producers, c.Name)
+	}
+	return producers, nil
+}
+
+// imageBaseURL serves TMDB images at a thumbnail-friendly width
+const imageBaseURL = "https://image.tmdb.org/t/p/w342"
+
+// Details is what TMDB lists about a movie or TV show
+type Details struct {
+	Runtime      int      // Minutes; a typical episode's for TV shows, 0 if unknown
+	Genres       []string // e.g. ["Drama", "Crime"]
+	ThumbnailURL string   // Poster image, or "" if there is none
+}
+
+// Details returns the runtime, genres and poster of a movie or TV show
+func (c *Client) Details(ctx context.Context, mediaType string, id int64) (*Details, error) {
+	var result struct {
+		Runtime        int   `json:"runtime"`          // Movies
+		EpisodeRunTime []int `json:"episode_run_time"` // TV shows
+		Genres         []struct {
+			Name string `json:"name"`
+		} `json:"genres"`
+		PosterPath string `json:"poster_path"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/%s/%d", mediaType, id), url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	details := &Details{Runtime: result.Runtime, Genres: []string{}}
+	if details.Runtime == 0 && len(result.EpisodeRunTime) > 0 {
+		details.Runtime = result.EpisodeRunTime[0]
+	}
+	for _, g := range result.Genres {
+		details.Genres = append(details.Genres, g.Name)
+	}
+	if result.PosterPath != "" {
+		details.ThumbnailURL = imageBaseURL + result.PosterPath
+	}
+	return details, nil
+}
+
+// ContentRating returns the maturity rating a movie or TV show carries in a
+// region, e.g. "TV-MA" or "PG-13", or "" if it has none there
+func (c *Client) ContentRating(ctx context.Context, mediaType string, id int64, region string) (string, error) {
+	if mediaType == "tv" {
+		var result struct {
+			Results []struct {
+				Region string `json:"iso_3166_1"`
+				Rating string `json:"rating"`
+			} `json:"results"`
+		}
+		if err := c.get(ctx, fmt.Sprintf("/tv/%d/content_ratings", id), url.Values{}, &result); err != nil {
+			return "", err
+		}
+		for _, r := range result.Results {
+			if r.Region == region && r.Rating != "" {
+				return r.Rating, nil
+			}
+		}
+		return "", nil
+	}
+
+	var result struct {
+		Results []struct {
+			Region       string `json:"iso_3166_1"`
+			ReleaseDates []struct {
+				Certification string `json:"certification"`
+			} `json:"release_dates"`
+		} `json:"results"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d/release_dates", id), url.Values{}, &result); err != nil {
+		return "", err
+	}
+	for _, r := range result.Results {
+		if r.Region != region {
+			continue
+		}
+		// Releases without a certification (e.g. festival premieres) are skipped
+		for _, release := range r.ReleaseDates {
+			if release.Certification != "" {
+				return release.Certification, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// Airing is where a TV show stands: its most recently aired episode and
+// the next one scheduled. Either is nil when there's none.
+type Airing struct {
+	LastEpisode *Episode `json:"last_episode_to_air"`
+	NextEpisode *Episode `json:"next_episode_to_air"`
+}
+
+// ShowAiring returns the last aired and next scheduled episodes of a TV show
+func (c *Client) ShowAiring(ctx context.Context, id int64) (*Airing, error) {
+	var result Airing
+	if err := c.get(ctx, fmt.Sprintf("/tv/%d", id), url.Values{}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SeasonEpisodes returns the episodes of one season of a TV show, with their
+// air dates
+func (c *Client) SeasonEpisodes(ctx context.Context, id int64, season int) ([]Episode, error) {
+	var result struct {
+		Episodes []Episode `json:"episodes"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/tv/%d/season/%d", id, season), url.Values{}, &result); err != nil {
+		return nil, err
+	}
+	if result.Episodes == nil {
+		return []Episode{}, nil
+	}
+	return result.Episodes, nil
+}
+
+// get performs a GET request against the API and decodes the JSON response,
+// using the cache when the request is cacheable
+func (c *Client) get(ctx context.Context, path string, params url.Values, v interface{}) error {
+	request, title, cacheable := cacheKey(path, params)
+	cacheable = cacheable && c.cache != nil
+	if cacheable {
+		cached, ok, err := c.cache.GetTMDBResponse(request, title, time.Now().Add(-cacheTTL))
+		if err == nil && ok {
+			if err := json.Unmarshal([]byte(cached), v); err == nil {
+				return nil
+			}
+		}
+	}
+
+	body, err := c.fetch(ctx, path, params)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode tmdb response: %w", err)
+	}
+
+	if cacheable {
+		if err := c.cache.SetTMDBResponse(request, title, string(body)); err != nil {
+			log.Printf("Failed to cache tmdb response for %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// fetch performs a GET request within the rate limit and returns the body,
+// backing off and retrying when TMDB answers 429
+func (c *Client) fetch(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	params.Set("api_key", c.apiKey)
+	delay := c.retryDelay
+
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("tmdb request failed: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tmdb response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			wait := delay
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+			log.Printf("tmdb rate limited on %s, retrying in %s", path, wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			delay *= 2
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("tmdb request %s returned status %d", path, resp.StatusCode)
+		}
+		return body, nil
+	}
+}
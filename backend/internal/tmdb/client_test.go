@@ -0,0 +1,408 @@
+package tmdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/search/movie":
+			if r.URL.Query().Get("query") == "Nothing Matches" {
+				w.Write([]byte(`{"results": []}`))
+				return
+			}
+			w.Write([]byte(`{"results": [
+				{"id": 1, "title": "Stalker 2", "release_date": "2010-01-01"},
+				{"id": 1398, "title": "Stalker", "release_date": "1979-05-25"}
+			]}`))
+		case "/movie/1398":
+			w.Write([]byte(`{"id": 1398, "title": "Stalker", "release_date": "1979-05-25", "runtime": 162}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClientWithBaseURL("test-key", server.URL)
+}
+
+func TestMovieRuntime(t *testing.T) {
+	client := newTestClient(t)
+
+	runtime, err := client.MovieRuntime(context.Background(), "stalker", 1979)
+	if err != nil {
+		t.Fatalf("MovieRuntime failed: %v", err)
+	}
+	if runtime != 162 {
+		t.Errorf("Expected runtime 162 for the exact title match, got %d", runtime)
+	}
+}
+
+func TestMovieRuntimeNoMatch(t *testing.T) {
+	client := newTestClient(t)
+
+	runtime, err := client.MovieRuntime(context.Background(), "Nothing Matches", 0)
+	if err != nil {
+		t.Fatalf("Expected no error for a missing match, got %v", err)
+	}
+	if runtime != 0 {
+		t.Errorf("Expected runtime 0, got %d", runtime)
+	}
+}
+
+func TestClientBadAPIKey(t *testing.T) {
+	client := newTestClient(t)
+	client.apiKey = "wrong"
+
+	if _, err := client.SearchMovie(context.Background(), "Stalker", 0); err == nil {
+		t.Error("Expected error for unauthorized request")
+	}
+}
+
+func TestSearchMulti(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/multi" || r.URL.Query().Get("api_key") != "test-key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"results": [
+			{"id": 1, "media_type": "person", "name": "Severance"},
+			{"id": 2, "media_type": "movie", "title": "Severance 2"},
+			{"id": 95396, "media_type": "tv", "name": "Severance"}
+		]}`))
+	}))
+	defer server.Close()
+	client := NewClientWithBaseURL("test-key", server.URL)
+
+	match, err := client.SearchMulti(context.Background(), "severance")
+	if err != nil {
+		t.Fatalf("SearchMulti failed: %v", err)
+	}
+	if match == nil || match.ID != 95396 || match.DisplayTitle() != "Severance" {
+		t.Errorf("Expected the exact TV title match, got %+v", match)
+	}
+}
+
+func TestTrending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/trending/all/week" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"results": [
+			{"id": 1, "media_type": "tv", "name": "The Bear"},
+			{"id": 2, "media_type": "person", "name": "Some Actor"},
+			{"id": 3, "media_type": "movie", "title": "Dune: Part Two"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", server.URL)
+	items, err := client.Trending(context.Background(), "week")
+	if err != nil {
+		t.Fatalf("Trending failed: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 movies and shows, got %d", len(items))
+	}
+	if items[0].DisplayTitle() != "The Bear" || items[1].DisplayTitle() != "Dune: Part Two" {
+		t.Errorf("Unexpected titles: %q, %q", items[0].DisplayTitle(), items[1].DisplayTitle())
+	}
+}
+
+func TestRecommendationsAndProviders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/multi":
+			w.Write([]byte(`{"results": [
+				{"id": 9, "media_type": "person", "name": "Severance"},
+				{"id": 95396, "media_type": "tv", "name": "Severance"}
+			]}`))
+		case "/tv/95396/recommendations":
+			w.Write([]byte(`{"results": [{"id": 1, "name": "Silo"}]}`))
+		case "/tv/1/watch/providers":
+			w.Write([]byte(`{"results": {
+				"US": {"flatrate": [{"provider_name": "Apple TV Plus"}]},
+				"GB": {"flatrate": [{"provider_name": "Apple TV+"}]}
+			}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", server.URL)
+	ctx := context.Background()
+
+	match, err := client.SearchMulti(ctx, "Severance")
+	if err != nil || match == nil || match.ID != 95396 {
+		t.Fatalf("Expected the TV match, got %+v (%v)", match, err)
+	}
+
+	recs, err := client.Recommendations(ctx, match.MediaType, match.ID)
+	if err != nil {
+		t.Fatalf("Recommendations failed: %v", err)
+	}
+	if len(recs) != 1 || recs[0].MediaType != "tv" {
+		t.Fatalf("Expected one TV recommendation, got %+v", recs)
+	}
+
+	providers, err := client.WatchProviders(ctx, "tv", recs[0].ID, "US")
+	if err != nil {
+		t.Fatalf("WatchProviders failed: %v", err)
+	}
+	if len(providers) != 1 || providers[0] != "Apple TV Plus" {
+		t.Errorf("Expected US providers, got %v", providers)
+	}
+}
+
+func TestProducers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tv/66732":
+			w.Write([]byte(`{"id": 66732, "networks": [{"name": "Netflix"}], "production_companies": [{"name": "21 Laps Entertainment"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", server.URL)
+	producers, err := client.Producers(context.Background(), "tv", 66732)
+	if err != nil {
+		t.Fatalf("Producers failed: %v", err)
+	}
+	if len(producers) != 2 || producers[0] != "Netflix" || producers[1] != "21 Laps Entertainment" {
+		t.Errorf("Unexpected producers: %v", producers)
+	}
+}
+
+func TestDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tv/66732":
+			w.Write([]byte(`{"id": 66732, "episode_run_time": [50], "genres": [{"name": "Drama"}, {"name": "Mystery"}], "poster_path": "/poster.jpg"}`))
+		case "/movie/1398":
+			w.Write([]byte(`{"id": 1398, "runtime": 162, "genres": [], "poster_path": null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", server.URL)
+	show, err := client.Details(context.Background(), "tv", 66732)
+	if err != nil {
+		t.Fatalf("Details failed: %v", err)
+	}
+	if show.Runtime != 50 || len(show.Genres) != 2 || show.Genres[0] != "Drama" ||
+		show.ThumbnailURL != "https://image.tmdb.org/t/p/w342/poster.jpg" {
+		t.Errorf("Unexpected show details: %+v", show)
+	}
+
+	movie, err := client.Details(context.Background(), "movie", 1398)
+	if err != nil {
+		t.Fatalf("Details failed: %v", err)
+	}
+	if movie.Runtime != 162 || len(movie.Genres) != 0 || movie.ThumbnailURL != "" {
+		t.Errorf("Unexpected movie details: %+v", movie)
+	}
+}
+
+func TestContentRating(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tv/66732/content_ratings":
+			w.Write([]byte(`{"results": [{"iso_3166_1": "DE", "rating": "16"}, {"iso_3166_1": "US", "rating": "TV-14"}]}`))
+		case "/movie/1398/release_dates":
+			w.Write([]byte(`{"results": [{"iso_3166_1": "US", "release_dates": [{"certification": ""}, {"certification": "PG"}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", server.URL)
+	for _, tc := range []struct {
+		mediaType string
+		id        int64
+		region    string
+		want      string
+	}{
+		{"tv", 66732, "US", "TV-14"},
+		{"tv", 66732, "DE", "16"},
+		{"tv", 66732, "GB", ""},
+		{"movie", 1398, "US", "PG"},
+	} {
+		rating, err := client.ContentRating(context.Background(), tc.mediaType, tc.id, tc.region)
+		if err != nil {
+			t.Fatalf("ContentRating failed: %v", err)
+		}
+		if rating != tc.want {
+			t.Errorf("%s %d in %s: expected %q, got %q", tc.mediaType, tc.id, tc.region, tc.want, rating)
+		}
+	}
+}
+
+func TestEpisodeAirDates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tv/95396":
+			w.Write([]byte(`{"name": "Severance", "last_episode_to_air": {"season_number": 2, "episode_number": 10, "air_date": "2025-03-21"}, "next_episode_to_air": {"season_number": 3, "episode_number": 1, "name": "Cold Harbor", "air_date": "2026-01-16"}}`))
+		case "/tv/95396/season/2":
+			w.Write([]byte(`{"episodes": [{"season_number": 2, "episode_number": 1, "name": "Hello, Ms. Cobel", "air_date": "2025-01-17"}, {"season_number": 2, "episode_number": 2, "air_date": "2025-01-24"}]}`))
+		case "/tv/1":
+			w.Write([]byte(`{"name": "Unaired", "last_episode_to_air": null, "next_episode_to_air": null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", server.URL)
+	airing, err := client.ShowAiring(context.Background(), 95396)
+	if err != nil {
+		t.Fatalf("ShowAiring failed: %v", err)
+	}
+	if last := airing.LastEpisode; last == nil || last.SeasonNumber != 2 || last.EpisodeNumber != 10 || last.AirDate != "2025-03-21" {
+		t.Errorf("Unexpected last episode: %+v", last)
+	}
+	if next := airing.NextEpisode; next == nil || next.SeasonNumber != 3 || next.Name != "Cold Harbor" || next.AirDate != "2026-01-16" {
+		t.Errorf("Unexpected next episode: %+v", next)
+	}
+	if airing, err := client.ShowAiring(context.Background(), 1); err != nil || airing.LastEpisode != nil || airing.NextEpisode != nil {
+		t.Errorf("Expected no episodes for an unaired show, got %+v (%v)", airing, err)
+	}
+
+	episodes, err := client.SeasonEpisodes(context.Background(), 95396, 2)
+	if err != nil {
+		t.Fatalf("SeasonEpisodes failed: %v", err)
+	}
+	if len(episodes) != 2 || episodes[0].Name != "Hello, Ms. Cobel" || episodes[1].AirDate != "2025-01-24" {
+		t.Errorf("Unexpected episodes: %+v", episodes)
+	}
+}
+
+func TestRetryOnTooManyRequests(t *testing.T) {
+	attempts, limited := 0, 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= limited {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id": 1398, "title": "Stalker", "runtime": 162}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", server.URL)
+	client.retryDelay = time.Millisecond
+	movie, err := client.GetMovie(context.Background(), 1398)
+	if err != nil {
+		t.Fatalf("Expected the request to succeed after retrying, got %v", err)
+	}
+	if movie.Runtime != 162 || attempts != 3 {
+		t.Errorf("Expected runtime 162 after 3 attempts, got %d after %d", movie.Runtime, attempts)
+	}
+
+	// Requests still rate limited after every retry fail
+	attempts, limited = 0, 100
+	if _, err := client.GetMovie(context.Background(), 1398); err == nil {
+		t.Error("Expected an error once retries run out")
+	}
+	if attempts != maxRetries+1 {
+		t.Errorf("Expected %d attempts, got %d", maxRetries+1, attempts)
+	}
+}
+
+// memoryCache is a Cache kept in memory
+type memoryCache map[string]string
+
+func (c memoryCache) GetTMDBResponse(request, title string, since time.Time) (string, bool, error) {
+	response, ok := c[request+"|"+title]
+	return response, ok, nil
+}
+
+func (c memoryCache) SetTMDBResponse(request, title, response string) error {
+	c[request+"|"+title] = response
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/search/movie":
+			w.Write([]byte(`{"results": [{"id": 1398, "title": "Stalker", "release_date": "1979-05-25"}]}`))
+		case "/movie/1398":
+			w.Write([]byte(`{"id": 1398, "title": "Stalker", "runtime": 162}`))
+		case "/tv/66732":
+			w.Write([]byte(`{"last_episode_to_air": null, "next_episode_to_air": null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cache := memoryCache{}
+	client := NewClientWithBaseURL("test-key", server.URL)
+	client.SetCache(cache)
+
+	for _, title := range []string{"Stalker", " stalker", "STALKER  "} {
+		runtime, err := client.MovieRuntime(context.Background(), title, 1979)
+		if err != nil || runtime != 162 {
+			t.Fatalf("MovieRuntime(%q) = %d, %v", title, runtime, err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected one search and one details request, got %d requests", requests)
+	}
+	if _, ok := cache["/search/movie?year=1979|stalker"]; !ok {
+		t.Errorf("Expected the search cached under the normalized title, got %v", cache)
+	}
+
+	// Airing episodes change, so they're always fetched
+	client.ShowAiring(context.Background(), 66732)
+	client.ShowAiring(context.Background(), 66732)
+	if requests != 4 {
+		t.Errorf("Expected show airing requests not to be cached, got %d requests", requests)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatalf("wait failed: %v", err)
+		}
+	}
+	// Two requests from the burst, then two at 100 per second
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Expected requests past the burst to wait, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := newRateLimiter(0.001, 1).wait(ctx); err != nil {
+		t.Errorf("Expected the first request to use the burst, got %v", err)
+	}
+	empty := newRateLimiter(0.001, 1)
+	empty.wait(context.Background())
+	if err := empty.wait(ctx); err == nil {
+		t.Error("Expected a cancelled wait to fail")
+	}
+}
@@ -0,0 +1,53 @@
+package tmdb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TMDB allows bursts of around 40 requests, refilling at a few per second.
+// Every client in the process shares one bucket, since they share an API key.
+const (
+	defaultRate  = 4.0 // Requests per second
+	defaultBurst = 40
+)
+
+var sharedLimiter = newRateLimiter(defaultRate, defaultBurst)
+
+// rateLimiter is a token bucket: requests take a token, waiting for one to
+// refill when the bucket is empty
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until a request may be made, or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
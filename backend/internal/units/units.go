@@ -0,0 +1,80 @@
+package units
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Duration formats for human-readable watch time
+const (
+	FormatShort = "short" // "2h 35m"
+	FormatLong  = "long"  // "2 hours 35 minutes"
+	FormatNone  = "none"  // Minutes only
+)
+
+// ValidFormat reports whether format is a known duration format
+func ValidFormat(format string) bool {
+	return format == FormatShort || format == FormatLong || format == FormatNone
+}
+
+// FormatMinutes renders minutes as a human-readable duration, e.g. 155 ->
+// "2h 35m" in the short format or "2 hours 35 minutes" in the long one.
+// It returns an empty string for FormatNone.
+func FormatMinutes(minutes int, format string) string {
+	if format == FormatNone {
+		return ""
+	}
+
+	sign := ""
+	if minutes < 0 {
+		sign, minutes = "-", -minutes
+	}
+	hours, mins := minutes/60, minutes%60
+
+	if format == FormatLong {
+		var parts []string
+		if hours > 0 {
+			parts = append(parts, plural(hours, "hour"))
+		}
+		if mins > 0 || hours == 0 {
+			parts = append(parts, plural(mins, "minute"))
+		}
+		return sign + strings.Join(parts, " ")
+	}
+
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%s%dm", sign, mins)
+	case mins == 0:
+		return fmt.Sprintf("%s%dh", sign, hours)
+	default:
+		return fmt.Sprintf("%s%dh %dm", sign, hours, mins)
+	}
+}
+
+// plural formats a count with a singular or plural unit
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// ParseWeekday parses a week start setting ("monday" or "sunday")
+func ParseWeekday(name string) (time.Weekday, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "monday":
+		return time.Monday, nil
+	case "sunday":
+		return time.Sunday, nil
+	default:
+		return 0, fmt.Errorf("week start must be monday or sunday, got %q", name)
+	}
+}
+
+// WeekStart returns midnight on the first day of the week containing date
+func WeekStart(date time.Time, first time.Weekday) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) - int(first) + 7) % 7))
+}
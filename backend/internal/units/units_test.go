@@ -0,0 +1,60 @@
+package units
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatMinutes(t *testing.T) {
+	tests := []struct {
+		minutes int
+		format  string
+		want    string
+	}{
+		{155, FormatShort, "2h 35m"},
+		{120, FormatShort, "2h"},
+		{45, FormatShort, "45m"},
+		{0, FormatShort, "0m"},
+		{-90, FormatShort, "-1h 30m"},
+		{155, FormatLong, "2 hours 35 minutes"},
+		{61, FormatLong, "1 hour 1 minute"},
+		{60, FormatLong, "1 hour"},
+		{0, FormatLong, "0 minutes"},
+		{155, FormatNone, ""},
+	}
+
+	for _, tt := range tests {
+		if got := FormatMinutes(tt.minutes, tt.format); got != tt.want {
+			t.Errorf("FormatMinutes(%d, %q) = %q, want %q", tt.minutes, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	// Wednesday
+	date := time.Date(2025, 6, 11, 15, 30, 0, 0, time.UTC)
+
+	if got := WeekStart(date, time.Monday); !got.Equal(time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected Monday June 9, got %s", got)
+	}
+	if got := WeekStart(date, time.Sunday); !got.Equal(time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected Sunday June 8, got %s", got)
+	}
+
+	sunday := time.Date(2025, 6, 8, 9, 0, 0, 0, time.UTC)
+	if got := WeekStart(sunday, time.Monday); !got.Equal(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a Sunday to belong to the week starting Monday June 2, got %s", got)
+	}
+	if got := WeekStart(sunday, time.Sunday); !got.Equal(time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a Sunday to start its own week, got %s", got)
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	if day, err := ParseWeekday("Sunday"); err != nil || day != time.Sunday {
+		t.Errorf("Expected Sunday, got %v (%v)", day, err)
+	}
+	if _, err := ParseWeekday("friday"); err == nil {
+		t.Error("Expected an error for an unsupported week start")
+	}
+}
@@ -1,6 +1,7 @@
 import { BrowserRouter as Router, Routes, Route } from 'react-router-dom';
 import Dashboard from './pages/Dashboard';
 import ServiceDetail from './pages/ServiceDetail';
+import SavedView from './pages/SavedView';
 
 function App() {
   return (
@@ -8,6 +9,7 @@ function App() {
       <Routes>
         <Route path="/" element={<Dashboard />} />
         <Route path="/service/:id" element={<ServiceDetail />} />
+        <Route path="/views/:id" element={<SavedView />} />
       </Routes>
     </Router>
   );
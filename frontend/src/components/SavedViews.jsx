@@ -0,0 +1,34 @@
+import { useState, useEffect } from 'react';
+import { Link } from 'react-router-dom';
+import api from '../services/api';
+
+const SavedViews = () => {
+  const [views, setViews] = useState([]);
+
+  useEffect(() => {
+    api.getViews()
+      .then((data) => setViews(data || []))
+      .catch((err) => console.error('Error fetching saved views:', err));
+  }, []);
+
+  if (views.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className="flex flex-wrap items-center gap-3">
+      <span className="text-slate-400 text-sm uppercase tracking-wider font-semibold">Saved views</span>
+      {views.map((view) => (
+        <Link
+          key={view.id}
+          to={`/views/${view.id}`}
+          className="px-4 py-2 bg-slate-800 border border-slate-700 text-slate-200 rounded-full hover:border-blue-500 hover:text-white transition-colors"
+        >
+          {view.name}
+        </Link>
+      ))}
+    </div>
+  );
+};
+
+export default SavedViews;
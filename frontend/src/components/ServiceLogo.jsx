@@ -14,10 +14,18 @@ const ServiceLogo = ({ serviceName, size = 'md', showFullTextFallback = false })
     'Amazon Video': '/logos/amazon.svg',
     'Prime Video': '/logos/amazon.svg',
     'HBO Max': '/logos/hbo.svg',
+    'Apple TV': '/logos/apple-tv.svg',
     'Apple TV+': '/logos/apple-tv.svg',
     'Peacock': '/logos/peacock.svg',
     'Hulu': '/logos/hulu.svg',
     'Disney+': '/logos/disney.svg',
+    'Vudu': '/logos/vudu.svg',
+    'Kanopy': '/logos/kanopy.svg',
+    'Hoopla': '/logos/hoopla.svg',
+    'MUBI': '/logos/mubi.svg',
+    'Criterion Channel': '/logos/criterion.svg',
+    'ESPN+': '/logos/espn.svg',
+    'Audible': '/logos/audible.svg',
   };
 
   const logoPath = logoFiles[serviceName];
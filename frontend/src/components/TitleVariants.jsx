@@ -0,0 +1,70 @@
+import { useState, useEffect } from 'react';
+import api from '../services/api';
+import { formatMinutes } from '../utils/format';
+
+const TitleVariants = () => {
+  const [clusters, setClusters] = useState([]);
+  const [confirming, setConfirming] = useState(null);
+
+  const fetchVariants = () => {
+    api.getTitleVariants()
+      .then((data) => setClusters(data?.clusters || []))
+      .catch((err) => console.error('Error fetching title variants:', err));
+  };
+
+  useEffect(() => {
+    fetchVariants();
+  }, []);
+
+  const confirmAlias = async (suggestion) => {
+    setConfirming(suggestion.alias);
+    try {
+      await api.createTitleAlias(suggestion.alias, suggestion.canonical);
+      fetchVariants();
+    } catch (err) {
+      console.error('Error creating title alias:', err);
+    } finally {
+      setConfirming(null);
+    }
+  };
+
+  if (clusters.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className="bg-slate-800 rounded-lg border border-slate-700 p-6">
+      <h2 className="text-xl font-bold text-white mb-1">Possible duplicate titles</h2>
+      <p className="text-slate-400 text-sm mb-4">Merge spellings of the same title so they're counted together.</p>
+      <div className="space-y-4">
+        {clusters.map((cluster) => (
+          <div key={cluster.canonical}>
+            <p className="text-slate-200 font-semibold">{cluster.canonical}</p>
+            <ul className="mt-1 space-y-1">
+              {cluster.suggestions.map((suggestion) => {
+                const variant = cluster.variants.find((v) => v.title === suggestion.alias);
+                return (
+                  <li key={suggestion.alias} className="flex items-center justify-between text-sm">
+                    <span className="text-slate-400">
+                      {suggestion.alias}
+                      {variant && <span className="ml-2 text-slate-500">{formatMinutes(variant.total_minutes)}</span>}
+                    </span>
+                    <button
+                      onClick={() => confirmAlias(suggestion)}
+                      disabled={confirming !== null}
+                      className="px-3 py-1 bg-blue-600 text-white rounded hover:bg-blue-700 disabled:opacity-50 transition-colors"
+                    >
+                      {confirming === suggestion.alias ? 'Merging...' : 'Merge'}
+                    </button>
+                  </li>
+                );
+              })}
+            </ul>
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+};
+
+export default TitleVariants;
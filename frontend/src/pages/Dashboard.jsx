@@ -1,6 +1,8 @@
 import { useState, useEffect, useCallback } from 'react';
 import ServiceCard from '../components/ServiceCard';
 import DateFilter from '../components/DateFilter';
+import SavedViews from '../components/SavedViews';
+import TitleVariants from '../components/TitleVariants';
 import api from '../services/api';
 import { formatMinutes } from '../utils/format';
 
@@ -82,6 +84,11 @@ const Dashboard = () => {
           <DateFilter onFilterChange={handleFilterChange} />
         </div>
 
+        {/* Saved Views */}
+        <div className="mb-8">
+          <SavedViews />
+        </div>
+
         {/* Summary Stats */}
         <div className="grid grid-cols-1 md:grid-cols-3 gap-6 mb-8">
           <div className="bg-gradient-to-br from-slate-800 to-slate-900 p-6 rounded-lg border border-slate-700 shadow-lg hover:shadow-blue-500/10 transition-shadow">
@@ -119,6 +126,11 @@ const Dashboard = () => {
             ))}
           </div>
         )}
+
+        {/* Title Variants */}
+        <div className="mt-8">
+          <TitleVariants />
+        </div>
       </div>
     </div>
   );
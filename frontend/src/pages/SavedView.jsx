@@ -0,0 +1,156 @@
+import { useState, useEffect } from 'react';
+import { useParams, Link } from 'react-router-dom';
+import {
+  LineChart, Line, BarChart, Bar, PieChart, Pie, Cell,
+  XAxis, YAxis, CartesianGrid, Tooltip, Legend, ResponsiveContainer,
+} from 'recharts';
+import api from '../services/api';
+import { formatMinutes, formatDate } from '../utils/format';
+
+// Merge per-service series into one row per period for recharts
+const toChartRows = (series) => {
+  const rows = {};
+  series.forEach((s) => {
+    s.points.forEach((point) => {
+      rows[point.period] = rows[point.period] || { period: point.period };
+      rows[point.period][s.service_name] = point.minutes;
+    });
+  });
+  return Object.values(rows).sort((a, b) => a.period.localeCompare(b.period));
+};
+
+const tooltipProps = {
+  contentStyle: { backgroundColor: '#1e293b', border: '1px solid #475569' },
+  labelStyle: { color: '#e2e8f0' },
+  formatter: (value, name) => [formatMinutes(value), name],
+};
+
+const ViewChart = ({ chartType, series }) => {
+  if (chartType === 'pie') {
+    return (
+      <ResponsiveContainer width="100%" height={350}>
+        <PieChart>
+          <Pie data={series} dataKey="total_minutes" nameKey="service_name" outerRadius={130} label={(entry) => entry.service_name}>
+            {series.map((s) => (
+              <Cell key={s.service_id} fill={s.color} />
+            ))}
+          </Pie>
+          <Tooltip {...tooltipProps} />
+        </PieChart>
+      </ResponsiveContainer>
+    );
+  }
+
+  const rows = toChartRows(series);
+
+  if (chartType === 'table') {
+    return (
+      <table className="w-full text-left text-slate-300">
+        <thead>
+          <tr className="border-b border-slate-700">
+            <th className="py-2">Period</th>
+            {series.map((s) => (
+              <th key={s.service_id} className="py-2">{s.service_name}</th>
+            ))}
+          </tr>
+        </thead>
+        <tbody>
+          {rows.map((row) => (
+            <tr key={row.period} className="border-b border-slate-800">
+              <td className="py-2">{formatDate(row.period)}</td>
+              {series.map((s) => (
+                <td key={s.service_id} className="py-2">{formatMinutes(row[s.service_name] || 0)}</td>
+              ))}
+            </tr>
+          ))}
+        </tbody>
+      </table>
+    );
+  }
+
+  const Chart = chartType === 'line' ? LineChart : BarChart;
+  return (
+    <ResponsiveContainer width="100%" height={350}>
+      <Chart data={rows}>
+        <CartesianGrid strokeDasharray="3 3" stroke="#475569" />
+        <XAxis dataKey="period" stroke="#94a3b8" />
+        <YAxis stroke="#94a3b8" />
+        <Tooltip {...tooltipProps} />
+        <Legend />
+        {series.map((s) => (chartType === 'line' ? (
+          <Line key={s.service_id} type="monotone" dataKey={s.service_name} stroke={s.color} strokeWidth={2} />
+        ) : (
+          <Bar key={s.service_id} dataKey={s.service_name} stackId="minutes" fill={s.color} />
+        )))}
+      </Chart>
+    </ResponsiveContainer>
+  );
+};
+
+const SavedView = () => {
+  const { id } = useParams();
+  const [data, setData] = useState(null);
+  const [error, setError] = useState(null);
+
+  useEffect(() => {
+    api.getViewData(id)
+      .then((viewData) => {
+        setData(viewData);
+        setError(null);
+      })
+      .catch((err) => {
+        setError('Failed to load saved view');
+        console.error('Error fetching saved view:', err);
+      });
+  }, [id]);
+
+  if (error) {
+    return (
+      <div className="min-h-screen bg-slate-900 flex items-center justify-center">
+        <div className="text-center">
+          <p className="text-red-400 mb-4">{error}</p>
+          <Link to="/" className="px-4 py-2 bg-blue-600 text-white rounded hover:bg-blue-700 transition-colors">
+            Back to Dashboard
+          </Link>
+        </div>
+      </div>
+    );
+  }
+
+  if (!data) {
+    return (
+      <div className="min-h-screen bg-slate-900 flex items-center justify-center">
+        <div className="animate-spin rounded-full h-12 w-12 border-b-2 border-blue-500"></div>
+      </div>
+    );
+  }
+
+  const { view, series, start_date, end_date } = data;
+  const totalMinutes = series.reduce((sum, s) => sum + s.total_minutes, 0);
+
+  return (
+    <div className="min-h-screen bg-gradient-to-br from-slate-900 via-slate-800 to-slate-900 py-8 px-4 sm:px-6 lg:px-8">
+      <div className="max-w-7xl mx-auto">
+        <div className="mb-8">
+          <Link to="/" className="inline-flex items-center gap-2 text-blue-400 hover:text-blue-300 mb-4 transition-colors">
+            <span className="text-lg">←</span> Back to Dashboard
+          </Link>
+          <h1 className="text-4xl font-bold text-white mb-2">{view.name}</h1>
+          <p className="text-slate-400 text-lg">
+            📅 {formatDate(start_date)} - {formatDate(end_date)} · {formatMinutes(totalMinutes)}
+          </p>
+        </div>
+
+        <div className="bg-gradient-to-br from-slate-800 to-slate-900 p-6 rounded-lg border border-slate-700 shadow-xl">
+          {series.length === 0 ? (
+            <p className="text-slate-400 text-center py-12">No services in this view</p>
+          ) : (
+            <ViewChart chartType={view.chart_type} series={series} />
+          )}
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default SavedView;
@@ -12,6 +12,7 @@ const ServiceDetail = () => {
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState(null);
   const [scraping, setScraping] = useState(false);
+  const [checkingAuth, setCheckingAuth] = useState(false);
   const [filterParams, setFilterParams] = useState({});
   const [isInitialLoad, setIsInitialLoad] = useState(true);
 
@@ -45,10 +46,11 @@ const ServiceDetail = () => {
     setIsInitialLoad(true);
   }, [id]);
 
-  const handleTriggerScrape = async (serviceName) => {
+  const handleTriggerScrape = async (service, serviceName) => {
     try {
       setScraping(true);
-      const slug = getServiceSlug(serviceName);
+      // Services created outside the config have no key; fall back to a slug of the name
+      const slug = service?.key || getServiceSlug(serviceName);
       await api.triggerScrape(slug);
       alert(`Scraper triggered for ${serviceName}. Check back in a few minutes.`);
     } catch (err) {
@@ -58,6 +60,22 @@ const ServiceDetail = () => {
     }
   };
 
+  const handleCheckAuth = async (serviceName) => {
+    try {
+      setCheckingAuth(true);
+      const status = await api.checkServiceAuth(id);
+      if (status.logged_in) {
+        alert(`${serviceName} cookies are still signed in.`);
+      } else {
+        alert(`${serviceName} is signed out (${status.reason}). Refresh its cookies in config.yaml.`);
+      }
+    } catch (err) {
+      alert(`Failed to check login: ${err.message}`);
+    } finally {
+      setCheckingAuth(false);
+    }
+  };
+
   if (loading) {
     return (
       <div className="min-h-screen bg-slate-900 flex items-center justify-center">
@@ -118,13 +136,22 @@ const ServiceDetail = () => {
                 📅 {formatDate(start_date)} - {formatDate(end_date)}
               </p>
             </div>
-            <button
-              onClick={() => handleTriggerScrape(serviceName)}
-              disabled={scraping}
-              className="px-6 py-3 bg-gradient-to-r from-blue-600 to-blue-500 text-white rounded-lg font-medium hover:shadow-lg hover:shadow-blue-500/50 transition-all disabled:from-slate-600 disabled:to-slate-600 disabled:cursor-not-allowed disabled:shadow-none"
-            >
-              {scraping ? '⏳ Scraping...' : '🔄 Trigger Scrape'}
-            </button>
+            <div className="flex gap-3">
+              <button
+                onClick={() => handleCheckAuth(serviceName)}
+                disabled={checkingAuth}
+                className="px-6 py-3 bg-slate-700 text-white rounded-lg font-medium hover:bg-slate-600 transition-colors disabled:bg-slate-800 disabled:cursor-not-allowed"
+              >
+                {checkingAuth ? '⏳ Checking...' : '🔑 Check Login'}
+              </button>
+              <button
+                onClick={() => handleTriggerScrape(service, serviceName)}
+                disabled={scraping}
+                className="px-6 py-3 bg-gradient-to-r from-blue-600 to-blue-500 text-white rounded-lg font-medium hover:shadow-lg hover:shadow-blue-500/50 transition-all disabled:from-slate-600 disabled:to-slate-600 disabled:cursor-not-allowed disabled:shadow-none"
+              >
+                {scraping ? '⏳ Scraping...' : '🔄 Trigger Scrape'}
+              </button>
+            </div>
           </div>
         </div>
 
@@ -194,13 +221,26 @@ const ServiceDetail = () => {
                           📺 {item.episode_info}
                         </p>
                       )}
+                      {item.notes && (
+                        <p className="text-slate-300 text-sm mt-1 italic">
+                          📝 {item.notes}
+                        </p>
+                      )}
+                      {item.confidence === 'low' && (
+                        <p className="text-amber-400 text-xs mt-1">
+                          Low confidence · inferred from network traffic
+                        </p>
+                      )}
                     </div>
                     <span className="text-blue-400 font-medium ml-4 flex items-center gap-1">
                       ⏱️ {formatMinutes(item.duration_minutes)}
                     </span>
                   </div>
                   <div className="flex justify-between items-center text-sm text-slate-400">
-                    <span>🕐 {formatDateTime(item.watched_at)}</span>
+                    <span>
+                      🕐 {formatDateTime(item.watched_at)}
+                      {item.rating > 0 && <span className="ml-3 text-yellow-400">{'★'.repeat(item.rating)}</span>}
+                    </span>
                     {item.genre && <span className="text-slate-500 px-2 py-1 bg-slate-600/30 rounded">{item.genre}</span>}
                   </div>
                 </div>
@@ -33,6 +33,19 @@ class APIService {
     }
   }
 
+  async delete(endpoint) {
+    try {
+      const response = await fetch(`${API_BASE_URL}${endpoint}`, { method: 'DELETE' });
+      if (!response.ok) {
+        throw new Error(`HTTP error! status: ${response.status}`);
+      }
+      return await response.json();
+    } catch (error) {
+      console.error(`API DELETE error for ${endpoint}:`, error);
+      throw error;
+    }
+  }
+
   // Service endpoints
   async getServices(params = {}) {
     const queryString = new URLSearchParams(params).toString();
@@ -50,10 +63,42 @@ class APIService {
     return this.post(`/scrape/${serviceName}`);
   }
 
+  async checkServiceAuth(serviceId) {
+    return this.post(`/services/${serviceId}/check-auth`);
+  }
+
   async getScraperStatus() {
     return this.get('/scraper/status');
   }
 
+  // Saved view endpoints
+  async getViews() {
+    return this.get('/views');
+  }
+
+  async getViewData(viewId) {
+    return this.get(`/views/${viewId}/data`);
+  }
+
+  async createView(view) {
+    return this.post('/views', view);
+  }
+
+  async deleteView(viewId) {
+    return this.delete(`/views/${viewId}`);
+  }
+
+  // Title alias endpoints
+  async getTitleVariants(params = {}) {
+    const queryString = new URLSearchParams(params).toString();
+    const endpoint = `/insights/title-variants${queryString ? `?${queryString}` : ''}`;
+    return this.get(endpoint);
+  }
+
+  async createTitleAlias(alias, canonical) {
+    return this.post('/title-aliases', { alias, canonical });
+  }
+
   async getHealth() {
     return this.get('/health');
   }